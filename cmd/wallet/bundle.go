@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/logging"
+	"github.com/Fantasim/hdpay/internal/wallet/hd"
+	"github.com/Fantasim/hdpay/internal/wallet/tx"
+)
+
+// runSignBundle signs a BSC or SOL sweep bundle exported by
+// POST /api/send/bundle/export. Like sign-psbt it is meant for an air-gapped
+// machine holding the seed: it needs no database and no network, checks that
+// every transaction is a plain transfer to the bundle destination, and writes
+// the signed bundle as JSON for POST /api/send/bundle/broadcast.
+func runSignBundle() error {
+	fs := flag.NewFlagSet("sign-bundle", flag.ExitOnError)
+	inPath := fs.String("in", "-", "Sweep bundle JSON to sign (- for stdin)")
+	outPath := fs.String("out", "-", "Write the signed bundle JSON to this path (- for stdout)")
+	network := fs.String("network", "", "Network: mainnet or testnet (default: from HDPAY_NETWORK or testnet)")
+	mnemonicFile := fs.String("mnemonic-file", "", "Path to the 24-word BIP-39 mnemonic (default: from HDPAY_MNEMONIC_FILE)")
+	keystoreFile := fs.String("keystore", "", "Path to the encrypted mnemonic keystore (default: from HDPAY_KEYSTORE_FILE)")
+	var shareFiles shareFileFlags
+	fs.Var(&shareFiles, "share-file", "SLIP-39 share file to recover the seed from (repeatable; default: from HDPAY_SHARE_FILES)")
+	passphraseFile := fs.String("passphrase-file", "", "Path to file containing the optional BIP-39 (or SLIP-39) passphrase")
+	passphrasePrompt := fs.Bool("passphrase-prompt", false, "Prompt for the optional BIP-39 (or SLIP-39) passphrase on the terminal")
+	fs.Parse(os.Args[2:])
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	logCloser, err := logging.Setup(cfg.LogLevel, cfg.LogDir)
+	if err != nil {
+		return fmt.Errorf("failed to setup logging: %w", err)
+	}
+	defer logCloser.Close()
+
+	overrideSeedSource(cfg, *mnemonicFile, *keystoreFile, shareFiles)
+	if *network != "" {
+		cfg.Network = *network
+	}
+	if *passphraseFile != "" {
+		cfg.PassphraseFile = *passphraseFile
+	}
+	if *passphrasePrompt {
+		cfg.PassphrasePrompt = true
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if !cfg.HasSeedSource() {
+		return fmt.Errorf("--mnemonic-file, --keystore or --share-file is required (or set HDPAY_MNEMONIC_FILE/HDPAY_KEYSTORE_FILE/HDPAY_SHARE_FILES)")
+	}
+
+	// Read and check the bundle before unlocking the seed, so a bad file fails fast.
+	raw, err := readBundleInput(*inPath)
+	if err != nil {
+		return err
+	}
+	bundle, err := tx.DecodeSweepBundle(raw)
+	if err != nil {
+		return err
+	}
+	if bundle.Network != cfg.Network {
+		return fmt.Errorf("%w: bundle is for %s, signing for %s", config.ErrInvalidBundle, bundle.Network, cfg.Network)
+	}
+
+	summary, err := tx.SummarizeSweepBundle(bundle)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%s %s bundle (account %d): %d transactions, %s sent to %s, fee %s\n",
+		bundle.Chain, bundle.Token, bundle.Account, summary.TxCount,
+		summary.TotalAmount, bundle.Destination, summary.TotalFee)
+
+	seed, err := loadSeed(cfg)
+	if err != nil {
+		return err
+	}
+	hd.MlockBytes(seed)
+	defer func() {
+		hd.MunlockBytes(seed)
+		hd.ZeroBytes(seed)
+	}()
+
+	signed, err := tx.SignSweepBundle(bundle, seed)
+	if err != nil {
+		return fmt.Errorf("sign bundle: %w", err)
+	}
+
+	encoded, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeBundleOutput(*outPath, encoded); err != nil {
+		return err
+	}
+
+	slog.Info("sweep bundle signed",
+		"chain", bundle.Chain,
+		"token", bundle.Token,
+		"signedCount", signed,
+		"out", *outPath,
+	)
+	fmt.Fprintf(os.Stderr, "Signed %d of %d transactions\n", signed, summary.TxCount)
+	return nil
+}
+
+// readBundleInput reads a sweep bundle from a file or stdin, refusing anything
+// larger than the broadcast endpoint accepts.
+func readBundleInput(path string) ([]byte, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("open bundle: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	limit := int64(config.MaxSweepBundleBytes)
+	raw, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("read bundle: %w", err)
+	}
+	if int64(len(raw)) > limit {
+		return nil, fmt.Errorf("%w: larger than %d bytes", config.ErrInvalidBundle, limit)
+	}
+	return raw, nil
+}
+
+// writeBundleOutput writes the signed bundle, newline-terminated, to a file or stdout.
+func writeBundleOutput(path string, encoded []byte) error {
+	encoded = append(encoded, '\n')
+	if path == "-" {
+		_, err := os.Stdout.Write(encoded)
+		return err
+	}
+	if err := os.WriteFile(path, encoded, 0o600); err != nil {
+		return fmt.Errorf("write bundle: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/logging"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/shared/scanner"
+	"github.com/Fantasim/hdpay/internal/wallet/db"
+	"github.com/Fantasim/hdpay/internal/wallet/hd"
+)
+
+// discoverReport is the machine-readable output of hdpay discover.
+type discoverReport struct {
+	Network     string                     `json:"network"`
+	Account     uint32                     `json:"account"`
+	GapLimit    int                        `json:"gap_limit"`
+	Source      string                     `json:"source"`
+	GeneratedAt string                     `json:"generated_at"`
+	DryRun      bool                       `json:"dry_run"`
+	Branches    []*scanner.DiscoveryResult `json:"branches"`
+	Extended    []*hd.ExtendResult         `json:"extended"`
+}
+
+// runDiscover finds how far a restored seed was used: it derives and checks addresses
+// from index 0 until --gap-limit consecutive ones have no history and no balance,
+// then stores the receive addresses up to the last used index. For BTC the change
+// chain (/1/i) is searched as well; its result is recorded and reported, but change
+// addresses are not stored, since hdpay only tracks the receive chain.
+func runDiscover() error {
+	fs := flag.NewFlagSet("discover", flag.ExitOnError)
+	dbPath := fs.String("db", "", "Database path (default: from HDPAY_DB_PATH or ./data/hdpay.sqlite)")
+	network := fs.String("network", "", "Network: mainnet or testnet (default: from HDPAY_NETWORK or testnet)")
+	account := fs.Int("account", -1, "BIP-44 account to discover (default: from HDPAY_ACCOUNT or 0)")
+	btcType := fs.String("btc-type", "", "BTC address type (default: from HDPAY_BTC_ADDRESS_TYPE or p2wpkh)")
+	chains := fs.String("chain", "", "Comma-separated chains to discover (default: all)")
+	gapLimit := fs.Int("gap-limit", config.DefaultGapLimit, "Stop after this many consecutive unused addresses")
+	dryRun := fs.Bool("dry-run", false, "Report the discovered range without storing any address")
+	reportPath := fs.String("json", "", "Write a JSON report to this path (- for stdout)")
+	mnemonicFile := fs.String("mnemonic-file", "", "Path to the 24-word BIP-39 mnemonic (default: from HDPAY_MNEMONIC_FILE)")
+	keystoreFile := fs.String("keystore", "", "Path to the encrypted mnemonic keystore (default: from HDPAY_KEYSTORE_FILE)")
+	var shareFiles shareFileFlags
+	fs.Var(&shareFiles, "share-file", "SLIP-39 share file to recover the seed from (repeatable; default: from HDPAY_SHARE_FILES)")
+	passphraseFile := fs.String("passphrase-file", "", "Path to file containing the optional BIP-39 (or SLIP-39) passphrase")
+	passphrasePrompt := fs.Bool("passphrase-prompt", false, "Prompt for the optional BIP-39 (or SLIP-39) passphrase on the terminal")
+	xpubs := xpubFlags{}
+	fs.Var(xpubs, "xpub", "Discover from an account-level extended public key as chain=key instead of the seed (repeatable)")
+	fs.Parse(os.Args[2:])
+
+	if *gapLimit < 1 || *gapLimit > config.MaxGapLimit {
+		return fmt.Errorf("--gap-limit must be between 1 and %d, got %d", config.MaxGapLimit, *gapLimit)
+	}
+	selected, err := parseChainList(*chains)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	logCloser, err := logging.Setup(cfg.LogLevel, cfg.LogDir)
+	if err != nil {
+		return fmt.Errorf("failed to setup logging: %w", err)
+	}
+	defer logCloser.Close()
+
+	overrideSeedSource(cfg, *mnemonicFile, *keystoreFile, shareFiles)
+	if *dbPath != "" {
+		cfg.DBPath = *dbPath
+	}
+	if *network != "" {
+		cfg.Network = *network
+	}
+	if *account >= 0 {
+		cfg.Account = *account
+	}
+	if *btcType != "" {
+		cfg.BTCAddressType = *btcType
+	}
+	if *passphraseFile != "" {
+		cfg.PassphraseFile = *passphraseFile
+	}
+	if *passphrasePrompt {
+		cfg.PassphrasePrompt = true
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	watchOnly := len(xpubs) > 0
+	if watchOnly && (*mnemonicFile != "" || *keystoreFile != "" || len(shareFiles) > 0) {
+		return fmt.Errorf("--xpub and --mnemonic-file/--keystore/--share-file are mutually exclusive")
+	}
+	if !watchOnly && !cfg.HasSeedSource() {
+		return fmt.Errorf("--mnemonic-file, --keystore or --share-file is required (or set HDPAY_MNEMONIC_FILE/HDPAY_KEYSTORE_FILE/HDPAY_SHARE_FILES), or pass --xpub")
+	}
+
+	net := hd.NetworkParams(cfg.Network)
+	btc := cfg.BTCType()
+	acct := uint32(cfg.Account)
+
+	// Receive-chain derivers per chain, plus the BTC change chain.
+	derivers := make(map[models.Chain]hd.IndexDeriver, len(selected))
+	var changeDeriver hd.IndexDeriver
+	source := "seed"
+	if watchOnly {
+		source = "xpub"
+		for _, chain := range selected {
+			encoded, ok := xpubs[chain]
+			if !ok {
+				slog.Warn("no xpub for chain, skipping", "chain", chain)
+				continue
+			}
+			parent, err := xpubExternalParent(chain, encoded, btc, acct, net)
+			if err != nil {
+				return err
+			}
+			if derivers[chain], err = hd.NewParentIndexDeriver(parent, chain, btc, net); err != nil {
+				return err
+			}
+			if chain == models.ChainBTC {
+				if changeDeriver, err = xpubChangeDeriver(encoded, btc, acct, net); err != nil {
+					return err
+				}
+			}
+		}
+	} else {
+		seed, err := loadSeed(cfg)
+		if err != nil {
+			return err
+		}
+		hd.MlockBytes(seed)
+		defer func() {
+			hd.MunlockBytes(seed)
+			hd.ZeroBytes(seed)
+		}()
+
+		for _, chain := range selected {
+			if derivers[chain], err = hd.NewSeedIndexDeriver(seed, chain, btc, acct, net); err != nil {
+				return err
+			}
+			if chain == models.ChainBTC {
+				if changeDeriver, err = hd.NewSeedChangeIndexDeriver(seed, btc, acct, net); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	database, err := db.New(cfg.DBPath, cfg.Network)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+	defer database.Close()
+
+	if err := database.RunMigrations(); err != nil {
+		return fmt.Errorf("run migrations: %w", err)
+	}
+
+	database = database.WithAccount(cfg.Account)
+
+	sc, err := scanner.SetupScanner(database, cfg, scanner.NewSSEHub())
+	if err != nil {
+		return fmt.Errorf("setup scanner: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	report := discoverReport{
+		Network:     cfg.Network,
+		Account:     acct,
+		GapLimit:    *gapLimit,
+		Source:      source,
+		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		DryRun:      *dryRun,
+		Branches:    []*scanner.DiscoveryResult{},
+		Extended:    []*hd.ExtendResult{},
+	}
+
+	progress := func(chain models.Chain, generated, total int) {
+		slog.Info("storing discovered addresses", "chain", chain, "generated", generated, "total", total)
+	}
+
+	for _, chain := range selected {
+		derive, ok := derivers[chain]
+		if !ok {
+			continue
+		}
+
+		result, err := discoverBranch(ctx, sc, database, chain, config.DiscoveryBranchExternal, derive, *gapLimit, *dryRun)
+		if err != nil {
+			return err
+		}
+		report.Branches = append(report.Branches, result)
+
+		if !*dryRun && result.LastUsedIndex >= 0 {
+			extended, err := hd.ExtendAddresses(database, chain, derive, result.LastUsedIndex+1, progress)
+			if err != nil {
+				return err
+			}
+			report.Extended = append(report.Extended, extended)
+			if extended.Added > 0 {
+				if err := hd.ExportAccountAddresses(database, chain, cfg.Network, btc, acct, ""); err != nil {
+					slog.Error("export failed", "chain", chain, "error", err)
+				}
+			}
+		}
+
+		if chain == models.ChainBTC && changeDeriver != nil {
+			change, err := discoverBranch(ctx, sc, database, chain, config.DiscoveryBranchInternal, changeDeriver, *gapLimit, *dryRun)
+			if err != nil {
+				return err
+			}
+			report.Branches = append(report.Branches, change)
+		}
+	}
+
+	printDiscoverReport(&report)
+
+	if *reportPath != "" {
+		if err := writeJSONReport(*reportPath, &report); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// discoverBranch runs gap-limit discovery on one branch and, unless dryRun, records
+// the outcome in the database.
+func discoverBranch(ctx context.Context, sc *scanner.Scanner, database *db.DB, chain models.Chain, branch int, derive hd.IndexDeriver, gapLimit int, dryRun bool) (*scanner.DiscoveryResult, error) {
+	result, err := sc.Discover(ctx, chain, branch, derive, gapLimit)
+	if err != nil {
+		return nil, fmt.Errorf("discover %s branch %d: %w", chain, branch, err)
+	}
+	if dryRun {
+		return result, nil
+	}
+
+	if err := database.UpsertAddressDiscovery(db.AddressDiscoveryRow{
+		Chain:         string(chain),
+		Branch:        branch,
+		GapLimit:      gapLimit,
+		LastUsedIndex: result.LastUsedIndex,
+		UsedCount:     result.Used,
+		Scanned:       result.Scanned,
+	}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// xpubChangeDeriver parses the BTC account xpub, checks it belongs to account and
+// returns an IndexDeriver over its internal (change) chain.
+func xpubChangeDeriver(encoded string, btcType models.BTCAddressType, account uint32, net *chaincfg.Params) (hd.IndexDeriver, error) {
+	accountKey, err := hd.ParseBTCAccountXPub(encoded, btcType, net)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkXPubAccount(models.ChainBTC, accountKey, account); err != nil {
+		return nil, err
+	}
+	parent, err := hd.DeriveInternalParentFromAccount(accountKey)
+	if err != nil {
+		return nil, fmt.Errorf("derive BTC change chain from xpub: %w", err)
+	}
+	return hd.NewParentIndexDeriver(parent, models.ChainBTC, btcType, net)
+}
+
+// printDiscoverReport prints a human-readable summary of the discovery to stderr.
+func printDiscoverReport(r *discoverReport) {
+	for _, b := range r.Branches {
+		name := "receive"
+		if b.Branch == config.DiscoveryBranchInternal {
+			name = "change"
+		}
+		if b.LastUsedIndex < 0 {
+			fmt.Fprintf(os.Stderr, "%s %s: no used address in the first %d (%s)\n", b.Chain, name, b.Scanned, b.Duration)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "%s %s: %d used, last used index %d, %d checked (%s)\n",
+			b.Chain, name, b.Used, b.LastUsedIndex, b.Scanned, b.Duration)
+		if b.Branch == config.DiscoveryBranchInternal {
+			fmt.Fprintf(os.Stderr, "  change addresses are not stored by hdpay; move their funds with a wallet that tracks the change chain\n")
+		}
+	}
+	for _, e := range r.Extended {
+		fmt.Fprintf(os.Stderr, "%s: address set covers indices 0..%d (%d added); run a scan to load their balances\n",
+			e.Chain, e.To-1, e.Added)
+	}
+	if r.DryRun {
+		fmt.Fprintln(os.Stderr, "dry run: nothing was stored")
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/logging"
+	"github.com/Fantasim/hdpay/internal/wallet/hd"
+)
+
+func printKeystoreUsage() {
+	fmt.Fprintf(os.Stderr, `Usage: hdpay keystore <subcommand> [flags]
+
+Subcommands:
+  create           Generate a new 24-word mnemonic and store it encrypted
+  import           Encrypt an existing plaintext mnemonic file
+  change-password  Re-encrypt the keystore under a new password
+`)
+}
+
+// runKeystore manages the encrypted mnemonic keystore (scrypt + AES-256-GCM).
+func runKeystore() error {
+	if len(os.Args) < 3 {
+		printKeystoreUsage()
+		return fmt.Errorf("missing keystore subcommand")
+	}
+	sub := os.Args[2]
+
+	fs := flag.NewFlagSet("keystore "+sub, flag.ExitOnError)
+	keystorePath := fs.String("keystore", "", "Keystore path (default: from HDPAY_KEYSTORE_FILE or "+config.DefaultKeystorePath+")")
+	passwordFD := fs.Int("password-fd", -1, "Read the keystore password from this file descriptor instead of prompting")
+
+	var newPasswordFD *int
+	var mnemonicPath *string
+	var force *bool
+	switch sub {
+	case "create":
+		force = fs.Bool("force", false, "Overwrite an existing keystore")
+	case "import":
+		mnemonicPath = fs.String("mnemonic-file", "", "Path to the plaintext 24-word mnemonic to encrypt (required)")
+		force = fs.Bool("force", false, "Overwrite an existing keystore")
+	case "change-password":
+		newPasswordFD = fs.Int("new-password-fd", -1, "Read the new password from this file descriptor instead of prompting")
+	default:
+		printKeystoreUsage()
+		return fmt.Errorf("unknown keystore subcommand: %s", sub)
+	}
+	fs.Parse(os.Args[3:])
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	logCloser, err := logging.Setup(cfg.LogLevel, cfg.LogDir)
+	if err != nil {
+		return fmt.Errorf("failed to setup logging: %w", err)
+	}
+	defer logCloser.Close()
+
+	path := *keystorePath
+	if path == "" {
+		path = cfg.KeystoreFile
+	}
+	if path == "" {
+		path = config.DefaultKeystorePath
+	}
+
+	switch sub {
+	case "create":
+		return keystoreCreate(path, *passwordFD, *force)
+	case "import":
+		return keystoreImport(path, *mnemonicPath, *passwordFD, *force)
+	default:
+		return keystoreChangePassword(path, *passwordFD, *newPasswordFD)
+	}
+}
+
+// keystoreCreate generates a new mnemonic, shows it once for an offline backup and
+// stores it encrypted. The mnemonic is never written to disk in plaintext.
+func keystoreCreate(path string, passwordFD int, force bool) error {
+	password, err := readNewSecret(passwordFD, "keystore password")
+	if err != nil {
+		return err
+	}
+	defer hd.ZeroBytes(password)
+
+	mnemonic, err := hd.NewMnemonic()
+	if err != nil {
+		return err
+	}
+	hd.MlockBytes(mnemonic)
+	defer func() {
+		hd.MunlockBytes(mnemonic)
+		hd.ZeroBytes(mnemonic)
+	}()
+
+	if err := hd.WriteKeystoreFile(path, mnemonic, password, force); err != nil {
+		return err
+	}
+
+	// Printed to stderr only, so it does not end up in redirected stdout logs.
+	fmt.Fprintf(os.Stderr, "\nWrite down this mnemonic and store it offline. It will not be shown again:\n\n%s\n\n", mnemonic)
+	fmt.Fprintf(os.Stderr, "Keystore written to %s. Set HDPAY_KEYSTORE_FILE=%s to use it.\n", path, path)
+
+	slog.Info("keystore created", "path", path)
+	return nil
+}
+
+// keystoreImport encrypts an existing plaintext mnemonic file. The plaintext file
+// is left in place; the operator is expected to shred it once the keystore is verified.
+func keystoreImport(path, mnemonicPath string, passwordFD int, force bool) error {
+	if mnemonicPath == "" {
+		return fmt.Errorf("--mnemonic-file is required")
+	}
+
+	mnemonic, err := hd.ReadMnemonicBytesFromFile(mnemonicPath)
+	if err != nil {
+		return fmt.Errorf("read mnemonic: %w", err)
+	}
+	hd.MlockBytes(mnemonic)
+	defer func() {
+		hd.MunlockBytes(mnemonic)
+		hd.ZeroBytes(mnemonic)
+	}()
+
+	password, err := readNewSecret(passwordFD, "keystore password")
+	if err != nil {
+		return err
+	}
+	defer hd.ZeroBytes(password)
+
+	if err := hd.WriteKeystoreFile(path, mnemonic, password, force); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Keystore written to %s. Securely delete %s once you have verified it.\n", path, mnemonicPath)
+
+	slog.Info("mnemonic imported into keystore", "path", path, "mnemonicFile", mnemonicPath)
+	return nil
+}
+
+// keystoreChangePassword re-encrypts the keystore under a new password.
+func keystoreChangePassword(path string, passwordFD, newPasswordFD int) error {
+	oldPassword, err := readPassword(passwordFD, "Current keystore password: ")
+	if err != nil {
+		return err
+	}
+	defer hd.ZeroBytes(oldPassword)
+
+	newPassword, err := readNewSecret(newPasswordFD, "keystore password")
+	if err != nil {
+		return err
+	}
+	defer hd.ZeroBytes(newPassword)
+
+	if err := hd.ChangeKeystorePassword(path, oldPassword, newPassword); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Keystore password changed for %s.\n", path)
+	return nil
+}
+
+// unlockKeystore reads the keystore password (from fd, or the terminal when fd < 0)
+// and returns the decrypted mnemonic. The caller should mlock and zero it.
+func unlockKeystore(path string, passwordFD int) ([]byte, error) {
+	password, err := readPassword(passwordFD, "Keystore password: ")
+	if err != nil {
+		return nil, err
+	}
+	defer hd.ZeroBytes(password)
+
+	mnemonic, err := hd.ReadKeystoreFile(path, password)
+	if err != nil {
+		return nil, fmt.Errorf("unlock keystore: %w", err)
+	}
+	return mnemonic, nil
+}
+
+// readPassword reads a password from fd, or prompts on the terminal when fd < 0.
+func readPassword(fd int, prompt string) ([]byte, error) {
+	if fd >= 0 {
+		password, err := hd.ReadSecretFromFD(fd)
+		if err != nil {
+			return nil, fmt.Errorf("read password: %w", err)
+		}
+		return password, nil
+	}
+
+	password, err := hd.ReadSecretFromTerminal(prompt)
+	if err != nil {
+		return nil, fmt.Errorf("prompt password: %w", err)
+	}
+	return password, nil
+}
+
+// readNewSecret reads a new password or passphrase, described by what. Interactive
+// input is asked twice and must match; a secret from a file descriptor is taken as-is.
+func readNewSecret(fd int, what string) ([]byte, error) {
+	secret, err := readPassword(fd, "New "+what+": ")
+	if err != nil {
+		return nil, err
+	}
+	if fd >= 0 {
+		return secret, nil
+	}
+
+	confirm, err := readPassword(fd, "Repeat new "+what+": ")
+	if err != nil {
+		hd.ZeroBytes(secret)
+		return nil, err
+	}
+	defer hd.ZeroBytes(confirm)
+
+	if !bytes.Equal(secret, confirm) {
+		hd.ZeroBytes(secret)
+		return nil, fmt.Errorf("%s entries do not match", what)
+	}
+	return secret, nil
+}
@@ -9,10 +9,14 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/ethereum/go-ethereum/ethclient"
 
 	"github.com/Fantasim/hdpay/internal/wallet/api"
@@ -52,6 +56,41 @@ func main() {
 			slog.Error("export error", "error", err)
 			os.Exit(1)
 		}
+	case "keystore":
+		if err := runKeystore(); err != nil {
+			slog.Error("keystore error", "error", err)
+			os.Exit(1)
+		}
+	case "shares":
+		if err := runShares(); err != nil {
+			slog.Error("shares error", "error", err)
+			os.Exit(1)
+		}
+	case "verify":
+		if err := runVerify(); err != nil {
+			slog.Error("verify error", "error", err)
+			os.Exit(1)
+		}
+	case "discover":
+		if err := runDiscover(); err != nil {
+			slog.Error("discover error", "error", err)
+			os.Exit(1)
+		}
+	case "sign-psbt":
+		if err := runSignPSBT(); err != nil {
+			slog.Error("sign-psbt error", "error", err)
+			os.Exit(1)
+		}
+	case "sign-bundle":
+		if err := runSignBundle(); err != nil {
+			slog.Error("sign-bundle error", "error", err)
+			os.Exit(1)
+		}
+	case "sign-message":
+		if err := runSignMessage(); err != nil {
+			slog.Error("sign-message error", "error", err)
+			os.Exit(1)
+		}
 	case "version":
 		fmt.Printf("hdpay %s\n", version)
 	default:
@@ -65,10 +104,17 @@ func printUsage() {
 	fmt.Fprintf(os.Stderr, `Usage: hdpay <command>
 
 Commands:
-  serve     Start the HTTP server
-  init      Generate HD wallet addresses and store in DB
-  export    Export addresses to JSON files
-  version   Print version information
+  serve         Start the HTTP server
+  init          Generate HD wallet addresses and store in DB (--extend-to appends)
+  export        Export addresses (json, csv, ndjson) or the BTC descriptor
+  keystore      Create, import or re-key the encrypted mnemonic keystore
+  shares        Split the seed into SLIP-39 Shamir shares
+  verify        Audit stored addresses against the seed or xpubs
+  discover      Find used addresses of a restored seed (gap limit) and store them
+  sign-psbt     Sign a BTC consolidation PSBT offline with the seed
+  sign-bundle   Sign a BSC or SOL sweep bundle offline with the seed
+  sign-message  Sign a message with an address key to prove ownership
+  version       Print version information
 `)
 }
 
@@ -87,6 +133,8 @@ func runServe() error {
 	slog.Info("starting hdpay",
 		"version", version,
 		"network", cfg.Network,
+		"account", cfg.Account,
+		"btcAddressType", cfg.BTCType(),
 		"port", cfg.Port,
 		"dbPath", cfg.DBPath,
 		"logLevel", cfg.LogLevel,
@@ -98,7 +146,10 @@ func runServe() error {
 	}
 	defer database.Close()
 
-	slog.Info("database opened", "path", cfg.DBPath)
+	// Everything this server scans, shows and sweeps belongs to one BIP-44 account.
+	database = database.WithAccount(cfg.Account)
+
+	slog.Info("database opened", "path", cfg.DBPath, "account", cfg.Account)
 
 	if err := database.RunMigrations(); err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
@@ -106,9 +157,22 @@ func runServe() error {
 
 	slog.Info("database migrations applied")
 
+	// Key service (derives private keys on demand from mnemonic file or unlocked
+	// keystore + passphrase).
+	// Never constructed in watch-only mode: no secret material on this box.
+	var keyService *tx.KeyService
+	if !cfg.WatchOnly {
+		keyService, err = newKeyService(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to setup key service: %w", err)
+		}
+		defer keyService.Close()
+	}
+
 	// Safety check: ensure configured network matches addresses in DB.
 	// Prevents silent mismatch if HDPAY_NETWORK changed between runs.
-	if err := database.ValidateNetworkConsistency(); err != nil {
+	// Also detects a wrong BIP-39 passphrase when the mnemonic is reachable.
+	if err := database.ValidateNetworkConsistency(keyDerivers(keyService)...); err != nil {
 		return fmt.Errorf("startup safety check failed: %w", err)
 	}
 
@@ -128,11 +192,16 @@ func runServe() error {
 	// Run startup health checks (non-blocking, logs warnings for failing providers).
 	go scanner.RunStartupHealthChecks(cfg)
 
-	// Setup price service.
+	// Setup price service, pricing the registry tokens alongside the built-in coins.
 	ps := price.NewPriceService()
+	tokens, err := database.ListTokens("")
+	if err != nil {
+		return fmt.Errorf("failed to load token registry: %w", err)
+	}
+	ps.SetTokens(tokens)
 
 	// Setup TX services for send functionality.
-	sendDeps, txReconciler, err := setupSendDeps(database, cfg, hubCtx)
+	sendDeps, txReconciler, err := setupSendDeps(database, cfg, keyService, hubCtx)
 	if err != nil {
 		return fmt.Errorf("failed to setup send dependencies: %w", err)
 	}
@@ -140,7 +209,10 @@ func runServe() error {
 	slog.Info("send services initialized")
 
 	// Reconcile any pending transactions from previous server runs (non-blocking).
-	go txReconciler.ReconcilePending(hubCtx)
+	// Watch-only servers never broadcast, so there is nothing to reconcile.
+	if txReconciler != nil {
+		go txReconciler.ReconcilePending(hubCtx)
+	}
 
 	// Extract the embedded SPA build directory (strip the "build/" prefix from the embed FS).
 	staticFS, err := fs.Sub(walletweb.StaticFiles, "build")
@@ -204,20 +276,39 @@ func runServe() error {
 
 func runInit() error {
 	fs := flag.NewFlagSet("init", flag.ExitOnError)
-	mnemonicFile := fs.String("mnemonic-file", "", "Path to file containing 24-word BIP-39 mnemonic (required)")
+	mnemonicFile := fs.String("mnemonic-file", "", "Path to file containing 24-word BIP-39 mnemonic (required unless --keystore or --share-file)")
+	keystoreFile := fs.String("keystore", "", "Path to the encrypted mnemonic keystore (default: from HDPAY_KEYSTORE_FILE)")
+	var shareFiles shareFileFlags
+	fs.Var(&shareFiles, "share-file", "SLIP-39 share file to recover the seed from (repeatable; default: from HDPAY_SHARE_FILES)")
 	dbPath := fs.String("db", "", "Database path (default: from HDPAY_DB_PATH or ./data/hdpay.sqlite)")
 	network := fs.String("network", "", "Network: mainnet or testnet (default: from HDPAY_NETWORK or testnet)")
-	count := fs.Int("count", 0, "Number of addresses per chain (required, max: 500000)")
+	count := fs.Int("count", 0, "Number of addresses per chain (required unless --extend-to, max: 500000)")
+	extendTo := fs.Int("extend-to", 0, "Append addresses to the existing set, from its highest index up to this many per chain (max: 500000)")
+	account := fs.Int("account", -1, "BIP-44 account to derive (default: from HDPAY_ACCOUNT or 0)")
+	btcType := fs.String("btc-type", "", "BTC address type: p2wpkh, p2tr, p2sh-p2wpkh or p2pkh (default: from HDPAY_BTC_ADDRESS_TYPE or p2wpkh)")
+	passphraseFile := fs.String("passphrase-file", "", "Path to file containing the optional BIP-39 (or SLIP-39) passphrase")
+	passphrasePrompt := fs.Bool("passphrase-prompt", false, "Prompt for the optional BIP-39 (or SLIP-39) passphrase on the terminal")
+	xpubs := xpubFlags{}
+	fs.Var(xpubs, "xpub", "Watch-only: account-level extended public key as chain=key (repeatable, e.g. BTC=zpub... BSC=xpub...)")
 	fs.Parse(os.Args[2:])
 
-	if *count == 0 {
-		return fmt.Errorf("--count is required: specify the number of addresses per chain (1 to %d)\n\nExample: hdpay init --mnemonic-file /path/to/mnemonic.txt --count 5000", config.MaxAddressesPerChain)
+	if *count != 0 && *extendTo != 0 {
+		return fmt.Errorf("--count and --extend-to are mutually exclusive")
 	}
-	if *count < 0 {
-		return fmt.Errorf("--count must be positive, got %d", *count)
+	if *extendTo < 0 || *extendTo > config.MaxAddressesPerChain {
+		return fmt.Errorf("--extend-to must be 1 to %d, got %d", config.MaxAddressesPerChain, *extendTo)
 	}
-	if *count > config.MaxAddressesPerChain {
-		return fmt.Errorf("--count must not exceed %d, got %d", config.MaxAddressesPerChain, *count)
+	extend := *extendTo > 0
+	if !extend {
+		if *count == 0 {
+			return fmt.Errorf("--count is required: specify the number of addresses per chain (1 to %d)\n\nExample: hdpay init --mnemonic-file /path/to/mnemonic.txt --count 5000", config.MaxAddressesPerChain)
+		}
+		if *count < 0 {
+			return fmt.Errorf("--count must be positive, got %d", *count)
+		}
+		if *count > config.MaxAddressesPerChain {
+			return fmt.Errorf("--count must not exceed %d, got %d", config.MaxAddressesPerChain, *count)
+		}
 	}
 
 	cfg, err := config.Load()
@@ -232,44 +323,68 @@ func runInit() error {
 	defer logCloser.Close()
 
 	// Override config with flags if provided.
-	if *mnemonicFile != "" {
-		cfg.MnemonicFile = *mnemonicFile
-	}
+	overrideSeedSource(cfg, *mnemonicFile, *keystoreFile, shareFiles)
 	if *dbPath != "" {
 		cfg.DBPath = *dbPath
 	}
 	if *network != "" {
 		cfg.Network = *network
 	}
+	if *account >= 0 {
+		cfg.Account = *account
+	}
+	if *btcType != "" {
+		cfg.BTCAddressType = *btcType
+	}
+	if *passphraseFile != "" {
+		cfg.PassphraseFile = *passphraseFile
+	}
+	if *passphrasePrompt {
+		cfg.PassphrasePrompt = true
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
 
-	if cfg.MnemonicFile == "" {
-		return fmt.Errorf("--mnemonic-file is required (or set HDPAY_MNEMONIC_FILE)")
+	watchOnly := len(xpubs) > 0
+	if watchOnly && (*mnemonicFile != "" || *keystoreFile != "" || len(shareFiles) > 0) {
+		return fmt.Errorf("--xpub and --mnemonic-file/--keystore/--share-file are mutually exclusive")
+	}
+	if !watchOnly && !cfg.HasSeedSource() {
+		return fmt.Errorf("--mnemonic-file, --keystore or --share-file is required (or set HDPAY_MNEMONIC_FILE/HDPAY_KEYSTORE_FILE/HDPAY_SHARE_FILES), or pass --xpub for a watch-only wallet")
 	}
 
 	slog.Info("starting address initialization",
 		"mnemonicFile", cfg.MnemonicFile,
+		"keystoreFile", cfg.KeystoreFile,
+		"shareFiles", len(cfg.ShareFiles),
+		"watchOnly", watchOnly,
 		"dbPath", cfg.DBPath,
 		"network", cfg.Network,
+		"account", cfg.Account,
+		"btcAddressType", cfg.BTCType(),
 		"countPerChain", *count,
+		"extendTo", *extendTo,
 	)
 
-	// Read and validate mnemonic.
-	mnemonic, err := hd.ReadMnemonicFromFile(cfg.MnemonicFile)
-	if err != nil {
-		return fmt.Errorf("read mnemonic: %w", err)
-	}
+	net := hd.NetworkParams(cfg.Network)
 
-	// Derive seed.
-	seed, err := hd.MnemonicToSeed(mnemonic)
-	if err != nil {
-		return fmt.Errorf("derive seed: %w", err)
+	var indexDerivers map[models.Chain]hd.IndexDeriver
+	var derivers []db.AddressDeriver
+	if watchOnly {
+		indexDerivers, err = watchOnlyIndexDerivers(xpubs, cfg.BTCType(), uint32(cfg.Account), net)
+	} else {
+		var seed []byte
+		seed, err = loadSeed(cfg)
+		if err != nil {
+			return err
+		}
+		var derive db.AddressDeriver
+		indexDerivers, derive, err = seedIndexDerivers(seed, cfg.BTCType(), uint32(cfg.Account), net)
+		derivers = append(derivers, derive)
 	}
-
-	// Derive master key for BTC/BSC (BIP-32).
-	net := hd.NetworkParams(cfg.Network)
-	masterKey, err := hd.DeriveMasterKey(seed, net)
 	if err != nil {
-		return fmt.Errorf("derive master key: %w", err)
+		return err
 	}
 
 	// Open database.
@@ -283,6 +398,15 @@ func runInit() error {
 		return fmt.Errorf("run migrations: %w", err)
 	}
 
+	// Each account has its own address set in the shared database.
+	database = database.WithAccount(cfg.Account)
+
+	// Refuse to top up an existing address set that was derived from different
+	// key material (e.g. the same mnemonic with another BIP-39 passphrase).
+	if err := database.ValidateNetworkConsistency(derivers...); err != nil {
+		return fmt.Errorf("existing addresses do not match: %w", err)
+	}
+
 	progress := func(chain models.Chain, generated int, total int) {
 		slog.Info("address generation progress",
 			"chain", chain,
@@ -292,33 +416,25 @@ func runInit() error {
 		)
 	}
 
+	if extend {
+		return extendAddressSets(database, cfg, indexDerivers, *extendTo, progress)
+	}
+
 	totalStart := time.Now()
 
 	// Generate all chains in parallel — BTC, BSC, SOL are independent.
-	type chainJob struct {
-		chain    models.Chain
-		generate func() ([]models.Address, error)
-	}
-	jobs := []chainJob{
-		{models.ChainBTC, func() ([]models.Address, error) {
-			return hd.GenerateBTCAddresses(masterKey, *count, net, progress)
-		}},
-		{models.ChainBSC, func() ([]models.Address, error) {
-			return hd.GenerateBSCAddresses(masterKey, *count, progress)
-		}},
-		{models.ChainSOL, func() ([]models.Address, error) {
-			return hd.GenerateSOLAddresses(seed, *count, progress)
-		}},
-	}
-
 	var wg sync.WaitGroup
-	errs := make([]error, len(jobs))
-	for i, job := range jobs {
+	errs := make([]error, len(models.AllChains))
+	for i, chain := range models.AllChains {
+		derive, ok := indexDerivers[chain]
+		if !ok {
+			continue
+		}
 		wg.Add(1)
-		go func(idx int, j chainJob) {
+		go func(idx int, chain models.Chain, derive hd.IndexDeriver) {
 			defer wg.Done()
-			errs[idx] = generateAndStore(database, j.chain, *count, j.generate)
-		}(i, job)
+			errs[idx] = generateAndStore(database, chain, *count, derive, progress)
+		}(i, chain, derive)
 	}
 	wg.Wait()
 
@@ -335,17 +451,323 @@ func runInit() error {
 	// Auto-export after generation.
 	slog.Info("exporting addresses to JSON")
 	for _, chain := range models.AllChains {
-		if err := hd.ExportAddresses(database, chain, cfg.Network, ""); err != nil {
+		if _, ok := indexDerivers[chain]; !ok {
+			continue
+		}
+		if err := hd.ExportAccountAddresses(database, chain, cfg.Network, cfg.BTCType(), uint32(cfg.Account), ""); err != nil {
 			slog.Error("export failed", "chain", chain, "error", err)
 		}
 	}
 
+	if watchOnly {
+		slog.Info("watch-only wallet initialized; run the server with HDPAY_WATCH_ONLY=true")
+	}
+
 	return nil
 }
 
-// generateAndStore generates addresses for a chain and stores them in DB.
+// seedIndexDerivers returns an IndexDeriver per chain of the given BIP-44 account
+// (BTC addresses of btcType), plus an AddressDeriver over the same seed for the key
+// consistency check.
+func seedIndexDerivers(seed []byte, btcType models.BTCAddressType, account uint32, net *chaincfg.Params) (map[models.Chain]hd.IndexDeriver, db.AddressDeriver, error) {
+	indexDerivers := make(map[models.Chain]hd.IndexDeriver, len(models.AllChains))
+	for _, chain := range models.AllChains {
+		derive, err := hd.NewSeedIndexDeriver(seed, chain, btcType, account, net)
+		if err != nil {
+			return nil, nil, err
+		}
+		indexDerivers[chain] = derive
+	}
+
+	derive := func(chain models.Chain, index int) (string, error) {
+		return hd.DeriveAccountAddressFromSeed(seed, chain, btcType, account, uint32(index), net)
+	}
+	return indexDerivers, derive, nil
+}
+
+// watchOnlyIndexDerivers parses the account-level xpubs and returns an IndexDeriver
+// for each chain they cover. SOL is never included: its path is fully hardened
+// (SLIP-10 ed25519), so addresses cannot be derived from public material.
+// Every xpub must be the key of the BIP-44 account being initialized; the BTC xpub
+// must be exported for the purpose of btcType.
+func watchOnlyIndexDerivers(xpubs xpubFlags, btcType models.BTCAddressType, account uint32, net *chaincfg.Params) (map[models.Chain]hd.IndexDeriver, error) {
+	indexDerivers := make(map[models.Chain]hd.IndexDeriver, len(xpubs))
+	for chain, encoded := range xpubs {
+		parent, err := xpubExternalParent(chain, encoded, btcType, account, net)
+		if err != nil {
+			return nil, err
+		}
+		if indexDerivers[chain], err = hd.NewParentIndexDeriver(parent, chain, btcType, net); err != nil {
+			return nil, err
+		}
+	}
+
+	slog.Warn("watch-only init: SOL addresses are skipped (hardened ed25519 derivation requires the seed)")
+	return indexDerivers, nil
+}
+
+// extendAddressSets appends addresses to every chain with a deriver until it holds
+// target addresses, then re-exports the chains that grew. Chains are extended in
+// parallel like a full init.
+func extendAddressSets(database *db.DB, cfg *config.Config, indexDerivers map[models.Chain]hd.IndexDeriver, target int, progress hd.ProgressCallback) error {
+	totalStart := time.Now()
+
+	var mu sync.Mutex
+	var grown []models.Chain
+	var wg sync.WaitGroup
+	errs := make([]error, len(models.AllChains))
+	for i, chain := range models.AllChains {
+		derive, ok := indexDerivers[chain]
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func(idx int, chain models.Chain, derive hd.IndexDeriver) {
+			defer wg.Done()
+			result, err := hd.ExtendAddresses(database, chain, derive, target, progress)
+			if err != nil {
+				errs[idx] = err
+				return
+			}
+			if result.Added > 0 {
+				mu.Lock()
+				grown = append(grown, chain)
+				mu.Unlock()
+			}
+		}(i, chain, derive)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	slog.Info("address extension complete",
+		"target", target,
+		"grownChains", grown,
+		"totalDuration", time.Since(totalStart).Round(time.Millisecond),
+	)
+
+	for _, chain := range grown {
+		if err := hd.ExportAccountAddresses(database, chain, cfg.Network, cfg.BTCType(), uint32(cfg.Account), ""); err != nil {
+			slog.Error("export failed", "chain", chain, "error", err)
+		}
+	}
+	return nil
+}
+
+// xpubExternalParent parses the account-level xpub of chain (BTC or BSC), checks it
+// belongs to account and returns its external chain key (account/0).
+func xpubExternalParent(chain models.Chain, encoded string, btcType models.BTCAddressType, account uint32, net *chaincfg.Params) (*hdkeychain.ExtendedKey, error) {
+	var accountKey *hdkeychain.ExtendedKey
+	var err error
+	if chain == models.ChainBTC {
+		accountKey, err = hd.ParseBTCAccountXPub(encoded, btcType, net)
+	} else {
+		accountKey, err = hd.ParseBSCAccountXPub(encoded)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := checkXPubAccount(chain, accountKey, account); err != nil {
+		return nil, err
+	}
+	parent, err := hd.DeriveExternalParentFromAccount(accountKey)
+	if err != nil {
+		return nil, fmt.Errorf("derive %s external chain from xpub: %w", chain, err)
+	}
+	return parent, nil
+}
+
+// checkXPubAccount rejects an xpub exported for a different BIP-44 account than the
+// one being initialized, which would silently file its addresses under the wrong account.
+func checkXPubAccount(chain models.Chain, accountKey *hdkeychain.ExtendedKey, account uint32) error {
+	if got := hd.XPubAccount(accountKey); got != account {
+		return fmt.Errorf("%w: %s xpub is for account %d, but initializing account %d (use --account %d)",
+			hd.ErrInvalidXPub, chain, got, account, got)
+	}
+	return nil
+}
+
+// loadPassphrase resolves the optional BIP-39 passphrase from HDPAY_PASSPHRASE_FILE
+// or an interactive terminal prompt. Returns nil when neither is configured.
+// The caller should zero the returned slice after use.
+func loadPassphrase(cfg *config.Config) ([]byte, error) {
+	switch {
+	case cfg.PassphraseFile != "":
+		passphrase, err := hd.ReadPassphraseFromFile(cfg.PassphraseFile)
+		if err != nil {
+			return nil, fmt.Errorf("read passphrase: %w", err)
+		}
+		return passphrase, nil
+	case cfg.PassphrasePrompt:
+		passphrase, err := hd.ReadSecretFromTerminal("BIP-39 passphrase: ")
+		if err != nil {
+			return nil, fmt.Errorf("prompt passphrase: %w", err)
+		}
+		return passphrase, nil
+	default:
+		return nil, nil
+	}
+}
+
+// loadMnemonic returns the validated mnemonic from the keystore (unlocking it with
+// a prompted or fd-supplied password) or from the plaintext mnemonic file.
+// The caller should zero the returned slice after use.
+func loadMnemonic(cfg *config.Config) ([]byte, error) {
+	if cfg.KeystoreFile != "" {
+		return unlockKeystore(cfg.KeystoreFile, cfg.KeystorePasswordFD)
+	}
+	mnemonic, err := hd.ReadMnemonicBytesFromFile(cfg.MnemonicFile)
+	if err != nil {
+		return nil, fmt.Errorf("read mnemonic: %w", err)
+	}
+	return mnemonic, nil
+}
+
+// loadSeed returns the BIP-32 seed from the configured source: recovered from
+// SLIP-39 share files (the passphrase is the SLIP-39 passphrase), or derived from
+// the BIP-39 mnemonic in the keystore or mnemonic file (the passphrase is the
+// BIP-39 passphrase). The caller should zero the returned slice after use.
+func loadSeed(cfg *config.Config) ([]byte, error) {
+	if len(cfg.ShareFiles) > 0 {
+		passphrase, err := loadPassphrase(cfg)
+		if err != nil {
+			return nil, err
+		}
+		defer hd.ZeroBytes(passphrase)
+
+		seed, err := hd.SLIP39SeedFromFiles(cfg.ShareFiles, passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("recover seed from shares: %w", err)
+		}
+		return seed, nil
+	}
+
+	mnemonic, err := loadMnemonic(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer hd.ZeroBytes(mnemonic)
+
+	passphrase, err := loadPassphrase(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer hd.ZeroBytes(passphrase)
+
+	seed, err := hd.MnemonicBytesToSeedWithPassphrase(mnemonic, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("derive seed: %w", err)
+	}
+	return seed, nil
+}
+
+// overrideSeedSource applies the --mnemonic-file / --keystore / --share-file flags.
+// A seed source given on the command line replaces the ones from the environment;
+// giving more than one is rejected by Validate.
+func overrideSeedSource(cfg *config.Config, mnemonicFile, keystoreFile string, shareFiles []string) {
+	if mnemonicFile == "" && keystoreFile == "" && len(shareFiles) == 0 {
+		return
+	}
+	cfg.MnemonicFile = mnemonicFile
+	cfg.KeystoreFile = keystoreFile
+	cfg.ShareFiles = shareFiles
+}
+
+// newKeyService creates the KeyService with the configured BIP-39 passphrase installed.
+// With a keystore, the password is asked once here and the decrypted mnemonic stays
+// mlocked inside the KeyService until Close.
+func newKeyService(cfg *config.Config) (*tx.KeyService, error) {
+	var mnemonic []byte
+	if cfg.KeystoreFile != "" {
+		var err error
+		mnemonic, err = unlockKeystore(cfg.KeystoreFile, cfg.KeystorePasswordFD)
+		if err != nil {
+			return nil, err
+		}
+		defer hd.ZeroBytes(mnemonic)
+	}
+
+	passphrase, err := loadPassphrase(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer hd.ZeroBytes(passphrase)
+
+	keyService := tx.NewKeyService(cfg.MnemonicFile, cfg.Network)
+	if len(cfg.ShareFiles) > 0 {
+		keyService.SetShareFiles(cfg.ShareFiles)
+	}
+	keyService.SetAccount(uint32(cfg.Account))
+	keyService.SetBTCAddressType(cfg.BTCType())
+	keyService.SetUnlockedMnemonic(mnemonic)
+	keyService.SetPassphrase(passphrase)
+	return keyService, nil
+}
+
+// keyDerivers returns the derivers for the key consistency check. The check is
+// skipped (with a warning) when the mnemonic is not reachable, e.g. the wallet
+// disk is not plugged in yet — it will be re-read on demand at signing time.
+func keyDerivers(keyService *tx.KeyService) []db.AddressDeriver {
+	if keyService == nil {
+		return nil
+	}
+	if err := keyService.CheckMnemonicAvailable(); err != nil {
+		slog.Warn("mnemonic not available, skipping key consistency check", "error", err)
+		return nil
+	}
+	return []db.AddressDeriver{keyService.DeriveAddress}
+}
+
+// shareFileFlags collects repeated --share-file flags.
+type shareFileFlags []string
+
+func (s *shareFileFlags) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *shareFileFlags) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// xpubFlags collects repeated --xpub chain=key flags.
+type xpubFlags map[models.Chain]string
+
+func (x xpubFlags) String() string {
+	chains := make([]string, 0, len(x))
+	for chain := range x {
+		chains = append(chains, string(chain))
+	}
+	return strings.Join(chains, ",")
+}
+
+func (x xpubFlags) Set(value string) error {
+	chainStr, key, ok := strings.Cut(value, "=")
+	if !ok || strings.TrimSpace(key) == "" {
+		return fmt.Errorf("expected chain=key, got %q", value)
+	}
+
+	chain := models.Chain(strings.ToUpper(strings.TrimSpace(chainStr)))
+	switch chain {
+	case models.ChainBTC, models.ChainBSC:
+	case models.ChainSOL:
+		return fmt.Errorf("SOL does not support watch-only derivation from an xpub")
+	default:
+		return fmt.Errorf("unsupported chain %q (want BTC or BSC)", chainStr)
+	}
+
+	x[chain] = strings.TrimSpace(key)
+	return nil
+}
+
+// generateAndStore generates addresses for a chain with derive and stores them in DB,
+// inserting each batch in index order as soon as it is generated.
 // Skips if the chain already has the expected count of addresses.
-func generateAndStore(database *db.DB, chain models.Chain, expectedCount int, generate func() ([]models.Address, error)) error {
+func generateAndStore(database *db.DB, chain models.Chain, expectedCount int, derive hd.IndexDeriver, progress hd.ProgressCallback) error {
 	existing, err := database.CountAddresses(chain)
 	if err != nil {
 		return fmt.Errorf("count existing %s addresses: %w", chain, err)
@@ -370,26 +792,33 @@ func generateAndStore(database *db.DB, chain models.Chain, expectedCount int, ge
 		}
 	}
 
-	addresses, err := generate()
+	err = hd.GenerateBatches(chain, derive, 0, expectedCount, progress, func(batch []models.Address) error {
+		return database.InsertAddressBatch(chain, batch)
+	})
 	if err != nil {
 		return fmt.Errorf("generate %s addresses: %w", chain, err)
 	}
 
-	if err := database.InsertAddressBatch(chain, addresses); err != nil {
-		return fmt.Errorf("insert %s addresses: %w", chain, err)
-	}
-
 	return nil
 }
 
 // setupSendDeps initializes all transaction services needed for the send handlers.
-// Also returns a TxReconciler for startup reconciliation of pending transactions.
-func setupSendDeps(database *db.DB, cfg *config.Config, hubCtx context.Context) (*handlers.SendDeps, *tx.TxReconciler, error) {
+// Also returns a TxReconciler for startup reconciliation of pending transactions
+// (nil in watch-only mode, where only DB/Config/NetParams are populated).
+func setupSendDeps(database *db.DB, cfg *config.Config, keyService *tx.KeyService, hubCtx context.Context) (*handlers.SendDeps, *tx.TxReconciler, error) {
 	netParams := hd.NetworkParams(cfg.Network)
 	httpClient := &http.Client{Timeout: config.APITimeout}
 
-	// Key service (derives private keys on demand from mnemonic file).
-	keyService := tx.NewKeyService(cfg.MnemonicFile, cfg.Network)
+	// Watch-only: no secret material on this box, so no key service and no TX services.
+	if cfg.WatchOnly {
+		slog.Info("watch-only mode: signing disabled, send routes unavailable")
+		return &handlers.SendDeps{
+			DB:        database,
+			Config:    cfg,
+			NetParams: netParams,
+			WatchOnly: true,
+		}, nil, nil
+	}
 
 	// BTC services.
 	var btcProviderURLs []string
@@ -410,13 +839,20 @@ func setupSendDeps(database *db.DB, cfg *config.Config, hubCtx context.Context)
 
 	utxoFetcher := tx.NewBTCUTXOFetcher(httpClient, btcProviderURLs, btcRateLimiters)
 
-	var mempoolURL string
+	var mempoolURL, esploraURL string
 	if cfg.Network == string(models.NetworkTestnet) {
 		mempoolURL = config.MempoolTestnetURL
+		esploraURL = config.BlockstreamTestnetURL
 	} else {
 		mempoolURL = config.MempoolMainnetURL
+		esploraURL = config.BlockstreamMainnetURL
 	}
 	feeEstimator := tx.NewBTCFeeEstimator(httpClient, mempoolURL)
+	feeEstimator.SetEsploraURL(esploraURL)
+	if cfg.BTCCoreRPCURL != "" {
+		feeEstimator.SetCoreRPCURL(cfg.BTCCoreRPCURL)
+	}
+	feeEstimator.SetFallbackFeeRate(int64(cfg.BTCFeeRate))
 	broadcaster := tx.NewBTCBroadcaster(httpClient, btcProviderURLs)
 
 	// TX SSE Hub — created early so it can be passed to consolidation services.
@@ -424,6 +860,17 @@ func setupSendDeps(database *db.DB, cfg *config.Config, hubCtx context.Context)
 	go txHub.Run(hubCtx)
 
 	btcService := tx.NewBTCConsolidationService(keyService, utxoFetcher, feeEstimator, broadcaster, database, netParams, httpClient, btcProviderURLs, txHub)
+	btcService.SetChunkLimits(cfg.BTCChunkMaxInputs, cfg.BTCChunkMaxVsize)
+
+	// PSBT key origins: a configured xpub + master fingerprint lets previews carry
+	// derivation info without touching the seed; otherwise the key service derives it.
+	if cfg.BTCXPub != "" {
+		keyOrigin, err := tx.NewXPubKeyOrigin(cfg.BTCXPub, cfg.BTCMasterFingerprint, cfg.BTCType(), uint32(cfg.Account), netParams)
+		if err != nil {
+			return nil, nil, fmt.Errorf("BTC PSBT key origin: %w", err)
+		}
+		btcService.SetKeyOriginSource(keyOrigin)
+	}
 
 	// BSC services.
 	var bscRPCURL string
@@ -459,8 +906,11 @@ func setupSendDeps(database *db.DB, cfg *config.Config, hubCtx context.Context)
 	bscChainID := tx.BSCChainID(cfg.Network)
 	bscService := tx.NewBSCConsolidationService(keyService, bscClient, database, bscChainID, txHub)
 	gasPreSeedService := tx.NewGasPreSeedService(keyService, bscClient, database, bscChainID)
+	bscService.SetDynamicFees(cfg.BSCDynamicFee)
+	bscService.SetSweepWorkers(cfg.BSCSweepWorkers)
+	gasPreSeedService.SetDynamicFees(cfg.BSCDynamicFee)
 
-	slog.Info("BSC services initialized", "rpcURL", bscRPCURL, "chainID", bscChainID)
+	slog.Info("BSC services initialized", "rpcURL", bscRPCURL, "chainID", bscChainID, "dynamicFee", cfg.BSCDynamicFee, "sweepWorkers", cfg.BSCSweepWorkers)
 
 	// SOL services.
 	var solRPCURLs []string
@@ -475,6 +925,7 @@ func setupSendDeps(database *db.DB, cfg *config.Config, hubCtx context.Context)
 
 	solRPCClient := tx.NewDefaultSOLRPCClient(httpClient, solRPCURLs)
 	solService := tx.NewSOLConsolidationService(keyService, solRPCClient, database, cfg.Network, txHub)
+	solService.SetNonceAccounts(cfg.SOLNonceAccounts)
 
 	slog.Info("SOL services initialized", "rpcURLs", solRPCURLs)
 
@@ -504,8 +955,38 @@ func runExport() error {
 	dbPath := fs.String("db", "", "Database path (default: from HDPAY_DB_PATH or ./data/hdpay.sqlite)")
 	network := fs.String("network", "", "Network: mainnet or testnet (default: from HDPAY_NETWORK or testnet)")
 	outputDir := fs.String("output", "", "Output directory (default: ./data/export)")
+	account := fs.Int("account", -1, "BIP-44 account to export (default: from HDPAY_ACCOUNT or 0)")
+	btcType := fs.String("btc-type", "", "BTC address type recorded in the export (default: from HDPAY_BTC_ADDRESS_TYPE or p2wpkh)")
+	mnemonicFile := fs.String("mnemonic-file", "", "Optional: verify stored addresses against this mnemonic before exporting")
+	keystoreFile := fs.String("keystore", "", "Optional: verify stored addresses against this encrypted keystore before exporting")
+	var shareFiles shareFileFlags
+	fs.Var(&shareFiles, "share-file", "Optional: verify stored addresses against the seed in these SLIP-39 share files (repeatable)")
+	passphraseFile := fs.String("passphrase-file", "", "Path to file containing the optional BIP-39 (or SLIP-39) passphrase")
+	passphrasePrompt := fs.Bool("passphrase-prompt", false, "Prompt for the optional BIP-39 (or SLIP-39) passphrase on the terminal")
+	format := fs.String("format", string(models.ExportFormatJSON), "Export format: json, csv, ndjson or descriptor (BTC importdescriptors JSON)")
+	balances := fs.Bool("balances", false, "Include stored balances and last scan time per address (json, csv, ndjson)")
+	xpubs := xpubFlags{}
+	fs.Var(xpubs, "xpub", "For --format descriptor without a seed: the BTC account xpub as BTC=key")
+	fingerprint := fs.String("master-fingerprint", "", "For --format descriptor with --xpub: hex master key fingerprint to record the key origin")
 	fs.Parse(os.Args[2:])
 
+	exportFormat := models.ExportFormat(strings.ToLower(*format))
+	if !slices.Contains(models.AllExportFormats, exportFormat) {
+		return fmt.Errorf("%w: %q (want json, csv, ndjson or descriptor)", hd.ErrUnsupportedExportFormat, *format)
+	}
+	if exportFormat == models.ExportFormatDescriptor && *balances {
+		return fmt.Errorf("--balances does not apply to --format descriptor")
+	}
+	if _, ok := xpubs[models.ChainBSC]; ok {
+		return fmt.Errorf("--xpub only takes the BTC account xpub for descriptor export")
+	}
+	if len(xpubs) > 0 && exportFormat != models.ExportFormatDescriptor {
+		return fmt.Errorf("--xpub requires --format descriptor")
+	}
+	if *fingerprint != "" && len(xpubs) == 0 {
+		return fmt.Errorf("--master-fingerprint requires --xpub")
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
@@ -523,6 +1004,22 @@ func runExport() error {
 	if *network != "" {
 		cfg.Network = *network
 	}
+	if *account >= 0 {
+		cfg.Account = *account
+	}
+	if *btcType != "" {
+		cfg.BTCAddressType = *btcType
+	}
+	overrideSeedSource(cfg, *mnemonicFile, *keystoreFile, shareFiles)
+	if *passphraseFile != "" {
+		cfg.PassphraseFile = *passphraseFile
+	}
+	if *passphrasePrompt {
+		cfg.PassphrasePrompt = true
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
 
 	database, err := db.New(cfg.DBPath, cfg.Network)
 	if err != nil {
@@ -534,14 +1031,45 @@ func runExport() error {
 		return fmt.Errorf("run migrations: %w", err)
 	}
 
+	database = database.WithAccount(cfg.Account)
+
+	// Never export an address set that doesn't belong to the configured seed.
+	var keyService *tx.KeyService
+	var derivers []db.AddressDeriver
+	if cfg.HasSeedSource() && !cfg.WatchOnly {
+		keyService, err = newKeyService(cfg)
+		if err != nil {
+			return err
+		}
+		defer keyService.Close()
+		derivers = keyDerivers(keyService)
+	}
+	if err := database.ValidateNetworkConsistency(derivers...); err != nil {
+		return fmt.Errorf("export safety check failed: %w", err)
+	}
+
 	slog.Info("exporting addresses",
 		"dbPath", cfg.DBPath,
 		"network", cfg.Network,
+		"account", cfg.Account,
+		"format", exportFormat,
+		"balances", *balances,
 		"outputDir", *outputDir,
 	)
 
+	if exportFormat == models.ExportFormatDescriptor {
+		return exportBTCDescriptor(database, cfg, keyService, xpubs[models.ChainBTC], *fingerprint, *outputDir)
+	}
+
+	opts := hd.ExportOptions{
+		Network:  cfg.Network,
+		BTCType:  cfg.BTCType(),
+		Account:  uint32(cfg.Account),
+		Format:   exportFormat,
+		Balances: *balances,
+	}
 	for _, chain := range models.AllChains {
-		if err := hd.ExportAddresses(database, chain, cfg.Network, *outputDir); err != nil {
+		if _, err := hd.ExportAddressFile(database, chain, opts, *outputDir); err != nil {
 			slog.Error("export failed", "chain", chain, "error", err)
 			continue
 		}
@@ -550,3 +1078,51 @@ func runExport() error {
 	slog.Info("export complete")
 	return nil
 }
+
+// exportBTCDescriptor writes the BTC account descriptor in Bitcoin Core importdescriptors
+// format, ranged over the stored addresses. The descriptor comes from the seed when
+// keyService is set, otherwise from the account xpub (watch-only), where the key origin
+// is only recorded if the master fingerprint is given.
+func exportBTCDescriptor(database *db.DB, cfg *config.Config, keyService *tx.KeyService, xpub, fingerprint, outputDir string) error {
+	var desc string
+	var err error
+	switch {
+	case xpub != "":
+		net := hd.NetworkParams(cfg.Network)
+		accountKey, err := hd.ParseBTCAccountXPub(xpub, cfg.BTCType(), net)
+		if err != nil {
+			return err
+		}
+		if err := checkXPubAccount(models.ChainBTC, accountKey, uint32(cfg.Account)); err != nil {
+			return err
+		}
+		if desc, err = hd.BTCAccountDescriptor(accountKey, cfg.BTCType(), fingerprint, uint32(cfg.Account), net); err != nil {
+			return err
+		}
+	case keyService != nil:
+		if desc, err = keyService.BTCDescriptor(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("--format descriptor needs the seed (--mnemonic-file, --keystore or --share-file) or --xpub BTC=<account xpub>")
+	}
+
+	count, err := database.CountAddresses(models.ChainBTC)
+	if err != nil {
+		return fmt.Errorf("count BTC addresses: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("no addresses found for chain %s", models.ChainBTC)
+	}
+	createdAt, err := database.FirstAddressCreatedAt(models.ChainBTC)
+	if err != nil {
+		return err
+	}
+
+	path, err := hd.ExportDescriptorFile(hd.NewImportDescriptor(desc, count, createdAt), uint32(cfg.Account), outputDir)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %s. Import it with: bitcoin-cli importdescriptors \"$(cat %s)\"\n", path, path)
+	return nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/logging"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+)
+
+// runSignMessage signs a proof-of-ownership message with the key of the address
+// at (chain, index) and prints the signed message as JSON. It needs no database,
+// so it works on the air-gapped machine holding the seed when the server runs
+// watch-only; POST /api/addresses/{chain}/verify-message checks the result.
+func runSignMessage() error {
+	fs := flag.NewFlagSet("sign-message", flag.ExitOnError)
+	chainFlag := fs.String("chain", "", "Chain of the address: BTC, BSC or SOL")
+	index := fs.Uint("index", 0, "Address index to sign with")
+	message := fs.String("message", "", "Message to sign")
+	messageFile := fs.String("message-file", "", "Read the message to sign from this file (- for stdin)")
+	network := fs.String("network", "", "Network: mainnet or testnet (default: from HDPAY_NETWORK or testnet)")
+	mnemonicFile := fs.String("mnemonic-file", "", "Path to the 24-word BIP-39 mnemonic (default: from HDPAY_MNEMONIC_FILE)")
+	keystoreFile := fs.String("keystore", "", "Path to the encrypted mnemonic keystore (default: from HDPAY_KEYSTORE_FILE)")
+	var shareFiles shareFileFlags
+	fs.Var(&shareFiles, "share-file", "SLIP-39 share file to recover the seed from (repeatable; default: from HDPAY_SHARE_FILES)")
+	passphraseFile := fs.String("passphrase-file", "", "Path to file containing the optional BIP-39 (or SLIP-39) passphrase")
+	passphrasePrompt := fs.Bool("passphrase-prompt", false, "Prompt for the optional BIP-39 (or SLIP-39) passphrase on the terminal")
+	fs.Parse(os.Args[2:])
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	logCloser, err := logging.Setup(cfg.LogLevel, cfg.LogDir)
+	if err != nil {
+		return fmt.Errorf("failed to setup logging: %w", err)
+	}
+	defer logCloser.Close()
+
+	overrideSeedSource(cfg, *mnemonicFile, *keystoreFile, shareFiles)
+	if *network != "" {
+		cfg.Network = *network
+	}
+	if *passphraseFile != "" {
+		cfg.PassphraseFile = *passphraseFile
+	}
+	if *passphrasePrompt {
+		cfg.PassphrasePrompt = true
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if !cfg.HasSeedSource() {
+		return fmt.Errorf("--mnemonic-file, --keystore or --share-file is required (or set HDPAY_MNEMONIC_FILE/HDPAY_KEYSTORE_FILE/HDPAY_SHARE_FILES)")
+	}
+
+	chain := models.Chain(strings.ToUpper(*chainFlag))
+	if chain != models.ChainBTC && chain != models.ChainBSC && chain != models.ChainSOL {
+		return fmt.Errorf("--chain must be BTC, BSC or SOL, got %q", *chainFlag)
+	}
+
+	msg, err := readMessageInput(*message, *messageFile)
+	if err != nil {
+		return err
+	}
+
+	keyService, err := newKeyService(cfg)
+	if err != nil {
+		return err
+	}
+	defer keyService.Close()
+
+	signed, err := keyService.SignMessage(context.Background(), chain, uint32(*index), msg)
+	if err != nil {
+		return fmt.Errorf("sign message: %w", err)
+	}
+
+	encoded, err := json.MarshalIndent(signed, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(encoded))
+	return nil
+}
+
+// readMessageInput returns the --message text, or the contents of --message-file
+// when set. Exactly one of the two must be given.
+func readMessageInput(message, path string) ([]byte, error) {
+	if (message == "") == (path == "") {
+		return nil, fmt.Errorf("exactly one of --message or --message-file is required")
+	}
+	if path == "" {
+		return []byte(message), nil
+	}
+
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("open message: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	// Read one byte past the limit so SignMessage reports the oversized message.
+	raw, err := io.ReadAll(io.LimitReader(r, config.MaxSignMessageBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("read message: %w", err)
+	}
+	return raw, nil
+}
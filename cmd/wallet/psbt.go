@@ -0,0 +1,158 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/logging"
+	"github.com/Fantasim/hdpay/internal/wallet/hd"
+	"github.com/Fantasim/hdpay/internal/wallet/tx"
+)
+
+// runSignPSBT signs a BTC consolidation PSBT exported by the send preview. It is
+// meant for an air-gapped machine holding the seed: it needs no database and no
+// network, signs every input whose BIP-32 derivation matches the seed's master
+// fingerprint, and writes the signed PSBT as base64 for the broadcast endpoint.
+func runSignPSBT() error {
+	fs := flag.NewFlagSet("sign-psbt", flag.ExitOnError)
+	inPath := fs.String("in", "-", "PSBT to sign, binary or base64 (- for stdin)")
+	outPath := fs.String("out", "-", "Write the signed base64 PSBT to this path (- for stdout)")
+	network := fs.String("network", "", "Network: mainnet or testnet (default: from HDPAY_NETWORK or testnet)")
+	mnemonicFile := fs.String("mnemonic-file", "", "Path to the 24-word BIP-39 mnemonic (default: from HDPAY_MNEMONIC_FILE)")
+	keystoreFile := fs.String("keystore", "", "Path to the encrypted mnemonic keystore (default: from HDPAY_KEYSTORE_FILE)")
+	var shareFiles shareFileFlags
+	fs.Var(&shareFiles, "share-file", "SLIP-39 share file to recover the seed from (repeatable; default: from HDPAY_SHARE_FILES)")
+	passphraseFile := fs.String("passphrase-file", "", "Path to file containing the optional BIP-39 (or SLIP-39) passphrase")
+	passphrasePrompt := fs.Bool("passphrase-prompt", false, "Prompt for the optional BIP-39 (or SLIP-39) passphrase on the terminal")
+	fs.Parse(os.Args[2:])
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	logCloser, err := logging.Setup(cfg.LogLevel, cfg.LogDir)
+	if err != nil {
+		return fmt.Errorf("failed to setup logging: %w", err)
+	}
+	defer logCloser.Close()
+
+	overrideSeedSource(cfg, *mnemonicFile, *keystoreFile, shareFiles)
+	if *network != "" {
+		cfg.Network = *network
+	}
+	if *passphraseFile != "" {
+		cfg.PassphraseFile = *passphraseFile
+	}
+	if *passphrasePrompt {
+		cfg.PassphrasePrompt = true
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if !cfg.HasSeedSource() {
+		return fmt.Errorf("--mnemonic-file, --keystore or --share-file is required (or set HDPAY_MNEMONIC_FILE/HDPAY_KEYSTORE_FILE/HDPAY_SHARE_FILES)")
+	}
+
+	// Read and check the PSBT before unlocking the seed, so a bad file fails fast.
+	raw, err := readPSBTInput(*inPath)
+	if err != nil {
+		return err
+	}
+	packet, err := tx.DecodePSBT(raw)
+	if err != nil {
+		return err
+	}
+
+	net := hd.NetworkParams(cfg.Network)
+	summary, err := tx.SummarizePSBT(packet, net)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "PSBT: %d inputs, %d sats in, fee %d sats\n",
+		summary.InputCount, summary.TotalInputSats, summary.FeeSats)
+	for _, out := range summary.Outputs {
+		fmt.Fprintf(os.Stderr, "  pays %d sats to %s\n", out.Value, out.Address)
+	}
+
+	seed, err := loadSeed(cfg)
+	if err != nil {
+		return err
+	}
+	hd.MlockBytes(seed)
+	defer func() {
+		hd.MunlockBytes(seed)
+		hd.ZeroBytes(seed)
+	}()
+
+	masterKey, err := hd.DeriveMasterKey(seed, net)
+	if err != nil {
+		return err
+	}
+	defer masterKey.Zero()
+
+	signed, err := tx.SignPSBT(packet, masterKey)
+	if err != nil {
+		return fmt.Errorf("sign PSBT: %w", err)
+	}
+	if signed == 0 {
+		return fmt.Errorf("no input of this PSBT belongs to the seed (wrong seed, passphrase or network?)")
+	}
+
+	encoded, err := packet.B64Encode()
+	if err != nil {
+		return err
+	}
+	if err := writePSBTOutput(*outPath, encoded); err != nil {
+		return err
+	}
+
+	slog.Info("PSBT signed",
+		"signedInputs", signed,
+		"inputCount", summary.InputCount,
+		"feeSats", summary.FeeSats,
+		"out", *outPath,
+	)
+	fmt.Fprintf(os.Stderr, "Signed %d of %d inputs\n", signed, summary.InputCount)
+	return nil
+}
+
+// readPSBTInput reads a PSBT from a file or stdin, refusing anything larger than
+// the base64 form of the largest accepted PSBT.
+func readPSBTInput(path string) ([]byte, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("open PSBT: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	limit := int64(config.MaxPSBTBytes) * 2
+	raw, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("read PSBT: %w", err)
+	}
+	if int64(len(raw)) > limit {
+		return nil, fmt.Errorf("%w: larger than %d bytes", config.ErrInvalidPSBT, limit)
+	}
+	return raw, nil
+}
+
+// writePSBTOutput writes the base64 PSBT, newline-terminated, to a file or stdout.
+func writePSBTOutput(path, encoded string) error {
+	if path == "-" {
+		_, err := fmt.Fprintln(os.Stdout, encoded)
+		return err
+	}
+	if err := os.WriteFile(path, []byte(encoded+"\n"), 0o600); err != nil {
+		return fmt.Errorf("write PSBT: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/logging"
+	"github.com/Fantasim/hdpay/internal/wallet/hd"
+)
+
+func printSharesUsage() {
+	fmt.Fprintf(os.Stderr, `Usage: hdpay shares <subcommand> [flags]
+
+Subcommands:
+  split  Split the wallet seed into SLIP-39 Shamir shares, one file per share
+`)
+}
+
+// runShares manages SLIP-39 Shamir shares of the wallet seed.
+func runShares() error {
+	if len(os.Args) < 3 {
+		printSharesUsage()
+		return fmt.Errorf("missing shares subcommand")
+	}
+	if os.Args[2] != "split" {
+		printSharesUsage()
+		return fmt.Errorf("unknown shares subcommand: %s", os.Args[2])
+	}
+
+	fs := flag.NewFlagSet("shares split", flag.ExitOnError)
+	mnemonicFile := fs.String("mnemonic-file", "", "Path to the 24-word BIP-39 mnemonic to split (default: from HDPAY_MNEMONIC_FILE)")
+	keystoreFile := fs.String("keystore", "", "Path to the encrypted mnemonic keystore to split (default: from HDPAY_KEYSTORE_FILE)")
+	var shareFiles shareFileFlags
+	fs.Var(&shareFiles, "share-file", "Existing SLIP-39 share file to re-split (repeatable)")
+	passphraseFile := fs.String("passphrase-file", "", "Path to file containing the BIP-39 passphrase of the source mnemonic")
+	passphrasePrompt := fs.Bool("passphrase-prompt", false, "Prompt for the BIP-39 passphrase of the source mnemonic")
+	groupThreshold := fs.Int("group-threshold", 1, "Number of groups required to recover the seed")
+	groupsSpec := fs.String("groups", "2of3", "Comma-separated member thresholds per group, e.g. 2of3,3of5,1of1")
+	sharePassphrasePrompt := fs.Bool("share-passphrase-prompt", false, "Prompt for a SLIP-39 passphrase protecting the new shares")
+	iterationExponent := fs.Int("iteration-exponent", config.SLIP39IterationExponent, "SLIP-39 PBKDF2 iteration exponent (rounds = 10000 << e)")
+	outputDir := fs.String("output-dir", config.DefaultSharesDir, "Directory to write one file per share into")
+	fs.Parse(os.Args[3:])
+
+	groups, err := parseSLIP39Groups(*groupsSpec)
+	if err != nil {
+		return err
+	}
+	if *iterationExponent < 0 || *iterationExponent > 15 {
+		return fmt.Errorf("--iteration-exponent must be 0-15, got %d", *iterationExponent)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	logCloser, err := logging.Setup(cfg.LogLevel, cfg.LogDir)
+	if err != nil {
+		return fmt.Errorf("failed to setup logging: %w", err)
+	}
+	defer logCloser.Close()
+
+	overrideSeedSource(cfg, *mnemonicFile, *keystoreFile, shareFiles)
+	if *passphraseFile != "" {
+		cfg.PassphraseFile = *passphraseFile
+	}
+	if *passphrasePrompt {
+		cfg.PassphrasePrompt = true
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if !cfg.HasSeedSource() {
+		return fmt.Errorf("--mnemonic-file, --keystore or --share-file is required (or set HDPAY_MNEMONIC_FILE/HDPAY_KEYSTORE_FILE/HDPAY_SHARE_FILES)")
+	}
+
+	seed, err := loadSeed(cfg)
+	if err != nil {
+		return err
+	}
+	hd.MlockBytes(seed)
+	defer func() {
+		hd.MunlockBytes(seed)
+		hd.ZeroBytes(seed)
+	}()
+
+	var sharePassphrase []byte
+	if *sharePassphrasePrompt {
+		sharePassphrase, err = readNewSecret(-1, "SLIP-39 passphrase")
+		if err != nil {
+			return err
+		}
+		defer hd.ZeroBytes(sharePassphrase)
+	}
+
+	shares, err := hd.SplitSLIP39(seed, sharePassphrase, *groupThreshold, groups, uint8(*iterationExponent))
+	if err != nil {
+		return err
+	}
+
+	// Never hand out shares that do not recover the seed.
+	if err := verifySLIP39Split(shares, groups, *groupThreshold, sharePassphrase, seed); err != nil {
+		return err
+	}
+
+	paths, err := writeSLIP39Shares(*outputDir, shares)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Wrote %d shares to %s: any %d of %d groups recover the seed.\n", len(paths), *outputDir, *groupThreshold, len(groups))
+	for gi, g := range groups {
+		fmt.Fprintf(os.Stderr, "  group %d: %d of %d shares\n", gi+1, g.Threshold, g.Count)
+	}
+	fmt.Fprintln(os.Stderr, "Hand each file to its holder and delete it from this machine.")
+
+	slog.Info("seed split into SLIP-39 shares",
+		"groupThreshold", *groupThreshold,
+		"groups", len(groups),
+		"shares", len(paths),
+		"outputDir", *outputDir,
+	)
+	return nil
+}
+
+// parseSLIP39Groups parses "2of3,3of5" into member thresholds and counts.
+func parseSLIP39Groups(spec string) ([]hd.SLIP39Group, error) {
+	var groups []hd.SLIP39Group
+	for _, part := range strings.Split(spec, ",") {
+		t, n, ok := strings.Cut(strings.ToLower(strings.TrimSpace(part)), "of")
+		threshold, errT := strconv.Atoi(t)
+		count, errN := strconv.Atoi(n)
+		if !ok || errT != nil || errN != nil {
+			return nil, fmt.Errorf("invalid group %q in --groups: want MofN, e.g. 2of3", part)
+		}
+		groups = append(groups, hd.SLIP39Group{Threshold: threshold, Count: count})
+	}
+	return groups, nil
+}
+
+// verifySLIP39Split recombines the minimum share set (the first groupThreshold
+// groups, each with its member threshold) and checks it yields seed.
+func verifySLIP39Split(shares [][]string, groups []hd.SLIP39Group, groupThreshold int, passphrase, seed []byte) error {
+	var minimal []string
+	for gi := 0; gi < groupThreshold; gi++ {
+		minimal = append(minimal, shares[gi][:groups[gi].Threshold]...)
+	}
+
+	recovered, err := hd.CombineSLIP39Shares(minimal, passphrase)
+	if err != nil {
+		return fmt.Errorf("verify shares: %w", err)
+	}
+	defer hd.ZeroBytes(recovered)
+
+	if !bytes.Equal(recovered, seed) {
+		return fmt.Errorf("verify shares: recovered seed does not match")
+	}
+	return nil
+}
+
+// writeSLIP39Shares writes each share to <dir>/share-g<G>-m<M>.txt with 0600
+// permissions, refusing to overwrite existing files.
+func writeSLIP39Shares(dir string, shares [][]string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create shares directory %q: %w", dir, err)
+	}
+
+	var paths []string
+	for gi, group := range shares {
+		for mi, share := range group {
+			path := filepath.Join(dir, fmt.Sprintf("share-g%d-m%d.txt", gi+1, mi+1))
+			f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+			if err != nil {
+				return paths, fmt.Errorf("create share file: %w", err)
+			}
+			_, err = f.WriteString(share + "\n")
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return paths, fmt.Errorf("write share file %q: %w", path, err)
+			}
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/logging"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/wallet/db"
+	"github.com/Fantasim/hdpay/internal/wallet/hd"
+)
+
+// verifyReport is the machine-readable output of hdpay verify.
+type verifyReport struct {
+	Network        string            `json:"network"`
+	Account        uint32            `json:"account"`
+	BTCAddressType string            `json:"btc_address_type"`
+	Source         string            `json:"source"`
+	GeneratedAt    string            `json:"generated_at"`
+	OK             bool              `json:"ok"`
+	Chains         []*hd.AuditReport `json:"chains"`
+}
+
+// runVerify re-derives every stored address (or a random sample) from the seed or
+// the account xpubs and reports any index whose stored address does not match.
+// It is the control that the address table was not tampered with before funds are
+// sent to or swept from it, and exits non-zero on any finding.
+func runVerify() error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	dbPath := fs.String("db", "", "Database path (default: from HDPAY_DB_PATH or ./data/hdpay.sqlite)")
+	network := fs.String("network", "", "Network: mainnet or testnet (default: from HDPAY_NETWORK or testnet)")
+	account := fs.Int("account", -1, "BIP-44 account to verify (default: from HDPAY_ACCOUNT or 0)")
+	btcType := fs.String("btc-type", "", "BTC address type of the stored addresses (default: from HDPAY_BTC_ADDRESS_TYPE or p2wpkh)")
+	chains := fs.String("chain", "", "Comma-separated chains to verify (default: all)")
+	sample := fs.Int("sample", 0, "Verify only this many randomly chosen addresses per chain (default: all)")
+	reportPath := fs.String("json", "", "Write a JSON report to this path (- for stdout)")
+	mnemonicFile := fs.String("mnemonic-file", "", "Path to the 24-word BIP-39 mnemonic (default: from HDPAY_MNEMONIC_FILE)")
+	keystoreFile := fs.String("keystore", "", "Path to the encrypted mnemonic keystore (default: from HDPAY_KEYSTORE_FILE)")
+	var shareFiles shareFileFlags
+	fs.Var(&shareFiles, "share-file", "SLIP-39 share file to recover the seed from (repeatable; default: from HDPAY_SHARE_FILES)")
+	passphraseFile := fs.String("passphrase-file", "", "Path to file containing the optional BIP-39 (or SLIP-39) passphrase")
+	passphrasePrompt := fs.Bool("passphrase-prompt", false, "Prompt for the optional BIP-39 (or SLIP-39) passphrase on the terminal")
+	xpubs := xpubFlags{}
+	fs.Var(xpubs, "xpub", "Verify against an account-level extended public key as chain=key instead of the seed (repeatable)")
+	fs.Parse(os.Args[2:])
+
+	if *sample < 0 {
+		return fmt.Errorf("--sample must be positive, got %d", *sample)
+	}
+	selected, err := parseChainList(*chains)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	logCloser, err := logging.Setup(cfg.LogLevel, cfg.LogDir)
+	if err != nil {
+		return fmt.Errorf("failed to setup logging: %w", err)
+	}
+	defer logCloser.Close()
+
+	overrideSeedSource(cfg, *mnemonicFile, *keystoreFile, shareFiles)
+	if *dbPath != "" {
+		cfg.DBPath = *dbPath
+	}
+	if *network != "" {
+		cfg.Network = *network
+	}
+	if *account >= 0 {
+		cfg.Account = *account
+	}
+	if *btcType != "" {
+		cfg.BTCAddressType = *btcType
+	}
+	if *passphraseFile != "" {
+		cfg.PassphraseFile = *passphraseFile
+	}
+	if *passphrasePrompt {
+		cfg.PassphrasePrompt = true
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	watchOnly := len(xpubs) > 0
+	if watchOnly && (*mnemonicFile != "" || *keystoreFile != "" || len(shareFiles) > 0) {
+		return fmt.Errorf("--xpub and --mnemonic-file/--keystore/--share-file are mutually exclusive")
+	}
+	if !watchOnly && !cfg.HasSeedSource() {
+		return fmt.Errorf("--mnemonic-file, --keystore or --share-file is required (or set HDPAY_MNEMONIC_FILE/HDPAY_KEYSTORE_FILE/HDPAY_SHARE_FILES), or pass --xpub")
+	}
+
+	net := hd.NetworkParams(cfg.Network)
+	btc := cfg.BTCType()
+	acct := uint32(cfg.Account)
+
+	// Build one deriver per chain before touching the database, so a wrong password
+	// or share set fails fast.
+	derivers := make(map[models.Chain]hd.IndexDeriver, len(selected))
+	source := "seed"
+	if watchOnly {
+		source = "xpub"
+		for _, chain := range selected {
+			encoded, ok := xpubs[chain]
+			if !ok {
+				slog.Warn("no xpub for chain, skipping", "chain", chain)
+				continue
+			}
+			parent, err := xpubExternalParent(chain, encoded, btc, acct, net)
+			if err != nil {
+				return err
+			}
+			if derivers[chain], err = hd.NewParentIndexDeriver(parent, chain, btc, net); err != nil {
+				return err
+			}
+		}
+	} else {
+		seed, err := loadSeed(cfg)
+		if err != nil {
+			return err
+		}
+		hd.MlockBytes(seed)
+		defer func() {
+			hd.MunlockBytes(seed)
+			hd.ZeroBytes(seed)
+		}()
+
+		for _, chain := range selected {
+			if derivers[chain], err = hd.NewSeedIndexDeriver(seed, chain, btc, acct, net); err != nil {
+				return err
+			}
+		}
+	}
+
+	database, err := db.New(cfg.DBPath, cfg.Network)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+	defer database.Close()
+
+	if err := database.RunMigrations(); err != nil {
+		return fmt.Errorf("run migrations: %w", err)
+	}
+
+	database = database.WithAccount(cfg.Account)
+
+	report := verifyReport{
+		Network:        cfg.Network,
+		Account:        acct,
+		BTCAddressType: string(btc),
+		Source:         source,
+		GeneratedAt:    time.Now().UTC().Format(time.RFC3339),
+		OK:             true,
+		Chains:         []*hd.AuditReport{},
+	}
+
+	for _, chain := range selected {
+		derive, ok := derivers[chain]
+		if !ok {
+			continue
+		}
+		chainReport, err := hd.AuditAddresses(database, chain, derive, *sample)
+		if err != nil {
+			return err
+		}
+		report.Chains = append(report.Chains, chainReport)
+		report.OK = report.OK && chainReport.OK()
+		printAuditReport(chainReport)
+	}
+
+	if *reportPath != "" {
+		if err := writeJSONReport(*reportPath, &report); err != nil {
+			return err
+		}
+	}
+
+	if !report.OK {
+		return fmt.Errorf("%w: stored addresses of account %d do not all match the %s", config.ErrKeyMismatch, acct, source)
+	}
+
+	slog.Info("address verification passed", "account", acct, "source", source, "chains", len(report.Chains))
+	return nil
+}
+
+// parseChainList parses "BTC,SOL" into chains; empty means all chains.
+func parseChainList(spec string) ([]models.Chain, error) {
+	if spec == "" {
+		return models.AllChains, nil
+	}
+
+	var chains []models.Chain
+	for _, part := range strings.Split(spec, ",") {
+		chain := models.Chain(strings.ToUpper(strings.TrimSpace(part)))
+		switch chain {
+		case models.ChainBTC, models.ChainBSC, models.ChainSOL:
+			chains = append(chains, chain)
+		default:
+			return nil, fmt.Errorf("unsupported chain %q in --chain (want BTC, BSC or SOL)", part)
+		}
+	}
+	return chains, nil
+}
+
+// printAuditReport prints a human-readable summary of one chain's audit to stderr.
+func printAuditReport(r *hd.AuditReport) {
+	status := "OK"
+	if !r.OK() {
+		status = "FAILED"
+	}
+	fmt.Fprintf(os.Stderr, "%s: %s — checked %d of %d stored addresses, %d mismatched, %d missing (%s)\n",
+		r.Chain, status, r.Checked, r.Stored, r.MismatchCount, r.Missing, r.Duration)
+	for _, m := range r.Mismatches {
+		fmt.Fprintf(os.Stderr, "  index %d: stored %s, derived %s\n", m.Index, m.Stored, m.Derived)
+	}
+	if hidden := r.MismatchCount - len(r.Mismatches); hidden > 0 {
+		fmt.Fprintf(os.Stderr, "  ... and %d more not listed\n", hidden)
+	}
+}
+
+// writeJSONReport writes report as indented JSON to path, or to stdout when path is "-".
+func writeJSONReport(path string, report any) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal report: %w", err)
+	}
+	data = append(data, '\n')
+
+	if path == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("write report %q: %w", path, err)
+	}
+	slog.Info("report written", "path", path)
+	return nil
+}
@@ -6,16 +6,31 @@ import "time"
 const (
 	MaxAddressesPerChain = 500_000
 	DefaultMaxScanID     = 5_000
+
+	// AddressGenBatchSize is the number of consecutive indices a generator worker
+	// derives at once; batches are stored in index order as they complete.
+	AddressGenBatchSize = 10_000
+
+	// MaxAddressExtendPerRequest bounds how many addresses one
+	// POST /api/addresses/{chain}/extend call may append, keeping it well inside
+	// the server write timeout. Larger extensions go through hdpay init --extend-to.
+	MaxAddressExtendPerRequest = 100_000
 )
 
 // BIP-44 / BIP-84 Derivation Paths
 const (
 	BIP44Purpose    = 44  // Standard BIP-44 purpose
 	BIP84Purpose    = 84  // BIP-84 purpose for Native SegWit (bech32)
+	BIP49Purpose    = 49  // BIP-49 purpose for nested SegWit (P2SH-P2WPKH)
+	BIP86Purpose    = 86  // BIP-86 purpose for Taproot key-path (bech32m)
 	BTCCoinType     = 0   // m/84'/0'/0'/0/N (Native SegWit bech32)
 	BSCCoinType     = 60  // m/44'/60'/0'/0/N (same as ETH)
 	SOLCoinType     = 501 // m/44'/501'/N'/0'
 	BTCTestCoinType = 1   // Testnet
+
+	// MaxBIP44Account is the highest account number usable as a hardened path
+	// element (m/purpose'/coin'/account'). HDPAY_ACCOUNT / --account must not exceed it.
+	MaxBIP44Account = 1<<31 - 1
 )
 
 // Token Contract Addresses — BSC Mainnet
@@ -88,6 +103,44 @@ const (
 	ScanContextTimeout            = 24 * time.Hour // upper bound on scan goroutine lifetime
 )
 
+// Gap-limit Discovery
+const (
+	// DefaultGapLimit is the number of consecutive unused addresses after which
+	// discovery stops (BIP-44 address gap limit).
+	DefaultGapLimit = 20
+	MaxGapLimit     = 1_000
+
+	// DiscoveryBranchExternal and DiscoveryBranchInternal are the last-but-one path
+	// element: receive addresses (/0/i) and BTC change addresses (/1/i).
+	DiscoveryBranchExternal = 0
+	DiscoveryBranchInternal = 1
+)
+
+// Address Metadata
+const (
+	MaxAddressLabelLength      = 200
+	MaxAddressExternalIDLength = 200
+	MaxAddressTags             = 20
+	MaxAddressTagLength        = 50
+	MaxAddressMetadataBytes    = 8 * 1024 // Compacted free-form JSON object
+)
+
+// Address Allocation
+const (
+	MaxAllocatedToLength    = 200
+	MaxIdempotencyKeyLength = 128
+)
+
+// BTC Coin Control
+const (
+	MaxFrozenUTXONoteLength = 200
+)
+
+// BTC Payouts
+const (
+	BTCMaxPayoutRecipients = 100 // outputs per payout, besides change
+)
+
 // HTTP Client Connection Pool
 const (
 	HTTPMaxConnsPerHost     = 10  // max connections per provider host
@@ -239,19 +292,76 @@ const (
 	BTCFeeSafetyMarginPct   = 2       // Percentage added to estimated fee to prevent underestimation
 )
 
+// BTC Fee Targets
+const (
+	BTCDefaultFeeTarget = "3_blocks" // confirmation target of a send that names none (halfHourFee)
+)
+
+// BTC Consolidation Chunking
+const (
+	BTCDefaultChunkMaxVsize = 90_000             // vB per consolidation chunk, headroom under the 100 kvB standardness limit
+	BTCMinChunkVsize        = 1_000              // Smallest configurable chunk; fits a few inputs of any type
+	BTCMaxChunkVsize        = BTCMaxTxWeight / 4 // Largest standard transaction
+)
+
+// BTC Replace-by-fee (BIP-125)
+const (
+	BTCRBFSequence             = 0xfffffffd // nSequence below 0xfffffffe signals replaceability
+	BTCIncrementalRelayFeeRate = 1          // sat/vB a replacement pays for its own vsize on top of the replaced fee
+)
+
 // BTC Transaction vsize estimation (weight units per BIP-141)
 const (
 	BTCTxOverheadWU        = 42  // version(16) + marker(1) + flag(1) + vinCount(4) + voutCount(4) + locktime(16)
 	BTCP2WPKHInputNonWitWU = 164 // (outpoint(36) + scriptLen(1) + sequence(4)) × 4
 	BTCP2WPKHInputWitWU    = 108 // stackCount(1) + sigLen(1) + sig(72) + pkLen(1) + pk(33)
 	BTCP2WPKHOutputWU      = 124 // (value(8) + scriptLen(1) + script(22)) × 4
+
+	BTCP2TRInputNonWitWU       = 164 // same layout as P2WPKH, empty scriptSig
+	BTCP2TRInputWitWU          = 66  // stackCount(1) + sigLen(1) + schnorrSig(64), SIGHASH_DEFAULT
+	BTCP2SHP2WPKHInputNonWitWU = 256 // (outpoint(36) + scriptLen(1) + scriptSig(23) + sequence(4)) × 4
+	BTCP2SHP2WPKHInputWitWU    = 108 // same witness as P2WPKH
+	BTCP2PKHInputNonWitWU      = 592 // (outpoint(36) + scriptLen(1) + sig(73) + pk(34) + sequence(4)) × 4
+	BTCP2PKHInputWitWU         = 1   // empty witness stack count when mixed with SegWit inputs
+	BTCOutputBaseWU            = 36  // (value(8) + scriptLen(1)) × 4, plus script length × 4
+)
+
+// BTC PSBT (BIP-174) offline signing
+const (
+	MaxPSBTBytes = 4 << 20 // Largest PSBT accepted by the broadcast endpoint and sign-psbt
+)
+
+// BSC/SOL sweep bundles (offline signing)
+const (
+	SweepBundleVersion  = 1       // Format version written by the export endpoint
+	MaxSweepBundleBytes = 8 << 20 // Largest bundle accepted by the broadcast endpoint and sign-bundle
+)
+
+// SOL durable nonces
+const (
+	SOLNonceAccountSize          = 80 // version(4) + state(4) + authority(32) + nonce(32) + lamportsPerSignature(8)
+	SOLNonceStateInitialized     = 1  // nonce account state after InitializeNonceAccount
+	SOLRecentBlockhashesSysvarID = "SysvarRecentB1ockHashes11111111111111111111"
+)
+
+// Message signing (proof of ownership)
+const (
+	MaxSignMessageBytes      = 16 * 1024                // Largest message accepted by the sign/verify endpoints and sign-message
+	BIP322Tag                = "BIP0322-signed-message" // BIP-340 tagged hash tag of the message
+	EIP191Prefix             = "\x19Ethereum Signed Message:\n"
+	SOLOffchainSigningDomain = "\xffsolana offchain" // 16-byte prefix of every SOL off-chain message
+	SOLOffchainLedgerMaxLen  = 1212                  // longest restricted-ASCII / limited-UTF-8 message
+	SOLOffchainMaxLen        = 65515                 // longest extended-UTF-8 message
 )
 
 // BTC Fee Estimation
 const (
 	MempoolFeeEstimatePath = "/v1/fees/recommended"
+	EsploraFeeEstimatePath = "/fee-estimates" // map of confirmation target (blocks) → sat/vB
 	FeeEstimateTimeout     = 5 * time.Second
 	FeeCacheTTL            = 2 * time.Minute
+	BTCMaxSaneFeeRate      = 2_000   // sat/vB; source quotes above this are discarded
+	BTCSatsPerBTCKvB       = 100_000 // bitcoind estimatesmartfee BTC/kvB → sat/vB
 )
 
 // Server
@@ -329,6 +439,7 @@ const (
 	BTCConfirmationTimeout      = 10 * time.Minute // max wait for BTC TX to get 1 confirmation
 	BTCConfirmationPollInterval = 15 * time.Second  // poll interval for Esplora /tx/{txid}/status
 	BTCTxStatusPath             = "/tx/%s/status"   // Esplora endpoint format for TX status
+	BTCTxPath                   = "/tx/%s"         // Esplora endpoint format for a TX with its prevouts
 )
 
 // SOL Blockhash Cache
@@ -375,11 +486,27 @@ const (
 	BSCGasPriceMaxIncreaseMultiplier = 2 // reject if gas price more than 2x preview
 )
 
+// BSC Parallel Sweeps
+const (
+	BSCDefaultSweepWorkers = 8  // source addresses swept concurrently
+	BSCMaxSweepWorkers     = 32 // upper bound for HDPAY_BSC_SWEEP_WORKERS, keeps RPC load sane
+)
+
+// BSC Dynamic Fees (EIP-1559)
+const (
+	BSCBaseFeeMultiplier = 2 // max fee per gas = 2x next base fee + tip
+)
+
 // Gas Pre-Seed Token Identifier
 const (
 	TokenGasPreSeed = "GAS_PRESEED" // token field in tx_state for gas pre-seed rows
 )
 
+// BTC Payout Identifier
+const (
+	FromAddressBTCPayout = "payout" // from_address field in tx_state for BTC payout rows; never resumed
+)
+
 // SOL Confirmation
 const (
 	SOLMaxConfirmationRPCErrors = 3 // consecutive RPC errors before marking TX as uncertain
@@ -394,6 +521,7 @@ const (
 	TxStateFailed       = "failed"
 	TxStateUncertain    = "uncertain"
 	TxStateDismissed    = "dismissed"
+	TxStateSuperseded   = "superseded" // replaced by a fee bump (RBF); only moves on to confirmed
 )
 
 // TX Reconciler (startup reconciliation of pending transactions)
@@ -439,3 +567,39 @@ const (
 	CoinGeckoIDs       = "bitcoin,binancecoin,solana,usd-coin,tether"
 	PriceCacheDuration = 5 * time.Minute
 )
+
+// Token Registry
+const (
+	CoinGeckoIDUSDC = "usd-coin"
+	CoinGeckoIDUSDT = "tether"
+
+	TokenSymbolMinLen = 2
+	TokenSymbolMaxLen = 10
+	TokenMaxDecimals  = 36
+	CoinGeckoIDMaxLen = 64
+)
+
+// Encrypted Mnemonic Keystore (scrypt + AES-256-GCM)
+const (
+	KeystoreVersion        = 1
+	KeystoreScryptN        = 1 << 18 // ~256 MiB, ~1s per unlock
+	KeystoreScryptR        = 8
+	KeystoreScryptP        = 1
+	KeystoreMaxScryptN     = 1 << 22 // Upper bound accepted when reading a keystore file
+	KeystoreSaltLen        = 32
+	KeystoreKeyLen         = 32 // AES-256
+	KeystoreMinPasswordLen = 8
+	DefaultKeystorePath    = "./data/keystore.json"
+)
+
+// SLIP-39 Shamir Shares
+const (
+	SLIP39IterationExponent = 1  // PBKDF2 rounds = 10000 << e, split across 4 Feistel rounds
+	SLIP39MaxShareCount     = 16 // Max groups, and max members per group
+	DefaultSharesDir        = "./data/shares"
+)
+
+// Address Audit (hdpay verify)
+const (
+	AuditMaxReportedMismatches = 1000 // Mismatches listed per chain; the count is always exact
+)
@@ -57,9 +57,60 @@ var (
 	// Scanner
 	ErrScanFailed         = errors.New("scan failed")
 
+	// Gap-limit discovery
+	ErrActivityNotSupported = errors.New("no provider can report address history")
+
+	// Address metadata
+	ErrInvalidAddressMetadata = errors.New("invalid address metadata")
+
+	// Address allocation
+	ErrNoAddressAvailable  = errors.New("no unallocated address available")
+	ErrIdempotencyConflict = errors.New("idempotency key already used for a different allocation")
+
+	// PSBT offline signing
+	ErrInvalidPSBT              = errors.New("invalid PSBT")
+	ErrPSBTIncomplete           = errors.New("PSBT is not fully signed")
+	ErrPSBTForeignInput         = errors.New("PSBT spends an input that is not a stored address")
+	ErrPSBTKeyOriginUnavailable = errors.New("no BTC key origin available for PSBT inputs")
+
+	// BSC/SOL sweep bundles
+	ErrInvalidBundle         = errors.New("invalid sweep bundle")
+	ErrBundleEmpty           = errors.New("no address can be swept into a bundle")
+	ErrBundleUnsigned        = errors.New("sweep bundle is not fully signed")
+	ErrBundleForeignAddress  = errors.New("sweep bundle spends from an address that is not stored")
+	ErrSOLNonceAccount       = errors.New("invalid SOL durable nonce account")
+	ErrSOLNonceAccountsShort = errors.New("not enough SOL durable nonce accounts configured")
+
+	// Message signing (proof of ownership)
+	ErrInvalidMessage            = errors.New("invalid message")
+	ErrMessageSigningUnsupported = errors.New("message signing not supported for this address type")
+	ErrInvalidMessageSignature   = errors.New("invalid message signature")
+
+	// BTC fee bumping (RBF and CPFP)
+	ErrInvalidFeeBump     = errors.New("invalid fee bump request")
+	ErrTxNotFound         = errors.New("transaction not found")
+	ErrTxNotReplaceable   = errors.New("transaction cannot be replaced")
+	ErrTxAlreadyConfirmed = errors.New("transaction already confirmed")
+	ErrFeeBumpTooLow      = errors.New("fee rate too low to replace the transaction")
+	ErrNoCPFPOutputs      = errors.New("transaction has no unspent output to a stored address")
+
+	// BTC coin control
+	ErrInvalidInputSelection = errors.New("invalid input selection")
+
+	// BTC fee estimation
+	ErrInvalidFeeTarget = errors.New("invalid fee target")
+
+	// BTC payouts
+	ErrInvalidPayout = errors.New("invalid payout")
+
 	// Multicall3
 	ErrMulticall3Failed = errors.New("multicall3 aggregate call failed")
 
+	// Token registry
+	ErrInvalidTokenInfo = errors.New("invalid token")
+	ErrTokenExists      = errors.New("token already registered")
+	ErrBuiltinToken     = errors.New("built-in token cannot be changed")
+
 	// TX Safety — Advanced (V2 Phase 4)
 	ErrUTXODiverged     = errors.New("UTXO set diverged significantly since preview")
 	ErrGasPriceSpiked   = errors.New("gas price increased more than 2x since preview")
@@ -68,6 +119,13 @@ var (
 
 	// Mnemonic security
 	ErrMnemonicFileUnavailable = errors.New("mnemonic file not accessible (is your wallet disk plugged in?)")
+	ErrKeyMismatch             = errors.New("KEY MISMATCH: stored addresses do not match the configured mnemonic/passphrase")
+
+	// Watch-only mode
+	ErrSigningUnavailable = errors.New("signing unavailable in watch-only mode")
+
+	// Multi-account
+	ErrAccountMismatch = errors.New("sweep belongs to a different BIP-44 account")
 
 	// Config validation
 	ErrInvalidConfig = errors.New("invalid configuration")
@@ -170,9 +228,72 @@ const (
 	// Mnemonic security
 	ErrorMnemonicUnavailable = "ERROR_MNEMONIC_UNAVAILABLE"
 
+	// Watch-only mode
+	ErrorSigningUnavailable = "ERROR_SIGNING_UNAVAILABLE"
+
+	// Multi-account
+	ErrorAccountMismatch = "ERROR_ACCOUNT_MISMATCH"
+
+	// Export formats
+	ErrorInvalidExportFormat   = "ERROR_INVALID_EXPORT_FORMAT"
+	ErrorDescriptorUnavailable = "ERROR_DESCRIPTOR_UNAVAILABLE"
+
+	// Address extension
+	ErrorInvalidExtendTarget = "ERROR_INVALID_EXTEND_TARGET"
+	ErrorKeyMismatch         = "ERROR_KEY_MISMATCH"
+
+	// Address metadata
+	ErrorAddressNotFound        = "ERROR_ADDRESS_NOT_FOUND"
+	ErrorInvalidAddressIndex    = "ERROR_INVALID_ADDRESS_INDEX"
+	ErrorInvalidAddressMetadata = "ERROR_INVALID_ADDRESS_METADATA"
+
+	// Address allocation
+	ErrorInvalidAllocation   = "ERROR_INVALID_ALLOCATION"
+	ErrorNoAddressAvailable  = "ERROR_NO_ADDRESS_AVAILABLE"
+	ErrorIdempotencyConflict = "ERROR_IDEMPOTENCY_CONFLICT"
+
+	// PSBT offline signing
+	ErrorInvalidPSBT      = "ERROR_INVALID_PSBT"
+	ErrorPSBTIncomplete   = "ERROR_PSBT_INCOMPLETE"
+	ErrorPSBTForeignInput = "ERROR_PSBT_FOREIGN_INPUT"
+
+	// BSC/SOL sweep bundles
+	ErrorInvalidBundle        = "ERROR_INVALID_BUNDLE"
+	ErrorBundleEmpty          = "ERROR_BUNDLE_EMPTY"
+	ErrorBundleUnsigned       = "ERROR_BUNDLE_UNSIGNED"
+	ErrorBundleForeignAddress = "ERROR_BUNDLE_FOREIGN_ADDRESS"
+	ErrorSOLNonceAccount      = "ERROR_SOL_NONCE_ACCOUNT"
+
+	// Message signing (proof of ownership)
+	ErrorInvalidMessage            = "ERROR_INVALID_MESSAGE"
+	ErrorMessageSigningUnsupported = "ERROR_MESSAGE_SIGNING_UNSUPPORTED"
+
+	// BTC fee bumping (RBF and CPFP)
+	ErrorInvalidFeeBump     = "ERROR_INVALID_FEE_BUMP"
+	ErrorTxNotFound         = "ERROR_TX_NOT_FOUND"
+	ErrorTxNotReplaceable   = "ERROR_TX_NOT_REPLACEABLE"
+	ErrorTxAlreadyConfirmed = "ERROR_TX_ALREADY_CONFIRMED"
+	ErrorFeeBumpTooLow      = "ERROR_FEE_BUMP_TOO_LOW"
+	ErrorNoCPFPOutputs      = "ERROR_NO_CPFP_OUTPUTS"
+
+	// BTC coin control
+	ErrorInvalidInputSelection = "ERROR_INVALID_INPUT_SELECTION"
+	ErrorInvalidOutpoint       = "ERROR_INVALID_OUTPOINT"
+
+	// BTC fee estimation
+	ErrorInvalidFeeTarget = "ERROR_INVALID_FEE_TARGET"
+
+	// BTC payouts
+	ErrorInvalidPayout = "ERROR_INVALID_PAYOUT"
+
 	// Multicall3
 	ErrorMulticall3Failed = "ERROR_MULTICALL3_FAILED"
 
+	// Token registry
+	ErrorTokenExists   = "ERROR_TOKEN_EXISTS"
+	ErrorBuiltinToken  = "ERROR_BUILTIN_TOKEN"
+	ErrorTokenNotFound = "ERROR_TOKEN_NOT_FOUND"
+
 	// Scanner Diagnostics
 	ErrorNoAddressesGenerated    = "ERROR_NO_ADDRESSES_GENERATED"
 	ErrorAllProvidersReturnedNull = "ERROR_ALL_PROVIDERS_NULL"
@@ -0,0 +1,32 @@
+package config
+
+import "github.com/Fantasim/hdpay/internal/shared/models"
+
+// BuiltinTokens returns the tokens every wallet tracks on the given network: USDC
+// and USDT on BSC and SOL. Tokens without a deployment on the network are left
+// out. Custom tokens are registered on top of these in the database.
+func BuiltinTokens(network string) []models.TokenInfo {
+	bscUSDC, bscUSDT := BSCUSDCContract, BSCUSDTContract
+	solUSDC, solUSDT := SOLUSDCMint, SOLUSDTMint
+	if network == string(models.NetworkTestnet) {
+		bscUSDC, bscUSDT = BSCTestnetUSDCContract, BSCTestnetUSDTContract
+		solUSDC, solUSDT = SOLTestnetUSDCMint, SOLTestnetUSDTMint
+	}
+
+	all := []models.TokenInfo{
+		{Chain: models.ChainBSC, Symbol: models.TokenUSDC, Contract: bscUSDC, Decimals: BSCUSDCDecimals, CoinGeckoID: CoinGeckoIDUSDC},
+		{Chain: models.ChainBSC, Symbol: models.TokenUSDT, Contract: bscUSDT, Decimals: BSCUSDTDecimals, CoinGeckoID: CoinGeckoIDUSDT},
+		{Chain: models.ChainSOL, Symbol: models.TokenUSDC, Contract: solUSDC, Decimals: SOLUSDCDecimals, CoinGeckoID: CoinGeckoIDUSDC},
+		{Chain: models.ChainSOL, Symbol: models.TokenUSDT, Contract: solUSDT, Decimals: SOLUSDTDecimals, CoinGeckoID: CoinGeckoIDUSDT},
+	}
+
+	tokens := make([]models.TokenInfo, 0, len(all))
+	for _, t := range all {
+		if t.Contract == "" {
+			continue
+		}
+		t.Builtin = true
+		tokens = append(tokens, t)
+	}
+	return tokens
+}
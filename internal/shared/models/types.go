@@ -1,5 +1,7 @@
 package models
 
+import "encoding/json"
+
 // Chain represents a supported blockchain.
 type Chain string
 
@@ -20,6 +22,19 @@ const (
 	NetworkTestnet NetworkMode = "testnet"
 )
 
+// BTCAddressType is the script type of a BTC address (and the BIP purpose it is derived under).
+type BTCAddressType string
+
+const (
+	BTCAddressP2WPKH     BTCAddressType = "p2wpkh"      // BIP-84 Native SegWit (bc1q...)
+	BTCAddressP2TR       BTCAddressType = "p2tr"        // BIP-86 Taproot key-path (bc1p...)
+	BTCAddressP2SHP2WPKH BTCAddressType = "p2sh-p2wpkh" // BIP-49 nested SegWit (3...)
+	BTCAddressP2PKH      BTCAddressType = "p2pkh"       // BIP-44 legacy (1...)
+)
+
+// AllBTCAddressTypes is the list of supported BTC address types.
+var AllBTCAddressTypes = []BTCAddressType{BTCAddressP2WPKH, BTCAddressP2TR, BTCAddressP2SHP2WPKH, BTCAddressP2PKH}
+
 // Token represents a supported token symbol.
 type Token string
 
@@ -36,6 +51,9 @@ type Address struct {
 	AddressIndex int         `json:"addressIndex"`
 	Address      string      `json:"address"`
 	CreatedAt    string      `json:"createdAt"`
+	// Change marks a BTC internal-chain (m/.../1/N) address; AddressIndex is then
+	// its change index.
+	Change bool `json:"change,omitempty"`
 }
 
 // Balance represents the balance of an address for a specific token.
@@ -75,6 +93,7 @@ type Transaction struct {
 	Status       string `json:"status"`
 	CreatedAt    string `json:"createdAt"`
 	ConfirmedAt  string `json:"confirmedAt,omitempty"`
+	Label        string `json:"label,omitempty"` // Label of the address at AddressIndex
 }
 
 // AddressWithBalance represents an address with its balance data for API responses.
@@ -86,6 +105,76 @@ type AddressWithBalance struct {
 	NativeBalance string             `json:"nativeBalance"`
 	TokenBalances []TokenBalanceItem `json:"tokenBalances"`
 	LastScanned   *string            `json:"lastScanned"`
+	Label         string             `json:"label,omitempty"`
+	Tags          []string           `json:"tags,omitempty"`
+	ExternalID    string             `json:"externalId,omitempty"`
+	Allocated     bool               `json:"allocated"`
+	AllocatedTo   string             `json:"allocatedTo,omitempty"`
+	AllocatedAt   string             `json:"allocatedAt,omitempty"`
+}
+
+// AddressAllocation records that a deposit address was handed out, to whom and when.
+type AddressAllocation struct {
+	Chain          Chain  `json:"chain"`
+	AddressIndex   int    `json:"addressIndex"`
+	Address        string `json:"address"`
+	AllocatedTo    string `json:"allocatedTo"`
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+	AllocatedAt    string `json:"allocatedAt"`
+}
+
+// AddressMetadata is the user-assigned label, tags, external reference (e.g. a
+// customer or invoice ID) and free-form JSON of one address.
+type AddressMetadata struct {
+	Chain        Chain           `json:"chain"`
+	AddressIndex int             `json:"addressIndex"`
+	Address      string          `json:"address,omitempty"`
+	Label        string          `json:"label"`
+	Tags         []string        `json:"tags"`
+	ExternalID   string          `json:"externalId"`
+	Metadata     json.RawMessage `json:"metadata,omitempty"`
+	CreatedAt    string          `json:"createdAt,omitempty"`
+	UpdatedAt    string          `json:"updatedAt,omitempty"`
+}
+
+// MessageScheme identifies how a proof-of-ownership message is signed.
+type MessageScheme string
+
+const (
+	MessageSchemeBIP322Simple MessageScheme = "bip322-simple"   // BTC P2WPKH / P2TR
+	MessageSchemeEIP191       MessageScheme = "eip191"          // BSC personal_sign
+	MessageSchemeSOLOffchain  MessageScheme = "solana-offchain" // SOL off-chain message, v0 header
+)
+
+// SignMessageRequest is the body of POST /api/addresses/{chain}/{index}/sign-message.
+type SignMessageRequest struct {
+	Message string `json:"message"`
+}
+
+// SignedMessage is a message signed with the key of a derived address.
+type SignedMessage struct {
+	Chain        Chain         `json:"chain"`
+	AddressIndex int           `json:"addressIndex"`
+	Address      string        `json:"address"`
+	Message      string        `json:"message"`
+	Scheme       MessageScheme `json:"scheme"`
+	Signature    string        `json:"signature"` // base64 witness (BTC), 0x-hex (BSC), base58 (SOL)
+}
+
+// VerifyMessageRequest is the body of POST /api/addresses/{chain}/verify-message.
+type VerifyMessageRequest struct {
+	Address   string `json:"address"`
+	Message   string `json:"message"`
+	Signature string `json:"signature"`
+}
+
+// VerifyMessageResult reports whether a signature proves control of an address.
+type VerifyMessageResult struct {
+	Chain   Chain         `json:"chain"`
+	Address string        `json:"address"`
+	Scheme  MessageScheme `json:"scheme"`
+	Valid   bool          `json:"valid"`
+	Reason  string        `json:"reason,omitempty"` // why an invalid signature was rejected
 }
 
 // TokenBalanceItem represents a single token balance in an API response.
@@ -99,6 +188,8 @@ type TokenBalanceItem struct {
 type AddressExport struct {
 	Chain                  Chain               `json:"chain"`
 	Network                string              `json:"network"`
+	Account                uint32              `json:"account"`
+	AddressType            BTCAddressType      `json:"address_type,omitempty"` // BTC only
 	DerivationPathTemplate string              `json:"derivation_path_template"`
 	GeneratedAt            string              `json:"generated_at"`
 	Count                  int                 `json:"count"`
@@ -106,11 +197,31 @@ type AddressExport struct {
 }
 
 // AddressExportItem is a single address entry in the export file.
+// Balances (raw, in the token's smallest unit) and LastScanned are only set when
+// balances were requested; Label, Tags and ExternalID when the address has metadata.
 type AddressExportItem struct {
-	Index   int    `json:"index"`
-	Address string `json:"address"`
+	Index       int              `json:"index"`
+	Address     string           `json:"address"`
+	Balances    map[Token]string `json:"balances,omitempty"`
+	LastScanned *string          `json:"last_scanned,omitempty"`
+	Label       string           `json:"label,omitempty"`
+	Tags        []string         `json:"tags,omitempty"`
+	ExternalID  string           `json:"external_id,omitempty"`
 }
 
+// ExportFormat is the file format of an address export.
+type ExportFormat string
+
+const (
+	ExportFormatJSON       ExportFormat = "json"       // One AddressExport document per chain
+	ExportFormatCSV        ExportFormat = "csv"        // Header row + one row per address
+	ExportFormatNDJSON     ExportFormat = "ndjson"     // One AddressExportItem per line
+	ExportFormatDescriptor ExportFormat = "descriptor" // BTC only: BIP-380 descriptor for importdescriptors
+)
+
+// AllExportFormats is the list of supported export formats.
+var AllExportFormats = []ExportFormat{ExportFormatJSON, ExportFormatCSV, ExportFormatNDJSON, ExportFormatDescriptor}
+
 // APIResponse is the standard API response wrapper.
 type APIResponse struct {
 	Data interface{} `json:"data,omitempty"`
@@ -134,15 +245,49 @@ type UTXO struct {
 	BlockHeight  int64  `json:"blockHeight,omitempty"`
 	Address      string `json:"address"`
 	AddressIndex int    `json:"addressIndex"`
+	// AddressType is the script type of Address; empty is treated as P2WPKH.
+	AddressType BTCAddressType `json:"addressType,omitempty"`
+	// Change marks an output of a change address; AddressIndex is then its
+	// internal-chain index.
+	Change bool `json:"change,omitempty"`
 }
 
-// FeeEstimate contains recommended fee rates from mempool.space.
+// FeeEstimate contains recommended BTC fee rates, the median of the configured
+// sources for each confirmation target. The five tiers mirror the targets:
+// 1, 3, 6, 24 and 144 blocks.
 type FeeEstimate struct {
 	FastestFee  int64 `json:"fastestFee"`  // sat/vB, next block
 	HalfHourFee int64 `json:"halfHourFee"` // sat/vB, ~3 blocks
 	HourFee     int64 `json:"hourFee"`     // sat/vB, ~6 blocks
 	EconomyFee  int64 `json:"economyFee"`  // sat/vB, several hours
 	MinimumFee  int64 `json:"minimumFee"`  // sat/vB, eventually
+
+	Targets  []FeeTargetEstimate `json:"targets,omitempty"`
+	Sources  []FeeSourceStatus   `json:"sources,omitempty"`
+	Fallback bool                `json:"fallback,omitempty"` // no source answered: configured default rates
+}
+
+// FeeTargetEstimate is the fee rate chosen for one confirmation target, with
+// the quotes it was chosen from.
+type FeeTargetEstimate struct {
+	Target  string     `json:"target"` // next_block, 3_blocks, 6_blocks, 24_blocks or economy
+	Blocks  int        `json:"blocks"`
+	FeeRate int64      `json:"feeRate"` // sat/vB
+	Quotes  []FeeQuote `json:"quotes"`
+}
+
+// FeeQuote is one source's fee rate for a confirmation target. Quotes outside
+// the sanity bounds are listed but not used.
+type FeeQuote struct {
+	Source  string  `json:"source"`
+	FeeRate float64 `json:"feeRate"` // sat/vB
+	Used    bool    `json:"used"`
+}
+
+// FeeSourceStatus reports whether a fee source answered.
+type FeeSourceStatus struct {
+	Source string `json:"source"` // mempool, esplora or bitcoind
+	Error  string `json:"error,omitempty"`
 }
 
 // SendPreview contains the preview of a consolidation transaction.
@@ -155,13 +300,191 @@ type SendPreview struct {
 	FeeRate        int64  `json:"feeRate"`  // sat/vB
 	EstimatedVsize int    `json:"estimatedVsize"`
 	DestAddress    string `json:"destAddress"`
+	PSBT           string `json:"psbt,omitempty"` // base64 unsigned PSBT for offline signing; single-transaction consolidations only
+	// Chunks lists the transactions a consolidation is split into; the totals above
+	// are their sums.
+	Chunks []BTCChunkPreview `json:"chunks"`
+	// FeeTarget is the confirmation target FeeRate was estimated for and
+	// FeeBreakdown the estimate it was taken from; both empty when the caller set
+	// the rate.
+	FeeTarget    string       `json:"feeTarget,omitempty"`
+	FeeBreakdown *FeeEstimate `json:"feeBreakdown,omitempty"`
+}
+
+// BTCChunkPreview is one transaction of a BTC consolidation preview.
+type BTCChunkPreview struct {
+	InputCount     int   `json:"inputCount"`
+	TotalInputSats int64 `json:"totalInputSats"`
+	OutputSats     int64 `json:"outputSats"`
+	FeeSats        int64 `json:"feeSats"`
+	EstimatedVsize int   `json:"estimatedVsize"`
 }
 
 // SendResult contains the result of broadcasting a transaction.
 type SendResult struct {
+	TxHash     string           `json:"txHash"`
+	Chain      Chain            `json:"chain"`
+	OutputSats int64            `json:"outputSats,omitempty"` // BTC only: net output sats after fees
+	Chunks     []BTCChunkResult `json:"chunks,omitempty"`     // BTC only: one entry per consolidation transaction
+}
+
+// BTCChunkResult is the outcome of one transaction of a BTC consolidation.
+type BTCChunkResult struct {
+	TxHash         string `json:"txHash,omitempty"`
+	InputCount     int    `json:"inputCount"`
+	AddressIndices []int  `json:"addressIndices"`
+	OutputSats     int64  `json:"outputSats"`
+	Status         string `json:"status"` // "success" or "failed"
+	Error          string `json:"error,omitempty"`
+}
+
+// PSBTBroadcastRequest carries a PSBT signed offline, as base64.
+type PSBTBroadcastRequest struct {
+	PSBT string `json:"psbt"`
+}
+
+// PSBTBroadcastResult is the response of broadcasting an offline-signed PSBT.
+type PSBTBroadcastResult struct {
+	SweepID    string `json:"sweepID"`
 	TxHash     string `json:"txHash"`
 	Chain      Chain  `json:"chain"`
-	OutputSats int64  `json:"outputSats,omitempty"` // BTC only: net output sats after fees
+	InputCount int    `json:"inputCount"`
+	OutputSats int64  `json:"outputSats"`
+	FeeSats    int64  `json:"feeSats"`
+}
+
+// FeeBumpRequest is the body of POST /api/send/bump.
+type FeeBumpRequest struct {
+	TxHash  string `json:"txHash"`
+	FeeRate int64  `json:"feeRate,omitempty"` // sat/vB; 0 = estimate, raised to the minimum replacement rate
+}
+
+// FeeBumpResult is the response of replacing a BTC consolidation with a higher fee.
+type FeeBumpResult struct {
+	SweepID         string `json:"sweepID"`
+	TxHash          string `json:"txHash"`
+	ReplacedTxHash  string `json:"replacedTxHash"`
+	Chain           Chain  `json:"chain"`
+	InputCount      int    `json:"inputCount"`
+	OutputSats      int64  `json:"outputSats"`
+	FeeSats         int64  `json:"feeSats"`
+	FeeRate         int64  `json:"feeRate"`
+	ReplacedFeeSats int64  `json:"replacedFeeSats"`
+}
+
+// CPFPRequest is the body of POST /api/send/cpfp.
+type CPFPRequest struct {
+	ParentTxHash string `json:"parentTxHash"`
+	Destination  string `json:"destination"`
+	FeeRate      int64  `json:"feeRate,omitempty"` // target package sat/vB; 0 = estimate
+}
+
+// CPFPResult is the response of spending the unconfirmed outputs of a stuck BTC
+// transaction with a child that pays for both.
+type CPFPResult struct {
+	SweepID        string `json:"sweepID"`
+	TxHash         string `json:"txHash"`
+	ParentTxHash   string `json:"parentTxHash"`
+	Chain          Chain  `json:"chain"`
+	InputCount     int    `json:"inputCount"`
+	OutputSats     int64  `json:"outputSats"`
+	FeeSats        int64  `json:"feeSats"`
+	ParentFeeSats  int64  `json:"parentFeeSats"`
+	ParentVsize    int    `json:"parentVsize"`
+	PackageFeeRate int64  `json:"packageFeeRate"` // requested target, sat/vB
+}
+
+// PayoutRecipient is one output of a BTC payout.
+type PayoutRecipient struct {
+	Address    string `json:"address"`
+	AmountSats int64  `json:"amountSats"`
+}
+
+// PayoutRequest is the request body for previewing or sending a BTC payout to
+// several recipients from the pooled funds of the wallet.
+type PayoutRequest struct {
+	Recipients     []PayoutRecipient  `json:"recipients"`
+	FeeRate        int64              `json:"feeRate,omitempty"` // sat/vB; 0 = estimate
+	InputSelection *BTCInputSelection `json:"inputSelection,omitempty"`
+}
+
+// PayoutOutput is a recipient output of a broadcast BTC payout, as listed by
+// GET /api/send/payouts.
+type PayoutOutput struct {
+	SweepID     string `json:"sweepID"`
+	TxHash      string `json:"txHash"`
+	Vout        int    `json:"vout"`
+	Address     string `json:"address"`
+	AmountSats  int64  `json:"amountSats"`
+	Status      string `json:"status"`
+	CreatedAt   string `json:"createdAt"`
+	ConfirmedAt string `json:"confirmedAt,omitempty"`
+}
+
+// PayoutResult describes a BTC payout. SweepID and TxHash are empty in a
+// preview. ChangeAddress is empty when the change would be dust and goes to the
+// fee instead.
+type PayoutResult struct {
+	SweepID        string            `json:"sweepID,omitempty"`
+	TxHash         string            `json:"txHash,omitempty"`
+	Chain          Chain             `json:"chain"`
+	Recipients     []PayoutRecipient `json:"recipients"`
+	InputCount     int               `json:"inputCount"`
+	TotalInputSats int64             `json:"totalInputSats"`
+	PayoutSats     int64             `json:"payoutSats"`
+	ChangeSats     int64             `json:"changeSats"`
+	ChangeAddress  string            `json:"changeAddress,omitempty"`
+	ChangeIndex    *int              `json:"changeIndex,omitempty"` // internal-chain index of ChangeAddress
+	FeeSats        int64             `json:"feeSats"`
+	FeeRate        int64             `json:"feeRate"`
+	EstimatedVsize int               `json:"estimatedVsize"`
+}
+
+// SweepBundle is a BSC or SOL sweep exported for offline signing: one
+// transaction per funded address, each sending to Destination. `hdpay
+// sign-bundle` fills in SignedTx and the broadcast endpoint sends them.
+type SweepBundle struct {
+	Version      int             `json:"version"`
+	Chain        Chain           `json:"chain"`
+	Token        Token           `json:"token"`
+	Network      string          `json:"network"`
+	Account      uint32          `json:"account"`
+	Destination  string          `json:"destination"`
+	Contract     string          `json:"contract,omitempty"` // BEP-20 contract or SPL mint for token sweeps
+	ChainID      string          `json:"chainID,omitempty"`  // BSC only: EIP-155 chain ID
+	CreatedAt    string          `json:"createdAt"`
+	Transactions []SweepBundleTx `json:"transactions"`
+}
+
+// SweepBundleTx is one transaction of a sweep bundle. BSC entries carry the
+// legacy transaction fields; SOL entries carry the compiled message, built on
+// a durable nonce so it stays valid until it is broadcast.
+type SweepBundleTx struct {
+	AddressIndex int    `json:"addressIndex"`
+	FromAddress  string `json:"fromAddress"`
+	Amount       string `json:"amount"` // wei, lamports or token base units
+
+	// BSC
+	Nonce    uint64 `json:"nonce,omitempty"`
+	GasPrice string `json:"gasPrice,omitempty"`
+	GasLimit uint64 `json:"gasLimit,omitempty"`
+	To       string `json:"to,omitempty"`
+	Value    string `json:"value,omitempty"`
+	Data     string `json:"data,omitempty"` // 0x-prefixed calldata
+
+	// SOL
+	Message       string `json:"message,omitempty"` // base64 serialized message
+	NonceAccount  string `json:"nonceAccount,omitempty"`
+	SignerIndexes []int  `json:"signerIndexes,omitempty"` // address index of each required signer, in message order
+
+	// SignedTx is set by the offline signer: 0x-hex RLP for BSC, base64 wire
+	// transaction for SOL.
+	SignedTx string `json:"signedTx,omitempty"`
+}
+
+// BundleBroadcastRequest carries a sweep bundle signed offline.
+type BundleBroadcastRequest struct {
+	Bundle *SweepBundle `json:"bundle"`
 }
 
 // BSCSendPreview contains the preview of a BSC consolidation transaction.
@@ -173,7 +496,9 @@ type BSCSendPreview struct {
 	GasCostWei  string `json:"gasCostWei"`
 	NetAmount   string `json:"netAmount"`  // totalAmount - gasCost (native only)
 	DestAddress string `json:"destAddress"`
-	GasPrice    string `json:"gasPrice"` // wei, for transparency
+	GasPrice    string `json:"gasPrice"`              // wei, max fee per gas for dynamic fees
+	BaseFee     string `json:"baseFee,omitempty"`     // wei, dynamic fees only
+	PriorityFee string `json:"priorityFee,omitempty"` // wei, dynamic fees only
 }
 
 // BSCSendResult contains the result of a BSC consolidation sweep.
@@ -205,6 +530,9 @@ type GasPreSeedPreview struct {
 	AmountPerTarget string `json:"amountPerTarget"` // wei
 	TotalNeeded     string `json:"totalNeeded"`     // wei (amount + gas)
 	Sufficient      bool   `json:"sufficient"`
+	GasPrice        string `json:"gasPrice"`              // wei, max fee per gas for dynamic fees
+	BaseFee         string `json:"baseFee,omitempty"`     // wei, dynamic fees only
+	PriorityFee     string `json:"priorityFee,omitempty"` // wei, dynamic fees only
 }
 
 // GasPreSeedResult contains the result of a gas pre-seeding operation.
@@ -259,12 +587,59 @@ type SendRequest struct {
 	// When set, this address pays fees instead of each token holder paying their own.
 	FeePayerIndex *int `json:"feePayerIndex,omitempty"`
 
+	// BTC coin control: which addresses and UTXOs the consolidation spends.
+	// Omitted = every confirmed, unfrozen UTXO of every funded address.
+	InputSelection *BTCInputSelection `json:"inputSelection,omitempty"`
+
+	// BTC fee estimation: confirmation target of the fee rate, one of next_block,
+	// 3_blocks, 6_blocks, 24_blocks or economy. Omitted = 3_blocks.
+	FeeTarget string `json:"feeTarget,omitempty"`
+
 	// Preview→Execute validation (optional, set by frontend from preview response)
 	ExpectedInputCount int    `json:"expectedInputCount,omitempty"` // BTC: UTXO count from preview
 	ExpectedTotalSats  int64  `json:"expectedTotalSats,omitempty"`  // BTC: total input sats from preview
 	ExpectedGasPrice   string `json:"expectedGasPrice,omitempty"`   // BSC: gas price (wei) from preview
 }
 
+// BTCInputSelection narrows the UTXOs a BTC consolidation spends. The zero value
+// spends every confirmed UTXO of every funded address. Frozen UTXOs are never
+// spent, whatever the selection.
+type BTCInputSelection struct {
+	AddressIndices     []int `json:"addressIndices,omitempty"`     // only these funded addresses; empty = all
+	MinValueSats       int64 `json:"minValueSats,omitempty"`       // skip smaller UTXOs
+	IncludeUnconfirmed bool  `json:"includeUnconfirmed,omitempty"` // also spend mempool UTXOs
+	MaxInputs          int   `json:"maxInputs,omitempty"`          // keep only the largest UTXOs; 0 = no cap
+}
+
+// TokenInfo is a token of the registry: a BEP-20 contract on BSC or an SPL mint
+// on SOL that is scanned, priced and swept like the built-in stablecoins.
+type TokenInfo struct {
+	Chain       Chain  `json:"chain"`
+	Symbol      Token  `json:"symbol"`
+	Contract    string `json:"contract"`
+	Decimals    int    `json:"decimals"`
+	CoinGeckoID string `json:"coingeckoId,omitempty"`
+	Builtin     bool   `json:"builtin"`
+	CreatedAt   string `json:"createdAt,omitempty"`
+}
+
+// AddTokenRequest is the request body for registering a custom token.
+type AddTokenRequest struct {
+	Chain       Chain  `json:"chain"`
+	Symbol      string `json:"symbol"`
+	Contract    string `json:"contract"`
+	Decimals    int    `json:"decimals"`
+	CoinGeckoID string `json:"coingeckoId"`
+}
+
+// FrozenUTXO is a BTC outpoint excluded from every consolidation until unfrozen.
+type FrozenUTXO struct {
+	TxID     string `json:"txid"`
+	Vout     uint32 `json:"vout"`
+	Note     string `json:"note,omitempty"`
+	FrozenAt string `json:"frozenAt"`
+}
+
 // ResumeRequest is the request body for resuming a partial sweep.
 type ResumeRequest struct {
 	SweepID     string `json:"sweepID"`
@@ -304,6 +679,8 @@ type FundedAddressInfo struct {
 	Address      string `json:"address"`
 	Balance      string `json:"balance"`
 	HasGas       bool   `json:"hasGas"`
+	Label        string `json:"label,omitempty"`
+	ExternalID   string `json:"externalId,omitempty"`
 }
 
 // UnifiedSendPreview is the unified preview response for all chains.
@@ -319,6 +696,19 @@ type UnifiedSendPreview struct {
 	NeedsGasPreSeed bool                `json:"needsGasPreSeed"`
 	GasPreSeedCount int                 `json:"gasPreSeedCount"`
 	FundedAddresses []FundedAddressInfo  `json:"fundedAddresses"`
+	PSBT            string              `json:"psbt,omitempty"` // BTC only: base64 unsigned PSBT
+	// BTC only: one entry per consolidation transaction.
+	Chunks []BTCChunkPreview `json:"chunks,omitempty"`
+	// BTC only: fee rate the preview was built at, the confirmation target it
+	// was estimated for and the estimate behind it.
+	FeeRate      int64        `json:"feeRate,omitempty"`
+	FeeTarget    string       `json:"feeTarget,omitempty"`
+	FeeBreakdown *FeeEstimate `json:"feeBreakdown,omitempty"`
+	// BSC only: gas price (wei) to echo back as expectedGasPrice, the max fee
+	// per gas for dynamic fees, and the base fee and tip behind it.
+	GasPrice    string `json:"gasPrice,omitempty"`
+	BaseFee     string `json:"baseFee,omitempty"`
+	PriorityFee string `json:"priorityFee,omitempty"`
 }
 
 // UnifiedSendResult is the unified execute response for all chains.
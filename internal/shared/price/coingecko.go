@@ -6,10 +6,13 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
 )
 
 // coinGeckoIDToSymbol maps CoinGecko coin IDs to our internal token symbols.
@@ -28,6 +31,8 @@ type PriceService struct {
 	cache    map[string]float64
 	cachedAt time.Time
 	stale    bool // true when returning stale cache after fetch failure
+	// tokenIDs maps registry token CoinGecko IDs to symbols, on top of coinGeckoIDToSymbol.
+	tokenIDs map[string]string
 	mu       sync.RWMutex
 }
 
@@ -122,13 +127,76 @@ func (ps *PriceService) IsStale() bool {
 	return ps.stale
 }
 
+// SetTokens prices the registry tokens that have a CoinGecko ID alongside the
+// built-in coins, keyed by their symbol. The cache is dropped so the next
+// GetPrices fetches the new set. Safe to call while the service is shared.
+func (ps *PriceService) SetTokens(tokens []models.TokenInfo) {
+	ids := make(map[string]string)
+	for _, t := range tokens {
+		if t.CoinGeckoID == "" {
+			continue
+		}
+		if _, builtin := coinGeckoIDToSymbol[t.CoinGeckoID]; builtin {
+			continue
+		}
+		ids[t.CoinGeckoID] = string(t.Symbol)
+	}
+
+	ps.mu.Lock()
+	ps.tokenIDs = ids
+	ps.cache = make(map[string]float64)
+	ps.cachedAt = time.Time{}
+	ps.mu.Unlock()
+
+	slog.Info("price service token set updated", "extraCoins", len(ids))
+}
+
+// CheckTokenID reports whether a token priced by the CoinGecko ID id under
+// symbol clashes with the coins already priced: the ID already prices another
+// symbol, or the symbol is already priced by another ID.
+func (ps *PriceService) CheckTokenID(id, symbol string) error {
+	_, symbols := ps.coinIDs()
+	for knownID, knownSymbol := range symbols {
+		if knownID == id && knownSymbol != symbol {
+			return fmt.Errorf("%w: CoinGecko ID %q already prices %s", config.ErrInvalidTokenInfo, id, knownSymbol)
+		}
+		if knownSymbol == symbol && knownID != id {
+			return fmt.Errorf("%w: %s is already priced by CoinGecko ID %q", config.ErrInvalidTokenInfo, symbol, knownID)
+		}
+	}
+	return nil
+}
+
+// coinIDs returns the CoinGecko IDs to fetch and the symbol each one prices.
+// Without registry tokens the ID list is exactly config.CoinGeckoIDs.
+func (ps *PriceService) coinIDs() (string, map[string]string) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	symbols := make(map[string]string, len(coinGeckoIDToSymbol)+len(ps.tokenIDs))
+	for id, symbol := range coinGeckoIDToSymbol {
+		symbols[id] = symbol
+	}
+	extra := make([]string, 0, len(ps.tokenIDs))
+	for id, symbol := range ps.tokenIDs {
+		symbols[id] = symbol
+		extra = append(extra, id)
+	}
+	if len(extra) == 0 {
+		return config.CoinGeckoIDs, symbols
+	}
+	sort.Strings(extra)
+	return config.CoinGeckoIDs + "," + strings.Join(extra, ","), symbols
+}
+
 // coinGeckoResponse represents the CoinGecko /simple/price response.
 // Each key is a coin ID mapping to currency values.
 type coinGeckoResponse map[string]map[string]float64
 
 // fetchPrices fetches fresh prices from the CoinGecko API.
 func (ps *PriceService) fetchPrices(ctx context.Context) (map[string]float64, error) {
-	url := fmt.Sprintf("%s/simple/price?ids=%s&vs_currencies=usd", ps.baseURL, config.CoinGeckoIDs)
+	ids, symbols := ps.coinIDs()
+	url := fmt.Sprintf("%s/simple/price?ids=%s&vs_currencies=usd", ps.baseURL, ids)
 
 	slog.Info("fetching prices from CoinGecko",
 		"url", url,
@@ -170,8 +238,8 @@ func (ps *PriceService) fetchPrices(ctx context.Context) (map[string]float64, er
 	}
 
 	// Map CoinGecko IDs to our symbols.
-	prices := make(map[string]float64, len(coinGeckoIDToSymbol))
-	for cgID, symbol := range coinGeckoIDToSymbol {
+	prices := make(map[string]float64, len(symbols))
+	for cgID, symbol := range symbols {
 		if coinData, ok := cgResp[cgID]; ok {
 			if usd, ok := coinData["usd"]; ok {
 				prices[symbol] = usd
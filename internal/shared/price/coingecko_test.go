@@ -3,12 +3,14 @@ package price
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
 )
 
 // mockCoinGeckoResponse returns a valid CoinGecko-style JSON response.
@@ -273,3 +275,52 @@ func TestGetPrices_StaleExpiredReturnsError(t *testing.T) {
 		t.Fatal("expected error when stale cache is beyond tolerance")
 	}
 }
+
+func TestSetTokens_PricesRegistryTokens(t *testing.T) {
+	var gotIDs string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotIDs = r.URL.Query().Get("ids")
+		resp := mockCoinGeckoResponse()
+		resp["pancakeswap-token"] = map[string]float64{"usd": 2.5}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	ps := NewPriceServiceWithURL(srv.URL)
+	if _, err := ps.GetPrices(context.Background()); err != nil {
+		t.Fatalf("first GetPrices() error = %v", err)
+	}
+
+	// Built-in IDs are not requested twice; tokens without an ID are not priced.
+	ps.SetTokens([]models.TokenInfo{
+		{Chain: models.ChainBSC, Symbol: models.TokenUSDC, CoinGeckoID: config.CoinGeckoIDUSDC, Builtin: true},
+		{Chain: models.ChainBSC, Symbol: "CAKE", CoinGeckoID: "pancakeswap-token"},
+		{Chain: models.ChainSOL, Symbol: "NOID"},
+	})
+
+	prices, err := ps.GetPrices(context.Background())
+	if err != nil {
+		t.Fatalf("GetPrices() after SetTokens error = %v", err)
+	}
+	if want := config.CoinGeckoIDs + ",pancakeswap-token"; gotIDs != want {
+		t.Errorf("ids param = %q, want %q", gotIDs, want)
+	}
+	if prices["CAKE"] != 2.5 || prices["BTC"] != 97500.00 {
+		t.Errorf("prices = %v, want CAKE 2.5 alongside the built-in coins", prices)
+	}
+	if _, ok := prices["NOID"]; ok {
+		t.Error("token without a CoinGecko ID was priced")
+	}
+
+	// An ID prices one symbol and a symbol has one ID.
+	if err := ps.CheckTokenID("pancakeswap-token", "CAKE"); err != nil {
+		t.Errorf("CheckTokenID(same ID and symbol) error = %v", err)
+	}
+	if err := ps.CheckTokenID("tether", "USDT0"); !errors.Is(err, config.ErrInvalidTokenInfo) {
+		t.Errorf("CheckTokenID(tether as USDT0) error = %v, want ErrInvalidTokenInfo", err)
+	}
+	if err := ps.CheckTokenID("pancake-bunny", "CAKE"); !errors.Is(err, config.ErrInvalidTokenInfo) {
+		t.Errorf("CheckTokenID(CAKE by another ID) error = %v, want ErrInvalidTokenInfo", err)
+	}
+}
@@ -271,3 +271,83 @@ func (p *BSCRPCProvider) FetchTokenBalances(ctx context.Context, addresses []mod
 
 	return results, nil
 }
+
+// FetchActivity fetches the nonce and BNB balance of a batch of addresses in a single
+// JSON-RPC batch call (eth_getTransactionCount + eth_getBalance per address). The nonce
+// only counts sent transactions, so an address that received funds and never spent
+// them is detected through its balance instead.
+func (p *BSCRPCProvider) FetchActivity(ctx context.Context, addresses []models.Address) ([]ActivityResult, error) {
+	if len(addresses) == 0 {
+		return nil, nil
+	}
+
+	if err := p.rl.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limiter wait: %w", err)
+	}
+
+	elems := make([]rpc.BatchElem, 0, 2*len(addresses))
+	for _, addr := range addresses {
+		account := common.HexToAddress(addr.Address)
+		elems = append(elems,
+			rpc.BatchElem{
+				Method: "eth_getTransactionCount",
+				Args:   []interface{}{account, "latest"},
+				Result: new(hexutil.Uint64),
+			},
+			rpc.BatchElem{
+				Method: "eth_getBalance",
+				Args:   []interface{}{account, "latest"},
+				Result: new(hexutil.Big),
+			},
+		)
+	}
+
+	if err := p.rpcClient.BatchCallContext(ctx, elems); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("context cancelled during batch: %w", err)
+		}
+		slog.Warn("bsc rpc activity batch failed",
+			"provider", p.name,
+			"error", err,
+		)
+		return nil, fmt.Errorf("batch eth_getTransactionCount: %w", config.NewTransientError(err))
+	}
+
+	results := make([]ActivityResult, len(addresses))
+	var failCount int
+	for i, addr := range addresses {
+		nonceElem, balanceElem := elems[2*i], elems[2*i+1]
+		ar := ActivityResult{
+			Address:      addr.Address,
+			AddressIndex: addr.AddressIndex,
+			Balance:      "0",
+			Source:       p.Name(),
+		}
+
+		switch {
+		case nonceElem.Error != nil:
+			ar.Error = nonceElem.Error.Error()
+			failCount++
+		case balanceElem.Error != nil:
+			ar.Error = balanceElem.Error.Error()
+			failCount++
+		default:
+			ar.TxCount = int(*nonceElem.Result.(*hexutil.Uint64))
+			ar.Balance = (*big.Int)(balanceElem.Result.(*hexutil.Big)).String()
+		}
+
+		results[i] = ar
+	}
+
+	slog.Debug("bsc rpc activity batch complete",
+		"provider", p.name,
+		"total", len(addresses),
+		"failed", failCount,
+	)
+
+	if failCount > 0 && failCount == len(addresses) {
+		return results, fmt.Errorf("all %d addresses failed: %w", failCount, config.ErrProviderUnavailable)
+	}
+
+	return results, nil
+}
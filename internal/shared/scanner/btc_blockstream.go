@@ -18,13 +18,27 @@ type blockstreamResponse struct {
 	ChainStats struct {
 		FundedTxoSum int64 `json:"funded_txo_sum"`
 		SpentTxoSum  int64 `json:"spent_txo_sum"`
+		TxCount      int   `json:"tx_count"`
 	} `json:"chain_stats"`
 	MempoolStats struct {
 		FundedTxoSum int64 `json:"funded_txo_sum"`
 		SpentTxoSum  int64 `json:"spent_txo_sum"`
+		TxCount      int   `json:"tx_count"`
 	} `json:"mempool_stats"`
 }
 
+// balance returns confirmed plus mempool balance in satoshis.
+func (r *blockstreamResponse) balance() int64 {
+	confirmed := r.ChainStats.FundedTxoSum - r.ChainStats.SpentTxoSum
+	mempool := r.MempoolStats.FundedTxoSum - r.MempoolStats.SpentTxoSum
+	return confirmed + mempool
+}
+
+// txCount returns the number of confirmed and mempool transactions touching the address.
+func (r *blockstreamResponse) txCount() int {
+	return r.ChainStats.TxCount + r.MempoolStats.TxCount
+}
+
 // BlockstreamProvider fetches BTC balances from Blockstream Esplora API.
 type BlockstreamProvider struct {
 	client  *http.Client
@@ -116,6 +130,12 @@ func (p *BlockstreamProvider) FetchNativeBalances(ctx context.Context, addresses
 	return results, nil
 }
 
+// FetchActivity fetches the transaction count and balance of each address (one API
+// call per address).
+func (p *BlockstreamProvider) FetchActivity(ctx context.Context, addresses []models.Address) ([]ActivityResult, error) {
+	return fetchEsploraActivity(ctx, p.rl, p.Name(), addresses, p.fetchAddressStats)
+}
+
 // FetchTokenBalances is not supported for BTC.
 func (p *BlockstreamProvider) FetchTokenBalances(_ context.Context, _ []models.Address, _ models.Token, _ string) ([]BalanceResult, error) {
 	return nil, config.ErrTokensNotSupported
@@ -123,6 +143,17 @@ func (p *BlockstreamProvider) FetchTokenBalances(_ context.Context, _ []models.A
 
 // fetchAddressBalance queries a single address and returns the balance in satoshis.
 func (p *BlockstreamProvider) fetchAddressBalance(ctx context.Context, address string) (string, error) {
+	data, err := p.fetchAddressStats(ctx, address)
+	if err != nil {
+		return "0", err
+	}
+
+	// Balance includes mempool (unconfirmed) for a more complete picture.
+	return strconv.FormatInt(data.balance(), 10), nil
+}
+
+// fetchAddressStats queries a single address and returns its chain and mempool stats.
+func (p *BlockstreamProvider) fetchAddressStats(ctx context.Context, address string) (*blockstreamResponse, error) {
 	url := fmt.Sprintf("%s/address/%s", p.baseURL, address)
 
 	slog.Debug("blockstream request",
@@ -131,12 +162,12 @@ func (p *BlockstreamProvider) fetchAddressBalance(ctx context.Context, address s
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return "0", fmt.Errorf("create request: %w", err)
+		return nil, fmt.Errorf("create request: %w", err)
 	}
 
 	resp, err := p.client.Do(req)
 	if err != nil {
-		return "0", fmt.Errorf("execute request: %w", err)
+		return nil, fmt.Errorf("execute request: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -146,7 +177,7 @@ func (p *BlockstreamProvider) fetchAddressBalance(ctx context.Context, address s
 			"address", address,
 			"retryAfter", retryAfter,
 		)
-		return "0", config.NewTransientErrorWithRetry(config.ErrProviderRateLimit, retryAfter)
+		return nil, config.NewTransientErrorWithRetry(config.ErrProviderRateLimit, retryAfter)
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -154,19 +185,64 @@ func (p *BlockstreamProvider) fetchAddressBalance(ctx context.Context, address s
 			"address", address,
 			"status", resp.StatusCode,
 		)
-		return "0", config.NewTransientError(fmt.Errorf("%w: HTTP %d", config.ErrProviderUnavailable, resp.StatusCode))
+		return nil, config.NewTransientError(fmt.Errorf("%w: HTTP %d", config.ErrProviderUnavailable, resp.StatusCode))
 	}
 
 	var data blockstreamResponse
 	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
-		return "0", fmt.Errorf("decode response: %w", err)
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return &data, nil
+}
+
+// fetchEsploraActivity runs fetch for each address against an Esplora-compatible API
+// and converts the stats into activity results. Per-address errors are annotated;
+// an error is returned only when every address failed.
+func fetchEsploraActivity(ctx context.Context, rl *RateLimiter, name string, addresses []models.Address, fetch func(context.Context, string) (*blockstreamResponse, error)) ([]ActivityResult, error) {
+	results := make([]ActivityResult, 0, len(addresses))
+	var failCount int
+
+	for _, addr := range addresses {
+		if err := rl.Wait(ctx); err != nil {
+			return results, fmt.Errorf("rate limiter wait: %w", err)
+		}
+
+		data, err := fetch(ctx, addr.Address)
+		if err != nil {
+			if ctx.Err() != nil {
+				return results, fmt.Errorf("context cancelled during fetch: %w", err)
+			}
+
+			slog.Warn("esplora address activity fetch failed",
+				"provider", name,
+				"address", addr.Address,
+				"index", addr.AddressIndex,
+				"error", err,
+			)
+			failCount++
+			results = append(results, ActivityResult{
+				Address:      addr.Address,
+				AddressIndex: addr.AddressIndex,
+				Balance:      "0",
+				Error:        err.Error(),
+				Source:       name,
+			})
+			continue
+		}
+
+		results = append(results, ActivityResult{
+			Address:      addr.Address,
+			AddressIndex: addr.AddressIndex,
+			TxCount:      data.txCount(),
+			Balance:      strconv.FormatInt(data.balance(), 10),
+			Source:       name,
+		})
 	}
 
-	// Balance = confirmed funded - confirmed spent (satoshis).
-	// We include mempool (unconfirmed) for a more complete picture.
-	confirmedBalance := data.ChainStats.FundedTxoSum - data.ChainStats.SpentTxoSum
-	mempoolBalance := data.MempoolStats.FundedTxoSum - data.MempoolStats.SpentTxoSum
-	totalBalance := confirmedBalance + mempoolBalance
+	if failCount > 0 && failCount == len(addresses) {
+		return results, fmt.Errorf("all %d addresses failed: %w", failCount, config.ErrProviderUnavailable)
+	}
 
-	return strconv.FormatInt(totalBalance, 10), nil
+	return results, nil
 }
@@ -319,3 +319,40 @@ func TestBlockstreamProvider_ContextCancellation(t *testing.T) {
 		t.Fatal("expected error on context cancellation")
 	}
 }
+
+func TestBlockstreamProvider_FetchActivity(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := blockstreamResponse{}
+		if strings.HasSuffix(r.URL.Path, "/bc1qswept") {
+			// Funded and fully spent: zero balance, but used.
+			resp.ChainStats.FundedTxoSum = 50000
+			resp.ChainStats.SpentTxoSum = 50000
+			resp.ChainStats.TxCount = 2
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	provider := &BlockstreamProvider{
+		client:  server.Client(),
+		rl:      NewRateLimiter("test", 100, 0),
+		baseURL: server.URL,
+	}
+
+	results, err := provider.FetchActivity(context.Background(), []models.Address{
+		{Chain: models.ChainBTC, AddressIndex: 0, Address: "bc1qswept"},
+		{Chain: models.ChainBTC, AddressIndex: 1, Address: "bc1qfresh"},
+	})
+	if err != nil {
+		t.Fatalf("FetchActivity() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if !results[0].Used() || results[0].Balance != "0" || results[0].TxCount != 2 {
+		t.Errorf("swept address = %+v, want used with zero balance", results[0])
+	}
+	if results[1].Used() {
+		t.Errorf("fresh address = %+v, want unused", results[1])
+	}
+}
@@ -107,6 +107,12 @@ func (p *MempoolProvider) FetchNativeBalances(ctx context.Context, addresses []m
 	return results, nil
 }
 
+// FetchActivity fetches the transaction count and balance of each address (one API
+// call per address).
+func (p *MempoolProvider) FetchActivity(ctx context.Context, addresses []models.Address) ([]ActivityResult, error) {
+	return fetchEsploraActivity(ctx, p.rl, p.Name(), addresses, p.fetchAddressStats)
+}
+
 // FetchTokenBalances is not supported for BTC.
 func (p *MempoolProvider) FetchTokenBalances(_ context.Context, _ []models.Address, _ models.Token, _ string) ([]BalanceResult, error) {
 	return nil, config.ErrTokensNotSupported
@@ -114,6 +120,16 @@ func (p *MempoolProvider) FetchTokenBalances(_ context.Context, _ []models.Addre
 
 // fetchAddressBalance queries a single address and returns the balance in satoshis.
 func (p *MempoolProvider) fetchAddressBalance(ctx context.Context, address string) (string, error) {
+	data, err := p.fetchAddressStats(ctx, address)
+	if err != nil {
+		return "0", err
+	}
+
+	return strconv.FormatInt(data.balance(), 10), nil
+}
+
+// fetchAddressStats queries a single address and returns its chain and mempool stats.
+func (p *MempoolProvider) fetchAddressStats(ctx context.Context, address string) (*mempoolResponse, error) {
 	url := fmt.Sprintf("%s/address/%s", p.baseURL, address)
 
 	slog.Debug("mempool request",
@@ -122,12 +138,12 @@ func (p *MempoolProvider) fetchAddressBalance(ctx context.Context, address strin
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return "0", fmt.Errorf("create request: %w", err)
+		return nil, fmt.Errorf("create request: %w", err)
 	}
 
 	resp, err := p.client.Do(req)
 	if err != nil {
-		return "0", fmt.Errorf("execute request: %w", err)
+		return nil, fmt.Errorf("execute request: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -137,7 +153,7 @@ func (p *MempoolProvider) fetchAddressBalance(ctx context.Context, address strin
 			"address", address,
 			"retryAfter", retryAfter,
 		)
-		return "0", config.NewTransientErrorWithRetry(config.ErrProviderRateLimit, retryAfter)
+		return nil, config.NewTransientErrorWithRetry(config.ErrProviderRateLimit, retryAfter)
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -145,17 +161,13 @@ func (p *MempoolProvider) fetchAddressBalance(ctx context.Context, address strin
 			"address", address,
 			"status", resp.StatusCode,
 		)
-		return "0", config.NewTransientError(fmt.Errorf("%w: HTTP %d", config.ErrProviderUnavailable, resp.StatusCode))
+		return nil, config.NewTransientError(fmt.Errorf("%w: HTTP %d", config.ErrProviderUnavailable, resp.StatusCode))
 	}
 
 	var data mempoolResponse
 	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
-		return "0", fmt.Errorf("decode response: %w", err)
+		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
-	confirmedBalance := data.ChainStats.FundedTxoSum - data.ChainStats.SpentTxoSum
-	mempoolBalance := data.MempoolStats.FundedTxoSum - data.MempoolStats.SpentTxoSum
-	totalBalance := confirmedBalance + mempoolBalance
-
-	return strconv.FormatInt(totalBalance, 10), nil
+	return &data, nil
 }
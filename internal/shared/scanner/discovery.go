@@ -0,0 +1,276 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+)
+
+// DiscoveryResult is the outcome of a gap-limit discovery pass over one derivation
+// branch of a chain.
+type DiscoveryResult struct {
+	Chain         models.Chain `json:"chain"`
+	Branch        int          `json:"branch"` // config.DiscoveryBranchExternal or DiscoveryBranchInternal
+	GapLimit      int          `json:"gapLimit"`
+	LastUsedIndex int          `json:"lastUsedIndex"` // -1 when no address was used
+	Used          int          `json:"used"`
+	Scanned       int          `json:"scanned"`
+	Duration      string       `json:"duration"`
+}
+
+// Discover derives and checks the addresses of one branch of chain from index 0 with
+// derive until gapLimit consecutive addresses show no transaction history, no native
+// balance and no token balance. Addresses are checked gapLimit at a time, so at most
+// one window past the stop point is queried. The search is capped at
+// config.MaxAddressesPerChain indices.
+//
+// Discovery needs at least one provider implementing ActivityProvider in the chain's
+// pool, and fails rather than guessing when the activity of an address is unknown.
+func (s *Scanner) Discover(ctx context.Context, chain models.Chain, branch int, derive func(index uint32) (string, error), gapLimit int) (*DiscoveryResult, error) {
+	if gapLimit < 1 || gapLimit > config.MaxGapLimit {
+		return nil, fmt.Errorf("gap limit must be between 1 and %d, got %d", config.MaxGapLimit, gapLimit)
+	}
+
+	pool, ok := s.pools[chain]
+	if !ok {
+		return nil, fmt.Errorf("no provider pool registered for %s", chain)
+	}
+
+	start := time.Now()
+	result := &DiscoveryResult{
+		Chain:         chain,
+		Branch:        branch,
+		GapLimit:      gapLimit,
+		LastUsedIndex: -1,
+	}
+
+	slog.Info("address discovery starting",
+		"chain", chain,
+		"branch", branch,
+		"gapLimit", gapLimit,
+	)
+
+	gap := 0
+	for next := 0; gap < gapLimit && next < config.MaxAddressesPerChain; next += gapLimit {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		end := min(next+gapLimit, config.MaxAddressesPerChain)
+		batch := make([]models.Address, 0, end-next)
+		for index := next; index < end; index++ {
+			addr, err := derive(uint32(index))
+			if err != nil {
+				return nil, fmt.Errorf("derive %s branch %d address at index %d: %w", chain, branch, index, err)
+			}
+			batch = append(batch, models.Address{Chain: chain, AddressIndex: index, Address: addr})
+		}
+
+		used, err := s.usedAddresses(ctx, pool, chain, batch)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, addr := range batch {
+			result.Scanned++
+			if used[addr.AddressIndex] {
+				result.LastUsedIndex = addr.AddressIndex
+				result.Used++
+				gap = 0
+				continue
+			}
+			if gap++; gap >= gapLimit {
+				break
+			}
+		}
+
+		slog.Debug("address discovery progress",
+			"chain", chain,
+			"branch", branch,
+			"scanned", result.Scanned,
+			"lastUsedIndex", result.LastUsedIndex,
+			"gap", gap,
+		)
+	}
+
+	if gap < gapLimit {
+		slog.Warn("address discovery reached the per-chain address limit",
+			"chain", chain,
+			"branch", branch,
+			"limit", config.MaxAddressesPerChain,
+		)
+	}
+
+	result.Duration = time.Since(start).Round(time.Millisecond).String()
+
+	slog.Info("address discovery complete",
+		"chain", chain,
+		"branch", branch,
+		"lastUsedIndex", result.LastUsedIndex,
+		"used", result.Used,
+		"scanned", result.Scanned,
+		"duration", result.Duration,
+	)
+	return result, nil
+}
+
+// usedAddresses returns the indices in batch that have history or a native or token
+// balance.
+func (s *Scanner) usedAddresses(ctx context.Context, pool *Pool, chain models.Chain, batch []models.Address) (map[int]bool, error) {
+	activity, err := pool.FetchActivity(ctx, batch)
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[int]bool, len(batch))
+	var unused []models.Address
+	for _, a := range activity {
+		if a.Used() {
+			used[a.AddressIndex] = true
+		}
+	}
+	for _, addr := range batch {
+		if !used[addr.AddressIndex] {
+			unused = append(unused, addr)
+		}
+	}
+
+	// Token-only addresses (received a token, never sent) have no nonce on BSC
+	// and no native balance, so ask the token providers too.
+	for _, tc := range s.scanTokens(chain) {
+		if len(unused) == 0 {
+			break
+		}
+		results, err := pool.FetchTokenBalances(ctx, unused, tc.Symbol, tc.Contract)
+		if errors.Is(err, config.ErrTokensNotSupported) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("fetch %s %s balances for discovery: %w", chain, tc.Symbol, err)
+		}
+		for _, r := range results {
+			if r.Error != "" {
+				return nil, fmt.Errorf("%w: %s %s balance of index %d unknown: %s", config.ErrAllProvidersFailed, chain, tc.Symbol, r.AddressIndex, r.Error)
+			}
+			if r.Balance != "" && r.Balance != "0" {
+				used[r.AddressIndex] = true
+			}
+		}
+
+		remaining := unused[:0]
+		for _, addr := range unused {
+			if !used[addr.AddressIndex] {
+				remaining = append(remaining, addr)
+			}
+		}
+		unused = remaining
+	}
+
+	return used, nil
+}
+
+// FetchActivity fetches the history of addresses from the pool's healthy providers
+// that implement ActivityProvider, in order, retrying addresses that failed on the
+// next one. Unlike balance fetches, an address whose activity could not be
+// determined is an error: discovery must not mistake it for an unused address.
+// Results are sorted by address index.
+func (p *Pool) FetchActivity(ctx context.Context, addresses []models.Address) ([]ActivityResult, error) {
+	if len(addresses) == 0 {
+		return nil, nil
+	}
+
+	supported := false
+	for _, provider := range p.providers {
+		if _, ok := provider.(ActivityProvider); ok {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return nil, fmt.Errorf("%s: %w", p.chain, config.ErrActivityNotSupported)
+	}
+
+	resolved := make([]ActivityResult, 0, len(addresses))
+	pending := addresses
+	var lastErr error
+
+	for _, pa := range p.healthyAssignments() {
+		ap, ok := pa.provider.(ActivityProvider)
+		if !ok {
+			continue
+		}
+
+		results, err := fetchActivitySubBatched(ctx, ap, pending)
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("context cancelled: %w", ctx.Err())
+		}
+		if err != nil {
+			p.recordFailure(pa, err)
+			lastErr = err
+			slog.Warn("activity provider failed",
+				"chain", p.chain,
+				"provider", pa.provider.Name(),
+				"error", err,
+			)
+		} else {
+			p.recordSuccess(pa)
+		}
+
+		done := make(map[int]bool, len(results))
+		for _, r := range results {
+			if r.Error == "" {
+				resolved = append(resolved, r)
+				done[r.AddressIndex] = true
+			} else {
+				lastErr = errors.New(r.Error)
+			}
+		}
+
+		var retry []models.Address
+		for _, addr := range pending {
+			if !done[addr.AddressIndex] {
+				retry = append(retry, addr)
+			}
+		}
+		pending = retry
+		if len(pending) == 0 {
+			break
+		}
+	}
+
+	if len(pending) > 0 {
+		return nil, fmt.Errorf("activity of %d %s addresses unknown (last error: %v): %w", len(pending), p.chain, lastErr, config.ErrAllProvidersFailed)
+	}
+
+	sort.Slice(resolved, func(i, j int) bool {
+		return resolved[i].AddressIndex < resolved[j].AddressIndex
+	})
+	return resolved, nil
+}
+
+// fetchActivitySubBatched calls provider.FetchActivity in MaxBatchSize chunks and
+// returns the results gathered before the first error.
+func fetchActivitySubBatched(ctx context.Context, provider ActivityProvider, addrs []models.Address) ([]ActivityResult, error) {
+	batchSize := max(provider.MaxBatchSize(), 1)
+
+	var results []ActivityResult
+	for start := 0; start < len(addrs); start += batchSize {
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+
+		end := min(start+batchSize, len(addrs))
+		batchResults, err := provider.FetchActivity(ctx, addrs[start:end])
+		results = append(results, batchResults...)
+		if err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
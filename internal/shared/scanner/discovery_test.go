@@ -0,0 +1,147 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+)
+
+// mockActivityProvider implements ActivityProvider for testing.
+type mockActivityProvider struct {
+	mockProvider
+	used    map[string]bool
+	failFor map[string]bool
+	calls   int
+}
+
+func (m *mockActivityProvider) FetchActivity(_ context.Context, addresses []models.Address) ([]ActivityResult, error) {
+	m.calls++
+	results := make([]ActivityResult, len(addresses))
+	for i, a := range addresses {
+		results[i] = ActivityResult{Address: a.Address, AddressIndex: a.AddressIndex, Balance: "0", Source: m.name}
+		if m.failFor[a.Address] {
+			results[i].Error = "timeout"
+		} else if m.used[a.Address] {
+			results[i].TxCount = 2
+		}
+	}
+	return results, nil
+}
+
+func discoveryDeriver(index uint32) (string, error) {
+	return fmt.Sprintf("addr_%d", index), nil
+}
+
+func TestScanner_Discover(t *testing.T) {
+	provider := &mockActivityProvider{
+		mockProvider: mockProvider{name: "Activity", chain: models.ChainBTC, batchSize: 4},
+		used:         map[string]bool{"addr_0": true, "addr_3": true, "addr_12": true},
+	}
+	scanner := SetupScannerForTest(setupTestDB(t), NewSSEHub(), map[models.Chain]*Pool{
+		models.ChainBTC: NewPool(models.ChainBTC, provider),
+	})
+
+	result, err := scanner.Discover(context.Background(), models.ChainBTC, config.DiscoveryBranchExternal, discoveryDeriver, 10)
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+
+	// Indices 13..22 are the ten unused addresses that end the search.
+	if result.LastUsedIndex != 12 || result.Used != 3 || result.Scanned != 23 {
+		t.Errorf("result = %+v, want last used 12, 3 used, 23 scanned", result)
+	}
+}
+
+func TestScanner_DiscoverNothingUsed(t *testing.T) {
+	provider := &mockActivityProvider{
+		mockProvider: mockProvider{name: "Activity", chain: models.ChainBTC, batchSize: 1},
+	}
+	scanner := SetupScannerForTest(setupTestDB(t), NewSSEHub(), map[models.Chain]*Pool{
+		models.ChainBTC: NewPool(models.ChainBTC, provider),
+	})
+
+	result, err := scanner.Discover(context.Background(), models.ChainBTC, config.DiscoveryBranchInternal, discoveryDeriver, 5)
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	if result.LastUsedIndex != -1 || result.Scanned != 5 || result.Branch != config.DiscoveryBranchInternal {
+		t.Errorf("result = %+v, want nothing used after 5 scanned", result)
+	}
+}
+
+func TestScanner_DiscoverTokenOnlyAddress(t *testing.T) {
+	provider := &mockActivityProvider{
+		mockProvider: mockProvider{
+			name:      "Activity",
+			chain:     models.ChainBSC,
+			batchSize: 20,
+			tokenFunc: func(_ context.Context, addresses []models.Address, token models.Token, _ string) ([]BalanceResult, error) {
+				results := make([]BalanceResult, len(addresses))
+				for i, a := range addresses {
+					results[i] = BalanceResult{Address: a.Address, AddressIndex: a.AddressIndex, Balance: "0"}
+					if token == models.TokenUSDT && a.Address == "addr_7" {
+						results[i].Balance = "1000000"
+					}
+				}
+				return results, nil
+			},
+		},
+	}
+	scanner := SetupScannerForTest(setupTestDB(t), NewSSEHub(), map[models.Chain]*Pool{
+		models.ChainBSC: NewPool(models.ChainBSC, provider),
+	})
+
+	result, err := scanner.Discover(context.Background(), models.ChainBSC, config.DiscoveryBranchExternal, discoveryDeriver, 10)
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	if result.LastUsedIndex != 7 || result.Used != 1 {
+		t.Errorf("result = %+v, want the USDT-only address 7 discovered", result)
+	}
+}
+
+func TestPool_FetchActivityFailover(t *testing.T) {
+	flaky := &mockActivityProvider{
+		mockProvider: mockProvider{name: "Flaky", chain: models.ChainBTC, batchSize: 1},
+		failFor:      map[string]bool{"addr_1": true},
+	}
+	backup := &mockActivityProvider{
+		mockProvider: mockProvider{name: "Backup", chain: models.ChainBTC, batchSize: 1},
+		used:         map[string]bool{"addr_1": true},
+	}
+	pool := NewPool(models.ChainBTC, flaky, backup)
+
+	addrs := []models.Address{
+		{Chain: models.ChainBTC, AddressIndex: 0, Address: "addr_0"},
+		{Chain: models.ChainBTC, AddressIndex: 1, Address: "addr_1"},
+	}
+	results, err := pool.FetchActivity(context.Background(), addrs)
+	if err != nil {
+		t.Fatalf("FetchActivity() error = %v", err)
+	}
+	if len(results) != 2 || results[1].Source != "Backup" || !results[1].Used() {
+		t.Errorf("results = %+v, want index 1 resolved as used by Backup", results)
+	}
+	if backup.calls != 1 {
+		t.Errorf("backup called %d times, want 1 (only for the failed address)", backup.calls)
+	}
+
+	// Activity that stays unknown is an error, never "unused".
+	backup.failFor = map[string]bool{"addr_1": true}
+	if _, err := pool.FetchActivity(context.Background(), addrs); !errors.Is(err, config.ErrAllProvidersFailed) {
+		t.Errorf("FetchActivity() error = %v, want ErrAllProvidersFailed", err)
+	}
+}
+
+func TestPool_FetchActivityNotSupported(t *testing.T) {
+	pool := NewPool(models.ChainBTC, &mockProvider{name: "BalanceOnly", chain: models.ChainBTC, batchSize: 1})
+
+	_, err := pool.FetchActivity(context.Background(), makeAddresses(1))
+	if !errors.Is(err, config.ErrActivityNotSupported) {
+		t.Errorf("FetchActivity() error = %v, want ErrActivityNotSupported", err)
+	}
+}
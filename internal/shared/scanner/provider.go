@@ -35,3 +35,28 @@ type Provider interface {
 	// BTC providers should return config.ErrTokensNotSupported.
 	FetchTokenBalances(ctx context.Context, addresses []models.Address, token models.Token, contractOrMint string) ([]BalanceResult, error)
 }
+
+// ActivityResult reports whether an address has ever been used on-chain.
+type ActivityResult struct {
+	Address      string
+	AddressIndex int
+	TxCount      int    // transactions seen for the address (BSC: sent transactions, i.e. the nonce)
+	Balance      string // raw native balance string
+	Error        string // non-empty if the activity is unknown
+	Source       string // provider name that returned this result
+}
+
+// Used reports whether the address has any history or a non-zero native balance.
+func (r ActivityResult) Used() bool {
+	return r.TxCount > 0 || (r.Balance != "" && r.Balance != "0")
+}
+
+// ActivityProvider is implemented by providers that can tell whether an address has
+// any transaction history, not just a current balance. Gap-limit discovery relies on
+// it: an address that was funded and later swept has a zero balance but is used.
+type ActivityProvider interface {
+	Provider
+
+	// FetchActivity returns the history and native balance for a batch of addresses.
+	FetchActivity(ctx context.Context, addresses []models.Address) ([]ActivityResult, error)
+}
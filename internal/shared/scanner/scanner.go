@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strconv"
 	"sync"
 	"time"
 
@@ -12,64 +13,45 @@ import (
 	"github.com/Fantasim/hdpay/internal/shared/models"
 )
 
-// tokenConfigEntry describes a token to scan for a chain.
-type tokenConfigEntry struct {
-	Token    models.Token
-	Contract string
-}
-
-// buildTokenConfig returns the token contract map based on network.
-func buildTokenConfig(network string) map[models.Chain][]tokenConfigEntry {
-	if network == string(models.NetworkTestnet) {
-		return map[models.Chain][]tokenConfigEntry{
-			models.ChainBTC: {},
-			models.ChainBSC: {
-				{Token: models.TokenUSDC, Contract: config.BSCTestnetUSDCContract},
-				{Token: models.TokenUSDT, Contract: config.BSCTestnetUSDTContract},
-			},
-			models.ChainSOL: {
-				{Token: models.TokenUSDC, Contract: config.SOLTestnetUSDCMint},
-				{Token: models.TokenUSDT, Contract: config.SOLTestnetUSDTMint},
-			},
-		}
-	}
-	return map[models.Chain][]tokenConfigEntry{
-		models.ChainBTC: {},
-		models.ChainBSC: {
-			{Token: models.TokenUSDC, Contract: config.BSCUSDCContract},
-			{Token: models.TokenUSDT, Contract: config.BSCUSDTContract},
-		},
-		models.ChainSOL: {
-			{Token: models.TokenUSDC, Contract: config.SOLUSDCMint},
-			{Token: models.TokenUSDT, Contract: config.SOLUSDTMint},
-		},
-	}
-}
-
 // Scanner orchestrates balance scanning across chains.
 type Scanner struct {
-	db          *db.DB
-	pools       map[models.Chain]*Pool
-	hub         *SSEHub
-	cfg         *config.Config
-	cancels     map[models.Chain]context.CancelFunc
-	tokenConfig map[models.Chain][]tokenConfigEntry
-	mu          sync.Mutex
+	db      *db.DB
+	pools   map[models.Chain]*Pool
+	hub     *SSEHub
+	cfg     *config.Config
+	cancels map[models.Chain]context.CancelFunc
+	mu      sync.Mutex
 }
 
 // New creates a new scanner orchestrator.
 func New(database *db.DB, cfg *config.Config, hub *SSEHub) *Scanner {
 	slog.Info("scanner orchestrator created")
 	return &Scanner{
-		db:          database,
-		pools:       make(map[models.Chain]*Pool),
-		hub:         hub,
-		cfg:         cfg,
-		cancels:     make(map[models.Chain]context.CancelFunc),
-		tokenConfig: buildTokenConfig(cfg.Network),
+		db:      database,
+		pools:   make(map[models.Chain]*Pool),
+		hub:     hub,
+		cfg:     cfg,
+		cancels: make(map[models.Chain]context.CancelFunc),
 	}
 }
 
+// scanTokens returns the registry tokens to scan on a chain. A registry read
+// failure is logged and scans native balances only rather than failing the scan.
+func (s *Scanner) scanTokens(chain models.Chain) []models.TokenInfo {
+	if chain == models.ChainBTC {
+		return nil
+	}
+	tokens, err := s.db.ListTokens(chain)
+	if err != nil {
+		slog.Error("failed to load token registry, scanning native balances only",
+			"chain", chain,
+			"error", err,
+		)
+		return nil
+	}
+	return tokens
+}
+
 // RegisterPool adds a provider pool for a chain.
 func (s *Scanner) RegisterPool(chain models.Chain, pool *Pool) {
 	s.pools[chain] = pool
@@ -100,6 +82,7 @@ func (s *Scanner) StartScan(ctx context.Context, chain models.Chain, maxID int)
 
 	slog.Info("scan starting",
 		"chain", chain,
+		"account", s.db.Account(),
 		"maxID", maxID,
 	)
 
@@ -211,12 +194,15 @@ func (s *Scanner) runScan(ctx context.Context, chain models.Chain, pool *Pool, s
 	checkedCount := 0
 	errorCount := 0
 	consecutivePoolFails := 0
+	// Tokens registered while the scan runs are picked up by the next scan.
+	tokens := s.scanTokens(chain)
 
 	slog.Info("scan goroutine started",
 		"chain", chain,
 		"startIndex", startIndex,
 		"maxID", maxID,
 		"batchSize", batchSize,
+		"tokens", len(tokens),
 	)
 
 	for i := startIndex; i < maxID; i += batchSize {
@@ -368,11 +354,8 @@ func (s *Scanner) runScan(ctx context.Context, chain models.Chain, pool *Pool, s
 
 		// --- Fetch token balances (B8: decoupled from native) ---
 		var allTokenBalances []models.Balance
-		for _, tc := range s.tokenConfig[chain] {
-			if tc.Contract == "" {
-				continue
-			}
-			tokenResults, err := pool.FetchTokenBalances(ctx, addresses, tc.Token, tc.Contract)
+		for _, tc := range tokens {
+			tokenResults, err := pool.FetchTokenBalances(ctx, addresses, tc.Symbol, tc.Contract)
 			if err != nil {
 				if ctx.Err() != nil {
 					s.removeScan(chain)
@@ -384,14 +367,14 @@ func (s *Scanner) runScan(ctx context.Context, chain models.Chain, pool *Pool, s
 				consecutivePoolFails++
 				slog.Warn("token balance fetch failed",
 					"chain", chain,
-					"token", tc.Token,
+					"token", tc.Symbol,
 					"error", err,
 				)
 				s.hub.Broadcast(Event{
 					Type: "scan_token_error",
 					Data: ScanTokenErrorData{
 						Chain:   string(chain),
-						Token:   string(tc.Token),
+						Token:   string(tc.Symbol),
 						Error:   config.ErrorTokenScanFailed,
 						Message: err.Error(),
 					},
@@ -403,7 +386,7 @@ func (s *Scanner) runScan(ctx context.Context, chain models.Chain, pool *Pool, s
 				allTokenBalances = append(allTokenBalances, models.Balance{
 					Chain:        chain,
 					AddressIndex: r.AddressIndex,
-					Token:        tc.Token,
+					Token:        tc.Symbol,
 					Balance:      r.Balance,
 				})
 			}
@@ -543,10 +526,74 @@ func (s *Scanner) runScan(ctx context.Context, chain models.Chain, pool *Pool, s
 		})
 	}
 
+	if chain == models.ChainBTC {
+		s.scanBTCChange(ctx, pool)
+	}
+
 	// Scan complete.
 	s.finishScan(chain, maxID, scanned, found, checkedCount, errorCount, db.ScanStatusCompleted, startTime, nil)
 }
 
+// scanBTCChange refreshes the balances of the change addresses payouts sent
+// change to. They live on the internal chain, outside the indexed address set.
+// Failures are logged and leave the stored balances as they were; they never fail
+// the scan.
+func (s *Scanner) scanBTCChange(ctx context.Context, pool *Pool) {
+	rows, err := s.db.ListBTCChangeAddresses()
+	if err != nil {
+		slog.Error("failed to load BTC change addresses", "error", err)
+		return
+	}
+	if len(rows) == 0 {
+		return
+	}
+
+	batchSize := scanChunkSize(models.ChainBTC)
+	balances := make(map[string]int64, len(rows))
+	for start := 0; start < len(rows); start += batchSize {
+		end := start + batchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		addresses := make([]models.Address, 0, end-start)
+		for _, row := range rows[start:end] {
+			addresses = append(addresses, models.Address{
+				Chain:        models.ChainBTC,
+				AddressIndex: row.ChangeIndex,
+				Address:      row.Address,
+				Change:       true,
+			})
+		}
+
+		results, err := pool.FetchNativeBalances(ctx, addresses)
+		if err != nil {
+			slog.Error("BTC change balance fetch failed", "start", start, "error", err)
+			continue
+		}
+		for _, r := range results {
+			if r.Error != "" {
+				continue
+			}
+			sats, err := strconv.ParseInt(r.Balance, 10, 64)
+			if err != nil {
+				slog.Warn("invalid BTC change balance", "address", r.Address, "balance", r.Balance)
+				continue
+			}
+			balances[r.Address] = sats
+		}
+	}
+
+	if err := s.db.UpdateBTCChangeBalances(balances); err != nil {
+		slog.Error("failed to store BTC change balances", "error", err)
+		return
+	}
+
+	slog.Info("BTC change addresses scanned",
+		"changeAddresses", len(rows),
+		"updated", len(balances),
+	)
+}
+
 // finishScan updates state and broadcasts completion/error events.
 func (s *Scanner) finishScan(chain models.Chain, maxID, scanned, found, checked, errors int, status string, startTime time.Time, scanErr error) {
 	duration := time.Since(startTime).Round(time.Second)
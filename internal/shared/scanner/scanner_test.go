@@ -309,3 +309,70 @@ done:
 		t.Errorf("expected balance 50000, got %s", funded[0].Balance)
 	}
 }
+
+func TestScanner_ScansBTCChangeAddresses(t *testing.T) {
+	database := setupTestDB(t)
+	hub := NewSSEHub()
+
+	seedAddresses(t, database, models.ChainBTC, 2)
+	for idx, addr := range []string{"change_spent", "change_funded"} {
+		if err := database.RecordBTCChangeAddress(db.BTCChangeAddressRow{
+			AddressType: string(models.BTCAddressP2WPKH), ChangeIndex: idx, Address: addr, TxHash: "payout", AmountSats: 5000,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var changeScanned []models.Address
+	provider := &mockProvider{
+		name:      "TestProvider",
+		chain:     models.ChainBTC,
+		batchSize: 10,
+		nativeFunc: func(ctx context.Context, addresses []models.Address) ([]BalanceResult, error) {
+			results := make([]BalanceResult, len(addresses))
+			for i, a := range addresses {
+				bal := "0"
+				if a.Change {
+					changeScanned = append(changeScanned, a)
+					if a.Address == "change_funded" {
+						bal = "4200"
+					}
+				}
+				results[i] = BalanceResult{Address: a.Address, AddressIndex: a.AddressIndex, Balance: bal}
+			}
+			return results, nil
+		},
+	}
+
+	scanner := SetupScannerForTest(database, hub, map[models.Chain]*Pool{
+		models.ChainBTC: NewPool(models.ChainBTC, provider),
+	})
+
+	ch := hub.Subscribe()
+	defer hub.Unsubscribe(ch)
+
+	if err := scanner.StartScan(context.Background(), models.ChainBTC, 2); err != nil {
+		t.Fatalf("StartScan() error = %v", err)
+	}
+
+	deadline := time.After(5 * time.Second)
+	for completed := false; !completed; {
+		select {
+		case event := <-ch:
+			completed = event.Type == "scan_complete"
+		case <-deadline:
+			t.Fatal("scan did not complete within timeout")
+		}
+	}
+
+	if len(changeScanned) != 2 {
+		t.Fatalf("scanned %d change addresses, want 2", len(changeScanned))
+	}
+	funded, err := database.GetFundedBTCChangeAddresses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(funded) != 1 || funded[0].Address != "change_funded" || funded[0].AddressIndex != 1 {
+		t.Errorf("funded change addresses = %+v, want change_funded at index 1", funded)
+	}
+}
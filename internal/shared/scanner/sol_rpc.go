@@ -386,29 +386,122 @@ func (p *SolanaRPCProvider) FetchTokenBalances(ctx context.Context, addresses []
 	return results, nil
 }
 
+// FetchActivity fetches the lamport balance of a batch of addresses with
+// getMultipleAccounts, then asks getSignaturesForAddress (limit 1) whether each
+// unfunded address has any transaction history.
+func (p *SolanaRPCProvider) FetchActivity(ctx context.Context, addresses []models.Address) ([]ActivityResult, error) {
+	balances, err := p.FetchNativeBalances(ctx, addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]ActivityResult, 0, len(balances))
+	var failCount int
+	for _, b := range balances {
+		ar := ActivityResult{
+			Address:      b.Address,
+			AddressIndex: b.AddressIndex,
+			Balance:      b.Balance,
+			Error:        b.Error,
+			Source:       p.Name(),
+		}
+
+		if ar.Error == "" && !ar.Used() {
+			count, err := p.fetchSignatureCount(ctx, b.Address)
+			if err != nil {
+				if ctx.Err() != nil {
+					return results, fmt.Errorf("context cancelled during fetch: %w", err)
+				}
+				slog.Warn("solana signature lookup failed",
+					"provider", p.name,
+					"address", b.Address,
+					"index", b.AddressIndex,
+					"error", err,
+				)
+				ar.Error = err.Error()
+			}
+			ar.TxCount = count
+		}
+
+		if ar.Error != "" {
+			failCount++
+		}
+		results = append(results, ar)
+	}
+
+	if failCount > 0 && failCount == len(addresses) {
+		return results, fmt.Errorf("all %d addresses failed: %w", failCount, config.ErrProviderUnavailable)
+	}
+
+	return results, nil
+}
+
+// fetchSignatureCount returns 1 if the address has at least one transaction
+// signature, 0 otherwise.
+func (p *SolanaRPCProvider) fetchSignatureCount(ctx context.Context, address string) (int, error) {
+	if err := p.rl.Wait(ctx); err != nil {
+		return 0, fmt.Errorf("rate limiter wait: %w", err)
+	}
+
+	rpcReq := solanaRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "getSignaturesForAddress",
+		Params: []interface{}{
+			address,
+			map[string]int{"limit": 1},
+		},
+	}
+
+	var rpcResp struct {
+		Error *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error,omitempty"`
+		Result []json.RawMessage `json:"result"`
+	}
+	if err := p.postRPC(ctx, rpcReq, &rpcResp); err != nil {
+		return 0, err
+	}
+	if rpcResp.Error != nil {
+		return 0, fmt.Errorf("%w: %s", config.ErrProviderUnavailable, rpcResp.Error.Message)
+	}
+
+	return len(rpcResp.Result), nil
+}
+
 // doRPCCall sends a JSON-RPC request and returns the parsed response.
 func (p *SolanaRPCProvider) doRPCCall(ctx context.Context, rpcReq solanaRPCRequest) (*solanaRPCResponse, error) {
+	var rpcResp solanaRPCResponse
+	if err := p.postRPC(ctx, rpcReq, &rpcResp); err != nil {
+		return nil, err
+	}
+	return &rpcResp, nil
+}
+
+// postRPC sends a JSON-RPC request and decodes the response into out.
+func (p *SolanaRPCProvider) postRPC(ctx context.Context, rpcReq solanaRPCRequest, out interface{}) error {
 	body, err := json.Marshal(rpcReq)
 	if err != nil {
-		return nil, fmt.Errorf("marshal rpc request: %w", err)
+		return fmt.Errorf("marshal rpc request: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.rpcURL, bytes.NewReader(body))
 	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
+		return fmt.Errorf("create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := p.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("execute request: %w", err)
+		return fmt.Errorf("execute request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusTooManyRequests {
 		retryAfter := parseRetryAfter(resp.Header)
 		slog.Warn("solana rpc rate limited", "provider", p.name, "retryAfter", retryAfter)
-		return nil, config.NewTransientErrorWithRetry(config.ErrProviderRateLimit, retryAfter)
+		return config.NewTransientErrorWithRetry(config.ErrProviderRateLimit, retryAfter)
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -416,13 +509,12 @@ func (p *SolanaRPCProvider) doRPCCall(ctx context.Context, rpcReq solanaRPCReque
 			"provider", p.name,
 			"status", resp.StatusCode,
 		)
-		return nil, config.NewTransientError(fmt.Errorf("%w: HTTP %d", config.ErrProviderUnavailable, resp.StatusCode))
+		return config.NewTransientError(fmt.Errorf("%w: HTTP %d", config.ErrProviderUnavailable, resp.StatusCode))
 	}
 
-	var rpcResp solanaRPCResponse
-	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
-		return nil, fmt.Errorf("decode rpc response: %w", err)
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode rpc response: %w", err)
 	}
 
-	return &rpcResp, nil
+	return nil
 }
@@ -2,19 +2,27 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Fantasim/hdpay/internal/shared/config"
 	"github.com/Fantasim/hdpay/internal/wallet/db"
+	"github.com/Fantasim/hdpay/internal/wallet/hd"
 	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/wallet/tx"
 	"github.com/go-chi/chi/v5"
 )
 
 // ListAddresses handles GET /api/addresses/{chain}
+//
+// Query params: page, pageSize, hasBalance=true, token (NATIVE, USDC or USDT),
+// tag / externalId to select addresses by their metadata, and allocated=true|false.
 func ListAddresses(database *db.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
@@ -39,6 +47,17 @@ func ListAddresses(database *db.DB) http.HandlerFunc {
 		pageSize := parseIntParam(r, "pageSize", config.DefaultPageSize)
 		hasBalance := r.URL.Query().Get("hasBalance") == "true"
 		token := strings.ToUpper(r.URL.Query().Get("token"))
+		tag := strings.TrimSpace(r.URL.Query().Get("tag"))
+		externalID := strings.TrimSpace(r.URL.Query().Get("externalId"))
+		var allocated *bool
+		switch r.URL.Query().Get("allocated") {
+		case "true":
+			v := true
+			allocated = &v
+		case "false":
+			v := false
+			allocated = &v
+		}
 
 		// Clamp page size
 		if pageSize > config.MaxPageSize {
@@ -52,10 +71,18 @@ func ListAddresses(database *db.DB) http.HandlerFunc {
 		}
 
 		// Validate token filter
-		if token != "" && token != "NATIVE" && token != "USDC" && token != "USDT" {
-			slog.Warn("invalid token parameter", "token", token)
-			writeError(w, http.StatusBadRequest, config.ErrorInvalidToken, "invalid token: "+token+", must be NATIVE, USDC, or USDT")
-			return
+		if token != "" {
+			known, err := isRegisteredToken(database, models.Token(token))
+			if err != nil {
+				slog.Error("failed to list tokens for token filter", "error", err)
+				writeError(w, http.StatusInternalServerError, config.ErrorDatabase, "failed to list tokens")
+				return
+			}
+			if !known {
+				slog.Warn("invalid token parameter", "token", token)
+				writeError(w, http.StatusBadRequest, config.ErrorInvalidToken, "invalid token: "+token+", must be NATIVE or a registered token")
+				return
+			}
 		}
 
 		slog.Debug("parsed address list params",
@@ -64,6 +91,9 @@ func ListAddresses(database *db.DB) http.HandlerFunc {
 			"pageSize", pageSize,
 			"hasBalance", hasBalance,
 			"token", token,
+			"tag", tag,
+			"externalId", externalID,
+			"allocated", r.URL.Query().Get("allocated"),
 		)
 
 		filter := db.AddressFilter{
@@ -72,6 +102,9 @@ func ListAddresses(database *db.DB) http.HandlerFunc {
 			PageSize:   pageSize,
 			HasBalance: hasBalance,
 			Token:      token,
+			Tag:        tag,
+			ExternalID: externalID,
+			Allocated:  allocated,
 		}
 
 		addresses, total, err := database.GetAddressesWithBalances(filter)
@@ -107,61 +140,248 @@ func ListAddresses(database *db.DB) http.HandlerFunc {
 	}
 }
 
-// ExportAddresses handles GET /api/addresses/{chain}/export
-func ExportAddresses(database *db.DB) http.HandlerFunc {
+// extendAddressesRequest is the JSON body for POST /api/addresses/{chain}/extend.
+type extendAddressesRequest struct {
+	ExtendTo int `json:"extendTo"`
+}
+
+// ExtendAddresses handles POST /api/addresses/{chain}/extend.
+// Appends addresses derived from the seed from max(address_index)+1 up to extendTo-1
+// and raises the chain's max scan ID; balances and history are untouched. At most
+// config.MaxAddressExtendPerRequest addresses are added per call. Needs keyService,
+// so it is unavailable in watch-only mode (use hdpay init --extend-to --xpub there).
+func ExtendAddresses(database *db.DB, keyService *tx.KeyService) http.HandlerFunc {
+	// Serializes extensions so two calls never insert the same indices.
+	var mu sync.Mutex
+
 	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
 		chainParam := strings.ToUpper(chi.URLParam(r, "chain"))
 
-		slog.Info("export addresses requested",
+		var req extendAddressesRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			slog.Warn("invalid extend addresses request body", "error", err, "remoteAddr", r.RemoteAddr)
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidExtendTarget, "invalid request body")
+			return
+		}
+
+		slog.Info("extend addresses requested",
 			"chain", chainParam,
+			"extendTo", req.ExtendTo,
 			"remoteAddr", r.RemoteAddr,
 		)
 
 		chain := models.Chain(chainParam)
 		if !isValidChain(chain) {
-			slog.Warn("invalid chain for export", "chain", chainParam)
+			slog.Warn("invalid chain for extend", "chain", chainParam)
 			writeError(w, http.StatusBadRequest, config.ErrorInvalidChain, "invalid chain: "+chainParam)
 			return
 		}
+		if req.ExtendTo <= 0 || req.ExtendTo > config.MaxAddressesPerChain {
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidExtendTarget,
+				fmt.Sprintf("extendTo must be 1 to %d", config.MaxAddressesPerChain))
+			return
+		}
+		if keyService == nil {
+			writeError(w, http.StatusBadRequest, config.ErrorMnemonicUnavailable,
+				"extending addresses needs the seed; in watch-only mode use hdpay init --extend-to --xpub")
+			return
+		}
+		if err := keyService.CheckMnemonicAvailable(); err != nil {
+			slog.Warn("mnemonic not accessible for address extension", "error", err)
+			writeError(w, http.StatusBadRequest, config.ErrorMnemonicUnavailable, err.Error())
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		maxIndex, err := database.MaxAddressIndex(chain)
+		if err != nil {
+			slog.Error("failed to read max address index", "chain", chain, "error", err)
+			writeError(w, http.StatusInternalServerError, config.ErrorDatabase, "failed to read stored addresses")
+			return
+		}
+		if added := req.ExtendTo - (maxIndex + 1); added > config.MaxAddressExtendPerRequest {
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidExtendTarget,
+				fmt.Sprintf("extendTo would add %d addresses, at most %d per request (use hdpay init --extend-to for more)",
+					added, config.MaxAddressExtendPerRequest))
+			return
+		}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.Header().Set("Content-Disposition", "attachment; filename="+string(chain)+"-addresses.json")
+		var result *hd.ExtendResult
+		err = keyService.WithIndexDeriver(chain, func(derive hd.IndexDeriver) error {
+			var err error
+			result, err = hd.ExtendAddresses(database, chain, derive, req.ExtendTo, nil)
+			return err
+		})
+		if errors.Is(err, config.ErrKeyMismatch) {
+			slog.Error("refusing to extend address set", "chain", chain, "error", err)
+			writeError(w, http.StatusConflict, config.ErrorKeyMismatch, err.Error())
+			return
+		}
+		if err != nil {
+			slog.Error("address extension failed", "chain", chain, "error", err)
+			writeError(w, http.StatusInternalServerError, config.ErrorAddressGeneration, "failed to extend addresses")
+			return
+		}
 
-		// Stream addresses as JSON array
-		first := true
+		elapsed := time.Since(start).Milliseconds()
+		slog.Info("addresses extended",
+			"chain", chain,
+			"from", result.From,
+			"to", result.To,
+			"added", result.Added,
+			"elapsed_ms", elapsed,
+		)
 
-		w.Write([]byte("["))
+		writeJSON(w, http.StatusOK, models.APIResponse{
+			Data: result,
+			Meta: &models.APIMeta{ExecutionTime: elapsed},
+		})
+	}
+}
 
-		err := database.StreamAddresses(chain, func(addr models.Address) error {
-			if !first {
-				w.Write([]byte(","))
-			}
-			first = false
-			item := models.AddressExportItem{
-				Index:   addr.AddressIndex,
-				Address: addr.Address,
+// ExportAddresses handles GET /api/addresses/{chain}/export
+//
+// Query params:
+//   - format: json (default, a JSON array of items), csv, ndjson or descriptor
+//     (BTC only: importdescriptors JSON; needs keyService, so not in watch-only mode)
+//   - balances=true: add stored balances (raw smallest units) and last scan time
+func ExportAddresses(database *db.DB, keyService *tx.KeyService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		chainParam := strings.ToUpper(chi.URLParam(r, "chain"))
+		format := models.ExportFormat(strings.ToLower(r.URL.Query().Get("format")))
+		if format == "" {
+			format = models.ExportFormatJSON
+		}
+		balances := r.URL.Query().Get("balances") == "true"
+
+		slog.Info("export addresses requested",
+			"chain", chainParam,
+			"format", format,
+			"balances", balances,
+			"remoteAddr", r.RemoteAddr,
+		)
+
+		chain := models.Chain(chainParam)
+		if !isValidChain(chain) {
+			slog.Warn("invalid chain for export", "chain", chainParam)
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidChain, "invalid chain: "+chainParam)
+			return
+		}
+
+		switch format {
+		case models.ExportFormatJSON:
+			exportJSONArray(w, database, chain, balances)
+		case models.ExportFormatCSV, models.ExportFormatNDJSON:
+			contentType := "text/csv"
+			if format == models.ExportFormatNDJSON {
+				contentType = "application/x-ndjson"
 			}
-			data, err := json.Marshal(item)
+			w.Header().Set("Content-Type", contentType)
+			w.Header().Set("Content-Disposition", "attachment; filename="+string(chain)+"-addresses."+string(format))
+
+			exported, err := hd.WriteAddressExport(w, database, chain, hd.ExportOptions{Format: format, Balances: balances})
 			if err != nil {
-				return err
+				// Can't change status code mid-stream, just log
+				slog.Error("export stream error", "chain", chain, "format", format, "error", err)
+				return
 			}
-			_, err = w.Write(data)
-			return err
-		})
+			slog.Info("address export complete", "chain", chain, "format", format, "exported", exported)
+		case models.ExportFormatDescriptor:
+			exportDescriptor(w, database, keyService, chain)
+		default:
+			slog.Warn("invalid export format", "format", format)
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidExportFormat,
+				"invalid format: "+string(format)+", must be json, csv, ndjson or descriptor")
+		}
+	}
+}
+
+// exportJSONArray streams the addresses of chain as a JSON array of AddressExportItem.
+func exportJSONArray(w http.ResponseWriter, database *db.DB, chain models.Chain, balances bool) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", "attachment; filename="+string(chain)+"-addresses.json")
 
+	// Stream addresses as JSON array
+	first := true
+
+	w.Write([]byte("["))
+
+	err := hd.StreamExportItems(database, chain, balances, func(item models.AddressExportItem) error {
+		if !first {
+			w.Write([]byte(","))
+		}
+		first = false
+		data, err := json.Marshal(item)
 		if err != nil {
-			slog.Error("export stream error",
-				"chain", chain,
-				"error", err,
-			)
-			// Can't change status code mid-stream, just log
-			return
+			return err
 		}
+		_, err = w.Write(data)
+		return err
+	})
+
+	if err != nil {
+		slog.Error("export stream error",
+			"chain", chain,
+			"error", err,
+		)
+		// Can't change status code mid-stream, just log
+		return
+	}
+
+	w.Write([]byte("]"))
+
+	slog.Info("address export complete", "chain", chain)
+}
+
+// exportDescriptor writes the BTC account descriptor in Bitcoin Core importdescriptors
+// format, covering every stored address index.
+func exportDescriptor(w http.ResponseWriter, database *db.DB, keyService *tx.KeyService, chain models.Chain) {
+	if chain != models.ChainBTC {
+		writeError(w, http.StatusBadRequest, config.ErrorInvalidExportFormat, "descriptor export is only available for BTC")
+		return
+	}
+	if keyService == nil {
+		writeError(w, http.StatusBadRequest, config.ErrorDescriptorUnavailable,
+			"descriptor export needs the seed; in watch-only mode use hdpay export --format descriptor --xpub")
+		return
+	}
+	if err := keyService.CheckMnemonicAvailable(); err != nil {
+		slog.Warn("mnemonic not accessible for descriptor export", "error", err)
+		writeError(w, http.StatusBadRequest, config.ErrorMnemonicUnavailable, err.Error())
+		return
+	}
+
+	desc, err := keyService.BTCDescriptor()
+	if err != nil {
+		slog.Error("failed to build BTC descriptor", "error", err)
+		writeError(w, http.StatusInternalServerError, config.ErrorExportFailed, "failed to build descriptor")
+		return
+	}
 
-		w.Write([]byte("]"))
+	count, err := database.CountAddresses(chain)
+	if err != nil {
+		slog.Error("failed to count addresses for descriptor", "error", err)
+		writeError(w, http.StatusInternalServerError, config.ErrorDatabase, "failed to count addresses")
+		return
+	}
+	createdAt, err := database.FirstAddressCreatedAt(chain)
+	if err != nil {
+		slog.Error("failed to read address creation time", "error", err)
+		writeError(w, http.StatusInternalServerError, config.ErrorDatabase, "failed to read address creation time")
+		return
+	}
 
-		slog.Info("address export complete", "chain", chain)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", "attachment; filename=BTC-descriptor.json")
+	if err := hd.WriteDescriptorExport(w, hd.NewImportDescriptor(desc, count, createdAt)); err != nil {
+		slog.Error("descriptor export write error", "error", err)
+		return
 	}
+
+	slog.Info("descriptor export complete", "chain", chain, "count", count)
 }
 
 // isValidChain checks if the chain is one of the supported chains.
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/wallet/db"
+	"github.com/go-chi/chi/v5"
+)
+
+// addressMetadataRequest is the JSON body for PUT /api/addresses/{chain}/{index}.
+type addressMetadataRequest struct {
+	Label      string          `json:"label"`
+	Tags       []string        `json:"tags"`
+	ExternalID string          `json:"externalId"`
+	Metadata   json.RawMessage `json:"metadata"`
+}
+
+// addressMetadataDeleted is the response of DELETE /api/addresses/{chain}/{index}.
+type addressMetadataDeleted struct {
+	Chain        models.Chain `json:"chain"`
+	AddressIndex int          `json:"addressIndex"`
+	Deleted      bool         `json:"deleted"`
+}
+
+// GetAddressMetadata handles GET /api/addresses/{chain}/{index}.
+// Returns the address with its label, tags, external ID and free-form metadata;
+// an address without metadata has them empty.
+func GetAddressMetadata(database *db.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		addr, ok := resolveStoredAddress(w, r, database)
+		if !ok {
+			return
+		}
+
+		meta, err := database.GetAddressMetadata(addr.Chain, addr.AddressIndex)
+		if errors.Is(err, sql.ErrNoRows) {
+			meta = &models.AddressMetadata{Chain: addr.Chain, AddressIndex: addr.AddressIndex, Tags: []string{}}
+		} else if err != nil {
+			slog.Error("failed to read address metadata", "chain", addr.Chain, "index", addr.AddressIndex, "error", err)
+			writeError(w, http.StatusInternalServerError, config.ErrorDatabase, "failed to read address metadata")
+			return
+		}
+		meta.Address = addr.Address
+
+		writeJSON(w, http.StatusOK, models.APIResponse{
+			Data: meta,
+			Meta: &models.APIMeta{ExecutionTime: time.Since(start).Milliseconds()},
+		})
+	}
+}
+
+// PutAddressMetadata handles PUT /api/addresses/{chain}/{index}.
+// Replaces the label, tags, external ID and free-form metadata (a JSON object) of a
+// stored address. Fields left out of the body are cleared.
+func PutAddressMetadata(database *db.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		addr, ok := resolveStoredAddress(w, r, database)
+		if !ok {
+			return
+		}
+
+		var req addressMetadataRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			slog.Warn("invalid address metadata request body", "error", err, "remoteAddr", r.RemoteAddr)
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidAddressMetadata, "invalid request body")
+			return
+		}
+
+		meta, err := normalizeAddressMetadata(req)
+		if err != nil {
+			slog.Warn("rejected address metadata", "chain", addr.Chain, "index", addr.AddressIndex, "error", err)
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidAddressMetadata, err.Error())
+			return
+		}
+		meta.Chain = addr.Chain
+		meta.AddressIndex = addr.AddressIndex
+
+		if err := database.UpsertAddressMetadata(meta); err != nil {
+			slog.Error("failed to store address metadata", "chain", addr.Chain, "index", addr.AddressIndex, "error", err)
+			writeError(w, http.StatusInternalServerError, config.ErrorDatabase, "failed to store address metadata")
+			return
+		}
+
+		stored, err := database.GetAddressMetadata(addr.Chain, addr.AddressIndex)
+		if err != nil {
+			slog.Error("failed to read back address metadata", "chain", addr.Chain, "index", addr.AddressIndex, "error", err)
+			writeError(w, http.StatusInternalServerError, config.ErrorDatabase, "failed to read address metadata")
+			return
+		}
+		stored.Address = addr.Address
+
+		slog.Info("address metadata updated",
+			"chain", addr.Chain,
+			"index", addr.AddressIndex,
+			"tags", len(stored.Tags),
+			"hasExternalId", stored.ExternalID != "",
+		)
+
+		writeJSON(w, http.StatusOK, models.APIResponse{
+			Data: stored,
+			Meta: &models.APIMeta{ExecutionTime: time.Since(start).Milliseconds()},
+		})
+	}
+}
+
+// DeleteAddressMetadata handles DELETE /api/addresses/{chain}/{index}.
+// Removes the metadata of a stored address; the address itself is kept.
+func DeleteAddressMetadata(database *db.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		addr, ok := resolveStoredAddress(w, r, database)
+		if !ok {
+			return
+		}
+
+		deleted, err := database.DeleteAddressMetadata(addr.Chain, addr.AddressIndex)
+		if err != nil {
+			slog.Error("failed to delete address metadata", "chain", addr.Chain, "index", addr.AddressIndex, "error", err)
+			writeError(w, http.StatusInternalServerError, config.ErrorDatabase, "failed to delete address metadata")
+			return
+		}
+
+		slog.Info("address metadata deleted", "chain", addr.Chain, "index", addr.AddressIndex, "deleted", deleted)
+
+		writeJSON(w, http.StatusOK, models.APIResponse{
+			Data: addressMetadataDeleted{Chain: addr.Chain, AddressIndex: addr.AddressIndex, Deleted: deleted},
+			Meta: &models.APIMeta{ExecutionTime: time.Since(start).Milliseconds()},
+		})
+	}
+}
+
+// resolveStoredAddress parses the {chain} and {index} URL params and loads the
+// stored address. On failure it writes the error response and returns false.
+func resolveStoredAddress(w http.ResponseWriter, r *http.Request, database *db.DB) (*models.Address, bool) {
+	chainParam := strings.ToUpper(chi.URLParam(r, "chain"))
+	indexParam := chi.URLParam(r, "index")
+
+	slog.Info("address metadata requested",
+		"method", r.Method,
+		"chain", chainParam,
+		"index", indexParam,
+		"remoteAddr", r.RemoteAddr,
+	)
+
+	chain := models.Chain(chainParam)
+	if !isValidChain(chain) {
+		slog.Warn("invalid chain parameter", "chain", chainParam)
+		writeError(w, http.StatusBadRequest, config.ErrorInvalidChain, "invalid chain: "+chainParam+", must be BTC, BSC, or SOL")
+		return nil, false
+	}
+
+	index, err := strconv.Atoi(indexParam)
+	if err != nil || index < 0 {
+		writeError(w, http.StatusBadRequest, config.ErrorInvalidAddressIndex, "invalid address index: "+indexParam)
+		return nil, false
+	}
+
+	addr, err := database.GetAddressByIndex(chain, index)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, config.ErrorAddressNotFound,
+			fmt.Sprintf("no %s address stored at index %d", chain, index))
+		return nil, false
+	}
+	if err != nil {
+		slog.Error("failed to read address", "chain", chain, "index", index, "error", err)
+		writeError(w, http.StatusInternalServerError, config.ErrorDatabase, "failed to read address")
+		return nil, false
+	}
+	return addr, true
+}
+
+// normalizeAddressMetadata trims and validates a metadata request. Tags are
+// de-duplicated in order and may not contain ';' or ',' (the CSV export separators);
+// metadata must be a JSON object and is stored compacted.
+func normalizeAddressMetadata(req addressMetadataRequest) (models.AddressMetadata, error) {
+	meta := models.AddressMetadata{
+		Label:      strings.TrimSpace(req.Label),
+		ExternalID: strings.TrimSpace(req.ExternalID),
+		Tags:       []string{},
+	}
+
+	if n := utf8.RuneCountInString(meta.Label); n > config.MaxAddressLabelLength {
+		return meta, fmt.Errorf("%w: label is %d characters, at most %d", config.ErrInvalidAddressMetadata, n, config.MaxAddressLabelLength)
+	}
+	if n := utf8.RuneCountInString(meta.ExternalID); n > config.MaxAddressExternalIDLength {
+		return meta, fmt.Errorf("%w: externalId is %d characters, at most %d", config.ErrInvalidAddressMetadata, n, config.MaxAddressExternalIDLength)
+	}
+
+	seen := make(map[string]bool, len(req.Tags))
+	for _, tag := range req.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			return meta, fmt.Errorf("%w: tags may not be empty", config.ErrInvalidAddressMetadata)
+		}
+		if utf8.RuneCountInString(tag) > config.MaxAddressTagLength {
+			return meta, fmt.Errorf("%w: tag %q is longer than %d characters", config.ErrInvalidAddressMetadata, tag, config.MaxAddressTagLength)
+		}
+		if strings.ContainsAny(tag, ";,") {
+			return meta, fmt.Errorf("%w: tag %q may not contain ';' or ','", config.ErrInvalidAddressMetadata, tag)
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			meta.Tags = append(meta.Tags, tag)
+		}
+	}
+	if len(meta.Tags) > config.MaxAddressTags {
+		return meta, fmt.Errorf("%w: %d tags, at most %d", config.ErrInvalidAddressMetadata, len(meta.Tags), config.MaxAddressTags)
+	}
+
+	raw := bytes.TrimSpace(req.Metadata)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return meta, nil
+	}
+	if raw[0] != '{' {
+		return meta, fmt.Errorf("%w: metadata must be a JSON object", config.ErrInvalidAddressMetadata)
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, raw); err != nil {
+		return meta, fmt.Errorf("%w: metadata: %v", config.ErrInvalidAddressMetadata, err)
+	}
+	if compact.Len() > config.MaxAddressMetadataBytes {
+		return meta, fmt.Errorf("%w: metadata is %d bytes, at most %d", config.ErrInvalidAddressMetadata, compact.Len(), config.MaxAddressMetadataBytes)
+	}
+	meta.Metadata = compact.Bytes()
+	return meta, nil
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/go-chi/chi/v5"
+)
+
+func setupMetadataRouter(t *testing.T) http.Handler {
+	t.Helper()
+	database := setupTestDB(t)
+	r := chi.NewRouter()
+	r.Get("/api/addresses/{chain}", ListAddresses(database))
+	r.Get("/api/addresses/{chain}/{index}", GetAddressMetadata(database))
+	r.Put("/api/addresses/{chain}/{index}", PutAddressMetadata(database))
+	r.Delete("/api/addresses/{chain}/{index}", DeleteAddressMetadata(database))
+	return r
+}
+
+func decodeAddressMetadata(t *testing.T, body []byte) models.AddressMetadata {
+	t.Helper()
+	var resp struct {
+		Data models.AddressMetadata `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("unmarshal error: %v\nbody: %s", err, body)
+	}
+	return resp.Data
+}
+
+func TestAddressMetadata_PutGetDelete(t *testing.T) {
+	router := setupMetadataRouter(t)
+
+	body := `{"label":"  Acme Inc. ","tags":["customer","vip","customer"],"externalId":"INV-1042","metadata":{ "plan": "gold" }}`
+	req := httptest.NewRequest("PUT", "/api/addresses/btc/2", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("PUT status = %d, want 200\nbody: %s", w.Code, w.Body.String())
+	}
+	put := decodeAddressMetadata(t, w.Body.Bytes())
+	if put.Label != "Acme Inc." || len(put.Tags) != 2 || put.Address != "bc1qtestc" || string(put.Metadata) != `{"plan":"gold"}` {
+		t.Errorf("PUT data = %+v, want trimmed label, de-duplicated tags and compacted metadata", put)
+	}
+
+	req = httptest.NewRequest("GET", "/api/addresses/BTC/2", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET status = %d, want 200", w.Code)
+	}
+	if got := decodeAddressMetadata(t, w.Body.Bytes()); got.ExternalID != "INV-1042" {
+		t.Errorf("GET data = %+v", got)
+	}
+
+	req = httptest.NewRequest("GET", "/api/addresses/BTC?tag=vip", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var list struct {
+		Data []models.AddressWithBalance `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	if len(list.Data) != 1 || list.Data[0].AddressIndex != 2 || list.Data[0].Label != "Acme Inc." {
+		t.Errorf("tag-filtered list = %+v, want index 2", list.Data)
+	}
+
+	req = httptest.NewRequest("DELETE", "/api/addresses/BTC/2", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"deleted":true`) {
+		t.Fatalf("DELETE status = %d body = %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/api/addresses/BTC/2", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if got := decodeAddressMetadata(t, w.Body.Bytes()); got.Label != "" || got.Address != "bc1qtestc" {
+		t.Errorf("GET after delete = %+v, want empty metadata", got)
+	}
+}
+
+func TestAddressMetadata_Errors(t *testing.T) {
+	router := setupMetadataRouter(t)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		code   string
+	}{
+		{"unknown address", "GET", "/api/addresses/BTC/99", "", http.StatusNotFound, config.ErrorAddressNotFound},
+		{"bad index", "GET", "/api/addresses/BTC/-1", "", http.StatusBadRequest, config.ErrorInvalidAddressIndex},
+		{"bad chain", "PUT", "/api/addresses/ETH/1", `{}`, http.StatusBadRequest, config.ErrorInvalidChain},
+		{"metadata not an object", "PUT", "/api/addresses/BTC/1", `{"metadata":[1,2]}`, http.StatusBadRequest, config.ErrorInvalidAddressMetadata},
+		{"tag with separator", "PUT", "/api/addresses/BTC/1", `{"tags":["a;b"]}`, http.StatusBadRequest, config.ErrorInvalidAddressMetadata},
+		{"empty tag", "PUT", "/api/addresses/BTC/1", `{"tags":[" "]}`, http.StatusBadRequest, config.ErrorInvalidAddressMetadata},
+		{"label too long", "PUT", "/api/addresses/BTC/1", `{"label":"` + strings.Repeat("x", config.MaxAddressLabelLength+1) + `"}`, http.StatusBadRequest, config.ErrorInvalidAddressMetadata},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d\nbody: %s", w.Code, tt.status, w.Body.String())
+			}
+			assertErrorCode(t, w.Body.Bytes(), tt.code)
+		})
+	}
+}
@@ -4,11 +4,14 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/Fantasim/hdpay/internal/wallet/db"
 	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/wallet/tx"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -45,7 +48,7 @@ func setupTestDB(t *testing.T) *db.DB {
 func setupRouter(database *db.DB) http.Handler {
 	r := chi.NewRouter()
 	r.Get("/api/addresses/{chain}", ListAddresses(database))
-	r.Get("/api/addresses/{chain}/export", ExportAddresses(database))
+	r.Get("/api/addresses/{chain}/export", ExportAddresses(database, nil))
 	return r
 }
 
@@ -193,3 +196,143 @@ func TestExportAddresses_InvalidChain(t *testing.T) {
 		t.Fatalf("status = %d, want 400", w.Code)
 	}
 }
+
+func TestExportAddresses_CSVWithBalances(t *testing.T) {
+	database := setupTestDB(t)
+	router := setupRouter(database)
+
+	if err := database.UpsertBalance(models.ChainBTC, 3, models.TokenNative, "1500"); err != nil {
+		t.Fatalf("UpsertBalance() error = %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/api/addresses/BTC/export?format=csv&balances=true", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/csv" {
+		t.Errorf("content-type = %q, want text/csv", ct)
+	}
+	if d := w.Header().Get("Content-Disposition"); d != "attachment; filename=BTC-addresses.csv" {
+		t.Errorf("content-disposition = %q", d)
+	}
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 26 {
+		t.Fatalf("got %d lines, want header + 25", len(lines))
+	}
+	if lines[0] != "index,address,native,last_scanned" {
+		t.Errorf("header = %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[4], "3,bc1qtestd,1500,") {
+		t.Errorf("row 3 = %q, want balance 1500", lines[4])
+	}
+}
+
+func TestExportAddresses_InvalidFormat(t *testing.T) {
+	database := setupTestDB(t)
+	router := setupRouter(database)
+
+	req := httptest.NewRequest("GET", "/api/addresses/BTC/export?format=xml", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "ERROR_INVALID_EXPORT_FORMAT") {
+		t.Errorf("body = %s, want ERROR_INVALID_EXPORT_FORMAT", w.Body.String())
+	}
+}
+
+func TestExportAddresses_DescriptorWithoutKeys(t *testing.T) {
+	database := setupTestDB(t)
+	router := setupRouter(database)
+
+	req := httptest.NewRequest("GET", "/api/addresses/BTC/export?format=descriptor", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "ERROR_DESCRIPTOR_UNAVAILABLE") {
+		t.Errorf("body = %s, want ERROR_DESCRIPTOR_UNAVAILABLE", w.Body.String())
+	}
+}
+
+// newTestKeyService returns a testnet KeyService over the BIP-39 "abandon ... about" mnemonic.
+func newTestKeyService(t *testing.T) *tx.KeyService {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "mnemonic.txt")
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	if err := os.WriteFile(path, []byte(mnemonic), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return tx.NewKeyService(path, "testnet")
+}
+
+func postExtend(t *testing.T, handler http.HandlerFunc, chain, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	r := chi.NewRouter()
+	r.Post("/api/addresses/{chain}/extend", handler)
+	req := httptest.NewRequest("POST", "/api/addresses/"+chain+"/extend", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestExtendAddresses_AppendsFromSeed(t *testing.T) {
+	database := setupTestDB(t)
+	handler := ExtendAddresses(database, newTestKeyService(t))
+
+	w := postExtend(t, handler, "SOL", `{"extendTo": 12}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if got, _ := database.CountAddresses(models.ChainSOL); got != 12 {
+		t.Fatalf("SOL addresses = %d, want 12", got)
+	}
+
+	w = postExtend(t, handler, "SOL", `{"extendTo": 20}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Data struct {
+			From  int `json:"from"`
+			Added int `json:"added"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Data.From != 12 || resp.Data.Added != 8 {
+		t.Errorf("second extend = %+v, want 8 added from index 12", resp.Data)
+	}
+}
+
+func TestExtendAddresses_Rejects(t *testing.T) {
+	database := setupTestDB(t)
+
+	// The seeded BTC addresses are placeholders, not derived from the seed.
+	w := postExtend(t, ExtendAddresses(database, newTestKeyService(t)), "BTC", `{"extendTo": 30}`)
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "ERROR_KEY_MISMATCH") {
+		t.Errorf("mismatched set: status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if got, _ := database.CountAddresses(models.ChainBTC); got != 25 {
+		t.Errorf("BTC addresses = %d, want 25 (untouched)", got)
+	}
+
+	w = postExtend(t, ExtendAddresses(database, nil), "SOL", `{"extendTo": 10}`)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "ERROR_MNEMONIC_UNAVAILABLE") {
+		t.Errorf("watch-only: status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	w = postExtend(t, ExtendAddresses(database, newTestKeyService(t)), "SOL", `{"extendTo": 0}`)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "ERROR_INVALID_EXTEND_TARGET") {
+		t.Errorf("zero target: status = %d, body = %s", w.Code, w.Body.String())
+	}
+}
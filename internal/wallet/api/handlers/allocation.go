@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/wallet/db"
+	"github.com/go-chi/chi/v5"
+)
+
+// IdempotencyKeyHeader carries the client-chosen key that makes an allocation
+// request safe to retry.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// allocateAddressRequest is the JSON body for POST /api/addresses/{chain}/allocate.
+type allocateAddressRequest struct {
+	AllocatedTo string `json:"allocatedTo"` // Customer, invoice or order the address is for
+}
+
+// AllocateAddress handles POST /api/addresses/{chain}/allocate.
+// Reserves the next never-allocated address that holds no balance and has no
+// recorded transaction, and records who it was allocated to. Responds 201 with the
+// allocation, or 200 with the original allocation when the Idempotency-Key header
+// repeats an earlier request. When every stored address is taken it responds 409;
+// extend the address set and retry.
+func AllocateAddress(database *db.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		chainParam := strings.ToUpper(chi.URLParam(r, "chain"))
+		idempotencyKey := strings.TrimSpace(r.Header.Get(IdempotencyKeyHeader))
+
+		slog.Info("address allocation requested",
+			"chain", chainParam,
+			"idempotencyKey", idempotencyKey,
+			"remoteAddr", r.RemoteAddr,
+		)
+
+		chain := models.Chain(chainParam)
+		if !isValidChain(chain) {
+			slog.Warn("invalid chain for allocation", "chain", chainParam)
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidChain, "invalid chain: "+chainParam)
+			return
+		}
+
+		var req allocateAddressRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			slog.Warn("invalid allocation request body", "error", err, "remoteAddr", r.RemoteAddr)
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidAllocation, "invalid request body")
+			return
+		}
+		req.AllocatedTo = strings.TrimSpace(req.AllocatedTo)
+		if req.AllocatedTo == "" || utf8.RuneCountInString(req.AllocatedTo) > config.MaxAllocatedToLength {
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidAllocation,
+				fmt.Sprintf("allocatedTo is required and at most %d characters", config.MaxAllocatedToLength))
+			return
+		}
+		if len(idempotencyKey) > config.MaxIdempotencyKeyLength {
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidAllocation,
+				fmt.Sprintf("%s is at most %d bytes", IdempotencyKeyHeader, config.MaxIdempotencyKeyLength))
+			return
+		}
+
+		alloc, created, err := database.AllocateAddress(chain, req.AllocatedTo, idempotencyKey)
+		if errors.Is(err, config.ErrIdempotencyConflict) {
+			slog.Warn("idempotency key reused for a different allocation", "chain", chain, "error", err)
+			writeError(w, http.StatusConflict, config.ErrorIdempotencyConflict, err.Error())
+			return
+		}
+		if errors.Is(err, config.ErrNoAddressAvailable) {
+			slog.Warn("no address left to allocate", "chain", chain)
+			writeError(w, http.StatusConflict, config.ErrorNoAddressAvailable,
+				"no unallocated "+string(chain)+" address left; extend the address set (POST /api/addresses/"+string(chain)+"/extend) and retry")
+			return
+		}
+		if err != nil {
+			slog.Error("address allocation failed", "chain", chain, "error", err)
+			writeError(w, http.StatusInternalServerError, config.ErrorDatabase, "failed to allocate address")
+			return
+		}
+
+		status := http.StatusOK
+		if created {
+			status = http.StatusCreated
+		}
+
+		elapsed := time.Since(start).Milliseconds()
+		slog.Info("address allocation complete",
+			"chain", chain,
+			"index", alloc.AddressIndex,
+			"created", created,
+			"elapsed_ms", elapsed,
+		)
+
+		writeJSON(w, status, models.APIResponse{
+			Data: alloc,
+			Meta: &models.APIMeta{ExecutionTime: elapsed},
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/go-chi/chi/v5"
+)
+
+func setupAllocationRouter(t *testing.T) http.Handler {
+	t.Helper()
+	database := setupTestDB(t)
+	r := chi.NewRouter()
+	r.Get("/api/addresses/{chain}", ListAddresses(database))
+	r.Post("/api/addresses/{chain}/allocate", AllocateAddress(database))
+	return r
+}
+
+func postAllocate(router http.Handler, chain, body, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/api/addresses/"+chain+"/allocate", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func decodeAllocation(t *testing.T, body []byte) models.AddressAllocation {
+	t.Helper()
+	var resp struct {
+		Data models.AddressAllocation `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("unmarshal error: %v\nbody: %s", err, body)
+	}
+	return resp.Data
+}
+
+func TestAllocateAddress_Handler(t *testing.T) {
+	router := setupAllocationRouter(t)
+
+	w := postAllocate(router, "btc", `{"allocatedTo":"customer-1"}`, "order-1")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201\nbody: %s", w.Code, w.Body.String())
+	}
+	first := decodeAllocation(t, w.Body.Bytes())
+	if first.AddressIndex != 0 || first.Address != "bc1qtesta" {
+		t.Errorf("allocation = %+v, want index 0", first)
+	}
+
+	// A retried request returns the same address with 200.
+	w = postAllocate(router, "BTC", `{"allocatedTo":"customer-1"}`, "order-1")
+	if w.Code != http.StatusOK {
+		t.Fatalf("replay status = %d, want 200", w.Code)
+	}
+	if again := decodeAllocation(t, w.Body.Bytes()); again.AddressIndex != 0 {
+		t.Errorf("replay index = %d, want 0", again.AddressIndex)
+	}
+
+	w = postAllocate(router, "BTC", `{"allocatedTo":"customer-2"}`, "")
+	if next := decodeAllocation(t, w.Body.Bytes()); w.Code != http.StatusCreated || next.AddressIndex != 1 {
+		t.Errorf("next allocation = %d %+v, want 201 index 1", w.Code, next)
+	}
+
+	req := httptest.NewRequest("GET", "/api/addresses/BTC?allocated=false&pageSize=100", nil)
+	lw := httptest.NewRecorder()
+	router.ServeHTTP(lw, req)
+	var resp models.APIResponse
+	if err := json.Unmarshal(lw.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	if resp.Meta.Total != 23 {
+		t.Errorf("unallocated total = %d, want 23", resp.Meta.Total)
+	}
+}
+
+func TestAllocateAddress_HandlerErrors(t *testing.T) {
+	router := setupAllocationRouter(t)
+
+	if w := postAllocate(router, "BTC", `{"allocatedTo":"a"}`, "k"); w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201", w.Code)
+	}
+
+	tests := []struct {
+		name   string
+		chain  string
+		body   string
+		key    string
+		status int
+		code   string
+	}{
+		{"invalid chain", "ETH", `{"allocatedTo":"a"}`, "", http.StatusBadRequest, config.ErrorInvalidChain},
+		{"missing allocatedTo", "BTC", `{}`, "", http.StatusBadRequest, config.ErrorInvalidAllocation},
+		{"invalid body", "BTC", `not json`, "", http.StatusBadRequest, config.ErrorInvalidAllocation},
+		{"key reused", "BTC", `{"allocatedTo":"b"}`, "k", http.StatusConflict, config.ErrorIdempotencyConflict},
+		{"no addresses", "SOL", `{"allocatedTo":"a"}`, "", http.StatusConflict, config.ErrorNoAddressAvailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := postAllocate(router, tt.chain, tt.body, tt.key)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d\nbody: %s", w.Code, tt.status, w.Body.String())
+			}
+			assertErrorCode(t, w.Body.Bytes(), tt.code)
+		})
+	}
+}
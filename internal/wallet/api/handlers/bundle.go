@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/wallet/tx"
+)
+
+// ExportBundle handles POST /api/send/bundle/export.
+// Builds an unsigned sweep bundle for a BSC or SOL sweep — per-address nonce,
+// gas price and calldata for BSC, durable-nonce messages for SOL — to be signed
+// offline with hdpay sign-bundle. No key is touched. BTC uses the preview PSBT.
+func ExportBundle(deps *SendDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		var req models.SendRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			slog.Warn("invalid bundle export request body", "error", err)
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidAddress, "invalid request body")
+			return
+		}
+
+		req.Chain = models.Chain(strings.ToUpper(string(req.Chain)))
+		req.Token = models.Token(strings.ToUpper(string(req.Token)))
+
+		slog.Info("bundle export requested",
+			"chain", req.Chain,
+			"token", req.Token,
+			"destination", req.Destination,
+			"feePayerIndex", req.FeePayerIndex,
+		)
+
+		if req.Chain == models.ChainBTC {
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidChain,
+				"BTC consolidations are signed offline through the PSBT returned by the preview")
+			return
+		}
+		if !isValidChain(req.Chain) {
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidChain, "invalid chain")
+			return
+		}
+		if !isValidToken(deps.DB, req.Chain, req.Token) {
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidToken, "invalid token for chain")
+			return
+		}
+		if err := validateDestination(req.Chain, req.Destination, deps.NetParams); err != nil {
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidDestination, err.Error())
+			return
+		}
+
+		funded, err := deps.DB.GetFundedAddressesJoined(req.Chain, req.Token)
+		if err != nil {
+			slog.Error("failed to fetch funded addresses for bundle export", "error", err)
+			writeError(w, http.StatusInternalServerError, config.ErrorDatabase, "failed to fetch funded addresses")
+			return
+		}
+		if len(funded) == 0 {
+			writeError(w, http.StatusBadRequest, config.ErrorNoFundedAddresses, "no funded addresses found")
+			return
+		}
+
+		bundle, err := exportBundle(r.Context(), deps, req, funded)
+		if err != nil {
+			writeBundleError(w, err)
+			return
+		}
+
+		elapsed := time.Since(start).Milliseconds()
+		slog.Info("bundle exported",
+			"chain", req.Chain,
+			"token", req.Token,
+			"txCount", len(bundle.Transactions),
+			"elapsed_ms", elapsed,
+		)
+
+		writeJSON(w, http.StatusOK, models.APIResponse{
+			Data: bundle,
+			Meta: &models.APIMeta{ExecutionTime: elapsed},
+		})
+	}
+}
+
+// exportBundle dispatches to the chain-specific bundle export.
+func exportBundle(ctx context.Context, deps *SendDeps, req models.SendRequest, funded []models.AddressWithBalance) (*models.SweepBundle, error) {
+	contract := getTokenContractAddress(deps.DB, req.Chain, req.Token)
+
+	switch req.Chain {
+	case models.ChainBSC:
+		if req.Token == models.TokenNative {
+			return deps.BSCService.ExportNativeBundle(ctx, funded, req.Destination)
+		}
+		return deps.BSCService.ExportTokenBundle(ctx, funded, req.Destination, req.Token, contract)
+
+	case models.ChainSOL:
+		if req.Token == models.TokenNative {
+			return deps.SOLService.ExportNativeBundle(ctx, funded, req.Destination)
+		}
+		return deps.SOLService.ExportTokenBundle(ctx, funded, req.Destination, req.Token, contract, req.FeePayerIndex)
+
+	default:
+		return nil, fmt.Errorf("unsupported chain: %s", req.Chain)
+	}
+}
+
+// BroadcastBundle handles POST /api/send/bundle/broadcast.
+// Accepts a sweep bundle exported by POST /api/send/bundle/export and signed
+// offline. Every transaction is checked against the exported transfer before
+// anything is sent; the broadcast then runs in the background like
+// POST /api/send/execute, with tx_state tracking and tx_status / tx_complete
+// SSE events under the returned sweepID.
+func BroadcastBundle(deps *SendDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req models.BundleBroadcastRequest
+		r.Body = http.MaxBytesReader(w, r.Body, config.MaxSweepBundleBytes)
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			slog.Warn("invalid bundle broadcast request body", "error", err)
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidBundle, "invalid request body")
+			return
+		}
+
+		bundle := req.Bundle
+		if err := tx.CheckSweepBundle(bundle); err != nil {
+			writeBundleError(w, err)
+			return
+		}
+		if bundle.Network != deps.Config.Network {
+			writeBundleError(w, fmt.Errorf("%w: bundle is for %s, server runs on %s",
+				config.ErrInvalidBundle, bundle.Network, deps.Config.Network))
+			return
+		}
+		if bundle.Token != models.TokenNative &&
+			!strings.EqualFold(bundle.Contract, getTokenContractAddress(deps.DB, bundle.Chain, bundle.Token)) {
+			writeBundleError(w, fmt.Errorf("%w: %s is not the %s %s contract",
+				config.ErrInvalidBundle, bundle.Contract, bundle.Chain, bundle.Token))
+			return
+		}
+
+		var err error
+		switch bundle.Chain {
+		case models.ChainBSC:
+			err = deps.BSCService.ValidateBundle(bundle)
+		case models.ChainSOL:
+			err = deps.SOLService.ValidateBundle(bundle)
+		}
+		if err != nil {
+			writeBundleError(w, err)
+			return
+		}
+
+		// NOTE: Do NOT defer mu.Unlock() — the goroutine below unlocks it when done.
+		mu := deps.ChainLocks[bundle.Chain]
+		if mu == nil {
+			slog.Error("no chain lock configured", "chain", bundle.Chain)
+			writeError(w, http.StatusInternalServerError, config.ErrorTxBroadcastFailed, "internal configuration error")
+			return
+		}
+		if !mu.TryLock() {
+			slog.Warn("send already in progress for chain", "chain", bundle.Chain)
+			writeError(w, http.StatusConflict, config.ErrorSendBusy,
+				fmt.Sprintf("send operation already in progress for %s", bundle.Chain))
+			return
+		}
+
+		sweepID := tx.GenerateSweepID()
+
+		slog.Info("bundle broadcast accepted (async)",
+			"chain", bundle.Chain,
+			"token", bundle.Token,
+			"txCount", len(bundle.Transactions),
+			"destination", bundle.Destination,
+			"sweepID", sweepID,
+		)
+
+		writeJSON(w, http.StatusAccepted, models.APIResponse{
+			Data: models.SweepStarted{
+				SweepID:      sweepID,
+				Chain:        bundle.Chain,
+				Token:        bundle.Token,
+				AddressCount: len(bundle.Transactions),
+			},
+		})
+
+		go func() {
+			defer mu.Unlock()
+			defer func() {
+				if r := recover(); r != nil {
+					slog.Error("PANIC in background bundle goroutine — recovered",
+						"sweepID", sweepID,
+						"chain", bundle.Chain,
+						"panic", fmt.Sprintf("%v", r),
+					)
+				}
+			}()
+
+			start := time.Now()
+
+			result, err := broadcastBundleBg(context.Background(), deps, bundle, sweepID)
+			if err != nil {
+				slog.Error("background bundle broadcast failed",
+					"sweepID", sweepID,
+					"chain", bundle.Chain,
+					"error", err,
+					"duration", time.Since(start).Round(time.Millisecond),
+				)
+				broadcastSweepError(deps, bundle.Chain, err)
+				return
+			}
+
+			slog.Info("background bundle broadcast completed",
+				"sweepID", sweepID,
+				"chain", bundle.Chain,
+				"token", bundle.Token,
+				"successCount", result.SuccessCount,
+				"failCount", result.FailCount,
+				"totalSwept", result.TotalSwept,
+				"duration", time.Since(start).Round(time.Millisecond),
+			)
+			broadcastSweepComplete(deps, bundle.Chain, bundle.Token, result)
+		}()
+	}
+}
+
+// broadcastBundleBg dispatches a validated bundle to its chain service and
+// returns a unified result. Called from a background goroutine.
+func broadcastBundleBg(ctx context.Context, deps *SendDeps, bundle *models.SweepBundle, sweepID string) (*models.UnifiedSendResult, error) {
+	result := &models.UnifiedSendResult{
+		Chain: bundle.Chain,
+		Token: bundle.Token,
+	}
+
+	switch bundle.Chain {
+	case models.ChainBSC:
+		bscResult, err := deps.BSCService.BroadcastBundle(ctx, bundle, sweepID)
+		if err != nil {
+			return nil, fmt.Errorf("BSC bundle broadcast failed: %w", err)
+		}
+		for _, r := range bscResult.TxResults {
+			result.TxResults = append(result.TxResults, models.TxResult{
+				AddressIndex: r.AddressIndex,
+				FromAddress:  r.FromAddress,
+				TxHash:       r.TxHash,
+				Amount:       r.Amount,
+				Status:       r.Status,
+				Error:        r.Error,
+			})
+		}
+		result.SuccessCount = bscResult.SuccessCount
+		result.FailCount = bscResult.FailCount
+		result.TotalSwept = bscResult.TotalSwept
+
+	case models.ChainSOL:
+		solResult, err := deps.SOLService.BroadcastBundle(ctx, bundle, sweepID)
+		if err != nil {
+			return nil, fmt.Errorf("SOL bundle broadcast failed: %w", err)
+		}
+		for _, r := range solResult.TxResults {
+			result.TxResults = append(result.TxResults, models.TxResult{
+				AddressIndex: r.AddressIndex,
+				FromAddress:  r.FromAddress,
+				TxHash:       r.TxSignature,
+				Amount:       r.Amount,
+				Status:       r.Status,
+				Error:        r.Error,
+			})
+		}
+		result.SuccessCount = solResult.SuccessCount
+		result.FailCount = solResult.FailCount
+		result.TotalSwept = solResult.TotalSwept
+
+	default:
+		return nil, fmt.Errorf("unsupported chain: %s", bundle.Chain)
+	}
+
+	return result, nil
+}
+
+// writeBundleError maps a bundle export or validation failure to its API error.
+func writeBundleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, config.ErrBundleEmpty):
+		slog.Warn("bundle export found nothing to sweep", "error", err)
+		writeError(w, http.StatusBadRequest, config.ErrorBundleEmpty, err.Error())
+	case errors.Is(err, config.ErrSOLNonceAccount), errors.Is(err, config.ErrSOLNonceAccountsShort):
+		slog.Warn("SOL nonce accounts unusable", "error", err)
+		writeError(w, http.StatusBadRequest, config.ErrorSOLNonceAccount, err.Error())
+	case errors.Is(err, config.ErrBundleUnsigned):
+		slog.Warn("bundle not fully signed", "error", err)
+		writeError(w, http.StatusBadRequest, config.ErrorBundleUnsigned, err.Error())
+	case errors.Is(err, config.ErrBundleForeignAddress):
+		slog.Warn("bundle spends from a foreign address", "error", err)
+		writeError(w, http.StatusBadRequest, config.ErrorBundleForeignAddress, err.Error())
+	case errors.Is(err, config.ErrInvalidBundle), errors.Is(err, config.ErrKeyMismatch), errors.Is(err, config.ErrSOLTxTooLarge):
+		slog.Warn("bundle rejected", "error", err)
+		writeError(w, http.StatusBadRequest, config.ErrorInvalidBundle, err.Error())
+	default:
+		slog.Error("bundle operation failed", "error", err)
+		writeError(w, http.StatusInternalServerError, config.ErrorTxBroadcastFailed, err.Error())
+	}
+}
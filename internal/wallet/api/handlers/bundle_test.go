@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/wallet/tx"
+)
+
+// unsignedTestBundle returns a broadcast request body carrying a one-transaction
+// SOL bundle that was never signed.
+func unsignedTestBundle(t *testing.T, network string) string {
+	t.Helper()
+	body, err := json.Marshal(models.BundleBroadcastRequest{Bundle: &models.SweepBundle{
+		Version:     config.SweepBundleVersion,
+		Chain:       models.ChainSOL,
+		Token:       models.TokenNative,
+		Network:     network,
+		Destination: "5frqxtii9LeGq2bz3dSNokvZcEooF483MzeU24JrhcTA",
+		Transactions: []models.SweepBundleTx{{
+			AddressIndex:  0,
+			FromAddress:   "3Cy3YNTFywCmxoxt8n7UH6hg6dLo5uACowX3CFceaSnx",
+			Amount:        "1000",
+			SignerIndexes: []int{0},
+		}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func postBundle(t *testing.T, router http.Handler, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest("POST", path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestExportBundle_BTCRejected(t *testing.T) {
+	database := setupSendTestDB(t)
+	router := setupSendRouter(t, makeSendDeps(t, database))
+
+	body := `{"chain":"BTC","token":"NATIVE","destination":"tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx"}`
+	w := postBundle(t, router, "/api/send/bundle/export", body)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400. body: %s", w.Code, w.Body.String())
+	}
+	assertErrorCode(t, w.Body.Bytes(), config.ErrorInvalidChain)
+}
+
+func TestExportBundle_NoFundedAddresses(t *testing.T) {
+	database := setupSendTestDB(t)
+	router := setupSendRouter(t, makeSendDeps(t, database))
+
+	body := `{"chain":"SOL","token":"NATIVE","destination":"5frqxtii9LeGq2bz3dSNokvZcEooF483MzeU24JrhcTA"}`
+	w := postBundle(t, router, "/api/send/bundle/export", body)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400. body: %s", w.Code, w.Body.String())
+	}
+	assertErrorCode(t, w.Body.Bytes(), config.ErrorNoFundedAddresses)
+}
+
+func TestBroadcastBundle_InvalidBody(t *testing.T) {
+	database := setupSendTestDB(t)
+	router := setupSendRouter(t, makeSendDeps(t, database))
+
+	w := postBundle(t, router, "/api/send/bundle/broadcast", "not json")
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", w.Code)
+	}
+	assertErrorCode(t, w.Body.Bytes(), config.ErrorInvalidBundle)
+
+	w = postBundle(t, router, "/api/send/bundle/broadcast", `{"bundle":{"version":1,"chain":"BTC"}}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400. body: %s", w.Code, w.Body.String())
+	}
+	assertErrorCode(t, w.Body.Bytes(), config.ErrorInvalidBundle)
+}
+
+func TestBroadcastBundle_WrongNetwork(t *testing.T) {
+	database := setupSendTestDB(t)
+	router := setupSendRouter(t, makeSendDeps(t, database))
+
+	w := postBundle(t, router, "/api/send/bundle/broadcast", unsignedTestBundle(t, "mainnet"))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400. body: %s", w.Code, w.Body.String())
+	}
+	assertErrorCode(t, w.Body.Bytes(), config.ErrorInvalidBundle)
+}
+
+func TestBroadcastBundle_Unsigned(t *testing.T) {
+	database := setupSendTestDB(t)
+	deps := makeSendDeps(t, database)
+	deps.SOLService = tx.NewSOLConsolidationService(nil, nil, database, "testnet", deps.TxHub)
+	router := setupSendRouter(t, deps)
+
+	w := postBundle(t, router, "/api/send/bundle/broadcast", unsignedTestBundle(t, "testnet"))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400. body: %s", w.Code, w.Body.String())
+	}
+	assertErrorCode(t, w.Body.Bytes(), config.ErrorBundleUnsigned)
+
+	rows, err := database.GetPendingTxStates(string(models.ChainSOL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 0 {
+		t.Errorf("rejected bundle left %d pending tx_state rows", len(rows))
+	}
+}
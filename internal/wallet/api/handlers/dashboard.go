@@ -91,10 +91,11 @@ func tokenToSymbol(chain models.Chain, token models.Token) string {
 	return string(token)
 }
 
-// tokenDecimals returns the number of decimals for a chain/token pair.
+// tokenDecimals returns the number of decimals for a chain/token pair, looking
+// non-native tokens up in the token registry.
 // Balances are stored in smallest units (satoshis, wei, lamports) and must
 // be divided by 10^decimals to get the human-readable amount for USD calc.
-func tokenDecimals(chain models.Chain, token models.Token) int {
+func tokenDecimals(chain models.Chain, token models.Token, tokens []models.TokenInfo) int {
 	if token == models.TokenNative {
 		switch chain {
 		case models.ChainBTC:
@@ -105,15 +106,10 @@ func tokenDecimals(chain models.Chain, token models.Token) int {
 			return config.SOLDecimals
 		}
 	}
-	switch {
-	case chain == models.ChainBSC && token == models.TokenUSDC:
-		return config.BSCUSDCDecimals
-	case chain == models.ChainBSC && token == models.TokenUSDT:
-		return config.BSCUSDTDecimals
-	case chain == models.ChainSOL && token == models.TokenUSDC:
-		return config.SOLUSDCDecimals
-	case chain == models.ChainSOL && token == models.TokenUSDT:
-		return config.SOLUSDTDecimals
+	for _, t := range tokens {
+		if t.Chain == chain && t.Symbol == token {
+			return t.Decimals
+		}
 	}
 	return 0
 }
@@ -137,6 +133,16 @@ func GetPortfolio(database *db.DB, ps *price.PriceService) http.HandlerFunc {
 			return
 		}
 
+		// Fetch the token registry for token decimals.
+		tokens, err := database.ListTokens("")
+		if err != nil {
+			slog.Error("failed to list tokens for portfolio",
+				"error", err,
+			)
+			writeError(w, http.StatusInternalServerError, config.ErrorDatabase, "failed to list tokens")
+			return
+		}
+
 		// Fetch prices.
 		prices, err := ps.GetPrices(r.Context())
 		if err != nil {
@@ -201,7 +207,7 @@ func GetPortfolio(database *db.DB, ps *price.PriceService) http.HandlerFunc {
 			}
 
 			// Convert from smallest unit (satoshis/wei/lamports) to main unit (BTC/BNB/SOL).
-			decimals := tokenDecimals(agg.Chain, agg.Token)
+			decimals := tokenDecimals(agg.Chain, agg.Token, tokens)
 			balance := rawBalance / math.Pow10(decimals)
 
 			// Use -1 to signal "price unavailable" so frontend can distinguish from $0.
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/wallet/tx"
+)
+
+// BumpFee handles POST /api/send/bump.
+// Replaces an unconfirmed BTC consolidation or payout with one spending the same
+// inputs at a higher fee rate (BIP-125 replace-by-fee), signed from the seed and
+// broadcast synchronously. The replaced transaction is marked superseded, not
+// failed, and the replacement is tracked under the same sweep.
+func BumpFee(deps *SendDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		var req models.FeeBumpRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			slog.Warn("invalid fee bump request body", "error", err)
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidFeeBump, "invalid request body")
+			return
+		}
+
+		req.TxHash = strings.ToLower(strings.TrimSpace(req.TxHash))
+		if _, err := chainhash.NewHashFromStr(req.TxHash); err != nil || len(req.TxHash) != chainhash.MaxHashStringSize {
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidFeeBump, "txHash must be a 64-character hex transaction ID")
+			return
+		}
+		if req.FeeRate < 0 {
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidFeeBump, "feeRate must not be negative")
+			return
+		}
+
+		mu := deps.ChainLocks[models.ChainBTC]
+		if mu == nil {
+			slog.Error("no chain lock configured", "chain", models.ChainBTC)
+			writeError(w, http.StatusInternalServerError, config.ErrorTxBroadcastFailed, "internal configuration error")
+			return
+		}
+		if !mu.TryLock() {
+			slog.Warn("send already in progress for chain", "chain", models.ChainBTC)
+			writeError(w, http.StatusConflict, config.ErrorSendBusy,
+				"send operation already in progress for BTC")
+			return
+		}
+		defer mu.Unlock()
+
+		result, err := deps.BTCService.BumpFee(r.Context(), req.TxHash, req.FeeRate)
+		if err != nil {
+			writeFeeBumpError(w, err)
+			return
+		}
+
+		elapsed := time.Since(start).Milliseconds()
+		slog.Info("fee bump complete",
+			"replacedTxHash", result.ReplacedTxHash,
+			"txHash", result.TxHash,
+			"feeRate", result.FeeRate,
+			"elapsed_ms", elapsed,
+		)
+
+		writeJSON(w, http.StatusOK, models.APIResponse{
+			Data: result,
+			Meta: &models.APIMeta{ExecutionTime: elapsed},
+		})
+	}
+}
+
+// BumpFeeCPFP handles POST /api/send/cpfp.
+// Unsticks an unconfirmed BTC transaction paying this wallet (an underpaid
+// deposit, a consolidation that does not signal RBF, or a payout's change) by
+// spending its unconfirmed outputs to stored or change addresses to the
+// destination with a child whose
+// fee lifts the parent and child to the target rate (child-pays-for-parent).
+// Signed from the seed and broadcast synchronously; the child is tracked as a
+// new sweep.
+func BumpFeeCPFP(deps *SendDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		var req models.CPFPRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			slog.Warn("invalid CPFP request body", "error", err)
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidFeeBump, "invalid request body")
+			return
+		}
+
+		req.ParentTxHash = strings.ToLower(strings.TrimSpace(req.ParentTxHash))
+		if _, err := chainhash.NewHashFromStr(req.ParentTxHash); err != nil || len(req.ParentTxHash) != chainhash.MaxHashStringSize {
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidFeeBump, "parentTxHash must be a 64-character hex transaction ID")
+			return
+		}
+		if req.FeeRate < 0 {
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidFeeBump, "feeRate must not be negative")
+			return
+		}
+		req.Destination = strings.TrimSpace(req.Destination)
+		if err := validateDestination(models.ChainBTC, req.Destination, deps.NetParams); err != nil {
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidDestination, err.Error())
+			return
+		}
+
+		mu := deps.ChainLocks[models.ChainBTC]
+		if mu == nil {
+			slog.Error("no chain lock configured", "chain", models.ChainBTC)
+			writeError(w, http.StatusInternalServerError, config.ErrorTxBroadcastFailed, "internal configuration error")
+			return
+		}
+		if !mu.TryLock() {
+			slog.Warn("send already in progress for chain", "chain", models.ChainBTC)
+			writeError(w, http.StatusConflict, config.ErrorSendBusy,
+				"send operation already in progress for BTC")
+			return
+		}
+		defer mu.Unlock()
+
+		sweepID := tx.GenerateSweepID()
+		result, err := deps.BTCService.BumpFeeCPFP(r.Context(), req.ParentTxHash, req.Destination, req.FeeRate, sweepID)
+		if err != nil {
+			writeFeeBumpError(w, err)
+			return
+		}
+
+		elapsed := time.Since(start).Milliseconds()
+		slog.Info("CPFP complete",
+			"parentTxHash", result.ParentTxHash,
+			"txHash", result.TxHash,
+			"packageFeeRate", result.PackageFeeRate,
+			"sweepID", sweepID,
+			"elapsed_ms", elapsed,
+		)
+
+		writeJSON(w, http.StatusOK, models.APIResponse{
+			Data: result,
+			Meta: &models.APIMeta{ExecutionTime: elapsed},
+		})
+	}
+}
+
+// writeFeeBumpError maps an RBF or CPFP fee bump failure to its API error.
+func writeFeeBumpError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, config.ErrTxNotFound):
+		writeError(w, http.StatusNotFound, config.ErrorTxNotFound, err.Error())
+	case errors.Is(err, config.ErrTxAlreadyConfirmed):
+		writeError(w, http.StatusConflict, config.ErrorTxAlreadyConfirmed, err.Error())
+	case errors.Is(err, config.ErrTxNotReplaceable):
+		slog.Warn("transaction not replaceable", "error", err)
+		writeError(w, http.StatusBadRequest, config.ErrorTxNotReplaceable, err.Error())
+	case errors.Is(err, config.ErrFeeBumpTooLow):
+		writeError(w, http.StatusBadRequest, config.ErrorFeeBumpTooLow, err.Error())
+	case errors.Is(err, config.ErrNoCPFPOutputs):
+		writeError(w, http.StatusBadRequest, config.ErrorNoCPFPOutputs, err.Error())
+	case errors.Is(err, config.ErrInsufficientUTXO), errors.Is(err, config.ErrDustOutput):
+		writeError(w, http.StatusBadRequest, config.ErrorInsufficientUTXO, err.Error())
+	case errors.Is(err, config.ErrMnemonicFileNotSet), errors.Is(err, config.ErrMnemonicFileUnavailable):
+		slog.Warn("mnemonic not accessible for fee bump", "error", err)
+		writeError(w, http.StatusBadRequest, config.ErrorMnemonicUnavailable, err.Error())
+	default:
+		slog.Error("fee bump failed", "error", err)
+		writeError(w, http.StatusInternalServerError, config.ErrorTxBroadcastFailed, err.Error())
+	}
+}
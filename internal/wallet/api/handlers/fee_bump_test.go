@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/wallet/tx"
+)
+
+func TestBumpFee_InvalidRequest(t *testing.T) {
+	database := setupSendTestDB(t)
+	router := setupSendRouter(t, makeSendDeps(t, database))
+
+	for _, body := range []string{
+		"not json",
+		`{"txHash":"abc","feeRate":20}`,
+		`{"txHash":"` + strings.Repeat("zz", 32) + `","feeRate":20}`,
+		`{"txHash":"` + strings.Repeat("ab", 32) + `","feeRate":-1}`,
+	} {
+		w := postBundle(t, router, "/api/send/bump", body)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("body %s: status = %d, want 400", body, w.Code)
+			continue
+		}
+		assertErrorCode(t, w.Body.Bytes(), config.ErrorInvalidFeeBump)
+	}
+}
+
+func TestBumpFee_UnknownTransaction(t *testing.T) {
+	database := setupSendTestDB(t)
+	deps := makeSendDeps(t, database)
+	deps.BTCService = tx.NewBTCConsolidationService(nil, nil, nil, nil, database, &chaincfg.TestNet3Params, http.DefaultClient, nil, deps.TxHub)
+	router := setupSendRouter(t, deps)
+
+	w := postBundle(t, router, "/api/send/bump", `{"txHash":"`+strings.Repeat("ab", 32)+`","feeRate":20}`)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404. body: %s", w.Code, w.Body.String())
+	}
+	assertErrorCode(t, w.Body.Bytes(), config.ErrorTxNotFound)
+}
+
+func TestBumpFeeCPFP_InvalidRequest(t *testing.T) {
+	database := setupSendTestDB(t)
+	router := setupSendRouter(t, makeSendDeps(t, database))
+
+	dest := `"destination":"tb1qtk89me2ae95dmlp3yfl4q9ynpux8mxjujuf2fr"`
+	for _, body := range []string{
+		"not json",
+		`{"parentTxHash":"abc",` + dest + `}`,
+		`{"parentTxHash":"` + strings.Repeat("ab", 32) + `","feeRate":-1,` + dest + `}`,
+	} {
+		w := postBundle(t, router, "/api/send/cpfp", body)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("body %s: status = %d, want 400", body, w.Code)
+			continue
+		}
+		assertErrorCode(t, w.Body.Bytes(), config.ErrorInvalidFeeBump)
+	}
+
+	w := postBundle(t, router, "/api/send/cpfp", `{"parentTxHash":"`+strings.Repeat("ab", 32)+`","destination":"notavalidaddress"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invalid destination: status = %d, want 400", w.Code)
+	}
+	assertErrorCode(t, w.Body.Bytes(), config.ErrorInvalidDestination)
+}
+
+func TestBumpFeeCPFP_UnknownParent(t *testing.T) {
+	esplora := httptest.NewServer(http.NotFoundHandler())
+	defer esplora.Close()
+
+	database := setupSendTestDB(t)
+	deps := makeSendDeps(t, database)
+	deps.BTCService = tx.NewBTCConsolidationService(nil, nil, nil, nil, database, &chaincfg.TestNet3Params, esplora.Client(), []string{esplora.URL}, deps.TxHub)
+	router := setupSendRouter(t, deps)
+
+	w := postBundle(t, router, "/api/send/cpfp", `{"parentTxHash":"`+strings.Repeat("ab", 32)+`","destination":"tb1qtk89me2ae95dmlp3yfl4q9ynpux8mxjujuf2fr","feeRate":20}`)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404. body: %s", w.Code, w.Body.String())
+	}
+	assertErrorCode(t, w.Body.Bytes(), config.ErrorTxNotFound)
+}
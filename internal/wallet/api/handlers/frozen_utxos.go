@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/wallet/db"
+)
+
+// freezeUTXORequest is the JSON body for POST /api/utxos/frozen.
+type freezeUTXORequest struct {
+	TxID string  `json:"txid"`
+	Vout *uint32 `json:"vout"`
+	Note string  `json:"note"`
+}
+
+// frozenUTXODeleted is the response of DELETE /api/utxos/frozen/{txid}/{vout}.
+type frozenUTXODeleted struct {
+	TxID     string `json:"txid"`
+	Vout     uint32 `json:"vout"`
+	Unfrozen bool   `json:"unfrozen"`
+}
+
+// ListFrozenUTXOs handles GET /api/utxos/frozen.
+// Returns the BTC outpoints that consolidations never spend.
+func ListFrozenUTXOs(database *db.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		frozen, err := database.ListFrozenUTXOs()
+		if err != nil {
+			slog.Error("failed to list frozen UTXOs", "error", err)
+			writeError(w, http.StatusInternalServerError, config.ErrorDatabase, "failed to list frozen UTXOs")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, models.APIResponse{
+			Data: frozen,
+			Meta: &models.APIMeta{ExecutionTime: time.Since(start).Milliseconds()},
+		})
+	}
+}
+
+// FreezeUTXO handles POST /api/utxos/frozen.
+// Freezes a BTC outpoint so that no consolidation spends it until it is
+// unfrozen. The outpoint need not be known to the wallet yet; freezing it again
+// replaces the note.
+func FreezeUTXO(database *db.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		var req freezeUTXORequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			slog.Warn("invalid freeze UTXO request body", "error", err)
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidOutpoint, "invalid request body")
+			return
+		}
+
+		txid, err := parseOutpointTxID(req.TxID)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidOutpoint, err.Error())
+			return
+		}
+		if req.Vout == nil {
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidOutpoint, "vout is required")
+			return
+		}
+		note := strings.TrimSpace(req.Note)
+		if utf8.RuneCountInString(note) > config.MaxFrozenUTXONoteLength {
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidOutpoint,
+				fmt.Sprintf("note must be at most %d characters", config.MaxFrozenUTXONoteLength))
+			return
+		}
+
+		frozen, err := database.FreezeUTXO(txid, *req.Vout, note)
+		if err != nil {
+			slog.Error("failed to freeze UTXO", "txid", txid, "vout", *req.Vout, "error", err)
+			writeError(w, http.StatusInternalServerError, config.ErrorDatabase, "failed to freeze UTXO")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, models.APIResponse{
+			Data: frozen,
+			Meta: &models.APIMeta{ExecutionTime: time.Since(start).Milliseconds()},
+		})
+	}
+}
+
+// UnfreezeUTXO handles DELETE /api/utxos/frozen/{txid}/{vout}.
+// Makes a frozen outpoint spendable by consolidations again.
+func UnfreezeUTXO(database *db.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		txid, err := parseOutpointTxID(chi.URLParam(r, "txid"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidOutpoint, err.Error())
+			return
+		}
+		vout, err := strconv.ParseUint(chi.URLParam(r, "vout"), 10, 32)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidOutpoint, "vout must be a non-negative integer")
+			return
+		}
+
+		unfrozen, err := database.UnfreezeUTXO(txid, uint32(vout))
+		if err != nil {
+			slog.Error("failed to unfreeze UTXO", "txid", txid, "vout", vout, "error", err)
+			writeError(w, http.StatusInternalServerError, config.ErrorDatabase, "failed to unfreeze UTXO")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, models.APIResponse{
+			Data: frozenUTXODeleted{TxID: txid, Vout: uint32(vout), Unfrozen: unfrozen},
+			Meta: &models.APIMeta{ExecutionTime: time.Since(start).Milliseconds()},
+		})
+	}
+}
+
+// parseOutpointTxID normalizes the transaction ID of an outpoint to lowercase hex.
+func parseOutpointTxID(raw string) (string, error) {
+	txid := strings.ToLower(strings.TrimSpace(raw))
+	if _, err := chainhash.NewHashFromStr(txid); err != nil || len(txid) != chainhash.MaxHashStringSize {
+		return "", fmt.Errorf("txid must be a 64-character hex transaction ID")
+	}
+	return txid, nil
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/wallet/db"
+)
+
+func setupFrozenUTXORouter(database *db.DB) http.Handler {
+	r := chi.NewRouter()
+	r.Get("/api/utxos/frozen", ListFrozenUTXOs(database))
+	r.Post("/api/utxos/frozen", FreezeUTXO(database))
+	r.Delete("/api/utxos/frozen/{txid}/{vout}", UnfreezeUTXO(database))
+	return r
+}
+
+func TestFrozenUTXOs_FreezeListUnfreeze(t *testing.T) {
+	router := setupFrozenUTXORouter(setupTestDB(t))
+	txid := strings.Repeat("AB", 32)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/api/utxos/frozen", strings.NewReader(`{"txid":"`+txid+`","vout":0,"note":" dust attack "}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("freeze status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var freezeResp struct {
+		Data models.FrozenUTXO `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &freezeResp); err != nil {
+		t.Fatal(err)
+	}
+	if freezeResp.Data.TxID != strings.ToLower(txid) || freezeResp.Data.Vout != 0 || freezeResp.Data.Note != "dust attack" {
+		t.Errorf("frozen = %+v, want the normalized outpoint and note", freezeResp.Data)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/utxos/frozen", nil))
+	var listResp struct {
+		Data []models.FrozenUTXO `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &listResp); err != nil {
+		t.Fatal(err)
+	}
+	if len(listResp.Data) != 1 {
+		t.Fatalf("list = %+v, want the frozen outpoint", listResp.Data)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/utxos/frozen/"+txid+"/0", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"unfrozen":true`) {
+		t.Errorf("unfreeze status = %d, body = %s", w.Code, w.Body.String())
+	}
+}
+
+func TestFrozenUTXOs_InvalidOutpoint(t *testing.T) {
+	router := setupFrozenUTXORouter(setupTestDB(t))
+	txid := strings.Repeat("ab", 32)
+
+	for _, body := range []string{
+		"not json",
+		`{"txid":"abc","vout":0}`,
+		`{"txid":"` + txid + `"}`,
+		`{"txid":"` + txid + `","vout":0,"note":"` + strings.Repeat("n", config.MaxFrozenUTXONoteLength+1) + `"}`,
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("POST", "/api/utxos/frozen", strings.NewReader(body)))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("freeze %.40s: status = %d, want 400", body, w.Code)
+			continue
+		}
+		assertErrorCode(t, w.Body.Bytes(), config.ErrorInvalidOutpoint)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/utxos/frozen/"+txid+"/-1", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("unfreeze vout -1: status = %d, want 400", w.Code)
+	}
+	assertErrorCode(t, w.Body.Bytes(), config.ErrorInvalidOutpoint)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"log/slog"
 	"net/http"
+	"strconv"
 
 	"github.com/Fantasim/hdpay/internal/shared/config"
 )
@@ -15,10 +16,21 @@ func HealthHandler(cfg *config.Config, version string) http.HandlerFunc {
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]string{
-			"status":  "ok",
-			"version": version,
-			"network": cfg.Network,
-			"dbPath":  cfg.DBPath,
+			"status":         "ok",
+			"version":        version,
+			"network":        cfg.Network,
+			"account":        strconv.Itoa(cfg.Account),
+			"btcAddressType": string(cfg.BTCType()),
+			"dbPath":         cfg.DBPath,
+			"mode":           walletMode(cfg),
 		})
 	}
 }
+
+// walletMode reports whether the server can sign ("full") or only watch ("watch-only").
+func walletMode(cfg *config.Config) string {
+	if cfg.WatchOnly {
+		return "watch-only"
+	}
+	return "full"
+}
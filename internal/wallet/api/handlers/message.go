@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/wallet/db"
+	"github.com/Fantasim/hdpay/internal/wallet/tx"
+)
+
+// SignAddressMessage handles POST /api/addresses/{chain}/{index}/sign-message.
+// Signs {"message": "..."} with the key of a stored address to prove control of
+// it: BIP-322 simple (BTC P2WPKH/P2TR), EIP-191 personal_sign (BSC) or a Solana
+// off-chain message (SOL). Needs keyService, so it is unavailable in watch-only
+// mode (use hdpay sign-message on the machine holding the seed).
+func SignAddressMessage(database *db.DB, keyService *tx.KeyService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		if keyService == nil {
+			writeError(w, http.StatusServiceUnavailable, config.ErrorSigningUnavailable,
+				"signing messages needs the seed; in watch-only mode use hdpay sign-message")
+			return
+		}
+
+		addr, ok := resolveStoredAddress(w, r, database)
+		if !ok {
+			return
+		}
+
+		var req models.SignMessageRequest
+		r.Body = http.MaxBytesReader(w, r.Body, config.MaxSignMessageBytes*2)
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			slog.Warn("invalid sign message request body", "error", err, "remoteAddr", r.RemoteAddr)
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidMessage, "invalid request body")
+			return
+		}
+
+		if err := keyService.CheckMnemonicAvailable(); err != nil {
+			slog.Warn("mnemonic not accessible for message signing", "error", err)
+			writeError(w, http.StatusBadRequest, config.ErrorMnemonicUnavailable,
+				"mnemonic file not accessible — is your wallet disk plugged in?")
+			return
+		}
+
+		signed, err := keyService.SignMessage(r.Context(), addr.Chain, uint32(addr.AddressIndex), []byte(req.Message))
+		if err != nil {
+			writeMessageError(w, err)
+			return
+		}
+
+		// The key must derive the stored address, or the proof is for another wallet.
+		if !strings.EqualFold(signed.Address, addr.Address) {
+			slog.Error("message signing key does not derive the stored address",
+				"chain", addr.Chain,
+				"index", addr.AddressIndex,
+				"stored", addr.Address,
+				"derived", signed.Address,
+			)
+			writeError(w, http.StatusConflict, config.ErrorKeyMismatch, config.ErrKeyMismatch.Error())
+			return
+		}
+
+		writeJSON(w, http.StatusOK, models.APIResponse{
+			Data: signed,
+			Meta: &models.APIMeta{ExecutionTime: time.Since(start).Milliseconds()},
+		})
+	}
+}
+
+// VerifyAddressMessage handles POST /api/addresses/{chain}/verify-message.
+// Checks a proof-of-ownership signature for any address of the chain, not only
+// stored ones. A signature that does not verify is a 200 with valid=false and
+// the reason; a malformed request, address or message is a 400.
+func VerifyAddressMessage(netParams *chaincfg.Params) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		chainParam := strings.ToUpper(chi.URLParam(r, "chain"))
+
+		chain := models.Chain(chainParam)
+		if !isValidChain(chain) {
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidChain, "invalid chain: "+chainParam+", must be BTC, BSC, or SOL")
+			return
+		}
+
+		var req models.VerifyMessageRequest
+		r.Body = http.MaxBytesReader(w, r.Body, config.MaxSignMessageBytes*2)
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			slog.Warn("invalid verify message request body", "error", err, "remoteAddr", r.RemoteAddr)
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidMessage, "invalid request body")
+			return
+		}
+
+		req.Address = strings.TrimSpace(req.Address)
+		req.Signature = strings.TrimSpace(req.Signature)
+
+		scheme, err := tx.VerifyMessage(chain, req.Address, []byte(req.Message), req.Signature, netParams)
+		result := models.VerifyMessageResult{
+			Chain:   chain,
+			Address: req.Address,
+			Scheme:  scheme,
+			Valid:   err == nil,
+		}
+		if err != nil {
+			if !errors.Is(err, config.ErrInvalidMessageSignature) {
+				writeMessageError(w, err)
+				return
+			}
+			result.Reason = err.Error()
+		}
+
+		slog.Info("message signature verified",
+			"chain", chain,
+			"address", req.Address,
+			"scheme", scheme,
+			"valid", result.Valid,
+		)
+
+		writeJSON(w, http.StatusOK, models.APIResponse{
+			Data: result,
+			Meta: &models.APIMeta{ExecutionTime: time.Since(start).Milliseconds()},
+		})
+	}
+}
+
+// writeMessageError maps a message signing or verification failure to its API error.
+func writeMessageError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, config.ErrInvalidMessage):
+		writeError(w, http.StatusBadRequest, config.ErrorInvalidMessage, err.Error())
+	case errors.Is(err, config.ErrMessageSigningUnsupported):
+		writeError(w, http.StatusBadRequest, config.ErrorMessageSigningUnsupported, err.Error())
+	case errors.Is(err, config.ErrMnemonicFileNotSet), errors.Is(err, config.ErrMnemonicFileUnavailable):
+		slog.Warn("mnemonic not accessible for message signing", "error", err)
+		writeError(w, http.StatusBadRequest, config.ErrorMnemonicUnavailable, err.Error())
+	default:
+		slog.Error("message signing failed", "error", err)
+		writeError(w, http.StatusInternalServerError, config.ErrorTxSignFailed, err.Error())
+	}
+}
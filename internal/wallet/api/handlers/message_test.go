@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/wallet/db"
+	"github.com/Fantasim/hdpay/internal/wallet/tx"
+)
+
+func setupMessageRouter(database *db.DB, keyService *tx.KeyService) http.Handler {
+	r := chi.NewRouter()
+	r.Post("/api/addresses/{chain}/{index}/sign-message", SignAddressMessage(database, keyService))
+	r.Post("/api/addresses/{chain}/verify-message", VerifyAddressMessage(&chaincfg.TestNet3Params))
+	return r
+}
+
+func postMessage(t *testing.T, router http.Handler, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest("POST", path, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestSignAddressMessage_SignAndVerify(t *testing.T) {
+	database := setupTestDB(t)
+	keyService := newTestKeyService(t)
+
+	// Store real SOL addresses derived from the test seed.
+	if w := postExtend(t, ExtendAddresses(database, keyService), "SOL", `{"extendTo": 3}`); w.Code != http.StatusOK {
+		t.Fatalf("extend status = %d, body = %s", w.Code, w.Body.String())
+	}
+	router := setupMessageRouter(database, keyService)
+
+	w := postMessage(t, router, "/api/addresses/sol/2/sign-message", `{"message":"prove it"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("sign status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var signResp struct {
+		Data models.SignedMessage `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &signResp); err != nil {
+		t.Fatal(err)
+	}
+	signed := signResp.Data
+	stored, err := database.GetAddressByIndex(models.ChainSOL, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signed.Address != stored.Address || signed.Scheme != models.MessageSchemeSOLOffchain {
+		t.Fatalf("signed = %+v, want %s signed with %s", signed, stored.Address, models.MessageSchemeSOLOffchain)
+	}
+
+	verify := func(message string) models.VerifyMessageResult {
+		t.Helper()
+		body, _ := json.Marshal(models.VerifyMessageRequest{Address: signed.Address, Message: message, Signature: signed.Signature})
+		w := postMessage(t, router, "/api/addresses/SOL/verify-message", string(body))
+		if w.Code != http.StatusOK {
+			t.Fatalf("verify status = %d, body = %s", w.Code, w.Body.String())
+		}
+		var resp struct {
+			Data models.VerifyMessageResult `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp.Data
+	}
+
+	if got := verify("prove it"); !got.Valid {
+		t.Errorf("verify = %+v, want valid", got)
+	}
+	if got := verify("prove it again"); got.Valid || got.Reason == "" {
+		t.Errorf("verify tampered = %+v, want invalid with a reason", got)
+	}
+}
+
+func TestSignAddressMessage_Rejects(t *testing.T) {
+	database := setupTestDB(t)
+
+	w := postMessage(t, setupMessageRouter(database, nil), "/api/addresses/BTC/0/sign-message", `{"message":"hi"}`)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("watch-only: status = %d, want 503", w.Code)
+	}
+	assertErrorCode(t, w.Body.Bytes(), config.ErrorSigningUnavailable)
+
+	router := setupMessageRouter(database, newTestKeyService(t))
+
+	// The seeded BTC addresses are placeholders, not derived from the seed.
+	w = postMessage(t, router, "/api/addresses/BTC/0/sign-message", `{"message":"hi"}`)
+	if w.Code != http.StatusConflict {
+		t.Errorf("mismatched address: status = %d, want 409. body: %s", w.Code, w.Body.String())
+	}
+	assertErrorCode(t, w.Body.Bytes(), config.ErrorKeyMismatch)
+
+	w = postMessage(t, router, "/api/addresses/BTC/0/sign-message", `{"message":""}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("empty message: status = %d, want 400", w.Code)
+	}
+	assertErrorCode(t, w.Body.Bytes(), config.ErrorInvalidMessage)
+
+	w = postMessage(t, router, "/api/addresses/BTC/999/sign-message", `{"message":"hi"}`)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unknown index: status = %d, want 404", w.Code)
+	}
+
+	w = postMessage(t, router, "/api/addresses/BSC/verify-message", `{"address":"not-an-address","message":"hi","signature":"0x00"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invalid address: status = %d, want 400", w.Code)
+	}
+	assertErrorCode(t, w.Body.Bytes(), config.ErrorInvalidMessage)
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/wallet/tx"
+)
+
+// PreviewPayout handles POST /api/send/payout/preview.
+// Builds the BTC payout PayoutBTC would send, with its inputs, change and fee,
+// without signing or broadcasting it.
+func PreviewPayout(deps *SendDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		req, addresses, ok := decodePayoutRequest(w, r, deps)
+		if !ok {
+			return
+		}
+
+		preview, err := deps.BTCService.PreviewPayout(r.Context(), addresses, req.Recipients, req.FeeRate, payoutInputSelection(req))
+		if err != nil {
+			writePayoutError(w, err, config.ErrorTxBuildFailed)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, models.APIResponse{
+			Data: preview,
+			Meta: &models.APIMeta{ExecutionTime: time.Since(start).Milliseconds()},
+		})
+	}
+}
+
+// PayoutBTC handles POST /api/send/payout.
+// Pays each recipient from the pooled BTC funds in one transaction, sending the
+// change to a fresh internal-chain address.
+func PayoutBTC(deps *SendDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		req, addresses, ok := decodePayoutRequest(w, r, deps)
+		if !ok {
+			return
+		}
+
+		mu := deps.ChainLocks[models.ChainBTC]
+		if mu == nil {
+			slog.Error("no chain lock configured", "chain", models.ChainBTC)
+			writeError(w, http.StatusInternalServerError, config.ErrorTxBroadcastFailed, "internal configuration error")
+			return
+		}
+		if !mu.TryLock() {
+			slog.Warn("send already in progress for chain", "chain", models.ChainBTC)
+			writeError(w, http.StatusConflict, config.ErrorSendBusy,
+				"send operation already in progress for BTC")
+			return
+		}
+		defer mu.Unlock()
+
+		sweepID := tx.GenerateSweepID()
+		result, err := deps.BTCService.Payout(r.Context(), addresses, req.Recipients, req.FeeRate, payoutInputSelection(req), sweepID)
+		if err != nil {
+			writePayoutError(w, err, config.ErrorTxBroadcastFailed)
+			return
+		}
+
+		elapsed := time.Since(start).Milliseconds()
+		slog.Info("BTC payout complete",
+			"txHash", result.TxHash,
+			"recipientCount", len(result.Recipients),
+			"payoutSats", result.PayoutSats,
+			"changeSats", result.ChangeSats,
+			"sweepID", sweepID,
+			"elapsed_ms", elapsed,
+		)
+
+		writeJSON(w, http.StatusOK, models.APIResponse{
+			Data: result,
+			Meta: &models.APIMeta{ExecutionTime: elapsed},
+		})
+	}
+}
+
+// ListPayouts handles GET /api/send/payouts.
+// Returns a page of the recipient outputs of broadcast BTC payouts, newest first.
+func ListPayouts(deps *SendDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		page := parseIntParam(r, "page", config.DefaultPage)
+		pageSize := parseIntParam(r, "pageSize", config.DefaultPageSize)
+		if pageSize > config.MaxPageSize {
+			pageSize = config.MaxPageSize
+		}
+		if pageSize < 1 {
+			pageSize = config.DefaultPageSize
+		}
+		if page < 1 {
+			page = config.DefaultPage
+		}
+
+		payouts, total, err := deps.DB.ListBTCPayouts(page, pageSize)
+		if err != nil {
+			slog.Error("failed to fetch payouts", "error", err)
+			writeError(w, http.StatusInternalServerError, config.ErrorDatabase, "failed to fetch payouts")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, models.APIResponse{
+			Data: payouts,
+			Meta: &models.APIMeta{
+				Page:          page,
+				PageSize:      pageSize,
+				Total:         total,
+				ExecutionTime: time.Since(start).Milliseconds(),
+			},
+		})
+	}
+}
+
+// decodePayoutRequest decodes and validates a payout request and returns the
+// funded BTC addresses it may spend from: the selected funded addresses, plus the
+// funded change addresses of earlier payouts unless the request names address
+// indices. On failure it writes the error response and returns false.
+func decodePayoutRequest(w http.ResponseWriter, r *http.Request, deps *SendDeps) (models.PayoutRequest, []models.Address, bool) {
+	var req models.PayoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Warn("invalid payout request body", "error", err)
+		writeError(w, http.StatusBadRequest, config.ErrorInvalidPayout, "invalid request body")
+		return req, nil, false
+	}
+
+	for i := range req.Recipients {
+		req.Recipients[i].Address = strings.TrimSpace(req.Recipients[i].Address)
+	}
+	if err := tx.ValidatePayoutRecipients(req.Recipients, deps.NetParams); err != nil {
+		writeError(w, http.StatusBadRequest, config.ErrorInvalidPayout, err.Error())
+		return req, nil, false
+	}
+	if req.FeeRate < 0 {
+		writeError(w, http.StatusBadRequest, config.ErrorInvalidPayout, "feeRate must not be negative")
+		return req, nil, false
+	}
+	if req.InputSelection != nil {
+		if err := tx.ValidateBTCInputSelection(*req.InputSelection); err != nil {
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidInputSelection, err.Error())
+			return req, nil, false
+		}
+	}
+
+	funded, err := deps.DB.GetFundedAddressesJoined(models.ChainBTC, models.TokenNative)
+	if err != nil {
+		slog.Error("failed to fetch funded addresses", "chain", models.ChainBTC, "error", err)
+		writeError(w, http.StatusInternalServerError, config.ErrorDatabase, "failed to fetch funded addresses")
+		return req, nil, false
+	}
+	funded = selectFundedAddresses(funded, req.InputSelection)
+
+	addresses := make([]models.Address, len(funded))
+	for i, f := range funded {
+		addresses[i] = models.Address{
+			Chain:        f.Chain,
+			AddressIndex: f.AddressIndex,
+			Address:      f.Address,
+		}
+	}
+
+	if req.InputSelection == nil || len(req.InputSelection.AddressIndices) == 0 {
+		change, err := deps.DB.GetFundedBTCChangeAddresses()
+		if err != nil {
+			slog.Error("failed to fetch funded change addresses", "error", err)
+			writeError(w, http.StatusInternalServerError, config.ErrorDatabase, "failed to fetch funded change addresses")
+			return req, nil, false
+		}
+		addresses = append(addresses, change...)
+	}
+
+	if len(addresses) == 0 {
+		writeError(w, http.StatusBadRequest, config.ErrorNoFundedAddresses,
+			fmt.Sprintf("no funded %s addresses found for %s", models.TokenNative, models.ChainBTC))
+		return req, nil, false
+	}
+	return req, addresses, true
+}
+
+// payoutInputSelection returns the coin control criteria of a payout request; the
+// zero value when the request has none.
+func payoutInputSelection(req models.PayoutRequest) models.BTCInputSelection {
+	if req.InputSelection == nil {
+		return models.BTCInputSelection{}
+	}
+	return *req.InputSelection
+}
+
+// writePayoutError maps a payout failure to its API error; failures without a
+// specific code get fallbackCode.
+func writePayoutError(w http.ResponseWriter, err error, fallbackCode string) {
+	switch {
+	case errors.Is(err, config.ErrInvalidPayout):
+		writeError(w, http.StatusBadRequest, config.ErrorInvalidPayout, err.Error())
+	case errors.Is(err, config.ErrInsufficientUTXO), errors.Is(err, config.ErrDustOutput):
+		writeError(w, http.StatusBadRequest, config.ErrorInsufficientUTXO, err.Error())
+	case errors.Is(err, config.ErrTxTooLarge):
+		writeError(w, http.StatusBadRequest, config.ErrorTxTooLarge, err.Error())
+	case errors.Is(err, config.ErrMnemonicFileNotSet), errors.Is(err, config.ErrMnemonicFileUnavailable):
+		slog.Warn("mnemonic not accessible for payout", "error", err)
+		writeError(w, http.StatusBadRequest, config.ErrorMnemonicUnavailable, err.Error())
+	default:
+		slog.Error("BTC payout failed", "error", err)
+		writeError(w, http.StatusInternalServerError, fallbackCode, err.Error())
+	}
+}
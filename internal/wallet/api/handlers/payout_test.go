@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+)
+
+func TestPayout_InvalidRequest(t *testing.T) {
+	router := setupSendRouter(t, makeSendDeps(t, setupSendTestDB(t)))
+
+	recipient := `{"address":"tb1qtk89me2ae95dmlp3yfl4q9ynpux8mxjujuf2fr","amountSats":10000}`
+	for _, path := range []string{"/api/send/payout/preview", "/api/send/payout"} {
+		for _, body := range []string{
+			"not json",
+			`{"recipients":[]}`,
+			`{"recipients":[{"address":"notavalidaddress","amountSats":10000}]}`,
+			`{"recipients":[{"address":"tb1qtk89me2ae95dmlp3yfl4q9ynpux8mxjujuf2fr","amountSats":100}]}`,
+			`{"recipients":[` + recipient + `],"feeRate":-1}`,
+		} {
+			w := postBundle(t, router, path, body)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%s %s: status = %d, want 400", path, body, w.Code)
+				continue
+			}
+			assertErrorCode(t, w.Body.Bytes(), config.ErrorInvalidPayout)
+		}
+	}
+
+	w := postBundle(t, router, "/api/send/payout", `{"recipients":[`+recipient+`],"inputSelection":{"maxInputs":-1}}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invalid input selection: status = %d, want 400", w.Code)
+	}
+	assertErrorCode(t, w.Body.Bytes(), config.ErrorInvalidInputSelection)
+}
+
+func TestPayout_NoFundedAddresses(t *testing.T) {
+	router := setupSendRouter(t, makeSendDeps(t, setupSendTestDB(t)))
+
+	body := `{"recipients":[` + strings.Repeat(`{"address":"tb1qtk89me2ae95dmlp3yfl4q9ynpux8mxjujuf2fr","amountSats":10000},`, 2)
+	body = strings.TrimSuffix(body, ",") + `]}`
+	w := postBundle(t, router, "/api/send/payout", body)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400. body: %s", w.Code, w.Body.String())
+	}
+	assertErrorCode(t, w.Body.Bytes(), config.ErrorNoFundedAddresses)
+}
+
+func TestPayout_Busy(t *testing.T) {
+	database := setupSendTestDB(t)
+	if err := database.InsertAddressBatch(models.ChainBTC, []models.Address{
+		{Chain: models.ChainBTC, AddressIndex: 0, Address: "tb1qtk89me2ae95dmlp3yfl4q9ynpux8mxjujuf2fr"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.UpsertBalance(models.ChainBTC, 0, models.TokenNative, "50000"); err != nil {
+		t.Fatal(err)
+	}
+	deps := makeSendDeps(t, database)
+	router := setupSendRouter(t, deps)
+
+	deps.ChainLocks[models.ChainBTC].Lock()
+	defer deps.ChainLocks[models.ChainBTC].Unlock()
+
+	w := postBundle(t, router, "/api/send/payout", `{"recipients":[{"address":"tb1qtk89me2ae95dmlp3yfl4q9ynpux8mxjujuf2fr","amountSats":10000}]}`)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want 409. body: %s", w.Code, w.Body.String())
+	}
+	assertErrorCode(t, w.Body.Bytes(), config.ErrorSendBusy)
+}
+
+func TestListPayouts(t *testing.T) {
+	database := setupSendTestDB(t)
+	if err := database.RecordBTCPayout("sweep-1", "payouthash", []models.PayoutRecipient{
+		{Address: "tb1qtk89me2ae95dmlp3yfl4q9ynpux8mxjujuf2fr", AmountSats: 10000},
+		{Address: "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx", AmountSats: 20000},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	router := setupSendRouter(t, makeSendDeps(t, database))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/send/payouts?pageSize=1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200. body: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Data []models.PayoutOutput `json:"data"`
+		Meta models.APIMeta        `json:"meta"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resp.Meta.Total != 2 || len(resp.Data) != 1 || resp.Data[0].TxHash != "payouthash" || resp.Data[0].Vout != 0 {
+		t.Errorf("response = %+v, want the first of 2 payout outputs", resp)
+	}
+}
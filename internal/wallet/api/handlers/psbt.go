@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/wallet/tx"
+)
+
+// BroadcastPSBT handles POST /api/send/psbt/broadcast.
+// Accepts a BTC consolidation PSBT exported by the preview and signed offline
+// (hdpay sign-psbt, or any BIP-174 signer), finalizes it and broadcasts it. The
+// broadcast is recorded in tx_state and transactions like POST /api/send/execute,
+// but runs synchronously: the response carries the transaction hash.
+func BroadcastPSBT(deps *SendDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		// Base64 inflates the PSBT by a third; twice the binary cap leaves room for JSON.
+		var req models.PSBTBroadcastRequest
+		r.Body = http.MaxBytesReader(w, r.Body, config.MaxPSBTBytes*2)
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			slog.Warn("invalid PSBT broadcast request body", "error", err)
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidPSBT, "invalid request body")
+			return
+		}
+
+		packet, err := tx.DecodePSBT([]byte(strings.TrimSpace(req.PSBT)))
+		if err != nil {
+			slog.Warn("PSBT rejected", "error", err)
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidPSBT, err.Error())
+			return
+		}
+
+		mu := deps.ChainLocks[models.ChainBTC]
+		if mu == nil {
+			slog.Error("no chain lock configured", "chain", models.ChainBTC)
+			writeError(w, http.StatusInternalServerError, config.ErrorTxBroadcastFailed, "internal configuration error")
+			return
+		}
+		if !mu.TryLock() {
+			slog.Warn("send already in progress for chain", "chain", models.ChainBTC)
+			writeError(w, http.StatusConflict, config.ErrorSendBusy,
+				"send operation already in progress for BTC")
+			return
+		}
+		defer mu.Unlock()
+
+		sweepID := tx.GenerateSweepID()
+		slog.Info("PSBT broadcast requested",
+			"inputCount", len(packet.Inputs),
+			"outputCount", len(packet.Outputs),
+			"sweepID", sweepID,
+		)
+
+		result, err := deps.BTCService.BroadcastPSBT(r.Context(), packet, sweepID)
+		if err != nil {
+			writePSBTError(w, err)
+			return
+		}
+
+		elapsed := time.Since(start).Milliseconds()
+		slog.Info("PSBT broadcast complete",
+			"txHash", result.TxHash,
+			"sweepID", sweepID,
+			"elapsed_ms", elapsed,
+		)
+
+		writeJSON(w, http.StatusOK, models.APIResponse{
+			Data: result,
+			Meta: &models.APIMeta{ExecutionTime: elapsed},
+		})
+	}
+}
+
+// writePSBTError maps a PSBT broadcast failure to its API error.
+func writePSBTError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, config.ErrPSBTIncomplete):
+		slog.Warn("PSBT not fully signed", "error", err)
+		writeError(w, http.StatusBadRequest, config.ErrorPSBTIncomplete, err.Error())
+	case errors.Is(err, config.ErrPSBTForeignInput):
+		slog.Warn("PSBT spends a foreign input", "error", err)
+		writeError(w, http.StatusBadRequest, config.ErrorPSBTForeignInput, err.Error())
+	case errors.Is(err, config.ErrInvalidPSBT):
+		slog.Warn("PSBT rejected", "error", err)
+		writeError(w, http.StatusBadRequest, config.ErrorInvalidPSBT, err.Error())
+	default:
+		slog.Error("PSBT broadcast failed", "error", err)
+		writeError(w, http.StatusInternalServerError, config.ErrorTxBroadcastFailed, err.Error())
+	}
+}
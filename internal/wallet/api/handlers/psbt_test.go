@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/wallet/tx"
+)
+
+// unsignedTestPSBT returns a base64 one-input, one-output P2WPKH PSBT with no
+// signatures.
+func unsignedTestPSBT(t *testing.T) string {
+	t.Helper()
+	addr, err := btcutil.DecodeAddress("tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx", &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msgTx := wire.NewMsgTx(2)
+	msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 0), nil, nil))
+	msgTx.AddTxOut(wire.NewTxOut(9000, pkScript))
+
+	p, err := psbt.NewFromUnsignedTx(msgTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Inputs[0].WitnessUtxo = wire.NewTxOut(10000, pkScript)
+	encoded, err := p.B64Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encoded
+}
+
+func postPSBT(t *testing.T, router http.Handler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/api/send/psbt/broadcast", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestBroadcastPSBT_InvalidBody(t *testing.T) {
+	database := setupSendTestDB(t)
+	router := setupSendRouter(t, makeSendDeps(t, database))
+
+	w := postPSBT(t, router, "not json")
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", w.Code)
+	}
+	assertErrorCode(t, w.Body.Bytes(), config.ErrorInvalidPSBT)
+}
+
+func TestBroadcastPSBT_InvalidPSBT(t *testing.T) {
+	database := setupSendTestDB(t)
+	router := setupSendRouter(t, makeSendDeps(t, database))
+
+	w := postPSBT(t, router, `{"psbt":"cHNidP8BAAA="}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400. body: %s", w.Code, w.Body.String())
+	}
+	assertErrorCode(t, w.Body.Bytes(), config.ErrorInvalidPSBT)
+}
+
+func TestBroadcastPSBT_Incomplete(t *testing.T) {
+	database := setupSendTestDB(t)
+	deps := makeSendDeps(t, database)
+	deps.BTCService = tx.NewBTCConsolidationService(nil, nil, nil, nil, database, deps.NetParams, http.DefaultClient, nil, deps.TxHub)
+	router := setupSendRouter(t, deps)
+
+	w := postPSBT(t, router, `{"psbt":"`+unsignedTestPSBT(t)+`"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400. body: %s", w.Code, w.Body.String())
+	}
+	assertErrorCode(t, w.Body.Bytes(), config.ErrorPSBTIncomplete)
+
+	rows, err := database.GetPendingTxStates(string(models.ChainBTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 0 {
+		t.Errorf("rejected PSBT left %d pending tx_state rows", len(rows))
+	}
+}
+
+func TestBroadcastPSBT_ChainLockConflict(t *testing.T) {
+	database := setupSendTestDB(t)
+	deps := makeSendDeps(t, database)
+	router := setupSendRouter(t, deps)
+
+	deps.ChainLocks[models.ChainBTC].Lock()
+	defer deps.ChainLocks[models.ChainBTC].Unlock()
+
+	w := postPSBT(t, router, `{"psbt":"`+unsignedTestPSBT(t)+`"}`)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want 409. body: %s", w.Code, w.Body.String())
+	}
+	assertErrorCode(t, w.Body.Bytes(), config.ErrorSendBusy)
+}
@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math/big"
@@ -36,6 +38,26 @@ type SendDeps struct {
 	TxHub      *tx.TxSSEHub
 	NetParams  *chaincfg.Params
 	ChainLocks map[models.Chain]*sync.Mutex // per-chain mutex to prevent concurrent sweeps
+	WatchOnly  bool                         // no KeyService or TX services; all send routes are disabled
+}
+
+// RequireSigning rejects every send route when the server runs in watch-only mode.
+// In that mode none of the TX services exist, so handlers must never be reached.
+func RequireSigning(deps *SendDeps) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if deps == nil || deps.WatchOnly {
+				slog.Warn("send request rejected: watch-only mode",
+					"method", r.Method,
+					"path", r.URL.Path,
+				)
+				writeError(w, http.StatusServiceUnavailable, config.ErrorSigningUnavailable,
+					config.ErrSigningUnavailable.Error())
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
 }
 
 // solBase58Regex matches valid Solana base58 addresses (32-44 chars, no 0OIl).
@@ -85,52 +107,81 @@ func validateDestination(chain models.Chain, address string, netParams *chaincfg
 	return nil
 }
 
-// isValidToken checks if a token is valid for a given chain.
-func isValidToken(chain models.Chain, token models.Token) bool {
-	switch chain {
-	case models.ChainBTC:
-		return token == models.TokenNative
-	case models.ChainBSC:
-		return token == models.TokenNative || token == models.TokenUSDC || token == models.TokenUSDT
-	case models.ChainSOL:
-		return token == models.TokenNative || token == models.TokenUSDC || token == models.TokenUSDT
+// validateInputSelection validates the coin control criteria of a send request,
+// which only BTC consolidations support.
+func validateInputSelection(req models.SendRequest) error {
+	if req.InputSelection == nil {
+		return nil
+	}
+	if req.Chain != models.ChainBTC {
+		return fmt.Errorf("inputSelection is only supported for BTC, not %s", req.Chain)
 	}
-	return false
+	return tx.ValidateBTCInputSelection(*req.InputSelection)
 }
 
-// getTokenContractAddress returns the contract/mint address for a token on a chain.
-func getTokenContractAddress(chain models.Chain, token models.Token, network string) string {
-	isTestnet := network == string(models.NetworkTestnet)
+// validateFeeTarget checks the optional fee target of a send request: BTC only,
+// and one of the estimate's confirmation targets.
+func validateFeeTarget(req models.SendRequest) error {
+	if req.FeeTarget == "" {
+		return nil
+	}
+	if req.Chain != models.ChainBTC {
+		return fmt.Errorf("%w: feeTarget is only supported for BTC, not %s", config.ErrInvalidFeeTarget, req.Chain)
+	}
+	return tx.ValidateFeeTarget(req.FeeTarget)
+}
 
-	switch chain {
-	case models.ChainBSC:
-		if token == models.TokenUSDC {
-			if isTestnet {
-				return config.BSCTestnetUSDCContract
-			}
-			return config.BSCUSDCContract
-		}
-		if token == models.TokenUSDT {
-			if isTestnet {
-				return config.BSCTestnetUSDTContract
-			}
-			return config.BSCUSDTContract
-		}
-	case models.ChainSOL:
-		if token == models.TokenUSDC {
-			if isTestnet {
-				return config.SOLTestnetUSDCMint
-			}
-			return config.SOLUSDCMint
+// selectFundedAddresses keeps the funded addresses listed in the selection's
+// AddressIndices, or all of them when it lists none.
+func selectFundedAddresses(funded []models.AddressWithBalance, sel *models.BTCInputSelection) []models.AddressWithBalance {
+	if sel == nil || len(sel.AddressIndices) == 0 {
+		return funded
+	}
+	wanted := make(map[int]bool, len(sel.AddressIndices))
+	for _, idx := range sel.AddressIndices {
+		wanted[idx] = true
+	}
+	var selected []models.AddressWithBalance
+	for _, f := range funded {
+		if wanted[f.AddressIndex] {
+			selected = append(selected, f)
 		}
-		if token == models.TokenUSDT {
-			if isTestnet {
-				return config.SOLTestnetUSDTMint
-			}
-			return config.SOLUSDTMint
+	}
+	return selected
+}
+
+// btcInputSelection returns the coin control criteria of a BTC send request; the
+// zero value when the request has none.
+func btcInputSelection(req models.SendRequest) models.BTCInputSelection {
+	if req.InputSelection == nil {
+		return models.BTCInputSelection{}
+	}
+	return *req.InputSelection
+}
+
+// isValidToken checks if a token is valid for a given chain: NATIVE, or a token
+// of the chain in the token registry.
+func isValidToken(database *db.DB, chain models.Chain, token models.Token) bool {
+	if token == models.TokenNative {
+		return chain == models.ChainBTC || chain == models.ChainBSC || chain == models.ChainSOL
+	}
+	return getTokenContractAddress(database, chain, token) != ""
+}
+
+// getTokenContractAddress returns the contract/mint address of a registry token
+// on a chain, or "" for NATIVE and tokens the registry does not have.
+func getTokenContractAddress(database *db.DB, chain models.Chain, token models.Token) string {
+	if token == models.TokenNative || chain == models.ChainBTC {
+		return ""
+	}
+	info, err := database.GetToken(chain, token)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			slog.Error("failed to look up token", "chain", chain, "token", token, "error", err)
 		}
+		return ""
 	}
-	return ""
+	return info.Contract
 }
 
 // PreviewSend handles POST /api/send/preview.
@@ -162,7 +213,7 @@ func PreviewSend(deps *SendDeps) http.HandlerFunc {
 		}
 
 		// Validate token.
-		if !isValidToken(req.Chain, req.Token) {
+		if !isValidToken(deps.DB, req.Chain, req.Token) {
 			slog.Warn("invalid token for send preview", "chain", req.Chain, "token", req.Token)
 			writeError(w, http.StatusBadRequest, config.ErrorInvalidToken,
 				fmt.Sprintf("invalid token %s for chain %s", req.Token, req.Chain))
@@ -179,6 +230,14 @@ func PreviewSend(deps *SendDeps) http.HandlerFunc {
 			writeError(w, http.StatusBadRequest, config.ErrorInvalidDestination, err.Error())
 			return
 		}
+		if err := validateInputSelection(req); err != nil {
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidInputSelection, err.Error())
+			return
+		}
+		if err := validateFeeTarget(req); err != nil {
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidFeeTarget, err.Error())
+			return
+		}
 
 		// Fetch funded addresses from DB.
 		funded, err := deps.DB.GetFundedAddressesJoined(req.Chain, req.Token)
@@ -187,6 +246,7 @@ func PreviewSend(deps *SendDeps) http.HandlerFunc {
 			writeError(w, http.StatusInternalServerError, config.ErrorDatabase, "failed to fetch funded addresses")
 			return
 		}
+		funded = selectFundedAddresses(funded, req.InputSelection)
 
 		if len(funded) == 0 {
 			slog.Info("no funded addresses for send preview", "chain", req.Chain, "token", req.Token)
@@ -267,8 +327,8 @@ func buildBTCPreview(ctx context.Context, deps *SendDeps, req models.SendRequest
 		}
 	}
 
-	// Use default fee rate; the BTC service will try to estimate dynamically.
-	btcPreview, err := deps.BTCService.Preview(ctx, addresses, req.Destination, 0)
+	// The BTC service estimates the fee rate for the requested confirmation target.
+	btcPreview, err := deps.BTCService.Preview(ctx, addresses, req.Destination, 0, req.FeeTarget, btcInputSelection(req))
 	if err != nil {
 		return nil, fmt.Errorf("BTC preview failed: %w", err)
 	}
@@ -281,6 +341,8 @@ func buildBTCPreview(ctx context.Context, deps *SendDeps, req models.SendRequest
 			Address:      f.Address,
 			Balance:      f.NativeBalance,
 			HasGas:       true, // BTC: UTXOs cover fees implicitly
+			Label:        f.Label,
+			ExternalID:   f.ExternalID,
 		}
 	}
 
@@ -292,10 +354,15 @@ func buildBTCPreview(ctx context.Context, deps *SendDeps, req models.SendRequest
 		TotalAmount:     fmt.Sprintf("%d", btcPreview.TotalInputSats),
 		FeeEstimate:     fmt.Sprintf("%d", btcPreview.FeeSats),
 		NetAmount:       fmt.Sprintf("%d", btcPreview.OutputSats),
-		TxCount:         1, // BTC is always a single transaction.
+		TxCount:         len(btcPreview.Chunks), // One multi-input TX per chunk.
 		NeedsGasPreSeed: false,
 		GasPreSeedCount: 0,
 		FundedAddresses: fundedInfos,
+		PSBT:            btcPreview.PSBT,
+		Chunks:          btcPreview.Chunks,
+		FeeRate:         btcPreview.FeeRate,
+		FeeTarget:       btcPreview.FeeTarget,
+		FeeBreakdown:    btcPreview.FeeBreakdown,
 	}, nil
 }
 
@@ -313,6 +380,8 @@ func buildBSCNativePreview(ctx context.Context, deps *SendDeps, req models.SendR
 			Address:      f.Address,
 			Balance:      f.NativeBalance,
 			HasGas:       true, // Native BNB sweeps: gas is deducted from balance.
+			Label:        f.Label,
+			ExternalID:   f.ExternalID,
 		}
 	}
 
@@ -328,18 +397,21 @@ func buildBSCNativePreview(ctx context.Context, deps *SendDeps, req models.SendR
 		NeedsGasPreSeed: false,
 		GasPreSeedCount: 0,
 		FundedAddresses: fundedInfos,
+		GasPrice:        bscPreview.GasPrice,
+		BaseFee:         bscPreview.BaseFee,
+		PriorityFee:     bscPreview.PriorityFee,
 	}, nil
 }
 
 // buildBSCTokenPreview generates a unified preview for BSC token (USDC/USDT) sweep.
 func buildBSCTokenPreview(ctx context.Context, deps *SendDeps, req models.SendRequest, funded []models.AddressWithBalance) (*models.UnifiedSendPreview, error) {
-	// Estimate gas cost: count × gasLimit × bufferedGasPrice.
-	gasPrice, err := deps.BSCService.EstimateGasPrice(ctx)
+	// Estimate gas cost: count × gasLimit × bufferedGasPrice (max fee for dynamic fees).
+	fee, err := deps.BSCService.EstimateFee(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("BSC gas price estimation failed: %w", err)
 	}
 
-	gasCostPerTx := new(big.Int).Mul(big.NewInt(int64(config.BSCGasLimitBEP20)), gasPrice)
+	gasCostPerTx := fee.GasCost(config.BSCGasLimitBEP20)
 	totalGasCost := new(big.Int).Mul(gasCostPerTx, big.NewInt(int64(len(funded))))
 
 	// Calculate total token amount.
@@ -377,10 +449,12 @@ func buildBSCTokenPreview(ctx context.Context, deps *SendDeps, req models.SendRe
 			Address:      f.Address,
 			Balance:      tokenBal,
 			HasGas:       hasGas,
+			Label:        f.Label,
+			ExternalID:   f.ExternalID,
 		}
 	}
 
-	return &models.UnifiedSendPreview{
+	preview := &models.UnifiedSendPreview{
 		Chain:           req.Chain,
 		Token:           req.Token,
 		Destination:     req.Destination,
@@ -392,7 +466,13 @@ func buildBSCTokenPreview(ctx context.Context, deps *SendDeps, req models.SendRe
 		NeedsGasPreSeed: gasPreSeedCount > 0,
 		GasPreSeedCount: gasPreSeedCount,
 		FundedAddresses: fundedInfos,
-	}, nil
+		GasPrice:        fee.GasPrice.String(),
+	}
+	if fee.Dynamic() {
+		preview.BaseFee = fee.BaseFee.String()
+		preview.PriorityFee = fee.TipCap.String()
+	}
+	return preview, nil
 }
 
 // buildSOLNativePreview generates a unified preview for SOL native sweep.
@@ -409,6 +489,8 @@ func buildSOLNativePreview(ctx context.Context, deps *SendDeps, req models.SendR
 			Address:      f.Address,
 			Balance:      f.NativeBalance,
 			HasGas:       true, // SOL: fee deducted from balance.
+			Label:        f.Label,
+			ExternalID:   f.ExternalID,
 		}
 	}
 
@@ -429,7 +511,7 @@ func buildSOLNativePreview(ctx context.Context, deps *SendDeps, req models.SendR
 
 // buildSOLTokenPreview generates a unified preview for SOL token (USDC/USDT) sweep.
 func buildSOLTokenPreview(ctx context.Context, deps *SendDeps, req models.SendRequest, funded []models.AddressWithBalance) (*models.UnifiedSendPreview, error) {
-	mint := getTokenContractAddress(req.Chain, req.Token, deps.Config.Network)
+	mint := getTokenContractAddress(deps.DB, req.Chain, req.Token)
 
 	// Call PreviewTokenSweep for fee estimation and ATA check only.
 	solPreview, err := deps.SOLService.PreviewTokenSweep(ctx, funded, req.Destination, req.Token, mint)
@@ -467,6 +549,8 @@ func buildSOLTokenPreview(ctx context.Context, deps *SendDeps, req models.SendRe
 			Address:      f.Address,
 			Balance:      tokenBal,
 			HasGas:       hasGas,
+			Label:        f.Label,
+			ExternalID:   f.ExternalID,
 		}
 	}
 
@@ -516,7 +600,7 @@ func ExecuteSend(deps *SendDeps) http.HandlerFunc {
 			writeError(w, http.StatusBadRequest, config.ErrorInvalidChain, "invalid chain")
 			return
 		}
-		if !isValidToken(req.Chain, req.Token) {
+		if !isValidToken(deps.DB, req.Chain, req.Token) {
 			writeError(w, http.StatusBadRequest, config.ErrorInvalidToken, "invalid token for chain")
 			return
 		}
@@ -524,6 +608,14 @@ func ExecuteSend(deps *SendDeps) http.HandlerFunc {
 			writeError(w, http.StatusBadRequest, config.ErrorInvalidDestination, err.Error())
 			return
 		}
+		if err := validateInputSelection(req); err != nil {
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidInputSelection, err.Error())
+			return
+		}
+		if err := validateFeeTarget(req); err != nil {
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidFeeTarget, err.Error())
+			return
+		}
 
 		// Pre-flight: ensure mnemonic file is accessible before committing to the sweep.
 		// Supports external-disk workflow where the mnemonic lives on removable media.
@@ -544,6 +636,7 @@ func ExecuteSend(deps *SendDeps) http.HandlerFunc {
 			writeError(w, http.StatusInternalServerError, config.ErrorDatabase, "failed to fetch funded addresses")
 			return
 		}
+		funded = selectFundedAddresses(funded, req.InputSelection)
 
 		if len(funded) == 0 {
 			writeError(w, http.StatusBadRequest, config.ErrorNoFundedAddresses, "no funded addresses found")
@@ -620,16 +713,7 @@ func ExecuteSend(deps *SendDeps) http.HandlerFunc {
 				)
 
 				// Broadcast error via SSE so frontend can show it.
-				if deps.TxHub != nil {
-					deps.TxHub.Broadcast(tx.TxEvent{
-						Type: "tx_error",
-						Data: tx.TxErrorData{
-							Chain:   string(req.Chain),
-							Error:   config.ErrorTxBroadcastFailed,
-							Message: err.Error(),
-						},
-					})
-				}
+				broadcastSweepError(deps, req.Chain, err)
 				return
 			}
 
@@ -644,40 +728,64 @@ func ExecuteSend(deps *SendDeps) http.HandlerFunc {
 			)
 
 			// Broadcast completion event via SSE with full results.
-			if deps.TxHub != nil {
-				// Build TxStatusData slice from UnifiedSendResult for the completion payload.
-				txResults := make([]tx.TxStatusData, len(result.TxResults))
-				for i, r := range result.TxResults {
-					txResults[i] = tx.TxStatusData{
-						Chain:        string(req.Chain),
-						Token:        string(req.Token),
-						AddressIndex: r.AddressIndex,
-						FromAddress:  r.FromAddress,
-						TxHash:       r.TxHash,
-						Status:       r.Status,
-						Amount:       r.Amount,
-						Error:        r.Error,
-						Current:      i + 1,
-						Total:        len(result.TxResults),
-					}
-				}
-
-				deps.TxHub.Broadcast(tx.TxEvent{
-					Type: "tx_complete",
-					Data: tx.TxCompleteData{
-						Chain:        string(req.Chain),
-						Token:        string(req.Token),
-						SuccessCount: result.SuccessCount,
-						FailCount:    result.FailCount,
-						TotalSwept:   result.TotalSwept,
-						TxResults:    txResults,
-					},
-				})
-			}
+			broadcastSweepComplete(deps, req.Chain, req.Token, result)
 		}()
 	}
 }
 
+// broadcastSweepError emits the tx_error SSE event for a background sweep that
+// failed before producing a result.
+func broadcastSweepError(deps *SendDeps, chain models.Chain, err error) {
+	if deps.TxHub == nil {
+		return
+	}
+	deps.TxHub.Broadcast(tx.TxEvent{
+		Type: "tx_error",
+		Data: tx.TxErrorData{
+			Chain:   string(chain),
+			Error:   config.ErrorTxBroadcastFailed,
+			Message: err.Error(),
+		},
+	})
+}
+
+// broadcastSweepComplete emits the tx_complete SSE event with the full results
+// of a background sweep.
+func broadcastSweepComplete(deps *SendDeps, chain models.Chain, token models.Token, result *models.UnifiedSendResult) {
+	if deps.TxHub == nil {
+		return
+	}
+
+	// Build TxStatusData slice from UnifiedSendResult for the completion payload.
+	txResults := make([]tx.TxStatusData, len(result.TxResults))
+	for i, r := range result.TxResults {
+		txResults[i] = tx.TxStatusData{
+			Chain:        string(chain),
+			Token:        string(token),
+			AddressIndex: r.AddressIndex,
+			FromAddress:  r.FromAddress,
+			TxHash:       r.TxHash,
+			Status:       r.Status,
+			Amount:       r.Amount,
+			Error:        r.Error,
+			Current:      i + 1,
+			Total:        len(result.TxResults),
+		}
+	}
+
+	deps.TxHub.Broadcast(tx.TxEvent{
+		Type: "tx_complete",
+		Data: tx.TxCompleteData{
+			Chain:        string(chain),
+			Token:        string(token),
+			SuccessCount: result.SuccessCount,
+			FailCount:    result.FailCount,
+			TotalSwept:   result.TotalSwept,
+			TxResults:    txResults,
+		},
+	})
+}
+
 // executeSweepBg dispatches to chain-specific execute logic and returns a unified result.
 // Called from a background goroutine with context.Background().
 func executeSweepBg(ctx context.Context, deps *SendDeps, req models.SendRequest, funded []models.AddressWithBalance, sweepID string) (*models.UnifiedSendResult, error) {
@@ -713,31 +821,50 @@ func executeBTCSweep(ctx context.Context, deps *SendDeps, req models.SendRequest
 		}
 	}
 
-	btcResult, err := deps.BTCService.Execute(ctx, addresses, req.Destination, 0, sweepID, req.ExpectedInputCount, req.ExpectedTotalSats)
+	btcResult, err := deps.BTCService.Execute(ctx, addresses, req.Destination, 0, req.FeeTarget, sweepID, req.ExpectedInputCount, req.ExpectedTotalSats, btcInputSelection(req))
 	if err != nil {
 		return nil, fmt.Errorf("BTC execute failed: %w", err)
 	}
 
-	// Build per-input results for display.
-	txResults := make([]models.TxResult, len(funded))
-	for i, f := range funded {
-		txResults[i] = models.TxResult{
+	// Build per-input results for display: each address takes the outcome of the
+	// consolidation transaction spending it.
+	chunkOf := make(map[int]models.BTCChunkResult)
+	for _, chunk := range btcResult.Chunks {
+		for _, idx := range chunk.AddressIndices {
+			// An address split across transactions reports the failed one, if any.
+			if prev, ok := chunkOf[idx]; !ok || prev.Status == "success" {
+				chunkOf[idx] = chunk
+			}
+		}
+	}
+
+	result := &models.UnifiedSendResult{
+		Chain:      req.Chain,
+		Token:      req.Token,
+		TxResults:  make([]models.TxResult, 0, len(funded)),
+		TotalSwept: strconv.FormatInt(btcResult.OutputSats, 10),
+	}
+	for _, f := range funded {
+		chunk, ok := chunkOf[f.AddressIndex]
+		if !ok {
+			continue // none of its UTXOs was selected
+		}
+		result.TxResults = append(result.TxResults, models.TxResult{
 			AddressIndex: f.AddressIndex,
 			FromAddress:  f.Address,
-			TxHash:       btcResult.TxHash,
+			TxHash:       chunk.TxHash,
 			Amount:       f.NativeBalance,
-			Status:       "success",
+			Status:       chunk.Status,
+			Error:        chunk.Error,
+		})
+		if chunk.Status == "success" {
+			result.SuccessCount++
+		} else {
+			result.FailCount++
 		}
 	}
 
-	return &models.UnifiedSendResult{
-		Chain:        req.Chain,
-		Token:        req.Token,
-		TxResults:    txResults,
-		SuccessCount: len(funded),
-		FailCount:    0,
-		TotalSwept:   strconv.FormatInt(btcResult.OutputSats, 10),
-	}, nil
+	return result, nil
 }
 
 // executeBSCNativeSweep executes a BSC native BNB sweep.
@@ -771,7 +898,7 @@ func executeBSCNativeSweep(ctx context.Context, deps *SendDeps, req models.SendR
 
 // executeBSCTokenSweep executes a BSC token (USDC/USDT) sweep.
 func executeBSCTokenSweep(ctx context.Context, deps *SendDeps, req models.SendRequest, funded []models.AddressWithBalance, sweepID string) (*models.UnifiedSendResult, error) {
-	contractAddr := getTokenContractAddress(req.Chain, req.Token, deps.Config.Network)
+	contractAddr := getTokenContractAddress(deps.DB, req.Chain, req.Token)
 
 	bscResult, err := deps.BSCService.ExecuteTokenSweep(ctx, funded, req.Destination, req.Token, contractAddr, sweepID, req.ExpectedGasPrice)
 	if err != nil {
@@ -831,7 +958,7 @@ func executeSOLNativeSweep(ctx context.Context, deps *SendDeps, req models.SendR
 
 // executeSOLTokenSweep executes a SOL token (USDC/USDT) sweep.
 func executeSOLTokenSweep(ctx context.Context, deps *SendDeps, req models.SendRequest, funded []models.AddressWithBalance, sweepID string) (*models.UnifiedSendResult, error) {
-	mint := getTokenContractAddress(req.Chain, req.Token, deps.Config.Network)
+	mint := getTokenContractAddress(deps.DB, req.Chain, req.Token)
 
 	solResult, err := deps.SOLService.ExecuteTokenSweep(ctx, funded, req.Destination, req.Token, mint, sweepID, req.FeePayerIndex)
 	if err != nil {
@@ -1203,6 +1330,25 @@ func ExecuteResume(deps *SendDeps) http.HandlerFunc {
 			return
 		}
 
+		// A sweep may only be resumed by a server running on the account that signed
+		// every transaction to retry, otherwise funded addresses and keys would come
+		// from another account.
+		for _, row := range retryable {
+			if row.Account == deps.DB.Account() {
+				continue
+			}
+			slog.Warn("resume rejected: sweep belongs to another account",
+				"sweepID", req.SweepID,
+				"txStateID", row.ID,
+				"sweepAccount", row.Account,
+				"serverAccount", deps.DB.Account(),
+			)
+			writeError(w, http.StatusConflict, config.ErrorAccountMismatch,
+				fmt.Sprintf("%s: sweep was created on account %d, server runs account %d",
+					config.ErrAccountMismatch.Error(), row.Account, deps.DB.Account()))
+			return
+		}
+
 		if len(retryable) == 0 {
 			slog.Info("no retryable transactions found", "sweepID", req.SweepID)
 			writeJSON(w, http.StatusOK, models.APIResponse{
@@ -1238,9 +1384,11 @@ func ExecuteResume(deps *SendDeps) http.HandlerFunc {
 		}
 
 		// Filter funded to only include addresses that need retry.
-		retryIndices := make(map[int]bool, len(retryable))
-		for _, rs := range retryable {
-			retryIndices[rs.AddressIndex] = true
+		retryIndices, err := retryAddressIndices(deps.DB, retryable)
+		if err != nil {
+			slog.Error("failed to fetch tx state inputs for resume", "sweepID", req.SweepID, "error", err)
+			writeError(w, http.StatusInternalServerError, config.ErrorDatabase, "failed to fetch retryable transactions")
+			return
 		}
 
 		var retryFunded []models.AddressWithBalance
@@ -1297,6 +1445,26 @@ func ExecuteResume(deps *SendDeps) http.HandlerFunc {
 	}
 }
 
+// retryAddressIndices returns the address indices a resume retries: those each
+// multi-input transaction (a BTC consolidation chunk) recorded as its inputs, and
+// the AddressIndex of every other transaction.
+func retryAddressIndices(database *db.DB, retryable []db.TxStateRow) (map[int]bool, error) {
+	indices := make(map[int]bool, len(retryable))
+	for _, rs := range retryable {
+		inputs, err := database.GetTxStateInputs(rs.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(inputs) == 0 {
+			indices[rs.AddressIndex] = true
+		}
+		for _, idx := range inputs {
+			indices[idx] = true
+		}
+	}
+	return indices, nil
+}
+
 // DismissTxState handles POST /api/send/dismiss/{id}.
 // Marks an uncertain TX as dismissed (user verified in explorer).
 func DismissTxState(deps *SendDeps) http.HandlerFunc {
@@ -182,6 +182,11 @@ func TestValidateDestination(t *testing.T) {
 }
 
 func TestIsValidToken(t *testing.T) {
+	database := setupSendTestDB(t)
+	if _, err := database.AddToken(models.TokenInfo{Chain: models.ChainBSC, Symbol: "CAKE", Contract: "0x0E09FaBB73Bd3Ade0a17ECC321fD13a19e81cE82", Decimals: 18}); err != nil {
+		t.Fatal(err)
+	}
+
 	tests := []struct {
 		name  string
 		chain models.Chain
@@ -197,16 +202,19 @@ func TestIsValidToken(t *testing.T) {
 		{"BSC NATIVE valid", models.ChainBSC, models.TokenNative, true},
 		{"BSC USDC valid", models.ChainBSC, models.TokenUSDC, true},
 		{"BSC USDT valid", models.ChainBSC, models.TokenUSDT, true},
+		{"BSC custom token valid", models.ChainBSC, "CAKE", true},
+		{"BSC unknown token invalid", models.ChainBSC, "DOGE", false},
 
 		// SOL
 		{"SOL NATIVE valid", models.ChainSOL, models.TokenNative, true},
 		{"SOL USDC valid", models.ChainSOL, models.TokenUSDC, true},
-		{"SOL USDT valid", models.ChainSOL, models.TokenUSDT, true},
+		{"SOL USDT invalid on testnet (no mint)", models.ChainSOL, models.TokenUSDT, false},
+		{"SOL token of another chain invalid", models.ChainSOL, "CAKE", false},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := isValidToken(tt.chain, tt.token)
+			got := isValidToken(database, tt.chain, tt.token)
 			if got != tt.want {
 				t.Errorf("isValidToken(%s, %s) = %v, want %v",
 					tt.chain, tt.token, got, tt.want)
@@ -255,6 +263,14 @@ func setupSendRouter(t *testing.T, deps *SendDeps) http.Handler {
 	r.Post("/api/send/preview", PreviewSend(deps))
 	r.Post("/api/send/execute", ExecuteSend(deps))
 	r.Post("/api/send/gas-preseed", GasPreSeedHandler(deps))
+	r.Post("/api/send/psbt/broadcast", BroadcastPSBT(deps))
+	r.Post("/api/send/bump", BumpFee(deps))
+	r.Post("/api/send/cpfp", BumpFeeCPFP(deps))
+	r.Post("/api/send/payout/preview", PreviewPayout(deps))
+	r.Post("/api/send/payout", PayoutBTC(deps))
+	r.Get("/api/send/payouts", ListPayouts(deps))
+	r.Post("/api/send/bundle/export", ExportBundle(deps))
+	r.Post("/api/send/bundle/broadcast", BroadcastBundle(deps))
 	r.Get("/api/send/sse", SendSSE(deps.TxHub))
 	r.Get("/api/send/pending", GetPendingTxStates(deps))
 	r.Get("/api/send/sweep/{sweepID}", GetSweepStatus(deps))
@@ -368,6 +384,72 @@ func TestPreviewSend_NoFundedAddresses(t *testing.T) {
 	assertErrorCode(t, w.Body.Bytes(), config.ErrorNoFundedAddresses)
 }
 
+func TestPreviewSend_InvalidInputSelection(t *testing.T) {
+	database := setupSendTestDB(t)
+	router := setupSendRouter(t, makeSendDeps(t, database))
+
+	for _, body := range []string{
+		`{"chain":"BSC","token":"NATIVE","destination":"0x0000000000000000000000000000000000000001","inputSelection":{"maxInputs":5}}`,
+		`{"chain":"BTC","token":"NATIVE","destination":"tb1qtk89me2ae95dmlp3yfl4q9ynpux8mxjujuf2fr","inputSelection":{"minValueSats":-1}}`,
+		`{"chain":"BTC","token":"NATIVE","destination":"tb1qtk89me2ae95dmlp3yfl4q9ynpux8mxjujuf2fr","inputSelection":{"addressIndices":[-3]}}`,
+	} {
+		req := httptest.NewRequest("POST", "/api/send/preview", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("body %s: status = %d, want 400", body, w.Code)
+			continue
+		}
+		assertErrorCode(t, w.Body.Bytes(), config.ErrorInvalidInputSelection)
+	}
+}
+
+func TestPreviewSend_InvalidFeeTarget(t *testing.T) {
+	database := setupSendTestDB(t)
+	router := setupSendRouter(t, makeSendDeps(t, database))
+
+	for _, body := range []string{
+		`{"chain":"BSC","token":"NATIVE","destination":"0x0000000000000000000000000000000000000001","feeTarget":"next_block"}`,
+		`{"chain":"BTC","token":"NATIVE","destination":"tb1qtk89me2ae95dmlp3yfl4q9ynpux8mxjujuf2fr","feeTarget":"2_blocks"}`,
+	} {
+		req := httptest.NewRequest("POST", "/api/send/preview", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("body %s: status = %d, want 400", body, w.Code)
+			continue
+		}
+		assertErrorCode(t, w.Body.Bytes(), config.ErrorInvalidFeeTarget)
+	}
+}
+
+func TestPreviewSend_InputSelectionMatchesNoFundedAddress(t *testing.T) {
+	database := setupSendTestDB(t)
+	router := setupSendRouter(t, makeSendDeps(t, database))
+
+	addrs := []models.Address{
+		{Chain: models.ChainBTC, AddressIndex: 0, Address: "tb1qtk89me2ae95dmlp3yfl4q9ynpux8mxjujuf2fr"},
+	}
+	if err := database.InsertAddressBatch(models.ChainBTC, addrs); err != nil {
+		t.Fatalf("InsertAddressBatch() error = %v", err)
+	}
+	if err := database.UpsertBalance(models.ChainBTC, 0, models.TokenNative, "50000"); err != nil {
+		t.Fatalf("UpsertBalance() error = %v", err)
+	}
+
+	body := `{"chain":"BTC","token":"NATIVE","destination":"tb1qtk89me2ae95dmlp3yfl4q9ynpux8mxjujuf2fr","inputSelection":{"addressIndices":[7]}}`
+	req := httptest.NewRequest("POST", "/api/send/preview", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400. body: %s", w.Code, w.Body.String())
+	}
+	assertErrorCode(t, w.Body.Bytes(), config.ErrorNoFundedAddresses)
+}
+
 func TestPreviewSend_CaseInsensitiveChain(t *testing.T) {
 	database := setupSendTestDB(t)
 	deps := makeSendDeps(t, database)
@@ -928,6 +1010,70 @@ func TestExecuteResume_NoRetryableTxs(t *testing.T) {
 	}
 }
 
+func TestExecuteResume_OtherAccountRejected(t *testing.T) {
+	database := setupSendTestDB(t)
+	deps := makeSendDeps(t, database.WithAccount(1))
+	router := setupSendRouter(t, deps)
+
+	// Failed sweep signed by account 0; this server runs account 1.
+	if err := database.CreateTxState(db.TxStateRow{
+		ID:           "resume-other-account",
+		SweepID:      "account0-sweep",
+		Chain:        "BTC",
+		Token:        "NATIVE",
+		AddressIndex: 0,
+		FromAddress:  "addr0",
+		ToAddress:    "tb1qtk89me2ae95dmlp3yfl4q9ynpux8mxjujuf2fr",
+		Amount:       "1000",
+		Status:       config.TxStateFailed,
+	}); err != nil {
+		t.Fatalf("CreateTxState() error = %v", err)
+	}
+
+	body := `{"sweepID":"account0-sweep"}`
+	req := httptest.NewRequest("POST", "/api/send/resume", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want 409. body: %s", w.Code, w.Body.String())
+	}
+	assertErrorCode(t, w.Body.Bytes(), config.ErrorAccountMismatch)
+
+	// A row of the server's account listed first does not hide a later one of
+	// another account.
+	for _, row := range []struct {
+		store *db.DB
+		id    string
+		index int
+	}{{database.WithAccount(1), "mixed-own", 0}, {database, "mixed-other", 5}} {
+		if err := row.store.CreateTxState(db.TxStateRow{
+			ID:           row.id,
+			SweepID:      "mixed-sweep",
+			Chain:        "BTC",
+			Token:        "NATIVE",
+			AddressIndex: row.index,
+			FromAddress:  "addr",
+			ToAddress:    "tb1qtk89me2ae95dmlp3yfl4q9ynpux8mxjujuf2fr",
+			Amount:       "1000",
+			Status:       config.TxStateFailed,
+		}); err != nil {
+			t.Fatalf("CreateTxState(%s) error = %v", row.id, err)
+		}
+	}
+
+	req = httptest.NewRequest("POST", "/api/send/resume", strings.NewReader(`{"sweepID":"mixed-sweep"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("mixed accounts: status = %d, want 409. body: %s", w.Code, w.Body.String())
+	}
+	assertErrorCode(t, w.Body.Bytes(), config.ErrorAccountMismatch)
+}
+
 func TestExecuteResume_ChainLockConflict(t *testing.T) {
 	database := setupSendTestDB(t)
 	deps := makeSendDeps(t, database)
@@ -978,6 +1124,36 @@ func TestExecuteResume_ChainLockConflict(t *testing.T) {
 	assertErrorCode(t, w.Body.Bytes(), config.ErrorSendBusy)
 }
 
+func TestRetryAddressIndices_ChunkInputs(t *testing.T) {
+	database := setupSendTestDB(t)
+
+	// A failed BTC consolidation chunk spending indices 3 and 5, and a failed
+	// single-address transaction from index 2.
+	for _, row := range []db.TxStateRow{
+		{ID: "chunk-failed", SweepID: "chunked-sweep", Chain: "BTC", Token: "NATIVE", FromAddress: "consolidated", ToAddress: "dest", Amount: "0", Status: config.TxStateFailed},
+		{ID: "single-failed", SweepID: "chunked-sweep", Chain: "BTC", Token: "NATIVE", AddressIndex: 2, FromAddress: "addr2", ToAddress: "dest", Amount: "0", Status: config.TxStateFailed},
+	} {
+		if err := database.CreateTxState(row); err != nil {
+			t.Fatalf("CreateTxState() error = %v", err)
+		}
+	}
+	if err := database.SetTxStateInputs("chunk-failed", []int{3, 5}); err != nil {
+		t.Fatal(err)
+	}
+
+	retryable, err := database.GetRetryableTxStates("chunked-sweep")
+	if err != nil {
+		t.Fatal(err)
+	}
+	indices, err := retryAddressIndices(database, retryable)
+	if err != nil {
+		t.Fatalf("retryAddressIndices() error = %v", err)
+	}
+	if len(indices) != 3 || !indices[2] || !indices[3] || !indices[5] {
+		t.Errorf("retryAddressIndices() = %v, want 2, 3 and 5", indices)
+	}
+}
+
 // --- SendSSE tests ---
 
 func TestSendSSE_Headers(t *testing.T) {
@@ -1052,6 +1228,21 @@ func TestSendSSE_ReceivesEvent(t *testing.T) {
 // --- getTokenContractAddress tests ---
 
 func TestGetTokenContractAddress(t *testing.T) {
+	databases := map[string]*db.DB{"testnet": setupSendTestDB(t)}
+	mainnet, err := db.New(filepath.Join(t.TempDir(), "mainnet.sqlite"), "mainnet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { mainnet.Close() })
+	if err := mainnet.RunMigrations(); err != nil {
+		t.Fatal(err)
+	}
+	databases["mainnet"] = mainnet
+
+	if _, err := mainnet.AddToken(models.TokenInfo{Chain: models.ChainSOL, Symbol: "JUP", Contract: "JUPyiwrYJFskUPiHa7hkeR8VUtAeFoSYbKedZNsDvCN", Decimals: 6}); err != nil {
+		t.Fatal(err)
+	}
+
 	tests := []struct {
 		name    string
 		chain   models.Chain
@@ -1067,13 +1258,15 @@ func TestGetTokenContractAddress(t *testing.T) {
 		{"SOL USDT mainnet", models.ChainSOL, models.TokenUSDT, "mainnet", config.SOLUSDTMint},
 		{"SOL USDC testnet", models.ChainSOL, models.TokenUSDC, "testnet", config.SOLTestnetUSDCMint},
 		{"SOL USDT testnet", models.ChainSOL, models.TokenUSDT, "testnet", config.SOLTestnetUSDTMint},
+		{"SOL custom token mainnet", models.ChainSOL, "JUP", "mainnet", "JUPyiwrYJFskUPiHa7hkeR8VUtAeFoSYbKedZNsDvCN"},
+		{"SOL custom token testnet (other network)", models.ChainSOL, "JUP", "testnet", ""},
 		{"BTC NATIVE (no contract)", models.ChainBTC, models.TokenNative, "mainnet", ""},
 		{"BSC NATIVE (no contract)", models.ChainBSC, models.TokenNative, "mainnet", ""},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := getTokenContractAddress(tt.chain, tt.token, tt.network)
+			got := getTokenContractAddress(databases[tt.network], tt.chain, tt.token)
 			if got != tt.want {
 				t.Errorf("getTokenContractAddress(%s, %s, %s) = %q, want %q",
 					tt.chain, tt.token, tt.network, got, tt.want)
@@ -1082,3 +1275,55 @@ func TestGetTokenContractAddress(t *testing.T) {
 	}
 }
 
+
+// --- RequireSigning tests ---
+
+func TestRequireSigning_WatchOnly(t *testing.T) {
+	database := setupSendTestDB(t)
+	deps := &SendDeps{
+		DB:        database,
+		Config:    &config.Config{Network: "testnet", WatchOnly: true},
+		NetParams: &chaincfg.TestNet3Params,
+		WatchOnly: true,
+	}
+
+	r := chi.NewRouter()
+	r.Route("/api/send", func(r chi.Router) {
+		r.Use(RequireSigning(deps))
+		r.Post("/preview", PreviewSend(deps))
+		r.Get("/pending", GetPendingTxStates(deps))
+	})
+
+	for _, tc := range []struct{ method, path string }{
+		{"POST", "/api/send/preview"},
+		{"GET", "/api/send/pending"},
+	} {
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(`{}`))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Fatalf("%s %s: status = %d, want 503", tc.method, tc.path, w.Code)
+		}
+		assertErrorCode(t, w.Body.Bytes(), config.ErrorSigningUnavailable)
+	}
+}
+
+func TestRequireSigning_FullMode(t *testing.T) {
+	database := setupSendTestDB(t)
+	deps := makeSendDeps(t, database)
+
+	r := chi.NewRouter()
+	r.Route("/api/send", func(r chi.Router) {
+		r.Use(RequireSigning(deps))
+		r.Get("/pending", GetPendingTxStates(deps))
+	})
+
+	req := httptest.NewRequest("GET", "/api/send/pending", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200. body: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/shared/price"
+	"github.com/Fantasim/hdpay/internal/wallet/db"
+	"github.com/Fantasim/hdpay/internal/wallet/tx"
+)
+
+var (
+	tokenSymbolRegex = regexp.MustCompile(fmt.Sprintf(`^[A-Z0-9]{%d,%d}$`, config.TokenSymbolMinLen, config.TokenSymbolMaxLen))
+	coinGeckoIDRegex = regexp.MustCompile(fmt.Sprintf(`^[a-z0-9-]{1,%d}$`, config.CoinGeckoIDMaxLen))
+)
+
+// tokenDeleted is the response of DELETE /api/settings/tokens/{chain}/{symbol}.
+type tokenDeleted struct {
+	Chain   models.Chain `json:"chain"`
+	Symbol  models.Token `json:"symbol"`
+	Deleted bool         `json:"deleted"`
+}
+
+// ListTokens handles GET /api/settings/tokens.
+// Returns the token registry: the built-in tokens, then custom tokens. An
+// optional ?chain= narrows it to one chain.
+func ListTokens(database *db.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		chain := models.Chain(strings.ToUpper(r.URL.Query().Get("chain")))
+		if chain != "" && !isValidChain(chain) {
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidChain, "invalid chain: "+string(chain))
+			return
+		}
+
+		tokens, err := database.ListTokens(chain)
+		if err != nil {
+			slog.Error("failed to list tokens", "error", err)
+			writeError(w, http.StatusInternalServerError, config.ErrorDatabase, "failed to list tokens")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, models.APIResponse{
+			Data: tokens,
+			Meta: &models.APIMeta{ExecutionTime: time.Since(start).Milliseconds()},
+		})
+	}
+}
+
+// AddToken handles POST /api/settings/tokens.
+// Registers a BEP-20 contract or SPL mint. From then on scans fetch its
+// balances, the portfolio prices it by its CoinGecko ID, and it can be swept.
+func AddToken(database *db.DB, ps *price.PriceService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		var req models.AddTokenRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			slog.Warn("invalid add token request body", "error", err)
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidToken, "invalid request body")
+			return
+		}
+
+		token, err := parseTokenRequest(req)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidToken, err.Error())
+			return
+		}
+		if token.CoinGeckoID != "" {
+			if err := ps.CheckTokenID(token.CoinGeckoID, string(token.Symbol)); err != nil {
+				writeError(w, http.StatusBadRequest, config.ErrorInvalidToken, err.Error())
+				return
+			}
+		}
+
+		added, err := database.AddToken(token)
+		if errors.Is(err, config.ErrTokenExists) {
+			writeError(w, http.StatusConflict, config.ErrorTokenExists, err.Error())
+			return
+		}
+		if err != nil {
+			slog.Error("failed to add token", "chain", token.Chain, "symbol", token.Symbol, "error", err)
+			writeError(w, http.StatusInternalServerError, config.ErrorDatabase, "failed to add token")
+			return
+		}
+		syncTokenPrices(database, ps)
+
+		writeJSON(w, http.StatusCreated, models.APIResponse{
+			Data: added,
+			Meta: &models.APIMeta{ExecutionTime: time.Since(start).Milliseconds()},
+		})
+	}
+}
+
+// DeleteToken handles DELETE /api/settings/tokens/{chain}/{symbol}.
+// Removes a custom token. Its stored balances are kept but no longer refreshed.
+func DeleteToken(database *db.DB, ps *price.PriceService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		chain := models.Chain(strings.ToUpper(chi.URLParam(r, "chain")))
+		symbol := models.Token(strings.ToUpper(chi.URLParam(r, "symbol")))
+		if !isValidChain(chain) {
+			writeError(w, http.StatusBadRequest, config.ErrorInvalidChain, "invalid chain: "+string(chain))
+			return
+		}
+
+		deleted, err := database.DeleteToken(chain, symbol)
+		if errors.Is(err, config.ErrBuiltinToken) {
+			writeError(w, http.StatusBadRequest, config.ErrorBuiltinToken, err.Error())
+			return
+		}
+		if err != nil {
+			slog.Error("failed to delete token", "chain", chain, "symbol", symbol, "error", err)
+			writeError(w, http.StatusInternalServerError, config.ErrorDatabase, "failed to delete token")
+			return
+		}
+		if !deleted {
+			writeError(w, http.StatusNotFound, config.ErrorTokenNotFound,
+				fmt.Sprintf("no custom token %s on %s", symbol, chain))
+			return
+		}
+		syncTokenPrices(database, ps)
+
+		writeJSON(w, http.StatusOK, models.APIResponse{
+			Data: tokenDeleted{Chain: chain, Symbol: symbol, Deleted: true},
+			Meta: &models.APIMeta{ExecutionTime: time.Since(start).Milliseconds()},
+		})
+	}
+}
+
+// parseTokenRequest validates a token registration and normalizes it: the
+// symbol is upper-cased, a BEP-20 contract is EIP-55 checksummed and the
+// CoinGecko ID is lower-cased.
+func parseTokenRequest(req models.AddTokenRequest) (models.TokenInfo, error) {
+	token := models.TokenInfo{
+		Chain:       models.Chain(strings.ToUpper(strings.TrimSpace(string(req.Chain)))),
+		Symbol:      models.Token(strings.ToUpper(strings.TrimSpace(req.Symbol))),
+		Contract:    strings.TrimSpace(req.Contract),
+		Decimals:    req.Decimals,
+		CoinGeckoID: strings.ToLower(strings.TrimSpace(req.CoinGeckoID)),
+	}
+
+	if !tokenSymbolRegex.MatchString(string(token.Symbol)) || token.Symbol == models.TokenNative {
+		return token, fmt.Errorf("symbol must be %d-%d letters or digits and not %s",
+			config.TokenSymbolMinLen, config.TokenSymbolMaxLen, models.TokenNative)
+	}
+	if token.Decimals < 0 || token.Decimals > config.TokenMaxDecimals {
+		return token, fmt.Errorf("decimals must be between 0 and %d", config.TokenMaxDecimals)
+	}
+	if token.CoinGeckoID != "" && !coinGeckoIDRegex.MatchString(token.CoinGeckoID) {
+		return token, fmt.Errorf("coingeckoId must be a CoinGecko coin ID (lowercase letters, digits and dashes)")
+	}
+
+	switch token.Chain {
+	case models.ChainBSC:
+		if !common.IsHexAddress(token.Contract) {
+			return token, fmt.Errorf("contract must be a 0x-prefixed BEP-20 contract address")
+		}
+		token.Contract = common.HexToAddress(token.Contract).Hex()
+	case models.ChainSOL:
+		if _, err := tx.SolPublicKeyFromBase58(token.Contract); err != nil {
+			return token, fmt.Errorf("contract must be a base58 SPL mint address")
+		}
+	default:
+		return token, fmt.Errorf("tokens can only be registered on %s and %s", models.ChainBSC, models.ChainSOL)
+	}
+	return token, nil
+}
+
+// isRegisteredToken reports whether token is NATIVE or a registry token of any
+// chain. List filters accept every symbol the wallet may hold balances of.
+func isRegisteredToken(database *db.DB, token models.Token) (bool, error) {
+	if token == models.TokenNative {
+		return true, nil
+	}
+	tokens, err := database.ListTokens("")
+	if err != nil {
+		return false, err
+	}
+	for _, t := range tokens {
+		if t.Symbol == token {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// syncTokenPrices hands the current token registry to the price service so that
+// token prices follow registry changes.
+func syncTokenPrices(database *db.DB, ps *price.PriceService) {
+	tokens, err := database.ListTokens("")
+	if err != nil {
+		slog.Error("failed to reload token registry for prices", "error", err)
+		return
+	}
+	ps.SetTokens(tokens)
+}
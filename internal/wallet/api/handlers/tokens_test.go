@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/shared/price"
+	"github.com/Fantasim/hdpay/internal/wallet/db"
+)
+
+func setupTokenRouter(database *db.DB) http.Handler {
+	ps := price.NewPriceServiceWithURL("http://127.0.0.1:0")
+	r := chi.NewRouter()
+	r.Get("/api/settings/tokens", ListTokens(database))
+	r.Post("/api/settings/tokens", AddToken(database, ps))
+	r.Delete("/api/settings/tokens/{chain}/{symbol}", DeleteToken(database, ps))
+	r.Get("/api/transactions", ListTransactions(database))
+	return r
+}
+
+func TestTokens_AddListDelete(t *testing.T) {
+	router := setupTokenRouter(setupTestDB(t))
+
+	// The symbol and CoinGecko ID are normalized and the contract checksummed.
+	body := `{"chain":"bsc","symbol":" cake ","contract":"0x0e09fabb73bd3ade0a17ecc321fd13a19e81ce82","decimals":18,"coingeckoId":"PancakeSwap-Token"}`
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/api/settings/tokens", strings.NewReader(body)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("add status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var addResp struct {
+		Data models.TokenInfo `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &addResp); err != nil {
+		t.Fatal(err)
+	}
+	want := models.TokenInfo{Chain: models.ChainBSC, Symbol: "CAKE", Contract: "0x0E09FaBB73Bd3Ade0a17ECC321fD13a19e81cE82", Decimals: 18, CoinGeckoID: "pancakeswap-token"}
+	addResp.Data.CreatedAt = ""
+	if addResp.Data != want {
+		t.Errorf("added = %+v, want %+v", addResp.Data, want)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/api/settings/tokens", strings.NewReader(body)))
+	if w.Code != http.StatusConflict {
+		t.Errorf("duplicate add status = %d, want 409", w.Code)
+	}
+	assertErrorCode(t, w.Body.Bytes(), config.ErrorTokenExists)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/settings/tokens?chain=BSC", nil))
+	var listResp struct {
+		Data []models.TokenInfo `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &listResp); err != nil {
+		t.Fatal(err)
+	}
+	if len(listResp.Data) != 3 || !listResp.Data[0].Builtin || listResp.Data[2].Symbol != "CAKE" {
+		t.Fatalf("list = %+v, want USDC, USDT, then CAKE", listResp.Data)
+	}
+
+	// List filters accept registered tokens.
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/transactions?token=cake", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("transactions?token=cake status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/settings/tokens/BSC/USDT", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("delete built-in status = %d, want 400", w.Code)
+	}
+	assertErrorCode(t, w.Body.Bytes(), config.ErrorBuiltinToken)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/settings/tokens/bsc/cake", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"deleted":true`) {
+		t.Errorf("delete status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/settings/tokens/bsc/cake", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("second delete status = %d, want 404", w.Code)
+	}
+	assertErrorCode(t, w.Body.Bytes(), config.ErrorTokenNotFound)
+}
+
+func TestTokens_InvalidRequest(t *testing.T) {
+	router := setupTokenRouter(setupTestDB(t))
+	contract := "0x0E09FaBB73Bd3Ade0a17ECC321fD13a19e81cE82"
+
+	for _, body := range []string{
+		"not json",
+		`{"chain":"BTC","symbol":"CAKE","contract":"` + contract + `","decimals":18}`,
+		`{"chain":"BSC","symbol":"NATIVE","contract":"` + contract + `","decimals":18}`,
+		`{"chain":"BSC","symbol":"C","contract":"` + contract + `","decimals":18}`,
+		`{"chain":"BSC","symbol":"CA-KE","contract":"` + contract + `","decimals":18}`,
+		`{"chain":"BSC","symbol":"CAKE","contract":"0x1234","decimals":18}`,
+		`{"chain":"BSC","symbol":"CAKE","contract":"` + contract + `","decimals":-1}`,
+		`{"chain":"BSC","symbol":"CAKE","contract":"` + contract + `","decimals":37}`,
+		`{"chain":"BSC","symbol":"CAKE","contract":"` + contract + `","decimals":18,"coingeckoId":"pancake swap"}`,
+		`{"chain":"SOL","symbol":"JUP","contract":"not-a-mint","decimals":6}`,
+		// A CoinGecko ID prices a single symbol.
+		`{"chain":"BSC","symbol":"CAKE","contract":"` + contract + `","decimals":18,"coingeckoId":"tether"}`,
+		`{"chain":"BSC","symbol":"BTC","contract":"` + contract + `","decimals":18,"coingeckoId":"wrapped-bitcoin"}`,
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("POST", "/api/settings/tokens", strings.NewReader(body)))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("add %.60s: status = %d, want 400", body, w.Code)
+			continue
+		}
+		assertErrorCode(t, w.Body.Bytes(), config.ErrorInvalidToken)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/transactions?token=DOGE", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("transactions?token=DOGE status = %d, want 400", w.Code)
+	}
+}
@@ -70,9 +70,15 @@ func ListTransactions(database *db.DB) http.HandlerFunc {
 		// Token filter.
 		token := strings.ToUpper(r.URL.Query().Get("token"))
 		if token != "" {
-			if token != "NATIVE" && token != "USDC" && token != "USDT" {
+			known, err := isRegisteredToken(database, models.Token(token))
+			if err != nil {
+				slog.Error("failed to list tokens for token filter", "error", err)
+				writeError(w, http.StatusInternalServerError, config.ErrorDatabase, "failed to list tokens")
+				return
+			}
+			if !known {
 				slog.Warn("invalid token parameter", "token", token)
-				writeError(w, http.StatusBadRequest, config.ErrorInvalidToken, "invalid token: "+token+", must be NATIVE, USDC, or USDT")
+				writeError(w, http.StatusBadRequest, config.ErrorInvalidToken, "invalid token: "+token+", must be NATIVE or a registered token")
 				return
 			}
 			t := models.Token(token)
@@ -82,9 +88,9 @@ func ListTransactions(database *db.DB) http.HandlerFunc {
 		// Status filter.
 		status := strings.ToLower(r.URL.Query().Get("status"))
 		if status != "" {
-			if status != "pending" && status != "confirmed" && status != "failed" {
+			if status != "pending" && status != "confirmed" && status != "failed" && status != config.TxStateSuperseded {
 				slog.Warn("invalid status parameter", "status", status)
-				writeError(w, http.StatusBadRequest, config.ErrorInvalidConfig, "invalid status: "+status+", must be pending, confirmed, or failed")
+				writeError(w, http.StatusBadRequest, config.ErrorInvalidConfig, "invalid status: "+status+", must be pending, confirmed, failed, or superseded")
 				return
 			}
 			filter.Status = &status
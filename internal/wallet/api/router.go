@@ -39,7 +39,14 @@ func NewRouter(database *db.DB, cfg *config.Config, sc *scanner.Scanner, hub *sc
 
 		// Address management
 		r.Get("/addresses/{chain}", handlers.ListAddresses(database))
-		r.Get("/addresses/{chain}/export", handlers.ExportAddresses(database))
+		r.Get("/addresses/{chain}/export", handlers.ExportAddresses(database, sendDeps.KeyService))
+		r.Post("/addresses/{chain}/extend", handlers.ExtendAddresses(database, sendDeps.KeyService))
+		r.Post("/addresses/{chain}/allocate", handlers.AllocateAddress(database))
+		r.Get("/addresses/{chain}/{index}", handlers.GetAddressMetadata(database))
+		r.Put("/addresses/{chain}/{index}", handlers.PutAddressMetadata(database))
+		r.Delete("/addresses/{chain}/{index}", handlers.DeleteAddressMetadata(database))
+		r.Post("/addresses/{chain}/{index}/sign-message", handlers.SignAddressMessage(database, sendDeps.KeyService))
+		r.Post("/addresses/{chain}/verify-message", handlers.VerifyAddressMessage(sendDeps.NetParams))
 
 		// Scanning
 		r.Post("/scan/start", handlers.StartScan(sc))
@@ -53,6 +60,11 @@ func NewRouter(database *db.DB, cfg *config.Config, sc *scanner.Scanner, hub *sc
 			r.Get("/portfolio", handlers.GetPortfolio(database, ps))
 		})
 
+		// BTC coin control
+		r.Get("/utxos/frozen", handlers.ListFrozenUTXOs(database))
+		r.Post("/utxos/frozen", handlers.FreezeUTXO(database))
+		r.Delete("/utxos/frozen/{txid}/{vout}", handlers.UnfreezeUTXO(database))
+
 		// Transaction History
 		r.Get("/transactions", handlers.ListTransactions(database))
 		r.Get("/transactions/{chain}", handlers.ListTransactions(database))
@@ -61,12 +73,24 @@ func NewRouter(database *db.DB, cfg *config.Config, sc *scanner.Scanner, hub *sc
 		r.Get("/settings", handlers.GetSettings(database, cfg))
 		r.Put("/settings", handlers.UpdateSettings(database))
 		r.Post("/settings/reset-balances", handlers.ResetBalancesHandler(database))
+		r.Get("/settings/tokens", handlers.ListTokens(database))
+		r.Post("/settings/tokens", handlers.AddToken(database, ps))
+		r.Delete("/settings/tokens/{chain}/{symbol}", handlers.DeleteToken(database, ps))
 
 		// Send / Transaction
 		r.Route("/send", func(r chi.Router) {
+			r.Use(handlers.RequireSigning(sendDeps))
 			r.Post("/preview", handlers.PreviewSend(sendDeps))
 			r.Post("/execute", handlers.ExecuteSend(sendDeps))
 			r.Post("/gas-preseed", handlers.GasPreSeedHandler(sendDeps))
+			r.Post("/psbt/broadcast", handlers.BroadcastPSBT(sendDeps))
+			r.Post("/bump", handlers.BumpFee(sendDeps))
+			r.Post("/cpfp", handlers.BumpFeeCPFP(sendDeps))
+			r.Post("/payout/preview", handlers.PreviewPayout(sendDeps))
+			r.Post("/payout", handlers.PayoutBTC(sendDeps))
+			r.Get("/payouts", handlers.ListPayouts(sendDeps))
+			r.Post("/bundle/export", handlers.ExportBundle(sendDeps))
+			r.Post("/bundle/broadcast", handlers.BroadcastBundle(sendDeps))
 			r.Get("/sse", handlers.SendSSE(sendDeps.TxHub))
 			r.Get("/pending", handlers.GetPendingTxStates(sendDeps))
 			r.Get("/sweep/{sweepID}", handlers.GetSweepStatus(sendDeps))
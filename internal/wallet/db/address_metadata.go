@@ -0,0 +1,245 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/Fantasim/hdpay/internal/shared/models"
+)
+
+// GetAddressMetadata returns the metadata of one address of the current network and
+// account. It wraps sql.ErrNoRows when the address has none.
+func (d *DB) GetAddressMetadata(chain models.Chain, index int) (*models.AddressMetadata, error) {
+	m := models.AddressMetadata{Chain: chain, AddressIndex: index}
+	var tags string
+	var metadata sql.NullString
+	err := d.conn.QueryRow(
+		`SELECT label, tags, external_id, metadata, created_at, updated_at
+		 FROM address_metadata WHERE chain = ? AND network = ? AND account = ? AND address_index = ?`,
+		string(chain), d.network, d.account, index,
+	).Scan(&m.Label, &tags, &m.ExternalID, &metadata, &m.CreatedAt, &m.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("get address metadata %s/%d: %w", chain, index, err)
+	}
+
+	if m.Tags, err = decodeTags(tags); err != nil {
+		return nil, fmt.Errorf("decode tags of %s/%d: %w", chain, index, err)
+	}
+	if metadata.Valid {
+		m.Metadata = json.RawMessage(metadata.String)
+	}
+	return &m, nil
+}
+
+// UpsertAddressMetadata stores the label, tags, external ID and free-form JSON of
+// an address, replacing any earlier metadata. created_at is kept on update.
+func (d *DB) UpsertAddressMetadata(m models.AddressMetadata) error {
+	tags := m.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	encodedTags, err := json.Marshal(tags)
+	if err != nil {
+		return fmt.Errorf("encode tags of %s/%d: %w", m.Chain, m.AddressIndex, err)
+	}
+	var metadata interface{}
+	if len(m.Metadata) > 0 {
+		metadata = string(m.Metadata)
+	}
+
+	_, err = d.conn.Exec(
+		`INSERT INTO address_metadata (chain, network, account, address_index, label, tags, external_id, metadata)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(chain, network, account, address_index) DO UPDATE SET
+		   label = excluded.label,
+		   tags = excluded.tags,
+		   external_id = excluded.external_id,
+		   metadata = excluded.metadata,
+		   updated_at = datetime('now')`,
+		string(m.Chain), d.network, d.account, m.AddressIndex, m.Label, string(encodedTags), m.ExternalID, metadata,
+	)
+	if err != nil {
+		return fmt.Errorf("upsert address metadata %s/%d: %w", m.Chain, m.AddressIndex, err)
+	}
+
+	slog.Debug("address metadata stored",
+		"chain", m.Chain,
+		"index", m.AddressIndex,
+		"tags", len(tags),
+	)
+
+	return nil
+}
+
+// DeleteAddressMetadata removes the metadata of an address and reports whether there
+// was any.
+func (d *DB) DeleteAddressMetadata(chain models.Chain, index int) (bool, error) {
+	result, err := d.conn.Exec(
+		"DELETE FROM address_metadata WHERE chain = ? AND network = ? AND account = ? AND address_index = ?",
+		string(chain), d.network, d.account, index,
+	)
+	if err != nil {
+		return false, fmt.Errorf("delete address metadata %s/%d: %w", chain, index, err)
+	}
+
+	affected, _ := result.RowsAffected()
+	slog.Debug("address metadata deleted", "chain", chain, "index", index, "rows", affected)
+	return affected > 0, nil
+}
+
+// GetAddressLabels returns the metadata (without the free-form JSON) of every
+// labelled address of chain, keyed by address index. Exports use it to annotate
+// streamed addresses without a per-row lookup.
+func (d *DB) GetAddressLabels(chain models.Chain) (map[int]models.AddressMetadata, error) {
+	rows, err := d.conn.Query(
+		`SELECT address_index, label, tags, external_id FROM address_metadata
+		 WHERE chain = ? AND network = ? AND account = ?`,
+		string(chain), d.network, d.account,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query address labels for %s: %w", chain, err)
+	}
+	defer rows.Close()
+
+	labels := make(map[int]models.AddressMetadata)
+	for rows.Next() {
+		m := models.AddressMetadata{Chain: chain}
+		var tags string
+		if err := rows.Scan(&m.AddressIndex, &m.Label, &tags, &m.ExternalID); err != nil {
+			return nil, fmt.Errorf("scan address label row: %w", err)
+		}
+		if m.Tags, err = decodeTags(tags); err != nil {
+			return nil, fmt.Errorf("decode tags of %s/%d: %w", chain, m.AddressIndex, err)
+		}
+		labels[m.AddressIndex] = m
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate address label rows: %w", err)
+	}
+	return labels, nil
+}
+
+// addressKey identifies an address of the current network and account.
+type addressKey struct {
+	chain models.Chain
+	index int
+}
+
+// addressKeysIn returns a "(chain, address_index) IN (...)" condition for keys and
+// its arguments.
+func addressKeysIn(keys []addressKey) (string, []interface{}) {
+	placeholders := make([]string, len(keys))
+	args := make([]interface{}, 0, len(keys)*2)
+	for i, k := range keys {
+		placeholders[i] = "(?, ?)"
+		args = append(args, string(k.chain), k.index)
+	}
+	return "(chain, address_index) IN (" + strings.Join(placeholders, ", ") + ")", args
+}
+
+// addressLookupBatchSize bounds the (chain, address_index) pairs per lookup query,
+// keeping it well below SQLite's bound-parameter limit.
+const addressLookupBatchSize = 1_000
+
+// lookupAddressLabels loads the label, tags and external ID of the given addresses
+// of the current account. Addresses without metadata are absent from the result.
+func (d *DB) lookupAddressLabels(keys []addressKey) (map[addressKey]models.AddressMetadata, error) {
+	labels := make(map[addressKey]models.AddressMetadata)
+	for start := 0; start < len(keys); start += addressLookupBatchSize {
+		end := min(start+addressLookupBatchSize, len(keys))
+		if err := d.lookupAddressLabelBatch(keys[start:end], labels); err != nil {
+			return nil, err
+		}
+	}
+	return labels, nil
+}
+
+// lookupAddressLabelBatch adds the metadata of one batch of keys to labels.
+func (d *DB) lookupAddressLabelBatch(keys []addressKey, labels map[addressKey]models.AddressMetadata) error {
+	in, keyArgs := addressKeysIn(keys)
+	rows, err := d.conn.Query(
+		"SELECT chain, address_index, label, tags, external_id FROM address_metadata WHERE network = ? AND account = ? AND "+in,
+		append([]interface{}{d.network, d.account}, keyArgs...)...,
+	)
+	if err != nil {
+		return fmt.Errorf("query address labels: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m models.AddressMetadata
+		var tags string
+		if err := rows.Scan(&m.Chain, &m.AddressIndex, &m.Label, &tags, &m.ExternalID); err != nil {
+			return fmt.Errorf("scan address label row: %w", err)
+		}
+		if m.Tags, err = decodeTags(tags); err != nil {
+			return fmt.Errorf("decode tags of %s/%d: %w", m.Chain, m.AddressIndex, err)
+		}
+		labels[addressKey{m.Chain, m.AddressIndex}] = m
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate address label rows: %w", err)
+	}
+	return nil
+}
+
+// hydrateLabels sets the label, tags and external ID of addresses from their metadata.
+func (d *DB) hydrateLabels(addresses []models.AddressWithBalance) error {
+	keys := make([]addressKey, len(addresses))
+	for i, a := range addresses {
+		keys[i] = addressKey{a.Chain, a.AddressIndex}
+	}
+
+	labels, err := d.lookupAddressLabels(keys)
+	if err != nil {
+		return err
+	}
+
+	for i := range addresses {
+		if m, ok := labels[keys[i]]; ok {
+			addresses[i].Label = m.Label
+			addresses[i].Tags = m.Tags
+			addresses[i].ExternalID = m.ExternalID
+		}
+	}
+	return nil
+}
+
+// hydrateTransactionLabels sets the label of the address each transaction belongs
+// to, within the current account.
+func (d *DB) hydrateTransactionLabels(txs []models.Transaction) error {
+	seen := make(map[addressKey]bool, len(txs))
+	var keys []addressKey
+	for _, tx := range txs {
+		k := addressKey{tx.Chain, tx.AddressIndex}
+		if !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+
+	labels, err := d.lookupAddressLabels(keys)
+	if err != nil {
+		return err
+	}
+
+	for i := range txs {
+		txs[i].Label = labels[addressKey{txs[i].Chain, txs[i].AddressIndex}].Label
+	}
+	return nil
+}
+
+// decodeTags parses the JSON array stored in address_metadata.tags.
+func decodeTags(encoded string) ([]string, error) {
+	tags := []string{}
+	if encoded == "" {
+		return tags, nil
+	}
+	if err := json.Unmarshal([]byte(encoded), &tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
@@ -0,0 +1,150 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/Fantasim/hdpay/internal/shared/models"
+)
+
+func TestAddressMetadataCRUD(t *testing.T) {
+	d := setupTestDB(t)
+	seedAddresses(t, d, models.ChainBSC, 3)
+
+	if _, err := d.GetAddressMetadata(models.ChainBSC, 1); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("GetAddressMetadata() before insert error = %v, want sql.ErrNoRows", err)
+	}
+
+	meta := models.AddressMetadata{
+		Chain:        models.ChainBSC,
+		AddressIndex: 1,
+		Label:        "Acme Inc.",
+		Tags:         []string{"customer", "vip"},
+		ExternalID:   "INV-1042",
+		Metadata:     json.RawMessage(`{"plan":"gold"}`),
+	}
+	if err := d.UpsertAddressMetadata(meta); err != nil {
+		t.Fatalf("UpsertAddressMetadata() error = %v", err)
+	}
+
+	got, err := d.GetAddressMetadata(models.ChainBSC, 1)
+	if err != nil {
+		t.Fatalf("GetAddressMetadata() error = %v", err)
+	}
+	if got.Label != "Acme Inc." || len(got.Tags) != 2 || got.Tags[1] != "vip" || got.ExternalID != "INV-1042" || string(got.Metadata) != `{"plan":"gold"}` {
+		t.Errorf("GetAddressMetadata() = %+v", got)
+	}
+
+	// Replacing clears fields left empty.
+	if err := d.UpsertAddressMetadata(models.AddressMetadata{Chain: models.ChainBSC, AddressIndex: 1, Label: "Acme"}); err != nil {
+		t.Fatalf("UpsertAddressMetadata() replace error = %v", err)
+	}
+	got, err = d.GetAddressMetadata(models.ChainBSC, 1)
+	if err != nil {
+		t.Fatalf("GetAddressMetadata() error = %v", err)
+	}
+	if got.Label != "Acme" || len(got.Tags) != 0 || got.ExternalID != "" || got.Metadata != nil {
+		t.Errorf("replaced metadata = %+v, want label only", got)
+	}
+
+	// Other accounts do not see it.
+	if _, err := d.WithAccount(1).GetAddressMetadata(models.ChainBSC, 1); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("account 1 GetAddressMetadata() error = %v, want sql.ErrNoRows", err)
+	}
+
+	deleted, err := d.DeleteAddressMetadata(models.ChainBSC, 1)
+	if err != nil || !deleted {
+		t.Fatalf("DeleteAddressMetadata() = %v, %v, want true", deleted, err)
+	}
+	if deleted, _ := d.DeleteAddressMetadata(models.ChainBSC, 1); deleted {
+		t.Error("second DeleteAddressMetadata() reported a deletion")
+	}
+}
+
+func TestGetAddressesWithBalances_MetadataFilters(t *testing.T) {
+	d := setupTestDB(t)
+	seedAddresses(t, d, models.ChainBTC, 5)
+
+	for _, m := range []models.AddressMetadata{
+		{Chain: models.ChainBTC, AddressIndex: 1, Label: "Alice", Tags: []string{"customer"}, ExternalID: "CUST-1"},
+		{Chain: models.ChainBTC, AddressIndex: 3, Label: "Bob", Tags: []string{"customer", "vip"}, ExternalID: "CUST-2"},
+	} {
+		if err := d.UpsertAddressMetadata(m); err != nil {
+			t.Fatalf("UpsertAddressMetadata() error = %v", err)
+		}
+	}
+
+	results, total, err := d.GetAddressesWithBalances(AddressFilter{Chain: models.ChainBTC, Page: 1, PageSize: 10, Tag: "customer"})
+	if err != nil {
+		t.Fatalf("GetAddressesWithBalances() error = %v", err)
+	}
+	if total != 2 || len(results) != 2 || results[0].Label != "Alice" || results[1].ExternalID != "CUST-2" {
+		t.Errorf("tag filter = %d %+v, want Alice and Bob", total, results)
+	}
+
+	results, total, err = d.GetAddressesWithBalances(AddressFilter{Chain: models.ChainBTC, Page: 1, PageSize: 10, Tag: "vip"})
+	if err != nil {
+		t.Fatalf("GetAddressesWithBalances() error = %v", err)
+	}
+	if total != 1 || results[0].AddressIndex != 3 {
+		t.Errorf("vip filter = %d %+v, want index 3", total, results)
+	}
+
+	results, total, err = d.GetAddressesWithBalances(AddressFilter{Chain: models.ChainBTC, Page: 1, PageSize: 10, ExternalID: "CUST-1"})
+	if err != nil {
+		t.Fatalf("GetAddressesWithBalances() error = %v", err)
+	}
+	if total != 1 || results[0].AddressIndex != 1 || len(results[0].Tags) != 1 {
+		t.Errorf("externalId filter = %d %+v, want index 1", total, results)
+	}
+
+	// Unfiltered pages carry labels only where set.
+	results, _, err = d.GetAddressesWithBalances(AddressFilter{Chain: models.ChainBTC, Page: 1, PageSize: 10})
+	if err != nil {
+		t.Fatalf("GetAddressesWithBalances() error = %v", err)
+	}
+	if len(results) != 5 || results[0].Label != "" || results[3].Label != "Bob" {
+		t.Errorf("unfiltered labels = %+v", results)
+	}
+}
+
+func TestAddressLabelsInFundedAndTransactions(t *testing.T) {
+	d := setupTestDB(t)
+	seedAddresses(t, d, models.ChainBTC, 3)
+
+	if err := d.UpsertAddressMetadata(models.AddressMetadata{Chain: models.ChainBTC, AddressIndex: 0, Label: "Treasury", ExternalID: "T-0"}); err != nil {
+		t.Fatalf("UpsertAddressMetadata() error = %v", err)
+	}
+	if err := d.UpsertBalance(models.ChainBTC, 0, models.TokenNative, "5000"); err != nil {
+		t.Fatalf("UpsertBalance() error = %v", err)
+	}
+
+	funded, err := d.GetFundedAddressesJoined(models.ChainBTC, models.TokenNative)
+	if err != nil {
+		t.Fatalf("GetFundedAddressesJoined() error = %v", err)
+	}
+	if len(funded) != 1 || funded[0].Label != "Treasury" || funded[0].ExternalID != "T-0" {
+		t.Errorf("funded = %+v, want labelled index 0", funded)
+	}
+
+	if _, err := d.InsertTransaction(testTransaction(models.ChainBTC)); err != nil {
+		t.Fatalf("InsertTransaction() error = %v", err)
+	}
+	txs, _, err := d.ListTransactions(nil, 1, 10)
+	if err != nil {
+		t.Fatalf("ListTransactions() error = %v", err)
+	}
+	if len(txs) != 1 || txs[0].Label != "Treasury" {
+		t.Errorf("transactions = %+v, want label Treasury", txs)
+	}
+
+	labels, err := d.GetAddressLabels(models.ChainBTC)
+	if err != nil {
+		t.Fatalf("GetAddressLabels() error = %v", err)
+	}
+	if len(labels) != 1 || labels[0].Label != "Treasury" {
+		t.Errorf("GetAddressLabels() = %+v", labels)
+	}
+}
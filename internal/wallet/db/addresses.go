@@ -2,6 +2,7 @@ package db
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -53,7 +54,7 @@ func (d *DB) insertBatch(addresses []models.Address) error {
 		return fmt.Errorf("begin transaction: %w", err)
 	}
 
-	stmt, err := tx.Prepare("INSERT INTO addresses (chain, network, address_index, address) VALUES (?, ?, ?, ?)")
+	stmt, err := tx.Prepare("INSERT INTO addresses (chain, network, account, address_index, address) VALUES (?, ?, ?, ?, ?)")
 	if err != nil {
 		tx.Rollback()
 		return fmt.Errorf("prepare insert statement: %w", err)
@@ -61,7 +62,7 @@ func (d *DB) insertBatch(addresses []models.Address) error {
 	defer stmt.Close()
 
 	for _, addr := range addresses {
-		if _, err := stmt.Exec(string(addr.Chain), d.network, addr.AddressIndex, addr.Address); err != nil {
+		if _, err := stmt.Exec(string(addr.Chain), d.network, d.account, addr.AddressIndex, addr.Address); err != nil {
 			tx.Rollback()
 			return fmt.Errorf("exec insert at index %d: %w", addr.AddressIndex, err)
 		}
@@ -77,7 +78,7 @@ func (d *DB) insertBatch(addresses []models.Address) error {
 // CountAddresses returns the number of addresses stored for a chain.
 func (d *DB) CountAddresses(chain models.Chain) (int, error) {
 	var count int
-	err := d.conn.QueryRow("SELECT COUNT(*) FROM addresses WHERE chain = ? AND network = ?", string(chain), d.network).Scan(&count)
+	err := d.conn.QueryRow("SELECT COUNT(*) FROM addresses WHERE chain = ? AND network = ? AND account = ?", string(chain), d.network, d.account).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("count addresses for %s: %w", chain, err)
 	}
@@ -86,13 +87,30 @@ func (d *DB) CountAddresses(chain models.Chain) (int, error) {
 	return count, nil
 }
 
+// MaxAddressIndex returns the highest address index stored for a chain, or -1 when
+// the chain has no addresses.
+func (d *DB) MaxAddressIndex(chain models.Chain) (int, error) {
+	var maxIndex sql.NullInt64
+	err := d.conn.QueryRow(
+		"SELECT MAX(address_index) FROM addresses WHERE chain = ? AND network = ? AND account = ?",
+		string(chain), d.network, d.account,
+	).Scan(&maxIndex)
+	if err != nil {
+		return 0, fmt.Errorf("max address index for %s: %w", chain, err)
+	}
+	if !maxIndex.Valid {
+		return -1, nil
+	}
+	return int(maxIndex.Int64), nil
+}
+
 // GetAddresses returns a paginated list of addresses for a chain.
 func (d *DB) GetAddresses(chain models.Chain, offset, limit int) ([]models.Address, error) {
 	slog.Debug("fetching addresses", "chain", chain, "offset", offset, "limit", limit)
 
 	rows, err := d.conn.Query(
-		"SELECT chain, address_index, address, created_at FROM addresses WHERE chain = ? AND network = ? ORDER BY address_index LIMIT ? OFFSET ?",
-		string(chain), d.network, limit, offset,
+		"SELECT chain, address_index, address, created_at FROM addresses WHERE chain = ? AND network = ? AND account = ? ORDER BY address_index LIMIT ? OFFSET ?",
+		string(chain), d.network, d.account, limit, offset,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("query addresses for %s: %w", chain, err)
@@ -121,7 +139,10 @@ type AddressFilter struct {
 	Page       int
 	PageSize   int
 	HasBalance bool
-	Token      string // "", "NATIVE", "USDC", "USDT"
+	Token      string // "", "NATIVE", or a registry token symbol
+	Tag        string // Only addresses whose metadata has this tag
+	ExternalID string // Only addresses whose metadata has this external ID
+	Allocated  *bool  // nil: all; true/false: only (un)allocated addresses
 }
 
 // GetAddressesWithBalances returns paginated addresses with their balance data.
@@ -134,26 +155,48 @@ func (d *DB) GetAddressesWithBalances(f AddressFilter) ([]models.AddressWithBala
 		"pageSize", f.PageSize,
 		"hasBalance", f.HasBalance,
 		"token", f.Token,
+		"tag", f.Tag,
+		"externalId", f.ExternalID,
+		"allocated", f.Allocated,
 		"offset", offset,
 	)
 
 	// Build WHERE clause
-	where := "a.chain = ? AND a.network = ?"
-	args := []interface{}{string(f.Chain), d.network}
+	where := "a.chain = ? AND a.network = ? AND a.account = ?"
+	args := []interface{}{string(f.Chain), d.network, d.account}
 
 	if f.HasBalance {
-		where += " AND EXISTS (SELECT 1 FROM balances b WHERE b.chain = a.chain AND b.network = a.network AND b.address_index = a.address_index AND b.balance != '0')"
+		where += " AND EXISTS (SELECT 1 FROM balances b WHERE b.chain = a.chain AND b.network = a.network AND b.account = a.account AND b.address_index = a.address_index AND b.balance != '0')"
 	}
 
 	if f.Token != "" {
 		if f.Token == "NATIVE" {
-			where += " AND EXISTS (SELECT 1 FROM balances b WHERE b.chain = a.chain AND b.network = a.network AND b.address_index = a.address_index AND b.token = 'NATIVE' AND b.balance != '0')"
+			where += " AND EXISTS (SELECT 1 FROM balances b WHERE b.chain = a.chain AND b.network = a.network AND b.account = a.account AND b.address_index = a.address_index AND b.token = 'NATIVE' AND b.balance != '0')"
 		} else {
-			where += " AND EXISTS (SELECT 1 FROM balances b WHERE b.chain = a.chain AND b.network = a.network AND b.address_index = a.address_index AND b.token = ? AND b.balance != '0')"
+			where += " AND EXISTS (SELECT 1 FROM balances b WHERE b.chain = a.chain AND b.network = a.network AND b.account = a.account AND b.address_index = a.address_index AND b.token = ? AND b.balance != '0')"
 			args = append(args, f.Token)
 		}
 	}
 
+	if f.Tag != "" {
+		where += " AND EXISTS (SELECT 1 FROM address_metadata m, json_each(m.tags) t WHERE m.chain = a.chain AND m.network = a.network AND m.account = a.account AND m.address_index = a.address_index AND t.value = ?)"
+		args = append(args, f.Tag)
+	}
+
+	if f.ExternalID != "" {
+		where += " AND EXISTS (SELECT 1 FROM address_metadata m WHERE m.chain = a.chain AND m.network = a.network AND m.account = a.account AND m.address_index = a.address_index AND m.external_id = ?)"
+		args = append(args, f.ExternalID)
+	}
+
+	if f.Allocated != nil {
+		allocated := "EXISTS (SELECT 1 FROM address_allocations x WHERE x.chain = a.chain AND x.network = a.network AND x.account = a.account AND x.address_index = a.address_index)"
+		if *f.Allocated {
+			where += " AND " + allocated
+		} else {
+			where += " AND NOT " + allocated
+		}
+	}
+
 	// Count total
 	var total int64
 	countQuery := "SELECT COUNT(*) FROM addresses a WHERE " + where
@@ -189,6 +232,12 @@ func (d *DB) GetAddressesWithBalances(f AddressFilter) ([]models.AddressWithBala
 	if err := d.hydrateBalances(results); err != nil {
 		return nil, 0, fmt.Errorf("hydrate balances: %w", err)
 	}
+	if err := d.hydrateLabels(results); err != nil {
+		return nil, 0, fmt.Errorf("hydrate labels: %w", err)
+	}
+	if err := d.hydrateAllocations(results); err != nil {
+		return nil, 0, fmt.Errorf("hydrate allocations: %w", err)
+	}
 
 	return results, total, nil
 }
@@ -199,16 +248,16 @@ func (d *DB) hydrateBalances(addresses []models.AddressWithBalance) error {
 		return nil
 	}
 
-	// Build (chain, address_index) IN clause, scoped to current network
+	// Build (chain, address_index) IN clause, scoped to current network and account
 	placeholders := make([]string, len(addresses))
-	args := make([]interface{}, 0, 1+len(addresses)*2)
-	args = append(args, d.network)
+	args := make([]interface{}, 0, 2+len(addresses)*2)
+	args = append(args, d.network, d.account)
 	for i, addr := range addresses {
 		placeholders[i] = "(?, ?)"
 		args = append(args, string(addr.Chain), addr.AddressIndex)
 	}
 
-	query := "SELECT chain, address_index, token, balance, last_scanned FROM balances WHERE network = ? AND (chain, address_index) IN (" + strings.Join(placeholders, ", ") + ")"
+	query := "SELECT chain, address_index, token, balance, last_scanned FROM balances WHERE network = ? AND account = ? AND (chain, address_index) IN (" + strings.Join(placeholders, ", ") + ")"
 
 	rows, err := d.conn.Query(query, args...)
 	if err != nil {
@@ -274,8 +323,8 @@ func (d *DB) hydrateBalances(addresses []models.AddressWithBalance) error {
 func (d *DB) GetAddressByIndex(chain models.Chain, index int) (*models.Address, error) {
 	var addr models.Address
 	err := d.conn.QueryRow(
-		"SELECT chain, address_index, address, created_at FROM addresses WHERE chain = ? AND network = ? AND address_index = ?",
-		string(chain), d.network, index,
+		"SELECT chain, address_index, address, created_at FROM addresses WHERE chain = ? AND network = ? AND account = ? AND address_index = ?",
+		string(chain), d.network, d.account, index,
 	).Scan(&addr.Chain, &addr.AddressIndex, &addr.Address, &addr.CreatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("get address %s/%d: %w", chain, index, err)
@@ -284,9 +333,43 @@ func (d *DB) GetAddressByIndex(chain models.Chain, index int) (*models.Address,
 	return &addr, nil
 }
 
+// LookupAddress returns the stored address of chain with the given encoding, so
+// its index can be recovered from an address alone. It wraps sql.ErrNoRows when
+// the address is not one of ours.
+func (d *DB) LookupAddress(chain models.Chain, address string) (*models.Address, error) {
+	var addr models.Address
+	err := d.conn.QueryRow(
+		"SELECT chain, address_index, address, created_at FROM addresses WHERE chain = ? AND network = ? AND account = ? AND address = ?",
+		string(chain), d.network, d.account, address,
+	).Scan(&addr.Chain, &addr.AddressIndex, &addr.Address, &addr.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("lookup %s address %s: %w", chain, address, err)
+	}
+
+	return &addr, nil
+}
+
+// FirstAddressCreatedAt returns when the index-0 address of chain was stored, or the
+// zero time when the chain has no addresses.
+func (d *DB) FirstAddressCreatedAt(chain models.Chain) (time.Time, error) {
+	addr, err := d.GetAddressByIndex(chain, 0)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	createdAt, err := time.Parse(time.DateTime, addr.CreatedAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse created_at of %s index 0: %w", chain, err)
+	}
+	return createdAt, nil
+}
+
 // DeleteAddresses deletes all addresses for a chain.
 func (d *DB) DeleteAddresses(chain models.Chain) error {
-	result, err := d.conn.Exec("DELETE FROM addresses WHERE chain = ? AND network = ?", string(chain), d.network)
+	result, err := d.conn.Exec("DELETE FROM addresses WHERE chain = ? AND network = ? AND account = ?", string(chain), d.network, d.account)
 	if err != nil {
 		return fmt.Errorf("delete addresses for %s: %w", chain, err)
 	}
@@ -299,8 +382,8 @@ func (d *DB) DeleteAddresses(chain models.Chain) error {
 // StreamAddresses streams all addresses for a chain via a callback, avoiding loading all into memory.
 func (d *DB) StreamAddresses(chain models.Chain, fn func(addr models.Address) error) error {
 	rows, err := d.conn.Query(
-		"SELECT chain, address_index, address, created_at FROM addresses WHERE chain = ? AND network = ? ORDER BY address_index",
-		string(chain), d.network,
+		"SELECT chain, address_index, address, created_at FROM addresses WHERE chain = ? AND network = ? AND account = ? ORDER BY address_index",
+		string(chain), d.network, d.account,
 	)
 	if err != nil {
 		return fmt.Errorf("query addresses for streaming %s: %w", chain, err)
@@ -319,3 +402,64 @@ func (d *DB) StreamAddresses(chain models.Chain, fn func(addr models.Address) er
 
 	return rows.Err()
 }
+
+// StreamAddressesWithBalances streams all addresses for a chain joined with their
+// stored balances, in index order. NativeBalance is "0" for never-scanned addresses;
+// TokenBalances only lists tokens that have a balance row, by symbol.
+func (d *DB) StreamAddressesWithBalances(chain models.Chain, fn func(addr models.AddressWithBalance) error) error {
+	rows, err := d.conn.Query(
+		`SELECT a.chain, a.address_index, a.address, b.token, b.balance, b.last_scanned
+		FROM addresses a
+		LEFT JOIN balances b ON b.chain = a.chain AND b.network = a.network AND b.account = a.account AND b.address_index = a.address_index
+		WHERE a.chain = ? AND a.network = ? AND a.account = ?
+		ORDER BY a.address_index, b.token`,
+		string(chain), d.network, d.account,
+	)
+	if err != nil {
+		return fmt.Errorf("query addresses with balances for streaming %s: %w", chain, err)
+	}
+	defer rows.Close()
+
+	// Rows come one per balance; an address is emitted once its last row is read.
+	var current *models.AddressWithBalance
+	for rows.Next() {
+		var addr models.AddressWithBalance
+		var token, balance, lastScanned sql.NullString
+		if err := rows.Scan(&addr.Chain, &addr.AddressIndex, &addr.Address, &token, &balance, &lastScanned); err != nil {
+			return fmt.Errorf("scan address with balances row during streaming: %w", err)
+		}
+
+		if current == nil || current.AddressIndex != addr.AddressIndex {
+			if current != nil {
+				if err := fn(*current); err != nil {
+					return fmt.Errorf("stream callback error: %w", err)
+				}
+			}
+			addr.NativeBalance = "0"
+			addr.TokenBalances = []models.TokenBalanceItem{}
+			current = &addr
+		}
+
+		if token.Valid && balance.Valid {
+			if models.Token(token.String) == models.TokenNative {
+				current.NativeBalance = balance.String
+			} else {
+				current.TokenBalances = append(current.TokenBalances, models.TokenBalanceItem{Symbol: models.Token(token.String), Balance: balance.String})
+			}
+		}
+		if lastScanned.Valid && (current.LastScanned == nil || lastScanned.String > *current.LastScanned) {
+			scanned := lastScanned.String
+			current.LastScanned = &scanned
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if current != nil {
+		if err := fn(*current); err != nil {
+			return fmt.Errorf("stream callback error: %w", err)
+		}
+	}
+	return nil
+}
@@ -1,6 +1,8 @@
 package db
 
 import (
+	"database/sql"
+	"errors"
 	"path/filepath"
 	"testing"
 
@@ -277,6 +279,26 @@ func TestInsertAddressBatch_LargeBatch(t *testing.T) {
 	}
 }
 
+func TestLookupAddress(t *testing.T) {
+	d := setupTestDB(t)
+	seedAddresses(t, d, models.ChainBTC, 5)
+
+	addr, err := d.LookupAddress(models.ChainBTC, "addr_BTC_3")
+	if err != nil {
+		t.Fatalf("LookupAddress() error = %v", err)
+	}
+	if addr.AddressIndex != 3 {
+		t.Errorf("LookupAddress() index = %d, want 3", addr.AddressIndex)
+	}
+
+	if _, err := d.LookupAddress(models.ChainBSC, "addr_BTC_3"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("LookupAddress() on another chain error = %v, want sql.ErrNoRows", err)
+	}
+	if _, err := d.WithAccount(1).LookupAddress(models.ChainBTC, "addr_BTC_3"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("LookupAddress() on another account error = %v, want sql.ErrNoRows", err)
+	}
+}
+
 func TestGetAddressesWithBalances_EmptyChain(t *testing.T) {
 	d := setupTestDB(t)
 
@@ -295,3 +317,79 @@ func TestGetAddressesWithBalances_EmptyChain(t *testing.T) {
 		t.Errorf("results = %v, want nil", results)
 	}
 }
+
+func TestStreamAddressesWithBalances(t *testing.T) {
+	d := setupTestDB(t)
+	seedAddresses(t, d, models.ChainBSC, 4)
+
+	if err := d.UpsertBalance(models.ChainBSC, 1, models.TokenNative, "42"); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.UpsertBalance(models.ChainBSC, 1, models.TokenUSDT, "7"); err != nil {
+		t.Fatal(err)
+	}
+	// Custom registry tokens are streamed like the built-in ones.
+	if err := d.UpsertBalance(models.ChainBSC, 1, models.Token("CAKE"), "3"); err != nil {
+		t.Fatal(err)
+	}
+	// Other accounts' balances must not leak into this account's stream.
+	if err := d.WithAccount(1).UpsertBalance(models.ChainBSC, 2, models.TokenNative, "99"); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []models.AddressWithBalance
+	err := d.StreamAddressesWithBalances(models.ChainBSC, func(addr models.AddressWithBalance) error {
+		got = append(got, addr)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamAddressesWithBalances() error = %v", err)
+	}
+
+	if len(got) != 4 {
+		t.Fatalf("streamed %d addresses, want 4", len(got))
+	}
+	for i, addr := range got {
+		if addr.AddressIndex != i {
+			t.Errorf("row %d has index %d", i, addr.AddressIndex)
+		}
+	}
+	if got[1].NativeBalance != "42" || len(got[1].TokenBalances) != 2 ||
+		got[1].TokenBalances[0] != (models.TokenBalanceItem{Symbol: "CAKE", Balance: "3"}) ||
+		got[1].TokenBalances[1] != (models.TokenBalanceItem{Symbol: models.TokenUSDT, Balance: "7"}) {
+		t.Errorf("index 1 = %+v, want native 42, CAKE 3 and USDT 7", got[1])
+	}
+	if got[1].LastScanned == nil {
+		t.Error("index 1 lastScanned should be set")
+	}
+	if got[2].NativeBalance != "0" || len(got[2].TokenBalances) != 0 || got[2].LastScanned != nil {
+		t.Errorf("index 2 = %+v, want no balances", got[2])
+	}
+}
+
+func TestMaxAddressIndex(t *testing.T) {
+	d := setupTestDB(t)
+
+	got, err := d.MaxAddressIndex(models.ChainSOL)
+	if err != nil {
+		t.Fatalf("MaxAddressIndex() error = %v", err)
+	}
+	if got != -1 {
+		t.Errorf("MaxAddressIndex(empty) = %d, want -1", got)
+	}
+
+	addresses := []models.Address{
+		{Chain: models.ChainSOL, AddressIndex: 0, Address: "sol0"},
+		{Chain: models.ChainSOL, AddressIndex: 7, Address: "sol7"},
+	}
+	if err := d.InsertAddressBatch(models.ChainSOL, addresses); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _ := d.MaxAddressIndex(models.ChainSOL); got != 7 {
+		t.Errorf("MaxAddressIndex() = %d, want 7", got)
+	}
+	if got, _ := d.WithAccount(1).MaxAddressIndex(models.ChainSOL); got != -1 {
+		t.Errorf("MaxAddressIndex(account 1) = %d, want -1", got)
+	}
+}
@@ -0,0 +1,174 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+)
+
+// AllocateAddress hands out the lowest-index address of chain that was never
+// allocated, holds no balance and has no recorded transaction, and records it as
+// allocated to allocatedTo. Allocations are permanent, so an address is never
+// handed out twice.
+//
+// With an idempotencyKey, a repeated call returns the original allocation instead
+// of a new one (created is then false); reusing the key for a different
+// allocatedTo fails with config.ErrIdempotencyConflict. When every stored address
+// is taken it fails with config.ErrNoAddressAvailable.
+//
+// The lookup and insert run in one transaction; with the single pooled connection
+// concurrent allocations are serialized.
+func (d *DB) AllocateAddress(chain models.Chain, allocatedTo, idempotencyKey string) (alloc *models.AddressAllocation, created bool, err error) {
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return nil, false, fmt.Errorf("begin allocation: %w", err)
+	}
+	defer tx.Rollback()
+
+	if idempotencyKey != "" {
+		existing, err := scanAllocation(tx.QueryRow(
+			allocationSelect+" WHERE x.chain = ? AND x.network = ? AND x.account = ? AND x.idempotency_key = ?",
+			string(chain), d.network, d.account, idempotencyKey,
+		))
+		if err == nil {
+			if existing.AllocatedTo != allocatedTo {
+				return nil, false, fmt.Errorf("%w: key %q was used for %q", config.ErrIdempotencyConflict, idempotencyKey, existing.AllocatedTo)
+			}
+			slog.Info("address allocation replayed",
+				"chain", chain,
+				"index", existing.AddressIndex,
+				"idempotencyKey", idempotencyKey,
+			)
+			return existing, false, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, false, fmt.Errorf("look up idempotency key: %w", err)
+		}
+	}
+
+	var index int
+	err = tx.QueryRow(
+		`SELECT a.address_index FROM addresses a
+		 WHERE a.chain = ? AND a.network = ? AND a.account = ?
+		   AND NOT EXISTS (SELECT 1 FROM address_allocations x WHERE x.chain = a.chain AND x.network = a.network AND x.account = a.account AND x.address_index = a.address_index)
+		   AND NOT EXISTS (SELECT 1 FROM balances b WHERE b.chain = a.chain AND b.network = a.network AND b.account = a.account AND b.address_index = a.address_index AND b.balance != '0')
+		   AND NOT EXISTS (SELECT 1 FROM transactions t WHERE t.account = a.account AND t.chain = a.chain AND t.network = a.network AND t.address_index = a.address_index)
+		 ORDER BY a.address_index LIMIT 1`,
+		string(chain), d.network, d.account,
+	).Scan(&index)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, fmt.Errorf("%w: every stored %s address is allocated or in use", config.ErrNoAddressAvailable, chain)
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("find next unallocated %s address: %w", chain, err)
+	}
+
+	var key interface{}
+	if idempotencyKey != "" {
+		key = idempotencyKey
+	}
+	if _, err := tx.Exec(
+		`INSERT INTO address_allocations (chain, network, account, address_index, allocated_to, idempotency_key)
+		 VALUES (?, ?, ?, ?, ?, ?)`,
+		string(chain), d.network, d.account, index, allocatedTo, key,
+	); err != nil {
+		return nil, false, fmt.Errorf("record allocation of %s/%d: %w", chain, index, err)
+	}
+
+	alloc, err = scanAllocation(tx.QueryRow(
+		allocationSelect+" WHERE x.chain = ? AND x.network = ? AND x.account = ? AND x.address_index = ?",
+		string(chain), d.network, d.account, index,
+	))
+	if err != nil {
+		return nil, false, fmt.Errorf("read allocation of %s/%d: %w", chain, index, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, false, fmt.Errorf("commit allocation: %w", err)
+	}
+
+	slog.Info("address allocated",
+		"chain", chain,
+		"index", index,
+		"allocatedTo", allocatedTo,
+		"idempotent", idempotencyKey != "",
+	)
+
+	return alloc, true, nil
+}
+
+// GetAddressAllocation returns the allocation of an address of the current network
+// and account. It wraps sql.ErrNoRows when the address was never allocated.
+func (d *DB) GetAddressAllocation(chain models.Chain, index int) (*models.AddressAllocation, error) {
+	alloc, err := scanAllocation(d.conn.QueryRow(
+		allocationSelect+" WHERE x.chain = ? AND x.network = ? AND x.account = ? AND x.address_index = ?",
+		string(chain), d.network, d.account, index,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("get allocation %s/%d: %w", chain, index, err)
+	}
+	return alloc, nil
+}
+
+// allocationSelect selects an allocation joined with its address; callers append
+// the WHERE clause on x.
+const allocationSelect = `SELECT x.chain, x.address_index, a.address, x.allocated_to, x.idempotency_key, x.allocated_at
+	FROM address_allocations x
+	JOIN addresses a ON a.chain = x.chain AND a.network = x.network AND a.account = x.account AND a.address_index = x.address_index`
+
+// scanAllocation scans one allocationSelect row.
+func scanAllocation(row *sql.Row) (*models.AddressAllocation, error) {
+	var alloc models.AddressAllocation
+	var key sql.NullString
+	if err := row.Scan(&alloc.Chain, &alloc.AddressIndex, &alloc.Address, &alloc.AllocatedTo, &key, &alloc.AllocatedAt); err != nil {
+		return nil, err
+	}
+	alloc.IdempotencyKey = key.String
+	return &alloc, nil
+}
+
+// hydrateAllocations sets the allocation status of addresses.
+func (d *DB) hydrateAllocations(addresses []models.AddressWithBalance) error {
+	keys := make([]addressKey, len(addresses))
+	for i, a := range addresses {
+		keys[i] = addressKey{a.Chain, a.AddressIndex}
+	}
+
+	allocations := make(map[addressKey]models.AddressAllocation, len(keys))
+	for start := 0; start < len(keys); start += addressLookupBatchSize {
+		end := min(start+addressLookupBatchSize, len(keys))
+		in, keyArgs := addressKeysIn(keys[start:end])
+		rows, err := d.conn.Query(
+			"SELECT chain, address_index, allocated_to, allocated_at FROM address_allocations WHERE network = ? AND account = ? AND "+in,
+			append([]interface{}{d.network, d.account}, keyArgs...)...,
+		)
+		if err != nil {
+			return fmt.Errorf("query allocations: %w", err)
+		}
+		for rows.Next() {
+			var a models.AddressAllocation
+			if err := rows.Scan(&a.Chain, &a.AddressIndex, &a.AllocatedTo, &a.AllocatedAt); err != nil {
+				rows.Close()
+				return fmt.Errorf("scan allocation row: %w", err)
+			}
+			allocations[addressKey{a.Chain, a.AddressIndex}] = a
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("iterate allocation rows: %w", err)
+		}
+	}
+
+	for i := range addresses {
+		if a, ok := allocations[keys[i]]; ok {
+			addresses[i].Allocated = true
+			addresses[i].AllocatedTo = a.AllocatedTo
+			addresses[i].AllocatedAt = a.AllocatedAt
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package db
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+)
+
+func TestAllocateAddress(t *testing.T) {
+	d := setupTestDB(t)
+	seedAddresses(t, d, models.ChainBTC, 5)
+
+	// Index 0 is funded and index 1 has a recorded transaction: both are skipped.
+	if err := d.UpsertBalance(models.ChainBTC, 0, models.TokenNative, "1000"); err != nil {
+		t.Fatalf("UpsertBalance() error = %v", err)
+	}
+	tx := testTransaction(models.ChainBTC)
+	tx.AddressIndex = 1
+	if _, err := d.InsertTransaction(tx); err != nil {
+		t.Fatalf("InsertTransaction() error = %v", err)
+	}
+
+	// Transactions of another account do not make an address used.
+	tx.AddressIndex = 2
+	if _, err := d.WithAccount(1).InsertTransaction(tx); err != nil {
+		t.Fatalf("InsertTransaction(account 1) error = %v", err)
+	}
+
+	alloc, created, err := d.AllocateAddress(models.ChainBTC, "customer-42", "")
+	if err != nil {
+		t.Fatalf("AllocateAddress() error = %v", err)
+	}
+	if !created || alloc.AddressIndex != 2 || alloc.Address != "addr_BTC_2" || alloc.AllocatedTo != "customer-42" || alloc.AllocatedAt == "" {
+		t.Errorf("AllocateAddress() = %+v, %v, want index 2 created", alloc, created)
+	}
+
+	next, _, err := d.AllocateAddress(models.ChainBTC, "customer-43", "")
+	if err != nil {
+		t.Fatalf("second AllocateAddress() error = %v", err)
+	}
+	if next.AddressIndex != 3 {
+		t.Errorf("second allocation index = %d, want 3", next.AddressIndex)
+	}
+
+	got, err := d.GetAddressAllocation(models.ChainBTC, 2)
+	if err != nil || got.AllocatedTo != "customer-42" {
+		t.Errorf("GetAddressAllocation() = %+v, %v", got, err)
+	}
+}
+
+func TestAllocateAddress_Idempotency(t *testing.T) {
+	d := setupTestDB(t)
+	seedAddresses(t, d, models.ChainSOL, 3)
+
+	first, created, err := d.AllocateAddress(models.ChainSOL, "invoice-7", "key-1")
+	if err != nil || !created {
+		t.Fatalf("AllocateAddress() = %v, %v", created, err)
+	}
+
+	again, created, err := d.AllocateAddress(models.ChainSOL, "invoice-7", "key-1")
+	if err != nil {
+		t.Fatalf("replayed AllocateAddress() error = %v", err)
+	}
+	if created || again.AddressIndex != first.AddressIndex || again.IdempotencyKey != "key-1" {
+		t.Errorf("replay = %+v, %v, want original index %d", again, created, first.AddressIndex)
+	}
+
+	if _, _, err := d.AllocateAddress(models.ChainSOL, "invoice-8", "key-1"); !errors.Is(err, config.ErrIdempotencyConflict) {
+		t.Errorf("key reuse error = %v, want ErrIdempotencyConflict", err)
+	}
+
+	// Keys are per chain and account.
+	if _, created, err := d.WithAccount(1).AllocateAddress(models.ChainSOL, "invoice-7", "key-1"); !errors.Is(err, config.ErrNoAddressAvailable) || created {
+		t.Errorf("account 1 AllocateAddress() error = %v, want ErrNoAddressAvailable (no addresses)", err)
+	}
+}
+
+func TestAllocateAddress_ConcurrentNeverDuplicates(t *testing.T) {
+	d := setupTestDB(t)
+	seedAddresses(t, d, models.ChainBSC, 10)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	seen := make(map[int]bool)
+	failures := 0
+	for i := 0; i < 12; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			alloc, _, err := d.AllocateAddress(models.ChainBSC, "customer", "")
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if !errors.Is(err, config.ErrNoAddressAvailable) {
+					t.Errorf("AllocateAddress() error = %v", err)
+				}
+				failures++
+				return
+			}
+			if seen[alloc.AddressIndex] {
+				t.Errorf("index %d allocated twice", alloc.AddressIndex)
+			}
+			seen[alloc.AddressIndex] = true
+		}()
+	}
+	wg.Wait()
+
+	if len(seen) != 10 || failures != 2 {
+		t.Errorf("allocated %d, failed %d, want 10 and 2", len(seen), failures)
+	}
+
+	allocated := true
+	results, total, err := d.GetAddressesWithBalances(AddressFilter{Chain: models.ChainBSC, Page: 1, PageSize: 20, Allocated: &allocated})
+	if err != nil {
+		t.Fatalf("GetAddressesWithBalances() error = %v", err)
+	}
+	if total != 10 || !results[0].Allocated || results[0].AllocatedTo != "customer" {
+		t.Errorf("allocated filter = %d %+v", total, results)
+	}
+}
@@ -15,10 +15,10 @@ func (d *DB) UpsertBalance(chain models.Chain, addressIndex int, token models.To
 	now := time.Now().UTC().Format(time.RFC3339)
 
 	_, err := d.conn.Exec(
-		`INSERT INTO balances (chain, network, address_index, token, balance, last_scanned)
-		 VALUES (?, ?, ?, ?, ?, ?)
-		 ON CONFLICT(chain, network, address_index, token) DO UPDATE SET balance = excluded.balance, last_scanned = excluded.last_scanned`,
-		string(chain), d.network, addressIndex, string(token), balance, now,
+		`INSERT INTO balances (chain, network, account, address_index, token, balance, last_scanned)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(chain, network, account, address_index, token) DO UPDATE SET balance = excluded.balance, last_scanned = excluded.last_scanned`,
+		string(chain), d.network, d.account, addressIndex, string(token), balance, now,
 	)
 	if err != nil {
 		return fmt.Errorf("upsert balance %s/%d/%s: %w", chain, addressIndex, token, err)
@@ -49,9 +49,9 @@ func (d *DB) UpsertBalanceBatch(balances []models.Balance) error {
 	defer tx.Rollback() // No-op after successful commit.
 
 	stmt, err := tx.Prepare(
-		`INSERT INTO balances (chain, network, address_index, token, balance, last_scanned)
-		 VALUES (?, ?, ?, ?, ?, ?)
-		 ON CONFLICT(chain, network, address_index, token) DO UPDATE SET balance = excluded.balance, last_scanned = excluded.last_scanned`,
+		`INSERT INTO balances (chain, network, account, address_index, token, balance, last_scanned)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(chain, network, account, address_index, token) DO UPDATE SET balance = excluded.balance, last_scanned = excluded.last_scanned`,
 	)
 	if err != nil {
 		return fmt.Errorf("prepare balance upsert: %w", err)
@@ -59,7 +59,7 @@ func (d *DB) UpsertBalanceBatch(balances []models.Balance) error {
 	defer stmt.Close()
 
 	for _, b := range balances {
-		if _, err := stmt.Exec(string(b.Chain), d.network, b.AddressIndex, string(b.Token), b.Balance, now); err != nil {
+		if _, err := stmt.Exec(string(b.Chain), d.network, d.account, b.AddressIndex, string(b.Token), b.Balance, now); err != nil {
 			return fmt.Errorf("exec balance upsert %s/%d/%s: %w", b.Chain, b.AddressIndex, b.Token, err)
 		}
 	}
@@ -86,9 +86,9 @@ func (d *DB) UpsertBalanceBatchTx(tx *sql.Tx, balances []models.Balance) error {
 	now := time.Now().UTC().Format(time.RFC3339)
 
 	stmt, err := tx.Prepare(
-		`INSERT INTO balances (chain, network, address_index, token, balance, last_scanned)
-		 VALUES (?, ?, ?, ?, ?, ?)
-		 ON CONFLICT(chain, network, address_index, token) DO UPDATE SET balance = excluded.balance, last_scanned = excluded.last_scanned`,
+		`INSERT INTO balances (chain, network, account, address_index, token, balance, last_scanned)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(chain, network, account, address_index, token) DO UPDATE SET balance = excluded.balance, last_scanned = excluded.last_scanned`,
 	)
 	if err != nil {
 		return fmt.Errorf("prepare balance upsert in tx: %w", err)
@@ -96,7 +96,7 @@ func (d *DB) UpsertBalanceBatchTx(tx *sql.Tx, balances []models.Balance) error {
 	defer stmt.Close()
 
 	for _, b := range balances {
-		if _, err := stmt.Exec(string(b.Chain), d.network, b.AddressIndex, string(b.Token), b.Balance, now); err != nil {
+		if _, err := stmt.Exec(string(b.Chain), d.network, d.account, b.AddressIndex, string(b.Token), b.Balance, now); err != nil {
 			return fmt.Errorf("exec balance upsert in tx %s/%d/%s: %w", b.Chain, b.AddressIndex, b.Token, err)
 		}
 	}
@@ -118,9 +118,9 @@ func (d *DB) GetFundedAddresses(chain models.Chain, token models.Token) ([]model
 
 	rows, err := d.conn.Query(
 		`SELECT chain, address_index, token, balance, last_scanned FROM balances
-		 WHERE chain = ? AND network = ? AND token = ? AND balance != '0'
+		 WHERE chain = ? AND network = ? AND account = ? AND token = ? AND balance != '0'
 		 ORDER BY address_index`,
-		string(chain), d.network, string(token),
+		string(chain), d.network, d.account, string(token),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("query funded addresses %s/%s: %w", chain, token, err)
@@ -154,7 +154,8 @@ func (d *DB) GetFundedAddresses(chain models.Chain, token models.Token) ([]model
 }
 
 // GetFundedAddressesJoined returns addresses with non-zero balance for a chain and token,
-// joined with the addresses table to include the actual address string and all token balances.
+// joined with the addresses table to include the actual address string, all token
+// balances and the address label.
 func (d *DB) GetFundedAddressesJoined(chain models.Chain, token models.Token) ([]models.AddressWithBalance, error) {
 	slog.Debug("fetching funded addresses with address data",
 		"chain", chain,
@@ -165,10 +166,10 @@ func (d *DB) GetFundedAddressesJoined(chain models.Chain, token models.Token) ([
 	rows, err := d.conn.Query(
 		`SELECT b.address_index, a.address, b.balance
 		 FROM balances b
-		 JOIN addresses a ON a.chain = b.chain AND a.network = b.network AND a.address_index = b.address_index
-		 WHERE b.chain = ? AND b.network = ? AND b.token = ? AND b.balance != '0'
+		 JOIN addresses a ON a.chain = b.chain AND a.network = b.network AND a.account = b.account AND a.address_index = b.address_index
+		 WHERE b.chain = ? AND b.network = ? AND b.account = ? AND b.token = ? AND b.balance != '0'
 		 ORDER BY b.address_index`,
-		string(chain), d.network, string(token),
+		string(chain), d.network, d.account, string(token),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("query funded addresses joined %s/%s: %w", chain, token, err)
@@ -203,8 +204,8 @@ func (d *DB) GetFundedAddressesJoined(chain models.Chain, token models.Token) ([
 
 	for _, f := range funded {
 		balRows, err := d.conn.Query(
-			`SELECT token, balance, last_scanned FROM balances WHERE chain = ? AND network = ? AND address_index = ?`,
-			string(chain), d.network, f.index,
+			`SELECT token, balance, last_scanned FROM balances WHERE chain = ? AND network = ? AND account = ? AND address_index = ?`,
+			string(chain), d.network, d.account, f.index,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("query all balances for %s/%d: %w", chain, f.index, err)
@@ -251,6 +252,10 @@ func (d *DB) GetFundedAddressesJoined(chain models.Chain, token models.Token) ([
 		results = append(results, awb)
 	}
 
+	if err := d.hydrateLabels(results); err != nil {
+		return nil, fmt.Errorf("hydrate labels of funded addresses: %w", err)
+	}
+
 	slog.Debug("funded addresses joined fetched",
 		"chain", chain,
 		"token", token,
@@ -280,9 +285,9 @@ func (d *DB) GetBalanceSummary(chain models.Chain) (*BalanceSummary, error) {
 	rows, err := d.conn.Query(
 		`SELECT token, COUNT(*) as funded_count
 		 FROM balances
-		 WHERE chain = ? AND network = ? AND balance != '0'
+		 WHERE chain = ? AND network = ? AND account = ? AND balance != '0'
 		 GROUP BY token`,
-		string(chain), d.network,
+		string(chain), d.network, d.account,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("query balance summary for %s: %w", chain, err)
@@ -326,17 +331,22 @@ type BalanceAggregate struct {
 }
 
 // GetBalanceAggregates returns aggregated balance totals per chain+token.
-// Only includes non-zero balances.
+// Only includes non-zero balances. BTC change addresses count toward BTC NATIVE.
 func (d *DB) GetBalanceAggregates() ([]BalanceAggregate, error) {
 	slog.Debug("fetching balance aggregates")
 
 	rows, err := d.conn.Query(
 		`SELECT chain, token, printf('%.0f', SUM(CAST(balance AS REAL))), COUNT(*)
-		 FROM balances
-		 WHERE network = ? AND balance != '0'
+		 FROM (
+		     SELECT chain, token, balance FROM balances
+		     WHERE network = ? AND account = ? AND balance != '0'
+		     UNION ALL
+		     SELECT 'BTC', 'NATIVE', CAST(balance_sats AS TEXT) FROM btc_change_addresses
+		     WHERE network = ? AND account = ? AND balance_sats > 0
+		 )
 		 GROUP BY chain, token
 		 ORDER BY chain, token`,
-		d.network,
+		d.network, d.account, d.network, d.account,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("query balance aggregates: %w", err)
@@ -361,16 +371,21 @@ func (d *DB) GetBalanceAggregates() ([]BalanceAggregate, error) {
 	return results, nil
 }
 
-// GetFundedCountByChain returns the number of unique funded addresses per chain.
+// GetFundedCountByChain returns the number of unique funded addresses per chain,
+// BTC change addresses included.
 func (d *DB) GetFundedCountByChain() (map[models.Chain]int, error) {
 	slog.Debug("fetching funded count by chain")
 
 	rows, err := d.conn.Query(
 		`SELECT chain, COUNT(DISTINCT address_index)
 		 FROM balances
-		 WHERE network = ? AND balance != '0'
-		 GROUP BY chain`,
-		d.network,
+		 WHERE network = ? AND account = ? AND balance != '0'
+		 GROUP BY chain
+		 UNION ALL
+		 SELECT 'BTC', COUNT(*)
+		 FROM btc_change_addresses
+		 WHERE network = ? AND account = ? AND balance_sats > 0`,
+		d.network, d.account, d.network, d.account,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("query funded count by chain: %w", err)
@@ -384,7 +399,9 @@ func (d *DB) GetFundedCountByChain() (map[models.Chain]int, error) {
 		if err := rows.Scan(&chain, &count); err != nil {
 			return nil, fmt.Errorf("scan funded count row: %w", err)
 		}
-		result[chain] = count
+		if count > 0 {
+			result[chain] += count
+		}
 	}
 
 	if err := rows.Err(); err != nil {
@@ -401,7 +418,7 @@ func (d *DB) GetLatestScanTime() (string, error) {
 	slog.Debug("fetching latest scan time")
 
 	var lastScan *string
-	err := d.conn.QueryRow("SELECT MAX(updated_at) FROM scan_state WHERE network = ?", d.network).Scan(&lastScan)
+	err := d.conn.QueryRow("SELECT MAX(updated_at) FROM scan_state WHERE network = ? AND account = ?", d.network, d.account).Scan(&lastScan)
 	if err != nil {
 		return "", fmt.Errorf("query latest scan time: %w", err)
 	}
@@ -420,8 +437,8 @@ func (d *DB) GetLatestScanTime() (string, error) {
 // Returns a map of chain -> updated_at timestamp string.
 func (d *DB) GetScanTimesByChain() (map[string]string, error) {
 	rows, err := d.conn.Query(
-		"SELECT chain, updated_at FROM scan_state WHERE network = ? AND updated_at IS NOT NULL",
-		d.network,
+		"SELECT chain, updated_at FROM scan_state WHERE network = ? AND account = ? AND updated_at IS NOT NULL",
+		d.network, d.account,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("query scan times by chain: %w", err)
@@ -449,16 +466,17 @@ func (d *DB) GetAddressesBatch(chain models.Chain, startIndex, count int) ([]mod
 	)
 
 	placeholders := make([]string, count)
-	args := make([]interface{}, count+2)
+	args := make([]interface{}, count+3)
 	args[0] = string(chain)
 	args[1] = d.network
+	args[2] = d.account
 	for i := 0; i < count; i++ {
 		placeholders[i] = "?"
-		args[i+2] = startIndex + i
+		args[i+3] = startIndex + i
 	}
 
 	query := fmt.Sprintf(
-		"SELECT chain, address_index, address, created_at FROM addresses WHERE chain = ? AND network = ? AND address_index IN (%s) ORDER BY address_index",
+		"SELECT chain, address_index, address, created_at FROM addresses WHERE chain = ? AND network = ? AND account = ? AND address_index IN (%s) ORDER BY address_index",
 		strings.Join(placeholders, ","),
 	)
 
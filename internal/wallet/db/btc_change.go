@@ -0,0 +1,205 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/Fantasim/hdpay/internal/shared/models"
+)
+
+// BTCChangeAddressRow is an internal-chain address that received the change of
+// a payout.
+type BTCChangeAddressRow struct {
+	AddressType string
+	ChangeIndex int
+	Address     string
+	TxHash      string
+	AmountSats  int64
+	BalanceSats int64  // last scanned balance; the change amount until scanned
+	LastScanned string // empty until scanned
+	CreatedAt   string
+}
+
+// NextBTCChangeIndex returns the first internal-chain index of addrType that no
+// payout of the current network and account has used, and that lies past the
+// last used change index found by address discovery.
+func (d *DB) NextBTCChangeIndex(addrType string) (int, error) {
+	var next int
+	err := d.conn.QueryRow(
+		`SELECT COALESCE(MAX(change_index) + 1, 0) FROM btc_change_addresses
+		 WHERE network = ? AND account = ? AND address_type = ?`,
+		d.network, d.account, addrType,
+	).Scan(&next)
+	if err != nil {
+		return 0, fmt.Errorf("query next BTC change index: %w", err)
+	}
+
+	var lastUsed int
+	err = d.conn.QueryRow(
+		`SELECT last_used_index FROM address_discovery
+		 WHERE chain = 'BTC' AND network = ? AND account = ? AND branch = 1`,
+		d.network, d.account,
+	).Scan(&lastUsed)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("query discovered BTC change index: %w", err)
+	}
+	if err == nil && lastUsed+1 > next {
+		next = lastUsed + 1
+	}
+
+	return next, nil
+}
+
+// RecordBTCChangeAddress stores the change address of a broadcast payout for
+// the current network and account. Its balance starts at the change amount.
+func (d *DB) RecordBTCChangeAddress(row BTCChangeAddressRow) error {
+	_, err := d.conn.Exec(
+		`INSERT INTO btc_change_addresses (network, account, address_type, change_index, address, tx_hash, amount_sats, balance_sats)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		d.network, d.account, row.AddressType, row.ChangeIndex, row.Address, row.TxHash, row.AmountSats, row.AmountSats,
+	)
+	if err != nil {
+		return fmt.Errorf("record BTC change address %d: %w", row.ChangeIndex, err)
+	}
+
+	slog.Info("BTC change address recorded",
+		"addressType", row.AddressType,
+		"changeIndex", row.ChangeIndex,
+		"txHash", row.TxHash,
+		"amountSats", row.AmountSats,
+	)
+	return nil
+}
+
+// UpdateBTCChangeAddressTx points the change address of the current network and
+// account at the payout that replaced the one it was recorded for (a fee bump),
+// resetting its balance to the new change amount.
+func (d *DB) UpdateBTCChangeAddressTx(address, txHash string, amountSats int64) error {
+	_, err := d.conn.Exec(
+		`UPDATE btc_change_addresses SET tx_hash = ?, amount_sats = ?, balance_sats = ?
+		 WHERE network = ? AND account = ? AND address = ?`,
+		txHash, amountSats, amountSats, d.network, d.account, address,
+	)
+	if err != nil {
+		return fmt.Errorf("update BTC change address %s: %w", address, err)
+	}
+
+	slog.Info("BTC change address updated",
+		"address", address,
+		"txHash", txHash,
+		"amountSats", amountSats,
+	)
+	return nil
+}
+
+// LookupBTCAddress returns the BTC address of the current network and account
+// with the given encoding, from the stored addresses or, flagged Change with its
+// change index as AddressIndex, from the payout change addresses. It wraps
+// sql.ErrNoRows when the address is neither.
+func (d *DB) LookupBTCAddress(address string) (*models.Address, error) {
+	addr, err := d.LookupAddress(models.ChainBTC, address)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return addr, err
+	}
+
+	change := models.Address{Chain: models.ChainBTC, Change: true}
+	err = d.conn.QueryRow(
+		`SELECT change_index, address, created_at FROM btc_change_addresses
+		 WHERE network = ? AND account = ? AND address = ?`,
+		d.network, d.account, address,
+	).Scan(&change.AddressIndex, &change.Address, &change.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("lookup BTC change address %s: %w", address, err)
+	}
+	return &change, nil
+}
+
+// ListBTCChangeAddresses returns the change addresses of the current network and
+// account, by address type and index.
+func (d *DB) ListBTCChangeAddresses() ([]BTCChangeAddressRow, error) {
+	rows, err := d.conn.Query(
+		`SELECT address_type, change_index, address, tx_hash, amount_sats, balance_sats, last_scanned, created_at
+		 FROM btc_change_addresses WHERE network = ? AND account = ?
+		 ORDER BY address_type, change_index`,
+		d.network, d.account,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query BTC change addresses: %w", err)
+	}
+	defer rows.Close()
+
+	var out []BTCChangeAddressRow
+	for rows.Next() {
+		var row BTCChangeAddressRow
+		var lastScanned sql.NullString
+		if err := rows.Scan(&row.AddressType, &row.ChangeIndex, &row.Address, &row.TxHash, &row.AmountSats, &row.BalanceSats, &lastScanned, &row.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan BTC change address row: %w", err)
+		}
+		row.LastScanned = lastScanned.String
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
+
+// UpdateBTCChangeBalances stores scanned balances, in satoshis by address, of
+// change addresses of the current network and account in one transaction.
+func (d *DB) UpdateBTCChangeBalances(balances map[string]int64) error {
+	if len(balances) == 0 {
+		return nil
+	}
+
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(
+		`UPDATE btc_change_addresses SET balance_sats = ?, last_scanned = datetime('now')
+		 WHERE network = ? AND account = ? AND address = ?`,
+	)
+	if err != nil {
+		return fmt.Errorf("prepare BTC change balance update: %w", err)
+	}
+	defer stmt.Close()
+
+	for address, sats := range balances {
+		if _, err := stmt.Exec(sats, d.network, d.account, address); err != nil {
+			return fmt.Errorf("update BTC change balance of %s: %w", address, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit BTC change balances: %w", err)
+	}
+
+	slog.Debug("BTC change balances updated", "count", len(balances))
+	return nil
+}
+
+// GetFundedBTCChangeAddresses returns the change addresses of the current network
+// and account with a non-zero balance, flagged Change, by address type and index.
+func (d *DB) GetFundedBTCChangeAddresses() ([]models.Address, error) {
+	rows, err := d.conn.Query(
+		`SELECT change_index, address, created_at
+		 FROM btc_change_addresses WHERE network = ? AND account = ? AND balance_sats > 0
+		 ORDER BY address_type, change_index`,
+		d.network, d.account,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query funded BTC change addresses: %w", err)
+	}
+	defer rows.Close()
+
+	var out []models.Address
+	for rows.Next() {
+		a := models.Address{Chain: models.ChainBTC, Change: true}
+		if err := rows.Scan(&a.AddressIndex, &a.Address, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan funded BTC change address row: %w", err)
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
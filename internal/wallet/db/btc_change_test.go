@@ -0,0 +1,130 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/Fantasim/hdpay/internal/shared/models"
+)
+
+func TestBTCChangeAddresses(t *testing.T) {
+	d := setupTestDB(t)
+
+	next, err := d.NextBTCChangeIndex("P2WPKH")
+	if err != nil || next != 0 {
+		t.Fatalf("NextBTCChangeIndex() on an empty table = %d, %v, want 0", next, err)
+	}
+
+	// Discovery found change used up to index 4, e.g. by another wallet.
+	if err := d.UpsertAddressDiscovery(AddressDiscoveryRow{Chain: "BTC", Branch: 1, GapLimit: 20, LastUsedIndex: 4}); err != nil {
+		t.Fatal(err)
+	}
+	if next, _ := d.NextBTCChangeIndex("P2WPKH"); next != 5 {
+		t.Errorf("NextBTCChangeIndex() after discovery = %d, want 5", next)
+	}
+
+	for _, idx := range []int{5, 6} {
+		if err := d.RecordBTCChangeAddress(BTCChangeAddressRow{AddressType: "P2WPKH", ChangeIndex: idx, Address: "tb1qchange", TxHash: "hash", AmountSats: 1000}); err != nil {
+			t.Fatalf("RecordBTCChangeAddress(%d) error = %v", idx, err)
+		}
+	}
+	if next, _ := d.NextBTCChangeIndex("P2WPKH"); next != 7 {
+		t.Errorf("NextBTCChangeIndex() after two payouts = %d, want 7", next)
+	}
+	if err := d.RecordBTCChangeAddress(BTCChangeAddressRow{AddressType: "P2WPKH", ChangeIndex: 6, Address: "tb1qchange", TxHash: "other"}); err == nil {
+		t.Error("RecordBTCChangeAddress() reusing an index should fail")
+	}
+
+	// Indices are per address type and per account.
+	if next, _ := d.NextBTCChangeIndex("P2TR"); next != 5 {
+		t.Errorf("NextBTCChangeIndex(P2TR) = %d, want 5 (discovery only)", next)
+	}
+	if next, _ := d.WithAccount(1).NextBTCChangeIndex("P2WPKH"); next != 0 {
+		t.Errorf("account 1 NextBTCChangeIndex() = %d, want 0", next)
+	}
+
+	list, err := d.ListBTCChangeAddresses()
+	if err != nil || len(list) != 2 || list[0].ChangeIndex != 5 || list[1].AmountSats != 1000 {
+		t.Errorf("ListBTCChangeAddresses() = %+v, %v", list, err)
+	}
+}
+
+func TestBTCChangeBalances(t *testing.T) {
+	d := setupTestDB(t)
+
+	for idx, addr := range []string{"tb1qchange0", "tb1qchange1"} {
+		if err := d.RecordBTCChangeAddress(BTCChangeAddressRow{AddressType: "P2WPKH", ChangeIndex: idx, Address: addr, TxHash: "hash", AmountSats: 3000}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Until scanned, change is assumed unspent.
+	funded, err := d.GetFundedBTCChangeAddresses()
+	if err != nil || len(funded) != 2 || !funded[0].Change || funded[1].AddressIndex != 1 {
+		t.Fatalf("GetFundedBTCChangeAddresses() = %+v, %v, want both change addresses", funded, err)
+	}
+
+	if err := d.UpdateBTCChangeBalances(map[string]int64{"tb1qchange0": 0, "tb1qchange1": 2500}); err != nil {
+		t.Fatalf("UpdateBTCChangeBalances() error = %v", err)
+	}
+	list, err := d.ListBTCChangeAddresses()
+	if err != nil || list[0].BalanceSats != 0 || list[1].BalanceSats != 2500 || list[1].LastScanned == "" {
+		t.Errorf("ListBTCChangeAddresses() after scan = %+v, %v", list, err)
+	}
+	if funded, _ := d.GetFundedBTCChangeAddresses(); len(funded) != 1 || funded[0].Address != "tb1qchange1" {
+		t.Errorf("GetFundedBTCChangeAddresses() after scan = %+v, want tb1qchange1", funded)
+	}
+
+	// Change counts toward the BTC balances of the account.
+	if err := d.InsertAddressBatch(models.ChainBTC, []models.Address{{Chain: models.ChainBTC, AddressIndex: 0, Address: "tb1qexternal0"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.UpsertBalance(models.ChainBTC, 0, models.TokenNative, "1000"); err != nil {
+		t.Fatal(err)
+	}
+	aggregates, err := d.GetBalanceAggregates()
+	if err != nil || len(aggregates) != 1 || aggregates[0].TotalBalance != "3500" || aggregates[0].FundedCount != 2 {
+		t.Errorf("GetBalanceAggregates() = %+v, %v, want 3500 sats on 2 addresses", aggregates, err)
+	}
+	counts, err := d.GetFundedCountByChain()
+	if err != nil || counts[models.ChainBTC] != 2 {
+		t.Errorf("GetFundedCountByChain() = %v, %v, want 2 BTC", counts, err)
+	}
+	if counts, _ := d.WithAccount(1).GetFundedCountByChain(); len(counts) != 0 {
+		t.Errorf("account 1 GetFundedCountByChain() = %v, want none", counts)
+	}
+}
+
+func TestLookupBTCAddress(t *testing.T) {
+	d := setupTestDB(t)
+
+	if err := d.InsertAddressBatch(models.ChainBTC, []models.Address{{Chain: models.ChainBTC, AddressIndex: 3, Address: "tb1qexternal3"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.RecordBTCChangeAddress(BTCChangeAddressRow{AddressType: "P2WPKH", ChangeIndex: 2, Address: "tb1qchange2", TxHash: "hash", AmountSats: 3000}); err != nil {
+		t.Fatal(err)
+	}
+
+	if addr, err := d.LookupBTCAddress("tb1qexternal3"); err != nil || addr.AddressIndex != 3 || addr.Change {
+		t.Errorf("LookupBTCAddress(external) = %+v, %v, want index 3", addr, err)
+	}
+	if addr, err := d.LookupBTCAddress("tb1qchange2"); err != nil || addr.AddressIndex != 2 || !addr.Change {
+		t.Errorf("LookupBTCAddress(change) = %+v, %v, want change index 2", addr, err)
+	}
+	if _, err := d.LookupBTCAddress("tb1qforeign"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("LookupBTCAddress(foreign) error = %v, want sql.ErrNoRows", err)
+	}
+	if _, err := d.WithAccount(1).LookupBTCAddress("tb1qchange2"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("account 1 LookupBTCAddress(change) error = %v, want sql.ErrNoRows", err)
+	}
+
+	// A fee bump moves the change address to the replacement payout.
+	if err := d.UpdateBTCChangeAddressTx("tb1qchange2", "replacement", 2400); err != nil {
+		t.Fatalf("UpdateBTCChangeAddressTx() error = %v", err)
+	}
+	list, err := d.ListBTCChangeAddresses()
+	if err != nil || len(list) != 1 || list[0].TxHash != "replacement" || list[0].AmountSats != 2400 || list[0].BalanceSats != 2400 {
+		t.Errorf("ListBTCChangeAddresses() after update = %+v, %v", list, err)
+	}
+}
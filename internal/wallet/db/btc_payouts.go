@@ -0,0 +1,98 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	"github.com/Fantasim/hdpay/internal/shared/models"
+)
+
+// RecordBTCPayout stores one row per recipient of a broadcast payout for the
+// current network and account. Recipient i is output i of the transaction.
+func (d *DB) RecordBTCPayout(sweepID, txHash string, recipients []models.PayoutRecipient) error {
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(
+		`INSERT INTO btc_payouts (network, account, tx_hash, vout, address, amount_sats, sweep_id)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+	)
+	if err != nil {
+		return fmt.Errorf("prepare BTC payout insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for i, r := range recipients {
+		if _, err := stmt.Exec(d.network, d.account, txHash, i, r.Address, r.AmountSats, sweepID); err != nil {
+			return fmt.Errorf("record BTC payout output %s:%d: %w", txHash, i, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit BTC payout %s: %w", txHash, err)
+	}
+
+	slog.Info("BTC payout recorded",
+		"txHash", txHash,
+		"sweepID", sweepID,
+		"recipients", len(recipients),
+	)
+	return nil
+}
+
+// UpdateBTCPayoutStatus sets the status of every output of a payout. If status
+// is "confirmed", confirmed_at is set to the current time.
+func (d *DB) UpdateBTCPayoutStatus(txHash, status string) error {
+	query := "UPDATE btc_payouts SET status = ? WHERE network = ? AND account = ? AND tx_hash = ?"
+	if status == "confirmed" {
+		query = "UPDATE btc_payouts SET status = ?, confirmed_at = datetime('now') WHERE network = ? AND account = ? AND tx_hash = ?"
+	}
+	if _, err := d.conn.Exec(query, status, d.network, d.account, txHash); err != nil {
+		return fmt.Errorf("update BTC payout %s status: %w", txHash, err)
+	}
+
+	slog.Info("BTC payout status updated", "txHash", txHash, "status", status)
+	return nil
+}
+
+// ListBTCPayouts returns a page of payout outputs of the current network and
+// account, newest payout first, and the total number of outputs.
+func (d *DB) ListBTCPayouts(page, pageSize int) ([]models.PayoutOutput, int64, error) {
+	var total int64
+	if err := d.conn.QueryRow(
+		"SELECT COUNT(*) FROM btc_payouts WHERE network = ? AND account = ?",
+		d.network, d.account,
+	).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count BTC payouts: %w", err)
+	}
+
+	rows, err := d.conn.Query(
+		`SELECT sweep_id, tx_hash, vout, address, amount_sats, status, created_at, confirmed_at
+		 FROM btc_payouts WHERE network = ? AND account = ?
+		 ORDER BY created_at DESC, tx_hash, vout LIMIT ? OFFSET ?`,
+		d.network, d.account, pageSize, (page-1)*pageSize,
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query BTC payouts: %w", err)
+	}
+	defer rows.Close()
+
+	var out []models.PayoutOutput
+	for rows.Next() {
+		var p models.PayoutOutput
+		var confirmedAt sql.NullString
+		if err := rows.Scan(&p.SweepID, &p.TxHash, &p.Vout, &p.Address, &p.AmountSats, &p.Status, &p.CreatedAt, &confirmedAt); err != nil {
+			return nil, 0, fmt.Errorf("scan BTC payout row: %w", err)
+		}
+		p.ConfirmedAt = confirmedAt.String
+		out = append(out, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate BTC payout rows: %w", err)
+	}
+	return out, total, nil
+}
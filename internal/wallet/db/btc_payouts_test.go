@@ -0,0 +1,50 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/Fantasim/hdpay/internal/shared/models"
+)
+
+func TestBTCPayouts(t *testing.T) {
+	d := setupTestDB(t)
+
+	recipients := []models.PayoutRecipient{
+		{Address: "tb1qrecipient0", AmountSats: 45000},
+		{Address: "tb1qrecipient1", AmountSats: 30000},
+	}
+	if err := d.RecordBTCPayout("sweep-payout", "payouthash", recipients); err != nil {
+		t.Fatalf("RecordBTCPayout() error = %v", err)
+	}
+
+	payouts, total, err := d.ListBTCPayouts(1, 10)
+	if err != nil {
+		t.Fatalf("ListBTCPayouts() error = %v", err)
+	}
+	if total != 2 || len(payouts) != 2 {
+		t.Fatalf("ListBTCPayouts() = %d rows (total %d), want 2", len(payouts), total)
+	}
+	for i, p := range payouts {
+		if p.TxHash != "payouthash" || p.SweepID != "sweep-payout" || p.Vout != i || p.Address != recipients[i].Address ||
+			p.AmountSats != recipients[i].AmountSats || p.Status != "pending" || p.ConfirmedAt != "" {
+			t.Errorf("payout %d = %+v", i, p)
+		}
+	}
+
+	if err := d.UpdateBTCPayoutStatus("payouthash", "confirmed"); err != nil {
+		t.Fatalf("UpdateBTCPayoutStatus() error = %v", err)
+	}
+	payouts, _, _ = d.ListBTCPayouts(1, 1)
+	if len(payouts) != 1 || payouts[0].Status != "confirmed" || payouts[0].ConfirmedAt == "" {
+		t.Errorf("after confirmation = %+v, want one confirmed row", payouts)
+	}
+
+	// Payouts are not address history: index 0 stays unused and allocatable.
+	if txs, total, err := d.ListTransactions(nil, 1, 10); err != nil || total != 0 || len(txs) != 0 {
+		t.Errorf("ListTransactions() = %d rows (total %d), %v, want none", len(txs), total, err)
+	}
+
+	if _, total, _ := d.WithAccount(1).ListBTCPayouts(1, 10); total != 0 {
+		t.Errorf("account 1 payouts = %d, want 0", total)
+	}
+}
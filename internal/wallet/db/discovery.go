@@ -0,0 +1,70 @@
+package db
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// AddressDiscoveryRow records the outcome of the last gap-limit discovery of one
+// derivation branch (0 external, 1 BTC change) of a chain.
+type AddressDiscoveryRow struct {
+	Chain         string
+	Branch        int
+	GapLimit      int
+	LastUsedIndex int // -1 when no address was used
+	UsedCount     int
+	Scanned       int
+	DiscoveredAt  string
+}
+
+// UpsertAddressDiscovery records a discovery result for the current network and
+// account, replacing any earlier one for the same branch.
+func (d *DB) UpsertAddressDiscovery(row AddressDiscoveryRow) error {
+	_, err := d.conn.Exec(
+		`INSERT INTO address_discovery (chain, network, account, branch, gap_limit, last_used_index, used_count, scanned)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(chain, network, account, branch) DO UPDATE SET
+		   gap_limit = excluded.gap_limit,
+		   last_used_index = excluded.last_used_index,
+		   used_count = excluded.used_count,
+		   scanned = excluded.scanned,
+		   discovered_at = datetime('now')`,
+		row.Chain, d.network, d.account, row.Branch, row.GapLimit, row.LastUsedIndex, row.UsedCount, row.Scanned,
+	)
+	if err != nil {
+		return fmt.Errorf("upsert address discovery for %s branch %d: %w", row.Chain, row.Branch, err)
+	}
+
+	slog.Debug("address discovery recorded",
+		"chain", row.Chain,
+		"branch", row.Branch,
+		"lastUsedIndex", row.LastUsedIndex,
+	)
+
+	return nil
+}
+
+// GetAddressDiscoveries returns the recorded discovery results of the current network
+// and account, ordered by chain and branch.
+func (d *DB) GetAddressDiscoveries() ([]AddressDiscoveryRow, error) {
+	rows, err := d.conn.Query(
+		`SELECT chain, branch, gap_limit, last_used_index, used_count, scanned, discovered_at
+		 FROM address_discovery WHERE network = ? AND account = ?
+		 ORDER BY chain, branch`,
+		d.network, d.account,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query address discoveries: %w", err)
+	}
+	defer rows.Close()
+
+	var out []AddressDiscoveryRow
+	for rows.Next() {
+		var row AddressDiscoveryRow
+		if err := rows.Scan(&row.Chain, &row.Branch, &row.GapLimit, &row.LastUsedIndex, &row.UsedCount, &row.Scanned, &row.DiscoveredAt); err != nil {
+			return nil, fmt.Errorf("scan address discovery row: %w", err)
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
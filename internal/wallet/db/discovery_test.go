@@ -0,0 +1,45 @@
+package db
+
+import "testing"
+
+func TestUpsertAddressDiscovery(t *testing.T) {
+	database := setupTestDB(t)
+
+	rows := []AddressDiscoveryRow{
+		{Chain: "BTC", Branch: 0, GapLimit: 20, LastUsedIndex: 41, UsedCount: 12, Scanned: 62},
+		{Chain: "BTC", Branch: 1, GapLimit: 20, LastUsedIndex: -1, Scanned: 20},
+	}
+	for _, row := range rows {
+		if err := database.UpsertAddressDiscovery(row); err != nil {
+			t.Fatalf("UpsertAddressDiscovery() error = %v", err)
+		}
+	}
+
+	// A later run replaces the branch's previous result.
+	if err := database.UpsertAddressDiscovery(AddressDiscoveryRow{Chain: "BTC", Branch: 1, GapLimit: 50, LastUsedIndex: 3, UsedCount: 2, Scanned: 54}); err != nil {
+		t.Fatalf("UpsertAddressDiscovery() error = %v", err)
+	}
+
+	got, err := database.GetAddressDiscoveries()
+	if err != nil {
+		t.Fatalf("GetAddressDiscoveries() error = %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(got))
+	}
+	if got[0].Branch != 0 || got[0].LastUsedIndex != 41 || got[0].UsedCount != 12 {
+		t.Errorf("external row = %+v", got[0])
+	}
+	if got[1].Branch != 1 || got[1].GapLimit != 50 || got[1].LastUsedIndex != 3 || got[1].DiscoveredAt == "" {
+		t.Errorf("internal row = %+v", got[1])
+	}
+
+	// Results are scoped to the account.
+	other, err := database.WithAccount(1).GetAddressDiscoveries()
+	if err != nil {
+		t.Fatalf("GetAddressDiscoveries() error = %v", err)
+	}
+	if len(other) != 0 {
+		t.Errorf("account 1 sees %d rows, want 0", len(other))
+	}
+}
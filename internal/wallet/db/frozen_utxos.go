@@ -0,0 +1,79 @@
+package db
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/Fantasim/hdpay/internal/shared/models"
+)
+
+// FreezeUTXO excludes a BTC outpoint of the current network and account from
+// consolidations. Freezing an already frozen outpoint replaces its note and keeps
+// frozen_at.
+func (d *DB) FreezeUTXO(txid string, vout uint32, note string) (*models.FrozenUTXO, error) {
+	_, err := d.conn.Exec(
+		`INSERT INTO frozen_utxos (network, account, txid, vout, note)
+		 VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT(network, account, txid, vout) DO UPDATE SET note = excluded.note`,
+		d.network, d.account, txid, vout, note,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("freeze UTXO %s:%d: %w", txid, vout, err)
+	}
+
+	frozen := models.FrozenUTXO{TxID: txid, Vout: vout, Note: note}
+	err = d.conn.QueryRow(
+		"SELECT frozen_at FROM frozen_utxos WHERE network = ? AND account = ? AND txid = ? AND vout = ?",
+		d.network, d.account, txid, vout,
+	).Scan(&frozen.FrozenAt)
+	if err != nil {
+		return nil, fmt.Errorf("read frozen UTXO %s:%d: %w", txid, vout, err)
+	}
+
+	slog.Info("UTXO frozen", "txid", txid, "vout", vout)
+	return &frozen, nil
+}
+
+// UnfreezeUTXO makes a frozen outpoint spendable again and reports whether it was
+// frozen.
+func (d *DB) UnfreezeUTXO(txid string, vout uint32) (bool, error) {
+	result, err := d.conn.Exec(
+		"DELETE FROM frozen_utxos WHERE network = ? AND account = ? AND txid = ? AND vout = ?",
+		d.network, d.account, txid, vout,
+	)
+	if err != nil {
+		return false, fmt.Errorf("unfreeze UTXO %s:%d: %w", txid, vout, err)
+	}
+
+	affected, _ := result.RowsAffected()
+	slog.Info("UTXO unfrozen", "txid", txid, "vout", vout, "rows", affected)
+	return affected > 0, nil
+}
+
+// ListFrozenUTXOs returns the frozen outpoints of the current network and
+// account, most recently frozen first.
+func (d *DB) ListFrozenUTXOs() ([]models.FrozenUTXO, error) {
+	rows, err := d.conn.Query(
+		`SELECT txid, vout, note, frozen_at FROM frozen_utxos
+		 WHERE network = ? AND account = ?
+		 ORDER BY frozen_at DESC, txid, vout`,
+		d.network, d.account,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query frozen UTXOs: %w", err)
+	}
+	defer rows.Close()
+
+	frozen := []models.FrozenUTXO{}
+	for rows.Next() {
+		var f models.FrozenUTXO
+		if err := rows.Scan(&f.TxID, &f.Vout, &f.Note, &f.FrozenAt); err != nil {
+			return nil, fmt.Errorf("scan frozen UTXO row: %w", err)
+		}
+		frozen = append(frozen, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate frozen UTXO rows: %w", err)
+	}
+	return frozen, nil
+}
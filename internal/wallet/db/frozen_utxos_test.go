@@ -0,0 +1,56 @@
+package db
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFrozenUTXOs(t *testing.T) {
+	d := setupTestDB(t)
+	txid := strings.Repeat("ab", 32)
+
+	frozen, err := d.FreezeUTXO(txid, 1, "disputed deposit")
+	if err != nil {
+		t.Fatalf("FreezeUTXO() error = %v", err)
+	}
+	if frozen.TxID != txid || frozen.Vout != 1 || frozen.Note != "disputed deposit" || frozen.FrozenAt == "" {
+		t.Errorf("FreezeUTXO() = %+v", frozen)
+	}
+
+	// Re-freezing replaces the note without duplicating the row.
+	if _, err := d.FreezeUTXO(txid, 1, "still disputed"); err != nil {
+		t.Fatalf("second FreezeUTXO() error = %v", err)
+	}
+	if _, err := d.FreezeUTXO(txid, 2, ""); err != nil {
+		t.Fatalf("FreezeUTXO(vout 2) error = %v", err)
+	}
+
+	// Another account does not see the freezes.
+	if list, err := d.WithAccount(1).ListFrozenUTXOs(); err != nil || len(list) != 0 {
+		t.Errorf("account 1 ListFrozenUTXOs() = %v, %v, want none", list, err)
+	}
+
+	list, err := d.ListFrozenUTXOs()
+	if err != nil {
+		t.Fatalf("ListFrozenUTXOs() error = %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("ListFrozenUTXOs() = %+v, want 2 outpoints", list)
+	}
+	for _, f := range list {
+		if f.Vout == 1 && f.Note != "still disputed" {
+			t.Errorf("vout 1 note = %q, want the replaced note", f.Note)
+		}
+	}
+
+	unfrozen, err := d.UnfreezeUTXO(txid, 1)
+	if err != nil || !unfrozen {
+		t.Fatalf("UnfreezeUTXO() = %v, %v, want true", unfrozen, err)
+	}
+	if unfrozen, _ := d.UnfreezeUTXO(txid, 1); unfrozen {
+		t.Error("UnfreezeUTXO() of a spendable outpoint = true, want false")
+	}
+	if list, _ := d.ListFrozenUTXOs(); len(list) != 1 || list[0].Vout != 2 {
+		t.Errorf("ListFrozenUTXOs() after unfreeze = %+v, want vout 2 only", list)
+	}
+}
@@ -0,0 +1,85 @@
+-- Migration 008: Add BIP-44 account column to addresses, balances, scan_state, tx_state and transactions.
+-- Lets several accounts (m/purpose'/coin'/account') share one wallet database.
+-- Existing rows were all derived from account 0'.
+
+-- ============================================================
+-- 1. addresses: recreate with account in PK
+-- ============================================================
+CREATE TABLE addresses_new (
+    chain TEXT NOT NULL,
+    network TEXT NOT NULL DEFAULT 'testnet',
+    account INTEGER NOT NULL DEFAULT 0,
+    address_index INTEGER NOT NULL,
+    address TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    PRIMARY KEY (chain, network, account, address_index)
+);
+
+INSERT INTO addresses_new (chain, network, account, address_index, address, created_at)
+SELECT chain, network, 0, address_index, address, created_at
+FROM addresses;
+
+DROP TABLE addresses;
+ALTER TABLE addresses_new RENAME TO addresses;
+CREATE INDEX IF NOT EXISTS idx_addresses_chain ON addresses(chain, network, account);
+CREATE INDEX IF NOT EXISTS idx_addresses_address ON addresses(address);
+
+-- ============================================================
+-- 2. balances: recreate with account in PK
+-- ============================================================
+CREATE TABLE balances_new (
+    chain TEXT NOT NULL,
+    network TEXT NOT NULL DEFAULT 'testnet',
+    account INTEGER NOT NULL DEFAULT 0,
+    address_index INTEGER NOT NULL,
+    token TEXT NOT NULL DEFAULT 'NATIVE',
+    balance TEXT NOT NULL DEFAULT '0',
+    last_scanned TEXT,
+    PRIMARY KEY (chain, network, account, address_index, token)
+);
+
+INSERT INTO balances_new (chain, network, account, address_index, token, balance, last_scanned)
+SELECT chain, network, 0, address_index, token, balance, last_scanned
+FROM balances;
+
+DROP TABLE balances;
+ALTER TABLE balances_new RENAME TO balances;
+CREATE INDEX IF NOT EXISTS idx_balances_nonzero ON balances(chain, network, account, token) WHERE balance != '0';
+
+-- ============================================================
+-- 3. scan_state: recreate with account in PK
+-- ============================================================
+CREATE TABLE scan_state_new (
+    chain TEXT NOT NULL,
+    network TEXT NOT NULL DEFAULT 'testnet',
+    account INTEGER NOT NULL DEFAULT 0,
+    last_scanned_index INTEGER NOT NULL DEFAULT 0,
+    max_scan_id INTEGER NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'idle',
+    started_at TEXT,
+    updated_at TEXT,
+    PRIMARY KEY (chain, network, account)
+);
+
+INSERT INTO scan_state_new (chain, network, account, last_scanned_index, max_scan_id, status, started_at, updated_at)
+SELECT chain, network, 0, last_scanned_index, max_scan_id, status, started_at, updated_at
+FROM scan_state;
+
+DROP TABLE scan_state;
+ALTER TABLE scan_state_new RENAME TO scan_state;
+
+-- ============================================================
+-- 4. tx_state: ALTER TABLE ADD COLUMN (PK is TEXT id)
+-- ============================================================
+ALTER TABLE tx_state ADD COLUMN account INTEGER NOT NULL DEFAULT 0;
+
+DROP INDEX IF EXISTS idx_tx_state_chain;
+CREATE INDEX idx_tx_state_chain ON tx_state(chain, network, account, status);
+
+-- ============================================================
+-- 5. transactions: ALTER TABLE ADD COLUMN (PK is INTEGER id)
+-- ============================================================
+ALTER TABLE transactions ADD COLUMN account INTEGER NOT NULL DEFAULT 0;
+
+DROP INDEX IF EXISTS idx_transactions_chain;
+CREATE INDEX idx_transactions_chain ON transactions(chain, network, account);
@@ -0,0 +1,15 @@
+-- Migration 009: Record gap-limit discovery results per derivation branch.
+-- branch 0 is the external (receive) chain stored in addresses; branch 1 is the
+-- BTC internal (change) chain, which is discovered and reported but not stored.
+CREATE TABLE IF NOT EXISTS address_discovery (
+    chain TEXT NOT NULL,
+    network TEXT NOT NULL,
+    account INTEGER NOT NULL DEFAULT 0,
+    branch INTEGER NOT NULL DEFAULT 0,
+    gap_limit INTEGER NOT NULL,
+    last_used_index INTEGER NOT NULL DEFAULT -1,
+    used_count INTEGER NOT NULL DEFAULT 0,
+    scanned INTEGER NOT NULL DEFAULT 0,
+    discovered_at TEXT NOT NULL DEFAULT (datetime('now')),
+    PRIMARY KEY (chain, network, account, branch)
+);
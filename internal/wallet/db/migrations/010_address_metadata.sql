@@ -0,0 +1,18 @@
+-- Migration 010: User-assigned address metadata (label, tags, external reference).
+-- tags is a JSON array of strings, metadata a free-form JSON object; both are
+-- stored as text and queried with json_each where needed.
+CREATE TABLE IF NOT EXISTS address_metadata (
+    chain TEXT NOT NULL,
+    network TEXT NOT NULL,
+    account INTEGER NOT NULL DEFAULT 0,
+    address_index INTEGER NOT NULL,
+    label TEXT NOT NULL DEFAULT '',
+    tags TEXT NOT NULL DEFAULT '[]',
+    external_id TEXT NOT NULL DEFAULT '',
+    metadata TEXT,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    updated_at TEXT NOT NULL DEFAULT (datetime('now')),
+    PRIMARY KEY (chain, network, account, address_index)
+);
+
+CREATE INDEX IF NOT EXISTS idx_address_metadata_external_id ON address_metadata(chain, network, account, external_id) WHERE external_id != '';
@@ -0,0 +1,18 @@
+-- Migration 011: Deposit address allocations.
+-- An address is handed out at most once: the row is never removed, and an
+-- idempotency key maps a retried allocation request to its original address.
+CREATE TABLE IF NOT EXISTS address_allocations (
+    chain TEXT NOT NULL,
+    network TEXT NOT NULL,
+    account INTEGER NOT NULL DEFAULT 0,
+    address_index INTEGER NOT NULL,
+    allocated_to TEXT NOT NULL,
+    idempotency_key TEXT,
+    allocated_at TEXT NOT NULL DEFAULT (datetime('now')),
+    PRIMARY KEY (chain, network, account, address_index)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_address_allocations_idempotency ON address_allocations(chain, network, account, idempotency_key) WHERE idempotency_key IS NOT NULL;
+
+-- AllocateAddress skips every address of the account with a recorded transaction.
+CREATE INDEX IF NOT EXISTS idx_transactions_address ON transactions(account, chain, network, address_index);
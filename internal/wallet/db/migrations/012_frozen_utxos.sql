@@ -0,0 +1,11 @@
+-- Migration 012: Frozen BTC outpoints (coin control).
+-- A frozen UTXO is never spent by a consolidation until it is unfrozen.
+CREATE TABLE IF NOT EXISTS frozen_utxos (
+    network TEXT NOT NULL,
+    account INTEGER NOT NULL DEFAULT 0,
+    txid TEXT NOT NULL,
+    vout INTEGER NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    frozen_at TEXT NOT NULL DEFAULT (datetime('now')),
+    PRIMARY KEY (network, account, txid, vout)
+);
@@ -0,0 +1,37 @@
+-- Migration 013: BTC payouts and the change addresses they hand out.
+-- Each btc_change_addresses row is an internal-chain (branch 1) index used by a
+-- broadcast payout, so that the next payout derives a fresh change address.
+-- The scanner refreshes balance_sats after each BTC scan; until then the change
+-- of a payout is assumed unspent.
+CREATE TABLE IF NOT EXISTS btc_change_addresses (
+    network TEXT NOT NULL,
+    account INTEGER NOT NULL DEFAULT 0,
+    address_type TEXT NOT NULL,
+    change_index INTEGER NOT NULL,
+    address TEXT NOT NULL,
+    tx_hash TEXT NOT NULL,
+    amount_sats INTEGER NOT NULL,
+    balance_sats INTEGER NOT NULL DEFAULT 0,
+    last_scanned TEXT,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    PRIMARY KEY (network, account, address_type, change_index)
+);
+
+-- One row per recipient output of a broadcast payout. Rows of transactions
+-- belong to one of our address indices (history, allocation checks), while a
+-- payout spends several addresses at once and pays external recipients.
+CREATE TABLE IF NOT EXISTS btc_payouts (
+    network TEXT NOT NULL,
+    account INTEGER NOT NULL DEFAULT 0,
+    tx_hash TEXT NOT NULL,
+    vout INTEGER NOT NULL,
+    address TEXT NOT NULL,
+    amount_sats INTEGER NOT NULL,
+    sweep_id TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'pending',
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    confirmed_at TEXT,
+    PRIMARY KEY (network, account, tx_hash, vout)
+);
+
+CREATE INDEX IF NOT EXISTS idx_btc_payouts_created ON btc_payouts(network, account, created_at);
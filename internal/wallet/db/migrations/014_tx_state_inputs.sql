@@ -0,0 +1,8 @@
+-- Migration 014: Address indices spent by a multi-input transaction.
+-- A chunked BTC consolidation has one tx_state row per transaction; each row
+-- lists the funded addresses it spends so a resume retries exactly those.
+CREATE TABLE IF NOT EXISTS tx_state_inputs (
+    tx_state_id TEXT NOT NULL,
+    address_index INTEGER NOT NULL,
+    PRIMARY KEY (tx_state_id, address_index)
+);
@@ -0,0 +1,14 @@
+-- Migration 015: Custom token registry.
+-- BEP-20 contracts and SPL mints tracked on top of the built-in USDC/USDT.
+-- Tokens are shared by every account of a network.
+CREATE TABLE IF NOT EXISTS tokens (
+    network TEXT NOT NULL,
+    chain TEXT NOT NULL,
+    symbol TEXT NOT NULL,
+    contract TEXT NOT NULL,
+    decimals INTEGER NOT NULL,
+    coingecko_id TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    PRIMARY KEY (network, chain, symbol),
+    UNIQUE (network, chain, contract)
+);
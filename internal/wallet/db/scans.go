@@ -28,8 +28,8 @@ func (d *DB) GetScanState(chain models.Chain) (*models.ScanState, error) {
 
 	err := d.conn.QueryRow(
 		`SELECT chain, last_scanned_index, max_scan_id, status, started_at, updated_at
-		 FROM scan_state WHERE chain = ? AND network = ?`,
-		string(chain), d.network,
+		 FROM scan_state WHERE chain = ? AND network = ? AND account = ?`,
+		string(chain), d.network, d.account,
 	).Scan(&state.Chain, &state.LastScannedIndex, &state.MaxScanID, &state.Status, &startedAt, &updatedAt)
 
 	if err == sql.ErrNoRows {
@@ -62,15 +62,15 @@ func (d *DB) UpsertScanState(state models.ScanState) error {
 	now := time.Now().UTC().Format(time.RFC3339)
 
 	_, err := d.conn.Exec(
-		`INSERT INTO scan_state (chain, network, last_scanned_index, max_scan_id, status, started_at, updated_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?)
-		 ON CONFLICT(chain, network) DO UPDATE SET
+		`INSERT INTO scan_state (chain, network, account, last_scanned_index, max_scan_id, status, started_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(chain, network, account) DO UPDATE SET
 		   last_scanned_index = excluded.last_scanned_index,
 		   max_scan_id = excluded.max_scan_id,
 		   status = excluded.status,
 		   started_at = COALESCE(NULLIF(excluded.started_at, ''), scan_state.started_at),
 		   updated_at = excluded.updated_at`,
-		string(state.Chain), d.network, state.LastScannedIndex, state.MaxScanID, state.Status,
+		string(state.Chain), d.network, d.account, state.LastScannedIndex, state.MaxScanID, state.Status,
 		state.StartedAt, now,
 	)
 	if err != nil {
@@ -93,15 +93,15 @@ func (d *DB) UpsertScanStateTx(tx *sql.Tx, state models.ScanState) error {
 	now := time.Now().UTC().Format(time.RFC3339)
 
 	_, err := tx.Exec(
-		`INSERT INTO scan_state (chain, network, last_scanned_index, max_scan_id, status, started_at, updated_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?)
-		 ON CONFLICT(chain, network) DO UPDATE SET
+		`INSERT INTO scan_state (chain, network, account, last_scanned_index, max_scan_id, status, started_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(chain, network, account) DO UPDATE SET
 		   last_scanned_index = excluded.last_scanned_index,
 		   max_scan_id = excluded.max_scan_id,
 		   status = excluded.status,
 		   started_at = COALESCE(NULLIF(excluded.started_at, ''), scan_state.started_at),
 		   updated_at = excluded.updated_at`,
-		string(state.Chain), d.network, state.LastScannedIndex, state.MaxScanID, state.Status,
+		string(state.Chain), d.network, d.account, state.LastScannedIndex, state.MaxScanID, state.Status,
 		state.StartedAt, now,
 	)
 	if err != nil {
@@ -117,14 +117,37 @@ func (d *DB) UpsertScanStateTx(tx *sql.Tx, state models.ScanState) error {
 	return nil
 }
 
+// ExtendScanMaxID raises the max scan ID of an existing scan state to maxID, keeping
+// its progress, status and timestamps (so resume decisions are unaffected). Chains that were never scanned, or already scan further,
+// are left unchanged.
+func (d *DB) ExtendScanMaxID(chain models.Chain, maxID int) error {
+	result, err := d.conn.Exec(
+		`UPDATE scan_state SET max_scan_id = ?
+		 WHERE chain = ? AND network = ? AND account = ? AND max_scan_id < ?`,
+		maxID, string(chain), d.network, d.account, maxID,
+	)
+	if err != nil {
+		return fmt.Errorf("extend scan max ID for %s: %w", chain, err)
+	}
+
+	rows, _ := result.RowsAffected()
+	slog.Debug("scan max ID extended",
+		"chain", chain,
+		"maxID", maxID,
+		"updated", rows > 0,
+	)
+
+	return nil
+}
+
 // GetAllScanStates returns current scan state for all chains.
 func (d *DB) GetAllScanStates() ([]models.ScanState, error) {
 	slog.Debug("fetching all scan states")
 
 	rows, err := d.conn.Query(
 		`SELECT chain, last_scanned_index, max_scan_id, status, started_at, updated_at
-		 FROM scan_state WHERE network = ?`,
-		d.network,
+		 FROM scan_state WHERE network = ? AND account = ?`,
+		d.network, d.account,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("query all scan states: %w", err)
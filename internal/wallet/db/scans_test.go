@@ -202,3 +202,43 @@ func TestShouldResume_MultipleChains(t *testing.T) {
 		t.Error("expected BSC not resumable (completed)")
 	}
 }
+
+func TestExtendScanMaxID(t *testing.T) {
+	database := setupTestDB(t)
+
+	// No scan state yet: nothing to extend.
+	if err := database.ExtendScanMaxID(models.ChainBTC, 50_000); err != nil {
+		t.Fatalf("ExtendScanMaxID() error = %v", err)
+	}
+	if state, _ := database.GetScanState(models.ChainBTC); state != nil {
+		t.Fatalf("ExtendScanMaxID created a scan state: %+v", state)
+	}
+
+	if err := database.UpsertScanState(models.ScanState{
+		Chain:            models.ChainBTC,
+		LastScannedIndex: 5000,
+		MaxScanID:        5000,
+		Status:           ScanStatusCompleted,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := database.ExtendScanMaxID(models.ChainBTC, 50_000); err != nil {
+		t.Fatalf("ExtendScanMaxID() error = %v", err)
+	}
+	state, err := database.GetScanState(models.ChainBTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.MaxScanID != 50_000 || state.LastScannedIndex != 5000 || state.Status != ScanStatusCompleted {
+		t.Errorf("state = %+v, want maxScanId 50000 with progress and status kept", state)
+	}
+
+	// Never lowers the range.
+	if err := database.ExtendScanMaxID(models.ChainBTC, 10_000); err != nil {
+		t.Fatal(err)
+	}
+	if state, _ := database.GetScanState(models.ChainBTC); state.MaxScanID != 50_000 {
+		t.Errorf("MaxScanID = %d, want 50000", state.MaxScanID)
+	}
+}
@@ -82,8 +82,8 @@ func (d *DB) GetAllSettings() (map[string]string, error) {
 	return result, nil
 }
 
-// ResetBalances deletes all balances, scan state, and transactions.
-// Addresses are preserved.
+// ResetBalances deletes all balances, scan state, and transactions of the network,
+// across every account. Addresses are preserved.
 func (d *DB) ResetBalances() error {
 	slog.Warn("resetting balances, scan state, and transactions")
 
@@ -111,7 +111,8 @@ func (d *DB) ResetBalances() error {
 	return nil
 }
 
-// ResetAll deletes all data: addresses, balances, scan state, and transactions.
+// ResetAll deletes all data of the network, across every account: addresses,
+// balances, scan state, and transactions.
 func (d *DB) ResetAll() error {
 	slog.Warn("resetting ALL data — addresses, balances, scan state, transactions")
 
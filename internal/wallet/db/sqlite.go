@@ -3,6 +3,7 @@ package db
 import (
 	"database/sql"
 	"embed"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -11,6 +12,7 @@ import (
 	"strings"
 
 	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
 	_ "modernc.org/sqlite"
 )
 
@@ -22,6 +24,7 @@ type DB struct {
 	conn    *sql.DB
 	path    string
 	network string
+	account int // BIP-44 account (m/purpose'/coin'/account'); 0 unless set via WithAccount
 }
 
 // Network returns the active network for this database instance.
@@ -29,6 +32,21 @@ func (d *DB) Network() string {
 	return d.network
 }
 
+// Account returns the BIP-44 account this database instance is scoped to.
+func (d *DB) Account() int {
+	return d.account
+}
+
+// WithAccount returns a view of the database scoped to the given BIP-44 account.
+// Addresses, balances, scan state, tx state and transactions are only visible
+// within their account. The view shares the underlying connection: closing either closes both.
+func (d *DB) WithAccount(account int) *DB {
+	scoped := *d
+	scoped.account = account
+	slog.Debug("database scoped to account", "network", d.network, "account", account)
+	return &scoped
+}
+
 // New opens a SQLite database at the given path with WAL mode and busy timeout.
 // The network parameter determines which network's data is visible (mainnet/testnet).
 func New(path string, network string) (*DB, error) {
@@ -86,10 +104,18 @@ func New(path string, network string) (*DB, error) {
 	return &DB{conn: conn, path: path, network: network}, nil
 }
 
+// AddressDeriver re-derives the address at chain/index from the configured key
+// material (mnemonic + optional BIP-39 passphrase).
+type AddressDeriver func(chain models.Chain, index int) (string, error)
+
 // ValidateNetworkConsistency checks that the configured network matches the
 // network of existing addresses in the database. Returns an error if addresses
 // exist for a different network, preventing silent address/balance mismatch.
-func (d *DB) ValidateNetworkConsistency() error {
+//
+// When derivers are given, the stored index-0 address of every populated chain is
+// also compared with a freshly derived one, which catches a wrong or missing
+// BIP-39 passphrase before any balance or sweep is attributed to the wrong keys.
+func (d *DB) ValidateNetworkConsistency(derivers ...AddressDeriver) error {
 	var dbNetwork string
 	err := d.conn.QueryRow("SELECT DISTINCT network FROM addresses LIMIT 1").Scan(&dbNetwork)
 	if err != nil {
@@ -107,6 +133,45 @@ func (d *DB) ValidateNetworkConsistency() error {
 	}
 
 	slog.Info("network consistency check passed", "network", d.network)
+
+	for _, derive := range derivers {
+		if err := d.validateKeyConsistency(derive); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateKeyConsistency compares each chain's stored index-0 address with the one
+// derived from the configured key material.
+func (d *DB) validateKeyConsistency(derive AddressDeriver) error {
+	for _, chain := range models.AllChains {
+		stored, err := d.GetAddressByIndex(chain, 0)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("key consistency check: %w", err)
+		}
+
+		derived, err := derive(chain, 0)
+		if err != nil {
+			return fmt.Errorf("key consistency check: derive %s index 0: %w", chain, err)
+		}
+
+		if derived != stored.Address {
+			return fmt.Errorf(
+				"%w: %s account %d index 0 is %s in the database but %s from the configured mnemonic. "+
+					"Check that the BIP-39 passphrase and account match the ones used at init",
+				config.ErrKeyMismatch, chain, d.account, stored.Address, derived,
+			)
+		}
+
+		slog.Debug("key consistency check passed", "chain", chain)
+	}
+
+	slog.Info("key consistency check passed", "network", d.network, "account", d.account)
 	return nil
 }
 
@@ -1,6 +1,9 @@
 package db
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -186,6 +189,47 @@ func TestValidateNetworkConsistency_Mismatch(t *testing.T) {
 	}
 }
 
+func TestValidateNetworkConsistency_KeyMismatch(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.sqlite")
+
+	d, err := New(dbPath, "testnet")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer d.Close()
+
+	if err := d.RunMigrations(); err != nil {
+		t.Fatalf("RunMigrations() error = %v", err)
+	}
+
+	addrs := []models.Address{
+		{Chain: models.ChainBTC, AddressIndex: 0, Address: "tb1qtest0"},
+	}
+	if err := d.InsertAddressBatch(models.ChainBTC, addrs); err != nil {
+		t.Fatalf("InsertAddressBatch() error = %v", err)
+	}
+
+	matching := func(chain models.Chain, index int) (string, error) {
+		return "tb1qtest0", nil
+	}
+	if err := d.ValidateNetworkConsistency(matching); err != nil {
+		t.Errorf("ValidateNetworkConsistency() should pass for matching keys, got: %v", err)
+	}
+
+	// Simulates a wrong BIP-39 passphrase: same mnemonic, different addresses.
+	wrongPassphrase := func(chain models.Chain, index int) (string, error) {
+		return "tb1qother", nil
+	}
+	err = d.ValidateNetworkConsistency(wrongPassphrase)
+	if !errors.Is(err, config.ErrKeyMismatch) {
+		t.Fatalf("ValidateNetworkConsistency() error = %v, want ErrKeyMismatch", err)
+	}
+	if !strings.Contains(err.Error(), "passphrase") {
+		t.Errorf("error should mention the passphrase, got: %s", err)
+	}
+}
+
 func TestNetworkIsolation(t *testing.T) {
 	tmpDir := t.TempDir()
 	dbPath := filepath.Join(tmpDir, "test.sqlite")
@@ -348,3 +392,157 @@ func TestNetworkIsolation(t *testing.T) {
 		}
 	})
 }
+
+func TestAccountIsolation(t *testing.T) {
+	base := setupTestDB(t)
+	account0 := base.WithAccount(0)
+	account1 := base.WithAccount(1)
+
+	if account1.Account() != 1 || account1.Network() != "testnet" {
+		t.Fatalf("WithAccount(1) = account %d network %q", account1.Account(), account1.Network())
+	}
+	if base.Account() != 0 {
+		t.Fatalf("WithAccount must not modify the receiver, base account = %d", base.Account())
+	}
+
+	// Same chain and indices in both accounts, different addresses.
+	for account, d := range []*DB{account0, account1} {
+		addrs := []models.Address{
+			{Chain: models.ChainBTC, AddressIndex: 0, Address: fmt.Sprintf("tb1qacct%d_0", account)},
+			{Chain: models.ChainBTC, AddressIndex: 1, Address: fmt.Sprintf("tb1qacct%d_1", account)},
+		}
+		if err := d.InsertAddressBatch(models.ChainBTC, addrs); err != nil {
+			t.Fatalf("InsertAddressBatch(account %d) error = %v", account, err)
+		}
+	}
+
+	if err := account1.UpsertBalance(models.ChainBTC, 1, models.TokenNative, "7000"); err != nil {
+		t.Fatalf("UpsertBalance(account 1) error = %v", err)
+	}
+	if err := account1.UpsertScanState(models.ScanState{
+		Chain:     models.ChainBTC,
+		MaxScanID: 2,
+		Status:    ScanStatusCompleted,
+	}); err != nil {
+		t.Fatalf("UpsertScanState(account 1) error = %v", err)
+	}
+	if err := account1.CreateTxState(TxStateRow{
+		ID:           "tx-acct1-001",
+		SweepID:      "sweep-acct1",
+		Chain:        "BTC",
+		Token:        "NATIVE",
+		AddressIndex: 1,
+		FromAddress:  "tb1qacct1_1",
+		ToAddress:    "tb1qdest",
+		Amount:       "7000",
+		Status:       config.TxStatePending,
+	}); err != nil {
+		t.Fatalf("CreateTxState(account 1) error = %v", err)
+	}
+	if _, err := account1.InsertTransaction(models.Transaction{
+		Chain:        models.ChainBTC,
+		AddressIndex: 1,
+		TxHash:       "acct1hash",
+		Direction:    "in",
+		Token:        models.TokenNative,
+		Amount:       "7000",
+		FromAddress:  "tb1qsender",
+		ToAddress:    "tb1qacct1_1",
+		Status:       "confirmed",
+	}); err != nil {
+		t.Fatalf("InsertTransaction(account 1) error = %v", err)
+	}
+
+	t.Run("addresses are per account", func(t *testing.T) {
+		for account, d := range []*DB{account0, account1} {
+			count, err := d.CountAddresses(models.ChainBTC)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != 2 {
+				t.Errorf("account %d address count = %d, want 2", account, count)
+			}
+			addr, err := d.GetAddressByIndex(models.ChainBTC, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := fmt.Sprintf("tb1qacct%d_1", account); addr.Address != want {
+				t.Errorf("account %d index 1 = %s, want %s", account, addr.Address, want)
+			}
+		}
+	})
+
+	t.Run("funded addresses only come from own account", func(t *testing.T) {
+		funded0, err := account0.GetFundedAddressesJoined(models.ChainBTC, models.TokenNative)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(funded0) != 0 {
+			t.Errorf("account 0 funded = %d, want 0", len(funded0))
+		}
+
+		funded1, err := account1.GetFundedAddressesJoined(models.ChainBTC, models.TokenNative)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(funded1) != 1 || funded1[0].Address != "tb1qacct1_1" {
+			t.Errorf("account 1 funded = %+v, want tb1qacct1_1", funded1)
+		}
+	})
+
+	t.Run("scan state is per account", func(t *testing.T) {
+		state, err := account0.GetScanState(models.ChainBTC)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if state != nil {
+			t.Errorf("account 0 scan state = %+v, want nil", state)
+		}
+		state, err = account1.GetScanState(models.ChainBTC)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if state == nil || state.Status != ScanStatusCompleted {
+			t.Errorf("account 1 scan state = %+v, want completed", state)
+		}
+	})
+
+	t.Run("transactions are per account", func(t *testing.T) {
+		txs0, total0, err := account0.ListTransactions(nil, 1, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total0 != 0 || len(txs0) != 0 {
+			t.Errorf("account 0 transactions = %d (total %d), want 0", len(txs0), total0)
+		}
+		if _, err := account0.GetTransactionByHash(models.ChainBTC, "acct1hash"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("account 0 GetTransactionByHash() error = %v, want sql.ErrNoRows", err)
+		}
+
+		txs1, total1, err := account1.ListTransactions(nil, 1, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total1 != 1 || len(txs1) != 1 || txs1[0].TxHash != "acct1hash" {
+			t.Errorf("account 1 transactions = %+v (total %d), want acct1hash", txs1, total1)
+		}
+	})
+
+	t.Run("tx state records its account", func(t *testing.T) {
+		pending0, err := account0.GetAllPendingTxStates()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pending0) != 0 {
+			t.Errorf("account 0 pending = %d, want 0", len(pending0))
+		}
+
+		states, err := account0.GetTxStatesBySweepID("sweep-acct1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(states) != 1 || states[0].Account != 1 {
+			t.Errorf("sweep lookup = %+v, want one row on account 1", states)
+		}
+	})
+}
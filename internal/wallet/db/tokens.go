@@ -0,0 +1,126 @@
+package db
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+)
+
+// ListTokens returns the token registry of the current network for a chain, or
+// for every chain when chain is empty: the built-in tokens first, then custom
+// tokens in the order they were added.
+func (d *DB) ListTokens(chain models.Chain) ([]models.TokenInfo, error) {
+	tokens := []models.TokenInfo{}
+	for _, t := range config.BuiltinTokens(d.network) {
+		if chain == "" || t.Chain == chain {
+			tokens = append(tokens, t)
+		}
+	}
+
+	rows, err := d.conn.Query(
+		`SELECT chain, symbol, contract, decimals, coingecko_id, created_at FROM tokens
+		 WHERE network = ? AND (? = '' OR chain = ?)
+		 ORDER BY created_at, rowid`,
+		d.network, string(chain), string(chain),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query tokens: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t models.TokenInfo
+		if err := rows.Scan(&t.Chain, &t.Symbol, &t.Contract, &t.Decimals, &t.CoinGeckoID, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan token row: %w", err)
+		}
+		tokens = append(tokens, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate token rows: %w", err)
+	}
+	return tokens, nil
+}
+
+// GetToken returns the registry entry of a token symbol on a chain. It wraps
+// sql.ErrNoRows when the chain has no such token.
+func (d *DB) GetToken(chain models.Chain, symbol models.Token) (*models.TokenInfo, error) {
+	for _, t := range config.BuiltinTokens(d.network) {
+		if t.Chain == chain && t.Symbol == symbol {
+			return &t, nil
+		}
+	}
+
+	t := models.TokenInfo{Chain: chain, Symbol: symbol}
+	err := d.conn.QueryRow(
+		`SELECT contract, decimals, coingecko_id, created_at FROM tokens
+		 WHERE network = ? AND chain = ? AND symbol = ?`,
+		d.network, string(chain), string(symbol),
+	).Scan(&t.Contract, &t.Decimals, &t.CoinGeckoID, &t.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("get token %s %s: %w", chain, symbol, err)
+	}
+	return &t, nil
+}
+
+// AddToken registers a custom token on the current network. A symbol or
+// contract already used on the chain, by a built-in or a custom token, is an
+// ErrTokenExists. Contracts are compared case-insensitively so that a BEP-20
+// address cannot be registered twice under different checksum casing.
+func (d *DB) AddToken(token models.TokenInfo) (*models.TokenInfo, error) {
+	existing, err := d.ListTokens(token.Chain)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range existing {
+		if t.Symbol == token.Symbol {
+			return nil, fmt.Errorf("%w: %s %s", config.ErrTokenExists, token.Chain, token.Symbol)
+		}
+		if strings.EqualFold(t.Contract, token.Contract) {
+			return nil, fmt.Errorf("%w: %s contract %s is registered as %s",
+				config.ErrTokenExists, token.Chain, token.Contract, t.Symbol)
+		}
+	}
+
+	_, err = d.conn.Exec(
+		`INSERT INTO tokens (network, chain, symbol, contract, decimals, coingecko_id)
+		 VALUES (?, ?, ?, ?, ?, ?)`,
+		d.network, string(token.Chain), string(token.Symbol), token.Contract, token.Decimals, token.CoinGeckoID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("insert token %s %s: %w", token.Chain, token.Symbol, err)
+	}
+
+	slog.Info("token registered",
+		"chain", token.Chain,
+		"symbol", token.Symbol,
+		"contract", token.Contract,
+		"decimals", token.Decimals,
+	)
+	return d.GetToken(token.Chain, token.Symbol)
+}
+
+// DeleteToken removes a custom token from the registry of the current network
+// and reports whether it was registered. Built-in tokens are an ErrBuiltinToken.
+// Stored balances of the token are kept; the token is no longer scanned.
+func (d *DB) DeleteToken(chain models.Chain, symbol models.Token) (bool, error) {
+	for _, t := range config.BuiltinTokens(d.network) {
+		if t.Chain == chain && t.Symbol == symbol {
+			return false, fmt.Errorf("%w: %s %s", config.ErrBuiltinToken, chain, symbol)
+		}
+	}
+
+	result, err := d.conn.Exec(
+		"DELETE FROM tokens WHERE network = ? AND chain = ? AND symbol = ?",
+		d.network, string(chain), string(symbol),
+	)
+	if err != nil {
+		return false, fmt.Errorf("delete token %s %s: %w", chain, symbol, err)
+	}
+
+	affected, _ := result.RowsAffected()
+	slog.Info("token removed", "chain", chain, "symbol", symbol, "rows", affected)
+	return affected > 0, nil
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+)
+
+func TestTokenRegistry(t *testing.T) {
+	d := setupTestDB(t)
+	cake := models.TokenInfo{
+		Chain:       models.ChainBSC,
+		Symbol:      "CAKE",
+		Contract:    "0x0E09FaBB73Bd3Ade0a17ECC321fD13a19e81cE82",
+		Decimals:    18,
+		CoinGeckoID: "pancakeswap-token",
+	}
+
+	builtins, err := d.ListTokens(models.ChainBSC)
+	if err != nil {
+		t.Fatalf("ListTokens() error = %v", err)
+	}
+	if len(builtins) != 2 || !builtins[0].Builtin || builtins[0].Symbol != models.TokenUSDC {
+		t.Fatalf("ListTokens(BSC) = %+v, want the built-in USDC and USDT", builtins)
+	}
+
+	added, err := d.AddToken(cake)
+	if err != nil {
+		t.Fatalf("AddToken() error = %v", err)
+	}
+	if added.Builtin || added.Contract != cake.Contract || added.Decimals != 18 || added.CreatedAt == "" {
+		t.Errorf("AddToken() = %+v", added)
+	}
+
+	// Neither the symbol nor the contract can be registered twice, even with
+	// different contract casing; built-in symbols are taken too.
+	dupContract := cake
+	dupContract.Symbol = "CAKE2"
+	dupContract.Contract = strings.ToLower(cake.Contract)
+	dupSymbol := cake
+	dupSymbol.Symbol = models.TokenUSDT
+	dupSymbol.Contract = "0x0000000000000000000000000000000000000001"
+	for _, tok := range []models.TokenInfo{cake, dupContract, dupSymbol} {
+		if _, err := d.AddToken(tok); !errors.Is(err, config.ErrTokenExists) {
+			t.Errorf("AddToken(%s %s) error = %v, want ErrTokenExists", tok.Symbol, tok.Contract, err)
+		}
+	}
+
+	// The same symbol on another chain is a different token.
+	if _, err := d.AddToken(models.TokenInfo{Chain: models.ChainSOL, Symbol: "CAKE", Contract: "So11111111111111111111111111111111111111112", Decimals: 9}); err != nil {
+		t.Fatalf("AddToken(SOL CAKE) error = %v", err)
+	}
+
+	// Testnet has no SOL USDT: three built-ins, then the custom tokens.
+	all, err := d.ListTokens("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3+2 || all[len(all)-2].Symbol != "CAKE" || all[len(all)-2].Chain != models.ChainBSC {
+		t.Errorf("ListTokens(all) = %+v, want built-ins then custom tokens in order", all)
+	}
+
+	got, err := d.GetToken(models.ChainBSC, "CAKE")
+	if err != nil || got.CoinGeckoID != "pancakeswap-token" {
+		t.Errorf("GetToken(CAKE) = %+v, %v", got, err)
+	}
+	if _, err := d.GetToken(models.ChainBSC, "NOPE"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetToken(NOPE) error = %v, want sql.ErrNoRows", err)
+	}
+
+	// Tokens are per network.
+	mainnet, err := New(filepath.Join(t.TempDir(), "mainnet.sqlite"), "mainnet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mainnet.Close()
+	if err := mainnet.RunMigrations(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mainnet.GetToken(models.ChainBSC, "CAKE"); err == nil {
+		t.Error("mainnet sees a testnet token")
+	}
+
+	if _, err := d.DeleteToken(models.ChainBSC, models.TokenUSDC); !errors.Is(err, config.ErrBuiltinToken) {
+		t.Errorf("DeleteToken(USDC) error = %v, want ErrBuiltinToken", err)
+	}
+	deleted, err := d.DeleteToken(models.ChainBSC, "CAKE")
+	if err != nil || !deleted {
+		t.Fatalf("DeleteToken(CAKE) = %v, %v, want true", deleted, err)
+	}
+	if deleted, _ := d.DeleteToken(models.ChainBSC, "CAKE"); deleted {
+		t.Error("second DeleteToken(CAKE) = true, want false")
+	}
+}
@@ -21,10 +21,11 @@ func (d *DB) InsertTransaction(tx models.Transaction) (int64, error) {
 	)
 
 	result, err := d.conn.Exec(
-		`INSERT INTO transactions (chain, network, address_index, tx_hash, direction, token, amount, from_address, to_address, status)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		`INSERT INTO transactions (chain, network, account, address_index, tx_hash, direction, token, amount, from_address, to_address, status)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		string(tx.Chain),
 		d.network,
+		d.account,
 		tx.AddressIndex,
 		tx.TxHash,
 		tx.Direction,
@@ -65,13 +66,13 @@ func (d *DB) UpdateTransactionStatus(id int64, status string, confirmedAt *strin
 	var err error
 	if confirmedAt != nil {
 		_, err = d.conn.Exec(
-			"UPDATE transactions SET status = ?, confirmed_at = ? WHERE id = ?",
-			status, *confirmedAt, id,
+			"UPDATE transactions SET status = ?, confirmed_at = ? WHERE id = ? AND account = ?",
+			status, *confirmedAt, id, d.account,
 		)
 	} else {
 		_, err = d.conn.Exec(
-			"UPDATE transactions SET status = ? WHERE id = ?",
-			status, id,
+			"UPDATE transactions SET status = ? WHERE id = ? AND account = ?",
+			status, id, d.account,
 		)
 	}
 	if err != nil {
@@ -99,13 +100,13 @@ func (d *DB) UpdateTransactionStatusByHash(chain, txHash, status string) error {
 	var err error
 	if status == "confirmed" {
 		_, err = d.conn.Exec(
-			"UPDATE transactions SET status = ?, confirmed_at = datetime('now') WHERE chain = ? AND network = ? AND tx_hash = ?",
-			status, chain, d.network, txHash,
+			"UPDATE transactions SET status = ?, confirmed_at = datetime('now') WHERE chain = ? AND network = ? AND account = ? AND tx_hash = ?",
+			status, chain, d.network, d.account, txHash,
 		)
 	} else {
 		_, err = d.conn.Exec(
-			"UPDATE transactions SET status = ? WHERE chain = ? AND network = ? AND tx_hash = ?",
-			status, chain, d.network, txHash,
+			"UPDATE transactions SET status = ? WHERE chain = ? AND network = ? AND account = ? AND tx_hash = ?",
+			status, chain, d.network, d.account, txHash,
 		)
 	}
 	if err != nil {
@@ -132,8 +133,8 @@ func (d *DB) GetTransaction(id int64) (*models.Transaction, error) {
 	err := d.conn.QueryRow(
 		`SELECT id, chain, address_index, tx_hash, direction, token, amount,
 		        from_address, to_address, block_number, status, created_at, confirmed_at
-		 FROM transactions WHERE id = ?`,
-		id,
+		 FROM transactions WHERE id = ? AND account = ?`,
+		id, d.account,
 	).Scan(
 		&tx.ID, &tx.Chain, &tx.AddressIndex, &tx.TxHash, &tx.Direction,
 		&tx.Token, &tx.Amount, &tx.FromAddress, &tx.ToAddress,
@@ -168,8 +169,8 @@ func (d *DB) GetTransactionByHash(chain models.Chain, txHash string) (*models.Tr
 	err := d.conn.QueryRow(
 		`SELECT id, chain, address_index, tx_hash, direction, token, amount,
 		        from_address, to_address, block_number, status, created_at, confirmed_at
-		 FROM transactions WHERE chain = ? AND network = ? AND tx_hash = ? LIMIT 1`,
-		string(chain), d.network, txHash,
+		 FROM transactions WHERE chain = ? AND network = ? AND account = ? AND tx_hash = ? LIMIT 1`,
+		string(chain), d.network, d.account, txHash,
 	).Scan(
 		&tx.ID, &tx.Chain, &tx.AddressIndex, &tx.TxHash, &tx.Direction,
 		&tx.Token, &tx.Amount, &tx.FromAddress, &tx.ToAddress,
@@ -209,7 +210,8 @@ func (d *DB) ListTransactions(chain *models.Chain, page, pageSize int) ([]models
 	})
 }
 
-// ListTransactionsFiltered returns paginated transactions with multiple optional filters.
+// ListTransactionsFiltered returns paginated transactions with multiple optional filters,
+// each carrying the label of its address.
 func (d *DB) ListTransactionsFiltered(filter TransactionFilter) ([]models.Transaction, int64, error) {
 	offset := (filter.Page - 1) * filter.PageSize
 
@@ -223,9 +225,9 @@ func (d *DB) ListTransactionsFiltered(filter TransactionFilter) ([]models.Transa
 		"offset", offset,
 	)
 
-	// Build WHERE clause dynamically. Network is always filtered.
-	conditions := []string{"network = ?"}
-	args := []interface{}{d.network}
+	// Build WHERE clause dynamically. Network and account are always filtered.
+	conditions := []string{"network = ?", "account = ?"}
+	args := []interface{}{d.network, d.account}
 
 	if filter.Chain != nil {
 		conditions = append(conditions, "chain = ?")
@@ -295,6 +297,10 @@ func (d *DB) ListTransactionsFiltered(filter TransactionFilter) ([]models.Transa
 		return nil, 0, fmt.Errorf("iterate transaction rows: %w", err)
 	}
 
+	if err := d.hydrateTransactionLabels(txs); err != nil {
+		return nil, 0, fmt.Errorf("hydrate transaction labels: %w", err)
+	}
+
 	slog.Debug("transactions listed",
 		"total", total,
 		"returned", len(txs),
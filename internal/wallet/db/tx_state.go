@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"fmt"
 	"log/slog"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
 )
 
 // TxStateRow represents a row in the tx_state table.
@@ -12,6 +14,7 @@ type TxStateRow struct {
 	SweepID      string
 	Chain        string
 	Token        string
+	Account      int
 	AddressIndex int
 	FromAddress  string
 	ToAddress    string
@@ -24,7 +27,7 @@ type TxStateRow struct {
 	Error        string
 }
 
-// CreateTxState inserts a new pending transaction state.
+// CreateTxState inserts a new pending transaction state for the database's account.
 func (d *DB) CreateTxState(tx TxStateRow) error {
 	slog.Debug("creating tx state",
 		"id", tx.ID,
@@ -39,12 +42,13 @@ func (d *DB) CreateTxState(tx TxStateRow) error {
 	)
 
 	_, err := d.conn.Exec(
-		`INSERT INTO tx_state (id, sweep_id, chain, network, token, address_index, from_address, to_address, amount, tx_hash, nonce, status, error)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		`INSERT INTO tx_state (id, sweep_id, chain, network, account, token, address_index, from_address, to_address, amount, tx_hash, nonce, status, error)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		tx.ID,
 		tx.SweepID,
 		tx.Chain,
 		d.network,
+		d.account,
 		tx.Token,
 		tx.AddressIndex,
 		tx.FromAddress,
@@ -70,6 +74,8 @@ func (d *DB) CreateTxState(tx TxStateRow) error {
 }
 
 // UpdateTxStatus updates the status, optional tx hash, and optional error for a transaction state.
+// A superseded row (replaced by a fee bump) only moves on to confirmed, when the
+// replaced transaction is mined after all; its own poller cannot mark it uncertain.
 func (d *DB) UpdateTxStatus(id, status, txHash, txError string) error {
 	slog.Debug("updating tx status",
 		"id", id,
@@ -80,11 +86,14 @@ func (d *DB) UpdateTxStatus(id, status, txHash, txError string) error {
 
 	result, err := d.conn.Exec(
 		`UPDATE tx_state SET status = ?, tx_hash = COALESCE(NULLIF(?, ''), tx_hash), error = ?, updated_at = datetime('now')
-		 WHERE id = ?`,
+		 WHERE id = ? AND (status != ? OR ? = ?)`,
 		status,
 		txHash,
 		txError,
 		id,
+		config.TxStateSuperseded,
+		status,
+		config.TxStateConfirmed,
 	)
 	if err != nil {
 		return fmt.Errorf("update tx status %s: %w", id, err)
@@ -100,18 +109,33 @@ func (d *DB) UpdateTxStatus(id, status, txHash, txError string) error {
 	return nil
 }
 
+// SetTxStateNonce records the account nonce a transaction state was signed with,
+// so that later nonce assignments can look it up through idx_tx_state_nonce.
+func (d *DB) SetTxStateNonce(id string, nonce uint64) error {
+	if _, err := d.conn.Exec(
+		`UPDATE tx_state SET nonce = ?, updated_at = datetime('now') WHERE id = ?`,
+		int64(nonce),
+		id,
+	); err != nil {
+		return fmt.Errorf("set tx state nonce %s: %w", id, err)
+	}
+
+	slog.Debug("tx state nonce recorded", "id", id, "nonce", nonce)
+	return nil
+}
+
 // GetPendingTxStates returns all non-terminal transaction states for a chain.
 // Includes: pending, broadcasting, confirming, uncertain.
 func (d *DB) GetPendingTxStates(chain string) ([]TxStateRow, error) {
 	slog.Debug("fetching pending tx states", "chain", chain)
 
 	rows, err := d.conn.Query(
-		`SELECT id, sweep_id, chain, token, address_index, from_address, to_address, amount,
+		`SELECT id, sweep_id, chain, token, account, address_index, from_address, to_address, amount,
 		        COALESCE(tx_hash, '') as tx_hash, COALESCE(nonce, 0) as nonce, status, created_at, updated_at, COALESCE(error, '') as error
 		 FROM tx_state
-		 WHERE chain = ? AND network = ? AND status IN ('pending', 'broadcasting', 'confirming', 'uncertain')
+		 WHERE chain = ? AND network = ? AND account = ? AND status IN ('pending', 'broadcasting', 'confirming', 'uncertain')
 		 ORDER BY created_at ASC`,
-		chain, d.network,
+		chain, d.network, d.account,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("query pending tx states for %s: %w", chain, err)
@@ -126,7 +150,7 @@ func (d *DB) GetTxStatesBySweepID(sweepID string) ([]TxStateRow, error) {
 	slog.Debug("fetching tx states by sweep", "sweepID", sweepID)
 
 	rows, err := d.conn.Query(
-		`SELECT id, sweep_id, chain, token, address_index, from_address, to_address, amount,
+		`SELECT id, sweep_id, chain, token, account, address_index, from_address, to_address, amount,
 		        COALESCE(tx_hash, '') as tx_hash, COALESCE(nonce, 0) as nonce, status, created_at, updated_at, COALESCE(error, '') as error
 		 FROM tx_state
 		 WHERE sweep_id = ?
@@ -151,7 +175,7 @@ func (d *DB) GetTxStateByNonce(chain, fromAddress string, nonce int64) (*TxState
 	)
 
 	row := d.conn.QueryRow(
-		`SELECT id, sweep_id, chain, token, address_index, from_address, to_address, amount,
+		`SELECT id, sweep_id, chain, token, account, address_index, from_address, to_address, amount,
 		        COALESCE(tx_hash, '') as tx_hash, COALESCE(nonce, 0) as nonce, status, created_at, updated_at, COALESCE(error, '') as error
 		 FROM tx_state
 		 WHERE chain = ? AND network = ? AND from_address = ? AND nonce = ?
@@ -164,7 +188,7 @@ func (d *DB) GetTxStateByNonce(chain, fromAddress string, nonce int64) (*TxState
 
 	var tx TxStateRow
 	err := row.Scan(
-		&tx.ID, &tx.SweepID, &tx.Chain, &tx.Token, &tx.AddressIndex,
+		&tx.ID, &tx.SweepID, &tx.Chain, &tx.Token, &tx.Account, &tx.AddressIndex,
 		&tx.FromAddress, &tx.ToAddress, &tx.Amount, &tx.TxHash, &tx.Nonce,
 		&tx.Status, &tx.CreatedAt, &tx.UpdatedAt, &tx.Error,
 	)
@@ -178,6 +202,42 @@ func (d *DB) GetTxStateByNonce(chain, fromAddress string, nonce int64) (*TxState
 	return &tx, nil
 }
 
+// GetTxStateByHash returns the most recent transaction state of a chain with the
+// given tx hash. Returns nil if not found.
+func (d *DB) GetTxStateByHash(chain, txHash string) (*TxStateRow, error) {
+	slog.Debug("fetching tx state by hash",
+		"chain", chain,
+		"txHash", txHash,
+	)
+
+	row := d.conn.QueryRow(
+		`SELECT id, sweep_id, chain, token, account, address_index, from_address, to_address, amount,
+		        COALESCE(tx_hash, '') as tx_hash, COALESCE(nonce, 0) as nonce, status, created_at, updated_at, COALESCE(error, '') as error
+		 FROM tx_state
+		 WHERE chain = ? AND network = ? AND account = ? AND tx_hash = ?
+		 ORDER BY created_at DESC LIMIT 1`,
+		chain,
+		d.network,
+		d.account,
+		txHash,
+	)
+
+	var tx TxStateRow
+	err := row.Scan(
+		&tx.ID, &tx.SweepID, &tx.Chain, &tx.Token, &tx.Account, &tx.AddressIndex,
+		&tx.FromAddress, &tx.ToAddress, &tx.Amount, &tx.TxHash, &tx.Nonce,
+		&tx.Status, &tx.CreatedAt, &tx.UpdatedAt, &tx.Error,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query tx state by hash: %w", err)
+	}
+
+	return &tx, nil
+}
+
 // CountTxStatesByStatus returns a count of transactions per status for a sweep.
 func (d *DB) CountTxStatesByStatus(sweepID string) (map[string]int, error) {
 	slog.Debug("counting tx states by status", "sweepID", sweepID)
@@ -213,18 +273,19 @@ func (d *DB) CountTxStatesByStatus(sweepID string) (map[string]int, error) {
 	return counts, nil
 }
 
-// GetAllPendingTxStates returns all non-terminal transaction states across all chains.
+// GetAllPendingTxStates returns all non-terminal transaction states across all chains
+// of the database's account.
 // Includes: pending, broadcasting, confirming, uncertain.
 func (d *DB) GetAllPendingTxStates() ([]TxStateRow, error) {
 	slog.Debug("fetching all pending tx states")
 
 	rows, err := d.conn.Query(
-		`SELECT id, sweep_id, chain, token, address_index, from_address, to_address, amount,
+		`SELECT id, sweep_id, chain, token, account, address_index, from_address, to_address, amount,
 		        COALESCE(tx_hash, '') as tx_hash, COALESCE(nonce, 0) as nonce, status, created_at, updated_at, COALESCE(error, '') as error
 		 FROM tx_state
-		 WHERE network = ? AND status IN ('pending', 'broadcasting', 'confirming', 'uncertain')
+		 WHERE network = ? AND account = ? AND status IN ('pending', 'broadcasting', 'confirming', 'uncertain')
 		 ORDER BY created_at ASC`,
-		d.network,
+		d.network, d.account,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("query all pending tx states: %w", err)
@@ -235,17 +296,18 @@ func (d *DB) GetAllPendingTxStates() ([]TxStateRow, error) {
 }
 
 // GetRetryableTxStates returns all failed and uncertain transaction states for a sweep.
-// These are the states that can be resumed/retried.
+// These are the states that can be resumed/retried. BTC payout rows are left out,
+// since a resume re-runs a consolidation to the sweep's destination.
 func (d *DB) GetRetryableTxStates(sweepID string) ([]TxStateRow, error) {
 	slog.Debug("fetching retryable tx states", "sweepID", sweepID)
 
 	rows, err := d.conn.Query(
-		`SELECT id, sweep_id, chain, token, address_index, from_address, to_address, amount,
+		`SELECT id, sweep_id, chain, token, account, address_index, from_address, to_address, amount,
 		        COALESCE(tx_hash, '') as tx_hash, COALESCE(nonce, 0) as nonce, status, created_at, updated_at, COALESCE(error, '') as error
 		 FROM tx_state
-		 WHERE sweep_id = ? AND status IN ('failed', 'uncertain')
+		 WHERE sweep_id = ? AND status IN ('failed', 'uncertain') AND from_address != ?
 		 ORDER BY address_index ASC`,
-		sweepID,
+		sweepID, config.FromAddressBTCPayout,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("query retryable tx states for sweep %s: %w", sweepID, err)
@@ -255,6 +317,67 @@ func (d *DB) GetRetryableTxStates(sweepID string) ([]TxStateRow, error) {
 	return scanTxStateRows(rows)
 }
 
+// SetTxStateInputs records the address indices a multi-input transaction state
+// spends, so that resuming it retries each of them rather than its AddressIndex.
+func (d *DB) SetTxStateInputs(id string, addressIndices []int) error {
+	if len(addressIndices) == 0 {
+		return nil
+	}
+
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("begin tx state inputs transaction: %w", err)
+	}
+	defer tx.Rollback() // No-op after successful commit.
+
+	stmt, err := tx.Prepare(
+		`INSERT OR IGNORE INTO tx_state_inputs (tx_state_id, address_index) VALUES (?, ?)`,
+	)
+	if err != nil {
+		return fmt.Errorf("prepare tx state input insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, idx := range addressIndices {
+		if _, err := stmt.Exec(id, idx); err != nil {
+			return fmt.Errorf("insert tx state input %s/%d: %w", id, idx, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx state inputs for %s: %w", id, err)
+	}
+
+	slog.Debug("tx state inputs recorded", "id", id, "count", len(addressIndices))
+	return nil
+}
+
+// GetTxStateInputs returns the address indices recorded for a transaction state
+// by SetTxStateInputs, in ascending order. Empty for single-address states.
+func (d *DB) GetTxStateInputs(id string) ([]int, error) {
+	rows, err := d.conn.Query(
+		`SELECT address_index FROM tx_state_inputs WHERE tx_state_id = ? ORDER BY address_index ASC`,
+		id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query tx state inputs for %s: %w", id, err)
+	}
+	defer rows.Close()
+
+	var indices []int
+	for rows.Next() {
+		var idx int
+		if err := rows.Scan(&idx); err != nil {
+			return nil, fmt.Errorf("scan tx state input: %w", err)
+		}
+		indices = append(indices, idx)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate tx state inputs: %w", err)
+	}
+	return indices, nil
+}
+
 // HasConfirmedTxForAddress checks if a confirmed tx_state exists for a specific
 // target address within a sweep. Used for gas pre-seed idempotency.
 func (d *DB) HasConfirmedTxForAddress(sweepID, toAddress string) (bool, error) {
@@ -302,7 +425,7 @@ func scanTxStateRows(rows *sql.Rows) ([]TxStateRow, error) {
 	for rows.Next() {
 		var tx TxStateRow
 		if err := rows.Scan(
-			&tx.ID, &tx.SweepID, &tx.Chain, &tx.Token, &tx.AddressIndex,
+			&tx.ID, &tx.SweepID, &tx.Chain, &tx.Token, &tx.Account, &tx.AddressIndex,
 			&tx.FromAddress, &tx.ToAddress, &tx.Amount, &tx.TxHash, &tx.Nonce,
 			&tx.Status, &tx.CreatedAt, &tx.UpdatedAt, &tx.Error,
 		); err != nil {
@@ -246,6 +246,86 @@ func TestGetTxStateByNonce(t *testing.T) {
 	}
 }
 
+func TestSetTxStateNonce(t *testing.T) {
+	d := setupTestDB(t)
+
+	tx := TxStateRow{
+		ID:          "tx-set-nonce",
+		SweepID:     "sweep-n",
+		Chain:       "BSC",
+		Token:       "NATIVE",
+		FromAddress: "0xabc",
+		ToAddress:   "0xdef",
+		Amount:      "1000",
+		Status:      config.TxStatePending,
+	}
+	if err := d.CreateTxState(tx); err != nil {
+		t.Fatalf("CreateTxState() error = %v", err)
+	}
+	if err := d.SetTxStateNonce("tx-set-nonce", 17); err != nil {
+		t.Fatalf("SetTxStateNonce() error = %v", err)
+	}
+
+	found, err := d.GetTxStateByNonce("BSC", "0xabc", 17)
+	if err != nil {
+		t.Fatalf("GetTxStateByNonce() error = %v", err)
+	}
+	if found == nil || found.ID != "tx-set-nonce" || found.Nonce != 17 {
+		t.Errorf("GetTxStateByNonce(17) = %+v, want tx-set-nonce", found)
+	}
+}
+
+func TestGetTxStateByHash_SupersededOnlyConfirms(t *testing.T) {
+	d := setupTestDB(t)
+
+	tx := TxStateRow{
+		ID:          "tx-rbf",
+		SweepID:     "sweep-rbf",
+		Chain:       "BTC",
+		Token:       "NATIVE",
+		FromAddress: "consolidated",
+		ToAddress:   "dest",
+		Amount:      "1000",
+		TxHash:      "hash-orig",
+		Status:      config.TxStateConfirming,
+	}
+	if err := d.CreateTxState(tx); err != nil {
+		t.Fatalf("CreateTxState() error = %v", err)
+	}
+
+	found, err := d.GetTxStateByHash("BTC", "hash-orig")
+	if err != nil {
+		t.Fatalf("GetTxStateByHash() error = %v", err)
+	}
+	if found == nil || found.ID != "tx-rbf" {
+		t.Fatalf("GetTxStateByHash() = %+v, want tx-rbf", found)
+	}
+	if missing, err := d.GetTxStateByHash("BSC", "hash-orig"); err != nil || missing != nil {
+		t.Errorf("GetTxStateByHash(BSC) = %+v, %v, want nil", missing, err)
+	}
+
+	// The replaced transaction's poller times out: the row stays superseded.
+	if err := d.UpdateTxStatus("tx-rbf", config.TxStateSuperseded, "", "replaced by hash-new"); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.UpdateTxStatus("tx-rbf", config.TxStateUncertain, "", "timeout"); err != nil {
+		t.Fatal(err)
+	}
+	found, _ = d.GetTxStateByHash("BTC", "hash-orig")
+	if found.Status != config.TxStateSuperseded || found.Error != "replaced by hash-new" {
+		t.Errorf("after uncertain: status = %s, error = %q, want superseded", found.Status, found.Error)
+	}
+
+	// The replaced transaction was mined after all.
+	if err := d.UpdateTxStatus("tx-rbf", config.TxStateConfirmed, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	found, _ = d.GetTxStateByHash("BTC", "hash-orig")
+	if found.Status != config.TxStateConfirmed {
+		t.Errorf("after confirmed: status = %s, want confirmed", found.Status)
+	}
+}
+
 func TestCountTxStatesByStatus(t *testing.T) {
 	d := setupTestDB(t)
 
@@ -313,3 +393,31 @@ func TestTxStateNotFound(t *testing.T) {
 		t.Errorf("expected nil, got %+v", found)
 	}
 }
+
+func TestTxStateInputs(t *testing.T) {
+	d := setupTestDB(t)
+
+	if err := d.SetTxStateInputs("tx-chunk-1", []int{7, 2, 4}); err != nil {
+		t.Fatalf("SetTxStateInputs() error = %v", err)
+	}
+	// Recording an index twice is harmless.
+	if err := d.SetTxStateInputs("tx-chunk-1", []int{4}); err != nil {
+		t.Fatalf("SetTxStateInputs() again error = %v", err)
+	}
+
+	got, err := d.GetTxStateInputs("tx-chunk-1")
+	if err != nil {
+		t.Fatalf("GetTxStateInputs() error = %v", err)
+	}
+	if len(got) != 3 || got[0] != 2 || got[1] != 4 || got[2] != 7 {
+		t.Errorf("GetTxStateInputs() = %v, want [2 4 7]", got)
+	}
+
+	got, err = d.GetTxStateInputs("tx-single")
+	if err != nil {
+		t.Fatalf("GetTxStateInputs(no inputs) error = %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("GetTxStateInputs(no inputs) = %v, want none", got)
+	}
+}
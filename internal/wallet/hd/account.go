@@ -0,0 +1,25 @@
+package hd
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+)
+
+// validateAccount rejects account numbers that cannot be used as a hardened path
+// element (m/purpose'/coin'/account').
+func validateAccount(account uint32) error {
+	if account > config.MaxBIP44Account {
+		return fmt.Errorf("%w: %d exceeds maximum %d", ErrInvalidAccount, account, config.MaxBIP44Account)
+	}
+	return nil
+}
+
+// XPubAccount returns the BIP-44 account number of an account-level extended key
+// (the unhardened value of its child index). Used to make sure a watch-only xpub
+// belongs to the account being initialized.
+func XPubAccount(accountKey *hdkeychain.ExtendedKey) uint32 {
+	return accountKey.ChildIndex() - hdkeychain.HardenedKeyStart
+}
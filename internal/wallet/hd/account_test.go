@@ -0,0 +1,155 @@
+package hd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+)
+
+func TestAccountZeroMatchesDefaultPaths(t *testing.T) {
+	net := &chaincfg.MainNetParams
+	seed, _ := MnemonicToSeed(testMnemonic12)
+	master, _ := DeriveMasterKey(seed, net)
+
+	btc, err := DeriveBTCAddress(master, 0, net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bsc, err := DeriveBSCAddress(master, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sol, err := DeriveSOLAddress(seed, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[models.Chain]string{
+		models.ChainBTC: btc,
+		models.ChainBSC: bsc,
+		models.ChainSOL: sol,
+	}
+	for chain, addr := range want {
+		got, err := DeriveAccountAddressFromSeed(seed, chain, models.BTCAddressP2WPKH, 0, 0, net)
+		if err != nil {
+			t.Fatalf("%s: DeriveAccountAddressFromSeed() error = %v", chain, err)
+		}
+		if got != addr {
+			t.Errorf("%s account 0 = %s, want %s", chain, got, addr)
+		}
+	}
+
+	// BIP-84 reference vector still holds for account 0.
+	if btc != "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu" {
+		t.Errorf("BTC account 0 index 0 = %s", btc)
+	}
+}
+
+func TestAccountsDeriveDistinctAddresses(t *testing.T) {
+	net := &chaincfg.MainNetParams
+	seed, _ := MnemonicToSeed(testMnemonic24)
+
+	for _, chain := range models.AllChains {
+		seen := make(map[string]uint32)
+		for _, account := range []uint32{0, 1, 2} {
+			addr, err := DeriveAccountAddressFromSeed(seed, chain, models.BTCAddressP2WPKH, account, 0, net)
+			if err != nil {
+				t.Fatalf("%s account %d: %v", chain, account, err)
+			}
+			if prev, dup := seen[addr]; dup {
+				t.Errorf("%s: account %d and %d share address %s", chain, prev, account, addr)
+			}
+			seen[addr] = account
+		}
+	}
+}
+
+func TestGenerateAccountAddressesMatchDerivation(t *testing.T) {
+	net := &chaincfg.TestNet3Params
+	seed, _ := MnemonicToSeed(testMnemonic24)
+	master, _ := DeriveMasterKey(seed, net)
+	const account = 3
+
+	btc, err := GenerateBTCAccountAddresses(master, models.BTCAddressP2WPKH, account, 4, net, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bsc, err := GenerateBSCAccountAddresses(master, account, 4, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sol, err := GenerateSOLAccountAddresses(seed, account, 4, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for chain, addrs := range map[models.Chain][]models.Address{
+		models.ChainBTC: btc,
+		models.ChainBSC: bsc,
+		models.ChainSOL: sol,
+	} {
+		for i, a := range addrs {
+			want, err := DeriveAccountAddressFromSeed(seed, chain, models.BTCAddressP2WPKH, account, uint32(i), net)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if a.Address != want {
+				t.Errorf("%s index %d: generated %s, derived %s", chain, i, a.Address, want)
+			}
+		}
+	}
+}
+
+func TestWatchOnlyAccountXPubMatchesMnemonic(t *testing.T) {
+	net := &chaincfg.MainNetParams
+	seed, _ := MnemonicToSeed(testMnemonic24)
+	master, _ := DeriveMasterKey(seed, net)
+
+	key := master
+	for _, idx := range []uint32{84, 0, 5} {
+		key, _ = key.Derive(hdkeychain.HardenedKeyStart + idx)
+	}
+	pub, _ := key.Neuter()
+
+	accountKey, err := ParseBTCAccountXPub(pub.String(), models.BTCAddressP2WPKH, net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := XPubAccount(accountKey); got != 5 {
+		t.Errorf("XPubAccount() = %d, want 5", got)
+	}
+
+	parent, err := DeriveExternalParentFromAccount(accountKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	watchOnly, err := DeriveBTCAddressFromParent(parent, 7, net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := DeriveAccountAddressFromSeed(seed, models.ChainBTC, models.BTCAddressP2WPKH, 5, 7, net)
+	if watchOnly != want {
+		t.Errorf("watch-only account 5 = %s, mnemonic = %s", watchOnly, want)
+	}
+}
+
+func TestInvalidAccountRejected(t *testing.T) {
+	seed, _ := MnemonicToSeed(testMnemonic24)
+	master, _ := DeriveMasterKey(seed, &chaincfg.MainNetParams)
+	tooHigh := uint32(config.MaxBIP44Account) + 1
+
+	if _, err := DeriveBTCAccountParentKey(master, models.BTCAddressP2WPKH, tooHigh, &chaincfg.MainNetParams); !errors.Is(err, ErrInvalidAccount) {
+		t.Errorf("DeriveBTCAccountParentKey() error = %v, want ErrInvalidAccount", err)
+	}
+	if _, err := DeriveBSCAccountParentKey(master, tooHigh); !errors.Is(err, ErrInvalidAccount) {
+		t.Errorf("DeriveBSCAccountParentKey() error = %v, want ErrInvalidAccount", err)
+	}
+	if _, err := DeriveSOLAccountAddress(seed, tooHigh, 0); !errors.Is(err, ErrInvalidAccount) {
+		t.Errorf("DeriveSOLAccountAddress() error = %v, want ErrInvalidAccount", err)
+	}
+}
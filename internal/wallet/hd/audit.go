@@ -0,0 +1,256 @@
+package hd
+
+import (
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+)
+
+// IndexDeriver derives the address at index for one chain and BIP-44 account.
+// Implementations must be safe for concurrent use.
+type IndexDeriver func(index uint32) (string, error)
+
+// NewSeedIndexDeriver returns an IndexDeriver over a BIP-39 seed. The account-level
+// parent key is derived once, so each call only performs the last derivation steps.
+func NewSeedIndexDeriver(seed []byte, chain models.Chain, btcType models.BTCAddressType, account uint32, net *chaincfg.Params) (IndexDeriver, error) {
+	switch chain {
+	case models.ChainBTC:
+		masterKey, err := DeriveMasterKey(seed, net)
+		if err != nil {
+			return nil, err
+		}
+		parent, err := DeriveBTCAccountParentKey(masterKey, btcType, account, net)
+		if err != nil {
+			return nil, fmt.Errorf("derive BTC parent key: %w", err)
+		}
+		return NewParentIndexDeriver(parent, chain, btcType, net)
+	case models.ChainBSC:
+		masterKey, err := DeriveMasterKey(seed, net)
+		if err != nil {
+			return nil, err
+		}
+		parent, err := DeriveBSCAccountParentKey(masterKey, account)
+		if err != nil {
+			return nil, fmt.Errorf("derive BSC parent key: %w", err)
+		}
+		return NewParentIndexDeriver(parent, chain, btcType, net)
+	case models.ChainSOL:
+		if err := validateAccount(account); err != nil {
+			return nil, err
+		}
+		parent, err := DeriveSOLParentKey(seed)
+		if err != nil {
+			return nil, fmt.Errorf("derive SOL parent key: %w", err)
+		}
+		return func(index uint32) (string, error) {
+			return DeriveSOLAccountAddressFromParent(parent, account, index)
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported chain %q", ErrDerivation, chain)
+	}
+}
+
+// NewSeedChangeIndexDeriver returns an IndexDeriver over the BTC internal (change)
+// chain m/purpose'/coin'/account'/1 of a BIP-39 seed.
+func NewSeedChangeIndexDeriver(seed []byte, btcType models.BTCAddressType, account uint32, net *chaincfg.Params) (IndexDeriver, error) {
+	masterKey, err := DeriveMasterKey(seed, net)
+	if err != nil {
+		return nil, err
+	}
+	accountKey, err := DeriveBTCAccountKey(masterKey, btcType, account, net)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := DeriveInternalParentFromAccount(accountKey)
+	if err != nil {
+		return nil, fmt.Errorf("derive BTC change key: %w", err)
+	}
+	return NewParentIndexDeriver(parent, models.ChainBTC, btcType, net)
+}
+
+// NewParentIndexDeriver returns an IndexDeriver over a pre-derived chain key
+// (account/0, or account/1 for BTC change) of BTC or BSC. The key may be public, which
+// is how watch-only wallets audit their addresses against an xpub. SOL has no public
+// derivation.
+func NewParentIndexDeriver(parentKey *hdkeychain.ExtendedKey, chain models.Chain, btcType models.BTCAddressType, net *chaincfg.Params) (IndexDeriver, error) {
+	switch chain {
+	case models.ChainBTC:
+		return func(index uint32) (string, error) {
+			return DeriveBTCTypedAddressFromParent(parentKey, btcType, index, net)
+		}, nil
+	case models.ChainBSC:
+		return func(index uint32) (string, error) {
+			return DeriveBSCAddressFromParent(parentKey, index)
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: no public derivation for chain %q", ErrDerivation, chain)
+	}
+}
+
+// AddressMismatch is a stored address that differs from the one derived at its index.
+type AddressMismatch struct {
+	Index   int    `json:"index"`
+	Stored  string `json:"stored"`
+	Derived string `json:"derived"`
+}
+
+// AuditReport is the outcome of auditing one chain's stored addresses.
+type AuditReport struct {
+	Chain   models.Chain `json:"chain"`
+	Stored  int          `json:"stored"`
+	Checked int          `json:"checked"`
+	Sampled bool         `json:"sampled"`
+	// Missing counts indices below the highest stored one that have no row.
+	// Only computed for full audits.
+	Missing       int               `json:"missing"`
+	MismatchCount int               `json:"mismatch_count"`
+	Mismatches    []AddressMismatch `json:"mismatches"`
+	Duration      string            `json:"duration"`
+}
+
+// OK reports whether every checked address matched and no index was missing.
+func (r *AuditReport) OK() bool {
+	return r.MismatchCount == 0 && r.Missing == 0
+}
+
+// AuditAddresses streams the stored addresses of chain and re-derives each one with
+// derive on runtime.NumCPU() workers. db must already be scoped to the account that
+// derive covers. When sample > 0, only that many addresses chosen uniformly at random
+// are checked. At most config.AuditMaxReportedMismatches mismatches are listed, in
+// index order; MismatchCount always holds the full count.
+func AuditAddresses(db AddressStreamer, chain models.Chain, derive IndexDeriver, sample int) (*AuditReport, error) {
+	numWorkers := runtime.NumCPU()
+	start := time.Now()
+
+	report := &AuditReport{
+		Chain:      chain,
+		Sampled:    sample > 0,
+		Mismatches: []AddressMismatch{},
+	}
+
+	slog.Info("auditing addresses",
+		"chain", chain,
+		"sample", sample,
+		"workers", numWorkers,
+	)
+
+	work := make(chan models.Address, numWorkers*64)
+	var checked, mismatches atomic.Int64
+	var firstErr atomic.Value
+	var mu sync.Mutex
+
+	var wg sync.WaitGroup
+	for w := 0; w < numWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for addr := range work {
+				// Drain without deriving once another worker hit an error.
+				if firstErr.Load() != nil {
+					continue
+				}
+
+				derived, err := derive(uint32(addr.AddressIndex))
+				if err != nil {
+					firstErr.CompareAndSwap(nil, fmt.Errorf("derive %s address at index %d: %w", chain, addr.AddressIndex, err))
+					continue
+				}
+
+				if derived != addr.Address {
+					mismatches.Add(1)
+					mu.Lock()
+					report.Mismatches = append(report.Mismatches, AddressMismatch{
+						Index:   addr.AddressIndex,
+						Stored:  addr.Address,
+						Derived: derived,
+					})
+					mu.Unlock()
+				}
+
+				if n := checked.Add(1); n%100_000 == 0 {
+					slog.Info("audit progress", "chain", chain, "checked", n)
+				}
+			}
+		}()
+	}
+
+	var streamErr error
+	if sample > 0 {
+		var picked []models.Address
+		picked, report.Stored, streamErr = sampleAddresses(db, chain, sample)
+		for _, addr := range picked {
+			work <- addr
+		}
+	} else {
+		next := 0
+		streamErr = db.StreamAddresses(chain, func(addr models.Address) error {
+			if errVal := firstErr.Load(); errVal != nil {
+				return errVal.(error)
+			}
+			if addr.AddressIndex > next {
+				report.Missing += addr.AddressIndex - next
+			}
+			next = addr.AddressIndex + 1
+			report.Stored++
+			work <- addr
+			return nil
+		})
+	}
+	close(work)
+	wg.Wait()
+
+	if errVal := firstErr.Load(); errVal != nil {
+		return nil, errVal.(error)
+	}
+	if streamErr != nil {
+		return nil, fmt.Errorf("stream addresses for audit: %w", streamErr)
+	}
+
+	report.Checked = int(checked.Load())
+	report.MismatchCount = int(mismatches.Load())
+	sort.Slice(report.Mismatches, func(i, j int) bool {
+		return report.Mismatches[i].Index < report.Mismatches[j].Index
+	})
+	if len(report.Mismatches) > config.AuditMaxReportedMismatches {
+		report.Mismatches = report.Mismatches[:config.AuditMaxReportedMismatches]
+	}
+	report.Duration = time.Since(start).Round(time.Millisecond).String()
+
+	slog.Info("address audit complete",
+		"chain", chain,
+		"stored", report.Stored,
+		"checked", report.Checked,
+		"missing", report.Missing,
+		"mismatches", report.MismatchCount,
+		"duration", report.Duration,
+	)
+	return report, nil
+}
+
+// sampleAddresses picks n stored addresses uniformly at random in one streaming pass
+// (reservoir sampling) and returns them with the number of addresses streamed.
+func sampleAddresses(db AddressStreamer, chain models.Chain, n int) ([]models.Address, int, error) {
+	picked := make([]models.Address, 0, n)
+	seen := 0
+	err := db.StreamAddresses(chain, func(addr models.Address) error {
+		seen++
+		if len(picked) < n {
+			picked = append(picked, addr)
+		} else if j := rand.IntN(seen); j < n {
+			picked[j] = addr
+		}
+		return nil
+	})
+	return picked, seen, err
+}
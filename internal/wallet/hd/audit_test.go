@@ -0,0 +1,156 @@
+package hd
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+
+	"github.com/Fantasim/hdpay/internal/shared/models"
+)
+
+// auditFixture returns count addresses of chain derived from the test mnemonic.
+func auditFixture(t *testing.T, chain models.Chain, count int) ([]models.Address, IndexDeriver) {
+	t.Helper()
+	seed, err := MnemonicToSeed(testMnemonic24)
+	if err != nil {
+		t.Fatal(err)
+	}
+	derive, err := NewSeedIndexDeriver(seed, chain, models.BTCAddressP2WPKH, 0, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("NewSeedIndexDeriver() error = %v", err)
+	}
+
+	addrs := make([]models.Address, count)
+	for i := range addrs {
+		addr, err := DeriveAccountAddressFromSeed(seed, chain, models.BTCAddressP2WPKH, 0, uint32(i), &chaincfg.MainNetParams)
+		if err != nil {
+			t.Fatal(err)
+		}
+		addrs[i] = models.Address{Chain: chain, AddressIndex: i, Address: addr}
+	}
+	return addrs, derive
+}
+
+func TestAuditAddressesClean(t *testing.T) {
+	for _, chain := range models.AllChains {
+		t.Run(string(chain), func(t *testing.T) {
+			addrs, derive := auditFixture(t, chain, 50)
+			mock := &mockStreamer{addresses: map[models.Chain][]models.Address{chain: addrs}}
+
+			report, err := AuditAddresses(mock, chain, derive, 0)
+			if err != nil {
+				t.Fatalf("AuditAddresses() error = %v", err)
+			}
+			if !report.OK() || report.Stored != 50 || report.Checked != 50 {
+				t.Errorf("report = %+v, want 50 stored and checked, no findings", report)
+			}
+		})
+	}
+}
+
+func TestAuditAddressesTampered(t *testing.T) {
+	addrs, derive := auditFixture(t, models.ChainBTC, 40)
+	want := addrs[7].Address
+	addrs[7].Address = addrs[8].Address
+	addrs[31].Address = "bc1qattacker"
+	// Drop indices 20 and 21.
+	addrs = append(addrs[:20], addrs[22:]...)
+	mock := &mockStreamer{addresses: map[models.Chain][]models.Address{models.ChainBTC: addrs}}
+
+	report, err := AuditAddresses(mock, models.ChainBTC, derive, 0)
+	if err != nil {
+		t.Fatalf("AuditAddresses() error = %v", err)
+	}
+	if report.OK() {
+		t.Fatal("tampered address set reported OK")
+	}
+	if report.MismatchCount != 2 || len(report.Mismatches) != 2 {
+		t.Fatalf("mismatches = %+v, want indices 7 and 31", report.Mismatches)
+	}
+	if m := report.Mismatches[0]; m.Index != 7 || m.Derived != want {
+		t.Errorf("first mismatch = %+v, want index 7 deriving %s", m, want)
+	}
+	if report.Mismatches[1].Index != 31 {
+		t.Errorf("second mismatch index = %d, want 31", report.Mismatches[1].Index)
+	}
+	if report.Missing != 2 {
+		t.Errorf("Missing = %d, want 2", report.Missing)
+	}
+}
+
+func TestAuditAddressesSample(t *testing.T) {
+	addrs, derive := auditFixture(t, models.ChainBSC, 60)
+	mock := &mockStreamer{addresses: map[models.Chain][]models.Address{models.ChainBSC: addrs}}
+
+	report, err := AuditAddresses(mock, models.ChainBSC, derive, 10)
+	if err != nil {
+		t.Fatalf("AuditAddresses() error = %v", err)
+	}
+	if !report.Sampled || report.Checked != 10 || report.Stored != 60 || !report.OK() {
+		t.Errorf("report = %+v, want 10 of 60 checked, no findings", report)
+	}
+
+	// A sample larger than the table checks everything.
+	report, err = AuditAddresses(mock, models.ChainBSC, derive, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Checked != 60 {
+		t.Errorf("Checked = %d, want 60", report.Checked)
+	}
+}
+
+func TestNewParentIndexDeriverMatchesSeed(t *testing.T) {
+	seed, _ := MnemonicToSeed(testMnemonic24)
+	masterKey, err := DeriveMasterKey(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent, err := DeriveBTCAccountParentKey(masterKey, models.BTCAddressP2WPKH, 0, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	public, err := parent.Neuter()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fromXPub, err := NewParentIndexDeriver(public, models.ChainBTC, models.BTCAddressP2WPKH, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromSeed, _ := NewSeedIndexDeriver(seed, models.ChainBTC, models.BTCAddressP2WPKH, 0, &chaincfg.MainNetParams)
+
+	for _, index := range []uint32{0, 1, 4242} {
+		a, _ := fromXPub(index)
+		b, _ := fromSeed(index)
+		if a == "" || a != b {
+			t.Errorf("index %d: xpub derived %q, seed derived %q", index, a, b)
+		}
+	}
+
+	if _, err := NewParentIndexDeriver(public, models.ChainSOL, "", nil); err == nil {
+		t.Error("NewParentIndexDeriver(SOL) should fail: no public derivation")
+	}
+}
+
+func TestNewSeedChangeIndexDeriver(t *testing.T) {
+	seed, err := MnemonicToSeed(testMnemonic12)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	derive, err := NewSeedChangeIndexDeriver(seed, models.BTCAddressP2WPKH, 0, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("NewSeedChangeIndexDeriver() error = %v", err)
+	}
+
+	// BIP-84 test vector: first change address m/84'/0'/0'/1/0.
+	got, err := derive(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "bc1q8c6fshw2dlwun7ekn9qwf37cu2rn755upcp6el"; got != want {
+		t.Errorf("change index 0 = %s, want %s", got, want)
+	}
+}
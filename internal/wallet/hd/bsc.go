@@ -14,6 +14,15 @@ import (
 // This eliminates redundant derivation of the first 4 levels during batch generation.
 // The returned key is safe for concurrent read-only use (Derive creates new child keys).
 func DeriveBSCParentKey(masterKey *hdkeychain.ExtendedKey) (*hdkeychain.ExtendedKey, error) {
+	return DeriveBSCAccountParentKey(masterKey, 0)
+}
+
+// DeriveBSCAccountParentKey pre-derives the BSC parent key to m/44'/60'/account'/0.
+func DeriveBSCAccountParentKey(masterKey *hdkeychain.ExtendedKey, account uint32) (*hdkeychain.ExtendedKey, error) {
+	if err := validateAccount(account); err != nil {
+		return nil, err
+	}
+
 	// m/44'
 	purpose, err := masterKey.Derive(hdkeychain.HardenedKeyStart + uint32(config.BIP44Purpose))
 	if err != nil {
@@ -26,29 +35,24 @@ func DeriveBSCParentKey(masterKey *hdkeychain.ExtendedKey) (*hdkeychain.Extended
 		return nil, fmt.Errorf("derive BSC coin key: %w", err)
 	}
 
-	// m/44'/60'/0'
-	account, err := coin.Derive(hdkeychain.HardenedKeyStart + 0)
+	// m/44'/60'/account'
+	accountKey, err := coin.Derive(hdkeychain.HardenedKeyStart + account)
 	if err != nil {
 		return nil, fmt.Errorf("derive BSC account key: %w", err)
 	}
 
-	// m/44'/60'/0'/0
-	change, err := account.Derive(0)
+	// m/44'/60'/account'/0
+	change, err := DeriveExternalParentFromAccount(accountKey)
 	if err != nil {
 		return nil, fmt.Errorf("derive BSC change key: %w", err)
 	}
 
-	// Force lazy pubkey computation so concurrent Derive() calls don't race.
-	if _, err := change.ECPubKey(); err != nil {
-		return nil, fmt.Errorf("warm BSC parent pubkey cache: %w", err)
-	}
-
-	slog.Debug("pre-derived BSC parent key", "path", "m/44'/60'/0'/0")
+	slog.Debug("pre-derived BSC parent key", "path", fmt.Sprintf("m/44'/60'/%d'/0", account))
 	return change, nil
 }
 
 // DeriveBSCAddressFromParent derives a BSC address from a pre-derived parent key.
-// parentKey must be at m/44'/60'/0'/0 (from DeriveBSCParentKey).
+// parentKey must be at m/44'/60'/account'/0 (from DeriveBSCAccountParentKey).
 // Only performs 1 derivation (index) instead of 5.
 func DeriveBSCAddressFromParent(parentKey *hdkeychain.ExtendedKey, index uint32) (string, error) {
 	child, err := parentKey.Derive(index)
@@ -56,13 +60,13 @@ func DeriveBSCAddressFromParent(parentKey *hdkeychain.ExtendedKey, index uint32)
 		return "", fmt.Errorf("derive BSC child key at index %d: %w", index, err)
 	}
 
-	privKey, err := child.ECPrivKey()
+	// Public-key derivation works for both private and watch-only (xpub) parents.
+	pubKey, err := child.ECPubKey()
 	if err != nil {
-		return "", fmt.Errorf("get BSC private key at index %d: %w", index, err)
+		return "", fmt.Errorf("get BSC public key at index %d: %w", index, err)
 	}
 
-	ecdsaPrivKey := privKey.ToECDSA()
-	addr := crypto.PubkeyToAddress(ecdsaPrivKey.PublicKey)
+	addr := crypto.PubkeyToAddress(*pubKey.ToECDSA())
 
 	return addr.Hex(), nil
 }
@@ -4,59 +4,143 @@ import (
 	"fmt"
 	"log/slog"
 
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/btcutil/hdkeychain"
 	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
 
 	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
 )
 
 // DeriveBTCParentKey pre-derives the BTC parent key to m/84'/coin'/0'/0.
 // This eliminates redundant derivation of the first 4 levels during batch generation.
 // The returned key is safe for concurrent read-only use (Derive creates new child keys).
 func DeriveBTCParentKey(masterKey *hdkeychain.ExtendedKey, net *chaincfg.Params) (*hdkeychain.ExtendedKey, error) {
-	coinType := uint32(config.BTCCoinType)
-	if net == &chaincfg.TestNet3Params {
-		coinType = uint32(config.BTCTestCoinType)
+	return DeriveBTCAccountParentKey(masterKey, models.BTCAddressP2WPKH, 0, net)
+}
+
+// btcPurpose returns the BIP purpose an address type is derived under.
+func btcPurpose(addrType models.BTCAddressType) (uint32, error) {
+	switch addrType {
+	case models.BTCAddressP2WPKH:
+		return config.BIP84Purpose, nil
+	case models.BTCAddressP2TR:
+		return config.BIP86Purpose, nil
+	case models.BTCAddressP2SHP2WPKH:
+		return config.BIP49Purpose, nil
+	case models.BTCAddressP2PKH:
+		return config.BIP44Purpose, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrUnsupportedAddressType, addrType)
+	}
+}
+
+// DeriveBTCAccountParentKey pre-derives the BTC parent key to m/purpose'/coin'/account'/0,
+// where purpose follows the address type (84 P2WPKH, 86 P2TR, 49 P2SH-P2WPKH, 44 P2PKH).
+func DeriveBTCAccountParentKey(masterKey *hdkeychain.ExtendedKey, addrType models.BTCAddressType, account uint32, net *chaincfg.Params) (*hdkeychain.ExtendedKey, error) {
+	accountKey, err := DeriveBTCAccountKey(masterKey, addrType, account, net)
+	if err != nil {
+		return nil, err
 	}
 
-	// m/84'
-	purpose, err := masterKey.Derive(hdkeychain.HardenedKeyStart + uint32(config.BIP84Purpose))
+	// m/purpose'/coin'/account'/0
+	change, err := DeriveExternalParentFromAccount(accountKey)
+	if err != nil {
+		return nil, fmt.Errorf("derive BTC change key: %w", err)
+	}
+
+	slog.Debug("pre-derived BTC parent key",
+		"path", BTCAccountPath(addrType, account, net)+"/0",
+		"addressType", addrType,
+	)
+	return change, nil
+}
+
+// DeriveBTCAccountKey derives the BIP-44 style account key m/purpose'/coin'/account'
+// for the address type. Its public half is the account xpub.
+func DeriveBTCAccountKey(masterKey *hdkeychain.ExtendedKey, addrType models.BTCAddressType, account uint32, net *chaincfg.Params) (*hdkeychain.ExtendedKey, error) {
+	if err := validateAccount(account); err != nil {
+		return nil, err
+	}
+
+	purposeIdx, err := btcPurpose(addrType)
+	if err != nil {
+		return nil, err
+	}
+
+	// m/purpose'
+	purpose, err := masterKey.Derive(hdkeychain.HardenedKeyStart + purposeIdx)
 	if err != nil {
 		return nil, fmt.Errorf("derive BTC purpose key: %w", err)
 	}
 
-	// m/84'/coin'
-	coin, err := purpose.Derive(hdkeychain.HardenedKeyStart + coinType)
+	// m/purpose'/coin'
+	coin, err := purpose.Derive(hdkeychain.HardenedKeyStart + btcCoinType(net))
 	if err != nil {
 		return nil, fmt.Errorf("derive BTC coin key: %w", err)
 	}
 
-	// m/84'/coin'/0'
-	account, err := coin.Derive(hdkeychain.HardenedKeyStart + 0)
+	// m/purpose'/coin'/account'
+	accountKey, err := coin.Derive(hdkeychain.HardenedKeyStart + account)
 	if err != nil {
 		return nil, fmt.Errorf("derive BTC account key: %w", err)
 	}
+	return accountKey, nil
+}
 
-	// m/84'/coin'/0'/0
-	change, err := account.Derive(0)
-	if err != nil {
-		return nil, fmt.Errorf("derive BTC change key: %w", err)
+// btcCoinType returns the SLIP-44 coin type for the network (0 mainnet, 1 testnet).
+func btcCoinType(net *chaincfg.Params) uint32 {
+	if net == &chaincfg.TestNet3Params {
+		return uint32(config.BTCTestCoinType)
 	}
+	return uint32(config.BTCCoinType)
+}
 
-	// Force lazy pubkey computation so concurrent Derive() calls don't race.
-	if _, err := change.ECPubKey(); err != nil {
-		return nil, fmt.Errorf("warm BTC parent pubkey cache: %w", err)
+// BTCAccountPath returns the account derivation path "m/purpose'/coin'/account'" for
+// the address type, or "" for an unsupported type.
+func BTCAccountPath(addrType models.BTCAddressType, account uint32, net *chaincfg.Params) string {
+	purpose, err := btcPurpose(addrType)
+	if err != nil {
+		return ""
 	}
+	return fmt.Sprintf("m/%d'/%d'/%d'", purpose, btcCoinType(net), account)
+}
 
-	slog.Debug("pre-derived BTC parent key", "path", fmt.Sprintf("m/84'/%d'/0'/0", coinType))
-	return change, nil
+// BTCKeyPath returns the full derivation path m/purpose'/coin'/account'/0/index of
+// an external address as BIP-32 child numbers, the form PSBT key origins carry.
+func BTCKeyPath(addrType models.BTCAddressType, account, index uint32, net *chaincfg.Params) ([]uint32, error) {
+	if err := validateAccount(account); err != nil {
+		return nil, err
+	}
+	purpose, err := btcPurpose(addrType)
+	if err != nil {
+		return nil, err
+	}
+	if index >= hdkeychain.HardenedKeyStart {
+		return nil, fmt.Errorf("address index %d out of range", index)
+	}
+	return []uint32{
+		hdkeychain.HardenedKeyStart + purpose,
+		hdkeychain.HardenedKeyStart + btcCoinType(net),
+		hdkeychain.HardenedKeyStart + account,
+		0,
+		index,
+	}, nil
 }
 
-// DeriveBTCAddressFromParent derives a BTC address from a pre-derived parent key.
-// parentKey must be at m/84'/coin'/0'/0 (from DeriveBTCParentKey).
+// DeriveBTCAddressFromParent derives a BTC Native SegWit address from a pre-derived parent key.
+// parentKey must be at m/84'/coin'/account'/0 (from DeriveBTCAccountParentKey).
 // Only performs 1 derivation (index) instead of 5.
 func DeriveBTCAddressFromParent(parentKey *hdkeychain.ExtendedKey, index uint32, net *chaincfg.Params) (string, error) {
+	return DeriveBTCTypedAddressFromParent(parentKey, models.BTCAddressP2WPKH, index, net)
+}
+
+// DeriveBTCTypedAddressFromParent derives a BTC address of the given type from a
+// pre-derived parent key at m/purpose'/coin'/account'/0.
+func DeriveBTCTypedAddressFromParent(parentKey *hdkeychain.ExtendedKey, addrType models.BTCAddressType, index uint32, net *chaincfg.Params) (string, error) {
 	child, err := parentKey.Derive(index)
 	if err != nil {
 		return "", fmt.Errorf("derive BTC child key at index %d: %w", index, err)
@@ -67,15 +151,64 @@ func DeriveBTCAddressFromParent(parentKey *hdkeychain.ExtendedKey, index uint32,
 		return "", fmt.Errorf("get BTC public key at index %d: %w", index, err)
 	}
 
-	witnessProg := btcutil.Hash160(pubKey.SerializeCompressed())
-	addr, err := btcutil.NewAddressWitnessPubKeyHash(witnessProg, net)
+	addr, err := BTCAddressFromPubKey(pubKey, addrType, net)
 	if err != nil {
-		return "", fmt.Errorf("create BTC bech32 address at index %d: %w", index, err)
+		return "", fmt.Errorf("create BTC %s address at index %d: %w", addrType, index, err)
 	}
 
 	return addr.EncodeAddress(), nil
 }
 
+// BTCAddressFromPubKey encodes a public key as a BTC address of the given type.
+// P2TR uses the BIP-86 output key (internal key tweaked with an empty script tree).
+func BTCAddressFromPubKey(pubKey *btcec.PublicKey, addrType models.BTCAddressType, net *chaincfg.Params) (btcutil.Address, error) {
+	keyHash := btcutil.Hash160(pubKey.SerializeCompressed())
+
+	switch addrType {
+	case models.BTCAddressP2WPKH:
+		return btcutil.NewAddressWitnessPubKeyHash(keyHash, net)
+	case models.BTCAddressP2TR:
+		outputKey := txscript.ComputeTaprootKeyNoScript(pubKey)
+		return btcutil.NewAddressTaproot(schnorr.SerializePubKey(outputKey), net)
+	case models.BTCAddressP2SHP2WPKH:
+		return btcutil.NewAddressScriptHash(P2SHP2WPKHRedeemScript(keyHash), net)
+	case models.BTCAddressP2PKH:
+		return btcutil.NewAddressPubKeyHash(keyHash, net)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedAddressType, addrType)
+	}
+}
+
+// P2SHP2WPKHRedeemScript returns the P2WPKH witness program (OP_0 <20-byte hash>)
+// that a nested SegWit address commits to and its scriptSig pushes.
+func P2SHP2WPKHRedeemScript(keyHash []byte) []byte {
+	script := make([]byte, 0, 2+len(keyHash))
+	script = append(script, txscript.OP_0, txscript.OP_DATA_20)
+	return append(script, keyHash...)
+}
+
+// BTCAddressTypeOf reports the address type of an encoded BTC address. Every P2SH
+// address is assumed to be nested P2WPKH, the only script-hash form HDPay derives.
+func BTCAddressTypeOf(address string, net *chaincfg.Params) (models.BTCAddressType, error) {
+	decoded, err := btcutil.DecodeAddress(address, net)
+	if err != nil {
+		return "", fmt.Errorf("decode BTC address %q: %w", address, err)
+	}
+
+	switch decoded.(type) {
+	case *btcutil.AddressWitnessPubKeyHash:
+		return models.BTCAddressP2WPKH, nil
+	case *btcutil.AddressTaproot:
+		return models.BTCAddressP2TR, nil
+	case *btcutil.AddressScriptHash:
+		return models.BTCAddressP2SHP2WPKH, nil
+	case *btcutil.AddressPubKeyHash:
+		return models.BTCAddressP2PKH, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedAddressType, address)
+	}
+}
+
 // DeriveBTCAddress derives a BTC Native SegWit (bech32) address at the given index.
 // Path: m/84'/0'/0'/0/N (mainnet) or m/84'/1'/0'/0/N (testnet) per BIP-84.
 func DeriveBTCAddress(masterKey *hdkeychain.ExtendedKey, index uint32, net *chaincfg.Params) (string, error) {
@@ -1,11 +1,16 @@
 package hd
 
 import (
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"testing"
 
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
 	"github.com/btcsuite/btcd/chaincfg"
+
+	"github.com/Fantasim/hdpay/internal/shared/models"
 )
 
 func TestDeriveBTCAddressKnownVector(t *testing.T) {
@@ -130,3 +135,108 @@ func TestDeriveBTCAddressDeterministic(t *testing.T) {
 		t.Errorf("DeriveBTCAddress() not deterministic: %v != %v", addr1, addr2)
 	}
 }
+
+func TestDeriveBTCTypedAddressKnownVectors(t *testing.T) {
+	// Reference vectors from BIP-86, BIP-49 and BIP-44 for the 12-word mnemonic.
+	tests := []struct {
+		addrType models.BTCAddressType
+		want     string
+	}{
+		{models.BTCAddressP2WPKH, "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu"},
+		{models.BTCAddressP2TR, "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr"},
+		{models.BTCAddressP2SHP2WPKH, "37VucYSaXLCAsxYyAPfbSi9eh4iEcbShgf"},
+		{models.BTCAddressP2PKH, "1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA"},
+	}
+
+	seed, _ := MnemonicToSeed(testMnemonic12)
+	masterKey, _ := DeriveMasterKey(seed, &chaincfg.MainNetParams)
+
+	for _, tt := range tests {
+		t.Run(string(tt.addrType), func(t *testing.T) {
+			parent, err := DeriveBTCAccountParentKey(masterKey, tt.addrType, 0, &chaincfg.MainNetParams)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := DeriveBTCTypedAddressFromParent(parent, tt.addrType, 0, &chaincfg.MainNetParams)
+			if err != nil {
+				t.Fatalf("DeriveBTCTypedAddressFromParent() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("%s index 0 = %v, want %v", tt.addrType, got, tt.want)
+			}
+
+			gotType, err := BTCAddressTypeOf(got, &chaincfg.MainNetParams)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gotType != tt.addrType {
+				t.Errorf("BTCAddressTypeOf(%s) = %s, want %s", got, gotType, tt.addrType)
+			}
+		})
+	}
+}
+
+func TestDeriveBTCTypedAddressTestnetPrefixes(t *testing.T) {
+	prefixes := map[models.BTCAddressType]string{
+		models.BTCAddressP2WPKH:     "tb1q",
+		models.BTCAddressP2TR:       "tb1p",
+		models.BTCAddressP2SHP2WPKH: "2",
+	}
+
+	seed, _ := MnemonicToSeed(testMnemonic24)
+	masterKey, _ := DeriveMasterKey(seed, &chaincfg.TestNet3Params)
+
+	for addrType, prefix := range prefixes {
+		addrs, err := GenerateBTCAccountAddresses(masterKey, addrType, 0, 3, &chaincfg.TestNet3Params, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", addrType, err)
+		}
+		for _, a := range addrs {
+			if !strings.HasPrefix(a.Address, prefix) {
+				t.Errorf("%s testnet address %s, want prefix %s", addrType, a.Address, prefix)
+			}
+		}
+	}
+
+	legacy, err := GenerateBTCAccountAddresses(masterKey, models.BTCAddressP2PKH, 0, 3, &chaincfg.TestNet3Params, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range legacy {
+		if a.Address[0] != 'm' && a.Address[0] != 'n' {
+			t.Errorf("p2pkh testnet address %s, want m/n prefix", a.Address)
+		}
+	}
+}
+
+func TestUnsupportedBTCAddressType(t *testing.T) {
+	seed, _ := MnemonicToSeed(testMnemonic24)
+	masterKey, _ := DeriveMasterKey(seed, &chaincfg.MainNetParams)
+
+	if _, err := DeriveBTCAccountParentKey(masterKey, "p2wsh", 0, &chaincfg.MainNetParams); !errors.Is(err, ErrUnsupportedAddressType) {
+		t.Errorf("DeriveBTCAccountParentKey(p2wsh) error = %v, want ErrUnsupportedAddressType", err)
+	}
+	if _, err := BTCAddressTypeOf("bc1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3qccfmv3", &chaincfg.MainNetParams); !errors.Is(err, ErrUnsupportedAddressType) {
+		t.Errorf("BTCAddressTypeOf(p2wsh) error = %v, want ErrUnsupportedAddressType", err)
+	}
+}
+
+func TestBTCKeyPath(t *testing.T) {
+	const h = hdkeychain.HardenedKeyStart
+
+	path, err := BTCKeyPath(models.BTCAddressP2TR, 2, 7, &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("BTCKeyPath() error = %v", err)
+	}
+	want := []uint32{h + 86, h + 1, h + 2, 0, 7}
+	if !slices.Equal(path, want) {
+		t.Errorf("BTCKeyPath() = %v, want %v", path, want)
+	}
+
+	if _, err := BTCKeyPath(models.BTCAddressP2WPKH, 0, h, &chaincfg.MainNetParams); err == nil {
+		t.Error("BTCKeyPath() with hardened index should fail")
+	}
+	if _, err := BTCKeyPath("p2wsh", 0, 0, &chaincfg.MainNetParams); !errors.Is(err, ErrUnsupportedAddressType) {
+		t.Errorf("BTCKeyPath(p2wsh) error = %v, want ErrUnsupportedAddressType", err)
+	}
+}
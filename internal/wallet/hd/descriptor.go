@@ -0,0 +1,156 @@
+package hd
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+
+	"github.com/Fantasim/hdpay/internal/shared/models"
+)
+
+// BIP-380 descriptor checksum alphabets and generator.
+const (
+	descriptorInputCharset    = "0123456789()[],'/*abcdefgh@:$%{}IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+	descriptorChecksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+)
+
+var descriptorGenerator = [5]uint64{0xf5dee51989, 0xa9fdca3312, 0x1bab10e32d, 0x3706b1677a, 0x644d626ffd}
+
+// ImportDescriptor is one entry of a Bitcoin Core importdescriptors request.
+type ImportDescriptor struct {
+	Desc      string `json:"desc"`
+	Timestamp int64  `json:"timestamp"`
+	Active    bool   `json:"active"`
+	Internal  bool   `json:"internal"`
+	Range     [2]int `json:"range"`
+}
+
+// MasterFingerprint returns the BIP-32 fingerprint of a master key: the first four
+// bytes of HASH160 of its compressed public key, hex encoded.
+func MasterFingerprint(masterKey *hdkeychain.ExtendedKey) (string, error) {
+	pubKey, err := masterKey.ECPubKey()
+	if err != nil {
+		return "", fmt.Errorf("master public key: %w", err)
+	}
+	return hex.EncodeToString(btcutil.Hash160(pubKey.SerializeCompressed())[:4]), nil
+}
+
+// BTCAccountDescriptor returns the BIP-380 output descriptor, with checksum, for the
+// external chain (/0/*) of a BTC account key. accountKey may be private or public;
+// only the xpub is written. fingerprint is the hex master key fingerprint; when it is
+// empty the key origin is omitted, as for a watch-only wallet initialized from a bare xpub.
+func BTCAccountDescriptor(accountKey *hdkeychain.ExtendedKey, addrType models.BTCAddressType, fingerprint string, account uint32, net *chaincfg.Params) (string, error) {
+	xpub, err := accountKey.Neuter()
+	if err != nil {
+		return "", fmt.Errorf("neuter account key: %w", err)
+	}
+	// Bitcoin Core only understands the standard xpub/tpub version bytes.
+	xpub, err = xpub.CloneWithVersion(net.HDPublicKeyID[:])
+	if err != nil {
+		return "", fmt.Errorf("normalize account xpub: %w", err)
+	}
+
+	key := xpub.String() + "/0/*"
+	if fingerprint != "" {
+		fp, err := hex.DecodeString(fingerprint)
+		if err != nil || len(fp) != 4 {
+			return "", fmt.Errorf("master fingerprint must be 8 hex characters, got %q", fingerprint)
+		}
+		path := strings.TrimPrefix(BTCAccountPath(addrType, account, net), "m")
+		key = "[" + strings.ToLower(fingerprint) + path + "]" + key
+	}
+
+	var desc string
+	switch addrType {
+	case models.BTCAddressP2WPKH:
+		desc = "wpkh(" + key + ")"
+	case models.BTCAddressP2TR:
+		desc = "tr(" + key + ")"
+	case models.BTCAddressP2SHP2WPKH:
+		desc = "sh(wpkh(" + key + "))"
+	case models.BTCAddressP2PKH:
+		desc = "pkh(" + key + ")"
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedAddressType, addrType)
+	}
+
+	return AddDescriptorChecksum(desc)
+}
+
+// NewImportDescriptor wraps a descriptor for Bitcoin Core's importdescriptors,
+// covering indices 0..count-1. Rescanning starts at createdAt (the creation time of
+// the first address); a zero createdAt rescans the whole chain.
+func NewImportDescriptor(desc string, count int, createdAt time.Time) ImportDescriptor {
+	var timestamp int64
+	if !createdAt.IsZero() {
+		timestamp = createdAt.Unix()
+	}
+	return ImportDescriptor{
+		Desc:      desc,
+		Timestamp: timestamp,
+		Active:    true,
+		Internal:  false,
+		Range:     [2]int{0, max(count-1, 0)},
+	}
+}
+
+// AddDescriptorChecksum appends the BIP-380 "#checksum" to a descriptor.
+func AddDescriptorChecksum(desc string) (string, error) {
+	checksum, err := DescriptorChecksum(desc)
+	if err != nil {
+		return "", err
+	}
+	return desc + "#" + checksum, nil
+}
+
+// DescriptorChecksum computes the 8-character BIP-380 checksum of a descriptor
+// (without any existing "#checksum" suffix).
+func DescriptorChecksum(desc string) (string, error) {
+	var symbols []uint64
+	var groups []uint64
+	for i := 0; i < len(desc); i++ {
+		v := strings.IndexByte(descriptorInputCharset, desc[i])
+		if v < 0 {
+			return "", fmt.Errorf("invalid descriptor character %q", desc[i])
+		}
+		symbols = append(symbols, uint64(v&31))
+		groups = append(groups, uint64(v>>5))
+		if len(groups) == 3 {
+			symbols = append(symbols, groups[0]*9+groups[1]*3+groups[2])
+			groups = groups[:0]
+		}
+	}
+	switch len(groups) {
+	case 1:
+		symbols = append(symbols, groups[0])
+	case 2:
+		symbols = append(symbols, groups[0]*3+groups[1])
+	}
+	symbols = append(symbols, 0, 0, 0, 0, 0, 0, 0, 0)
+
+	c := descriptorPolymod(symbols) ^ 1
+	out := make([]byte, 8)
+	for i := range out {
+		out[i] = descriptorChecksumCharset[(c>>(5*(7-i)))&31]
+	}
+	return string(out), nil
+}
+
+func descriptorPolymod(symbols []uint64) uint64 {
+	chk := uint64(1)
+	for _, v := range symbols {
+		top := chk >> 35
+		chk = (chk&0x7ffffffff)<<5 ^ v
+		for i, g := range descriptorGenerator {
+			if (top>>i)&1 == 1 {
+				chk ^= g
+			}
+		}
+	}
+	return chk
+}
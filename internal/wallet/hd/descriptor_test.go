@@ -0,0 +1,131 @@
+package hd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+
+	"github.com/Fantasim/hdpay/internal/shared/models"
+)
+
+// BIP-84 account 0 of the 12-word "abandon ... about" mnemonic, as a standard xpub.
+const testXpub12 = "xpub6CatWdiZiodmUeTDp8LT5or8nmbKNcuyvz7WyksVFkKB4RHwCD3XyuvPEbvqAQY3rAPshWcMLoP2fMFMKHPJ4ZeZXYVUhLv1VMrjPC7PW6V"
+
+func TestDescriptorChecksumVectors(t *testing.T) {
+	tests := []struct {
+		desc string
+		want string
+	}{
+		// BIP-380 / Bitcoin Core reference vectors.
+		{"raw(deadbeef)", "89f8spxm"},
+		{"addr(mkmZxiEcEd8ZqjQWVZuC6so5dFMKEFpN2j)", "02wpgw69"},
+	}
+
+	for _, tt := range tests {
+		got, err := DescriptorChecksum(tt.desc)
+		if err != nil {
+			t.Fatalf("DescriptorChecksum(%q) error = %v", tt.desc, err)
+		}
+		if got != tt.want {
+			t.Errorf("DescriptorChecksum(%q) = %s, want %s", tt.desc, got, tt.want)
+		}
+	}
+
+	if _, err := DescriptorChecksum("raw(dé)"); err == nil {
+		t.Error("DescriptorChecksum() accepted a character outside the input charset")
+	}
+}
+
+func TestBTCAccountDescriptor(t *testing.T) {
+	seed, _ := MnemonicToSeed(testMnemonic12)
+	masterKey, err := DeriveMasterKey(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fingerprint, err := MasterFingerprint(masterKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fingerprint != "73c5da0a" {
+		t.Fatalf("MasterFingerprint() = %s, want 73c5da0a", fingerprint)
+	}
+
+	accountKey, err := DeriveBTCAccountKey(masterKey, models.BTCAddressP2WPKH, 0, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	desc, err := BTCAccountDescriptor(accountKey, models.BTCAddressP2WPKH, fingerprint, 0, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("BTCAccountDescriptor() error = %v", err)
+	}
+	want := "wpkh([73c5da0a/84'/0'/0']" + testXpub12 + "/0/*)#wc3n3van"
+	if desc != want {
+		t.Errorf("descriptor = %s\nwant         %s", desc, want)
+	}
+
+	// The same descriptor from the watch-only zpub, without key origin.
+	public, err := ParseBTCAccountXPub(testZpub12, models.BTCAddressP2WPKH, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bare, err := BTCAccountDescriptor(public, models.BTCAddressP2WPKH, "", 0, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(bare, "wpkh("+testXpub12+"/0/*)#") {
+		t.Errorf("bare descriptor = %s", bare)
+	}
+}
+
+func TestBTCAccountDescriptorTypes(t *testing.T) {
+	seed, _ := MnemonicToSeed(testMnemonic12)
+	masterKey, _ := DeriveMasterKey(seed, &chaincfg.TestNet3Params)
+
+	tests := []struct {
+		addrType models.BTCAddressType
+		prefix   string
+	}{
+		{models.BTCAddressP2WPKH, "wpkh([73c5da0a/84'/1'/3']tpub"},
+		{models.BTCAddressP2TR, "tr([73c5da0a/86'/1'/3']tpub"},
+		{models.BTCAddressP2SHP2WPKH, "sh(wpkh([73c5da0a/49'/1'/3']tpub"},
+		{models.BTCAddressP2PKH, "pkh([73c5da0a/44'/1'/3']tpub"},
+	}
+
+	for _, tt := range tests {
+		accountKey, err := DeriveBTCAccountKey(masterKey, tt.addrType, 3, &chaincfg.TestNet3Params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		desc, err := BTCAccountDescriptor(accountKey, tt.addrType, "73c5da0a", 3, &chaincfg.TestNet3Params)
+		if err != nil {
+			t.Fatalf("%s: BTCAccountDescriptor() error = %v", tt.addrType, err)
+		}
+		if !strings.HasPrefix(desc, tt.prefix) {
+			t.Errorf("%s: descriptor = %s, want prefix %s", tt.addrType, desc, tt.prefix)
+		}
+		if strings.Contains(desc, "tprv") {
+			t.Errorf("%s: descriptor leaks the private key", tt.addrType)
+		}
+	}
+
+	accountKey, _ := DeriveBTCAccountKey(masterKey, models.BTCAddressP2WPKH, 0, &chaincfg.TestNet3Params)
+	if _, err := BTCAccountDescriptor(accountKey, models.BTCAddressP2WPKH, "xyz", 0, &chaincfg.TestNet3Params); err == nil {
+		t.Error("BTCAccountDescriptor() accepted a malformed fingerprint")
+	}
+}
+
+func TestNewImportDescriptor(t *testing.T) {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	got := NewImportDescriptor("wpkh(x)#y", 500, created)
+	if got.Range != [2]int{0, 499} || got.Timestamp != created.Unix() || !got.Active || got.Internal {
+		t.Errorf("NewImportDescriptor() = %+v", got)
+	}
+
+	if got := NewImportDescriptor("wpkh(x)#y", 0, time.Time{}); got.Timestamp != 0 || got.Range != [2]int{0, 0} {
+		t.Errorf("NewImportDescriptor(empty) = %+v, want full rescan", got)
+	}
+}
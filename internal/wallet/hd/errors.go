@@ -5,4 +5,18 @@ import "errors"
 var (
 	ErrInvalidMnemonic = errors.New("invalid mnemonic")
 	ErrDerivation      = errors.New("key derivation failed")
+	ErrInvalidXPub     = errors.New("invalid extended public key")
+	ErrNoTerminal      = errors.New("stdin is not a terminal")
+	ErrInvalidAccount  = errors.New("invalid BIP-44 account")
+
+	ErrUnsupportedAddressType = errors.New("unsupported BTC address type")
+
+	ErrInvalidKeystore  = errors.New("invalid keystore file")
+	ErrKeystorePassword = errors.New("wrong keystore password or corrupted keystore")
+	ErrWeakPassword     = errors.New("keystore password too short")
+
+	ErrInvalidShare       = errors.New("invalid SLIP-39 share")
+	ErrInsufficientShares = errors.New("insufficient SLIP-39 shares")
+
+	ErrUnsupportedExportFormat = errors.New("unsupported export format")
 )
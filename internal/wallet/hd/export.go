@@ -1,12 +1,16 @@
 package hd
 
 import (
+	"bufio"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Fantasim/hdpay/internal/shared/models"
@@ -21,15 +25,20 @@ type AddressStreamer interface {
 	CountAddresses(chain models.Chain) (int, error)
 }
 
-// derivationPathTemplate returns the derivation path template string for a chain.
-func derivationPathTemplate(chain models.Chain) string {
+// derivationPathTemplate returns the derivation path template string for a chain,
+// BTC address type and BIP-44 account.
+func derivationPathTemplate(chain models.Chain, btcType models.BTCAddressType, account uint32) string {
 	switch chain {
 	case models.ChainBTC:
-		return "m/84'/0'/0'/0/{index}"
+		purpose, err := btcPurpose(btcType)
+		if err != nil {
+			return ""
+		}
+		return fmt.Sprintf("m/%d'/0'/%d'/0/{index}", purpose, account)
 	case models.ChainBSC:
-		return "m/44'/60'/0'/0/{index}"
+		return fmt.Sprintf("m/44'/60'/%d'/0/{index}", account)
 	case models.ChainSOL:
-		return "m/44'/501'/{index}'/0'"
+		return fmt.Sprintf("m/44'/501'/{index}'/%d'", account)
 	default:
 		return ""
 	}
@@ -38,93 +47,387 @@ func derivationPathTemplate(chain models.Chain) string {
 // ExportAddresses exports all addresses for a chain to a JSON file using streaming.
 // The file is written incrementally to avoid loading all 500K addresses into memory.
 func ExportAddresses(db AddressStreamer, chain models.Chain, network string, outputDir string) error {
+	return ExportAccountAddresses(db, chain, network, models.BTCAddressP2WPKH, 0, outputDir)
+}
+
+// ExportAccountAddresses exports the addresses of one BIP-44 account. db must already
+// be scoped to that account. Account 0 keeps the historical <CHAIN>_addresses.json
+// file name; other accounts are written to <CHAIN>_account<N>_addresses.json.
+// btcType is recorded in the header of BTC exports and ignored for other chains.
+func ExportAccountAddresses(db AddressStreamer, chain models.Chain, network string, btcType models.BTCAddressType, account uint32, outputDir string) error {
+	_, err := ExportAddressFile(db, chain, ExportOptions{
+		Network: network,
+		BTCType: btcType,
+		Account: account,
+		Format:  models.ExportFormatJSON,
+	}, outputDir)
+	return err
+}
+
+// BalanceStreamer streams addresses joined with their stored balances.
+type BalanceStreamer interface {
+	StreamAddressesWithBalances(chain models.Chain, fn func(addr models.AddressWithBalance) error) error
+}
+
+// LabelSource provides the label, tags and external ID of the labelled addresses of
+// a chain, keyed by address index. Exports include them when the streamer implements it.
+type LabelSource interface {
+	GetAddressLabels(chain models.Chain) (map[int]models.AddressMetadata, error)
+}
+
+// TokenSource provides the token registry of a chain. Balance exports have one
+// column per registry token when the streamer implements it, and the built-in
+// USDC and USDT columns otherwise.
+type TokenSource interface {
+	ListTokens(chain models.Chain) ([]models.TokenInfo, error)
+}
+
+// ExportOptions describes the format and content of an address export.
+type ExportOptions struct {
+	Network  string
+	BTCType  models.BTCAddressType // Recorded in the header of BTC JSON exports
+	Account  uint32
+	Format   models.ExportFormat // json, csv or ndjson; empty means json
+	Balances bool                // Add balances; the streamer must implement BalanceStreamer
+}
+
+// ExportFileName returns the export file name for a chain and account, e.g.
+// BTC_addresses.csv or BTC_account2_descriptor.json.
+func ExportFileName(chain models.Chain, account uint32, kind, ext string) string {
+	if account != 0 {
+		return fmt.Sprintf("%s_account%d_%s.%s", chain, account, kind, ext)
+	}
+	return fmt.Sprintf("%s_%s.%s", chain, kind, ext)
+}
+
+// ExportAddressFile writes the addresses of chain to <outputDir>/<CHAIN>_addresses.<format>
+// and returns the file path.
+func ExportAddressFile(db AddressStreamer, chain models.Chain, opts ExportOptions, outputDir string) (string, error) {
+	if opts.Format == "" {
+		opts.Format = models.ExportFormatJSON
+	}
 	if outputDir == "" {
 		outputDir = ExportDir
 	}
 
 	if err := os.MkdirAll(outputDir, 0o755); err != nil {
-		return fmt.Errorf("create export directory %q: %w", outputDir, err)
+		return "", fmt.Errorf("create export directory %q: %w", outputDir, err)
 	}
 
 	count, err := db.CountAddresses(chain)
 	if err != nil {
-		return fmt.Errorf("count addresses for export: %w", err)
+		return "", fmt.Errorf("count addresses for export: %w", err)
 	}
 
 	if count == 0 {
-		return fmt.Errorf("no addresses found for chain %s", chain)
+		return "", fmt.Errorf("no addresses found for chain %s", chain)
 	}
 
-	filename := filepath.Join(outputDir, fmt.Sprintf("%s_addresses.json", chain))
+	filename := filepath.Join(outputDir, ExportFileName(chain, opts.Account, "addresses", string(opts.Format)))
 	slog.Info("exporting addresses",
 		"chain", chain,
+		"account", opts.Account,
+		"format", opts.Format,
+		"balances", opts.Balances,
 		"count", count,
 		"file", filename,
 	)
 
 	f, err := os.Create(filename)
 	if err != nil {
-		return fmt.Errorf("create export file %q: %w", filename, err)
+		return "", fmt.Errorf("create export file %q: %w", filename, err)
 	}
 	defer f.Close()
 
+	bw := bufio.NewWriter(f)
+	exported, err := WriteAddressExport(bw, db, chain, opts)
+	if err != nil {
+		return "", err
+	}
+	if err := bw.Flush(); err != nil {
+		return "", fmt.Errorf("flush export file: %w", err)
+	}
+
+	slog.Info("export complete",
+		"chain", chain,
+		"exported", exported,
+		"file", filename,
+	)
+	return filename, nil
+}
+
+// WriteAddressExport streams the addresses of chain to w in opts.Format and returns
+// the number of addresses written. The descriptor format carries no per-address
+// data and is written with WriteDescriptorExport instead.
+func WriteAddressExport(w io.Writer, db AddressStreamer, chain models.Chain, opts ExportOptions) (int, error) {
+	switch opts.Format {
+	case "", models.ExportFormatJSON:
+		return writeJSONExport(w, db, chain, opts)
+	case models.ExportFormatCSV:
+		return writeCSVExport(w, db, chain, opts)
+	case models.ExportFormatNDJSON:
+		return writeNDJSONExport(w, db, chain, opts)
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrUnsupportedExportFormat, opts.Format)
+	}
+}
+
+// writeJSONExport writes one models.AddressExport document.
+func writeJSONExport(w io.Writer, db AddressStreamer, chain models.Chain, opts ExportOptions) (int, error) {
+	count, err := db.CountAddresses(chain)
+	if err != nil {
+		return 0, fmt.Errorf("count addresses for export: %w", err)
+	}
+
 	// Write header
+	addressType := ""
+	if chain == models.ChainBTC {
+		addressType = fmt.Sprintf(`"address_type":"%s",`, opts.BTCType)
+	}
 	header := fmt.Sprintf(
-		`{"chain":"%s","network":"%s","derivation_path_template":"%s","generated_at":"%s","count":%d,"addresses":[`,
-		chain, network, derivationPathTemplate(chain),
+		`{"chain":"%s","network":"%s","account":%d,%s"derivation_path_template":"%s","generated_at":"%s","count":%d,"addresses":[`,
+		chain, opts.Network, opts.Account, addressType, derivationPathTemplate(chain, opts.BTCType, opts.Account),
 		time.Now().UTC().Format(time.RFC3339), count,
 	)
-	if _, err := io.WriteString(f, header); err != nil {
-		return fmt.Errorf("write export header: %w", err)
+	if _, err := io.WriteString(w, header); err != nil {
+		return 0, fmt.Errorf("write export header: %w", err)
 	}
 
 	// Stream addresses
 	first := true
 	exported := 0
-	err = db.StreamAddresses(chain, func(addr models.Address) error {
+	err = StreamExportItems(db, chain, opts.Balances, func(item models.AddressExportItem) error {
 		if !first {
-			if _, err := io.WriteString(f, ","); err != nil {
+			if _, err := io.WriteString(w, ","); err != nil {
 				return err
 			}
 		}
 		first = false
 
-		entry, err := json.Marshal(models.AddressExportItem{
-			Index:   addr.AddressIndex,
-			Address: addr.Address,
-		})
+		entry, err := json.Marshal(item)
 		if err != nil {
 			return fmt.Errorf("marshal address entry: %w", err)
 		}
 
-		if _, err := f.Write(entry); err != nil {
+		if _, err := w.Write(entry); err != nil {
 			return err
 		}
 
 		exported++
-		if exported%100_000 == 0 {
-			slog.Info("export progress",
-				"chain", chain,
-				"exported", exported,
-				"total", count,
-			)
+		logExportProgress(chain, exported, count)
+		return nil
+	})
+	if err != nil {
+		return exported, fmt.Errorf("stream addresses for export: %w", err)
+	}
+
+	// Write footer
+	if _, err := io.WriteString(w, "]}"); err != nil {
+		return exported, fmt.Errorf("write export footer: %w", err)
+	}
+	return exported, nil
+}
+
+// writeNDJSONExport writes one models.AddressExportItem per line.
+func writeNDJSONExport(w io.Writer, db AddressStreamer, chain models.Chain, opts ExportOptions) (int, error) {
+	enc := json.NewEncoder(w)
+	exported := 0
+	err := StreamExportItems(db, chain, opts.Balances, func(item models.AddressExportItem) error {
+		if err := enc.Encode(item); err != nil {
+			return err
+		}
+		exported++
+		logExportProgress(chain, exported, 0)
+		return nil
+	})
+	if err != nil {
+		return exported, fmt.Errorf("stream addresses for export: %w", err)
+	}
+	return exported, nil
+}
+
+// writeCSVExport writes a header row and one row per address. Balance columns are
+// the chain's tokens (lower-cased, raw smallest-unit amounts) plus last_scanned.
+// The label, tags (separated by ";") and external_id columns come last.
+func writeCSVExport(w io.Writer, db AddressStreamer, chain models.Chain, opts ExportOptions) (int, error) {
+	cw := csv.NewWriter(w)
+	tokens, err := exportTokens(db, chain)
+	if err != nil {
+		return 0, err
+	}
+
+	header := []string{"index", "address"}
+	if opts.Balances {
+		for _, token := range tokens {
+			header = append(header, strings.ToLower(string(token)))
 		}
+		header = append(header, "last_scanned")
+	}
+	header = append(header, "label", "tags", "external_id")
+	if err := cw.Write(header); err != nil {
+		return 0, fmt.Errorf("write export header: %w", err)
+	}
 
+	exported := 0
+	err = StreamExportItems(db, chain, opts.Balances, func(item models.AddressExportItem) error {
+		row := []string{strconv.Itoa(item.Index), item.Address}
+		if opts.Balances {
+			for _, token := range tokens {
+				row = append(row, item.Balances[token])
+			}
+			lastScanned := ""
+			if item.LastScanned != nil {
+				lastScanned = *item.LastScanned
+			}
+			row = append(row, lastScanned)
+		}
+		row = append(row, item.Label, strings.Join(item.Tags, ";"), item.ExternalID)
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+		exported++
+		logExportProgress(chain, exported, 0)
 		return nil
 	})
 	if err != nil {
-		return fmt.Errorf("stream addresses for export: %w", err)
+		return exported, fmt.Errorf("stream addresses for export: %w", err)
 	}
 
-	// Write footer
-	if _, err := io.WriteString(f, "]}"); err != nil {
-		return fmt.Errorf("write export footer: %w", err)
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return exported, fmt.Errorf("write export rows: %w", err)
 	}
+	return exported, nil
+}
 
-	slog.Info("export complete",
+// StreamExportItems calls fn with every stored address of chain in index order.
+// With balances, each item carries the chain's token balances ("0" when never
+// scanned) and its last scan time; db must then implement BalanceStreamer. When db
+// implements LabelSource, items also carry the address label, tags and external ID.
+func StreamExportItems(db AddressStreamer, chain models.Chain, balances bool, fn func(item models.AddressExportItem) error) error {
+	var labels map[int]models.AddressMetadata
+	if ls, ok := db.(LabelSource); ok {
+		var err error
+		if labels, err = ls.GetAddressLabels(chain); err != nil {
+			return fmt.Errorf("load address labels: %w", err)
+		}
+	}
+	emit := func(item models.AddressExportItem) error {
+		if m, ok := labels[item.Index]; ok {
+			item.Label = m.Label
+			item.Tags = m.Tags
+			item.ExternalID = m.ExternalID
+		}
+		return fn(item)
+	}
+
+	if !balances {
+		return db.StreamAddresses(chain, func(addr models.Address) error {
+			return emit(models.AddressExportItem{
+				Index:   addr.AddressIndex,
+				Address: addr.Address,
+			})
+		})
+	}
+
+	bs, ok := db.(BalanceStreamer)
+	if !ok {
+		return fmt.Errorf("%w: balances are not available from this address source", ErrUnsupportedExportFormat)
+	}
+
+	tokens, err := exportTokens(db, chain)
+	if err != nil {
+		return err
+	}
+	return bs.StreamAddressesWithBalances(chain, func(addr models.AddressWithBalance) error {
+		item := models.AddressExportItem{
+			Index:       addr.AddressIndex,
+			Address:     addr.Address,
+			Balances:    make(map[models.Token]string, len(tokens)),
+			LastScanned: addr.LastScanned,
+		}
+		for _, token := range tokens {
+			item.Balances[token] = "0"
+		}
+		if addr.NativeBalance != "" {
+			item.Balances[models.TokenNative] = addr.NativeBalance
+		}
+		for _, tb := range addr.TokenBalances {
+			item.Balances[tb.Symbol] = tb.Balance
+		}
+		return emit(item)
+	})
+}
+
+// exportTokens returns the balance columns of a chain: native first, then its tokens.
+func exportTokens(db AddressStreamer, chain models.Chain) ([]models.Token, error) {
+	if chain == models.ChainBTC {
+		return []models.Token{models.TokenNative}, nil
+	}
+	ts, ok := db.(TokenSource)
+	if !ok {
+		return []models.Token{models.TokenNative, models.TokenUSDC, models.TokenUSDT}, nil
+	}
+
+	registry, err := ts.ListTokens(chain)
+	if err != nil {
+		return nil, fmt.Errorf("load token registry: %w", err)
+	}
+	tokens := []models.Token{models.TokenNative}
+	for _, t := range registry {
+		tokens = append(tokens, t.Symbol)
+	}
+	return tokens, nil
+}
+
+// logExportProgress logs every 100K exported addresses. total is 0 when unknown.
+func logExportProgress(chain models.Chain, exported, total int) {
+	if exported%100_000 != 0 {
+		return
+	}
+	slog.Info("export progress",
 		"chain", chain,
 		"exported", exported,
-		"file", filename,
+		"total", total,
 	)
+}
+
+// WriteDescriptorExport writes descriptors as a JSON array that can be passed as-is
+// to Bitcoin Core's importdescriptors.
+func WriteDescriptorExport(w io.Writer, descriptors ...ImportDescriptor) error {
+	data, err := json.MarshalIndent(descriptors, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal descriptors: %w", err)
+	}
+	data = append(data, '\n')
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("write descriptors: %w", err)
+	}
 	return nil
 }
+
+// ExportDescriptorFile writes the BTC descriptor of an account to
+// <outputDir>/BTC_descriptor.json (BTC_account<N>_descriptor.json for N > 0) and
+// returns the file path.
+func ExportDescriptorFile(desc ImportDescriptor, account uint32, outputDir string) (string, error) {
+	if outputDir == "" {
+		outputDir = ExportDir
+	}
+	if err := os.MkdirAll(outputDir, 0o755); err != nil {
+		return "", fmt.Errorf("create export directory %q: %w", outputDir, err)
+	}
+
+	filename := filepath.Join(outputDir, ExportFileName(models.ChainBTC, account, "descriptor", "json"))
+	f, err := os.Create(filename)
+	if err != nil {
+		return "", fmt.Errorf("create export file %q: %w", filename, err)
+	}
+	defer f.Close()
+
+	if err := WriteDescriptorExport(f, desc); err != nil {
+		return "", err
+	}
+
+	slog.Info("descriptor export complete", "account", account, "range", desc.Range, "file", filename)
+	return filename, nil
+}
@@ -1,9 +1,14 @@
 package hd
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/Fantasim/hdpay/internal/shared/models"
@@ -27,6 +32,36 @@ func (m *mockStreamer) StreamAddresses(chain models.Chain, fn func(addr models.A
 	return nil
 }
 
+// mockBalanceStreamer adds stored balances to mockStreamer.
+type mockBalanceStreamer struct {
+	mockStreamer
+	balances map[int]models.AddressWithBalance
+}
+
+func (m *mockBalanceStreamer) StreamAddressesWithBalances(chain models.Chain, fn func(addr models.AddressWithBalance) error) error {
+	for _, addr := range m.addresses[chain] {
+		row, ok := m.balances[addr.AddressIndex]
+		if !ok {
+			row = models.AddressWithBalance{NativeBalance: "0"}
+		}
+		row.Chain, row.AddressIndex, row.Address = chain, addr.AddressIndex, addr.Address
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mockLabelStreamer adds address metadata to mockStreamer.
+type mockLabelStreamer struct {
+	mockStreamer
+	labels map[int]models.AddressMetadata
+}
+
+func (m *mockLabelStreamer) GetAddressLabels(_ models.Chain) (map[int]models.AddressMetadata, error) {
+	return m.labels, nil
+}
+
 func TestExportAddresses(t *testing.T) {
 	mock := &mockStreamer{
 		addresses: map[models.Chain][]models.Address{
@@ -89,17 +124,280 @@ func TestExportAddressesEmptyChain(t *testing.T) {
 
 func TestDerivationPathTemplate(t *testing.T) {
 	tests := []struct {
-		chain models.Chain
-		want  string
+		chain   models.Chain
+		btcType models.BTCAddressType
+		account uint32
+		want    string
 	}{
-		{models.ChainBTC, "m/84'/0'/0'/0/{index}"},
-		{models.ChainBSC, "m/44'/60'/0'/0/{index}"},
-		{models.ChainSOL, "m/44'/501'/{index}'/0'"},
+		{models.ChainBTC, models.BTCAddressP2WPKH, 0, "m/84'/0'/0'/0/{index}"},
+		{models.ChainBSC, models.BTCAddressP2WPKH, 0, "m/44'/60'/0'/0/{index}"},
+		{models.ChainSOL, models.BTCAddressP2WPKH, 0, "m/44'/501'/{index}'/0'"},
+		{models.ChainBTC, models.BTCAddressP2WPKH, 4, "m/84'/0'/4'/0/{index}"},
+		{models.ChainBSC, models.BTCAddressP2WPKH, 4, "m/44'/60'/4'/0/{index}"},
+		{models.ChainSOL, models.BTCAddressP2WPKH, 4, "m/44'/501'/{index}'/4'"},
+		{models.ChainBTC, models.BTCAddressP2TR, 0, "m/86'/0'/0'/0/{index}"},
+		{models.ChainBTC, models.BTCAddressP2SHP2WPKH, 0, "m/49'/0'/0'/0/{index}"},
+		{models.ChainBTC, models.BTCAddressP2PKH, 1, "m/44'/0'/1'/0/{index}"},
+		{models.ChainBSC, models.BTCAddressP2TR, 0, "m/44'/60'/0'/0/{index}"},
 	}
 
 	for _, tt := range tests {
-		if got := derivationPathTemplate(tt.chain); got != tt.want {
-			t.Errorf("derivationPathTemplate(%s) = %v, want %v", tt.chain, got, tt.want)
+		if got := derivationPathTemplate(tt.chain, tt.btcType, tt.account); got != tt.want {
+			t.Errorf("derivationPathTemplate(%s, %s, %d) = %v, want %v", tt.chain, tt.btcType, tt.account, got, tt.want)
 		}
 	}
 }
+
+func TestExportAccountAddresses(t *testing.T) {
+	mock := &mockStreamer{
+		addresses: map[models.Chain][]models.Address{
+			models.ChainSOL: {
+				{Chain: models.ChainSOL, AddressIndex: 0, Address: "SolAcct2_0"},
+			},
+		},
+	}
+
+	outputDir := t.TempDir()
+	if err := ExportAccountAddresses(mock, models.ChainSOL, "mainnet", models.BTCAddressP2WPKH, 2, outputDir); err != nil {
+		t.Fatalf("ExportAccountAddresses() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputDir, "SOL_account2_addresses.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var export models.AddressExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		t.Fatalf("unmarshal export: %v", err)
+	}
+	if export.Account != 2 {
+		t.Errorf("export.Account = %d, want 2", export.Account)
+	}
+	if export.DerivationPathTemplate != "m/44'/501'/{index}'/2'" {
+		t.Errorf("export.DerivationPathTemplate = %v", export.DerivationPathTemplate)
+	}
+}
+
+func TestExportAccountAddressesBTCType(t *testing.T) {
+	mock := &mockStreamer{
+		addresses: map[models.Chain][]models.Address{
+			models.ChainBTC: {
+				{Chain: models.ChainBTC, AddressIndex: 0, Address: "bc1ptest0"},
+			},
+		},
+	}
+
+	outputDir := t.TempDir()
+	if err := ExportAccountAddresses(mock, models.ChainBTC, "mainnet", models.BTCAddressP2TR, 0, outputDir); err != nil {
+		t.Fatalf("ExportAccountAddresses() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputDir, "BTC_addresses.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var export models.AddressExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		t.Fatalf("unmarshal export: %v", err)
+	}
+	if export.AddressType != models.BTCAddressP2TR {
+		t.Errorf("export.AddressType = %q, want p2tr", export.AddressType)
+	}
+	if export.DerivationPathTemplate != "m/86'/0'/0'/0/{index}" {
+		t.Errorf("export.DerivationPathTemplate = %v", export.DerivationPathTemplate)
+	}
+}
+
+func newBalanceMock() *mockBalanceStreamer {
+	scanned := "2026-02-18T10:00:00Z"
+	return &mockBalanceStreamer{
+		mockStreamer: mockStreamer{
+			addresses: map[models.Chain][]models.Address{
+				models.ChainSOL: {
+					{Chain: models.ChainSOL, AddressIndex: 0, Address: "Sol0"},
+					{Chain: models.ChainSOL, AddressIndex: 1, Address: "Sol1"},
+				},
+			},
+		},
+		balances: map[int]models.AddressWithBalance{
+			1: {
+				NativeBalance: "1500000000",
+				TokenBalances: []models.TokenBalanceItem{{Symbol: models.TokenUSDC, Balance: "2500000"}},
+				LastScanned:   &scanned,
+			},
+		},
+	}
+}
+
+func TestExportAddressFileCSV(t *testing.T) {
+	mock := newBalanceMock()
+	path, err := ExportAddressFile(mock, models.ChainSOL, ExportOptions{Format: models.ExportFormatCSV, Balances: true, Account: 1}, t.TempDir())
+	if err != nil {
+		t.Fatalf("ExportAddressFile() error = %v", err)
+	}
+	if filepath.Base(path) != "SOL_account1_addresses.csv" {
+		t.Errorf("file name = %s", filepath.Base(path))
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{
+		{"index", "address", "native", "usdc", "usdt", "last_scanned", "label", "tags", "external_id"},
+		{"0", "Sol0", "0", "0", "0", "", "", "", ""},
+		{"1", "Sol1", "1500000000", "2500000", "0", "2026-02-18T10:00:00Z", "", "", ""},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(records), len(want))
+	}
+	for i := range want {
+		for j := range want[i] {
+			if records[i][j] != want[i][j] {
+				t.Errorf("row %d col %d = %q, want %q", i, j, records[i][j], want[i][j])
+			}
+		}
+	}
+}
+
+// mockTokenStreamer adds a token registry to mockBalanceStreamer.
+type mockTokenStreamer struct {
+	*mockBalanceStreamer
+	tokens []models.TokenInfo
+}
+
+func (m *mockTokenStreamer) ListTokens(_ models.Chain) ([]models.TokenInfo, error) {
+	return m.tokens, nil
+}
+
+func TestWriteAddressExportCSV_TokenRegistry(t *testing.T) {
+	mock := &mockTokenStreamer{
+		mockBalanceStreamer: newBalanceMock(),
+		tokens: []models.TokenInfo{
+			{Chain: models.ChainSOL, Symbol: models.TokenUSDC, Builtin: true},
+			{Chain: models.ChainSOL, Symbol: "JUP"},
+		},
+	}
+	mock.balances[1] = models.AddressWithBalance{
+		NativeBalance: "1500000000",
+		TokenBalances: []models.TokenBalanceItem{{Symbol: "JUP", Balance: "42"}},
+	}
+
+	var buf bytes.Buffer
+	if _, err := WriteAddressExport(&buf, mock, models.ChainSOL, ExportOptions{Format: models.ExportFormatCSV, Balances: true}); err != nil {
+		t.Fatalf("WriteAddressExport() error = %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// One column per registry token, in registry order.
+	wantHeader := []string{"index", "address", "native", "usdc", "jup", "last_scanned", "label", "tags", "external_id"}
+	if strings.Join(records[0], ",") != strings.Join(wantHeader, ",") {
+		t.Errorf("header = %v, want %v", records[0], wantHeader)
+	}
+	if len(records) != 3 || records[2][3] != "0" || records[2][4] != "42" {
+		t.Errorf("rows = %v, want index 1 with 0 USDC and 42 JUP", records[1:])
+	}
+}
+
+func TestWriteAddressExportNDJSON(t *testing.T) {
+	mock := newBalanceMock()
+
+	var buf bytes.Buffer
+	n, err := WriteAddressExport(&buf, mock, models.ChainSOL, ExportOptions{Format: models.ExportFormatNDJSON})
+	if err != nil {
+		t.Fatalf("WriteAddressExport() error = %v", err)
+	}
+	if n != 2 {
+		t.Errorf("exported %d, want 2", n)
+	}
+
+	var items []models.AddressExportItem
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var item models.AddressExportItem
+		if err := json.Unmarshal(scanner.Bytes(), &item); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		items = append(items, item)
+	}
+	if len(items) != 2 || items[1].Address != "Sol1" || items[1].Balances != nil {
+		t.Errorf("items = %+v, want two items without balances", items)
+	}
+
+	buf.Reset()
+	if _, err := WriteAddressExport(&buf, mock, models.ChainSOL, ExportOptions{Format: models.ExportFormatNDJSON, Balances: true}); err != nil {
+		t.Fatal(err)
+	}
+	first, _, _ := bytes.Cut(buf.Bytes(), []byte("\n"))
+	var item models.AddressExportItem
+	if err := json.Unmarshal(first, &item); err != nil {
+		t.Fatal(err)
+	}
+	if item.Balances[models.TokenUSDT] != "0" || item.LastScanned != nil {
+		t.Errorf("unscanned item = %+v, want zero balances and no scan time", item)
+	}
+}
+
+func TestWriteAddressExportCSVLabels(t *testing.T) {
+	mock := &mockLabelStreamer{
+		mockStreamer: mockStreamer{addresses: map[models.Chain][]models.Address{
+			models.ChainBSC: {
+				{Chain: models.ChainBSC, AddressIndex: 0, Address: "0xaaa"},
+				{Chain: models.ChainBSC, AddressIndex: 1, Address: "0xbbb"},
+			},
+		}},
+		labels: map[int]models.AddressMetadata{
+			1: {AddressIndex: 1, Label: "Acme, Inc.", Tags: []string{"customer", "vip"}, ExternalID: "INV-1042"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if _, err := WriteAddressExport(&buf, mock, models.ChainBSC, ExportOptions{Format: models.ExportFormatCSV}); err != nil {
+		t.Fatalf("WriteAddressExport() error = %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{
+		{"index", "address", "label", "tags", "external_id"},
+		{"0", "0xaaa", "", "", ""},
+		{"1", "0xbbb", "Acme, Inc.", "customer;vip", "INV-1042"},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(records), len(want))
+	}
+	for i := range want {
+		for j := range want[i] {
+			if records[i][j] != want[i][j] {
+				t.Errorf("row %d col %d = %q, want %q", i, j, records[i][j], want[i][j])
+			}
+		}
+	}
+}
+
+func TestWriteAddressExportRejects(t *testing.T) {
+	mock := &mockStreamer{addresses: map[models.Chain][]models.Address{
+		models.ChainBTC: {{Chain: models.ChainBTC, AddressIndex: 0, Address: "bc1qtest0"}},
+	}}
+
+	var buf bytes.Buffer
+	if _, err := WriteAddressExport(&buf, mock, models.ChainBTC, ExportOptions{Format: models.ExportFormatDescriptor}); !errors.Is(err, ErrUnsupportedExportFormat) {
+		t.Errorf("descriptor format error = %v, want ErrUnsupportedExportFormat", err)
+	}
+	if _, err := WriteAddressExport(&buf, mock, models.ChainBTC, ExportOptions{Format: models.ExportFormatCSV, Balances: true}); !errors.Is(err, ErrUnsupportedExportFormat) {
+		t.Errorf("balances without BalanceStreamer error = %v, want ErrUnsupportedExportFormat", err)
+	}
+}
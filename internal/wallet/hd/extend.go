@@ -0,0 +1,96 @@
+package hd
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+)
+
+// AddressExtender is the storage needed to append addresses to an existing set.
+type AddressExtender interface {
+	MaxAddressIndex(chain models.Chain) (int, error)
+	GetAddressByIndex(chain models.Chain, index int) (*models.Address, error)
+	InsertAddressBatch(chain models.Chain, addresses []models.Address) error
+	ExtendScanMaxID(chain models.Chain, maxID int) error
+}
+
+// ExtendResult describes the indices appended by ExtendAddresses.
+type ExtendResult struct {
+	Chain models.Chain `json:"chain"`
+	From  int          `json:"from"`
+	To    int          `json:"to"`
+	Added int          `json:"added"`
+}
+
+// ExtendAddresses appends the addresses of chain from max(address_index)+1 up to
+// target-1, so that the set covers indices 0..target-1, and raises the max scan ID
+// of an existing scan state to target. Balances and transaction history are not
+// touched. A set already reaching target is left as is (Added is 0).
+//
+// db must be scoped to the account that derive covers. The highest stored address
+// is re-derived first: a set stored from other key material (another passphrase,
+// account or address type) is refused with config.ErrKeyMismatch instead of being
+// extended with addresses that do not belong to it.
+func ExtendAddresses(db AddressExtender, chain models.Chain, derive IndexDeriver, target int, progress ProgressCallback) (*ExtendResult, error) {
+	if target <= 0 || target > config.MaxAddressesPerChain {
+		return nil, fmt.Errorf("extend target must be 1 to %d, got %d", config.MaxAddressesPerChain, target)
+	}
+
+	maxIndex, err := db.MaxAddressIndex(chain)
+	if err != nil {
+		return nil, fmt.Errorf("max %s address index: %w", chain, err)
+	}
+
+	result := &ExtendResult{Chain: chain, From: maxIndex + 1, To: target}
+	if result.From >= target {
+		slog.Info("address set already covers extend target",
+			"chain", chain,
+			"maxIndex", maxIndex,
+			"target", target,
+		)
+		result.To = result.From
+		return result, nil
+	}
+
+	if maxIndex >= 0 {
+		last, err := db.GetAddressByIndex(chain, maxIndex)
+		if err != nil {
+			return nil, fmt.Errorf("read last %s address: %w", chain, err)
+		}
+		derived, err := derive(uint32(maxIndex))
+		if err != nil {
+			return nil, fmt.Errorf("derive %s address at index %d: %w", chain, maxIndex, err)
+		}
+		if derived != last.Address {
+			return nil, fmt.Errorf("%w: %s index %d is stored as %s but derives %s",
+				config.ErrKeyMismatch, chain, maxIndex, last.Address, derived)
+		}
+	}
+
+	// Batches are inserted in index order, so a failed run leaves a contiguous set
+	// that the next run extends from.
+	err = GenerateBatches(chain, derive, result.From, target, progress, func(batch []models.Address) error {
+		if err := db.InsertAddressBatch(chain, batch); err != nil {
+			return err
+		}
+		result.Added += len(batch)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.ExtendScanMaxID(chain, target); err != nil {
+		return nil, fmt.Errorf("extend %s scan range: %w", chain, err)
+	}
+
+	slog.Info("address set extended",
+		"chain", chain,
+		"from", result.From,
+		"to", result.To,
+		"added", result.Added,
+	)
+	return result, nil
+}
@@ -0,0 +1,100 @@
+package hd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+)
+
+// mockExtender is an in-memory AddressExtender.
+type mockExtender struct {
+	addresses map[int]models.Address
+	maxScanID int
+}
+
+func (m *mockExtender) MaxAddressIndex(chain models.Chain) (int, error) {
+	maxIndex := -1
+	for index := range m.addresses {
+		maxIndex = max(maxIndex, index)
+	}
+	return maxIndex, nil
+}
+
+func (m *mockExtender) GetAddressByIndex(chain models.Chain, index int) (*models.Address, error) {
+	addr, ok := m.addresses[index]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &addr, nil
+}
+
+func (m *mockExtender) InsertAddressBatch(chain models.Chain, addresses []models.Address) error {
+	for _, addr := range addresses {
+		if _, ok := m.addresses[addr.AddressIndex]; ok {
+			return errors.New("duplicate index")
+		}
+		m.addresses[addr.AddressIndex] = addr
+	}
+	return nil
+}
+
+func (m *mockExtender) ExtendScanMaxID(chain models.Chain, maxID int) error {
+	m.maxScanID = max(m.maxScanID, maxID)
+	return nil
+}
+
+func TestExtendAddresses(t *testing.T) {
+	addrs, derive := auditFixture(t, models.ChainBSC, 10)
+	mock := &mockExtender{addresses: map[int]models.Address{}}
+	for _, addr := range addrs[:4] {
+		mock.addresses[addr.AddressIndex] = addr
+	}
+
+	result, err := ExtendAddresses(mock, models.ChainBSC, derive, 10, nil)
+	if err != nil {
+		t.Fatalf("ExtendAddresses() error = %v", err)
+	}
+	if result.From != 4 || result.To != 10 || result.Added != 6 {
+		t.Errorf("result = %+v, want 4..10 with 6 added", result)
+	}
+	for i, want := range addrs {
+		if got := mock.addresses[i].Address; got != want.Address {
+			t.Errorf("index %d = %q, want %q", i, got, want.Address)
+		}
+	}
+	if mock.maxScanID != 10 {
+		t.Errorf("maxScanID = %d, want 10", mock.maxScanID)
+	}
+
+	// Already covered: no-op.
+	result, err = ExtendAddresses(mock, models.ChainBSC, derive, 8, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Added != 0 {
+		t.Errorf("Added = %d, want 0", result.Added)
+	}
+}
+
+func TestExtendAddressesKeyMismatch(t *testing.T) {
+	addrs, derive := auditFixture(t, models.ChainBTC, 3)
+	addrs[2].Address = "bc1qsomeoneelse"
+	mock := &mockExtender{addresses: map[int]models.Address{}}
+	for _, addr := range addrs {
+		mock.addresses[addr.AddressIndex] = addr
+	}
+
+	_, err := ExtendAddresses(mock, models.ChainBTC, derive, 10, nil)
+	if !errors.Is(err, config.ErrKeyMismatch) {
+		t.Fatalf("ExtendAddresses() error = %v, want ErrKeyMismatch", err)
+	}
+	if len(mock.addresses) != 3 {
+		t.Errorf("stored %d addresses, want 3 (nothing appended)", len(mock.addresses))
+	}
+
+	if _, err := ExtendAddresses(mock, models.ChainBTC, derive, config.MaxAddressesPerChain+1, nil); err == nil {
+		t.Error("ExtendAddresses() above MaxAddressesPerChain should fail")
+	}
+}
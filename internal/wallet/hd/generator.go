@@ -5,238 +5,264 @@ import (
 	"log/slog"
 	"runtime"
 	"sync"
-	"sync/atomic"
 	"time"
 
 	"github.com/btcsuite/btcd/btcutil/hdkeychain"
 	"github.com/btcsuite/btcd/chaincfg"
 
+	"github.com/Fantasim/hdpay/internal/shared/config"
 	"github.com/Fantasim/hdpay/internal/shared/models"
 )
 
 // ProgressCallback is called during address generation to report progress.
 type ProgressCallback func(chain models.Chain, generated int, total int)
 
+// BatchSink receives generated addresses in index order, one batch at a time.
+// The batch slice is not reused after the sink returns.
+type BatchSink func(batch []models.Address) error
+
 // GenerateBTCAddresses generates BTC Native SegWit addresses from index 0 to count-1.
 // Uses runtime.NumCPU() parallel workers with pre-derived parent key.
 func GenerateBTCAddresses(masterKey *hdkeychain.ExtendedKey, count int, net *chaincfg.Params, progress ProgressCallback) ([]models.Address, error) {
-	numWorkers := runtime.NumCPU()
-	slog.Info("generating BTC addresses",
-		"count", count,
-		"network", net.Name,
-		"workers", numWorkers,
-	)
-	start := time.Now()
+	return GenerateBTCAccountAddresses(masterKey, models.BTCAddressP2WPKH, 0, count, net, progress)
+}
 
-	// Pre-derive parent key to m/84'/coin'/0'/0 — done once instead of count times.
-	parentKey, err := DeriveBTCParentKey(masterKey, net)
+// GenerateBTCAccountAddresses generates BTC addresses of the given type for a BIP-44 account.
+func GenerateBTCAccountAddresses(masterKey *hdkeychain.ExtendedKey, addrType models.BTCAddressType, account uint32, count int, net *chaincfg.Params, progress ProgressCallback) ([]models.Address, error) {
+	// Pre-derive parent key to m/purpose'/coin'/account'/0 — done once instead of count times.
+	parentKey, err := DeriveBTCAccountParentKey(masterKey, addrType, account, net)
 	if err != nil {
 		return nil, fmt.Errorf("derive BTC parent key: %w", err)
 	}
 
-	addresses := make([]models.Address, count)
-	var done atomic.Int64
-	var firstErr atomic.Value
-
-	var wg sync.WaitGroup
-	chunkSize := (count + numWorkers - 1) / numWorkers
-
-	for w := 0; w < numWorkers; w++ {
-		chunkStart := w * chunkSize
-		chunkEnd := chunkStart + chunkSize
-		if chunkEnd > count {
-			chunkEnd = count
-		}
-		if chunkStart >= count {
-			break
-		}
-
-		wg.Add(1)
-		go func(from, to int) {
-			defer wg.Done()
-			for i := from; i < to; i++ {
-				// Stop early if another worker hit an error.
-				if firstErr.Load() != nil {
-					return
-				}
-
-				addr, err := DeriveBTCAddressFromParent(parentKey, uint32(i), net)
-				if err != nil {
-					firstErr.CompareAndSwap(nil, fmt.Errorf("generate BTC address at index %d: %w", i, err))
-					return
-				}
-
-				addresses[i] = models.Address{
-					Chain:        models.ChainBTC,
-					AddressIndex: i,
-					Address:      addr,
-				}
-
-				if n := done.Add(1); progress != nil && n%10000 == 0 {
-					progress(models.ChainBTC, int(n), count)
-				}
-			}
-		}(chunkStart, chunkEnd)
-	}
-
-	wg.Wait()
-
-	if errVal := firstErr.Load(); errVal != nil {
-		return nil, errVal.(error)
-	}
+	return GenerateBTCAddressesFromParent(parentKey, addrType, count, net, progress)
+}
 
-	slog.Info("BTC address generation complete",
-		"count", len(addresses),
-		"workers", numWorkers,
-		"duration", time.Since(start).Round(time.Millisecond),
+// GenerateBTCAddressesFromParent generates BTC addresses of the given type from a
+// pre-derived external chain key (m/purpose'/coin'/account'/0). The parent may be a
+// public key, which is how watch-only wallets are initialized from a zpub.
+func GenerateBTCAddressesFromParent(parentKey *hdkeychain.ExtendedKey, addrType models.BTCAddressType, count int, net *chaincfg.Params, progress ProgressCallback) ([]models.Address, error) {
+	slog.Info("generating BTC addresses",
+		"count", count,
+		"addressType", addrType,
+		"network", net.Name,
+		"watchOnly", !parentKey.IsPrivate(),
 	)
-	return addresses, nil
+
+	return GenerateAddressRange(models.ChainBTC, func(index uint32) (string, error) {
+		return DeriveBTCTypedAddressFromParent(parentKey, addrType, index, net)
+	}, 0, count, progress)
 }
 
 // GenerateBSCAddresses generates BSC/EVM addresses from index 0 to count-1.
 // Uses runtime.NumCPU() parallel workers with pre-derived parent key.
 func GenerateBSCAddresses(masterKey *hdkeychain.ExtendedKey, count int, progress ProgressCallback) ([]models.Address, error) {
-	numWorkers := runtime.NumCPU()
-	slog.Info("generating BSC addresses",
-		"count", count,
-		"workers", numWorkers,
-	)
-	start := time.Now()
+	return GenerateBSCAccountAddresses(masterKey, 0, count, progress)
+}
 
-	// Pre-derive parent key to m/44'/60'/0'/0 — done once instead of count times.
-	parentKey, err := DeriveBSCParentKey(masterKey)
+// GenerateBSCAccountAddresses generates BSC/EVM addresses for a BIP-44 account.
+func GenerateBSCAccountAddresses(masterKey *hdkeychain.ExtendedKey, account uint32, count int, progress ProgressCallback) ([]models.Address, error) {
+	// Pre-derive parent key to m/44'/60'/account'/0 — done once instead of count times.
+	parentKey, err := DeriveBSCAccountParentKey(masterKey, account)
 	if err != nil {
 		return nil, fmt.Errorf("derive BSC parent key: %w", err)
 	}
 
-	addresses := make([]models.Address, count)
-	var done atomic.Int64
-	var firstErr atomic.Value
+	return GenerateBSCAddressesFromParent(parentKey, count, progress)
+}
 
-	var wg sync.WaitGroup
-	chunkSize := (count + numWorkers - 1) / numWorkers
+// GenerateBSCAddressesFromParent generates BSC/EVM addresses from a pre-derived
+// external chain key (m/44'/60'/account'/0). The parent may be a public key (watch-only).
+func GenerateBSCAddressesFromParent(parentKey *hdkeychain.ExtendedKey, count int, progress ProgressCallback) ([]models.Address, error) {
+	slog.Info("generating BSC addresses",
+		"count", count,
+		"watchOnly", !parentKey.IsPrivate(),
+	)
 
-	for w := 0; w < numWorkers; w++ {
-		chunkStart := w * chunkSize
-		chunkEnd := chunkStart + chunkSize
-		if chunkEnd > count {
-			chunkEnd = count
-		}
-		if chunkStart >= count {
-			break
-		}
+	return GenerateAddressRange(models.ChainBSC, func(index uint32) (string, error) {
+		return DeriveBSCAddressFromParent(parentKey, index)
+	}, 0, count, progress)
+}
 
-		wg.Add(1)
-		go func(from, to int) {
-			defer wg.Done()
-			for i := from; i < to; i++ {
-				if firstErr.Load() != nil {
-					return
-				}
+// GenerateSOLAddresses generates SOL addresses from index 0 to count-1.
+// Uses runtime.NumCPU() parallel workers with pre-derived parent key.
+func GenerateSOLAddresses(seed []byte, count int, progress ProgressCallback) ([]models.Address, error) {
+	return GenerateSOLAccountAddresses(seed, 0, count, progress)
+}
 
-				addr, err := DeriveBSCAddressFromParent(parentKey, uint32(i))
-				if err != nil {
-					firstErr.CompareAndSwap(nil, fmt.Errorf("generate BSC address at index %d: %w", i, err))
-					return
-				}
+// GenerateSOLAccountAddresses generates SOL addresses for a BIP-44 account
+// (m/44'/501'/N'/account', see DeriveSOLAccountAddressFromParent).
+func GenerateSOLAccountAddresses(seed []byte, account uint32, count int, progress ProgressCallback) ([]models.Address, error) {
+	if err := validateAccount(account); err != nil {
+		return nil, err
+	}
 
-				addresses[i] = models.Address{
-					Chain:        models.ChainBSC,
-					AddressIndex: i,
-					Address:      addr,
-				}
+	slog.Info("generating SOL addresses",
+		"account", account,
+		"count", count,
+	)
 
-				if n := done.Add(1); progress != nil && n%10000 == 0 {
-					progress(models.ChainBSC, int(n), count)
-				}
-			}
-		}(chunkStart, chunkEnd)
+	// Pre-derive parent key to m/44'/501' — done once instead of count times.
+	parentKey, err := DeriveSOLParentKey(seed)
+	if err != nil {
+		return nil, fmt.Errorf("derive SOL parent key: %w", err)
 	}
 
-	wg.Wait()
+	return GenerateAddressRange(models.ChainSOL, func(index uint32) (string, error) {
+		return DeriveSOLAccountAddressFromParent(parentKey, account, index)
+	}, 0, count, progress)
+}
 
-	if errVal := firstErr.Load(); errVal != nil {
-		return nil, errVal.(error)
+// GenerateAddressRange generates the addresses of chain at indices from..to-1 with
+// derive and returns them in index order. See GenerateBatches.
+func GenerateAddressRange(chain models.Chain, derive IndexDeriver, from, to int, progress ProgressCallback) ([]models.Address, error) {
+	addresses := make([]models.Address, 0, max(to-from, 0))
+	err := GenerateBatches(chain, derive, from, to, progress, func(batch []models.Address) error {
+		addresses = append(addresses, batch...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-
-	slog.Info("BSC address generation complete",
-		"count", len(addresses),
-		"workers", numWorkers,
-		"duration", time.Since(start).Round(time.Millisecond),
-	)
 	return addresses, nil
 }
 
-// GenerateSOLAddresses generates SOL addresses from index 0 to count-1.
-// Uses runtime.NumCPU() parallel workers with pre-derived parent key.
-func GenerateSOLAddresses(seed []byte, count int, progress ProgressCallback) ([]models.Address, error) {
-	numWorkers := runtime.NumCPU()
-	slog.Info("generating SOL addresses",
-		"count", count,
+// GenerateBatches generates the addresses of chain at indices from..to-1 with derive
+// and hands them to sink in index order. The range is sharded into batches of up to
+// config.AddressGenBatchSize indices that runtime.NumCPU() workers claim in order;
+// completed batches are reordered and passed to sink on the calling goroutine, so
+// the caller can insert each batch as soon as all lower indices are done. At most
+// 2×workers batches are held in memory. progress is called from the calling
+// goroutine every 10,000 addresses handed to sink, with total = to-from.
+// Generation stops at the first derive or sink error.
+func GenerateBatches(chain models.Chain, derive IndexDeriver, from, to int, progress ProgressCallback, sink BatchSink) error {
+	count := to - from
+	if from < 0 || count <= 0 {
+		return fmt.Errorf("%w: invalid %s index range %d..%d", ErrDerivation, chain, from, to)
+	}
+
+	// Small ranges are split evenly so every CPU still gets a share.
+	numCPU := runtime.NumCPU()
+	batchSize := min(config.AddressGenBatchSize, (count+numCPU-1)/numCPU)
+	numBatches := (count + batchSize - 1) / batchSize
+	numWorkers := min(numCPU, numBatches)
+
+	slog.Info("generating addresses",
+		"chain", chain,
+		"from", from,
+		"to", to,
 		"workers", numWorkers,
+		"batches", numBatches,
 	)
 	start := time.Now()
 
-	// Pre-derive parent key to m/44'/501' — done once instead of count times.
-	parentKey, err := DeriveSOLParentKey(seed)
-	if err != nil {
-		return nil, fmt.Errorf("derive SOL parent key: %w", err)
+	type batchResult struct {
+		seq       int
+		addresses []models.Address
+		err       error
 	}
 
-	addresses := make([]models.Address, count)
-	var done atomic.Int64
-	var firstErr atomic.Value
+	done := make(chan struct{})
+	window := make(chan struct{}, 2*numWorkers)
+	seqs := make(chan int)
+	results := make(chan batchResult, numWorkers)
 
 	var wg sync.WaitGroup
-	chunkSize := (count + numWorkers - 1) / numWorkers
 
-	for w := 0; w < numWorkers; w++ {
-		chunkStart := w * chunkSize
-		chunkEnd := chunkStart + chunkSize
-		if chunkEnd > count {
-			chunkEnd = count
-		}
-		if chunkStart >= count {
-			break
+	// Dispatcher: hands out batch numbers in order, never more than the window
+	// ahead of the lowest batch not yet delivered to sink.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(seqs)
+		for seq := 0; seq < numBatches; seq++ {
+			select {
+			case window <- struct{}{}:
+			case <-done:
+				return
+			}
+			select {
+			case seqs <- seq:
+			case <-done:
+				return
+			}
 		}
+	}()
 
+	for w := 0; w < numWorkers; w++ {
 		wg.Add(1)
-		go func(from, to int) {
+		go func() {
 			defer wg.Done()
-			for i := from; i < to; i++ {
-				if firstErr.Load() != nil {
-					return
+			for seq := range seqs {
+				lo := from + seq*batchSize
+				hi := min(lo+batchSize, to)
+				batch := make([]models.Address, 0, hi-lo)
+
+				var err error
+				for index := lo; index < hi; index++ {
+					var addr string
+					addr, err = derive(uint32(index))
+					if err != nil {
+						err = fmt.Errorf("generate %s address at index %d: %w", chain, index, err)
+						break
+					}
+					batch = append(batch, models.Address{
+						Chain:        chain,
+						AddressIndex: index,
+						Address:      addr,
+					})
 				}
 
-				addr, err := DeriveSOLAddressFromParent(parentKey, uint32(i))
-				if err != nil {
-					firstErr.CompareAndSwap(nil, fmt.Errorf("generate SOL address at index %d: %w", i, err))
+				select {
+				case results <- batchResult{seq: seq, addresses: batch, err: err}:
+				case <-done:
 					return
 				}
+			}
+		}()
+	}
 
-				addresses[i] = models.Address{
-					Chain:        models.ChainSOL,
-					AddressIndex: i,
-					Address:      addr,
-				}
+	// Collector: deliver batches to sink strictly in order.
+	pending := make(map[int][]models.Address, cap(window))
+	generated := 0
+	var firstErr error
+	for next := 0; next < numBatches && firstErr == nil; {
+		r := <-results
+		if r.err != nil {
+			firstErr = r.err
+			break
+		}
+		pending[r.seq] = r.addresses
 
-				if n := done.Add(1); progress != nil && n%10000 == 0 {
-					progress(models.ChainSOL, int(n), count)
-				}
+		for batch, ok := pending[next]; ok; batch, ok = pending[next] {
+			delete(pending, next)
+			if err := sink(batch); err != nil {
+				firstErr = fmt.Errorf("store %s addresses %d..%d: %w", chain, batch[0].AddressIndex, batch[len(batch)-1].AddressIndex, err)
+				break
+			}
+			<-window
+			next++
+
+			before := generated
+			generated += len(batch)
+			for n := (before/10000 + 1) * 10000; progress != nil && n <= generated; n += 10000 {
+				progress(chain, n, count)
 			}
-		}(chunkStart, chunkEnd)
+		}
 	}
 
+	close(done)
 	wg.Wait()
 
-	if errVal := firstErr.Load(); errVal != nil {
-		return nil, errVal.(error)
+	if firstErr != nil {
+		return firstErr
 	}
 
-	slog.Info("SOL address generation complete",
-		"count", len(addresses),
+	slog.Info("address generation complete",
+		"chain", chain,
+		"count", count,
 		"workers", numWorkers,
 		"duration", time.Since(start).Round(time.Millisecond),
 	)
-	return addresses, nil
+	return nil
 }
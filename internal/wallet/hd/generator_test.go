@@ -1,11 +1,14 @@
 package hd
 
 import (
+	"errors"
+	"fmt"
 	"strings"
 	"testing"
 
 	"github.com/btcsuite/btcd/chaincfg"
 
+	"github.com/Fantasim/hdpay/internal/shared/config"
 	"github.com/Fantasim/hdpay/internal/shared/models"
 )
 
@@ -106,3 +109,123 @@ func TestGenerateSOLAddresses(t *testing.T) {
 		}
 	}
 }
+
+// indexDeriver returns "<chain>-<index>" so tests can check ordering without key derivation.
+func indexDeriver(chain models.Chain) IndexDeriver {
+	return func(index uint32) (string, error) {
+		return fmt.Sprintf("%s-%d", chain, index), nil
+	}
+}
+
+func TestGenerateBatchesOrdered(t *testing.T) {
+	const from, to = 7, 3*config.AddressGenBatchSize + 1234
+
+	var progressCalls []int
+	progress := func(chain models.Chain, generated int, total int) {
+		if total != to-from {
+			t.Errorf("progress total = %d, want %d", total, to-from)
+		}
+		progressCalls = append(progressCalls, generated)
+	}
+
+	next := from
+	batches := 0
+	err := GenerateBatches(models.ChainSOL, indexDeriver(models.ChainSOL), from, to, progress, func(batch []models.Address) error {
+		batches++
+		for _, addr := range batch {
+			if addr.AddressIndex != next || addr.Address != fmt.Sprintf("SOL-%d", next) || addr.Chain != models.ChainSOL {
+				return fmt.Errorf("got %+v, want index %d", addr, next)
+			}
+			next++
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("GenerateBatches() error = %v", err)
+	}
+	if next != to {
+		t.Errorf("sink received indices up to %d, want %d", next, to)
+	}
+	if batches < 4 {
+		t.Errorf("sink called %d times, want at least 4 batches", batches)
+	}
+	if want := []int{10000, 20000, 30000}; fmt.Sprint(progressCalls) != fmt.Sprint(want) {
+		t.Errorf("progress calls = %v, want %v", progressCalls, want)
+	}
+}
+
+func TestGenerateBatchesErrors(t *testing.T) {
+	failing := func(index uint32) (string, error) {
+		if index == 15_000 {
+			return "", errors.New("boom")
+		}
+		return "x", nil
+	}
+	err := GenerateBatches(models.ChainBTC, failing, 0, 40_000, nil, func([]models.Address) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "index 15000") {
+		t.Errorf("derive error = %v, want failure at index 15000", err)
+	}
+
+	stored := 0
+	sinkErr := errors.New("disk full")
+	err = GenerateBatches(models.ChainBTC, indexDeriver(models.ChainBTC), 0, 40_000, nil, func(batch []models.Address) error {
+		if stored > 0 {
+			return sinkErr
+		}
+		stored += len(batch)
+		return nil
+	})
+	if !errors.Is(err, sinkErr) {
+		t.Errorf("sink error = %v, want %v", err, sinkErr)
+	}
+
+	if err := GenerateBatches(models.ChainBTC, indexDeriver(models.ChainBTC), 5, 5, nil, nil); err == nil {
+		t.Error("GenerateBatches() with an empty range should fail")
+	}
+}
+
+// benchmarkGenerate generates config.MaxAddressesPerChain addresses per iteration and
+// reports throughput, e.g. go test ./internal/wallet/hd -run '^$' -bench Generate -benchtime 1x
+func benchmarkGenerate(b *testing.B, generate func(count int) ([]models.Address, error)) {
+	count := config.MaxAddressesPerChain
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		addresses, err := generate(count)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(addresses) != count {
+			b.Fatalf("generated %d addresses, want %d", len(addresses), count)
+		}
+	}
+	b.ReportMetric(float64(count*b.N)/b.Elapsed().Seconds(), "addrs/s")
+}
+
+func BenchmarkGenerateBTC500k(b *testing.B) {
+	seed, _ := MnemonicToSeed(testMnemonic24)
+	masterKey, err := DeriveMasterKey(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarkGenerate(b, func(count int) ([]models.Address, error) {
+		return GenerateBTCAddresses(masterKey, count, &chaincfg.MainNetParams, nil)
+	})
+}
+
+func BenchmarkGenerateBSC500k(b *testing.B) {
+	seed, _ := MnemonicToSeed(testMnemonic24)
+	masterKey, err := DeriveMasterKey(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarkGenerate(b, func(count int) ([]models.Address, error) {
+		return GenerateBSCAddresses(masterKey, count, nil)
+	})
+}
+
+func BenchmarkGenerateSOL500k(b *testing.B) {
+	seed, _ := MnemonicToSeed(testMnemonic24)
+	benchmarkGenerate(b, func(count int) ([]models.Address, error) {
+		return GenerateSOLAddresses(seed, count, nil)
+	})
+}
@@ -27,14 +27,39 @@ func ValidateMnemonic(mnemonic string) error {
 	return nil
 }
 
+// NewMnemonic generates a fresh 24-word BIP-39 mnemonic from 256 bits of entropy.
+// The caller owns the returned slice and should zero it after use.
+func NewMnemonic() ([]byte, error) {
+	entropy, err := bip39.NewEntropy(256)
+	if err != nil {
+		return nil, fmt.Errorf("generate entropy: %w", err)
+	}
+	defer ZeroBytes(entropy)
+
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		return nil, fmt.Errorf("generate mnemonic: %w", err)
+	}
+
+	slog.Info("new mnemonic generated", "wordCount", len(strings.Fields(mnemonic)))
+	return []byte(mnemonic), nil
+}
+
 // MnemonicToSeed converts a BIP-39 mnemonic to a 64-byte seed (empty passphrase).
 func MnemonicToSeed(mnemonic string) ([]byte, error) {
-	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, "")
+	return MnemonicToSeedWithPassphrase(mnemonic, "")
+}
+
+// MnemonicToSeedWithPassphrase converts a BIP-39 mnemonic to a 64-byte seed using
+// the optional BIP-39 passphrase ("25th word"). An empty passphrase is the default.
+// The passphrase is NFKD-normalized as BIP-39 requires.
+func MnemonicToSeedWithPassphrase(mnemonic string, passphrase string) ([]byte, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, normalizePassphrase(passphrase))
 	if err != nil {
 		return nil, fmt.Errorf("mnemonic to seed: %w", err)
 	}
 
-	slog.Debug("seed derived from mnemonic", "seedLen", len(seed))
+	slog.Debug("seed derived from mnemonic", "seedLen", len(seed), "passphrase", passphrase != "")
 	return seed, nil
 }
 
@@ -98,12 +123,19 @@ func ReadMnemonicBytesFromFile(path string) ([]byte, error) {
 
 // MnemonicBytesToSeed converts a BIP-39 mnemonic (as []byte) to a 64-byte seed (empty passphrase).
 func MnemonicBytesToSeed(mnemonic []byte) ([]byte, error) {
-	seed, err := bip39.NewSeedWithErrorChecking(string(mnemonic), "")
+	return MnemonicBytesToSeedWithPassphrase(mnemonic, nil)
+}
+
+// MnemonicBytesToSeedWithPassphrase converts a BIP-39 mnemonic (as []byte) to a 64-byte
+// seed using the optional BIP-39 passphrase. A nil/empty passphrase is the default.
+// The passphrase is NFKD-normalized as BIP-39 requires.
+func MnemonicBytesToSeedWithPassphrase(mnemonic []byte, passphrase []byte) ([]byte, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(string(mnemonic), normalizePassphrase(string(passphrase)))
 	if err != nil {
 		return nil, fmt.Errorf("mnemonic to seed: %w", err)
 	}
 
-	slog.Debug("seed derived from mnemonic", "seedLen", len(seed))
+	slog.Debug("seed derived from mnemonic", "seedLen", len(seed), "passphrase", len(passphrase) > 0)
 	return seed, nil
 }
 
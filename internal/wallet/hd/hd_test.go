@@ -52,6 +52,21 @@ func TestValidateMnemonic(t *testing.T) {
 	}
 }
 
+func TestNewMnemonic(t *testing.T) {
+	a, err := NewMnemonic()
+	if err != nil {
+		t.Fatalf("NewMnemonic() error = %v", err)
+	}
+	if err := ValidateMnemonic(string(a)); err != nil {
+		t.Errorf("NewMnemonic() produced invalid mnemonic: %v", err)
+	}
+
+	b, _ := NewMnemonic()
+	if string(a) == string(b) {
+		t.Error("two NewMnemonic() calls returned the same mnemonic")
+	}
+}
+
 func TestMnemonicToSeed(t *testing.T) {
 	seed, err := MnemonicToSeed(testMnemonic24)
 	if err != nil {
@@ -0,0 +1,262 @@
+package hd
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+)
+
+// keystoreScryptN is the scrypt cost used for new keystores. A variable so tests can
+// lower it; files always record the N they were written with.
+var keystoreScryptN = config.KeystoreScryptN
+
+// keystoreFile is the on-disk JSON layout of an encrypted mnemonic.
+type keystoreFile struct {
+	Version    int          `json:"version"`
+	KDF        string       `json:"kdf"`
+	KDFParams  scryptParams `json:"kdfparams"`
+	Cipher     string       `json:"cipher"`
+	Nonce      string       `json:"nonce"`
+	Ciphertext string       `json:"ciphertext"`
+}
+
+type scryptParams struct {
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+	Salt string `json:"salt"`
+}
+
+const (
+	keystoreKDF    = "scrypt"
+	keystoreCipher = "aes-256-gcm"
+)
+
+// EncryptMnemonic validates a mnemonic and seals it under password with a fresh
+// salt and nonce. Returns the keystore JSON.
+func EncryptMnemonic(mnemonic, password []byte) ([]byte, error) {
+	if len(password) < config.KeystoreMinPasswordLen {
+		return nil, fmt.Errorf("%w: need at least %d characters", ErrWeakPassword, config.KeystoreMinPasswordLen)
+	}
+	if err := ValidateMnemonic(string(mnemonic)); err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, config.KeystoreSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("generate keystore salt: %w", err)
+	}
+
+	params := scryptParams{
+		N:    keystoreScryptN,
+		R:    config.KeystoreScryptR,
+		P:    config.KeystoreScryptP,
+		Salt: hex.EncodeToString(salt),
+	}
+
+	aead, err := keystoreAEAD(password, salt, params)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generate keystore nonce: %w", err)
+	}
+
+	ciphertext := aead.Seal(nil, nonce, mnemonic, keystoreAAD(config.KeystoreVersion))
+
+	data, err := json.MarshalIndent(keystoreFile{
+		Version:    config.KeystoreVersion,
+		KDF:        keystoreKDF,
+		KDFParams:  params,
+		Cipher:     keystoreCipher,
+		Nonce:      hex.EncodeToString(nonce),
+		Ciphertext: hex.EncodeToString(ciphertext),
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal keystore: %w", err)
+	}
+
+	slog.Debug("mnemonic encrypted", "kdf", keystoreKDF, "scryptN", params.N, "cipher", keystoreCipher)
+	return data, nil
+}
+
+// DecryptMnemonic opens keystore JSON with password and returns the validated
+// mnemonic. The caller owns the returned slice and should mlock and zero it.
+func DecryptMnemonic(data, password []byte) ([]byte, error) {
+	var ks keystoreFile
+	if err := json.Unmarshal(data, &ks); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKeystore, err)
+	}
+
+	if ks.Version != config.KeystoreVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidKeystore, ks.Version)
+	}
+	if ks.KDF != keystoreKDF || ks.Cipher != keystoreCipher {
+		return nil, fmt.Errorf("%w: unsupported kdf/cipher %s/%s", ErrInvalidKeystore, ks.KDF, ks.Cipher)
+	}
+	// Bound the work factor so a crafted file cannot exhaust memory on unlock.
+	n := ks.KDFParams.N
+	if n < 2 || n&(n-1) != 0 || n > config.KeystoreMaxScryptN || ks.KDFParams.R < 1 || ks.KDFParams.P < 1 {
+		return nil, fmt.Errorf("%w: bad scrypt parameters n=%d r=%d p=%d",
+			ErrInvalidKeystore, n, ks.KDFParams.R, ks.KDFParams.P)
+	}
+
+	salt, err := hex.DecodeString(ks.KDFParams.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: salt: %s", ErrInvalidKeystore, err)
+	}
+	nonce, err := hex.DecodeString(ks.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: nonce: %s", ErrInvalidKeystore, err)
+	}
+	ciphertext, err := hex.DecodeString(ks.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("%w: ciphertext: %s", ErrInvalidKeystore, err)
+	}
+
+	aead, err := keystoreAEAD(password, salt, ks.KDFParams)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("%w: nonce length %d", ErrInvalidKeystore, len(nonce))
+	}
+
+	mnemonic, err := aead.Open(nil, nonce, ciphertext, keystoreAAD(ks.Version))
+	if err != nil {
+		return nil, ErrKeystorePassword
+	}
+
+	if err := ValidateMnemonic(string(mnemonic)); err != nil {
+		ZeroBytes(mnemonic)
+		return nil, fmt.Errorf("%w: decrypted mnemonic: %s", ErrInvalidKeystore, err)
+	}
+
+	return mnemonic, nil
+}
+
+// WriteKeystoreFile encrypts mnemonic under password and writes it to path with
+// 0600 permissions. The file is replaced atomically; an existing keystore is only
+// overwritten when overwrite is true.
+func WriteKeystoreFile(path string, mnemonic, password []byte, overwrite bool) error {
+	if !overwrite {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("keystore %q already exists", path)
+		}
+	}
+
+	data, err := EncryptMnemonic(mnemonic, password)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("create keystore directory %q: %w", dir, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".keystore-*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp keystore: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("chmod temp keystore: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write temp keystore: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("sync temp keystore: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close temp keystore: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("install keystore %q: %w", path, err)
+	}
+
+	slog.Info("keystore written", "path", path)
+	return nil
+}
+
+// ReadKeystoreFile reads and decrypts the keystore at path. The caller owns the
+// returned mnemonic and should mlock and zero it.
+func ReadKeystoreFile(path string, password []byte) ([]byte, error) {
+	slog.Info("unlocking keystore", "path", path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read keystore %q: %w", path, err)
+	}
+
+	mnemonic, err := DecryptMnemonic(data, password)
+	if err != nil {
+		return nil, fmt.Errorf("keystore %q: %w", path, err)
+	}
+
+	slog.Info("keystore unlocked", "path", path)
+	return mnemonic, nil
+}
+
+// ChangeKeystorePassword re-encrypts the keystore at path under newPassword with a
+// fresh salt and nonce.
+func ChangeKeystorePassword(path string, oldPassword, newPassword []byte) error {
+	mnemonic, err := ReadKeystoreFile(path, oldPassword)
+	if err != nil {
+		return err
+	}
+	MlockBytes(mnemonic)
+	defer func() {
+		MunlockBytes(mnemonic)
+		ZeroBytes(mnemonic)
+	}()
+
+	if err := WriteKeystoreFile(path, mnemonic, newPassword, true); err != nil {
+		return fmt.Errorf("rewrite keystore: %w", err)
+	}
+
+	slog.Info("keystore password changed", "path", path)
+	return nil
+}
+
+// keystoreAEAD derives the AES-256 key from password with scrypt and returns the GCM AEAD.
+func keystoreAEAD(password, salt []byte, params scryptParams) (cipher.AEAD, error) {
+	key, err := scrypt.Key(password, salt, params.N, params.R, params.P, config.KeystoreKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("derive keystore key: %w", err)
+	}
+	defer ZeroBytes(key)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create keystore cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create keystore GCM: %w", err)
+	}
+	return aead, nil
+}
+
+// keystoreAAD binds the ciphertext to the keystore format version.
+func keystoreAAD(version int) []byte {
+	return []byte(fmt.Sprintf("hdpay-keystore-v%d", version))
+}
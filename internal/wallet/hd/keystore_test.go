@@ -0,0 +1,180 @@
+package hd
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testKeystorePassword = "correct horse battery"
+
+// fastKeystore lowers the scrypt cost for the duration of a test.
+func fastKeystore(t *testing.T) {
+	t.Helper()
+	prev := keystoreScryptN
+	keystoreScryptN = 1 << 10
+	t.Cleanup(func() { keystoreScryptN = prev })
+}
+
+func TestKeystoreRoundTrip(t *testing.T) {
+	fastKeystore(t)
+
+	data, err := EncryptMnemonic([]byte(testMnemonic24), []byte(testKeystorePassword))
+	if err != nil {
+		t.Fatalf("EncryptMnemonic() error = %v", err)
+	}
+	if strings.Contains(string(data), "abandon") {
+		t.Fatal("keystore contains plaintext mnemonic words")
+	}
+
+	mnemonic, err := DecryptMnemonic(data, []byte(testKeystorePassword))
+	if err != nil {
+		t.Fatalf("DecryptMnemonic() error = %v", err)
+	}
+	if string(mnemonic) != testMnemonic24 {
+		t.Errorf("decrypted mnemonic = %q, want test mnemonic", mnemonic)
+	}
+}
+
+func TestKeystoreFreshSaltAndNonce(t *testing.T) {
+	fastKeystore(t)
+
+	a, _ := EncryptMnemonic([]byte(testMnemonic24), []byte(testKeystorePassword))
+	b, _ := EncryptMnemonic([]byte(testMnemonic24), []byte(testKeystorePassword))
+
+	var ka, kb keystoreFile
+	if err := json.Unmarshal(a, &ka); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, &kb); err != nil {
+		t.Fatal(err)
+	}
+	if ka.KDFParams.Salt == kb.KDFParams.Salt || ka.Nonce == kb.Nonce || ka.Ciphertext == kb.Ciphertext {
+		t.Error("two encryptions of the same mnemonic share salt, nonce or ciphertext")
+	}
+}
+
+func TestKeystoreWrongPassword(t *testing.T) {
+	fastKeystore(t)
+
+	data, _ := EncryptMnemonic([]byte(testMnemonic24), []byte(testKeystorePassword))
+	if _, err := DecryptMnemonic(data, []byte("wrong password")); !errors.Is(err, ErrKeystorePassword) {
+		t.Errorf("DecryptMnemonic() error = %v, want ErrKeystorePassword", err)
+	}
+}
+
+func TestKeystoreRejects(t *testing.T) {
+	fastKeystore(t)
+
+	if _, err := EncryptMnemonic([]byte(testMnemonic24), []byte("short")); !errors.Is(err, ErrWeakPassword) {
+		t.Errorf("EncryptMnemonic(short password) error = %v, want ErrWeakPassword", err)
+	}
+	if _, err := EncryptMnemonic([]byte(testMnemonic12), []byte(testKeystorePassword)); !errors.Is(err, ErrInvalidMnemonic) {
+		t.Errorf("EncryptMnemonic(12 words) error = %v, want ErrInvalidMnemonic", err)
+	}
+
+	data, _ := EncryptMnemonic([]byte(testMnemonic24), []byte(testKeystorePassword))
+	tamper := func(edit func(*keystoreFile)) []byte {
+		var ks keystoreFile
+		if err := json.Unmarshal(data, &ks); err != nil {
+			t.Fatal(err)
+		}
+		edit(&ks)
+		out, _ := json.Marshal(ks)
+		return out
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"not json", []byte("{"), ErrInvalidKeystore},
+		{"future version", tamper(func(k *keystoreFile) { k.Version = 99 }), ErrInvalidKeystore},
+		{"unknown kdf", tamper(func(k *keystoreFile) { k.KDF = "pbkdf2" }), ErrInvalidKeystore},
+		{"huge scrypt N", tamper(func(k *keystoreFile) { k.KDFParams.N = 1 << 30 }), ErrInvalidKeystore},
+		{"scrypt N not power of two", tamper(func(k *keystoreFile) { k.KDFParams.N = 1000 }), ErrInvalidKeystore},
+		{"bad nonce", tamper(func(k *keystoreFile) { k.Nonce = "00" }), ErrInvalidKeystore},
+		{"flipped ciphertext", tamper(func(k *keystoreFile) {
+			b := []byte(k.Ciphertext)
+			if b[0] == '0' {
+				b[0] = '1'
+			} else {
+				b[0] = '0'
+			}
+			k.Ciphertext = string(b)
+		}), ErrKeystorePassword},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecryptMnemonic(tt.data, []byte(testKeystorePassword)); !errors.Is(err, tt.want) {
+				t.Errorf("DecryptMnemonic() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeystoreFileLifecycle(t *testing.T) {
+	fastKeystore(t)
+	path := filepath.Join(t.TempDir(), "wallet", "keystore.json")
+
+	if err := WriteKeystoreFile(path, []byte(testMnemonic24), []byte(testKeystorePassword), false); err != nil {
+		t.Fatalf("WriteKeystoreFile() error = %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("keystore permissions = %o, want 600", perm)
+	}
+
+	if err := WriteKeystoreFile(path, []byte(testMnemonic24), []byte(testKeystorePassword), false); err == nil {
+		t.Error("WriteKeystoreFile() overwrote an existing keystore without overwrite=true")
+	}
+
+	const newPassword = "a much longer new password"
+	if err := ChangeKeystorePassword(path, []byte(testKeystorePassword), []byte(newPassword)); err != nil {
+		t.Fatalf("ChangeKeystorePassword() error = %v", err)
+	}
+	if _, err := ReadKeystoreFile(path, []byte(testKeystorePassword)); !errors.Is(err, ErrKeystorePassword) {
+		t.Errorf("old password after change: error = %v, want ErrKeystorePassword", err)
+	}
+
+	mnemonic, err := ReadKeystoreFile(path, []byte(newPassword))
+	if err != nil {
+		t.Fatalf("ReadKeystoreFile() error = %v", err)
+	}
+	if string(mnemonic) != testMnemonic24 {
+		t.Error("mnemonic changed across password change")
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("keystore directory has %d entries, want only the keystore (temp file leaked?)", len(entries))
+	}
+}
+
+func TestReadSecretFromFD(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.WriteString(testKeystorePassword + "\nignored\n"); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	secret, err := ReadSecretFromFD(int(r.Fd()))
+	if err != nil {
+		t.Fatalf("ReadSecretFromFD() error = %v", err)
+	}
+	if string(secret) != testKeystorePassword {
+		t.Errorf("ReadSecretFromFD() = %q, want %q", secret, testKeystorePassword)
+	}
+}
@@ -0,0 +1,92 @@
+package hd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// maxSecretLineLen caps interactive/file secret input to avoid unbounded reads.
+const maxSecretLineLen = 1024
+
+// normalizePassphrase applies the NFKD normalization BIP-39 requires before a
+// passphrase salts the seed. go-bip39 uses the passphrase as given, so without it
+// a non-ASCII passphrase typed in composed form would give a different seed than
+// other BIP-39 wallets.
+func normalizePassphrase(passphrase string) string {
+	return norm.NFKD.String(passphrase)
+}
+
+// ReadPassphraseFromFile reads a BIP-39 passphrase from a file.
+// Only the trailing line ending is stripped: leading/trailing spaces are part of
+// the passphrase per BIP-39 and must be preserved. An empty file yields an empty
+// passphrase. The caller owns the returned slice and should zero it after use.
+func ReadPassphraseFromFile(path string) ([]byte, error) {
+	slog.Info("reading BIP-39 passphrase from file", "path", path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read passphrase file %q: %w", path, err)
+	}
+	defer ZeroBytes(data)
+
+	trimmed := bytes.TrimSuffix(data, []byte("\n"))
+	trimmed = bytes.TrimSuffix(trimmed, []byte("\r"))
+
+	passphrase := make([]byte, len(trimmed))
+	copy(passphrase, trimmed)
+
+	slog.Info("passphrase read from file", "empty", len(passphrase) == 0)
+	return passphrase, nil
+}
+
+// readSecretLine reads a single line from r one byte at a time, so no buffered
+// copy of the secret is left behind. The line ending is not included.
+func readSecretLine(r io.Reader) ([]byte, error) {
+	secret := make([]byte, 0, 64)
+	buf := make([]byte, 1)
+
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if buf[0] == '\n' {
+				break
+			}
+			if len(secret) >= maxSecretLineLen {
+				ZeroBytes(secret)
+				return nil, fmt.Errorf("secret input exceeds %d bytes", maxSecretLineLen)
+			}
+			secret = append(secret, buf[0])
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			ZeroBytes(secret)
+			return nil, fmt.Errorf("read secret: %w", err)
+		}
+	}
+
+	return bytes.TrimSuffix(secret, []byte("\r")), nil
+}
+
+// ReadSecretFromFD reads one line from an inherited file descriptor (e.g. a pipe
+// set up by a process supervisor) and closes it. The caller owns the returned
+// slice and should zero it after use.
+func ReadSecretFromFD(fd int) ([]byte, error) {
+	f := os.NewFile(uintptr(fd), fmt.Sprintf("fd%d", fd))
+	if f == nil {
+		return nil, fmt.Errorf("invalid file descriptor %d", fd)
+	}
+	defer f.Close()
+
+	secret, err := readSecretLine(f)
+	if err != nil {
+		return nil, fmt.Errorf("read secret from fd %d: %w", fd, err)
+	}
+	return secret, nil
+}
@@ -0,0 +1,140 @@
+package hd
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+
+	"github.com/Fantasim/hdpay/internal/shared/models"
+)
+
+func TestMnemonicToSeedWithPassphraseKnownVector(t *testing.T) {
+	// Official BIP-39 test vector: "abandon ... about" with passphrase "TREZOR".
+	seed, err := MnemonicToSeedWithPassphrase(testMnemonic12, "TREZOR")
+	if err != nil {
+		t.Fatalf("MnemonicToSeedWithPassphrase() error = %v", err)
+	}
+
+	want := "c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04"
+	if got := hex.EncodeToString(seed); got != want {
+		t.Errorf("seed = %s, want %s", got, want)
+	}
+}
+
+func TestMnemonicToSeedWithPassphraseNFKD(t *testing.T) {
+	// BIP-39 salts the seed with the NFKD form of the passphrase: composed and
+	// decomposed spellings of the same passphrase give the same seed.
+	want := "a96e3047691c6a5bb8c7ab5afd16bb7bafcf3cc9ff415183bdcd91a5b49e114e3b4f7dc2f4f489f33aba1d7a57f66d7a25cc25df5d41150a7bfde534f6237e96"
+	for _, passphrase := range []string{"p\u00e4ssw\u00f6rd", "pa\u0308sswo\u0308rd"} {
+		seed, err := MnemonicToSeedWithPassphrase(testMnemonic24, passphrase)
+		if err != nil {
+			t.Fatalf("MnemonicToSeedWithPassphrase(%q) error = %v", passphrase, err)
+		}
+		if got := hex.EncodeToString(seed); got != want {
+			t.Errorf("MnemonicToSeedWithPassphrase(%q) seed = %s, want %s", passphrase, got, want)
+		}
+
+		seed, err = MnemonicBytesToSeedWithPassphrase([]byte(testMnemonic24), []byte(passphrase))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(seed); got != want {
+			t.Errorf("MnemonicBytesToSeedWithPassphrase(%q) seed = %s, want %s", passphrase, got, want)
+		}
+	}
+}
+
+func TestMnemonicBytesToSeedWithPassphrase(t *testing.T) {
+	withPass, err := MnemonicBytesToSeedWithPassphrase([]byte(testMnemonic24), []byte("correct horse"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromString, err := MnemonicToSeedWithPassphrase(testMnemonic24, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(withPass) != hex.EncodeToString(fromString) {
+		t.Error("byte and string passphrase APIs produced different seeds")
+	}
+
+	noPass, err := MnemonicBytesToSeed([]byte(testMnemonic24))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(withPass) == hex.EncodeToString(noPass) {
+		t.Error("passphrase did not change the seed")
+	}
+}
+
+func TestPassphraseChangesAddresses(t *testing.T) {
+	plain, _ := MnemonicToSeed(testMnemonic24)
+	protected, _ := MnemonicToSeedWithPassphrase(testMnemonic24, "hunter2")
+
+	for _, chain := range models.AllChains {
+		a, err := DeriveAddressFromSeed(plain, chain, 0, &chaincfg.MainNetParams)
+		if err != nil {
+			t.Fatalf("%s: %v", chain, err)
+		}
+		b, err := DeriveAddressFromSeed(protected, chain, 0, &chaincfg.MainNetParams)
+		if err != nil {
+			t.Fatalf("%s: %v", chain, err)
+		}
+		if a == b {
+			t.Errorf("%s: passphrase-protected address equals plain address %s", chain, a)
+		}
+	}
+}
+
+func TestReadPassphraseFromFile(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"trailing newline stripped", "secret\n", "secret"},
+		{"CRLF stripped", "secret\r\n", "secret"},
+		{"spaces preserved", "  spaced out  \n", "  spaced out  "},
+		{"empty file", "", ""},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "pass"+string(rune('a'+i)))
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := ReadPassphraseFromFile(path)
+			if err != nil {
+				t.Fatalf("ReadPassphraseFromFile() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ReadPassphraseFromFile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ReadPassphraseFromFile(filepath.Join(dir, "missing")); err == nil {
+		t.Error("ReadPassphraseFromFile() expected error for missing file")
+	}
+}
+
+func TestReadSecretLine(t *testing.T) {
+	got, err := readSecretLine(strings.NewReader("first line\r\nsecond"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "first line" {
+		t.Errorf("readSecretLine() = %q, want %q", got, "first line")
+	}
+
+	if _, err := readSecretLine(strings.NewReader(strings.Repeat("x", maxSecretLineLen+1))); err == nil {
+		t.Error("readSecretLine() expected error for oversized input")
+	}
+}
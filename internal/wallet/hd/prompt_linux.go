@@ -0,0 +1,36 @@
+//go:build linux
+
+package hd
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// ReadSecretFromTerminal prints prompt to stderr and reads one line from stdin with
+// terminal echo disabled. Fails if stdin is not a terminal, so secrets are never
+// silently read from a pipe when an interactive prompt was requested.
+func ReadSecretFromTerminal(prompt string) ([]byte, error) {
+	fd := uintptr(os.Stdin.Fd())
+
+	var oldState syscall.Termios
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TCGETS, uintptr(unsafe.Pointer(&oldState))); errno != 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoTerminal, errno)
+	}
+
+	newState := oldState
+	newState.Lflag &^= syscall.ECHO
+	newState.Lflag |= syscall.ICANON | syscall.ISIG
+	newState.Iflag |= syscall.ICRNL
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TCSETS, uintptr(unsafe.Pointer(&newState))); errno != 0 {
+		return nil, fmt.Errorf("disable terminal echo: %s", errno)
+	}
+	defer syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TCSETS, uintptr(unsafe.Pointer(&oldState)))
+
+	fmt.Fprint(os.Stderr, prompt)
+	defer fmt.Fprintln(os.Stderr)
+
+	return readSecretLine(os.Stdin)
+}
@@ -0,0 +1,17 @@
+//go:build !linux
+
+package hd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// ReadSecretFromTerminal prints prompt to stderr and reads one line from stdin.
+// Echo suppression is only implemented on Linux; elsewhere input is visible.
+func ReadSecretFromTerminal(prompt string) ([]byte, error) {
+	slog.Warn("terminal echo suppression not supported on this platform")
+	fmt.Fprint(os.Stderr, prompt)
+	return readSecretLine(os.Stdin)
+}
@@ -0,0 +1,201 @@
+package hd
+
+import (
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// SLIP-39 reference vectors (passphrase "TREZOR").
+const (
+	slip39Vector1Share = "duckling enlarge academic academic agency result length solution fridge kidney coal piece deal husband erode duke ajar critical decision keyboard"
+	slip39Vector1Seed  = "bb54aac4b89dc868ba37d9cc21b2cece"
+	slip39Vector4A     = "shadow pistol academic always adequate wildlife fancy gross oasis cylinder mustang wrist rescue view short owner flip making coding armed"
+	slip39Vector4B     = "shadow pistol academic acid actress prayer class unknown daughter sweater depict flip twice unkind craft early superior advocate guest smoking"
+	slip39Vector4Seed  = "b43ceb7e57a0ea8766221624d01b0864"
+)
+
+func TestSLIP39Wordlist(t *testing.T) {
+	words := slip39Wordlist[:]
+	if !sort.StringsAreSorted(words) {
+		t.Error("wordlist is not sorted")
+	}
+	prefixes := make(map[string]bool, len(words))
+	for _, w := range words {
+		if len(w) < 4 || len(w) > 8 {
+			t.Errorf("word %q has length %d", w, len(w))
+		}
+		if prefixes[w[:4]] {
+			t.Errorf("duplicate 4-letter prefix %q", w[:4])
+		}
+		prefixes[w[:4]] = true
+	}
+}
+
+func TestCombineSLIP39SharesVectors(t *testing.T) {
+	tests := []struct {
+		name   string
+		shares []string
+		want   string
+	}{
+		{"single share", []string{slip39Vector1Share}, slip39Vector1Seed},
+		{"2-of-3", []string{slip39Vector4A, slip39Vector4B}, slip39Vector4Seed},
+		{"2-of-3 reversed order", []string{slip39Vector4B, slip39Vector4A}, slip39Vector4Seed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret, err := CombineSLIP39Shares(tt.shares, []byte("TREZOR"))
+			if err != nil {
+				t.Fatalf("CombineSLIP39Shares() error = %v", err)
+			}
+			if got := hex.EncodeToString(secret); got != tt.want {
+				t.Errorf("master secret = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCombineSLIP39SharesRejects(t *testing.T) {
+	words := strings.Fields(slip39Vector1Share)
+	words[5] = "academic"
+	badChecksum := strings.Join(words, " ")
+
+	tests := []struct {
+		name   string
+		shares []string
+		want   error
+	}{
+		{"no shares", nil, ErrInsufficientShares},
+		{"below threshold", []string{slip39Vector4A}, ErrInsufficientShares},
+		{"bad checksum", []string{badChecksum}, ErrInvalidShare},
+		{"unknown word", []string{strings.Replace(slip39Vector1Share, "duckling", "duckbill", 1)}, ErrInvalidShare},
+		{"too short", []string{"duckling enlarge academic"}, ErrInvalidShare},
+		{"mixed share sets", []string{slip39Vector1Share, slip39Vector4A}, ErrInvalidShare},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := CombineSLIP39Shares(tt.shares, []byte("TREZOR")); !errors.Is(err, tt.want) {
+				t.Errorf("CombineSLIP39Shares() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSLIP39ShareMnemonicRoundTrip(t *testing.T) {
+	for _, m := range []string{slip39Vector1Share, slip39Vector4A} {
+		share, err := ParseSLIP39Share(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := share.Mnemonic(); got != m {
+			t.Errorf("Mnemonic() = %q, want %q", got, m)
+		}
+	}
+}
+
+func TestSplitSLIP39GroupThresholds(t *testing.T) {
+	seed, _ := MnemonicToSeed(testMnemonic24)
+	passphrase := []byte("backup passphrase")
+
+	// 2 of 3 groups: 2-of-3 members, 3-of-5 members, 1-of-1.
+	groups := []SLIP39Group{{Threshold: 2, Count: 3}, {Threshold: 3, Count: 5}, {Threshold: 1, Count: 1}}
+	shares, err := SplitSLIP39(seed, passphrase, 2, groups, 0)
+	if err != nil {
+		t.Fatalf("SplitSLIP39() error = %v", err)
+	}
+	for gi, g := range groups {
+		if len(shares[gi]) != g.Count {
+			t.Fatalf("group %d has %d shares, want %d", gi, len(shares[gi]), g.Count)
+		}
+	}
+
+	valid := map[string][]string{
+		"groups 1+2":               {shares[0][0], shares[0][2], shares[1][1], shares[1][3], shares[1][4]},
+		"groups 2+3":               {shares[1][0], shares[1][1], shares[1][2], shares[2][0]},
+		"surplus shares":           append(append([]string{}, shares[0]...), shares[2][0], shares[1][0]),
+		"incomplete group skipped": {shares[1][0], shares[0][1], shares[0][0], shares[2][0]},
+	}
+	for name, set := range valid {
+		t.Run(name, func(t *testing.T) {
+			got, err := CombineSLIP39Shares(set, passphrase)
+			if err != nil {
+				t.Fatalf("CombineSLIP39Shares() error = %v", err)
+			}
+			if hex.EncodeToString(got) != hex.EncodeToString(seed) {
+				t.Error("recovered seed differs from the split seed")
+			}
+		})
+	}
+
+	t.Run("one group only", func(t *testing.T) {
+		if _, err := CombineSLIP39Shares(shares[1][:3], passphrase); !errors.Is(err, ErrInsufficientShares) {
+			t.Errorf("error = %v, want ErrInsufficientShares", err)
+		}
+	})
+
+	t.Run("wrong passphrase yields a different seed", func(t *testing.T) {
+		got, err := CombineSLIP39Shares([]string{shares[0][0], shares[0][1], shares[2][0]}, []byte("other"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(got) == hex.EncodeToString(seed) {
+			t.Error("wrong passphrase recovered the original seed")
+		}
+	})
+}
+
+func TestSplitSLIP39Rejects(t *testing.T) {
+	seed, _ := MnemonicToSeed(testMnemonic24)
+
+	tests := []struct {
+		name      string
+		secret    []byte
+		threshold int
+		groups    []SLIP39Group
+	}{
+		{"short secret", seed[:10], 1, []SLIP39Group{{2, 3}}},
+		{"odd secret", seed[:17], 1, []SLIP39Group{{2, 3}}},
+		{"group threshold too high", seed, 2, []SLIP39Group{{2, 3}}},
+		{"member threshold above count", seed, 1, []SLIP39Group{{4, 3}}},
+		{"1-of-N", seed, 1, []SLIP39Group{{1, 3}}},
+		{"too many members", seed, 1, []SLIP39Group{{2, 17}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := SplitSLIP39(tt.secret, nil, tt.threshold, tt.groups, 0); !errors.Is(err, ErrInvalidShare) {
+				t.Errorf("SplitSLIP39() error = %v, want ErrInvalidShare", err)
+			}
+		})
+	}
+}
+
+func TestSLIP39SeedFromFiles(t *testing.T) {
+	dir := t.TempDir()
+	one := filepath.Join(dir, "share1.txt")
+	two := filepath.Join(dir, "share2.txt")
+	if err := os.WriteFile(one, []byte(slip39Vector4A+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(two, []byte("\n  "+slip39Vector4B+"  \n\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	seed, err := SLIP39SeedFromFiles([]string{one, two}, []byte("TREZOR"))
+	if err != nil {
+		t.Fatalf("SLIP39SeedFromFiles() error = %v", err)
+	}
+	if got := hex.EncodeToString(seed); got != slip39Vector4Seed {
+		t.Errorf("seed = %s, want %s", got, slip39Vector4Seed)
+	}
+
+	if _, err := SLIP39SeedFromFiles([]string{filepath.Join(dir, "missing.txt")}, nil); err == nil {
+		t.Error("SLIP39SeedFromFiles() with a missing file should fail")
+	}
+}
@@ -48,10 +48,24 @@ func DeriveSOLParentKey(seed []byte) (slip10Key, error) {
 // parentKey must be at m/44'/501' (from DeriveSOLParentKey).
 // Only performs 2 derivations (index' and 0') instead of 4, plus skips master key derivation.
 func DeriveSOLAddressFromParent(parentKey slip10Key, index uint32) (string, error) {
+	return DeriveSOLAccountAddressFromParent(parentKey, 0, index)
+}
+
+// DeriveSOLAccountAddressFromParent derives the SOL address at index for a BIP-44
+// account. Path: m/44'/501'/index'/account'.
+//
+// The Phantom/Solflare path already spends the third level on the address index, so
+// the account is carried by the last hardened level instead. Account 0 is exactly
+// the wallet-compatible m/44'/501'/N'/0' path.
+func DeriveSOLAccountAddressFromParent(parentKey slip10Key, account, index uint32) (string, error) {
+	if err := validateAccount(account); err != nil {
+		return "", err
+	}
+
 	// m/44'/501'/index'
 	current := slip10DeriveChild(parentKey, index+hardenedOffset)
-	// m/44'/501'/index'/0'
-	current = slip10DeriveChild(current, 0+hardenedOffset)
+	// m/44'/501'/index'/account'
+	current = slip10DeriveChild(current, account+hardenedOffset)
 
 	privKey := ed25519.NewKeyFromSeed(current.key)
 	pubKey := privKey.Public().(ed25519.PublicKey)
@@ -62,17 +76,24 @@ func DeriveSOLAddressFromParent(parentKey slip10Key, index uint32) (string, erro
 // DeriveSOLAddress derives a Solana address using SLIP-10 ed25519 at the given account index.
 // Path: m/44'/501'/N'/0' (all hardened, Phantom/Solflare standard).
 func DeriveSOLAddress(seed []byte, index uint32) (string, error) {
+	return DeriveSOLAccountAddress(seed, 0, index)
+}
+
+// DeriveSOLAccountAddress derives the Solana address at index for a BIP-44 account.
+// Path: m/44'/501'/N'/account' (see DeriveSOLAccountAddressFromParent).
+func DeriveSOLAccountAddress(seed []byte, account, index uint32) (string, error) {
 	parentKey, err := DeriveSOLParentKey(seed)
 	if err != nil {
 		return "", err
 	}
 
-	addr, err := DeriveSOLAddressFromParent(parentKey, index)
+	addr, err := DeriveSOLAccountAddressFromParent(parentKey, account, index)
 	if err != nil {
 		return "", err
 	}
 
 	slog.Debug("derived SOL address",
+		"account", account,
 		"index", index,
 		"address", addr,
 	)
@@ -83,6 +104,16 @@ func DeriveSOLAddress(seed []byte, index uint32) (string, error) {
 // DeriveSOLPrivateKey derives the ed25519 private key for a Solana address at the given index.
 // Used only during transaction signing — caller must discard the key immediately after use.
 func DeriveSOLPrivateKey(seed []byte, index uint32) (ed25519.PrivateKey, error) {
+	return DeriveSOLAccountPrivateKey(seed, 0, index)
+}
+
+// DeriveSOLAccountPrivateKey derives the ed25519 private key at m/44'/501'/index'/account'.
+// Used only during transaction signing — caller must discard the key immediately after use.
+func DeriveSOLAccountPrivateKey(seed []byte, account, index uint32) (ed25519.PrivateKey, error) {
+	if err := validateAccount(account); err != nil {
+		return nil, err
+	}
+
 	mac := hmac.New(sha512.New, []byte(slip10Curve))
 	mac.Write(seed)
 	I := mac.Sum(nil)
@@ -96,7 +127,7 @@ func DeriveSOLPrivateKey(seed []byte, index uint32) (ed25519.PrivateKey, error)
 		44 + hardenedOffset,
 		501 + hardenedOffset,
 		index + hardenedOffset,
-		0 + hardenedOffset,
+		account + hardenedOffset,
 	}
 
 	current := master
@@ -106,7 +137,7 @@ func DeriveSOLPrivateKey(seed []byte, index uint32) (ed25519.PrivateKey, error)
 
 	privKey := ed25519.NewKeyFromSeed(current.key)
 
-	slog.Debug("derived SOL private key for signing", "index", index)
+	slog.Debug("derived SOL private key for signing", "account", account, "index", index)
 	return privKey, nil
 }
 
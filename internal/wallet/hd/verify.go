@@ -0,0 +1,47 @@
+package hd
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+
+	"github.com/Fantasim/hdpay/internal/shared/models"
+)
+
+// DeriveAddressFromSeed derives the address at index for chain directly from a BIP-39
+// seed. Used to check stored addresses against the configured mnemonic + passphrase.
+func DeriveAddressFromSeed(seed []byte, chain models.Chain, index uint32, net *chaincfg.Params) (string, error) {
+	return DeriveAccountAddressFromSeed(seed, chain, models.BTCAddressP2WPKH, 0, index, net)
+}
+
+// DeriveAccountAddressFromSeed derives the address at index of a BIP-44 account for
+// chain directly from a BIP-39 seed. btcType selects the BTC address type and is
+// ignored for other chains.
+func DeriveAccountAddressFromSeed(seed []byte, chain models.Chain, btcType models.BTCAddressType, account, index uint32, net *chaincfg.Params) (string, error) {
+	switch chain {
+	case models.ChainBTC:
+		masterKey, err := DeriveMasterKey(seed, net)
+		if err != nil {
+			return "", err
+		}
+		parent, err := DeriveBTCAccountParentKey(masterKey, btcType, account, net)
+		if err != nil {
+			return "", err
+		}
+		return DeriveBTCTypedAddressFromParent(parent, btcType, index, net)
+	case models.ChainBSC:
+		masterKey, err := DeriveMasterKey(seed, net)
+		if err != nil {
+			return "", err
+		}
+		parent, err := DeriveBSCAccountParentKey(masterKey, account)
+		if err != nil {
+			return "", err
+		}
+		return DeriveBSCAddressFromParent(parent, index)
+	case models.ChainSOL:
+		return DeriveSOLAccountAddress(seed, account, index)
+	default:
+		return "", fmt.Errorf("%w: unsupported chain %q", ErrDerivation, chain)
+	}
+}
@@ -0,0 +1,144 @@
+package hd
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+
+	"github.com/Fantasim/hdpay/internal/shared/models"
+)
+
+// SLIP-132 version bytes for BIP-84 (P2WPKH) account-level public keys.
+// Wallets export these as zpub (mainnet) / vpub (testnet) instead of xpub/tpub.
+var (
+	zpubVersion = [4]byte{0x04, 0xb2, 0x47, 0x46}
+	vpubVersion = [4]byte{0x04, 0x5f, 0x1c, 0xf6}
+)
+
+// SLIP-132 version bytes for BIP-49 (P2SH-P2WPKH) account-level public keys: ypub / upub.
+// Taproot and legacy accounts have no SLIP-132 prefix and are exported as xpub/tpub.
+var (
+	ypubVersion = [4]byte{0x04, 0x9d, 0x7c, 0xb2}
+	upubVersion = [4]byte{0x04, 0x4a, 0x52, 0x62}
+)
+
+// accountKeyDepth is the BIP-32 depth of an account-level key (m/purpose'/coin'/account').
+const accountKeyDepth = 3
+
+// ParseBTCAccountXPub parses an account-level extended public key (m/purpose'/coin'/account')
+// for the given BTC address type. xpub/tpub is always accepted; P2WPKH also accepts
+// zpub/vpub and P2SH-P2WPKH ypub/upub. The returned key is normalized to the
+// standard BIP-32 version bytes for the network.
+func ParseBTCAccountXPub(encoded string, addrType models.BTCAddressType, net *chaincfg.Params) (*hdkeychain.ExtendedKey, error) {
+	testnet := net == &chaincfg.TestNet3Params
+	allowed := [][4]byte{net.HDPublicKeyID}
+
+	switch addrType {
+	case models.BTCAddressP2WPKH:
+		if testnet {
+			allowed = append(allowed, vpubVersion)
+		} else {
+			allowed = append(allowed, zpubVersion)
+		}
+	case models.BTCAddressP2SHP2WPKH:
+		if testnet {
+			allowed = append(allowed, upubVersion)
+		} else {
+			allowed = append(allowed, ypubVersion)
+		}
+	case models.BTCAddressP2TR, models.BTCAddressP2PKH:
+	default:
+		return nil, fmt.Errorf("parse BTC account xpub: %w: %q", ErrUnsupportedAddressType, addrType)
+	}
+
+	key, err := parseAccountXPub(encoded, allowed, net.HDPublicKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("parse BTC account xpub: %w", err)
+	}
+
+	slog.Debug("parsed BTC account xpub", "network", net.Name, "addressType", addrType)
+	return key, nil
+}
+
+// ParseBSCAccountXPub parses a BIP-44 account-level extended public key (m/44'/60'/0').
+// EVM wallets always export mainnet xpub version bytes, regardless of the network in use.
+func ParseBSCAccountXPub(encoded string) (*hdkeychain.ExtendedKey, error) {
+	mainnet := chaincfg.MainNetParams.HDPublicKeyID
+	key, err := parseAccountXPub(encoded, [][4]byte{mainnet}, mainnet)
+	if err != nil {
+		return nil, fmt.Errorf("parse BSC account xpub: %w", err)
+	}
+
+	slog.Debug("parsed BSC account xpub")
+	return key, nil
+}
+
+// parseAccountXPub decodes an extended public key, checks its version against the
+// allowed set, and enforces that it is a public, account-level (depth 3, hardened) key.
+func parseAccountXPub(encoded string, allowed [][4]byte, normalized [4]byte) (*hdkeychain.ExtendedKey, error) {
+	key, err := hdkeychain.NewKeyFromString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidXPub, err)
+	}
+
+	if key.IsPrivate() {
+		return nil, fmt.Errorf("%w: extended private key given, expected public key", ErrInvalidXPub)
+	}
+
+	versionOK := false
+	for _, v := range allowed {
+		if bytes.Equal(key.Version(), v[:]) {
+			versionOK = true
+			break
+		}
+	}
+	if !versionOK {
+		return nil, fmt.Errorf("%w: unexpected version bytes %x for this chain/network", ErrInvalidXPub, key.Version())
+	}
+
+	if key.Depth() != accountKeyDepth {
+		return nil, fmt.Errorf("%w: expected account-level key (depth %d), got depth %d", ErrInvalidXPub, accountKeyDepth, key.Depth())
+	}
+
+	if key.ChildIndex() < hdkeychain.HardenedKeyStart {
+		return nil, fmt.Errorf("%w: account key must be a hardened child", ErrInvalidXPub)
+	}
+
+	normalizedKey, err := key.CloneWithVersion(normalized[:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: normalize version: %s", ErrInvalidXPub, err)
+	}
+
+	return normalizedKey, nil
+}
+
+// DeriveExternalParentFromAccount derives the external chain key (account/0) from an
+// account-level key. Works with both private and public (watch-only) account keys.
+func DeriveExternalParentFromAccount(accountKey *hdkeychain.ExtendedKey) (*hdkeychain.ExtendedKey, error) {
+	return deriveBranchFromAccount(accountKey, 0, "external")
+}
+
+// DeriveInternalParentFromAccount derives the internal (change) chain key (account/1)
+// from an account-level key. Payouts send their change there, and a restored
+// wallet may have funds on it. Works with both private and public account keys.
+func DeriveInternalParentFromAccount(accountKey *hdkeychain.ExtendedKey) (*hdkeychain.ExtendedKey, error) {
+	return deriveBranchFromAccount(accountKey, 1, "internal")
+}
+
+func deriveBranchFromAccount(accountKey *hdkeychain.ExtendedKey, branch uint32, name string) (*hdkeychain.ExtendedKey, error) {
+	change, err := accountKey.Derive(branch)
+	if err != nil {
+		return nil, fmt.Errorf("derive %s chain key: %w", name, err)
+	}
+
+	// Force lazy pubkey computation so concurrent Derive() calls don't race.
+	if _, err := change.ECPubKey(); err != nil {
+		return nil, fmt.Errorf("warm %s chain pubkey cache: %w", name, err)
+	}
+
+	return change, nil
+}
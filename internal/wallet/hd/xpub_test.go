@@ -0,0 +1,226 @@
+package hd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+
+	"github.com/Fantasim/hdpay/internal/shared/models"
+)
+
+// BIP-84 reference account zpub for the 12-word "abandon ... about" mnemonic.
+const testZpub12 = "zpub6rFR7y4Q2AijBEqTUquhVz398htDFrtymD9xYYfG1m4wAcvPhXNfE3EfH1r1ADqtfSdVCToUG868RvUUkgDKf31mGDtKsAYz2oz2AGutZYs"
+
+// accountXPub derives m/purpose'/coin'/0' from the mnemonic and returns it neutered.
+func accountXPub(t *testing.T, mnemonic string, net *chaincfg.Params, purpose, coin uint32) *hdkeychain.ExtendedKey {
+	t.Helper()
+
+	seed, err := MnemonicToSeed(mnemonic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := DeriveMasterKey(seed, net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, idx := range []uint32{purpose, coin, 0} {
+		key, err = key.Derive(hdkeychain.HardenedKeyStart + idx)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	pub, err := key.Neuter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pub
+}
+
+func TestParseBTCAccountXPubKnownVector(t *testing.T) {
+	account, err := ParseBTCAccountXPub(testZpub12, models.BTCAddressP2WPKH, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("ParseBTCAccountXPub() error = %v", err)
+	}
+
+	parent, err := DeriveExternalParentFromAccount(account)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := DeriveBTCAddressFromParent(parent, 0, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu"
+	if got != want {
+		t.Errorf("zpub index 0 = %v, want %v", got, want)
+	}
+}
+
+func TestWatchOnlyBTCMatchesMnemonic(t *testing.T) {
+	tests := []struct {
+		name    string
+		net     *chaincfg.Params
+		coin    uint32
+		version [4]byte
+	}{
+		{"mainnet_xpub", &chaincfg.MainNetParams, 0, chaincfg.MainNetParams.HDPublicKeyID},
+		{"mainnet_zpub", &chaincfg.MainNetParams, 0, zpubVersion},
+		{"testnet_tpub", &chaincfg.TestNet3Params, 1, chaincfg.TestNet3Params.HDPublicKeyID},
+		{"testnet_vpub", &chaincfg.TestNet3Params, 1, vpubVersion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pub := accountXPub(t, testMnemonic24, tt.net, 84, tt.coin)
+			pub, err := pub.CloneWithVersion(tt.version[:])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			account, err := ParseBTCAccountXPub(pub.String(), models.BTCAddressP2WPKH, tt.net)
+			if err != nil {
+				t.Fatalf("ParseBTCAccountXPub() error = %v", err)
+			}
+			parent, err := DeriveExternalParentFromAccount(account)
+			if err != nil {
+				t.Fatal(err)
+			}
+			watchOnly, err := GenerateBTCAddressesFromParent(parent, models.BTCAddressP2WPKH, 5, tt.net, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			seed, _ := MnemonicToSeed(testMnemonic24)
+			master, _ := DeriveMasterKey(seed, tt.net)
+			full, err := GenerateBTCAddresses(master, 5, tt.net, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i := range full {
+				if watchOnly[i].Address != full[i].Address {
+					t.Errorf("index %d: watch-only = %s, mnemonic = %s", i, watchOnly[i].Address, full[i].Address)
+				}
+			}
+		})
+	}
+}
+
+func TestWatchOnlyBSCMatchesMnemonic(t *testing.T) {
+	pub := accountXPub(t, testMnemonic24, &chaincfg.MainNetParams, 44, 60)
+
+	account, err := ParseBSCAccountXPub(pub.String())
+	if err != nil {
+		t.Fatalf("ParseBSCAccountXPub() error = %v", err)
+	}
+	parent, err := DeriveExternalParentFromAccount(account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	watchOnly, err := GenerateBSCAddressesFromParent(parent, 5, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seed, _ := MnemonicToSeed(testMnemonic24)
+	master, _ := DeriveMasterKey(seed, &chaincfg.MainNetParams)
+	for i := range watchOnly {
+		want, err := DeriveBSCAddress(master, uint32(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if watchOnly[i].Address != want {
+			t.Errorf("index %d: watch-only = %s, mnemonic = %s", i, watchOnly[i].Address, want)
+		}
+	}
+}
+
+func TestParseAccountXPubRejects(t *testing.T) {
+	seed, _ := MnemonicToSeed(testMnemonic24)
+	master, _ := DeriveMasterKey(seed, &chaincfg.MainNetParams)
+	masterPub, _ := master.Neuter()
+
+	accountPriv := master
+	for _, idx := range []uint32{84, 0, 0} {
+		accountPriv, _ = accountPriv.Derive(hdkeychain.HardenedKeyStart + idx)
+	}
+
+	testnetPub := accountXPub(t, testMnemonic24, &chaincfg.TestNet3Params, 84, 1)
+
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{"garbage", "not-an-xpub"},
+		{"private key", accountPriv.String()},
+		{"master depth", masterPub.String()},
+		{"testnet key on mainnet", testnetPub.String()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseBTCAccountXPub(tt.encoded, models.BTCAddressP2WPKH, &chaincfg.MainNetParams)
+			if !errors.Is(err, ErrInvalidXPub) {
+				t.Errorf("ParseBTCAccountXPub() error = %v, want ErrInvalidXPub", err)
+			}
+		})
+	}
+}
+
+func TestWatchOnlyBTCAddressTypes(t *testing.T) {
+	tests := []struct {
+		addrType models.BTCAddressType
+		purpose  uint32
+		version  [4]byte
+	}{
+		{models.BTCAddressP2TR, 86, chaincfg.MainNetParams.HDPublicKeyID},
+		{models.BTCAddressP2SHP2WPKH, 49, ypubVersion},
+		{models.BTCAddressP2PKH, 44, chaincfg.MainNetParams.HDPublicKeyID},
+	}
+
+	seed, _ := MnemonicToSeed(testMnemonic24)
+	master, _ := DeriveMasterKey(seed, &chaincfg.MainNetParams)
+
+	for _, tt := range tests {
+		t.Run(string(tt.addrType), func(t *testing.T) {
+			pub := accountXPub(t, testMnemonic24, &chaincfg.MainNetParams, tt.purpose, 0)
+			pub, err := pub.CloneWithVersion(tt.version[:])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			account, err := ParseBTCAccountXPub(pub.String(), tt.addrType, &chaincfg.MainNetParams)
+			if err != nil {
+				t.Fatalf("ParseBTCAccountXPub() error = %v", err)
+			}
+			parent, err := DeriveExternalParentFromAccount(account)
+			if err != nil {
+				t.Fatal(err)
+			}
+			watchOnly, err := GenerateBTCAddressesFromParent(parent, tt.addrType, 3, &chaincfg.MainNetParams, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			full, err := GenerateBTCAccountAddresses(master, tt.addrType, 0, 3, &chaincfg.MainNetParams, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range full {
+				if watchOnly[i].Address != full[i].Address {
+					t.Errorf("index %d: watch-only = %s, mnemonic = %s", i, watchOnly[i].Address, full[i].Address)
+				}
+			}
+		})
+	}
+}
+
+func TestParseBTCAccountXPubRejectsOtherSLIP132Prefix(t *testing.T) {
+	// A zpub announces a BIP-84 account; it must not be used for a Taproot wallet.
+	if _, err := ParseBTCAccountXPub(testZpub12, models.BTCAddressP2TR, &chaincfg.MainNetParams); !errors.Is(err, ErrInvalidXPub) {
+		t.Errorf("ParseBTCAccountXPub(zpub, p2tr) error = %v, want ErrInvalidXPub", err)
+	}
+}
@@ -0,0 +1,497 @@
+package tx
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/wallet/db"
+)
+
+// bscNetwork maps the service's chain ID back to the network name.
+func (s *BSCConsolidationService) bscNetwork() string {
+	if s.chainID.Int64() == config.BSCTestnetChainID {
+		return string(models.NetworkTestnet)
+	}
+	return string(models.NetworkMainnet)
+}
+
+// ExportNativeBundle builds an unsigned BNB sweep bundle: for every address whose
+// live balance covers the gas, a transfer of balance minus gas at its pending
+// nonce. Nothing is signed or broadcast; see SignSweepBundle and BroadcastBundle.
+func (s *BSCConsolidationService) ExportNativeBundle(ctx context.Context, addresses []models.AddressWithBalance, destAddr string) (*models.SweepBundle, error) {
+	slog.Info("BSC native bundle export",
+		"addressCount", len(addresses),
+		"destAddress", destAddr,
+	)
+
+	dest := common.HexToAddress(destAddr)
+	gasPrice, err := s.EstimateGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	gasCostPerTx := new(big.Int).Mul(gasPrice, big.NewInt(int64(config.BSCGasLimitTransfer)))
+
+	bundle := newSweepBundle(models.ChainBSC, models.TokenNative, s.bscNetwork(), s.keyService.Account(), dest.Hex(), "")
+	bundle.ChainID = s.chainID.String()
+
+	for _, addr := range addresses {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		fromAddr := common.HexToAddress(addr.Address)
+		balance, err := s.ethClient.BalanceAt(ctx, fromAddr, nil)
+		if err != nil {
+			return nil, fmt.Errorf("get balance for %s: %w", addr.Address, err)
+		}
+
+		sendAmount := new(big.Int).Sub(balance, gasCostPerTx)
+		if balance.Cmp(bscMinNativeSweepWei) < 0 || sendAmount.Sign() <= 0 {
+			slog.Warn("BSC bundle: skipping address with balance too low to sweep",
+				"address", addr.Address,
+				"balance", balance.String(),
+				"gasCost", gasCostPerTx.String(),
+			)
+			continue
+		}
+
+		nonce, err := s.ethClient.PendingNonceAt(ctx, fromAddr)
+		if err != nil {
+			return nil, fmt.Errorf("get nonce for %s: %w", addr.Address, err)
+		}
+
+		unsigned := BuildBSCNativeTransfer(nonce, dest, sendAmount, gasPrice)
+		bundle.Transactions = append(bundle.Transactions, bscBundleEntry(addr, sendAmount, unsigned))
+	}
+
+	if len(bundle.Transactions) == 0 {
+		return nil, config.ErrBundleEmpty
+	}
+
+	slog.Info("BSC native bundle exported",
+		"txCount", len(bundle.Transactions),
+		"gasPrice", gasPrice.String(),
+	)
+	return bundle, nil
+}
+
+// ExportTokenBundle builds an unsigned BEP-20 sweep bundle. Amounts are the lower
+// of the stored and on-chain token balance; addresses without enough BNB for gas
+// are skipped and need a gas pre-seed first.
+func (s *BSCConsolidationService) ExportTokenBundle(
+	ctx context.Context,
+	addresses []models.AddressWithBalance,
+	destAddr string,
+	token models.Token,
+	contractAddr string,
+) (*models.SweepBundle, error) {
+	slog.Info("BSC token bundle export",
+		"addressCount", len(addresses),
+		"destAddress", destAddr,
+		"token", token,
+		"contract", contractAddr,
+	)
+
+	dest := common.HexToAddress(destAddr)
+	contract := common.HexToAddress(contractAddr)
+	gasPrice, err := s.EstimateGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	gasCostPerTx := new(big.Int).Mul(gasPrice, big.NewInt(int64(config.BSCGasLimitBEP20)))
+
+	bundle := newSweepBundle(models.ChainBSC, token, s.bscNetwork(), s.keyService.Account(), dest.Hex(), contract.Hex())
+	bundle.ChainID = s.chainID.String()
+
+	for _, addr := range addresses {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var tokenBalance *big.Int
+		for _, tb := range addr.TokenBalances {
+			if tb.Symbol == token {
+				tokenBalance, _ = new(big.Int).SetString(tb.Balance, 10)
+				break
+			}
+		}
+		if tokenBalance == nil || tokenBalance.Sign() <= 0 {
+			continue
+		}
+
+		fromAddr := common.HexToAddress(addr.Address)
+		onChain, err := BalanceOfBEP20(ctx, s.ethClient, contract, fromAddr)
+		if err != nil {
+			slog.Warn("BSC bundle: failed to fetch on-chain token balance, using DB value",
+				"address", addr.Address,
+				"token", token,
+				"error", err,
+			)
+		} else if onChain.Cmp(tokenBalance) < 0 {
+			tokenBalance = onChain
+		}
+		if tokenBalance.Sign() <= 0 {
+			continue
+		}
+
+		bnbBalance, err := s.ethClient.BalanceAt(ctx, fromAddr, nil)
+		if err != nil {
+			return nil, fmt.Errorf("get BNB balance for %s: %w", addr.Address, err)
+		}
+		if bnbBalance.Cmp(gasCostPerTx) < 0 {
+			slog.Warn("BSC bundle: skipping address that needs gas pre-seeding",
+				"address", addr.Address,
+				"bnbBalance", bnbBalance.String(),
+				"gasCost", gasCostPerTx.String(),
+			)
+			continue
+		}
+
+		nonce, err := s.ethClient.PendingNonceAt(ctx, fromAddr)
+		if err != nil {
+			return nil, fmt.Errorf("get nonce for %s: %w", addr.Address, err)
+		}
+
+		unsigned := BuildBSCTokenTransfer(nonce, contract, dest, tokenBalance, gasPrice)
+		bundle.Transactions = append(bundle.Transactions, bscBundleEntry(addr, tokenBalance, unsigned))
+	}
+
+	if len(bundle.Transactions) == 0 {
+		return nil, config.ErrBundleEmpty
+	}
+
+	slog.Info("BSC token bundle exported",
+		"token", token,
+		"txCount", len(bundle.Transactions),
+		"gasPrice", gasPrice.String(),
+	)
+	return bundle, nil
+}
+
+// bscBundleEntry describes an unsigned transfer as a bundle transaction.
+func bscBundleEntry(addr models.AddressWithBalance, amount *big.Int, unsigned *types.Transaction) models.SweepBundleTx {
+	return models.SweepBundleTx{
+		AddressIndex: addr.AddressIndex,
+		FromAddress:  addr.Address,
+		Amount:       amount.String(),
+		Nonce:        unsigned.Nonce(),
+		GasPrice:     unsigned.GasPrice().String(),
+		GasLimit:     unsigned.Gas(),
+		To:           unsigned.To().Hex(),
+		Value:        unsigned.Value().String(),
+		Data:         bscCalldata(unsigned.Data()),
+	}
+}
+
+// bscCalldata encodes calldata as 0x-hex, or "" when there is none.
+func bscCalldata(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	return hexutil.Encode(data)
+}
+
+// bscBundleTx rebuilds the unsigned transfer a bundle transaction describes, from
+// its amount, nonce and gas price and the bundle's destination and contract. The
+// descriptive fields (to, value, data, gas limit) must agree with it, so a signer
+// can never be made to sign anything but a transfer to the destination.
+func bscBundleTx(bundle *models.SweepBundle, entry models.SweepBundleTx) (*types.Transaction, error) {
+	if !common.IsHexAddress(bundle.Destination) {
+		return nil, fmt.Errorf("%w: invalid destination %q", config.ErrInvalidBundle, bundle.Destination)
+	}
+	if !common.IsHexAddress(entry.FromAddress) {
+		return nil, fmt.Errorf("%w: invalid sender %q", config.ErrInvalidBundle, entry.FromAddress)
+	}
+	amount, ok := new(big.Int).SetString(entry.Amount, 10)
+	if !ok || amount.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: invalid amount %q", config.ErrInvalidBundle, entry.Amount)
+	}
+	gasPrice, ok := new(big.Int).SetString(entry.GasPrice, 10)
+	if !ok || gasPrice.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: invalid gas price %q", config.ErrInvalidBundle, entry.GasPrice)
+	}
+
+	dest := common.HexToAddress(bundle.Destination)
+	var unsigned *types.Transaction
+	if bundle.Token == models.TokenNative {
+		unsigned = BuildBSCNativeTransfer(entry.Nonce, dest, amount, gasPrice)
+	} else {
+		if !common.IsHexAddress(bundle.Contract) {
+			return nil, fmt.Errorf("%w: invalid token contract %q", config.ErrInvalidBundle, bundle.Contract)
+		}
+		unsigned = BuildBSCTokenTransfer(entry.Nonce, common.HexToAddress(bundle.Contract), dest, amount, gasPrice)
+	}
+
+	if entry.GasLimit != unsigned.Gas() ||
+		!strings.EqualFold(entry.To, unsigned.To().Hex()) ||
+		entry.Value != unsigned.Value().String() ||
+		!strings.EqualFold(entry.Data, bscCalldata(unsigned.Data())) {
+		return nil, fmt.Errorf("%w: transaction from %s is not a %s transfer of %s to %s",
+			config.ErrInvalidBundle, entry.FromAddress, bundle.Token, entry.Amount, bundle.Destination)
+	}
+	return unsigned, nil
+}
+
+// signBSCBundle signs each transaction with the key of its sender index on the
+// bundle's account.
+func signBSCBundle(bundle *models.SweepBundle, masterKey *hdkeychain.ExtendedKey) (int, error) {
+	chainID := BSCChainID(bundle.Network)
+	if bundle.ChainID != chainID.String() {
+		return 0, fmt.Errorf("%w: chain ID %s does not match %s (%s)", config.ErrInvalidBundle, bundle.ChainID, bundle.Network, chainID)
+	}
+
+	signed := 0
+	for i := range bundle.Transactions {
+		entry := &bundle.Transactions[i]
+		unsigned, err := bscBundleTx(bundle, *entry)
+		if err != nil {
+			return signed, fmt.Errorf("transaction %d: %w", i, err)
+		}
+
+		privKey, derivedAddr, err := deriveBSCPrivKeyAtIndex(masterKey, bundle.Account, uint32(entry.AddressIndex))
+		if err != nil {
+			return signed, fmt.Errorf("%w: BSC index %d: %s", config.ErrKeyDerivation, entry.AddressIndex, err)
+		}
+		if derivedAddr != common.HexToAddress(entry.FromAddress) {
+			ZeroECDSAKey(privKey)
+			return signed, fmt.Errorf("%w: BSC index %d derives %s, bundle spends from %s",
+				config.ErrKeyMismatch, entry.AddressIndex, derivedAddr.Hex(), entry.FromAddress)
+		}
+
+		signedTx, err := SignBSCTx(unsigned, chainID, privKey)
+		ZeroECDSAKey(privKey)
+		if err != nil {
+			return signed, fmt.Errorf("transaction %d: %w", i, err)
+		}
+
+		raw, err := signedTx.MarshalBinary()
+		if err != nil {
+			return signed, fmt.Errorf("encode transaction %d: %w", i, err)
+		}
+		entry.SignedTx = hexutil.Encode(raw)
+		signed++
+	}
+
+	slog.Info("BSC bundle signed", "signedCount", signed, "token", bundle.Token)
+	return signed, nil
+}
+
+// bscSignedBundleTx decodes a signed bundle transaction and checks that it is the
+// exported transfer, signed by its sender for chainID.
+func bscSignedBundleTx(bundle *models.SweepBundle, entry models.SweepBundleTx, chainID *big.Int) (*types.Transaction, error) {
+	if entry.SignedTx == "" {
+		return nil, fmt.Errorf("%w: transaction from %s has no signature", config.ErrBundleUnsigned, entry.FromAddress)
+	}
+	raw, err := hexutil.Decode(entry.SignedTx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: decode signed transaction: %s", config.ErrInvalidBundle, err)
+	}
+	signedTx := new(types.Transaction)
+	if err := signedTx.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("%w: decode signed transaction: %s", config.ErrInvalidBundle, err)
+	}
+	if signedTx.Type() != types.LegacyTxType {
+		return nil, fmt.Errorf("%w: signed transaction has type %d, expected legacy", config.ErrInvalidBundle, signedTx.Type())
+	}
+
+	unsigned, err := bscBundleTx(bundle, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	signer := types.NewEIP155Signer(chainID)
+	if signer.Hash(signedTx) != signer.Hash(unsigned) {
+		return nil, fmt.Errorf("%w: signed transaction from %s differs from the exported one", config.ErrInvalidBundle, entry.FromAddress)
+	}
+	sender, err := types.Sender(signer, signedTx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: recover sender: %s", config.ErrInvalidBundle, err)
+	}
+	if sender != common.HexToAddress(entry.FromAddress) {
+		return nil, fmt.Errorf("%w: transaction from %s is signed by %s", config.ErrInvalidBundle, entry.FromAddress, sender.Hex())
+	}
+	return signedTx, nil
+}
+
+// ValidateBundle checks a signed BSC bundle before it is broadcast: it must be
+// for this chain ID, every transaction must be the exported transfer signed by
+// its sender, and every sender must be a stored address.
+func (s *BSCConsolidationService) ValidateBundle(bundle *models.SweepBundle) error {
+	if bundle.Chain != models.ChainBSC {
+		return fmt.Errorf("%w: not a BSC bundle", config.ErrInvalidBundle)
+	}
+	if bundle.ChainID != s.chainID.String() {
+		return fmt.Errorf("%w: bundle is for chain ID %s, this server uses %s", config.ErrInvalidBundle, bundle.ChainID, s.chainID)
+	}
+
+	for i, entry := range bundle.Transactions {
+		if _, err := bscSignedBundleTx(bundle, entry, s.chainID); err != nil {
+			return fmt.Errorf("transaction %d: %w", i, err)
+		}
+		if err := checkBundleAddress(s.database, models.ChainBSC, entry); err != nil {
+			return fmt.Errorf("transaction %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// BroadcastBundle sends the transactions of a validated, signed BSC bundle in
+// order. Each one is tracked in tx_state under sweepID, recorded in transactions
+// and reported over SSE like ExecuteNativeSweep; receipts are polled in the
+// background.
+func (s *BSCConsolidationService) BroadcastBundle(ctx context.Context, bundle *models.SweepBundle, sweepID string) (*models.BSCSendResult, error) {
+	slog.Info("BSC bundle broadcast",
+		"txCount", len(bundle.Transactions),
+		"token", bundle.Token,
+		"sweepID", sweepID,
+	)
+	start := time.Now()
+
+	result := &models.BSCSendResult{
+		Chain: models.ChainBSC,
+		Token: bundle.Token,
+	}
+	totalSwept := new(big.Int)
+
+	for i, entry := range bundle.Transactions {
+		if err := ctx.Err(); err != nil {
+			slog.Warn("BSC bundle broadcast cancelled", "error", err)
+			break
+		}
+
+		txResult := s.broadcastBundleTx(ctx, bundle, entry, sweepID)
+		result.TxResults = append(result.TxResults, txResult)
+
+		if txResult.Status == "success" {
+			result.SuccessCount++
+			amount, _ := new(big.Int).SetString(txResult.Amount, 10)
+			if amount != nil {
+				totalSwept.Add(totalSwept, amount)
+			}
+		} else {
+			result.FailCount++
+		}
+
+		if s.txHub != nil {
+			s.txHub.Broadcast(TxEvent{
+				Type: "tx_status",
+				Data: TxStatusData{
+					Chain:        string(models.ChainBSC),
+					Token:        string(bundle.Token),
+					AddressIndex: txResult.AddressIndex,
+					FromAddress:  txResult.FromAddress,
+					TxHash:       txResult.TxHash,
+					Status:       txResult.Status,
+					Amount:       txResult.Amount,
+					Error:        txResult.Error,
+					Current:      i + 1,
+					Total:        len(bundle.Transactions),
+				},
+			})
+		}
+	}
+
+	result.TotalSwept = totalSwept.String()
+
+	slog.Info("BSC bundle broadcast complete",
+		"successCount", result.SuccessCount,
+		"failCount", result.FailCount,
+		"totalSwept", result.TotalSwept,
+		"duration", time.Since(start).Round(time.Millisecond),
+	)
+
+	return result, nil
+}
+
+// broadcastBundleTx sends one signed bundle transaction.
+func (s *BSCConsolidationService) broadcastBundleTx(ctx context.Context, bundle *models.SweepBundle, entry models.SweepBundleTx, sweepID string) models.BSCTxResult {
+	txResult := models.BSCTxResult{
+		AddressIndex: entry.AddressIndex,
+		FromAddress:  entry.FromAddress,
+	}
+
+	txStateID := GenerateTxStateID()
+	s.createTxState(db.TxStateRow{
+		ID:           txStateID,
+		SweepID:      sweepID,
+		Chain:        string(models.ChainBSC),
+		Token:        string(bundle.Token),
+		AddressIndex: entry.AddressIndex,
+		FromAddress:  entry.FromAddress,
+		ToAddress:    bundle.Destination,
+		Amount:       entry.Amount,
+		Status:       config.TxStatePending,
+	})
+
+	signedTx, err := bscSignedBundleTx(bundle, entry, s.chainID)
+	if err != nil {
+		txResult.Status = "failed"
+		txResult.Error = err.Error()
+		slog.Error("BSC bundle: invalid transaction", "address", entry.FromAddress, "error", err)
+		s.updateTxState(txStateID, config.TxStateFailed, "", txResult.Error)
+		return txResult
+	}
+
+	slog.Info("BSC bundle: broadcasting transfer",
+		"from", entry.FromAddress,
+		"to", bundle.Destination,
+		"token", bundle.Token,
+		"amount", entry.Amount,
+		"nonce", signedTx.Nonce(),
+		"txStateID", txStateID,
+	)
+
+	s.updateTxState(txStateID, config.TxStateBroadcasting, "", "")
+
+	if err := s.ethClient.SendTransaction(ctx, signedTx); err != nil {
+		txResult.Status = "failed"
+		txResult.Error = fmt.Sprintf("broadcast: %s", err)
+		slog.Error("BSC bundle: broadcast failed", "address", entry.FromAddress, "error", err)
+		s.updateTxState(txStateID, config.TxStateFailed, "", txResult.Error)
+		return txResult
+	}
+
+	txHash := signedTx.Hash()
+	txResult.TxHash = txHash.Hex()
+	txResult.Amount = entry.Amount
+
+	s.updateTxState(txStateID, config.TxStateConfirming, txHash.Hex(), "")
+
+	addr := models.AddressWithBalance{
+		Chain:        models.ChainBSC,
+		AddressIndex: entry.AddressIndex,
+		Address:      entry.FromAddress,
+	}
+	if s.database != nil {
+		s.recordBSCTransaction(addr, txHash.Hex(), entry.Amount, bundle.Destination, bundle.Token, "pending")
+	}
+	txResult.Status = "success"
+
+	go func() {
+		bgCtx, cancel := context.WithTimeout(context.Background(), config.BSCReceiptPollTimeout)
+		defer cancel()
+
+		receipt, err := WaitForReceipt(bgCtx, s.ethClient, txHash)
+		if err != nil {
+			slog.Error("BSC bundle: receipt failed", "txHash", txHash.Hex(), "error", err)
+			s.updateTxState(txStateID, config.TxStateFailed, txHash.Hex(), fmt.Sprintf("receipt: %s", err))
+			return
+		}
+
+		slog.Info("BSC bundle: transfer confirmed", "txHash", txHash.Hex(), "block", receipt.BlockNumber)
+		s.updateTxState(txStateID, config.TxStateConfirmed, txHash.Hex(), "")
+	}()
+
+	return txResult
+}
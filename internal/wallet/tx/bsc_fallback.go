@@ -74,6 +74,16 @@ func (f *FallbackEthClient) SuggestGasPrice(ctx context.Context) (*big.Int, erro
 	return f.primary.SuggestGasPrice(ctx)
 }
 
+// SuggestGasTipCap delegates to the primary client.
+func (f *FallbackEthClient) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return f.primary.SuggestGasTipCap(ctx)
+}
+
+// FeeHistory delegates to the primary client.
+func (f *FallbackEthClient) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	return f.primary.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+}
+
 // TransactionReceipt delegates to the primary client.
 func (f *FallbackEthClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
 	return f.primary.TransactionReceipt(ctx, txHash)
@@ -37,6 +37,14 @@ func (m *mockBroadcastClient) SuggestGasPrice(_ context.Context) (*big.Int, erro
 	return nil, m.gasPriceErr
 }
 
+func (m *mockBroadcastClient) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return m.SuggestGasPrice(ctx)
+}
+
+func (m *mockBroadcastClient) FeeHistory(_ context.Context, _ uint64, _ *big.Int, _ []float64) (*ethereum.FeeHistory, error) {
+	return nil, errors.New("not implemented")
+}
+
 func (m *mockBroadcastClient) TransactionReceipt(_ context.Context, _ common.Hash) (*types.Receipt, error) {
 	return nil, ethereum.NotFound
 }
@@ -0,0 +1,109 @@
+package tx
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+)
+
+// BSCFee is the per-gas price a BSC transaction is built with. A legacy fee has
+// only GasPrice. A dynamic fee (EIP-1559, type-2) also carries the base fee and
+// priority tip it was derived from, and GasPrice is its max fee per gas: the
+// most the transaction can pay, so gas costs computed from it are upper bounds.
+type BSCFee struct {
+	GasPrice *big.Int
+	BaseFee  *big.Int // dynamic only
+	TipCap   *big.Int // dynamic only
+}
+
+// Dynamic reports whether the fee prices a type-2 transaction.
+func (f BSCFee) Dynamic() bool {
+	return f.TipCap != nil
+}
+
+// GasCost returns the most a transaction with the given gas limit pays.
+func (f BSCFee) GasCost(gasLimit uint64) *big.Int {
+	return new(big.Int).Mul(f.GasPrice, new(big.Int).SetUint64(gasLimit))
+}
+
+// buildNativeTransfer builds an unsigned native BNB transfer priced by the fee.
+func (f BSCFee) buildNativeTransfer(chainID *big.Int, nonce uint64, to common.Address, amount *big.Int) *types.Transaction {
+	if f.Dynamic() {
+		return BuildBSCDynamicNativeTransfer(chainID, nonce, to, amount, f.TipCap, f.GasPrice)
+	}
+	return BuildBSCNativeTransfer(nonce, to, amount, f.GasPrice)
+}
+
+// buildTokenTransfer builds an unsigned BEP-20 token transfer priced by the fee.
+func (f BSCFee) buildTokenTransfer(chainID *big.Int, nonce uint64, contractAddr, recipient common.Address, amount *big.Int) *types.Transaction {
+	if f.Dynamic() {
+		return BuildBSCDynamicTokenTransfer(chainID, nonce, contractAddr, recipient, amount, f.TipCap, f.GasPrice)
+	}
+	return BuildBSCTokenTransfer(nonce, contractAddr, recipient, amount, f.GasPrice)
+}
+
+// EstimateBSCFee prices the next BSC transactions. The legacy fee is the suggested
+// gas price with the 20% buffer. The dynamic fee takes the pending block's base fee
+// from eth_feeHistory and the tip from eth_maxPriorityFeePerGas with the same
+// buffer, and caps the max fee at BSCBaseFeeMultiplier × base fee + tip so the
+// transaction stays includable while the base fee rises.
+func EstimateBSCFee(ctx context.Context, client EthClientWrapper, dynamic bool) (BSCFee, error) {
+	if !dynamic {
+		suggested, err := client.SuggestGasPrice(ctx)
+		if err != nil {
+			return BSCFee{}, fmt.Errorf("suggest gas price: %w", err)
+		}
+		fee := BSCFee{GasPrice: BufferedGasPrice(suggested)}
+		slog.Debug("BSC gas price estimated",
+			"suggested", suggested.String(),
+			"buffered", fee.GasPrice.String(),
+		)
+		return fee, nil
+	}
+
+	history, err := client.FeeHistory(ctx, 1, nil, nil)
+	if err != nil {
+		return BSCFee{}, fmt.Errorf("fee history: %w", err)
+	}
+	// BaseFee holds one entry per block plus the one after the newest.
+	if history == nil || len(history.BaseFee) == 0 || history.BaseFee[len(history.BaseFee)-1] == nil {
+		return BSCFee{}, fmt.Errorf("fee history: no base fee returned")
+	}
+	baseFee := new(big.Int).Set(history.BaseFee[len(history.BaseFee)-1])
+
+	suggestedTip, err := client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return BSCFee{}, fmt.Errorf("suggest priority fee: %w", err)
+	}
+	tipCap := BufferedGasPrice(suggestedTip)
+
+	maxFee := new(big.Int).Mul(baseFee, big.NewInt(config.BSCBaseFeeMultiplier))
+	maxFee.Add(maxFee, tipCap)
+
+	slog.Debug("BSC dynamic fee estimated",
+		"baseFee", baseFee.String(),
+		"suggestedTip", suggestedTip.String(),
+		"tipCap", tipCap.String(),
+		"maxFee", maxFee.String(),
+	)
+	return BSCFee{GasPrice: maxFee, BaseFee: baseFee, TipCap: tipCap}, nil
+}
+
+// SetDynamicFees makes sweeps build dynamic-fee (type-2) transactions priced from
+// the base fee and priority tip instead of legacy gas-price transactions.
+// Offline bundles stay legacy. Must be called before the service is shared.
+func (s *BSCConsolidationService) SetDynamicFees(enabled bool) {
+	s.dynamicFees = enabled
+}
+
+// SetDynamicFees makes gas pre-seeding build dynamic-fee (type-2) transactions.
+// Must be called before the service is shared.
+func (s *GasPreSeedService) SetDynamicFees(enabled bool) {
+	s.dynamicFees = enabled
+}
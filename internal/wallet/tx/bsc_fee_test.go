@@ -0,0 +1,121 @@
+package tx
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+)
+
+func TestEstimateBSCFee(t *testing.T) {
+	mock := &mockEthClient{
+		gasPrice: big.NewInt(5_000_000_000),
+		tipCap:   big.NewInt(1_000_000_000),
+		baseFee:  big.NewInt(3_000_000_000),
+	}
+
+	legacy, err := EstimateBSCFee(context.Background(), mock, false)
+	if err != nil {
+		t.Fatalf("EstimateBSCFee(legacy) error = %v", err)
+	}
+	if legacy.Dynamic() || legacy.GasPrice.Cmp(big.NewInt(6_000_000_000)) != 0 {
+		t.Errorf("legacy fee = %+v, want a 6 gwei gas price", legacy)
+	}
+
+	dynamic, err := EstimateBSCFee(context.Background(), mock, true)
+	if err != nil {
+		t.Fatalf("EstimateBSCFee(dynamic) error = %v", err)
+	}
+	// Max fee = 2 × 3 gwei base fee + 1.2 gwei buffered tip.
+	if !dynamic.Dynamic() ||
+		dynamic.BaseFee.Cmp(big.NewInt(3_000_000_000)) != 0 ||
+		dynamic.TipCap.Cmp(big.NewInt(1_200_000_000)) != 0 ||
+		dynamic.GasPrice.Cmp(big.NewInt(7_200_000_000)) != 0 {
+		t.Errorf("dynamic fee = %+v, want base 3 gwei, tip 1.2 gwei, max 7.2 gwei", dynamic)
+	}
+	if got := dynamic.GasCost(config.BSCGasLimitTransfer); got.Cmp(big.NewInt(7_200_000_000*config.BSCGasLimitTransfer)) != 0 {
+		t.Errorf("GasCost() = %s, want max fee × gas limit", got)
+	}
+
+	mock.feeHistoryErr = errors.New("method not found")
+	if _, err := EstimateBSCFee(context.Background(), mock, true); err == nil {
+		t.Error("EstimateBSCFee(dynamic) without fee history: expected error")
+	}
+}
+
+func TestBuildBSCDynamicTransfers(t *testing.T) {
+	chainID := big.NewInt(config.BSCTestnetChainID)
+	to := common.HexToAddress("0xaaaa000000000000000000000000000000000001")
+	contract := common.HexToAddress(config.BSCTestnetUSDCContract)
+	tipCap := big.NewInt(1_000_000_000)
+	feeCap := big.NewInt(3_000_000_000)
+
+	native := BuildBSCDynamicNativeTransfer(chainID, 7, to, big.NewInt(1000), tipCap, feeCap)
+	if native.Type() != types.DynamicFeeTxType || native.Gas() != config.BSCGasLimitTransfer ||
+		native.GasTipCap().Cmp(tipCap) != 0 || native.GasFeeCap().Cmp(feeCap) != 0 || native.Nonce() != 7 {
+		t.Errorf("native transfer: type %d, gas %d, tip %s, cap %s", native.Type(), native.Gas(), native.GasTipCap(), native.GasFeeCap())
+	}
+
+	token := BuildBSCDynamicTokenTransfer(chainID, 8, contract, to, big.NewInt(1000), tipCap, feeCap)
+	if token.Type() != types.DynamicFeeTxType || *token.To() != contract || token.Value().Sign() != 0 ||
+		string(token.Data()) != string(EncodeBEP20Transfer(to, big.NewInt(1000))) {
+		t.Errorf("token transfer: type %d, to %s, value %s", token.Type(), token.To().Hex(), token.Value())
+	}
+
+	privKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := SignBSCTx(native, chainID, privKey)
+	if err != nil {
+		t.Fatalf("SignBSCTx(type-2) error = %v", err)
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+	if err != nil {
+		t.Fatalf("recover sender: %v", err)
+	}
+	if sender != crypto.PubkeyToAddress(privKey.PublicKey) || signed.ChainId().Cmp(chainID) != 0 {
+		t.Errorf("signed type-2 transfer: sender %s, chain %s", sender.Hex(), signed.ChainId())
+	}
+}
+
+func TestGasPreSeed_Execute_DynamicFee(t *testing.T) {
+	ks := NewKeyService(writeTempMnemonic(t, testMnemonic24), "testnet")
+	mock := &mockEthClientDynamic{
+		nonceValues: []uint64{0},
+		gasPrice:    big.NewInt(1_000_000_000),
+		balance:     big.NewInt(1_000_000_000_000_000_000), // 1 BNB.
+		receipt: &types.Receipt{
+			Status:      types.ReceiptStatusSuccessful,
+			BlockNumber: big.NewInt(100),
+		},
+	}
+
+	svc := NewGasPreSeedService(ks, mock, setupGasTestDB(t), big.NewInt(config.BSCTestnetChainID))
+	svc.SetDynamicFees(true)
+
+	preview, err := svc.Preview(context.Background(), 0, []string{"0xaaaa000000000000000000000000000000000001"})
+	if err != nil {
+		t.Fatalf("Preview() error = %v", err)
+	}
+	if preview.BaseFee != "0" || preview.PriorityFee != "1200000000" || preview.GasPrice != "1200000000" {
+		t.Errorf("preview fees = base %q, tip %q, max %q", preview.BaseFee, preview.PriorityFee, preview.GasPrice)
+	}
+
+	result, err := svc.Execute(context.Background(), 0, []string{"0xaaaa000000000000000000000000000000000001"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.SuccessCount != 1 || len(mock.sentTxs) != 1 {
+		t.Fatalf("Execute() = %+v after %d sends, want 1 success", result, len(mock.sentTxs))
+	}
+	if sent := mock.sentTxs[0]; sent.Type() != types.DynamicFeeTxType || sent.GasTipCap().Cmp(big.NewInt(1_200_000_000)) != 0 {
+		t.Errorf("sent type %d with tip %s, want a type-2 transaction", sent.Type(), sent.GasTipCap())
+	}
+}
@@ -0,0 +1,134 @@
+package tx
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/wallet/db"
+)
+
+// bscNonceManager hands out account nonces for BSC source addresses. Every
+// address has its own nonce sequence guarded by its own lock, so parallel sweep
+// workers only wait on each other when they send from the same address.
+type bscNonceManager struct {
+	client   EthClientWrapper
+	database *db.DB
+
+	mu    sync.Mutex
+	addrs map[common.Address]*bscAddressNonce
+}
+
+// bscAddressNonce is the local nonce state of one source address.
+type bscAddressNonce struct {
+	mu    sync.Mutex
+	next  uint64 // next nonce to hand out, valid once known is set
+	known bool
+}
+
+// newBSCNonceManager creates a nonce manager. database may be nil, in which case
+// nonces are reconciled with the node only.
+func newBSCNonceManager(client EthClientWrapper, database *db.DB) *bscNonceManager {
+	return &bscNonceManager{
+		client:   client,
+		database: database,
+		addrs:    make(map[common.Address]*bscAddressNonce),
+	}
+}
+
+// address returns the nonce state of an address, creating it on first use.
+func (m *bscNonceManager) address(from common.Address) *bscAddressNonce {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	a, ok := m.addrs[from]
+	if !ok {
+		a = &bscAddressNonce{}
+		m.addrs[from] = a
+	}
+	return a
+}
+
+// Next reserves the next nonce of an address. It starts from the node's pending
+// nonce and moves past nonces this process already handed out, and past nonces of
+// transfers tx_state records as broadcast that a lagging node does not count yet.
+func (m *bscNonceManager) Next(ctx context.Context, from common.Address) (uint64, error) {
+	a := m.address(from)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	pending, err := m.client.PendingNonceAt(ctx, from)
+	if err != nil {
+		return 0, fmt.Errorf("get pending nonce for %s: %w", from.Hex(), err)
+	}
+
+	nonce := pending
+	if a.known && a.next > nonce {
+		nonce = a.next
+	}
+	nonce = m.skipRecorded(from, nonce)
+
+	if nonce != pending {
+		slog.Debug("BSC nonce ahead of node pending nonce",
+			"address", from.Hex(),
+			"pendingNonce", pending,
+			"nonce", nonce,
+		)
+	}
+
+	a.next, a.known = nonce+1, true
+	return nonce, nil
+}
+
+// Release hands back a nonce reserved by Next whose transaction never reached the
+// network, so that the next transfer from the address reuses it. Only the latest
+// reservation is taken back; releasing an older one would strand later nonces
+// behind a gap.
+func (m *bscNonceManager) Release(from common.Address, nonce uint64) {
+	a := m.address(from)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.known && a.next == nonce+1 {
+		a.next = nonce
+		slog.Debug("BSC nonce released", "address", from.Hex(), "nonce", nonce)
+	}
+}
+
+// skipRecorded returns the first nonce from nonce on that no broadcasting or
+// confirming tx_state row of the address holds. Nonce 0 is never skipped: rows
+// written before nonces were recorded hold 0, and skipping a nonce that was never
+// used would leave every later transfer stuck behind the gap.
+func (m *bscNonceManager) skipRecorded(from common.Address, nonce uint64) uint64 {
+	if m.database == nil {
+		return nonce
+	}
+
+	for nonce > 0 {
+		row, err := m.database.GetTxStateByNonce(string(models.ChainBSC), from.Hex(), int64(nonce))
+		if err != nil {
+			slog.Warn("BSC nonce: tx_state lookup failed, trusting node nonce",
+				"address", from.Hex(),
+				"nonce", nonce,
+				"error", err,
+			)
+			return nonce
+		}
+		if row == nil || (row.Status != config.TxStateBroadcasting && row.Status != config.TxStateConfirming) {
+			return nonce
+		}
+
+		slog.Debug("BSC nonce in flight in tx_state, skipping",
+			"address", from.Hex(),
+			"nonce", nonce,
+			"txStateID", row.ID,
+		)
+		nonce++
+	}
+	return nonce
+}
@@ -0,0 +1,70 @@
+package tx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/wallet/db"
+)
+
+func TestBSCNonceManager(t *testing.T) {
+	database := setupGasTestDB(t)
+	mock := &mockEthClient{pendingNonce: 5}
+	m := newBSCNonceManager(mock, database)
+	from := common.HexToAddress("0xaaaa000000000000000000000000000000000001")
+	ctx := context.Background()
+
+	next := func(want uint64) {
+		t.Helper()
+		got, err := m.Next(ctx, from)
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		if got != want {
+			t.Errorf("Next() = %d, want %d", got, want)
+		}
+	}
+
+	// A transfer at the node's pending nonce is still in flight in tx_state, so
+	// the lagging node nonce is skipped; a failed one is not.
+	for _, row := range []db.TxStateRow{
+		{ID: "in-flight", Nonce: 5, Status: config.TxStateConfirming},
+		{ID: "dropped", Nonce: 6, Status: config.TxStateFailed},
+	} {
+		row.SweepID, row.Chain, row.Token = "sweep-nonce", "BSC", "NATIVE"
+		row.FromAddress, row.ToAddress, row.Amount = from.Hex(), testBundleBSCDest, "1"
+		if err := database.CreateTxState(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	next(6)
+
+	// Local reservations run ahead of the node.
+	next(7)
+
+	// Only the latest reservation can be handed back.
+	m.Release(from, 6)
+	next(8)
+	m.Release(from, 8)
+	next(8)
+
+	// The node moving ahead wins over local state.
+	mock.pendingNonce = 20
+	next(20)
+
+	// Nonce 0 is never skipped: rows recorded before nonces were tracked hold 0.
+	fresh := common.HexToAddress("0xaaaa000000000000000000000000000000000002")
+	if err := database.CreateTxState(db.TxStateRow{
+		ID: "legacy", SweepID: "sweep-nonce", Chain: "BSC", Token: "NATIVE",
+		FromAddress: fresh.Hex(), ToAddress: testBundleBSCDest, Amount: "1", Status: config.TxStateConfirming,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	mock.pendingNonce = 0
+	if got, err := m.Next(ctx, fresh); err != nil || got != 0 {
+		t.Errorf("Next(fresh) = %d, %v, want 0", got, err)
+	}
+}
@@ -0,0 +1,237 @@
+package tx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+)
+
+// bscReceiptWatch is a broadcast sweep transfer awaiting its receipt.
+type bscReceiptWatch struct {
+	txStateID string
+	txHash    common.Hash
+	deadline  time.Time // marked failed when still unmined by then
+}
+
+// SetSweepWorkers sets how many source addresses a sweep sends from at once.
+// Values below 1 keep the default. Must be called before the service is shared.
+func (s *BSCConsolidationService) SetSweepWorkers(n int) {
+	if n > 0 {
+		s.sweepWorkers = n
+	}
+}
+
+// forEachBounded calls fn for every index in [0, n) on at most workers goroutines
+// and returns once all calls are done. Indices are handed out in order, and none
+// is handed out after ctx is cancelled.
+func forEachBounded(ctx context.Context, n, workers int, fn func(i int)) {
+	if workers > n {
+		workers = n
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				fn(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case work <- i:
+		case <-ctx.Done():
+		}
+	}
+	close(work)
+	wg.Wait()
+}
+
+// runSweep sweeps every address with sweepAddr on the service's worker pool and
+// collects the results in address order. Addresses not started when ctx is
+// cancelled are left out. Each finished transfer is broadcast as a tx_status SSE
+// event whose Current counts the transfers finished so far. Receipts of broadcast
+// transfers are polled in batch in the background after runSweep returns.
+func (s *BSCConsolidationService) runSweep(
+	ctx context.Context,
+	addresses []models.AddressWithBalance,
+	token models.Token,
+	sweepAddr func(addr models.AddressWithBalance, watches chan<- bscReceiptWatch) models.BSCTxResult,
+) *models.BSCSendResult {
+	// Buffered for every address so workers never wait on the receipt poller.
+	watches := make(chan bscReceiptWatch, len(addresses))
+	go s.watchReceipts(watches)
+
+	results := make([]*models.BSCTxResult, len(addresses))
+	var mu sync.Mutex
+	done := 0
+
+	forEachBounded(ctx, len(addresses), s.sweepWorkers, func(i int) {
+		txResult := sweepAddr(addresses[i], watches)
+
+		mu.Lock()
+		defer mu.Unlock()
+		results[i] = &txResult
+		done++
+
+		// Broadcast per-TX progress via SSE.
+		if s.txHub != nil {
+			s.txHub.Broadcast(TxEvent{
+				Type: "tx_status",
+				Data: TxStatusData{
+					Chain:        string(models.ChainBSC),
+					Token:        string(token),
+					AddressIndex: txResult.AddressIndex,
+					FromAddress:  txResult.FromAddress,
+					TxHash:       txResult.TxHash,
+					Status:       txResult.Status,
+					Amount:       txResult.Amount,
+					Error:        txResult.Error,
+					Current:      done,
+					Total:        len(addresses),
+				},
+			})
+		}
+	})
+	close(watches)
+
+	if err := ctx.Err(); err != nil {
+		slog.Warn("BSC sweep cancelled",
+			"token", token,
+			"finished", done,
+			"total", len(addresses),
+			"error", err,
+		)
+	}
+
+	result := &models.BSCSendResult{
+		Chain: models.ChainBSC,
+		Token: token,
+	}
+	totalSwept := new(big.Int)
+
+	for _, txResult := range results {
+		if txResult == nil {
+			continue
+		}
+		result.TxResults = append(result.TxResults, *txResult)
+
+		if txResult.Status == "success" || txResult.Status == "confirmed" {
+			result.SuccessCount++
+			amount, _ := new(big.Int).SetString(txResult.Amount, 10)
+			if amount != nil {
+				totalSwept.Add(totalSwept, amount)
+			}
+		} else {
+			result.FailCount++
+		}
+	}
+
+	result.TotalSwept = totalSwept.String()
+	return result
+}
+
+// watchReceipts polls the receipts of a sweep's broadcast transfers in batch.
+// Every BSCReceiptPollInterval it checks each outstanding transfer once. It
+// returns once watches is closed and every transfer is settled.
+func (s *BSCConsolidationService) watchReceipts(watches <-chan bscReceiptWatch) {
+	var pending []bscReceiptWatch
+	ticker := time.NewTicker(config.BSCReceiptPollInterval)
+	defer ticker.Stop()
+
+	for watches != nil || len(pending) > 0 {
+		select {
+		case w, ok := <-watches:
+			if !ok {
+				watches = nil
+				continue
+			}
+			pending = append(pending, w)
+		case <-ticker.C:
+			pending = s.pollReceipts(pending)
+		}
+	}
+}
+
+// pollReceipts checks the receipt of every pending transfer on the worker pool
+// and returns those still unsettled.
+func (s *BSCConsolidationService) pollReceipts(pending []bscReceiptWatch) []bscReceiptWatch {
+	if len(pending) == 0 {
+		return pending
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.BSCReceiptPollTimeout)
+	defer cancel()
+
+	now := time.Now()
+	settled := make([]bool, len(pending))
+	forEachBounded(ctx, len(pending), s.sweepWorkers, func(i int) {
+		settled[i] = s.checkReceipt(ctx, pending[i], now)
+	})
+
+	remaining := pending[:0]
+	for i, w := range pending {
+		if !settled[i] {
+			remaining = append(remaining, w)
+		}
+	}
+
+	slog.Debug("BSC sweep receipts polled",
+		"checked", len(pending),
+		"outstanding", len(remaining),
+	)
+	return remaining
+}
+
+// checkReceipt queries the receipt of one transfer and reports whether it is
+// settled: marked confirmed once mined, or failed when reverted or when its
+// deadline passed unmined. Query errors are retried on the next poll.
+func (s *BSCConsolidationService) checkReceipt(ctx context.Context, w bscReceiptWatch, now time.Time) bool {
+	receipt, err := s.ethClient.TransactionReceipt(ctx, w.txHash)
+	if err == nil {
+		if receipt.Status == types.ReceiptStatusFailed {
+			err = fmt.Errorf("%w: tx %s reverted in block %d",
+				config.ErrTxReverted, w.txHash.Hex(), receipt.BlockNumber.Uint64())
+			slog.Error("BSC sweep: receipt failed", "txHash", w.txHash.Hex(), "error", err)
+			s.updateTxState(w.txStateID, config.TxStateFailed, w.txHash.Hex(), fmt.Sprintf("receipt: %s", err))
+			return true
+		}
+
+		slog.Info("BSC sweep: transfer confirmed", "txHash", w.txHash.Hex(), "block", receipt.BlockNumber)
+		s.updateTxState(w.txStateID, config.TxStateConfirmed, w.txHash.Hex(), "")
+		return true
+	}
+
+	if now.After(w.deadline) {
+		err = fmt.Errorf("%w: tx %s not mined within timeout", config.ErrReceiptTimeout, w.txHash.Hex())
+		slog.Error("BSC sweep: receipt failed", "txHash", w.txHash.Hex(), "error", err)
+		s.updateTxState(w.txStateID, config.TxStateFailed, w.txHash.Hex(), fmt.Sprintf("receipt: %s", err))
+		return true
+	}
+
+	if !errors.Is(err, ethereum.NotFound) {
+		slog.Warn("BSC sweep: receipt query failed, retrying", "txHash", w.txHash.Hex(), "error", err)
+	}
+	return false
+}
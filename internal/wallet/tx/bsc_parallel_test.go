@@ -0,0 +1,184 @@
+package tx
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/wallet/db"
+	"github.com/Fantasim/hdpay/internal/wallet/hd"
+)
+
+// receiptsByHashClient answers receipt queries per transaction hash; unknown
+// hashes are not mined yet.
+type receiptsByHashClient struct {
+	*mockEthClient
+	receipts map[common.Hash]*types.Receipt
+}
+
+func (c *receiptsByHashClient) TransactionReceipt(_ context.Context, txHash common.Hash) (*types.Receipt, error) {
+	if receipt, ok := c.receipts[txHash]; ok {
+		return receipt, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func TestForEachBounded(t *testing.T) {
+	var running, peak, calls atomic.Int32
+	forEachBounded(context.Background(), 20, 3, func(int) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		running.Add(-1)
+		calls.Add(1)
+	})
+	if calls.Load() != 20 || peak.Load() > 3 {
+		t.Errorf("calls = %d, peak concurrency = %d, want 20 calls on at most 3 workers", calls.Load(), peak.Load())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls.Store(0)
+	forEachBounded(ctx, 20, 3, func(int) { calls.Add(1) })
+	if calls.Load() != 0 {
+		t.Errorf("cancelled calls = %d, want 0", calls.Load())
+	}
+}
+
+func TestBSCConsolidation_ParallelNativeSweep(t *testing.T) {
+	seed := testSeed(t, testMnemonic24)
+	masterKey, err := hd.DeriveMasterKey(seed, hd.NetworkParams("testnet"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var addresses []models.AddressWithBalance
+	for i := 0; i < 5; i++ {
+		address, err := hd.DeriveBSCAddress(masterKey, uint32(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		addresses = append(addresses, models.AddressWithBalance{
+			Chain:         models.ChainBSC,
+			AddressIndex:  i,
+			Address:       address,
+			NativeBalance: "1000000000000000000",
+		})
+	}
+
+	mock := &mockEthClient{
+		pendingNonce: 3,
+		gasPrice:     big.NewInt(3_000_000_000),
+		balance:      big.NewInt(1_000_000_000_000_000_000),
+		receiptErr:   ethereum.NotFound,
+	}
+	database := setupGasTestDB(t)
+	ks := NewKeyService(writeTempMnemonic(t, testMnemonic24), "testnet")
+	svc := NewBSCConsolidationService(ks, mock, database, big.NewInt(config.BSCTestnetChainID), nil)
+	svc.SetSweepWorkers(3)
+
+	result, err := svc.ExecuteNativeSweep(context.Background(), addresses, testBundleBSCDest, "sweep-parallel")
+	if err != nil {
+		t.Fatalf("ExecuteNativeSweep() error = %v", err)
+	}
+	if result.SuccessCount != 5 || result.FailCount != 0 || len(mock.sentTxs) != 5 {
+		t.Fatalf("ExecuteNativeSweep() = %+v after %d sends, want 5 successes", result, len(mock.sentTxs))
+	}
+	for i, txResult := range result.TxResults {
+		if txResult.AddressIndex != i {
+			t.Errorf("TxResults[%d].AddressIndex = %d, want results in address order", i, txResult.AddressIndex)
+		}
+	}
+
+	// Every source address has its own nonce sequence, recorded in tx_state.
+	for _, sent := range mock.sentTxs {
+		if sent.Nonce() != 3 {
+			t.Errorf("sent nonce %d, want 3", sent.Nonce())
+		}
+	}
+	states, err := database.GetTxStatesBySweepID("sweep-parallel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(states) != 5 {
+		t.Fatalf("tx_state rows = %d, want 5", len(states))
+	}
+	for _, st := range states {
+		if st.Status != config.TxStateConfirming || st.Nonce != 3 || st.TxHash == "" {
+			t.Errorf("tx_state %d = %s nonce %d hash %q, want confirming at nonce 3", st.AddressIndex, st.Status, st.Nonce, st.TxHash)
+		}
+	}
+}
+
+func TestBSCConsolidation_PollReceipts(t *testing.T) {
+	database := setupGasTestDB(t)
+	mined := common.HexToHash("0x01")
+	reverted := common.HexToHash("0x02")
+	client := &receiptsByHashClient{
+		mockEthClient: &mockEthClient{},
+		receipts: map[common.Hash]*types.Receipt{
+			mined:    {Status: types.ReceiptStatusSuccessful, BlockNumber: big.NewInt(100)},
+			reverted: {Status: types.ReceiptStatusFailed, BlockNumber: big.NewInt(101)},
+		},
+	}
+	svc := NewBSCConsolidationService(nil, client, database, big.NewInt(config.BSCTestnetChainID), nil)
+
+	now := time.Now()
+	watches := []bscReceiptWatch{
+		{txStateID: "mined", txHash: mined, deadline: now.Add(time.Minute)},
+		{txStateID: "reverted", txHash: reverted, deadline: now.Add(time.Minute)},
+		{txStateID: "waiting", txHash: common.HexToHash("0x03"), deadline: now.Add(time.Minute)},
+		{txStateID: "expired", txHash: common.HexToHash("0x04"), deadline: now.Add(-time.Second)},
+	}
+	for _, w := range watches {
+		if err := database.CreateTxState(db.TxStateRow{
+			ID: w.txStateID, SweepID: "sweep-receipts", Chain: "BSC", Token: "NATIVE",
+			FromAddress: "0xabc", ToAddress: testBundleBSCDest, Amount: "1",
+			TxHash: w.txHash.Hex(), Status: config.TxStateConfirming,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	remaining := svc.pollReceipts(watches)
+	if len(remaining) != 1 || remaining[0].txStateID != "waiting" {
+		t.Fatalf("pollReceipts() left %+v, want only the unmined transfer", remaining)
+	}
+
+	states, err := database.GetTxStatesBySweepID("sweep-receipts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]struct {
+		status string
+		err    error
+	}{
+		"mined":    {config.TxStateConfirmed, nil},
+		"reverted": {config.TxStateFailed, config.ErrTxReverted},
+		"waiting":  {config.TxStateConfirming, nil},
+		"expired":  {config.TxStateFailed, config.ErrReceiptTimeout},
+	}
+	for _, st := range states {
+		w := want[st.ID]
+		if st.Status != w.status {
+			t.Errorf("tx_state %s status = %s, want %s", st.ID, st.Status, w.status)
+		}
+		if w.err != nil && !strings.Contains(st.Error, w.err.Error()) {
+			t.Errorf("tx_state %s error = %q, want %v", st.ID, st.Error, w.err)
+		}
+	}
+}
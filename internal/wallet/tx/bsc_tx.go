@@ -25,6 +25,8 @@ import (
 type EthClientWrapper interface {
 	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
 	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
 	SendTransaction(ctx context.Context, tx *types.Transaction) error
 	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
 	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
@@ -91,6 +93,7 @@ var bscMinNativeSweepWei = func() *big.Int {
 // ValidateGasPriceAgainstPreview compares the current gas price with the preview gas price.
 // If the current price exceeds the preview by more than BSCGasPriceMaxIncreaseMultiplier (2x),
 // returns ErrGasPriceSpiked. Pass expectedGasPrice as "0" or "" to skip validation.
+// For dynamic-fee transactions both prices are the max fee per gas (BSCFee.GasPrice).
 func ValidateGasPriceAgainstPreview(currentGasPrice *big.Int, expectedGasPrice string) error {
 	if expectedGasPrice == "" || expectedGasPrice == "0" {
 		return nil
@@ -164,9 +167,43 @@ func BuildBSCTokenTransfer(nonce uint64, contractAddr common.Address, recipient
 	})
 }
 
-// SignBSCTx signs a BSC transaction with EIP-155 replay protection.
+// BuildBSCDynamicNativeTransfer builds an unsigned native BNB transfer as a
+// dynamic-fee (type-2) transaction paying at most feeCap per gas, tipCap of it as tip.
+func BuildBSCDynamicNativeTransfer(chainID *big.Int, nonce uint64, to common.Address, amount *big.Int, tipCap *big.Int, feeCap *big.Int) *types.Transaction {
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		To:        &to,
+		Value:     amount,
+		Gas:       config.BSCGasLimitTransfer,
+		GasTipCap: tipCap,
+		GasFeeCap: feeCap,
+		Data:      nil,
+	})
+}
+
+// BuildBSCDynamicTokenTransfer builds an unsigned BEP-20 token transfer as a
+// dynamic-fee (type-2) transaction. The To address is the token contract.
+func BuildBSCDynamicTokenTransfer(chainID *big.Int, nonce uint64, contractAddr common.Address, recipient common.Address, amount *big.Int, tipCap *big.Int, feeCap *big.Int) *types.Transaction {
+	data := EncodeBEP20Transfer(recipient, amount)
+
+	toAddr := contractAddr
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		To:        &toAddr,
+		Value:     big.NewInt(0),
+		Gas:       config.BSCGasLimitBEP20,
+		GasTipCap: tipCap,
+		GasFeeCap: feeCap,
+		Data:      data,
+	})
+}
+
+// SignBSCTx signs a BSC transaction with EIP-155 replay protection. Legacy and
+// dynamic-fee (type-2) transactions are both accepted.
 func SignBSCTx(tx *types.Transaction, chainID *big.Int, privKey *ecdsa.PrivateKey) (*types.Transaction, error) {
-	signer := types.NewEIP155Signer(chainID)
+	signer := types.LatestSignerForChainID(chainID)
 	signed, err := types.SignTx(tx, signer, privKey)
 	if err != nil {
 		return nil, fmt.Errorf("sign BSC transaction: %w", err)
@@ -220,6 +257,15 @@ type BSCConsolidationService struct {
 	database   *db.DB
 	chainID    *big.Int
 	txHub      *TxSSEHub
+
+	// dynamicFees builds type-2 transactions for sweeps; see SetDynamicFees.
+	dynamicFees bool
+
+	// nonces hands out per-address nonces to concurrent sweep workers.
+	nonces *bscNonceManager
+
+	// sweepWorkers bounds how many addresses are swept at once; see SetSweepWorkers.
+	sweepWorkers int
 }
 
 // NewBSCConsolidationService creates the BSC consolidation orchestrator.
@@ -232,26 +278,30 @@ func NewBSCConsolidationService(
 ) *BSCConsolidationService {
 	slog.Info("BSC consolidation service created", "chainID", chainID)
 	return &BSCConsolidationService{
-		keyService: keyService,
-		ethClient:  ethClient,
-		database:   database,
-		chainID:    chainID,
-		txHub:      txHub,
+		keyService:   keyService,
+		ethClient:    ethClient,
+		database:     database,
+		chainID:      chainID,
+		txHub:        txHub,
+		nonces:       newBSCNonceManager(ethClient, database),
+		sweepWorkers: config.BSCDefaultSweepWorkers,
 	}
 }
 
-// EstimateGasPrice returns the buffered gas price for BSC transactions.
+// EstimateGasPrice returns the buffered legacy gas price for BSC transactions.
+// Offline bundles are priced with it; sweeps use EstimateFee.
 func (s *BSCConsolidationService) EstimateGasPrice(ctx context.Context) (*big.Int, error) {
-	suggested, err := s.ethClient.SuggestGasPrice(ctx)
+	fee, err := EstimateBSCFee(ctx, s.ethClient, false)
 	if err != nil {
-		return nil, fmt.Errorf("suggest gas price: %w", err)
+		return nil, err
 	}
-	buffered := BufferedGasPrice(suggested)
-	slog.Debug("BSC gas price estimated",
-		"suggested", suggested.String(),
-		"buffered", buffered.String(),
-	)
-	return buffered, nil
+	return fee.GasPrice, nil
+}
+
+// EstimateFee returns the fee sweeps are built with: dynamic when enabled by
+// SetDynamicFees, legacy otherwise.
+func (s *BSCConsolidationService) EstimateFee(ctx context.Context) (BSCFee, error) {
+	return EstimateBSCFee(ctx, s.ethClient, s.dynamicFees)
 }
 
 // PreviewNativeSweep calculates the expected result of a native BNB consolidation.
@@ -261,13 +311,12 @@ func (s *BSCConsolidationService) PreviewNativeSweep(ctx context.Context, addres
 		"destAddress", destAddr,
 	)
 
-	gasPrice, err := s.ethClient.SuggestGasPrice(ctx)
+	fee, err := s.EstimateFee(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("suggest gas price: %w", err)
+		return nil, err
 	}
-	gasPrice = BufferedGasPrice(gasPrice)
 
-	gasCostPerTx := new(big.Int).Mul(gasPrice, big.NewInt(int64(config.BSCGasLimitTransfer)))
+	gasCostPerTx := fee.GasCost(config.BSCGasLimitTransfer)
 	totalGasCost := new(big.Int).Mul(gasCostPerTx, big.NewInt(int64(len(addresses))))
 
 	totalBalance := new(big.Int)
@@ -297,7 +346,11 @@ func (s *BSCConsolidationService) PreviewNativeSweep(ctx context.Context, addres
 		GasCostWei:  totalGasCost.String(),
 		NetAmount:   netAmount.String(),
 		DestAddress: destAddr,
-		GasPrice:    gasPrice.String(),
+		GasPrice:    fee.GasPrice.String(),
+	}
+	if fee.Dynamic() {
+		preview.BaseFee = fee.BaseFee.String()
+		preview.PriorityFee = fee.TipCap.String()
 	}
 
 	slog.Info("BSC native sweep preview complete",
@@ -336,6 +389,16 @@ func (s *BSCConsolidationService) updateTxState(id, status, txHash, txError stri
 	}
 }
 
+// setTxStateNonce is a non-blocking helper that records the nonce of a tx_state row.
+func (s *BSCConsolidationService) setTxStateNonce(id string, nonce uint64) {
+	if s.database == nil {
+		return
+	}
+	if err := s.database.SetTxStateNonce(id, nonce); err != nil {
+		slog.Error("failed to record BSC tx_state nonce", "id", id, "nonce", nonce, "error", err)
+	}
+}
+
 // createTxState is a non-blocking helper that creates a tx_state row if database is available.
 func (s *BSCConsolidationService) createTxState(txState db.TxStateRow) {
 	if s.database == nil {
@@ -346,9 +409,11 @@ func (s *BSCConsolidationService) createTxState(txState db.TxStateRow) {
 	}
 }
 
-// ExecuteNativeSweep performs sequential BNB transfers from funded addresses to a destination.
-// expectedGasPrice is the gas price (wei) from the preview. If non-empty, the sweep
-// will be rejected if the current gas price exceeds 2x the preview price.
+// ExecuteNativeSweep performs BNB transfers from funded addresses to a destination,
+// sweeping up to sweepWorkers addresses concurrently. Receipts are polled in the background.
+// expectedGasPrice is the gas price (wei) from the preview, the max fee per gas for
+// dynamic fees. If non-empty, the sweep will be rejected if the current gas price
+// exceeds 2x the preview price.
 func (s *BSCConsolidationService) ExecuteNativeSweep(ctx context.Context, addresses []models.AddressWithBalance, destAddr string, sweepID string, expectedGasPrice ...string) (*models.BSCSendResult, error) {
 	slog.Info("BSC native sweep execute",
 		"addressCount", len(addresses),
@@ -359,72 +424,31 @@ func (s *BSCConsolidationService) ExecuteNativeSweep(ctx context.Context, addres
 	dest := common.HexToAddress(destAddr)
 
 	// Get gas price once for all transactions.
-	gasPrice, err := s.ethClient.SuggestGasPrice(ctx)
+	fee, err := s.EstimateFee(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("suggest gas price: %w", err)
+		return nil, err
 	}
-	gasPrice = BufferedGasPrice(gasPrice)
 
 	// Validate gas price against preview (if provided).
 	if len(expectedGasPrice) > 0 && expectedGasPrice[0] != "" {
-		if err := ValidateGasPriceAgainstPreview(gasPrice, expectedGasPrice[0]); err != nil {
+		if err := ValidateGasPriceAgainstPreview(fee.GasPrice, expectedGasPrice[0]); err != nil {
 			return nil, err
 		}
 	}
 
-	gasCostPerTx := new(big.Int).Mul(gasPrice, big.NewInt(int64(config.BSCGasLimitTransfer)))
+	// For dynamic fees this is the most a transfer can cost; what the max fee does
+	// not spend stays at the swept address.
+	gasCostPerTx := fee.GasCost(config.BSCGasLimitTransfer)
 
 	slog.Info("BSC sweep gas price",
-		"gasPrice", gasPrice,
+		"gasPrice", fee.GasPrice,
+		"dynamic", fee.Dynamic(),
 		"gasCostPerTx", gasCostPerTx,
 	)
 
-	result := &models.BSCSendResult{
-		Chain:   models.ChainBSC,
-		Token:   models.TokenNative,
-	}
-	totalSwept := new(big.Int)
-
-	for i, addr := range addresses {
-		if err := ctx.Err(); err != nil {
-			slog.Warn("BSC native sweep cancelled", "error", err)
-			break
-		}
-
-		txResult := s.sweepNativeAddress(ctx, addr, dest, gasPrice, gasCostPerTx, sweepID)
-		result.TxResults = append(result.TxResults, txResult)
-
-		if txResult.Status == "success" || txResult.Status == "confirmed" {
-			result.SuccessCount++
-			amount, _ := new(big.Int).SetString(txResult.Amount, 10)
-			if amount != nil {
-				totalSwept.Add(totalSwept, amount)
-			}
-		} else {
-			result.FailCount++
-		}
-
-		// Broadcast per-TX progress via SSE.
-		if s.txHub != nil {
-			s.txHub.Broadcast(TxEvent{
-				Type: "tx_status",
-				Data: TxStatusData{
-					Chain:        string(models.ChainBSC),
-					Token:        string(models.TokenNative),
-					AddressIndex: txResult.AddressIndex,
-					FromAddress:  txResult.FromAddress,
-					TxHash:       txResult.TxHash,
-					Status:       txResult.Status,
-					Amount:       txResult.Amount,
-					Error:        txResult.Error,
-					Current:      i + 1,
-					Total:        len(addresses),
-				},
-			})
-		}
-	}
-
-	result.TotalSwept = totalSwept.String()
+	result := s.runSweep(ctx, addresses, models.TokenNative, func(addr models.AddressWithBalance, watches chan<- bscReceiptWatch) models.BSCTxResult {
+		return s.sweepNativeAddress(ctx, addr, dest, fee, gasCostPerTx, sweepID, watches)
+	})
 
 	slog.Info("BSC native sweep complete",
 		"successCount", result.SuccessCount,
@@ -436,14 +460,16 @@ func (s *BSCConsolidationService) ExecuteNativeSweep(ctx context.Context, addres
 	return result, nil
 }
 
-// sweepNativeAddress sends BNB from a single address to the destination.
+// sweepNativeAddress sends BNB from a single address to the destination and hands
+// the broadcast transfer to the sweep's receipt poller.
 func (s *BSCConsolidationService) sweepNativeAddress(
 	ctx context.Context,
 	addr models.AddressWithBalance,
 	dest common.Address,
-	gasPrice *big.Int,
+	fee BSCFee,
 	gasCostPerTx *big.Int,
 	sweepID string,
+	watches chan<- bscReceiptWatch,
 ) models.BSCTxResult {
 	txResult := models.BSCTxResult{
 		AddressIndex: addr.AddressIndex,
@@ -537,8 +563,8 @@ func (s *BSCConsolidationService) sweepNativeAddress(
 		return txResult
 	}
 
-	// Get nonce.
-	nonce, err := s.ethClient.PendingNonceAt(ctx, fromAddr)
+	// Reserve a nonce; it is handed back if the transfer never reaches the network.
+	nonce, err := s.nonces.Next(ctx, fromAddr)
 	if err != nil {
 		txResult.Status = "failed"
 		txResult.Error = fmt.Sprintf("get nonce: %s", err)
@@ -548,12 +574,13 @@ func (s *BSCConsolidationService) sweepNativeAddress(
 	}
 
 	// Build and sign.
-	unsignedTx := BuildBSCNativeTransfer(nonce, dest, sendAmount, gasPrice)
+	unsignedTx := fee.buildNativeTransfer(s.chainID, nonce, dest, sendAmount)
 	signedTx, err := SignBSCTx(unsignedTx, s.chainID, privKey)
 	if err != nil {
 		txResult.Status = "failed"
 		txResult.Error = fmt.Sprintf("sign tx: %s", err)
 		slog.Error("BSC sweep: sign failed", "address", addr.Address, "error", err)
+		s.nonces.Release(fromAddr, nonce)
 		s.updateTxState(txStateID, config.TxStateFailed, "", txResult.Error)
 		return txResult
 	}
@@ -566,7 +593,8 @@ func (s *BSCConsolidationService) sweepNativeAddress(
 		"txStateID", txStateID,
 	)
 
-	// Update to broadcasting.
+	// Record the nonce, then update to broadcasting.
+	s.setTxStateNonce(txStateID, nonce)
 	s.updateTxState(txStateID, config.TxStateBroadcasting, "", "")
 
 	// Broadcast.
@@ -574,6 +602,7 @@ func (s *BSCConsolidationService) sweepNativeAddress(
 		txResult.Status = "failed"
 		txResult.Error = fmt.Sprintf("broadcast: %s", err)
 		slog.Error("BSC sweep: broadcast failed", "address", addr.Address, "error", err)
+		s.nonces.Release(fromAddr, nonce)
 		s.updateTxState(txStateID, config.TxStateFailed, "", txResult.Error)
 		return txResult
 	}
@@ -595,26 +624,18 @@ func (s *BSCConsolidationService) sweepNativeAddress(
 		"amount", sendAmount,
 	)
 
-	// Poll for receipt in background.
-	go func() {
-		bgCtx, cancel := context.WithTimeout(context.Background(), config.BSCReceiptPollTimeout)
-		defer cancel()
-
-		receipt, err := WaitForReceipt(bgCtx, s.ethClient, txHash)
-		if err != nil {
-			slog.Error("BSC sweep: receipt failed", "txHash", txHash.Hex(), "error", err)
-			s.updateTxState(txStateID, config.TxStateFailed, txHash.Hex(), fmt.Sprintf("receipt: %s", err))
-			return
-		}
-
-		slog.Info("BSC sweep: native transfer confirmed", "txHash", txHash.Hex(), "block", receipt.BlockNumber)
-		s.updateTxState(txStateID, config.TxStateConfirmed, txHash.Hex(), "")
-	}()
+	// Poll for receipt in background, batched with the rest of the sweep.
+	watches <- bscReceiptWatch{
+		txStateID: txStateID,
+		txHash:    txHash,
+		deadline:  time.Now().Add(config.BSCReceiptPollTimeout),
+	}
 
 	return txResult
 }
 
-// ExecuteTokenSweep performs sequential BEP-20 token transfers from funded addresses.
+// ExecuteTokenSweep performs BEP-20 token transfers from funded addresses, sweeping up
+// to sweepWorkers addresses concurrently. Receipts are polled in the background.
 // expectedGasPrice is the gas price (wei) from the preview for spike detection.
 func (s *BSCConsolidationService) ExecuteTokenSweep(
 	ctx context.Context,
@@ -637,23 +658,23 @@ func (s *BSCConsolidationService) ExecuteTokenSweep(
 	contract := common.HexToAddress(contractAddr)
 
 	// Get gas price once.
-	gasPrice, err := s.ethClient.SuggestGasPrice(ctx)
+	fee, err := s.EstimateFee(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("suggest gas price: %w", err)
+		return nil, err
 	}
-	gasPrice = BufferedGasPrice(gasPrice)
 
 	// Validate gas price against preview (if provided).
 	if len(expectedGasPrice) > 0 && expectedGasPrice[0] != "" {
-		if err := ValidateGasPriceAgainstPreview(gasPrice, expectedGasPrice[0]); err != nil {
+		if err := ValidateGasPriceAgainstPreview(fee.GasPrice, expectedGasPrice[0]); err != nil {
 			return nil, err
 		}
 	}
 
-	gasCostPerTx := new(big.Int).Mul(gasPrice, big.NewInt(int64(config.BSCGasLimitBEP20)))
+	gasCostPerTx := fee.GasCost(config.BSCGasLimitBEP20)
 
 	slog.Info("BSC token sweep gas price",
-		"gasPrice", gasPrice,
+		"gasPrice", fee.GasPrice,
+		"dynamic", fee.Dynamic(),
 		"gasCostPerTx", gasCostPerTx,
 		"token", token,
 	)
@@ -670,52 +691,9 @@ func (s *BSCConsolidationService) ExecuteTokenSweep(
 		return nil, fmt.Errorf("%w: %d addresses need gas pre-seeding", config.ErrInsufficientBNBForGas, len(needsGas))
 	}
 
-	result := &models.BSCSendResult{
-		Chain: models.ChainBSC,
-		Token: token,
-	}
-	totalSwept := new(big.Int)
-
-	for i, addr := range addresses {
-		if err := ctx.Err(); err != nil {
-			slog.Warn("BSC token sweep cancelled", "error", err)
-			break
-		}
-
-		txResult := s.sweepTokenAddress(ctx, addr, dest, contract, token, gasPrice, sweepID)
-		result.TxResults = append(result.TxResults, txResult)
-
-		if txResult.Status == "success" || txResult.Status == "confirmed" {
-			result.SuccessCount++
-			amount, _ := new(big.Int).SetString(txResult.Amount, 10)
-			if amount != nil {
-				totalSwept.Add(totalSwept, amount)
-			}
-		} else {
-			result.FailCount++
-		}
-
-		// Broadcast per-TX progress via SSE.
-		if s.txHub != nil {
-			s.txHub.Broadcast(TxEvent{
-				Type: "tx_status",
-				Data: TxStatusData{
-					Chain:        string(models.ChainBSC),
-					Token:        string(token),
-					AddressIndex: txResult.AddressIndex,
-					FromAddress:  txResult.FromAddress,
-					TxHash:       txResult.TxHash,
-					Status:       txResult.Status,
-					Amount:       txResult.Amount,
-					Error:        txResult.Error,
-					Current:      i + 1,
-					Total:        len(addresses),
-				},
-			})
-		}
-	}
-
-	result.TotalSwept = totalSwept.String()
+	result := s.runSweep(ctx, addresses, token, func(addr models.AddressWithBalance, watches chan<- bscReceiptWatch) models.BSCTxResult {
+		return s.sweepTokenAddress(ctx, addr, dest, contract, token, fee, sweepID, watches)
+	})
 
 	slog.Info("BSC token sweep complete",
 		"token", token,
@@ -758,15 +736,17 @@ func (s *BSCConsolidationService) checkGasForTokenSweep(
 	return needsGas, nil
 }
 
-// sweepTokenAddress sends BEP-20 tokens from a single address to the destination.
+// sweepTokenAddress sends BEP-20 tokens from a single address to the destination and
+// hands the broadcast transfer to the sweep's receipt poller.
 func (s *BSCConsolidationService) sweepTokenAddress(
 	ctx context.Context,
 	addr models.AddressWithBalance,
 	dest common.Address,
 	contract common.Address,
 	token models.Token,
-	gasPrice *big.Int,
+	fee BSCFee,
 	sweepID string,
+	watches chan<- bscReceiptWatch,
 ) models.BSCTxResult {
 	txResult := models.BSCTxResult{
 		AddressIndex: addr.AddressIndex,
@@ -846,7 +826,7 @@ func (s *BSCConsolidationService) sweepTokenAddress(
 
 	// Per-TX gas check: verify this address still has enough BNB for gas.
 	// Gas prices may have changed since the initial sweep-level check.
-	gasCostPerTx := new(big.Int).Mul(gasPrice, big.NewInt(int64(config.BSCGasLimitBEP20)))
+	gasCostPerTx := fee.GasCost(config.BSCGasLimitBEP20)
 	bnbBalance, err := s.ethClient.BalanceAt(ctx, fromAddr, nil)
 	if err != nil {
 		slog.Warn("BSC token sweep: gas balance check failed, proceeding with caution",
@@ -902,8 +882,8 @@ func (s *BSCConsolidationService) sweepTokenAddress(
 		return txResult
 	}
 
-	// Get nonce.
-	nonce, err := s.ethClient.PendingNonceAt(ctx, fromAddr)
+	// Reserve a nonce; it is handed back if the transfer never reaches the network.
+	nonce, err := s.nonces.Next(ctx, fromAddr)
 	if err != nil {
 		txResult.Status = "failed"
 		txResult.Error = fmt.Sprintf("get nonce: %s", err)
@@ -913,12 +893,13 @@ func (s *BSCConsolidationService) sweepTokenAddress(
 	}
 
 	// Build and sign BEP-20 transfer.
-	unsignedTx := BuildBSCTokenTransfer(nonce, contract, dest, tokenBalance, gasPrice)
+	unsignedTx := fee.buildTokenTransfer(s.chainID, nonce, contract, dest, tokenBalance)
 	signedTx, err := SignBSCTx(unsignedTx, s.chainID, privKey)
 	if err != nil {
 		txResult.Status = "failed"
 		txResult.Error = fmt.Sprintf("sign tx: %s", err)
 		slog.Error("BSC token sweep: sign failed", "address", addr.Address, "error", err)
+		s.nonces.Release(fromAddr, nonce)
 		s.updateTxState(txStateID, config.TxStateFailed, "", txResult.Error)
 		return txResult
 	}
@@ -933,7 +914,8 @@ func (s *BSCConsolidationService) sweepTokenAddress(
 		"txStateID", txStateID,
 	)
 
-	// Update to broadcasting.
+	// Record the nonce, then update to broadcasting.
+	s.setTxStateNonce(txStateID, nonce)
 	s.updateTxState(txStateID, config.TxStateBroadcasting, "", "")
 
 	// Broadcast.
@@ -941,6 +923,7 @@ func (s *BSCConsolidationService) sweepTokenAddress(
 		txResult.Status = "failed"
 		txResult.Error = fmt.Sprintf("broadcast: %s", err)
 		slog.Error("BSC token sweep: broadcast failed", "address", addr.Address, "error", err)
+		s.nonces.Release(fromAddr, nonce)
 		s.updateTxState(txStateID, config.TxStateFailed, "", txResult.Error)
 		return txResult
 	}
@@ -963,21 +946,12 @@ func (s *BSCConsolidationService) sweepTokenAddress(
 		"amount", tokenBalance,
 	)
 
-	// Poll for receipt in background.
-	go func() {
-		bgCtx, cancel := context.WithTimeout(context.Background(), config.BSCReceiptPollTimeout)
-		defer cancel()
-
-		receipt, err := WaitForReceipt(bgCtx, s.ethClient, txHash)
-		if err != nil {
-			slog.Error("BSC token sweep: receipt failed", "txHash", txHash.Hex(), "error", err)
-			s.updateTxState(txStateID, config.TxStateFailed, txHash.Hex(), fmt.Sprintf("receipt: %s", err))
-			return
-		}
-
-		slog.Info("BSC token sweep: transfer confirmed", "txHash", txHash.Hex(), "block", receipt.BlockNumber)
-		s.updateTxState(txStateID, config.TxStateConfirmed, txHash.Hex(), "")
-	}()
+	// Poll for receipt in background, batched with the rest of the sweep.
+	watches <- bscReceiptWatch{
+		txStateID: txStateID,
+		txHash:    txHash,
+		deadline:  time.Now().Add(config.BSCReceiptPollTimeout),
+	}
 
 	return txResult
 }
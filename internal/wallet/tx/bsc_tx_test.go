@@ -5,6 +5,7 @@ import (
 	"crypto/ecdsa"
 	"errors"
 	"math/big"
+	"sync"
 	"testing"
 	"time"
 
@@ -31,8 +32,12 @@ type mockEthClient struct {
 	balanceErr      error
 	callResult      []byte
 	callErr         error
+	tipCap          *big.Int
+	baseFee         *big.Int
+	feeHistoryErr   error
 
-	// Track calls for assertions
+	// Track calls for assertions; sweeps send from several goroutines.
+	mu      sync.Mutex
 	sentTxs []*types.Transaction
 }
 
@@ -47,7 +52,27 @@ func (m *mockEthClient) SuggestGasPrice(_ context.Context) (*big.Int, error) {
 	return new(big.Int).Set(m.gasPrice), nil
 }
 
+func (m *mockEthClient) SuggestGasTipCap(_ context.Context) (*big.Int, error) {
+	if m.gasPriceErr != nil {
+		return nil, m.gasPriceErr
+	}
+	return new(big.Int).Set(m.tipCap), nil
+}
+
+func (m *mockEthClient) FeeHistory(_ context.Context, blockCount uint64, _ *big.Int, _ []float64) (*ethereum.FeeHistory, error) {
+	if m.feeHistoryErr != nil {
+		return nil, m.feeHistoryErr
+	}
+	baseFees := make([]*big.Int, blockCount+1)
+	for i := range baseFees {
+		baseFees[i] = new(big.Int).Set(m.baseFee)
+	}
+	return &ethereum.FeeHistory{BaseFee: baseFees}, nil
+}
+
 func (m *mockEthClient) SendTransaction(_ context.Context, tx *types.Transaction) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.sentTxs = append(m.sentTxs, tx)
 	return m.sendTxErr
 }
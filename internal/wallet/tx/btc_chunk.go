@@ -0,0 +1,190 @@
+package tx
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/wallet/db"
+)
+
+// ChunkBTCUTXOs splits the inputs of a consolidation paying destScript into
+// consecutive chunks, each small enough for one standard transaction: at most
+// maxInputs inputs and an estimated vsize of at most maxVsize. UTXO order is
+// kept. A single input that does not fit within maxVsize is an ErrTxTooLarge.
+func ChunkBTCUTXOs(utxos []models.UTXO, destScript []byte, maxInputs, maxVsize int) ([][]models.UTXO, error) {
+	if maxInputs <= 0 || maxVsize <= 0 {
+		return nil, fmt.Errorf("invalid chunk limits: %d inputs, %d vB", maxInputs, maxVsize)
+	}
+
+	maxWeight := maxVsize * 4
+	baseWeight := config.BTCTxOverheadWU + config.BTCOutputBaseWU + len(destScript)*4
+
+	var chunks [][]models.UTXO
+	var current []models.UTXO
+	weight := baseWeight
+	for _, u := range utxos {
+		w, err := btcInputWeight(u.AddressType)
+		if err != nil {
+			return nil, fmt.Errorf("input %s:%d: %w", u.TxID, u.Vout, err)
+		}
+		if baseWeight+w > maxWeight {
+			return nil, fmt.Errorf("%w: input %s:%d alone exceeds %d vB",
+				config.ErrTxTooLarge, u.TxID, u.Vout, maxVsize)
+		}
+		if len(current) > 0 && (len(current) == maxInputs || weight+w > maxWeight) {
+			chunks = append(chunks, current)
+			current = nil
+			weight = baseWeight
+		}
+		current = append(current, u)
+		weight += w
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+	return chunks, nil
+}
+
+// SetChunkLimits sets the per-transaction input count and vsize caps that
+// consolidations are split by. A zero value keeps the current limit.
+// Must be called before the service is shared.
+func (s *BTCConsolidationService) SetChunkLimits(maxInputs, maxVsize int) {
+	if maxInputs > 0 {
+		s.chunkMaxInputs = maxInputs
+	}
+	if maxVsize > 0 {
+		s.chunkMaxVsize = maxVsize
+	}
+}
+
+// chunkUTXOs splits a consolidation to destAddr under the service's chunk limits.
+func (s *BTCConsolidationService) chunkUTXOs(utxos []models.UTXO, destAddr string) ([][]models.UTXO, error) {
+	destScript, err := PKScriptFromAddress(destAddr, s.netParams)
+	if err != nil {
+		return nil, fmt.Errorf("destination script: %w", err)
+	}
+	return ChunkBTCUTXOs(utxos, destScript, s.chunkMaxInputs, s.chunkMaxVsize)
+}
+
+// executeChunk builds, signs and broadcasts one transaction of a consolidation
+// under its own tx_state row, which lists the address indices it spends so that
+// a resume retries exactly those. current and total number the chunk in SSE
+// progress events.
+func (s *BTCConsolidationService) executeChunk(ctx context.Context, sweepID, destAddr string, feeRate int64, utxos []models.UTXO, current, total int, start time.Time) (models.BTCChunkResult, error) {
+	result := models.BTCChunkResult{
+		InputCount:     len(utxos),
+		AddressIndices: utxoAddressIndices(utxos),
+		Status:         "failed",
+	}
+
+	txStateID := GenerateTxStateID()
+	if err := s.database.CreateTxState(db.TxStateRow{
+		ID:           txStateID,
+		SweepID:      sweepID,
+		Chain:        string(models.ChainBTC),
+		Token:        string(models.TokenNative),
+		AddressIndex: 0, // BTC consolidation is multi-input, no single index
+		FromAddress:  "consolidated",
+		ToAddress:    destAddr,
+		Amount:       "0",
+		Status:       config.TxStatePending,
+	}); err != nil {
+		slog.Error("failed to create BTC tx_state", "error", err)
+		// Non-blocking: continue even if tx_state write fails
+	}
+	if err := s.database.SetTxStateInputs(txStateID, result.AddressIndices); err != nil {
+		slog.Error("failed to record BTC tx_state inputs", "id", txStateID, "error", err)
+	}
+
+	fail := func(step string, err error) (models.BTCChunkResult, error) {
+		s.updateTxState(txStateID, config.TxStateFailed, "", fmt.Sprintf("%s: %s", step, err))
+		result.Error = err.Error()
+		return result, fmt.Errorf("%s: %w", step, err)
+	}
+
+	built, err := BuildBTCConsolidationTx(BTCBuildParams{
+		UTXOs:       utxos,
+		DestAddress: destAddr,
+		FeeRate:     feeRate,
+		NetParams:   s.netParams,
+	})
+	if err != nil {
+		return fail("build TX", err)
+	}
+
+	signingUTXOs, err := s.prepareSigningUTXOs(ctx, built.UTXOs)
+	if err != nil {
+		// Zero any keys already derived on error.
+		for _, su := range signingUTXOs {
+			if su.PrivKey != nil {
+				su.PrivKey.Zero()
+			}
+		}
+		return fail("prepare signing UTXOs", err)
+	}
+
+	if err := SignBTCTx(built.Tx, signingUTXOs); err != nil {
+		return fail("sign TX", err)
+	}
+
+	sent, err := s.broadcastSigned(ctx, txStateID, built, destAddr, start, current, total)
+	if err != nil {
+		result.Error = err.Error()
+		return result, err
+	}
+
+	result.TxHash = sent.TxHash
+	result.OutputSats = sent.OutputSats
+	result.Status = "success"
+	return result, nil
+}
+
+// recordFailedConsolidation stores a failed tx_state row for a consolidation that
+// failed before it was split into transactions, listing every address so a
+// resume retries the whole consolidation.
+func (s *BTCConsolidationService) recordFailedConsolidation(sweepID, destAddr string, addresses []models.Address, reason string) {
+	txStateID := GenerateTxStateID()
+	if err := s.database.CreateTxState(db.TxStateRow{
+		ID:           txStateID,
+		SweepID:      sweepID,
+		Chain:        string(models.ChainBTC),
+		Token:        string(models.TokenNative),
+		AddressIndex: 0, // BTC consolidation is multi-input, no single index
+		FromAddress:  "consolidated",
+		ToAddress:    destAddr,
+		Amount:       "0",
+		Status:       config.TxStateFailed,
+		Error:        reason,
+	}); err != nil {
+		slog.Error("failed to create BTC tx_state", "error", err)
+		return
+	}
+
+	indices := make([]int, len(addresses))
+	for i, a := range addresses {
+		indices[i] = a.AddressIndex
+	}
+	if err := s.database.SetTxStateInputs(txStateID, indices); err != nil {
+		slog.Error("failed to record BTC tx_state inputs", "id", txStateID, "error", err)
+	}
+}
+
+// utxoAddressIndices returns the distinct address indices the UTXOs spend, in
+// ascending order.
+func utxoAddressIndices(utxos []models.UTXO) []int {
+	seen := make(map[int]bool, len(utxos))
+	indices := make([]int, 0, len(utxos))
+	for _, u := range utxos {
+		if !seen[u.AddressIndex] {
+			seen[u.AddressIndex] = true
+			indices = append(indices, u.AddressIndex)
+		}
+	}
+	sort.Ints(indices)
+	return indices
+}
@@ -0,0 +1,156 @@
+package tx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/shared/scanner"
+)
+
+func TestChunkBTCUTXOs(t *testing.T) {
+	utxos := psbtTestConsolidation(t).UTXOs
+	destScript, err := PKScriptFromAddress(utxos[0].Address, &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chunks, err := ChunkBTCUTXOs(utxos, destScript, config.BTCMaxInputsPerTx, config.BTCDefaultChunkMaxVsize)
+	if err != nil {
+		t.Fatalf("ChunkBTCUTXOs(default limits) error = %v", err)
+	}
+	if len(chunks) != 1 || len(chunks[0]) != len(utxos) {
+		t.Fatalf("default limits: got %d chunks, want all inputs in one", len(chunks))
+	}
+
+	// Input cap: 4 inputs in chunks of 3 keep their order.
+	chunks, err = ChunkBTCUTXOs(utxos, destScript, 3, config.BTCDefaultChunkMaxVsize)
+	if err != nil {
+		t.Fatalf("ChunkBTCUTXOs(3 inputs) error = %v", err)
+	}
+	if len(chunks) != 2 || len(chunks[0]) != 3 || len(chunks[1]) != 1 || chunks[1][0].TxID != utxos[3].TxID {
+		t.Fatalf("3 inputs per chunk: got %d chunks, want [3 1] in order", len(chunks))
+	}
+
+	// Vsize cap: every chunk stays within it.
+	maxVsize := 250
+	chunks, err = ChunkBTCUTXOs(utxos, destScript, config.BTCMaxInputsPerTx, maxVsize)
+	if err != nil {
+		t.Fatalf("ChunkBTCUTXOs(%d vB) error = %v", maxVsize, err)
+	}
+	if len(chunks) < 2 {
+		t.Fatalf("%d vB: got %d chunks, want a split", maxVsize, len(chunks))
+	}
+	total := 0
+	for i, chunk := range chunks {
+		total += len(chunk)
+		weight, err := EstimateBTCTxWeight(chunk, [][]byte{destScript})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (weight+3)/4 > maxVsize {
+			t.Errorf("chunk %d: %d vB exceeds %d", i, (weight+3)/4, maxVsize)
+		}
+	}
+	if total != len(utxos) {
+		t.Errorf("chunks hold %d inputs, want %d", total, len(utxos))
+	}
+
+	if _, err := ChunkBTCUTXOs(utxos, destScript, config.BTCMaxInputsPerTx, 50); !errors.Is(err, config.ErrTxTooLarge) {
+		t.Errorf("ChunkBTCUTXOs(50 vB) error = %v, want ErrTxTooLarge", err)
+	}
+}
+
+func TestBTCConsolidationService_ChunkedConsolidation(t *testing.T) {
+	net := &chaincfg.TestNet3Params
+	utxos := psbtTestConsolidation(t).UTXOs
+	database := setupGasTestDB(t)
+
+	esplora := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/status") {
+			w.Write([]byte(`{"confirmed":true}`))
+			return
+		}
+		var list []map[string]any
+		for _, u := range utxos {
+			if r.URL.Path == "/address/"+u.Address+"/utxo" {
+				list = append(list, map[string]any{"txid": u.TxID, "vout": u.Vout, "value": u.Value, "status": map[string]any{"confirmed": true}})
+			}
+		}
+		json.NewEncoder(w).Encode(list)
+	}))
+	defer esplora.Close()
+
+	fetcher := NewBTCUTXOFetcher(esplora.Client(), []string{esplora.URL}, []*scanner.RateLimiter{scanner.NewRateLimiter("test", 100, 0)})
+	ks := NewKeyService(writeTempMnemonic(t, testMnemonic24), "testnet")
+	broadcaster := &recordingBroadcaster{}
+	svc := NewBTCConsolidationService(ks, fetcher, nil, broadcaster, database, net, esplora.Client(), []string{esplora.URL}, nil)
+	svc.SetChunkLimits(2, 0)
+
+	addresses := make([]models.Address, len(utxos))
+	for i, u := range utxos {
+		addresses[i] = models.Address{Chain: models.ChainBTC, AddressIndex: u.AddressIndex, Address: u.Address}
+	}
+	dest := utxos[0].Address
+
+	preview, err := svc.Preview(context.Background(), addresses, dest, 5, "", models.BTCInputSelection{})
+	if err != nil {
+		t.Fatalf("Preview() error = %v", err)
+	}
+	if len(preview.Chunks) != 2 || preview.InputCount != 4 {
+		t.Fatalf("Preview() = %+v, want 4 inputs in 2 transactions", preview)
+	}
+	if preview.Chunks[0].FeeSats+preview.Chunks[1].FeeSats != preview.FeeSats ||
+		preview.OutputSats+preview.FeeSats != preview.TotalInputSats {
+		t.Errorf("preview totals %+v do not add up over its chunks", preview)
+	}
+	if preview.PSBT != "" {
+		t.Error("chunked preview carries a PSBT")
+	}
+
+	result, err := svc.Execute(context.Background(), addresses, dest, 5, "", "sweep-chunked", 0, 0, models.BTCInputSelection{})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if len(broadcaster.sent) != 2 || len(result.Chunks) != 2 || result.OutputSats != preview.OutputSats {
+		t.Fatalf("Execute() = %+v after %d broadcasts, want 2 transactions", result, len(broadcaster.sent))
+	}
+	for i, chunk := range result.Chunks {
+		if chunk.Status != "success" || chunk.InputCount != 2 || len(chunk.AddressIndices) != 2 {
+			t.Errorf("chunk %d = %+v", i, chunk)
+		}
+	}
+
+	// Each transaction has its own tx_state row listing the addresses it spends.
+	states, err := database.GetTxStatesBySweepID("sweep-chunked")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(states) != 2 {
+		t.Fatalf("got %d tx_state rows, want one per transaction", len(states))
+	}
+	seen := make(map[int]bool)
+	for _, st := range states {
+		indices, err := database.GetTxStateInputs(st.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(indices) != 2 {
+			t.Errorf("tx_state %s inputs = %v, want 2 addresses", st.ID, indices)
+		}
+		for _, idx := range indices {
+			seen[idx] = true
+		}
+	}
+	if len(seen) != len(addresses) {
+		t.Errorf("tx_state inputs cover %d addresses, want %d", len(seen), len(addresses))
+	}
+}
@@ -0,0 +1,116 @@
+package tx
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+)
+
+// ValidateBTCInputSelection checks the bounds of a coin control selection.
+func ValidateBTCInputSelection(sel models.BTCInputSelection) error {
+	if sel.MinValueSats < 0 {
+		return fmt.Errorf("%w: minValueSats must not be negative", config.ErrInvalidInputSelection)
+	}
+	if sel.MaxInputs < 0 || sel.MaxInputs > config.BTCMaxInputsPerTx {
+		return fmt.Errorf("%w: maxInputs must be between 0 and %d", config.ErrInvalidInputSelection, config.BTCMaxInputsPerTx)
+	}
+	for _, idx := range sel.AddressIndices {
+		if idx < 0 {
+			return fmt.Errorf("%w: address index %d is negative", config.ErrInvalidInputSelection, idx)
+		}
+	}
+	return nil
+}
+
+// SelectBTCInputs drops frozen outpoints and UTXOs below sel.MinValueSats and,
+// when sel.MaxInputs is set, keeps only the largest UTXOs. The address and
+// confirmation criteria apply when fetching (see fetchSelectedUTXOs).
+func SelectBTCInputs(utxos []models.UTXO, sel models.BTCInputSelection, frozen []models.FrozenUTXO) []models.UTXO {
+	isFrozen := make(map[string]bool, len(frozen))
+	for _, f := range frozen {
+		isFrozen[fmt.Sprintf("%s:%d", f.TxID, f.Vout)] = true
+	}
+
+	selected := make([]models.UTXO, 0, len(utxos))
+	for _, u := range utxos {
+		if isFrozen[fmt.Sprintf("%s:%d", u.TxID, u.Vout)] {
+			slog.Debug("skipping frozen UTXO", "txid", u.TxID, "vout", u.Vout, "address", u.Address)
+			continue
+		}
+		if u.Value < sel.MinValueSats {
+			continue
+		}
+		selected = append(selected, u)
+	}
+
+	if sel.MaxInputs > 0 && len(selected) > sel.MaxInputs {
+		sort.SliceStable(selected, func(i, j int) bool {
+			return selected[i].Value > selected[j].Value
+		})
+		selected = selected[:sel.MaxInputs]
+	}
+
+	slog.Debug("BTC inputs selected",
+		"fetched", len(utxos),
+		"selected", len(selected),
+		"frozen", len(frozen),
+		"minValueSats", sel.MinValueSats,
+		"maxInputs", sel.MaxInputs,
+	)
+	return selected
+}
+
+// fetchSelectedUTXOs fetches the UTXOs of the addresses sel allows, confirmed
+// ones and, with sel.IncludeUnconfirmed, mempool ones too, then applies
+// SelectBTCInputs with the frozen outpoints from the database. sel.AddressIndices
+// name external-chain addresses, so change addresses are left out when it is set.
+// UTXOs of change addresses are flagged Change.
+func (s *BTCConsolidationService) fetchSelectedUTXOs(ctx context.Context, addresses []models.Address, sel models.BTCInputSelection) ([]models.UTXO, error) {
+	if len(sel.AddressIndices) > 0 {
+		wanted := make(map[int]bool, len(sel.AddressIndices))
+		for _, idx := range sel.AddressIndices {
+			wanted[idx] = true
+		}
+		var filtered []models.Address
+		for _, a := range addresses {
+			if wanted[a.AddressIndex] && !a.Change {
+				filtered = append(filtered, a)
+			}
+		}
+		addresses = filtered
+	}
+
+	utxos, err := s.utxoFetcher.FetchAllUTXOs(ctx, addresses)
+	if err != nil {
+		return nil, err
+	}
+	if sel.IncludeUnconfirmed {
+		for _, a := range addresses {
+			unconfirmed, err := s.utxoFetcher.FetchUnconfirmedUTXOs(ctx, a.Address, a.AddressIndex)
+			if err != nil {
+				return nil, fmt.Errorf("fetch unconfirmed UTXOs for address %s (index %d): %w", a.Address, a.AddressIndex, err)
+			}
+			utxos = append(utxos, unconfirmed...)
+		}
+	}
+
+	change := make(map[string]bool)
+	for _, a := range addresses {
+		if a.Change {
+			change[a.Address] = true
+		}
+	}
+	for i := range utxos {
+		utxos[i].Change = change[utxos[i].Address]
+	}
+
+	frozen, err := s.database.ListFrozenUTXOs()
+	if err != nil {
+		return nil, err
+	}
+	return SelectBTCInputs(utxos, sel, frozen), nil
+}
@@ -0,0 +1,95 @@
+package tx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/shared/scanner"
+)
+
+func TestSelectBTCInputs(t *testing.T) {
+	utxos := []models.UTXO{
+		{TxID: "a", Vout: 0, Value: 1000},
+		{TxID: "b", Vout: 1, Value: 90000},
+		{TxID: "c", Vout: 0, Value: 5000},
+		{TxID: "d", Vout: 2, Value: 70000},
+		{TxID: "e", Vout: 0, Value: 20000},
+	}
+	frozen := []models.FrozenUTXO{{TxID: "d", Vout: 2}, {TxID: "e", Vout: 1}}
+
+	got := SelectBTCInputs(utxos, models.BTCInputSelection{}, frozen)
+	if len(got) != 4 {
+		t.Fatalf("zero selection kept %d UTXOs, want every unfrozen one (4)", len(got))
+	}
+
+	got = SelectBTCInputs(utxos, models.BTCInputSelection{MinValueSats: 5000, MaxInputs: 2}, frozen)
+	if len(got) != 2 || got[0].TxID != "b" || got[1].TxID != "e" {
+		t.Errorf("SelectBTCInputs(min 5000, max 2) = %+v, want b and e", got)
+	}
+}
+
+func TestValidateBTCInputSelection(t *testing.T) {
+	if err := ValidateBTCInputSelection(models.BTCInputSelection{AddressIndices: []int{0, 4}, MinValueSats: 1, MaxInputs: 10}); err != nil {
+		t.Errorf("valid selection: error = %v", err)
+	}
+	for _, sel := range []models.BTCInputSelection{
+		{MinValueSats: -1},
+		{MaxInputs: -1},
+		{MaxInputs: config.BTCMaxInputsPerTx + 1},
+		{AddressIndices: []int{3, -2}},
+	} {
+		if err := ValidateBTCInputSelection(sel); !errors.Is(err, config.ErrInvalidInputSelection) {
+			t.Errorf("ValidateBTCInputSelection(%+v) error = %v, want ErrInvalidInputSelection", sel, err)
+		}
+	}
+}
+
+func TestFetchSelectedUTXOs(t *testing.T) {
+	esplora := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Every address holds one confirmed and one mempool UTXO.
+		addr := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/address/"), "/utxo")
+		json.NewEncoder(w).Encode([]map[string]any{
+			{"txid": addr + "-confirmed", "vout": 0, "value": 10000, "status": map[string]any{"confirmed": true}},
+			{"txid": addr + "-mempool", "vout": 0, "value": 20000, "status": map[string]any{"confirmed": false}},
+		})
+	}))
+	defer esplora.Close()
+
+	database := setupGasTestDB(t)
+	if _, err := database.FreezeUTXO("addr2-confirmed", 0, ""); err != nil {
+		t.Fatal(err)
+	}
+	fetcher := NewBTCUTXOFetcher(esplora.Client(), []string{esplora.URL}, []*scanner.RateLimiter{scanner.NewRateLimiter("test", 100, 0)})
+	svc := NewBTCConsolidationService(nil, fetcher, nil, nil, database, &chaincfg.TestNet3Params, esplora.Client(), nil, nil)
+
+	addresses := []models.Address{
+		{Chain: models.ChainBTC, AddressIndex: 1, Address: "addr1"},
+		{Chain: models.ChainBTC, AddressIndex: 2, Address: "addr2"},
+		{Chain: models.ChainBTC, AddressIndex: 3, Address: "addr3"},
+	}
+
+	got, err := svc.fetchSelectedUTXOs(context.Background(), addresses, models.BTCInputSelection{})
+	if err != nil {
+		t.Fatalf("fetchSelectedUTXOs() error = %v", err)
+	}
+	if len(got) != 2 || got[0].TxID != "addr1-confirmed" || got[1].TxID != "addr3-confirmed" {
+		t.Errorf("default selection = %+v, want the unfrozen confirmed UTXOs", got)
+	}
+
+	got, err = svc.fetchSelectedUTXOs(context.Background(), addresses, models.BTCInputSelection{AddressIndices: []int{2}, IncludeUnconfirmed: true})
+	if err != nil {
+		t.Fatalf("fetchSelectedUTXOs() error = %v", err)
+	}
+	if len(got) != 1 || got[0].TxID != "addr2-mempool" || got[0].Confirmed || got[0].AddressIndex != 2 {
+		t.Errorf("index 2 with unconfirmed = %+v, want only its mempool UTXO", got)
+	}
+}
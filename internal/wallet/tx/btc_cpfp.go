@@ -0,0 +1,224 @@
+package tx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/wallet/db"
+)
+
+// BTCCPFPParams contains the parameters for building a child-pays-for-parent
+// transaction.
+type BTCCPFPParams struct {
+	UTXOs          []models.UTXO // unconfirmed outputs of the parent
+	DestAddress    string
+	ParentVsize    int
+	ParentFeeSats  int64
+	PackageFeeRate int64 // sat/vB the parent and child must reach together
+	NetParams      *chaincfg.Params
+}
+
+// BuildBTCCPFPTx builds an unsigned child-pays-for-parent transaction: the
+// unconfirmed UTXOs of one parent spent to a single destination, paying enough
+// fee that parent and child together reach PackageFeeRate. Only the parent's own
+// size and fee are counted; unconfirmed ancestors of the parent are not.
+func BuildBTCCPFPTx(params BTCCPFPParams) (*BTCBuiltTx, error) {
+	if len(params.UTXOs) == 0 {
+		return nil, fmt.Errorf("%w: no UTXOs provided", config.ErrInsufficientUTXO)
+	}
+	if params.ParentVsize <= 0 || params.ParentFeeSats < 0 {
+		return nil, fmt.Errorf("invalid parent: vsize %d, fee %d sats", params.ParentVsize, params.ParentFeeSats)
+	}
+	if params.PackageFeeRate*int64(params.ParentVsize) <= params.ParentFeeSats {
+		return nil, fmt.Errorf("%w: the parent already pays %d sats for %d vB, at least %d sat/vB",
+			config.ErrFeeBumpTooLow, params.ParentFeeSats, params.ParentVsize, params.PackageFeeRate)
+	}
+
+	destScript, err := PKScriptFromAddress(params.DestAddress, params.NetParams)
+	if err != nil {
+		return nil, err
+	}
+	weight, err := EstimateBTCTxWeight(params.UTXOs, [][]byte{destScript})
+	if err != nil {
+		return nil, fmt.Errorf("estimate TX weight: %w", err)
+	}
+	childVsize := int64((weight + 3) / 4)
+
+	// The child pays for the whole package at the target rate, less what the
+	// parent already pays, and on its own at least the minimum relay rate. The fee
+	// is exact: no safety margin on top.
+	childFee := params.PackageFeeRate*(int64(params.ParentVsize)+childVsize) - params.ParentFeeSats
+	childFee = max(childFee, config.BTCMinFeeRate*childVsize)
+
+	slog.Info("BTC CPFP fee calculation",
+		"parentVsize", params.ParentVsize,
+		"parentFeeSats", params.ParentFeeSats,
+		"childVsize", childVsize,
+		"packageFeeRate", params.PackageFeeRate,
+		"childFeeSats", childFee,
+	)
+
+	return BuildBTCConsolidationTx(BTCBuildParams{
+		UTXOs:       params.UTXOs,
+		DestAddress: params.DestAddress,
+		FeeSats:     childFee,
+		NetParams:   params.NetParams,
+	})
+}
+
+// BumpFeeCPFP unsticks an unconfirmed transaction paying this wallet, whether an
+// underpaid incoming payment, a consolidation that cannot be replaced or a payout
+// with change, by spending its outputs to stored or change addresses to destAddr
+// with a child that lifts parent and child to feeRate (feeRate <= 0 uses the
+// estimator). The child is tracked like a consolidation under sweepID.
+func (s *BTCConsolidationService) BumpFeeCPFP(ctx context.Context, parentTxHash, destAddr string, feeRate int64, sweepID string) (*models.CPFPResult, error) {
+	slog.Info("BTC CPFP requested",
+		"parentTxHash", parentTxHash,
+		"destAddress", destAddr,
+		"requestedFeeRate", feeRate,
+		"sweepID", sweepID,
+	)
+	start := time.Now()
+
+	parent, err := fetchBTCTx(ctx, s.httpClient, s.confirmationURLs, parentTxHash)
+	if err != nil {
+		return nil, err
+	}
+	if parent.Status.Confirmed {
+		return nil, fmt.Errorf("%w: %s", config.ErrTxAlreadyConfirmed, parentTxHash)
+	}
+	parentVsize := (parent.Weight + 3) / 4
+	if parentVsize <= 0 {
+		return nil, fmt.Errorf("provider returned weight %d for %s", parent.Weight, parentTxHash)
+	}
+
+	utxos, err := s.cpfpInputs(ctx, parent)
+	if err != nil {
+		return nil, err
+	}
+
+	if feeRate <= 0 {
+		estimate, err := s.feeEstimator.EstimateFee(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("estimate fee: %w", err)
+		}
+		feeRate = DefaultFeeRate(estimate)
+	}
+
+	built, err := BuildBTCCPFPTx(BTCCPFPParams{
+		UTXOs:          utxos,
+		DestAddress:    destAddr,
+		ParentVsize:    parentVsize,
+		ParentFeeSats:  parent.Fee,
+		PackageFeeRate: feeRate,
+		NetParams:      s.netParams,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("build CPFP TX: %w", err)
+	}
+
+	signingUTXOs, err := s.prepareSigningUTXOs(ctx, built.UTXOs)
+	if err != nil {
+		for _, su := range signingUTXOs {
+			if su.PrivKey != nil {
+				su.PrivKey.Zero()
+			}
+		}
+		return nil, fmt.Errorf("prepare signing UTXOs: %w", err)
+	}
+	if err := SignBTCTx(built.Tx, signingUTXOs); err != nil {
+		return nil, fmt.Errorf("sign CPFP TX: %w", err)
+	}
+
+	txStateID := GenerateTxStateID()
+	if err := s.database.CreateTxState(db.TxStateRow{
+		ID:           txStateID,
+		SweepID:      sweepID,
+		Chain:        string(models.ChainBTC),
+		Token:        string(models.TokenNative),
+		AddressIndex: 0, // BTC consolidation is multi-input, no single index
+		FromAddress:  "consolidated",
+		ToAddress:    destAddr,
+		Amount:       strconv.FormatInt(built.OutputSats, 10),
+		Status:       config.TxStatePending,
+	}); err != nil {
+		slog.Error("failed to create BTC tx_state", "error", err)
+		// Non-blocking: continue even if tx_state write fails
+	}
+
+	result, err := s.broadcastSigned(ctx, txStateID, built, destAddr, start, 1, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("BTC CPFP broadcast",
+		"parentTxHash", parentTxHash,
+		"txHash", result.TxHash,
+		"parentFeeSats", parent.Fee,
+		"feeSats", built.FeeSats,
+		"packageFeeRate", feeRate,
+		"sweepID", sweepID,
+	)
+
+	return &models.CPFPResult{
+		SweepID:        sweepID,
+		TxHash:         result.TxHash,
+		ParentTxHash:   parentTxHash,
+		Chain:          models.ChainBTC,
+		InputCount:     len(built.UTXOs),
+		OutputSats:     built.OutputSats,
+		FeeSats:        built.FeeSats,
+		ParentFeeSats:  parent.Fee,
+		ParentVsize:    parentVsize,
+		PackageFeeRate: feeRate,
+	}, nil
+}
+
+// cpfpInputs returns the outputs of parent that pay stored or change addresses
+// and are still unspent, as the UTXO fetcher reports them from the mempool.
+func (s *BTCConsolidationService) cpfpInputs(ctx context.Context, parent *esploraTx) ([]models.UTXO, error) {
+	var utxos []models.UTXO
+	seen := make(map[string]bool)
+	for _, out := range parent.Vout {
+		if out.Address == "" || seen[out.Address] {
+			continue
+		}
+		seen[out.Address] = true
+
+		stored, err := s.database.LookupBTCAddress(out.Address)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("look up output address %s: %w", out.Address, err)
+		}
+
+		unconfirmed, err := s.utxoFetcher.FetchUnconfirmedUTXOs(ctx, out.Address, stored.AddressIndex)
+		if err != nil {
+			return nil, fmt.Errorf("fetch unconfirmed UTXOs for %s: %w", out.Address, err)
+		}
+		for _, u := range unconfirmed {
+			if u.TxID == parent.TxID {
+				u.Change = stored.Change
+				utxos = append(utxos, u)
+			}
+		}
+	}
+
+	if len(utxos) == 0 {
+		return nil, fmt.Errorf("%w: %s", config.ErrNoCPFPOutputs, parent.TxID)
+	}
+	if err := ClassifyUTXOs(utxos, s.netParams); err != nil {
+		return nil, err
+	}
+	return utxos, nil
+}
@@ -0,0 +1,258 @@
+package tx
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/shared/scanner"
+	"github.com/Fantasim/hdpay/internal/wallet/db"
+)
+
+// cpfpParentJSON describes an unconfirmed parent of 200 vB paying fee sats as the
+// Esplora /tx/{txid} endpoint would.
+func cpfpParentJSON(t *testing.T, txid string, fee int64, outputs []models.UTXO, confirmed bool) []byte {
+	t.Helper()
+	vout := make([]map[string]any, len(outputs))
+	for i, u := range outputs {
+		vout[i] = map[string]any{"scriptpubkey_address": u.Address, "value": u.Value}
+	}
+	raw, err := json.Marshal(map[string]any{
+		"txid":   txid,
+		"weight": 800,
+		"fee":    fee,
+		"vin":    []any{},
+		"vout":   vout,
+		"status": map[string]any{"confirmed": confirmed},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+// newCPFPTestService returns a BTC service whose Esplora stub serves parent for
+// txid and reports outputs as unconfirmed UTXOs of their addresses, next to a
+// confirmed and an unrelated unconfirmed UTXO. Only the addresses of stored are
+// in the database.
+func newCPFPTestService(t *testing.T, txid string, parent []byte, outputs, stored []models.UTXO) (*BTCConsolidationService, *recordingBroadcaster, *db.DB) {
+	t.Helper()
+	net := &chaincfg.TestNet3Params
+	database := setupGasTestDB(t)
+
+	addrs := make([]models.Address, len(stored))
+	for i, u := range stored {
+		addrs[i] = models.Address{Chain: models.ChainBTC, AddressIndex: u.AddressIndex, Address: u.Address}
+	}
+	if err := database.InsertAddressBatch(models.ChainBTC, addrs); err != nil {
+		t.Fatal(err)
+	}
+
+	utxoLists := make(map[string][]map[string]any)
+	for i, u := range outputs {
+		utxoLists["/address/"+u.Address+"/utxo"] = []map[string]any{
+			{"txid": txid, "vout": i, "value": u.Value, "status": map[string]any{"confirmed": false}},
+			{"txid": strings.Repeat("cd", 32), "vout": 0, "value": 7000, "status": map[string]any{"confirmed": false}},
+			{"txid": strings.Repeat("ef", 32), "vout": 1, "value": 9000, "status": map[string]any{"confirmed": true, "block_height": 100}},
+		}
+	}
+
+	esplora := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/status"):
+			w.Write([]byte(`{"confirmed":true}`))
+		case r.URL.Path == "/tx/"+txid:
+			w.Write(parent)
+		case utxoLists[r.URL.Path] != nil:
+			json.NewEncoder(w).Encode(utxoLists[r.URL.Path])
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(esplora.Close)
+
+	fetcher := NewBTCUTXOFetcher(esplora.Client(), []string{esplora.URL}, []*scanner.RateLimiter{scanner.NewRateLimiter("test", 100, 0)})
+	ks := NewKeyService(writeTempMnemonic(t, testMnemonic24), "testnet")
+	broadcaster := &recordingBroadcaster{}
+	svc := NewBTCConsolidationService(ks, fetcher, nil, broadcaster, database, net, esplora.Client(), []string{esplora.URL}, nil)
+	return svc, broadcaster, database
+}
+
+func TestBuildBTCCPFPTx_LiftsPackageRate(t *testing.T) {
+	utxos := psbtTestConsolidation(t).UTXOs[:2]
+	params := BTCCPFPParams{
+		UTXOs:          utxos,
+		DestAddress:    utxos[0].Address,
+		ParentVsize:    200,
+		ParentFeeSats:  200,
+		PackageFeeRate: 12,
+		NetParams:      &chaincfg.TestNet3Params,
+	}
+
+	built, err := BuildBTCCPFPTx(params)
+	if err != nil {
+		t.Fatalf("BuildBTCCPFPTx() error = %v", err)
+	}
+	// The child pays exactly what lifts the package to the target rate.
+	packageVsize := int64(params.ParentVsize + built.EstimatedVsize)
+	childFee := params.PackageFeeRate*packageVsize - params.ParentFeeSats
+	if built.FeeSats != childFee {
+		t.Errorf("child fee = %d sats, want %d", built.FeeSats, childFee)
+	}
+	if built.TotalInputSats-built.OutputSats != built.FeeSats {
+		t.Errorf("child spends %d sats into %d, fee %d", built.TotalInputSats, built.OutputSats, built.FeeSats)
+	}
+
+	// A parent already paying the target needs no child.
+	params.PackageFeeRate = 1
+	if _, err := BuildBTCCPFPTx(params); !errors.Is(err, config.ErrFeeBumpTooLow) {
+		t.Errorf("BuildBTCCPFPTx(rate 1) error = %v, want ErrFeeBumpTooLow", err)
+	}
+}
+
+func TestBumpFeeCPFP_SpendsParentOutputs(t *testing.T) {
+	ours := psbtTestConsolidation(t).UTXOs
+	parentTxID := chainhash.HashH([]byte("stuck parent")).String()
+
+	// The parent pays two stored addresses and one address this wallet does not know.
+	outputs := []models.UTXO{
+		{Address: ours[1].Address, Value: 30000},
+		{Address: ours[3].Address, Value: 50000},
+		{Address: ours[2].Address, Value: 20000},
+	}
+	stored := []models.UTXO{ours[1], ours[2]}
+	parent := cpfpParentJSON(t, parentTxID, 200, outputs, false)
+	svc, broadcaster, database := newCPFPTestService(t, parentTxID, parent, outputs, stored)
+
+	result, err := svc.BumpFeeCPFP(context.Background(), parentTxID, ours[0].Address, 15, "sweep-cpfp")
+	if err != nil {
+		t.Fatalf("BumpFeeCPFP() error = %v", err)
+	}
+	if result.TxHash != "psbt-txhash" || result.ParentTxHash != parentTxID || result.SweepID != "sweep-cpfp" ||
+		result.InputCount != 2 || result.ParentVsize != 200 || result.ParentFeeSats != 200 ||
+		result.OutputSats != 50000-result.FeeSats {
+		t.Errorf("BumpFeeCPFP() = %+v", result)
+	}
+
+	if len(broadcaster.sent) != 1 {
+		t.Fatalf("broadcast %d transactions, want 1", len(broadcaster.sent))
+	}
+	raw, err := hex.DecodeString(broadcaster.sent[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var child wire.MsgTx
+	if err := child.Deserialize(bytes.NewReader(raw)); err != nil {
+		t.Fatal(err)
+	}
+	if len(child.TxIn) != 2 || len(child.TxOut) != 1 {
+		t.Fatalf("child has %d inputs and %d outputs, want 2 and 1", len(child.TxIn), len(child.TxOut))
+	}
+	wantVouts := []uint32{0, 2}
+	for i, in := range child.TxIn {
+		if in.PreviousOutPoint.Hash.String() != parentTxID || in.PreviousOutPoint.Index != wantVouts[i] {
+			t.Errorf("child input %d spends %v, want %s:%d", i, in.PreviousOutPoint, parentTxID, wantVouts[i])
+		}
+		if len(in.Witness) == 0 && len(in.SignatureScript) == 0 {
+			t.Errorf("child input %d is not signed", i)
+		}
+	}
+
+	rows, err := database.GetTxStatesBySweepID("sweep-cpfp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].ToAddress != ours[0].Address {
+		t.Errorf("tx_state rows = %+v, want the child to %s", rows, ours[0].Address)
+	}
+}
+
+func TestBumpFeeCPFP_Rejects(t *testing.T) {
+	ours := psbtTestConsolidation(t).UTXOs
+	parentTxID := chainhash.HashH([]byte("stuck parent")).String()
+	outputs := []models.UTXO{{Address: ours[1].Address, Value: 30000}}
+
+	// An outgoing payment with nothing left for this wallet cannot be bumped by a child.
+	svc, broadcaster, _ := newCPFPTestService(t, parentTxID, cpfpParentJSON(t, parentTxID, 200, outputs, false), outputs, nil)
+	if _, err := svc.BumpFeeCPFP(context.Background(), parentTxID, ours[0].Address, 15, "s"); !errors.Is(err, config.ErrNoCPFPOutputs) {
+		t.Errorf("foreign outputs: error = %v, want ErrNoCPFPOutputs", err)
+	}
+	if len(broadcaster.sent) != 0 {
+		t.Errorf("rejected CPFP broadcast %d transactions", len(broadcaster.sent))
+	}
+
+	svc, _, _ = newCPFPTestService(t, parentTxID, cpfpParentJSON(t, parentTxID, 200, outputs, true), outputs, []models.UTXO{ours[1]})
+	if _, err := svc.BumpFeeCPFP(context.Background(), parentTxID, ours[0].Address, 15, "s"); !errors.Is(err, config.ErrTxAlreadyConfirmed) {
+		t.Errorf("confirmed parent: error = %v, want ErrTxAlreadyConfirmed", err)
+	}
+
+	// The parent pays 5 sat/vB already.
+	svc, broadcaster, _ = newCPFPTestService(t, parentTxID, cpfpParentJSON(t, parentTxID, 1000, outputs, false), outputs, []models.UTXO{ours[1]})
+	if _, err := svc.BumpFeeCPFP(context.Background(), parentTxID, ours[0].Address, 5, "s"); !errors.Is(err, config.ErrFeeBumpTooLow) {
+		t.Errorf("low target: error = %v, want ErrFeeBumpTooLow", err)
+	}
+	if _, err := svc.BumpFeeCPFP(context.Background(), strings.Repeat("ab", 32), ours[0].Address, 15, "s"); !errors.Is(err, config.ErrTxNotFound) {
+		t.Errorf("unknown parent: error = %v, want ErrTxNotFound", err)
+	}
+	if len(broadcaster.sent) != 0 {
+		t.Errorf("rejected CPFP broadcast %d transactions", len(broadcaster.sent))
+	}
+}
+
+func TestBumpFeeCPFP_SpendsPayoutChange(t *testing.T) {
+	ours := psbtTestConsolidation(t).UTXOs
+	parentTxID := chainhash.HashH([]byte("stuck payout")).String()
+	ks := NewKeyService(writeTempMnemonic(t, testMnemonic24), "testnet")
+	changeAddr, err := ks.DeriveBTCChangeAddress(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A payout to a foreign address whose change went to internal-chain index 0.
+	outputs := []models.UTXO{
+		{Address: ours[3].Address, Value: 30000},
+		{Address: changeAddr, Value: 50000},
+	}
+	svc, broadcaster, database := newCPFPTestService(t, parentTxID, cpfpParentJSON(t, parentTxID, 200, outputs, false), outputs, nil)
+	if err := database.RecordBTCChangeAddress(db.BTCChangeAddressRow{
+		AddressType: string(models.BTCAddressP2WPKH), ChangeIndex: 0, Address: changeAddr, TxHash: parentTxID, AmountSats: 50000,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := svc.BumpFeeCPFP(context.Background(), parentTxID, ours[0].Address, 15, "sweep-cpfp")
+	if err != nil {
+		t.Fatalf("BumpFeeCPFP() error = %v", err)
+	}
+	if result.InputCount != 1 || result.OutputSats != 50000-result.FeeSats {
+		t.Errorf("BumpFeeCPFP() = %+v, want the change output alone", result)
+	}
+
+	// The change input is signed with the internal-chain key.
+	raw, err := hex.DecodeString(broadcaster.sent[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var child wire.MsgTx
+	if err := child.Deserialize(bytes.NewReader(raw)); err != nil {
+		t.Fatal(err)
+	}
+	if len(child.TxIn) != 1 || child.TxIn[0].PreviousOutPoint.Index != 1 || len(child.TxIn[0].Witness) == 0 {
+		t.Errorf("child inputs = %+v, want the signed change output %s:1", child.TxIn, parentTxID)
+	}
+	if _, total, err := database.ListTransactions(nil, 1, 10); err != nil || total != 0 {
+		t.Errorf("CPFP of change wrote %d transactions rows, %v, want none", total, err)
+	}
+}
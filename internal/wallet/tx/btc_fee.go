@@ -1,11 +1,15 @@
 package tx
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"math"
 	"net/http"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 
@@ -13,29 +17,99 @@ import (
 	"github.com/Fantasim/hdpay/internal/shared/models"
 )
 
-// BTCFeeEstimator fetches dynamic fee rates from mempool.space.
-// Results are cached with a short TTL to avoid hammering the API on repeated previews.
+// Fee sources, as named in FeeQuote and FeeSourceStatus.
+const (
+	feeSourceMempool  = "mempool"
+	feeSourceEsplora  = "esplora"
+	feeSourceBitcoind = "bitcoind"
+)
+
+// btcFeeTarget is a confirmation target of the estimate, with the mempool.space
+// recommendation that stands for it.
+type btcFeeTarget struct {
+	name    string
+	blocks  int
+	mempool func(*models.FeeEstimate) int64
+}
+
+// btcFeeTargets lists the confirmation targets from fastest to slowest; the
+// FeeEstimate tiers are filled in this order.
+var btcFeeTargets = []btcFeeTarget{
+	{"next_block", 1, func(e *models.FeeEstimate) int64 { return e.FastestFee }},
+	{"3_blocks", 3, func(e *models.FeeEstimate) int64 { return e.HalfHourFee }},
+	{"6_blocks", 6, func(e *models.FeeEstimate) int64 { return e.HourFee }},
+	{"24_blocks", 24, func(e *models.FeeEstimate) int64 { return e.EconomyFee }},
+	{"economy", 144, func(e *models.FeeEstimate) int64 { return e.MinimumFee }},
+}
+
+// feeFetcher fetches one source's quotes, indexed like btcFeeTargets.
+type feeFetcher struct {
+	source string
+	fetch  func(context.Context) ([]float64, error)
+}
+
+// feeSourceResult is one source's quotes, indexed like btcFeeTargets; a zero
+// quote means the source had no rate for that target.
+type feeSourceResult struct {
+	source string
+	quotes []float64
+	err    error
+}
+
+// BTCFeeEstimator estimates BTC fee rates per confirmation target from
+// mempool.space and, when configured, an Esplora /fee-estimates endpoint and a
+// Bitcoin Core estimatesmartfee RPC. Each target takes the median of the quotes
+// within the sanity bounds. Results are cached with a short TTL to avoid
+// hammering the APIs on repeated previews.
 type BTCFeeEstimator struct {
-	client     *http.Client
-	mempoolURL string // Base URL, e.g. "https://mempool.space/api"
+	client      *http.Client
+	mempoolURL  string // Base URL, e.g. "https://mempool.space/api"
+	esploraURL  string // Base URL, e.g. "https://blockstream.info/api"; empty = not queried
+	coreRPCURL  string // bitcoind JSON-RPC URL, may carry user:password; empty = not queried
+	fallbackFee int64  // sat/vB used when no source answers
 
-	mu          sync.RWMutex
-	cached      *models.FeeEstimate
-	cachedAt    time.Time
+	mu       sync.RWMutex
+	cached   *models.FeeEstimate
+	cachedAt time.Time
 }
 
 // NewBTCFeeEstimator creates a fee estimator using mempool.space API.
 func NewBTCFeeEstimator(client *http.Client, mempoolURL string) *BTCFeeEstimator {
 	slog.Info("BTC fee estimator created", "mempoolURL", mempoolURL)
 	return &BTCFeeEstimator{
-		client:     client,
-		mempoolURL: mempoolURL,
+		client:      client,
+		mempoolURL:  mempoolURL,
+		fallbackFee: int64(config.BTCDefaultFeeRate),
 	}
 }
 
-// EstimateFee fetches all fee tiers from mempool.space.
-// Returns a cached result if available and within the TTL.
-// Falls back to default constant values if the API is unreachable.
+// SetEsploraURL adds an Esplora API (its /fee-estimates endpoint) as a fee source.
+// Must be called before the estimator is shared.
+func (fe *BTCFeeEstimator) SetEsploraURL(url string) {
+	fe.esploraURL = url
+	slog.Info("BTC fee estimator source added", "source", feeSourceEsplora, "url", url)
+}
+
+// SetCoreRPCURL adds a Bitcoin Core node (estimatesmartfee over JSON-RPC) as a
+// fee source. Credentials may be given in the URL. Must be called before the
+// estimator is shared.
+func (fe *BTCFeeEstimator) SetCoreRPCURL(url string) {
+	fe.coreRPCURL = url
+	slog.Info("BTC fee estimator source added", "source", feeSourceBitcoind)
+}
+
+// SetFallbackFeeRate sets the medium-priority rate used when no source answers.
+// Zero keeps the built-in default. Must be called before the estimator is shared.
+func (fe *BTCFeeEstimator) SetFallbackFeeRate(satPerVB int64) {
+	if satPerVB > 0 {
+		fe.fallbackFee = satPerVB
+	}
+}
+
+// EstimateFee queries every configured source and takes, for each confirmation
+// target, the median of the sane quotes; the estimate lists every quote and source
+// error. Returns a cached result if available and within the TTL.
+// Falls back to the configured default rate if no source answers.
 func (fe *BTCFeeEstimator) EstimateFee(ctx context.Context) (*models.FeeEstimate, error) {
 	// Check cache first.
 	fe.mu.RLock()
@@ -47,13 +121,16 @@ func (fe *BTCFeeEstimator) EstimateFee(ctx context.Context) (*models.FeeEstimate
 	}
 	fe.mu.RUnlock()
 
-	estimate, err := fe.fetchFromAPI(ctx)
-	if err != nil {
-		slog.Warn("fee estimation API failed, using default",
-			"error", err,
-			"defaultFeeRate", config.BTCDefaultFeeRate,
+	results := fe.querySources(ctx)
+	estimate := aggregateFeeQuotes(results)
+	if estimate == nil {
+		slog.Warn("fee estimation failed on every source, using default",
+			"sources", len(results),
+			"defaultFeeRate", fe.fallbackFee,
 		)
-		return fe.defaultEstimate(), nil
+		estimate = fe.defaultEstimate()
+		estimate.Sources = feeSourceStatuses(results)
+		return estimate, nil
 	}
 
 	// Enforce minimum fee rate on all tiers.
@@ -71,6 +148,7 @@ func (fe *BTCFeeEstimator) EstimateFee(ctx context.Context) (*models.FeeEstimate
 		"hourFee", estimate.HourFee,
 		"economyFee", estimate.EconomyFee,
 		"minimumFee", estimate.MinimumFee,
+		"sources", len(results),
 	)
 
 	return estimate, nil
@@ -81,47 +159,281 @@ func DefaultFeeRate(estimate *models.FeeEstimate) int64 {
 	return estimate.HalfHourFee
 }
 
-// fetchFromAPI queries the mempool.space fee recommendation endpoint.
-func (fe *BTCFeeEstimator) fetchFromAPI(ctx context.Context) (*models.FeeEstimate, error) {
+// ValidateFeeTarget checks that target is empty or names one of the confirmation
+// targets of the estimate.
+func ValidateFeeTarget(target string) error {
+	if target == "" {
+		return nil
+	}
+	names := make([]string, len(btcFeeTargets))
+	for i, t := range btcFeeTargets {
+		if t.name == target {
+			return nil
+		}
+		names[i] = t.name
+	}
+	return fmt.Errorf("%w: %q, want one of %v", config.ErrInvalidFeeTarget, target, names)
+}
+
+// FeeRateForTarget returns the fee rate estimate gives for the confirmation
+// target, and the target it used: config.BTCDefaultFeeTarget when target is empty.
+// An estimate without per-target rates falls back to the matching tier.
+func FeeRateForTarget(estimate *models.FeeEstimate, target string) (int64, string, error) {
+	if err := ValidateFeeTarget(target); err != nil {
+		return 0, "", err
+	}
+	if target == "" {
+		target = config.BTCDefaultFeeTarget
+	}
+	for _, t := range estimate.Targets {
+		if t.Target == target {
+			return t.FeeRate, target, nil
+		}
+	}
+	for _, t := range btcFeeTargets {
+		if t.name == target {
+			return t.mempool(estimate), target, nil
+		}
+	}
+	return 0, "", fmt.Errorf("%w: %q", config.ErrInvalidFeeTarget, target)
+}
+
+// querySources fetches the quotes of every configured source concurrently.
+func (fe *BTCFeeEstimator) querySources(ctx context.Context) []feeSourceResult {
 	ctx, cancel := context.WithTimeout(ctx, config.FeeEstimateTimeout)
 	defer cancel()
 
-	url := fe.mempoolURL + config.MempoolFeeEstimatePath
+	fetchers := []feeFetcher{{feeSourceMempool, fe.fetchMempool}}
+	if fe.esploraURL != "" {
+		fetchers = append(fetchers, feeFetcher{feeSourceEsplora, fe.fetchEsplora})
+	}
+	if fe.coreRPCURL != "" {
+		fetchers = append(fetchers, feeFetcher{feeSourceBitcoind, fe.fetchBitcoind})
+	}
+
+	results := make([]feeSourceResult, len(fetchers))
+	var wg sync.WaitGroup
+	for i, f := range fetchers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			quotes, err := f.fetch(ctx)
+			if err != nil {
+				slog.Warn("fee source failed", "source", f.source, "error", err)
+			}
+			results[i] = feeSourceResult{source: f.source, quotes: quotes, err: err}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// fetchMempool queries the mempool.space fee recommendation endpoint.
+func (fe *BTCFeeEstimator) fetchMempool(ctx context.Context) ([]float64, error) {
+	var estimate models.FeeEstimate
+	if err := fe.getJSON(ctx, fe.mempoolURL+config.MempoolFeeEstimatePath, &estimate); err != nil {
+		return nil, err
+	}
+
+	quotes := make([]float64, len(btcFeeTargets))
+	for i, target := range btcFeeTargets {
+		quotes[i] = float64(target.mempool(&estimate))
+	}
+	return quotes, nil
+}
+
+// fetchEsplora queries an Esplora /fee-estimates endpoint, which maps confirmation
+// targets in blocks to sat/vB.
+func (fe *BTCFeeEstimator) fetchEsplora(ctx context.Context) ([]float64, error) {
+	var rates map[string]float64
+	if err := fe.getJSON(ctx, fe.esploraURL+config.EsploraFeeEstimatePath, &rates); err != nil {
+		return nil, err
+	}
+
+	quotes := make([]float64, len(btcFeeTargets))
+	for i, target := range btcFeeTargets {
+		quotes[i] = rates[strconv.Itoa(target.blocks)]
+	}
+	return quotes, nil
+}
+
+// fetchBitcoind calls estimatesmartfee once per confirmation target. A target the
+// node has no data for is left without a quote.
+func (fe *BTCFeeEstimator) fetchBitcoind(ctx context.Context) ([]float64, error) {
+	quotes := make([]float64, len(btcFeeTargets))
+	for i, target := range btcFeeTargets {
+		body, err := json.Marshal(map[string]any{
+			"jsonrpc": "1.0",
+			"id":      "hdpay",
+			"method":  "estimatesmartfee",
+			"params":  []int{target.blocks},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("encode estimatesmartfee request: %w", err)
+		}
 
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, fe.coreRPCURL, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("create estimatesmartfee request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		var resp struct {
+			Result struct {
+				FeeRate float64  `json:"feerate"` // BTC/kvB
+				Errors  []string `json:"errors"`
+			} `json:"result"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := fe.doJSON(req, &resp); err != nil {
+			return nil, err
+		}
+		if resp.Error != nil {
+			return nil, fmt.Errorf("%w: estimatesmartfee: %s", config.ErrFeeEstimateFailed, resp.Error.Message)
+		}
+		quotes[i] = resp.Result.FeeRate * config.BTCSatsPerBTCKvB
+	}
+	return quotes, nil
+}
+
+// getJSON fetches url and decodes its JSON body into v.
+func (fe *BTCFeeEstimator) getJSON(ctx context.Context, url string, v any) error {
 	slog.Debug("fetching fee estimate", "url", url)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("create fee request: %w", err)
+		return fmt.Errorf("create fee request: %w", err)
 	}
+	return fe.doJSON(req, v)
+}
 
+// doJSON sends req and decodes its JSON response body into v.
+func (fe *BTCFeeEstimator) doJSON(req *http.Request, v any) error {
 	resp, err := fe.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %s", config.ErrFeeEstimateFailed, err)
+		return fmt.Errorf("%w: %s", config.ErrFeeEstimateFailed, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("%w: HTTP %d", config.ErrFeeEstimateFailed, resp.StatusCode)
+		return fmt.Errorf("%w: HTTP %d", config.ErrFeeEstimateFailed, resp.StatusCode)
 	}
 
-	var estimate models.FeeEstimate
-	if err := json.NewDecoder(resp.Body).Decode(&estimate); err != nil {
-		return nil, fmt.Errorf("decode fee response: %w", err)
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decode fee response: %w", err)
+	}
+	return nil
+}
+
+// aggregateFeeQuotes builds the estimate from the sources' quotes: each target
+// takes the median of its quotes within (0, BTCMaxSaneFeeRate], rounded up. A
+// target without any quote takes the rate of the nearest slower target (or faster,
+// if none is slower), and a slower target never costs more than a faster one.
+// Returns nil when no target has a quote.
+func aggregateFeeQuotes(results []feeSourceResult) *models.FeeEstimate {
+	targets := make([]models.FeeTargetEstimate, len(btcFeeTargets))
+	rates := make([]int64, len(btcFeeTargets))
+	quoted := false
+	for i, target := range btcFeeTargets {
+		targets[i] = models.FeeTargetEstimate{Target: target.name, Blocks: target.blocks, Quotes: []models.FeeQuote{}}
+
+		var sane []float64
+		for _, r := range results {
+			if r.err != nil || i >= len(r.quotes) || r.quotes[i] == 0 {
+				continue
+			}
+			q := r.quotes[i]
+			used := q > 0 && q <= config.BTCMaxSaneFeeRate
+			targets[i].Quotes = append(targets[i].Quotes, models.FeeQuote{Source: r.source, FeeRate: q, Used: used})
+			if used {
+				sane = append(sane, q)
+			}
+		}
+		if len(sane) > 0 {
+			rates[i] = int64(math.Ceil(median(sane)))
+			quoted = true
+		}
+	}
+	if !quoted {
+		return nil
+	}
+
+	// Fill targets without quotes, preferring the next slower target.
+	for i := len(rates) - 2; i >= 0; i-- {
+		if rates[i] == 0 {
+			rates[i] = rates[i+1]
+		}
+	}
+	for i := 1; i < len(rates); i++ {
+		if rates[i] == 0 {
+			rates[i] = rates[i-1]
+		}
+	}
+	// A faster target never costs less than a slower one.
+	for i := len(rates) - 2; i >= 0; i-- {
+		rates[i] = max(rates[i], rates[i+1])
+	}
+
+	for i := range targets {
+		targets[i].FeeRate = rates[i]
+	}
+	return &models.FeeEstimate{
+		FastestFee:  rates[0],
+		HalfHourFee: rates[1],
+		HourFee:     rates[2],
+		EconomyFee:  rates[3],
+		MinimumFee:  rates[4],
+		Targets:     targets,
+		Sources:     feeSourceStatuses(results),
 	}
+}
 
-	return &estimate, nil
+// median returns the median of values, averaging the middle two of an even count.
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
 }
 
-// defaultEstimate returns a conservative fallback fee estimate.
+// feeSourceStatuses reports which sources answered.
+func feeSourceStatuses(results []feeSourceResult) []models.FeeSourceStatus {
+	statuses := make([]models.FeeSourceStatus, len(results))
+	for i, r := range results {
+		statuses[i] = models.FeeSourceStatus{Source: r.source}
+		if r.err != nil {
+			statuses[i].Error = r.err.Error()
+		}
+	}
+	return statuses
+}
+
+// defaultEstimate returns a conservative fallback fee estimate around the
+// configured default rate.
 func (fe *BTCFeeEstimator) defaultEstimate() *models.FeeEstimate {
-	return &models.FeeEstimate{
-		FastestFee:  int64(config.BTCDefaultFeeRate) * 2,
-		HalfHourFee: int64(config.BTCDefaultFeeRate),
-		HourFee:     int64(config.BTCDefaultFeeRate),
+	estimate := &models.FeeEstimate{
+		FastestFee:  fe.fallbackFee * 2,
+		HalfHourFee: fe.fallbackFee,
+		HourFee:     fe.fallbackFee,
 		EconomyFee:  int64(config.BTCMinFeeRate),
 		MinimumFee:  int64(config.BTCMinFeeRate),
+		Fallback:    true,
 	}
+	estimate.Targets = make([]models.FeeTargetEstimate, len(btcFeeTargets))
+	for i, target := range btcFeeTargets {
+		estimate.Targets[i] = models.FeeTargetEstimate{
+			Target:  target.name,
+			Blocks:  target.blocks,
+			FeeRate: target.mempool(estimate),
+			Quotes:  []models.FeeQuote{},
+		}
+	}
+	return estimate
 }
 
 // enforceMinimum ensures no fee rate is below the minimum.
@@ -142,4 +454,9 @@ func (fe *BTCFeeEstimator) enforceMinimum(est *models.FeeEstimate) {
 	if est.MinimumFee < min {
 		est.MinimumFee = min
 	}
+	for i := range est.Targets {
+		if est.Targets[i].FeeRate < min {
+			est.Targets[i].FeeRate = min
+		}
+	}
 }
@@ -3,8 +3,10 @@ package tx
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/Fantasim/hdpay/internal/shared/config"
@@ -116,7 +118,7 @@ func TestBTCFeeEstimator_MalformedJSON(t *testing.T) {
 
 	estimator := NewBTCFeeEstimator(server.Client(), server.URL)
 
-	// Malformed JSON causes fetchFromAPI to fail, so EstimateFee should
+	// Malformed JSON causes fetchMempool to fail, so EstimateFee should
 	// fall back to the default estimate (no error returned).
 	estimate, err := estimator.EstimateFee(context.Background())
 	if err != nil {
@@ -143,3 +145,117 @@ func TestDefaultFeeRate(t *testing.T) {
 		t.Errorf("DefaultFeeRate() = %d, want 15 (halfHourFee)", rate)
 	}
 }
+
+func TestFeeRateForTarget(t *testing.T) {
+	estimate := &models.FeeEstimate{
+		FastestFee:  20,
+		HalfHourFee: 15,
+		HourFee:     10,
+		EconomyFee:  5,
+		MinimumFee:  1,
+		Targets:     []models.FeeTargetEstimate{{Target: "next_block", Blocks: 1, FeeRate: 22}},
+	}
+
+	for target, want := range map[string]struct {
+		rate int64
+		used string
+	}{
+		"":           {15, config.BTCDefaultFeeTarget},
+		"next_block": {22, "next_block"}, // per-target rate wins over the tier
+		"6_blocks":   {10, "6_blocks"},
+		"economy":    {1, "economy"},
+	} {
+		rate, used, err := FeeRateForTarget(estimate, target)
+		if err != nil || rate != want.rate || used != want.used {
+			t.Errorf("FeeRateForTarget(%q) = %d, %q, %v, want %d, %q", target, rate, used, err, want.rate, want.used)
+		}
+	}
+
+	if _, _, err := FeeRateForTarget(estimate, "2_blocks"); !errors.Is(err, config.ErrInvalidFeeTarget) {
+		t.Errorf("FeeRateForTarget(2_blocks) error = %v, want ErrInvalidFeeTarget", err)
+	}
+}
+
+func TestBTCFeeEstimator_MedianOfSources(t *testing.T) {
+	mempool := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(models.FeeEstimate{FastestFee: 30, HalfHourFee: 20, HourFee: 12, EconomyFee: 5, MinimumFee: 2})
+	}))
+	defer mempool.Close()
+
+	esplora := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != config.EsploraFeeEstimatePath {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		// 6 blocks is insane and must be ignored; 144 blocks is missing.
+		w.Write([]byte(`{"1": 40.5, "3": 18.2, "6": 90000, "24": 4.1}`))
+	}))
+	defer esplora.Close()
+
+	bitcoind := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "rpc" || pass != "secret" {
+			t.Errorf("bitcoind credentials = %q:%q, want rpc:secret", user, pass)
+		}
+		var req struct {
+			Method string `json:"method"`
+			Params []int  `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Method != "estimatesmartfee" {
+			t.Errorf("bitcoind request = %+v, %v", req, err)
+		}
+		// BTC/kvB: 0.0002 = 20 sat/vB for every target.
+		w.Write([]byte(`{"result": {"feerate": 0.0002, "blocks": 2}, "error": null}`))
+	}))
+	defer bitcoind.Close()
+
+	estimator := NewBTCFeeEstimator(mempool.Client(), mempool.URL)
+	estimator.SetEsploraURL(esplora.URL)
+	estimator.SetCoreRPCURL(strings.Replace(bitcoind.URL, "http://", "http://rpc:secret@", 1))
+
+	estimate, err := estimator.EstimateFee(context.Background())
+	if err != nil {
+		t.Fatalf("EstimateFee() error = %v", err)
+	}
+
+	// next block: median(30, 40.5, 20) = 30; 3 blocks: median(20, 18.2, 20) = 20;
+	// 6 blocks: median(12, 20) = 16; economy: median(2, 20) = 11;
+	// 24 blocks: median(5, 4.1, 20) = 5, raised to the slower economy rate.
+	want := []int64{30, 20, 16, 11, 11}
+	got := []int64{estimate.FastestFee, estimate.HalfHourFee, estimate.HourFee, estimate.EconomyFee, estimate.MinimumFee}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("tiers = %v, want %v", got, want)
+			break
+		}
+	}
+	if estimate.Fallback || len(estimate.Sources) != 3 || len(estimate.Targets) != 5 {
+		t.Fatalf("estimate = %+v, want 3 answering sources and 5 targets", estimate)
+	}
+	for _, q := range estimate.Targets[2].Quotes {
+		if q.Source == "esplora" && q.Used {
+			t.Error("insane esplora quote for 6 blocks was used")
+		}
+	}
+}
+
+func TestBTCFeeEstimator_FallbackFeeRate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	estimator := NewBTCFeeEstimator(server.Client(), server.URL)
+	estimator.SetEsploraURL(server.URL)
+	estimator.SetFallbackFeeRate(25)
+
+	estimate, err := estimator.EstimateFee(context.Background())
+	if err != nil {
+		t.Fatalf("EstimateFee() error = %v", err)
+	}
+	if !estimate.Fallback || estimate.HalfHourFee != 25 {
+		t.Errorf("estimate = %+v, want the configured fallback of 25 sat/vB", estimate)
+	}
+	if len(estimate.Sources) != 2 || estimate.Sources[0].Error == "" || estimate.Sources[1].Error == "" {
+		t.Errorf("sources = %+v, want both failures reported", estimate.Sources)
+	}
+}
@@ -0,0 +1,453 @@
+package tx
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/wallet/db"
+)
+
+// BTCPayoutParams contains the parameters for building a BTC payout.
+type BTCPayoutParams struct {
+	UTXOs         []models.UTXO // candidate inputs, spent largest first
+	Recipients    []models.PayoutRecipient
+	ChangeAddress string
+	FeeRate       int64 // sat/vB
+	NetParams     *chaincfg.Params
+}
+
+// BTCPayoutTx contains the result of building (but not signing) a payout.
+type BTCPayoutTx struct {
+	Tx             *wire.MsgTx
+	UTXOs          []models.UTXO // the inputs spent, in input order
+	TotalInputSats int64
+	PayoutSats     int64
+	ChangeSats     int64 // 0 when the change would be dust and goes to the fee
+	FeeSats        int64
+	EstimatedVsize int
+}
+
+// ValidatePayoutRecipients checks that there are between one and
+// config.BTCMaxPayoutRecipients recipients, each with an address of netParams and
+// an amount above the dust threshold.
+func ValidatePayoutRecipients(recipients []models.PayoutRecipient, netParams *chaincfg.Params) error {
+	if len(recipients) == 0 || len(recipients) > config.BTCMaxPayoutRecipients {
+		return fmt.Errorf("%w: between 1 and %d recipients are required", config.ErrInvalidPayout, config.BTCMaxPayoutRecipients)
+	}
+	for i, r := range recipients {
+		addr, err := btcutil.DecodeAddress(r.Address, netParams)
+		if err != nil || !addr.IsForNet(netParams) {
+			return fmt.Errorf("%w: recipient %d: invalid %s address %q", config.ErrInvalidPayout, i, netParams.Name, r.Address)
+		}
+		if r.AmountSats < int64(config.BTCDustThresholdSats) || r.AmountSats > btcutil.MaxSatoshi {
+			return fmt.Errorf("%w: recipient %d: amount %d sats must be between %d and %d",
+				config.ErrInvalidPayout, i, r.AmountSats, config.BTCDustThresholdSats, int64(btcutil.MaxSatoshi))
+		}
+	}
+	return nil
+}
+
+// BuildBTCPayoutTx builds an unsigned transaction paying each recipient its amount,
+// in request order, plus a change output to params.ChangeAddress. UTXOs are added
+// largest first until they cover the payouts and the fee; change below the dust
+// threshold is left to the fee instead. Every input signals BIP-125 replace-by-fee.
+func BuildBTCPayoutTx(params BTCPayoutParams) (*BTCPayoutTx, error) {
+	if err := ValidatePayoutRecipients(params.Recipients, params.NetParams); err != nil {
+		return nil, err
+	}
+
+	outputScripts := make([][]byte, 0, len(params.Recipients)+1)
+	var payoutSats int64
+	for _, r := range params.Recipients {
+		script, err := PKScriptFromAddress(r.Address, params.NetParams)
+		if err != nil {
+			return nil, err
+		}
+		outputScripts = append(outputScripts, script)
+		payoutSats += r.AmountSats
+	}
+	changeScript, err := PKScriptFromAddress(params.ChangeAddress, params.NetParams)
+	if err != nil {
+		return nil, fmt.Errorf("change address: %w", err)
+	}
+	withChange := append(outputScripts[:len(outputScripts):len(outputScripts)], changeScript)
+
+	slog.Info("building BTC payout transaction",
+		"recipientCount", len(params.Recipients),
+		"payoutSats", payoutSats,
+		"candidateInputs", len(params.UTXOs),
+		"feeRate", params.FeeRate,
+	)
+
+	candidates := make([]models.UTXO, len(params.UTXOs))
+	copy(candidates, params.UTXOs)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Value > candidates[j].Value
+	})
+
+	var selected []models.UTXO
+	var totalInputSats int64
+	for _, u := range candidates {
+		if len(selected) == config.BTCMaxInputsPerTx {
+			break
+		}
+		selected = append(selected, u)
+		totalInputSats += u.Value
+		if totalInputSats < payoutSats {
+			continue
+		}
+
+		feeSats, vsize, err := payoutFee(selected, withChange, params.FeeRate)
+		if err != nil {
+			return nil, err
+		}
+		if change := totalInputSats - payoutSats - feeSats; change >= int64(config.BTCDustThresholdSats) {
+			return assemblePayoutTx(params, selected, totalInputSats, payoutSats, change, feeSats, vsize, outputScripts, changeScript)
+		}
+
+		// Without a change output the leftover, at least the estimated fee, is the fee.
+		feeSats, vsize, err = payoutFee(selected, outputScripts, params.FeeRate)
+		if err != nil {
+			return nil, err
+		}
+		if leftover := totalInputSats - payoutSats; leftover >= feeSats {
+			return assemblePayoutTx(params, selected, totalInputSats, payoutSats, 0, leftover, vsize, outputScripts, nil)
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %d sats across %d inputs cannot pay %d sats plus fee",
+		config.ErrInsufficientUTXO, totalInputSats, len(selected), payoutSats)
+}
+
+// payoutFee returns the fee, with the consolidation safety margin, and the vsize
+// of spending utxos to outputs with the given scripts.
+func payoutFee(utxos []models.UTXO, outputScripts [][]byte, feeRate int64) (int64, int, error) {
+	weight, err := EstimateBTCTxWeight(utxos, outputScripts)
+	if err != nil {
+		return 0, 0, fmt.Errorf("estimate TX weight: %w", err)
+	}
+	if weight > config.BTCMaxTxWeight {
+		return 0, 0, fmt.Errorf("%w: estimated weight %d exceeds max %d",
+			config.ErrTxTooLarge, weight, config.BTCMaxTxWeight)
+	}
+	vsize := (weight + 3) / 4
+	baseFee := feeRate * int64(vsize)
+	safetyMargin := baseFee * int64(config.BTCFeeSafetyMarginPct) / 100
+	if safetyMargin < 1 {
+		safetyMargin = 1
+	}
+	return baseFee + safetyMargin, vsize, nil
+}
+
+// assemblePayoutTx builds the payout MsgTx; a nil changeScript means no change output.
+func assemblePayoutTx(params BTCPayoutParams, utxos []models.UTXO, totalInputSats, payoutSats, changeSats, feeSats int64, vsize int, outputScripts [][]byte, changeScript []byte) (*BTCPayoutTx, error) {
+	msgTx := wire.NewMsgTx(wire.TxVersion)
+	for _, u := range utxos {
+		hash, err := chainhash.NewHashFromStr(u.TxID)
+		if err != nil {
+			return nil, fmt.Errorf("parse UTXO txid %q: %w", u.TxID, err)
+		}
+		txIn := wire.NewTxIn(wire.NewOutPoint(hash, u.Vout), nil, nil)
+		txIn.Sequence = config.BTCRBFSequence
+		msgTx.AddTxIn(txIn)
+	}
+	for i, r := range params.Recipients {
+		msgTx.AddTxOut(wire.NewTxOut(r.AmountSats, outputScripts[i]))
+	}
+	if changeScript != nil {
+		msgTx.AddTxOut(wire.NewTxOut(changeSats, changeScript))
+	}
+
+	slog.Info("BTC payout fee calculation",
+		"inputCount", len(utxos),
+		"totalInputSats", totalInputSats,
+		"payoutSats", payoutSats,
+		"changeSats", changeSats,
+		"estimatedVsize", vsize,
+		"feeRate", params.FeeRate,
+		"feeSats", feeSats,
+	)
+
+	return &BTCPayoutTx{
+		Tx:             msgTx,
+		UTXOs:          utxos,
+		TotalInputSats: totalInputSats,
+		PayoutSats:     payoutSats,
+		ChangeSats:     changeSats,
+		FeeSats:        feeSats,
+		EstimatedVsize: vsize,
+	}, nil
+}
+
+// PreviewPayout builds the payout Payout would send, without signing or
+// broadcasting it. The change address shown is the one Payout will use unless
+// another payout is sent first.
+func (s *BTCConsolidationService) PreviewPayout(ctx context.Context, addresses []models.Address, recipients []models.PayoutRecipient, feeRate int64, sel models.BTCInputSelection) (*models.PayoutResult, error) {
+	slog.Info("BTC payout preview",
+		"addressCount", len(addresses),
+		"recipientCount", len(recipients),
+		"requestedFeeRate", feeRate,
+		"inputSelection", sel,
+	)
+
+	plan, err := s.planPayout(ctx, addresses, recipients, feeRate, sel)
+	if err != nil {
+		return nil, err
+	}
+	return plan.result(recipients), nil
+}
+
+// Payout pays each recipient from the UTXOs sel selects across addresses, with the
+// change going to a fresh address of the internal chain. It records the payout's
+// outputs in btc_payouts and the change address, and tracks the transaction in a
+// tx_state row marked config.FromAddressBTCPayout, so that it can be fee-bumped
+// but is never retried by a sweep resume.
+func (s *BTCConsolidationService) Payout(ctx context.Context, addresses []models.Address, recipients []models.PayoutRecipient, feeRate int64, sel models.BTCInputSelection, sweepID string) (*models.PayoutResult, error) {
+	slog.Info("BTC payout execute",
+		"addressCount", len(addresses),
+		"recipientCount", len(recipients),
+		"requestedFeeRate", feeRate,
+		"inputSelection", sel,
+		"sweepID", sweepID,
+	)
+	start := time.Now()
+
+	plan, err := s.planPayout(ctx, addresses, recipients, feeRate, sel)
+	if err != nil {
+		return nil, err
+	}
+	built := plan.built
+
+	signingUTXOs, err := s.prepareSigningUTXOs(ctx, built.UTXOs)
+	if err != nil {
+		for _, su := range signingUTXOs {
+			if su.PrivKey != nil {
+				su.PrivKey.Zero()
+			}
+		}
+		return nil, fmt.Errorf("prepare signing UTXOs: %w", err)
+	}
+	if err := SignBTCTx(built.Tx, signingUTXOs); err != nil {
+		return nil, fmt.Errorf("sign payout TX: %w", err)
+	}
+
+	txStateID := s.createPayoutTxState(sweepID, built, recipients)
+	if err := s.database.SetTxStateInputs(txStateID, payoutInputIndices(built.UTXOs)); err != nil {
+		slog.Error("failed to record BTC tx_state inputs", "id", txStateID, "error", err)
+	}
+
+	txHash, err := s.broadcastPayout(ctx, txStateID, sweepID, built, recipients, start)
+	if err != nil {
+		return nil, err
+	}
+
+	if plan.changeAddress != "" {
+		if err := s.database.RecordBTCChangeAddress(db.BTCChangeAddressRow{
+			AddressType: string(s.keyService.BTCAddressType()),
+			ChangeIndex: plan.changeIndex,
+			Address:     plan.changeAddress,
+			TxHash:      txHash,
+			AmountSats:  built.ChangeSats,
+		}); err != nil {
+			slog.Error("failed to record BTC change address", "txHash", txHash, "error", err)
+		}
+	}
+
+	result := plan.result(recipients)
+	result.SweepID = sweepID
+	result.TxHash = txHash
+	return result, nil
+}
+
+// createPayoutTxState writes the pending tx_state row of a signed payout and
+// returns its ID. Errors are logged, not returned.
+func (s *BTCConsolidationService) createPayoutTxState(sweepID string, built *BTCPayoutTx, recipients []models.PayoutRecipient) string {
+	txStateID := GenerateTxStateID()
+	if err := s.database.CreateTxState(db.TxStateRow{
+		ID:           txStateID,
+		SweepID:      sweepID,
+		Chain:        string(models.ChainBTC),
+		Token:        string(models.TokenNative),
+		AddressIndex: 0, // BTC payout is multi-input, no single index
+		FromAddress:  config.FromAddressBTCPayout,
+		ToAddress:    recipients[0].Address, // btc_payouts lists every recipient
+		Amount:       strconv.FormatInt(built.PayoutSats, 10),
+		Status:       config.TxStatePending,
+	}); err != nil {
+		slog.Error("failed to create BTC payout tx_state", "error", err)
+		// Non-blocking: continue even if tx_state write fails
+	}
+	return txStateID
+}
+
+// payoutInputIndices returns the address indices of the external-chain inputs of
+// a payout, for its tx_state inputs; change inputs have no external index.
+func payoutInputIndices(utxos []models.UTXO) []int {
+	var indices []int
+	for _, u := range utxos {
+		if !u.Change {
+			indices = append(indices, u.AddressIndex)
+		}
+	}
+	return indices
+}
+
+// broadcastPayout broadcasts a signed payout tracked by txStateID, records one
+// btc_payouts row per recipient and polls for confirmation in the background.
+// It returns the transaction hash.
+func (s *BTCConsolidationService) broadcastPayout(ctx context.Context, txStateID, sweepID string, built *BTCPayoutTx, recipients []models.PayoutRecipient, start time.Time) (string, error) {
+	rawHex, err := SerializeBTCTx(built.Tx)
+	if err != nil {
+		s.updateTxState(txStateID, config.TxStateFailed, "", fmt.Sprintf("serialize TX: %s", err))
+		return "", fmt.Errorf("serialize TX: %w", err)
+	}
+
+	s.updateTxState(txStateID, config.TxStateBroadcasting, "", "")
+	txHash, err := s.broadcaster.Broadcast(ctx, rawHex)
+	if err != nil {
+		s.updateTxState(txStateID, config.TxStateFailed, "", fmt.Sprintf("broadcast: %s", err))
+		return "", fmt.Errorf("broadcast TX: %w", err)
+	}
+
+	slog.Info("BTC payout broadcast successful",
+		"txHash", txHash,
+		"payoutSats", built.PayoutSats,
+		"changeSats", built.ChangeSats,
+		"feeSats", built.FeeSats,
+		"sweepID", sweepID,
+		"duration", time.Since(start).Round(time.Millisecond),
+	)
+
+	if s.txHub != nil {
+		s.txHub.Broadcast(TxEvent{
+			Type: "tx_status",
+			Data: TxStatusData{
+				Chain:        string(models.ChainBTC),
+				Token:        string(models.TokenNative),
+				AddressIndex: 0,
+				FromAddress:  config.FromAddressBTCPayout,
+				TxHash:       txHash,
+				Status:       "success",
+				Amount:       strconv.FormatInt(built.PayoutSats, 10),
+				Current:      1,
+				Total:        1,
+			},
+		})
+	}
+
+	s.updateTxState(txStateID, config.TxStateConfirming, txHash, "")
+	if err := s.database.RecordBTCPayout(sweepID, txHash, recipients); err != nil {
+		slog.Error("failed to record BTC payout", "txHash", txHash, "error", err)
+	}
+
+	// Poll for confirmation in background (best-effort), with a fresh context since
+	// the request context ends with the response.
+	go func() {
+		bgCtx, cancel := context.WithTimeout(context.Background(), config.BTCConfirmationTimeout)
+		defer cancel()
+
+		if err := WaitForBTCConfirmation(bgCtx, s.httpClient, s.confirmationURLs, txHash); err != nil {
+			slog.Warn("BTC payout confirmation polling timed out or failed", "txHash", txHash, "error", err)
+			s.updateTxState(txStateID, config.TxStateUncertain, txHash, fmt.Sprintf("confirmation: %s", err))
+			return
+		}
+		slog.Info("BTC payout confirmed", "txHash", txHash)
+		s.updateTxState(txStateID, config.TxStateConfirmed, txHash, "")
+		if err := s.database.UpdateBTCPayoutStatus(txHash, config.TxStateConfirmed); err != nil {
+			slog.Error("failed to update BTC payout status", "txHash", txHash, "error", err)
+		}
+	}()
+
+	return txHash, nil
+}
+
+// payoutPlan is a built payout with the fee rate and change address it uses.
+type payoutPlan struct {
+	built         *BTCPayoutTx
+	feeRate       int64
+	changeIndex   int
+	changeAddress string // empty when the payout has no change output
+}
+
+// planPayout fetches the selected UTXOs, estimates the fee rate when feeRate <= 0
+// and derives the next change address, then builds the payout.
+func (s *BTCConsolidationService) planPayout(ctx context.Context, addresses []models.Address, recipients []models.PayoutRecipient, feeRate int64, sel models.BTCInputSelection) (*payoutPlan, error) {
+	if err := ValidatePayoutRecipients(recipients, s.netParams); err != nil {
+		return nil, err
+	}
+
+	utxos, err := s.fetchSelectedUTXOs(ctx, addresses, sel)
+	if err != nil {
+		return nil, fmt.Errorf("fetch UTXOs: %w", err)
+	}
+	if len(utxos) == 0 {
+		return nil, fmt.Errorf("%w: no spendable UTXOs match the input selection", config.ErrInsufficientUTXO)
+	}
+	if err := ClassifyUTXOs(utxos, s.netParams); err != nil {
+		return nil, err
+	}
+
+	if feeRate <= 0 {
+		estimate, err := s.feeEstimator.EstimateFee(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("estimate fee: %w", err)
+		}
+		feeRate = DefaultFeeRate(estimate)
+	}
+
+	plan := &payoutPlan{feeRate: feeRate}
+	plan.changeIndex, err = s.database.NextBTCChangeIndex(string(s.keyService.BTCAddressType()))
+	if err != nil {
+		return nil, err
+	}
+	plan.changeAddress, err = s.keyService.DeriveBTCChangeAddress(uint32(plan.changeIndex))
+	if err != nil {
+		return nil, fmt.Errorf("derive change address: %w", err)
+	}
+
+	plan.built, err = BuildBTCPayoutTx(BTCPayoutParams{
+		UTXOs:         utxos,
+		Recipients:    recipients,
+		ChangeAddress: plan.changeAddress,
+		FeeRate:       feeRate,
+		NetParams:     s.netParams,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("build payout TX: %w", err)
+	}
+	if plan.built.ChangeSats == 0 {
+		plan.changeAddress = ""
+	}
+	return plan, nil
+}
+
+// result describes the planned payout to the API.
+func (p *payoutPlan) result(recipients []models.PayoutRecipient) *models.PayoutResult {
+	r := &models.PayoutResult{
+		Chain:          models.ChainBTC,
+		Recipients:     recipients,
+		InputCount:     len(p.built.UTXOs),
+		TotalInputSats: p.built.TotalInputSats,
+		PayoutSats:     p.built.PayoutSats,
+		ChangeSats:     p.built.ChangeSats,
+		FeeSats:        p.built.FeeSats,
+		FeeRate:        p.feeRate,
+		EstimatedVsize: p.built.EstimatedVsize,
+	}
+	if p.changeAddress != "" {
+		r.ChangeAddress = p.changeAddress
+		r.ChangeIndex = &p.changeIndex
+	}
+	return r
+}
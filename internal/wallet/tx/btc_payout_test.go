@@ -0,0 +1,271 @@
+package tx
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/shared/scanner"
+)
+
+// payoutTestUTXOs returns the four mixed-type test UTXOs worth 10000, 60000,
+// 25000 and 40000 sats.
+func payoutTestUTXOs(t *testing.T) []models.UTXO {
+	t.Helper()
+	utxos := psbtTestConsolidation(t).UTXOs
+	for i, v := range []int64{10000, 60000, 25000, 40000} {
+		utxos[i].Value = v
+	}
+	return utxos
+}
+
+func TestBuildBTCPayoutTx(t *testing.T) {
+	net := &chaincfg.TestNet3Params
+	utxos := payoutTestUTXOs(t)
+	params := BTCPayoutParams{
+		UTXOs: utxos,
+		Recipients: []models.PayoutRecipient{
+			{Address: utxos[0].Address, AmountSats: 30000},
+			{Address: utxos[2].Address, AmountSats: 20000},
+		},
+		ChangeAddress: utxos[3].Address,
+		FeeRate:       5,
+		NetParams:     net,
+	}
+
+	built, err := BuildBTCPayoutTx(params)
+	if err != nil {
+		t.Fatalf("BuildBTCPayoutTx() error = %v", err)
+	}
+	// The largest UTXO covers both payouts on its own.
+	if len(built.Tx.TxIn) != 1 || built.UTXOs[0].Value != 60000 {
+		t.Fatalf("inputs = %+v, want only the 60000 sat UTXO", built.UTXOs)
+	}
+	if len(built.Tx.TxOut) != 3 || built.Tx.TxOut[0].Value != 30000 || built.Tx.TxOut[1].Value != 20000 ||
+		built.Tx.TxOut[2].Value != built.ChangeSats {
+		t.Fatalf("outputs = %+v, want both recipients in order then the change", built.Tx.TxOut)
+	}
+	if built.PayoutSats+built.ChangeSats+built.FeeSats != built.TotalInputSats {
+		t.Errorf("payout %d + change %d + fee %d != inputs %d", built.PayoutSats, built.ChangeSats, built.FeeSats, built.TotalInputSats)
+	}
+	if built.FeeSats < params.FeeRate*int64(built.EstimatedVsize) {
+		t.Errorf("fee %d sats below %d sat/vB for %d vB", built.FeeSats, params.FeeRate, built.EstimatedVsize)
+	}
+	if built.Tx.TxIn[0].Sequence != config.BTCRBFSequence {
+		t.Errorf("input sequence = %#x, want RBF", built.Tx.TxIn[0].Sequence)
+	}
+
+	// Larger payouts pull in the next largest UTXOs.
+	params.Recipients[0].AmountSats = 80000
+	built, err = BuildBTCPayoutTx(params)
+	if err != nil {
+		t.Fatalf("BuildBTCPayoutTx(100000 sats) error = %v", err)
+	}
+	if len(built.UTXOs) != 3 || built.TotalInputSats != 125000 {
+		t.Errorf("inputs = %+v, want the three largest", built.UTXOs)
+	}
+
+	params.Recipients[0].AmountSats = 200000
+	if _, err := BuildBTCPayoutTx(params); !errors.Is(err, config.ErrInsufficientUTXO) {
+		t.Errorf("BuildBTCPayoutTx(beyond balance) error = %v, want ErrInsufficientUTXO", err)
+	}
+}
+
+func TestBuildBTCPayoutTx_DustChangeGoesToFee(t *testing.T) {
+	utxos := payoutTestUTXOs(t)
+	built, err := BuildBTCPayoutTx(BTCPayoutParams{
+		UTXOs:         utxos[1:2],
+		Recipients:    []models.PayoutRecipient{{Address: utxos[0].Address, AmountSats: 59700}},
+		ChangeAddress: utxos[3].Address,
+		FeeRate:       1,
+		NetParams:     &chaincfg.TestNet3Params,
+	})
+	if err != nil {
+		t.Fatalf("BuildBTCPayoutTx() error = %v", err)
+	}
+	if built.ChangeSats != 0 || len(built.Tx.TxOut) != 1 || built.FeeSats != 300 {
+		t.Errorf("change %d, %d outputs, fee %d; want no change output and the 300 sat leftover as fee",
+			built.ChangeSats, len(built.Tx.TxOut), built.FeeSats)
+	}
+}
+
+func TestValidatePayoutRecipients(t *testing.T) {
+	net := &chaincfg.TestNet3Params
+	addr := payoutTestUTXOs(t)[0].Address
+	if err := ValidatePayoutRecipients([]models.PayoutRecipient{{Address: addr, AmountSats: 1000}}, net); err != nil {
+		t.Errorf("valid recipient: error = %v", err)
+	}
+
+	tooMany := make([]models.PayoutRecipient, config.BTCMaxPayoutRecipients+1)
+	for i := range tooMany {
+		tooMany[i] = models.PayoutRecipient{Address: addr, AmountSats: 1000}
+	}
+	for name, recipients := range map[string][]models.PayoutRecipient{
+		"none":            nil,
+		"too many":        tooMany,
+		"dust":            {{Address: addr, AmountSats: int64(config.BTCDustThresholdSats) - 1}},
+		"invalid address": {{Address: "notanaddress", AmountSats: 1000}},
+		"mainnet address": {{Address: "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu", AmountSats: 1000}},
+	} {
+		if err := ValidatePayoutRecipients(recipients, net); !errors.Is(err, config.ErrInvalidPayout) {
+			t.Errorf("%s: error = %v, want ErrInvalidPayout", name, err)
+		}
+	}
+}
+
+func TestBTCConsolidationService_Payout(t *testing.T) {
+	net := &chaincfg.TestNet3Params
+	utxos := payoutTestUTXOs(t)
+	database := setupGasTestDB(t)
+
+	esplora := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/status") {
+			w.Write([]byte(`{"confirmed":true}`))
+			return
+		}
+		var list []map[string]any
+		for _, u := range utxos {
+			if r.URL.Path == "/address/"+u.Address+"/utxo" {
+				list = append(list, map[string]any{"txid": u.TxID, "vout": u.Vout, "value": u.Value, "status": map[string]any{"confirmed": true}})
+			}
+		}
+		json.NewEncoder(w).Encode(list)
+	}))
+	defer esplora.Close()
+
+	fetcher := NewBTCUTXOFetcher(esplora.Client(), []string{esplora.URL}, []*scanner.RateLimiter{scanner.NewRateLimiter("test", 100, 0)})
+	ks := NewKeyService(writeTempMnemonic(t, testMnemonic24), "testnet")
+	broadcaster := &recordingBroadcaster{}
+	svc := NewBTCConsolidationService(ks, fetcher, nil, broadcaster, database, net, esplora.Client(), []string{esplora.URL}, nil)
+
+	addresses := make([]models.Address, len(utxos))
+	for i, u := range utxos {
+		addresses[i] = models.Address{Chain: models.ChainBTC, AddressIndex: u.AddressIndex, Address: u.Address}
+	}
+	recipients := []models.PayoutRecipient{
+		{Address: utxos[0].Address, AmountSats: 45000},
+		{Address: utxos[2].Address, AmountSats: 30000},
+	}
+
+	preview, err := svc.PreviewPayout(context.Background(), addresses, recipients, 4, models.BTCInputSelection{})
+	if err != nil {
+		t.Fatalf("PreviewPayout() error = %v", err)
+	}
+	wantChange, err := ks.DeriveBTCChangeAddress(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.TxHash != "" || preview.ChangeAddress != wantChange || preview.ChangeIndex == nil || *preview.ChangeIndex != 0 ||
+		preview.InputCount != 2 || preview.PayoutSats != 75000 {
+		t.Errorf("PreviewPayout() = %+v, want 2 inputs and change to %s", preview, wantChange)
+	}
+	if len(broadcaster.sent) != 0 {
+		t.Fatal("PreviewPayout() broadcast a transaction")
+	}
+
+	result, err := svc.Payout(context.Background(), addresses, recipients, 4, models.BTCInputSelection{}, "sweep-payout")
+	if err != nil {
+		t.Fatalf("Payout() error = %v", err)
+	}
+	if result.TxHash != "psbt-txhash" || result.SweepID != "sweep-payout" || result.ChangeAddress != wantChange ||
+		result.ChangeSats != preview.ChangeSats {
+		t.Errorf("Payout() = %+v", result)
+	}
+
+	raw, err := hex.DecodeString(broadcaster.sent[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var signed wire.MsgTx
+	if err := signed.Deserialize(bytes.NewReader(raw)); err != nil {
+		t.Fatal(err)
+	}
+	if len(signed.TxIn) != 2 || len(signed.TxOut) != 3 {
+		t.Fatalf("payout has %d inputs and %d outputs, want 2 and 3", len(signed.TxIn), len(signed.TxOut))
+	}
+	for i, in := range signed.TxIn {
+		if len(in.Witness) == 0 && len(in.SignatureScript) == 0 {
+			t.Errorf("input %d is not signed", i)
+		}
+	}
+	changeScript, err := PKScriptFromAddress(wantChange, net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(signed.TxOut[2].PkScript, changeScript) {
+		t.Error("last output does not pay the change address")
+	}
+
+	payouts, _, err := database.ListBTCPayouts(1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(payouts) != 2 {
+		t.Fatalf("recorded %d payout outputs, want one per recipient", len(payouts))
+	}
+	for i, p := range payouts {
+		if p.TxHash != "psbt-txhash" || p.SweepID != "sweep-payout" || p.Vout != i || p.AmountSats != recipients[i].AmountSats {
+			t.Errorf("payout output %d = %+v", i, p)
+		}
+	}
+	if _, total, err := database.ListTransactions(nil, 1, 10); err != nil || total != 0 {
+		t.Errorf("payout wrote %d transactions rows, %v, want none", total, err)
+	}
+
+	// The payout is tracked for fee bumps, but a resume never retries it.
+	states, err := database.GetTxStatesBySweepID("sweep-payout")
+	if err != nil || len(states) != 1 || states[0].FromAddress != config.FromAddressBTCPayout || states[0].TxHash != "psbt-txhash" {
+		t.Fatalf("payout tx_state rows = %+v, %v, want one payout row", states, err)
+	}
+	if inputs, err := database.GetTxStateInputs(states[0].ID); err != nil || len(inputs) != preview.InputCount {
+		t.Errorf("payout tx_state inputs = %v, %v, want %d", inputs, err, preview.InputCount)
+	}
+	if err := database.UpdateTxStatus(states[0].ID, config.TxStateFailed, "psbt-txhash", "test"); err != nil {
+		t.Fatal(err)
+	}
+	if retryable, err := database.GetRetryableTxStates("sweep-payout"); err != nil || len(retryable) != 0 {
+		t.Errorf("GetRetryableTxStates() = %+v, %v, want no payout row", retryable, err)
+	}
+
+	// The next payout moves on to a fresh change address.
+	if next, err := database.NextBTCChangeIndex(string(models.BTCAddressP2WPKH)); err != nil || next != 1 {
+		t.Errorf("NextBTCChangeIndex() after payout = %d, %v, want 1", next, err)
+	}
+
+	// The change is spendable: a second payout signs for it with the internal-chain key.
+	changeTxID := strings.Repeat("c", 64)
+	utxos = append(utxos, models.UTXO{TxID: changeTxID, Vout: 2, Value: result.ChangeSats, Address: wantChange})
+	funded, err := database.GetFundedBTCChangeAddresses()
+	if err != nil || len(funded) != 1 || funded[0].Address != wantChange {
+		t.Fatalf("GetFundedBTCChangeAddresses() = %+v, %v, want %s", funded, err, wantChange)
+	}
+	second, err := svc.Payout(context.Background(), funded, []models.PayoutRecipient{{Address: utxos[1].Address, AmountSats: 20000}}, 4, models.BTCInputSelection{}, "sweep-payout-2")
+	if err != nil {
+		t.Fatalf("Payout() from change error = %v", err)
+	}
+	if second.InputCount != 1 || second.ChangeIndex == nil || *second.ChangeIndex != 1 {
+		t.Errorf("Payout() from change = %+v, want 1 input and change index 1", second)
+	}
+	raw, err = hex.DecodeString(broadcaster.sent[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromChange wire.MsgTx
+	if err := fromChange.Deserialize(bytes.NewReader(raw)); err != nil {
+		t.Fatal(err)
+	}
+	if len(fromChange.TxIn) != 1 || fromChange.TxIn[0].PreviousOutPoint.Hash.String() != changeTxID || len(fromChange.TxIn[0].Witness) == 0 {
+		t.Errorf("payout from change spends %+v, want the signed change outpoint", fromChange.TxIn)
+	}
+}
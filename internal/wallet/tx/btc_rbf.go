@@ -0,0 +1,430 @@
+package tx
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/wallet/db"
+)
+
+// esploraTx is the JSON response from the Esplora /tx/{txid} endpoint: the
+// transaction with the previous output of every input.
+type esploraTx struct {
+	TxID   string `json:"txid"`
+	Weight int    `json:"weight"`
+	Fee    int64  `json:"fee"`
+	Vin    []struct {
+		TxID     string `json:"txid"`
+		Vout     uint32 `json:"vout"`
+		Sequence uint32 `json:"sequence"`
+		Prevout  struct {
+			Address string `json:"scriptpubkey_address"`
+			Value   int64  `json:"value"`
+		} `json:"prevout"`
+	} `json:"vin"`
+	Vout []struct {
+		Address string `json:"scriptpubkey_address"`
+		Value   int64  `json:"value"`
+	} `json:"vout"`
+	Status struct {
+		Confirmed bool `json:"confirmed"`
+	} `json:"status"`
+}
+
+// BumpFee replaces an unconfirmed consolidation broadcast by this wallet with one
+// spending the same inputs to the same destination at a higher fee rate (BIP-125
+// replace-by-fee). A payout is replaced by one paying the same recipients, the
+// extra fee coming out of its change. feeRate <= 0 uses the estimator, raised to
+// the minimum rate a replacement needs. On success the replaced tx_state row and
+// transactions rows are marked superseded, and the replacement is tracked under
+// the same sweep.
+func (s *BTCConsolidationService) BumpFee(ctx context.Context, txHash string, feeRate int64) (*models.FeeBumpResult, error) {
+	slog.Info("BTC fee bump requested",
+		"txHash", txHash,
+		"requestedFeeRate", feeRate,
+	)
+	start := time.Now()
+
+	replaced, err := s.database.GetTxStateByHash(string(models.ChainBTC), txHash)
+	if err != nil {
+		return nil, err
+	}
+	if replaced == nil {
+		return nil, fmt.Errorf("%w: no BTC consolidation with hash %s", config.ErrTxNotFound, txHash)
+	}
+	switch replaced.Status {
+	case config.TxStateConfirmed:
+		return nil, fmt.Errorf("%w: %s", config.ErrTxAlreadyConfirmed, txHash)
+	case config.TxStateSuperseded, config.TxStateFailed, config.TxStateDismissed:
+		return nil, fmt.Errorf("%w: %s is %s", config.ErrTxNotReplaceable, txHash, replaced.Status)
+	}
+
+	orig, err := fetchBTCTx(ctx, s.httpClient, s.confirmationURLs, txHash)
+	if err != nil {
+		return nil, err
+	}
+	if orig.Status.Confirmed {
+		s.updateTxState(replaced.ID, config.TxStateConfirmed, txHash, "")
+		return nil, fmt.Errorf("%w: %s", config.ErrTxAlreadyConfirmed, txHash)
+	}
+
+	if replaced.FromAddress == config.FromAddressBTCPayout {
+		return s.bumpPayout(ctx, replaced, orig, feeRate, start)
+	}
+
+	destAddr := replaced.ToAddress
+	utxos, err := s.replaceableInputs(orig, destAddr)
+	if err != nil {
+		return nil, err
+	}
+	feeRate, err = s.replacementFeeRate(ctx, orig, feeRate)
+	if err != nil {
+		return nil, err
+	}
+
+	built, err := BuildBTCConsolidationTx(BTCBuildParams{
+		UTXOs:       utxos,
+		DestAddress: destAddr,
+		FeeRate:     feeRate,
+		NetParams:   s.netParams,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("build replacement TX: %w", err)
+	}
+	if minFee := orig.Fee + config.BTCIncrementalRelayFeeRate*int64(built.EstimatedVsize); built.FeeSats < minFee {
+		return nil, fmt.Errorf("%w: replacement fee %d sats, needs at least %d", config.ErrFeeBumpTooLow, built.FeeSats, minFee)
+	}
+
+	signingUTXOs, err := s.prepareSigningUTXOs(ctx, built.UTXOs)
+	if err != nil {
+		for _, su := range signingUTXOs {
+			if su.PrivKey != nil {
+				su.PrivKey.Zero()
+			}
+		}
+		return nil, fmt.Errorf("prepare signing UTXOs: %w", err)
+	}
+	if err := SignBTCTx(built.Tx, signingUTXOs); err != nil {
+		return nil, fmt.Errorf("sign replacement TX: %w", err)
+	}
+
+	txStateID := GenerateTxStateID()
+	if err := s.database.CreateTxState(db.TxStateRow{
+		ID:           txStateID,
+		SweepID:      replaced.SweepID,
+		Chain:        string(models.ChainBTC),
+		Token:        string(models.TokenNative),
+		AddressIndex: 0, // BTC consolidation is multi-input, no single index
+		FromAddress:  "consolidated",
+		ToAddress:    destAddr,
+		Amount:       strconv.FormatInt(built.OutputSats, 10),
+		Status:       config.TxStatePending,
+	}); err != nil {
+		slog.Error("failed to create BTC tx_state", "error", err)
+		// Non-blocking: continue even if tx_state write fails
+	}
+
+	// The replacement spends the inputs of the replaced row, so a resume of a
+	// chunked consolidation retries the same addresses.
+	inputs, err := s.database.GetTxStateInputs(replaced.ID)
+	if err != nil {
+		slog.Error("failed to read replaced BTC tx_state inputs", "id", replaced.ID, "error", err)
+	}
+	if err := s.database.SetTxStateInputs(txStateID, inputs); err != nil {
+		slog.Error("failed to record BTC tx_state inputs", "id", txStateID, "error", err)
+	}
+
+	result, err := s.broadcastSigned(ctx, txStateID, built, destAddr, start, 1, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	// The replaced transaction can no longer confirm unless the replacement is
+	// dropped; track it as superseded rather than failed.
+	s.updateTxState(replaced.ID, config.TxStateSuperseded, "", "replaced by "+result.TxHash)
+	if err := s.database.UpdateTransactionStatusByHash(string(models.ChainBTC), txHash, config.TxStateSuperseded); err != nil {
+		slog.Error("failed to mark replaced BTC transaction superseded",
+			"txHash", txHash,
+			"error", err,
+		)
+	}
+
+	slog.Info("BTC fee bump broadcast",
+		"replacedTxHash", txHash,
+		"txHash", result.TxHash,
+		"replacedFeeSats", orig.Fee,
+		"feeSats", built.FeeSats,
+		"feeRate", feeRate,
+		"sweepID", replaced.SweepID,
+	)
+
+	return &models.FeeBumpResult{
+		SweepID:         replaced.SweepID,
+		TxHash:          result.TxHash,
+		ReplacedTxHash:  txHash,
+		Chain:           models.ChainBTC,
+		InputCount:      len(built.UTXOs),
+		OutputSats:      built.OutputSats,
+		FeeSats:         built.FeeSats,
+		FeeRate:         feeRate,
+		ReplacedFeeSats: orig.Fee,
+	}, nil
+}
+
+// bumpPayout replaces the unconfirmed payout orig, tracked by replaced, with one
+// paying the same recipients from its inputs at a higher fee rate, the extra fee
+// coming out of the change. The replaced payout outputs are marked superseded and
+// the change address moves to the replacement.
+func (s *BTCConsolidationService) bumpPayout(ctx context.Context, replaced *db.TxStateRow, orig *esploraTx, feeRate int64, start time.Time) (*models.FeeBumpResult, error) {
+	utxos, err := s.signalledInputs(orig)
+	if err != nil {
+		return nil, err
+	}
+	recipients, changeAddr, err := s.payoutOutputs(orig)
+	if err != nil {
+		return nil, err
+	}
+	feeRate, err = s.replacementFeeRate(ctx, orig, feeRate)
+	if err != nil {
+		return nil, err
+	}
+
+	// A payout whose change went to the fee has no change address yet.
+	newChange := changeAddr == ""
+	changeIndex := 0
+	if newChange {
+		changeIndex, err = s.database.NextBTCChangeIndex(string(s.keyService.BTCAddressType()))
+		if err != nil {
+			return nil, err
+		}
+		changeAddr, err = s.keyService.DeriveBTCChangeAddress(uint32(changeIndex))
+		if err != nil {
+			return nil, fmt.Errorf("derive change address: %w", err)
+		}
+	}
+
+	built, err := BuildBTCPayoutTx(BTCPayoutParams{
+		UTXOs:         utxos,
+		Recipients:    recipients,
+		ChangeAddress: changeAddr,
+		FeeRate:       feeRate,
+		NetParams:     s.netParams,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("build replacement payout TX: %w", err)
+	}
+	if minFee := orig.Fee + config.BTCIncrementalRelayFeeRate*int64(built.EstimatedVsize); built.FeeSats < minFee {
+		return nil, fmt.Errorf("%w: replacement fee %d sats, needs at least %d", config.ErrFeeBumpTooLow, built.FeeSats, minFee)
+	}
+
+	signingUTXOs, err := s.prepareSigningUTXOs(ctx, built.UTXOs)
+	if err != nil {
+		for _, su := range signingUTXOs {
+			if su.PrivKey != nil {
+				su.PrivKey.Zero()
+			}
+		}
+		return nil, fmt.Errorf("prepare signing UTXOs: %w", err)
+	}
+	if err := SignBTCTx(built.Tx, signingUTXOs); err != nil {
+		return nil, fmt.Errorf("sign replacement payout TX: %w", err)
+	}
+
+	txStateID := s.createPayoutTxState(replaced.SweepID, built, recipients)
+	if err := s.database.SetTxStateInputs(txStateID, payoutInputIndices(built.UTXOs)); err != nil {
+		slog.Error("failed to record BTC tx_state inputs", "id", txStateID, "error", err)
+	}
+
+	txHash, err := s.broadcastPayout(ctx, txStateID, replaced.SweepID, built, recipients, start)
+	if err != nil {
+		return nil, err
+	}
+
+	s.updateTxState(replaced.ID, config.TxStateSuperseded, "", "replaced by "+txHash)
+	if err := s.database.UpdateBTCPayoutStatus(orig.TxID, config.TxStateSuperseded); err != nil {
+		slog.Error("failed to mark replaced BTC payout superseded", "txHash", orig.TxID, "error", err)
+	}
+	switch {
+	case !newChange:
+		if err := s.database.UpdateBTCChangeAddressTx(changeAddr, txHash, built.ChangeSats); err != nil {
+			slog.Error("failed to move BTC change address to replacement", "txHash", txHash, "error", err)
+		}
+	case built.ChangeSats > 0:
+		if err := s.database.RecordBTCChangeAddress(db.BTCChangeAddressRow{
+			AddressType: string(s.keyService.BTCAddressType()),
+			ChangeIndex: changeIndex,
+			Address:     changeAddr,
+			TxHash:      txHash,
+			AmountSats:  built.ChangeSats,
+		}); err != nil {
+			slog.Error("failed to record BTC change address", "txHash", txHash, "error", err)
+		}
+	}
+
+	slog.Info("BTC payout fee bump broadcast",
+		"replacedTxHash", orig.TxID,
+		"txHash", txHash,
+		"replacedFeeSats", orig.Fee,
+		"feeSats", built.FeeSats,
+		"feeRate", feeRate,
+		"sweepID", replaced.SweepID,
+	)
+
+	return &models.FeeBumpResult{
+		SweepID:         replaced.SweepID,
+		TxHash:          txHash,
+		ReplacedTxHash:  orig.TxID,
+		Chain:           models.ChainBTC,
+		InputCount:      len(built.UTXOs),
+		OutputSats:      built.PayoutSats + built.ChangeSats,
+		FeeSats:         built.FeeSats,
+		FeeRate:         feeRate,
+		ReplacedFeeSats: orig.Fee,
+	}, nil
+}
+
+// payoutOutputs splits the outputs of the payout orig into its recipients, in
+// output order, and its change address: the last output when it pays one of the
+// change addresses, empty otherwise.
+func (s *BTCConsolidationService) payoutOutputs(orig *esploraTx) ([]models.PayoutRecipient, string, error) {
+	recipients := make([]models.PayoutRecipient, 0, len(orig.Vout))
+	for _, out := range orig.Vout {
+		if out.Address == "" {
+			return nil, "", fmt.Errorf("%w: %s has an output without an address", config.ErrTxNotReplaceable, orig.TxID)
+		}
+		recipients = append(recipients, models.PayoutRecipient{Address: out.Address, AmountSats: out.Value})
+	}
+	if len(recipients) < 2 {
+		return recipients, "", nil
+	}
+
+	last := recipients[len(recipients)-1].Address
+	change, err := s.database.LookupBTCAddress(last)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !change.Change) {
+		return recipients, "", nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("look up output address %s: %w", last, err)
+	}
+	return recipients[:len(recipients)-1], last, nil
+}
+
+// replacementFeeRate returns the fee rate of a replacement of orig: feeRate, or
+// the estimate when feeRate <= 0, raised to the minimum rate BIP-125 requires.
+// An explicit feeRate below that minimum is rejected.
+func (s *BTCConsolidationService) replacementFeeRate(ctx context.Context, orig *esploraTx, feeRate int64) (int64, error) {
+	// BIP-125: the replacement pays a higher rate, and its fee covers the replaced
+	// fee plus its own relay at the incremental rate.
+	origVsize := int64((orig.Weight + 3) / 4)
+	if origVsize <= 0 || orig.Fee <= 0 {
+		return 0, fmt.Errorf("%w: provider returned weight %d, fee %d for %s", config.ErrTxNotReplaceable, orig.Weight, orig.Fee, orig.TxID)
+	}
+	minFeeRate := (orig.Fee+origVsize-1)/origVsize + config.BTCIncrementalRelayFeeRate
+	if feeRate <= 0 {
+		estimate, err := s.feeEstimator.EstimateFee(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("estimate fee: %w", err)
+		}
+		feeRate = max(DefaultFeeRate(estimate), minFeeRate)
+	}
+	if feeRate < minFeeRate {
+		return 0, fmt.Errorf("%w: %d sat/vB, the replacement needs at least %d sat/vB", config.ErrFeeBumpTooLow, feeRate, minFeeRate)
+	}
+	return feeRate, nil
+}
+
+// replaceableInputs checks that orig is a replaceable consolidation of stored
+// addresses paying destAddr, and returns its inputs as UTXOs.
+func (s *BTCConsolidationService) replaceableInputs(orig *esploraTx, destAddr string) ([]models.UTXO, error) {
+	if len(orig.Vout) != 1 || orig.Vout[0].Address != destAddr {
+		return nil, fmt.Errorf("%w: %s is not a consolidation to %s", config.ErrTxNotReplaceable, orig.TxID, destAddr)
+	}
+	return s.signalledInputs(orig)
+}
+
+// signalledInputs checks that orig signals BIP-125 and spends only stored or
+// change addresses, and returns its inputs as UTXOs, flagged Change as needed.
+func (s *BTCConsolidationService) signalledInputs(orig *esploraTx) ([]models.UTXO, error) {
+	signals := false
+	utxos := make([]models.UTXO, len(orig.Vin))
+	for i, in := range orig.Vin {
+		signals = signals || in.Sequence <= config.BTCRBFSequence
+		stored, err := s.database.LookupBTCAddress(in.Prevout.Address)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: input %d spends %s, not a stored or change address", config.ErrTxNotReplaceable, i, in.Prevout.Address)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("look up input %d address: %w", i, err)
+		}
+		utxos[i] = models.UTXO{
+			TxID:         in.TxID,
+			Vout:         in.Vout,
+			Value:        in.Prevout.Value,
+			Confirmed:    true,
+			Address:      in.Prevout.Address,
+			AddressIndex: stored.AddressIndex,
+			Change:       stored.Change,
+		}
+	}
+
+	// Consolidations built before RBF signalling was added cannot be replaced.
+	if !signals {
+		return nil, fmt.Errorf("%w: %s does not signal BIP-125 replace-by-fee", config.ErrTxNotReplaceable, orig.TxID)
+	}
+
+	if err := ClassifyUTXOs(utxos, s.netParams); err != nil {
+		return nil, err
+	}
+	return utxos, nil
+}
+
+// fetchBTCTx fetches a transaction with its prevouts, trying each Esplora
+// provider in turn.
+func fetchBTCTx(ctx context.Context, client *http.Client, providerURLs []string, txHash string) (*esploraTx, error) {
+	if len(providerURLs) == 0 {
+		return nil, fmt.Errorf("fetch BTC TX %s: no provider URLs configured", txHash)
+	}
+
+	var lastErr error
+	for _, baseURL := range providerURLs {
+		url := baseURL + fmt.Sprintf(config.BTCTxPath, txHash)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("create TX request: %w", err)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = err
+			slog.Warn("BTC TX fetch failed", "provider", baseURL, "txHash", txHash, "error", err)
+			continue
+		}
+
+		var tx esploraTx
+		switch {
+		case resp.StatusCode == http.StatusNotFound:
+			lastErr = fmt.Errorf("%w: %s not known to %s (dropped from the mempool?)", config.ErrTxNotFound, txHash, baseURL)
+		case resp.StatusCode != http.StatusOK:
+			lastErr = fmt.Errorf("HTTP %d from %s", resp.StatusCode, baseURL)
+		default:
+			err = json.NewDecoder(resp.Body).Decode(&tx)
+			if err == nil {
+				resp.Body.Close()
+				return &tx, nil
+			}
+			lastErr = fmt.Errorf("decode TX response from %s: %w", baseURL, err)
+		}
+		resp.Body.Close()
+		slog.Warn("BTC TX fetch failed", "provider", baseURL, "txHash", txHash, "error", lastErr)
+	}
+	return nil, fmt.Errorf("fetch BTC TX %s: %w", txHash, lastErr)
+}
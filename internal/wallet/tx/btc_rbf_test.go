@@ -0,0 +1,431 @@
+package tx
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/wallet/db"
+)
+
+// esploraTxJSON describes built as the Esplora /tx/{txid} endpoint would, with
+// every input using sequence.
+func esploraTxJSON(t *testing.T, built *BTCBuiltTx, sequence uint32) []byte {
+	t.Helper()
+	vin := make([]map[string]any, len(built.UTXOs))
+	for i, u := range built.UTXOs {
+		vin[i] = map[string]any{
+			"txid":     u.TxID,
+			"vout":     u.Vout,
+			"sequence": sequence,
+			"prevout":  map[string]any{"scriptpubkey_address": u.Address, "value": u.Value},
+		}
+	}
+	dest, err := scriptAddress(built.Tx.TxOut[0].PkScript, &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := json.Marshal(map[string]any{
+		"txid":   built.Tx.TxHash().String(),
+		"weight": built.EstimatedVsize * 4,
+		"fee":    built.FeeSats,
+		"vin":    vin,
+		"vout":   []map[string]any{{"scriptpubkey_address": dest, "value": built.OutputSats}},
+		"status": map[string]any{"confirmed": false},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+// rbfInputIndices returns the address indices of the inputs of built, recorded on
+// the replaced row as for a chunk of a consolidation.
+func rbfInputIndices(built *BTCBuiltTx) []int {
+	indices := make([]int, len(built.UTXOs))
+	for i, u := range built.UTXOs {
+		indices[i] = u.AddressIndex
+	}
+	return indices
+}
+
+// newRBFTestService returns a BTC service over a database holding the inputs of
+// built as stored addresses and built as a broadcast consolidation awaiting
+// confirmation. The Esplora stub serves orig for the replaced transaction and
+// reports every other transaction confirmed.
+func newRBFTestService(t *testing.T, built *BTCBuiltTx, orig []byte) (*BTCConsolidationService, *recordingBroadcaster, *db.DB) {
+	t.Helper()
+	net := &chaincfg.TestNet3Params
+	database := setupGasTestDB(t)
+
+	stored := make([]models.Address, len(built.UTXOs))
+	for i, u := range built.UTXOs {
+		stored[i] = models.Address{Chain: models.ChainBTC, AddressIndex: u.AddressIndex, Address: u.Address}
+	}
+	if err := database.InsertAddressBatch(models.ChainBTC, stored); err != nil {
+		t.Fatal(err)
+	}
+
+	txHash := built.Tx.TxHash().String()
+	dest, _ := scriptAddress(built.Tx.TxOut[0].PkScript, net)
+	if err := database.CreateTxState(db.TxStateRow{
+		ID:          "orig-state",
+		SweepID:     "sweep-rbf",
+		Chain:       string(models.ChainBTC),
+		Token:       string(models.TokenNative),
+		FromAddress: "consolidated",
+		ToAddress:   dest,
+		Amount:      "1",
+		TxHash:      txHash,
+		Status:      config.TxStateConfirming,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.SetTxStateInputs("orig-state", rbfInputIndices(built)); err != nil {
+		t.Fatal(err)
+	}
+	for _, u := range built.UTXOs {
+		if _, err := database.InsertTransaction(models.Transaction{
+			Chain: models.ChainBTC, AddressIndex: u.AddressIndex, TxHash: txHash, Direction: "send",
+			Token: models.TokenNative, Amount: "1", FromAddress: u.Address, ToAddress: dest, Status: "pending",
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	esplora := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/status"):
+			w.Write([]byte(`{"confirmed":true}`))
+		case r.URL.Path == "/tx/"+txHash:
+			w.Write(orig)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(esplora.Close)
+
+	ks := NewKeyService(writeTempMnemonic(t, testMnemonic24), "testnet")
+	broadcaster := &recordingBroadcaster{}
+	svc := NewBTCConsolidationService(ks, nil, nil, broadcaster, database, net, esplora.Client(), []string{esplora.URL}, nil)
+	return svc, broadcaster, database
+}
+
+func TestBuildBTCConsolidationTx_SignalsRBF(t *testing.T) {
+	built := psbtTestConsolidation(t)
+	for i, in := range built.Tx.TxIn {
+		if in.Sequence != config.BTCRBFSequence {
+			t.Errorf("input %d sequence = %#x, want %#x", i, in.Sequence, config.BTCRBFSequence)
+		}
+	}
+}
+
+func TestBumpFee_ReplacesConsolidation(t *testing.T) {
+	built := psbtTestConsolidation(t)
+	txHash := built.Tx.TxHash().String()
+	svc, broadcaster, database := newRBFTestService(t, built, esploraTxJSON(t, built, config.BTCRBFSequence))
+
+	// The original paid 5 sat/vB: 5 is not a replacement.
+	if _, err := svc.BumpFee(context.Background(), txHash, 5); !errors.Is(err, config.ErrFeeBumpTooLow) {
+		t.Fatalf("BumpFee(5) error = %v, want ErrFeeBumpTooLow", err)
+	}
+	if len(broadcaster.sent) != 0 {
+		t.Fatal("a rejected bump must not broadcast")
+	}
+
+	result, err := svc.BumpFee(context.Background(), txHash, 20)
+	if err != nil {
+		t.Fatalf("BumpFee(20) error = %v", err)
+	}
+	if result.ReplacedTxHash != txHash || result.TxHash != "psbt-txhash" || result.SweepID != "sweep-rbf" ||
+		result.FeeRate != 20 || result.ReplacedFeeSats != built.FeeSats || result.FeeSats <= built.FeeSats ||
+		result.OutputSats != built.TotalInputSats-result.FeeSats {
+		t.Errorf("BumpFee() = %+v", result)
+	}
+
+	// The replacement spends exactly the same inputs to the same destination.
+	if len(broadcaster.sent) != 1 {
+		t.Fatalf("broadcast %d transactions, want 1", len(broadcaster.sent))
+	}
+	raw, err := hex.DecodeString(broadcaster.sent[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var replacement wire.MsgTx
+	if err := replacement.Deserialize(bytes.NewReader(raw)); err != nil {
+		t.Fatal(err)
+	}
+	if len(replacement.TxIn) != len(built.Tx.TxIn) || len(replacement.TxOut) != 1 ||
+		string(replacement.TxOut[0].PkScript) != string(built.Tx.TxOut[0].PkScript) {
+		t.Fatalf("replacement has %d inputs and %d outputs, want %d inputs to the same output", len(replacement.TxIn), len(replacement.TxOut), len(built.Tx.TxIn))
+	}
+	for i, in := range replacement.TxIn {
+		if in.PreviousOutPoint != built.Tx.TxIn[i].PreviousOutPoint || in.Sequence != config.BTCRBFSequence {
+			t.Errorf("replacement input %d = %v seq %#x, want %v", i, in.PreviousOutPoint, in.Sequence, built.Tx.TxIn[i].PreviousOutPoint)
+		}
+	}
+
+	rows, err := database.GetTxStatesBySweepID("sweep-rbf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("tx_state rows = %d, want the replaced and the replacement", len(rows))
+	}
+	for _, row := range rows {
+		if row.ID == "orig-state" {
+			continue
+		}
+		// A resume retries every address of the replaced chunk.
+		inputs, err := database.GetTxStateInputs(row.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := len(rbfInputIndices(built)); len(inputs) != want {
+			t.Errorf("replacement tx_state inputs = %v, want the %d inputs of the replaced row", inputs, want)
+		}
+	}
+	replaced, err := database.GetTxStateByHash(string(models.ChainBTC), txHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replaced.Status != config.TxStateSuperseded || replaced.Error != "replaced by psbt-txhash" {
+		t.Errorf("replaced tx_state = %s (%q), want superseded", replaced.Status, replaced.Error)
+	}
+	recorded, err := database.GetTransactionByHash(models.ChainBTC, txHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recorded.Status != config.TxStateSuperseded {
+		t.Errorf("replaced transaction status = %s, want superseded", recorded.Status)
+	}
+
+	// A superseded transaction cannot be bumped again.
+	if _, err := svc.BumpFee(context.Background(), txHash, 40); !errors.Is(err, config.ErrTxNotReplaceable) {
+		t.Errorf("second BumpFee() error = %v, want ErrTxNotReplaceable", err)
+	}
+}
+
+func TestBumpFee_Rejects(t *testing.T) {
+	built := psbtTestConsolidation(t)
+	txHash := built.Tx.TxHash().String()
+
+	// Consolidations built before RBF signalling are final.
+	svc, broadcaster, _ := newRBFTestService(t, built, esploraTxJSON(t, built, wire.MaxTxInSequenceNum))
+	if _, err := svc.BumpFee(context.Background(), txHash, 20); !errors.Is(err, config.ErrTxNotReplaceable) {
+		t.Errorf("non-signalling BumpFee() error = %v, want ErrTxNotReplaceable", err)
+	}
+	if _, err := svc.BumpFee(context.Background(), strings.Repeat("ab", 32), 20); !errors.Is(err, config.ErrTxNotFound) {
+		t.Errorf("unknown hash BumpFee() error = %v, want ErrTxNotFound", err)
+	}
+	if len(broadcaster.sent) != 0 {
+		t.Errorf("rejected bumps broadcast %d transactions", len(broadcaster.sent))
+	}
+}
+
+// esploraPayoutJSON describes the payout built as the Esplora /tx/{txid} endpoint
+// would, with every input signalling replace-by-fee.
+func esploraPayoutJSON(t *testing.T, built *BTCPayoutTx) []byte {
+	t.Helper()
+	vin := make([]map[string]any, len(built.UTXOs))
+	for i, u := range built.UTXOs {
+		vin[i] = map[string]any{
+			"txid":     u.TxID,
+			"vout":     u.Vout,
+			"sequence": config.BTCRBFSequence,
+			"prevout":  map[string]any{"scriptpubkey_address": u.Address, "value": u.Value},
+		}
+	}
+	vout := make([]map[string]any, len(built.Tx.TxOut))
+	for i, out := range built.Tx.TxOut {
+		addr, err := scriptAddress(out.PkScript, &chaincfg.TestNet3Params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		vout[i] = map[string]any{"scriptpubkey_address": addr, "value": out.Value}
+	}
+	raw, err := json.Marshal(map[string]any{
+		"txid":   built.Tx.TxHash().String(),
+		"weight": built.EstimatedVsize * 4,
+		"fee":    built.FeeSats,
+		"vin":    vin,
+		"vout":   vout,
+		"status": map[string]any{"confirmed": false},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestBumpFee_ReplacesPayout(t *testing.T) {
+	net := &chaincfg.TestNet3Params
+	database := setupGasTestDB(t)
+	ks := NewKeyService(writeTempMnemonic(t, testMnemonic24), "testnet")
+
+	// The payout spends the change of an earlier payout (index 0) and sends its own
+	// change to index 1.
+	utxos := payoutTestUTXOs(t)
+	stored := make([]models.Address, len(utxos))
+	for i, u := range utxos {
+		stored[i] = models.Address{Chain: models.ChainBTC, AddressIndex: u.AddressIndex, Address: u.Address}
+	}
+	if err := database.InsertAddressBatch(models.ChainBTC, stored); err != nil {
+		t.Fatal(err)
+	}
+	changeAddrs := make([]string, 2)
+	for i := range changeAddrs {
+		addr, err := ks.DeriveBTCChangeAddress(uint32(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		changeAddrs[i] = addr
+	}
+	if err := database.RecordBTCChangeAddress(db.BTCChangeAddressRow{
+		AddressType: string(models.BTCAddressP2WPKH), ChangeIndex: 0, Address: changeAddrs[0], TxHash: "earlier", AmountSats: 90000,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	changeUTXOs := []models.UTXO{{TxID: psbtTestFundingTx(t, changeAddrs[0], 0, 90000).TxHash().String(), Value: 90000, Address: changeAddrs[0], Change: true}}
+	if err := ClassifyUTXOs(changeUTXOs, net); err != nil {
+		t.Fatal(err)
+	}
+
+	recipients := []models.PayoutRecipient{
+		{Address: utxos[0].Address, AmountSats: 45000},
+		{Address: utxos[2].Address, AmountSats: 30000},
+	}
+	built, err := BuildBTCPayoutTx(BTCPayoutParams{
+		UTXOs:         append(utxos, changeUTXOs...),
+		Recipients:    recipients,
+		ChangeAddress: changeAddrs[1],
+		FeeRate:       4,
+		NetParams:     net,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(built.UTXOs) != 1 || !built.UTXOs[0].Change || built.ChangeSats == 0 {
+		t.Fatalf("payout spends %+v with %d sats change, want the change UTXO alone", built.UTXOs, built.ChangeSats)
+	}
+	txHash := built.Tx.TxHash().String()
+	if err := database.RecordBTCChangeAddress(db.BTCChangeAddressRow{
+		AddressType: string(models.BTCAddressP2WPKH), ChangeIndex: 1, Address: changeAddrs[1], TxHash: txHash, AmountSats: built.ChangeSats,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.CreateTxState(db.TxStateRow{
+		ID:          "orig-payout",
+		SweepID:     "sweep-payout",
+		Chain:       string(models.ChainBTC),
+		Token:       string(models.TokenNative),
+		FromAddress: config.FromAddressBTCPayout,
+		ToAddress:   recipients[0].Address,
+		Amount:      "75000",
+		TxHash:      txHash,
+		Status:      config.TxStateConfirming,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.RecordBTCPayout("sweep-payout", txHash, recipients); err != nil {
+		t.Fatal(err)
+	}
+
+	orig := esploraPayoutJSON(t, built)
+	esplora := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/status"):
+			w.Write([]byte(`{"confirmed":true}`))
+		case r.URL.Path == "/tx/"+txHash:
+			w.Write(orig)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer esplora.Close()
+	broadcaster := &recordingBroadcaster{}
+	svc := NewBTCConsolidationService(ks, nil, nil, broadcaster, database, net, esplora.Client(), []string{esplora.URL}, nil)
+
+	result, err := svc.BumpFee(context.Background(), txHash, 20)
+	if err != nil {
+		t.Fatalf("BumpFee() error = %v", err)
+	}
+	if result.ReplacedTxHash != txHash || result.TxHash != "psbt-txhash" || result.SweepID != "sweep-payout" ||
+		result.InputCount != 1 || result.FeeSats <= built.FeeSats || result.OutputSats != built.TotalInputSats-result.FeeSats {
+		t.Errorf("BumpFee() = %+v", result)
+	}
+
+	// The replacement pays the same recipients, signs the change input with the
+	// internal-chain key and takes the extra fee out of the change.
+	if len(broadcaster.sent) != 1 {
+		t.Fatalf("broadcast %d transactions, want 1", len(broadcaster.sent))
+	}
+	raw, err := hex.DecodeString(broadcaster.sent[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var replacement wire.MsgTx
+	if err := replacement.Deserialize(bytes.NewReader(raw)); err != nil {
+		t.Fatal(err)
+	}
+	if len(replacement.TxIn) != 1 || replacement.TxIn[0].PreviousOutPoint != built.Tx.TxIn[0].PreviousOutPoint || len(replacement.TxIn[0].Witness) == 0 {
+		t.Fatalf("replacement inputs = %+v, want the signed change input", replacement.TxIn)
+	}
+	if len(replacement.TxOut) != 3 {
+		t.Fatalf("replacement has %d outputs, want 2 recipients and change", len(replacement.TxOut))
+	}
+	for i := range recipients {
+		if !bytes.Equal(replacement.TxOut[i].PkScript, built.Tx.TxOut[i].PkScript) || replacement.TxOut[i].Value != recipients[i].AmountSats {
+			t.Errorf("replacement output %d = %d sats, want %d to the same recipient", i, replacement.TxOut[i].Value, recipients[i].AmountSats)
+		}
+	}
+	newChange := replacement.TxOut[2].Value
+	if !bytes.Equal(replacement.TxOut[2].PkScript, built.Tx.TxOut[2].PkScript) || newChange != built.ChangeSats-(result.FeeSats-built.FeeSats) {
+		t.Errorf("replacement change = %d sats, want %d to the same change address", newChange, built.ChangeSats-(result.FeeSats-built.FeeSats))
+	}
+
+	replaced, err := database.GetTxStateByHash(string(models.ChainBTC), txHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replaced.Status != config.TxStateSuperseded {
+		t.Errorf("replaced tx_state = %s, want superseded", replaced.Status)
+	}
+	rows, err := database.GetTxStatesBySweepID("sweep-payout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("tx_state rows = %d, want the replaced and the replacement", len(rows))
+	}
+	for _, row := range rows {
+		if row.FromAddress != config.FromAddressBTCPayout {
+			t.Errorf("tx_state %s from %q, want a payout row", row.ID, row.FromAddress)
+		}
+	}
+
+	payouts, total, err := database.ListBTCPayouts(1, 10)
+	if err != nil || total != 4 {
+		t.Fatalf("ListBTCPayouts() = %d rows, %v, want the replaced and replacement outputs", total, err)
+	}
+	for _, p := range payouts {
+		if p.TxHash == txHash && p.Status != config.TxStateSuperseded {
+			t.Errorf("replaced payout output %d status = %s, want superseded", p.Vout, p.Status)
+		}
+	}
+	changes, err := database.ListBTCChangeAddresses()
+	if err != nil || len(changes) != 2 || changes[1].TxHash != "psbt-txhash" || changes[1].AmountSats != newChange {
+		t.Errorf("change addresses after bump = %+v, %v, want index 1 moved to the replacement", changes, err)
+	}
+}
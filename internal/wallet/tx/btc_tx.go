@@ -3,8 +3,10 @@ package tx
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -13,6 +15,7 @@ import (
 
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/txscript"
@@ -21,6 +24,7 @@ import (
 	"github.com/Fantasim/hdpay/internal/shared/config"
 	"github.com/Fantasim/hdpay/internal/wallet/db"
 	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/wallet/hd"
 )
 
 // SigningUTXO extends a UTXO with the data needed for signing.
@@ -35,6 +39,7 @@ type BTCBuildParams struct {
 	UTXOs       []models.UTXO
 	DestAddress string
 	FeeRate     int64 // sat/vB
+	FeeSats     int64 // exact fee, used as is instead of FeeRate plus the safety margin; 0 = FeeRate
 	NetParams   *chaincfg.Params
 }
 
@@ -57,8 +62,57 @@ func EstimateBTCVsize(numInputs, numOutputs int) int {
 	return (weight + 3) / 4
 }
 
+// btcInputWeight returns the estimated weight of one input spending an address of
+// addrType. An empty type is treated as P2WPKH.
+func btcInputWeight(addrType models.BTCAddressType) (int, error) {
+	switch addrType {
+	case models.BTCAddressP2WPKH, "":
+		return config.BTCP2WPKHInputNonWitWU + config.BTCP2WPKHInputWitWU, nil
+	case models.BTCAddressP2TR:
+		return config.BTCP2TRInputNonWitWU + config.BTCP2TRInputWitWU, nil
+	case models.BTCAddressP2SHP2WPKH:
+		return config.BTCP2SHP2WPKHInputNonWitWU + config.BTCP2SHP2WPKHInputWitWU, nil
+	case models.BTCAddressP2PKH:
+		return config.BTCP2PKHInputNonWitWU + config.BTCP2PKHInputWitWU, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", hd.ErrUnsupportedAddressType, addrType)
+	}
+}
+
+// EstimateBTCTxWeight returns the estimated weight of a transaction spending the
+// given UTXOs (mixed address types allowed) to outputs with the given pkScripts.
+func EstimateBTCTxWeight(utxos []models.UTXO, outputScripts [][]byte) (int, error) {
+	weight := config.BTCTxOverheadWU
+	for _, u := range utxos {
+		w, err := btcInputWeight(u.AddressType)
+		if err != nil {
+			return 0, fmt.Errorf("input %s:%d: %w", u.TxID, u.Vout, err)
+		}
+		weight += w
+	}
+	for _, script := range outputScripts {
+		weight += config.BTCOutputBaseWU + len(script)*4
+	}
+	return weight, nil
+}
+
+// ClassifyUTXOs sets AddressType on every UTXO from its address, so that fee
+// estimation and signing use the right script type for each input.
+func ClassifyUTXOs(utxos []models.UTXO, netParams *chaincfg.Params) error {
+	for i := range utxos {
+		addrType, err := hd.BTCAddressTypeOf(utxos[i].Address, netParams)
+		if err != nil {
+			return fmt.Errorf("classify UTXO %s:%d: %w", utxos[i].TxID, utxos[i].Vout, err)
+		}
+		utxos[i].AddressType = addrType
+	}
+	return nil
+}
+
 // BuildBTCConsolidationTx builds an unsigned multi-input consolidation transaction.
-// All UTXOs are spent to a single destination address.
+// All UTXOs are spent to a single destination address. Inputs may mix address types;
+// each UTXO's AddressType drives its share of the fee estimate. Every input signals
+// BIP-125 replace-by-fee (see BumpFee).
 func BuildBTCConsolidationTx(params BTCBuildParams) (*BTCBuiltTx, error) {
 	if len(params.UTXOs) == 0 {
 		return nil, fmt.Errorf("%w: no UTXOs provided", config.ErrInsufficientUTXO)
@@ -92,13 +146,23 @@ func BuildBTCConsolidationTx(params BTCBuildParams) (*BTCBuiltTx, error) {
 		totalInputSats += u.Value
 	}
 
-	estimatedVsize := EstimateBTCVsize(len(params.UTXOs), 1)
-	baseFee := params.FeeRate * int64(estimatedVsize)
-	// Add safety margin to prevent fee underestimation. Actual signed TX may be
-	// slightly larger than estimated vsize due to variable-length witness data.
-	safetyMargin := baseFee * int64(config.BTCFeeSafetyMarginPct) / 100
-	if safetyMargin < 1 {
-		safetyMargin = 1 // At least 1 sat safety margin.
+	estimatedWeight, err := EstimateBTCTxWeight(params.UTXOs, [][]byte{destScript})
+	if err != nil {
+		return nil, fmt.Errorf("estimate TX weight: %w", err)
+	}
+	estimatedVsize := (estimatedWeight + 3) / 4
+	var baseFee, safetyMargin int64
+	if params.FeeSats > 0 {
+		// The caller sized the fee itself, e.g. a CPFP child paying for its package.
+		baseFee = params.FeeSats
+	} else {
+		baseFee = params.FeeRate * int64(estimatedVsize)
+		// Add safety margin to prevent fee underestimation. Actual signed TX may be
+		// slightly larger than estimated vsize due to variable-length witness data.
+		safetyMargin = baseFee * int64(config.BTCFeeSafetyMarginPct) / 100
+		if safetyMargin < 1 {
+			safetyMargin = 1 // At least 1 sat safety margin.
+		}
 	}
 	feeSats := baseFee + safetyMargin
 	outputSats := totalInputSats - feeSats
@@ -123,9 +187,6 @@ func BuildBTCConsolidationTx(params BTCBuildParams) (*BTCBuiltTx, error) {
 	}
 
 	// Check weight limit.
-	estimatedWeight := config.BTCTxOverheadWU +
-		len(params.UTXOs)*(config.BTCP2WPKHInputNonWitWU+config.BTCP2WPKHInputWitWU) +
-		1*config.BTCP2WPKHOutputWU
 	if estimatedWeight > config.BTCMaxTxWeight {
 		return nil, fmt.Errorf("%w: estimated weight %d exceeds max %d",
 			config.ErrTxTooLarge, estimatedWeight, config.BTCMaxTxWeight)
@@ -141,7 +202,8 @@ func BuildBTCConsolidationTx(params BTCBuildParams) (*BTCBuiltTx, error) {
 		}
 		outPoint := wire.NewOutPoint(hash, u.Vout)
 		txIn := wire.NewTxIn(outPoint, nil, nil)
-		txIn.Sequence = wire.MaxTxInSequenceNum
+		// Signal BIP-125 replaceability so a stuck consolidation can be fee-bumped.
+		txIn.Sequence = config.BTCRBFSequence
 		msgTx.AddTxIn(txIn)
 	}
 
@@ -163,9 +225,11 @@ func BuildBTCConsolidationTx(params BTCBuildParams) (*BTCBuiltTx, error) {
 	}, nil
 }
 
-// SignBTCTx signs each input with P2WPKH witness data.
-// Uses MultiPrevOutFetcher for correct multi-input signing.
-// Each private key is zeroed after signing its input.
+// SignBTCTx signs each input according to the script type of its PKScript:
+// P2WPKH and nested P2SH-P2WPKH with BIP-143 witness signatures, P2TR with a
+// BIP-86 Schnorr key-path signature and P2PKH with a legacy scriptSig.
+// Uses MultiPrevOutFetcher for correct multi-input signing (Taproot sighashes
+// commit to every prevout). Each private key is zeroed after signing its input.
 func SignBTCTx(msgTx *wire.MsgTx, signingUTXOs []SigningUTXO) error {
 	if len(msgTx.TxIn) != len(signingUTXOs) {
 		return fmt.Errorf("input count mismatch: tx has %d inputs, got %d signing UTXOs",
@@ -188,35 +252,23 @@ func SignBTCTx(msgTx *wire.MsgTx, signingUTXOs []SigningUTXO) error {
 		})
 	}
 
-	// Compute sighash midstate ONCE for all inputs (BIP-143 optimization).
+	// Compute sighash midstate ONCE for all inputs (BIP-143 / BIP-341 optimization).
 	sigHashes := txscript.NewTxSigHashes(msgTx, prevOutFetcher)
 
 	// Sign each input.
 	for i, su := range signingUTXOs {
-		witness, err := txscript.WitnessSignature(
-			msgTx,
-			sigHashes,
-			i,
-			su.Value,
-			su.PKScript,
-			txscript.SigHashAll,
-			su.PrivKey,
-			true, // compressed pubkey
-		)
-		if err != nil {
+		if err := signBTCInput(msgTx, sigHashes, i, su); err != nil {
 			return fmt.Errorf("sign input %d (address %s, index %d): %w",
 				i, su.Address, su.AddressIndex, err)
 		}
 
-		msgTx.TxIn[i].Witness = witness
-		// SignatureScript stays nil for native SegWit P2WPKH.
-
 		// Zero the private key after signing this input.
 		su.PrivKey.Zero()
 
 		slog.Debug("BTC TX input signed",
 			"inputIndex", i,
 			"addressIndex", su.AddressIndex,
+			"scriptClass", txscript.GetScriptClass(su.PKScript).String(),
 			"value", su.Value,
 		)
 	}
@@ -225,6 +277,70 @@ func SignBTCTx(msgTx *wire.MsgTx, signingUTXOs []SigningUTXO) error {
 	return nil
 }
 
+// signBTCInput fills in the witness and/or scriptSig of input i.
+func signBTCInput(msgTx *wire.MsgTx, sigHashes *txscript.TxSigHashes, i int, su SigningUTXO) error {
+	switch class := txscript.GetScriptClass(su.PKScript); class {
+	case txscript.WitnessV0PubKeyHashTy:
+		witness, err := txscript.WitnessSignature(
+			msgTx, sigHashes, i, su.Value, su.PKScript,
+			txscript.SigHashAll, su.PrivKey, true, // compressed pubkey
+		)
+		if err != nil {
+			return err
+		}
+		msgTx.TxIn[i].Witness = witness
+		// SignatureScript stays nil for native SegWit P2WPKH.
+
+	case txscript.WitnessV1TaprootTy:
+		// BIP-86 key-path spend: the output key is the internal key tweaked with an
+		// empty script tree, which TaprootWitnessSignature applies to the private key.
+		witness, err := txscript.TaprootWitnessSignature(
+			msgTx, sigHashes, i, su.Value, su.PKScript,
+			txscript.SigHashDefault, su.PrivKey,
+		)
+		if err != nil {
+			return err
+		}
+		msgTx.TxIn[i].Witness = witness
+
+	case txscript.ScriptHashTy:
+		// Nested SegWit: the scriptSig pushes the P2WPKH redeem script, the witness
+		// signs against that witness program.
+		keyHash := btcutil.Hash160(su.PrivKey.PubKey().SerializeCompressed())
+		redeemScript := hd.P2SHP2WPKHRedeemScript(keyHash)
+		if !bytes.Equal(btcutil.Hash160(redeemScript), su.PKScript[2:22]) {
+			return fmt.Errorf("P2SH script does not commit to a P2WPKH redeem script for this key")
+		}
+		witness, err := txscript.WitnessSignature(
+			msgTx, sigHashes, i, su.Value, redeemScript,
+			txscript.SigHashAll, su.PrivKey, true,
+		)
+		if err != nil {
+			return err
+		}
+		sigScript, err := txscript.NewScriptBuilder().AddData(redeemScript).Script()
+		if err != nil {
+			return fmt.Errorf("build P2SH scriptSig: %w", err)
+		}
+		msgTx.TxIn[i].Witness = witness
+		msgTx.TxIn[i].SignatureScript = sigScript
+
+	case txscript.PubKeyHashTy:
+		sigScript, err := txscript.SignatureScript(
+			msgTx, i, su.PKScript, txscript.SigHashAll, su.PrivKey, true,
+		)
+		if err != nil {
+			return err
+		}
+		msgTx.TxIn[i].SignatureScript = sigScript
+
+	default:
+		return fmt.Errorf("%w: script class %s", hd.ErrUnsupportedAddressType, class)
+	}
+
+	return nil
+}
+
 // SerializeBTCTx serializes a signed transaction to hex.
 func SerializeBTCTx(msgTx *wire.MsgTx) (string, error) {
 	var buf bytes.Buffer
@@ -263,6 +379,9 @@ type BTCConsolidationService struct {
 	httpClient       *http.Client
 	confirmationURLs []string // Esplora-compatible base URLs for TX status polling
 	txHub            *TxSSEHub
+	keyOrigins       BTCKeyOriginSource // describes inputs of exported PSBTs; nil = no PSBT in previews
+	chunkMaxInputs   int                // per-transaction input cap of a consolidation
+	chunkMaxVsize    int                // per-transaction vsize cap of a consolidation
 }
 
 // NewBTCConsolidationService creates the consolidation orchestrator.
@@ -281,7 +400,7 @@ func NewBTCConsolidationService(
 		"network", netParams.Name,
 		"confirmationURLs", confirmationURLs,
 	)
-	return &BTCConsolidationService{
+	s := &BTCConsolidationService{
 		keyService:       keyService,
 		utxoFetcher:      utxoFetcher,
 		feeEstimator:     feeEstimator,
@@ -291,58 +410,126 @@ func NewBTCConsolidationService(
 		httpClient:       httpClient,
 		confirmationURLs: confirmationURLs,
 		txHub:            txHub,
+		chunkMaxInputs:   config.BTCMaxInputsPerTx,
+		chunkMaxVsize:    config.BTCDefaultChunkMaxVsize,
+	}
+	if keyService != nil {
+		s.keyOrigins = keyService
 	}
+	return s
 }
 
-// Preview performs a dry run of the consolidation: fetches UTXOs, estimates fee,
-// and returns the expected transaction details without signing or broadcasting.
-func (s *BTCConsolidationService) Preview(ctx context.Context, addresses []models.Address, destAddr string, feeRate int64) (*models.SendPreview, error) {
+// SetKeyOriginSource replaces where PSBT key origins come from, e.g. an
+// XPubKeyOrigin so previews carry a PSBT while the seed stays offline.
+// Must be called before the service is shared.
+func (s *BTCConsolidationService) SetKeyOriginSource(src BTCKeyOriginSource) {
+	s.keyOrigins = src
+}
+
+// Preview performs a dry run of the consolidation: fetches the UTXOs sel selects,
+// estimates fee, and returns the expected transaction details without signing or
+// broadcasting. feeRate <= 0 takes the estimate's rate for feeTarget (empty =
+// config.BTCDefaultFeeTarget); an estimated fee rate comes with its target and the
+// per-source breakdown it was chosen from. Consolidations over the chunk limits are split into several
+// transactions, each previewed with its own fee. When key origins are available a
+// single-transaction preview also carries the unsigned transaction as a base64
+// PSBT, to be signed offline with hdpay sign-psbt.
+func (s *BTCConsolidationService) Preview(ctx context.Context, addresses []models.Address, destAddr string, feeRate int64, feeTarget string, sel models.BTCInputSelection) (*models.SendPreview, error) {
 	slog.Info("BTC consolidation preview",
 		"addressCount", len(addresses),
 		"destAddress", destAddr,
 		"requestedFeeRate", feeRate,
+		"feeTarget", feeTarget,
+		"inputSelection", sel,
 	)
 
-	utxos, err := s.utxoFetcher.FetchAllUTXOs(ctx, addresses)
+	utxos, err := s.fetchSelectedUTXOs(ctx, addresses, sel)
 	if err != nil {
 		return nil, fmt.Errorf("fetch UTXOs for preview: %w", err)
 	}
 
 	if len(utxos) == 0 {
-		return nil, fmt.Errorf("%w: no confirmed UTXOs found", config.ErrInsufficientUTXO)
+		return nil, fmt.Errorf("%w: no spendable UTXOs match the input selection", config.ErrInsufficientUTXO)
+	}
+
+	if err := ClassifyUTXOs(utxos, s.netParams); err != nil {
+		return nil, err
 	}
 
 	// Use provided feeRate or estimate.
+	var feeBreakdown *models.FeeEstimate
+	var usedTarget string
 	if feeRate <= 0 {
 		estimate, err := s.feeEstimator.EstimateFee(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("estimate fee for preview: %w", err)
 		}
-		feeRate = DefaultFeeRate(estimate)
+		feeRate, usedTarget, err = FeeRateForTarget(estimate, feeTarget)
+		if err != nil {
+			return nil, err
+		}
+		feeBreakdown = estimate
 	}
 
-	built, err := BuildBTCConsolidationTx(BTCBuildParams{
-		UTXOs:       utxos,
-		DestAddress: destAddr,
-		FeeRate:     feeRate,
-		NetParams:   s.netParams,
-	})
+	chunks, err := s.chunkUTXOs(utxos, destAddr)
 	if err != nil {
-		return nil, fmt.Errorf("build preview TX: %w", err)
+		return nil, fmt.Errorf("split preview TX: %w", err)
 	}
 
 	preview := &models.SendPreview{
-		Chain:          models.ChainBTC,
-		InputCount:     len(built.UTXOs),
-		TotalInputSats: built.TotalInputSats,
-		OutputSats:     built.OutputSats,
-		FeeSats:        built.FeeSats,
-		FeeRate:        feeRate,
-		EstimatedVsize: built.EstimatedVsize,
-		DestAddress:    destAddr,
+		Chain:        models.ChainBTC,
+		FeeRate:      feeRate,
+		FeeTarget:    usedTarget,
+		DestAddress:  destAddr,
+		Chunks:       make([]models.BTCChunkPreview, 0, len(chunks)),
+		FeeBreakdown: feeBreakdown,
+	}
+
+	var built *BTCBuiltTx
+	for i, chunk := range chunks {
+		built, err = BuildBTCConsolidationTx(BTCBuildParams{
+			UTXOs:       chunk,
+			DestAddress: destAddr,
+			FeeRate:     feeRate,
+			NetParams:   s.netParams,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("build preview TX %d of %d: %w", i+1, len(chunks), err)
+		}
+
+		preview.Chunks = append(preview.Chunks, models.BTCChunkPreview{
+			InputCount:     len(built.UTXOs),
+			TotalInputSats: built.TotalInputSats,
+			OutputSats:     built.OutputSats,
+			FeeSats:        built.FeeSats,
+			EstimatedVsize: built.EstimatedVsize,
+		})
+		preview.InputCount += len(built.UTXOs)
+		preview.TotalInputSats += built.TotalInputSats
+		preview.OutputSats += built.OutputSats
+		preview.FeeSats += built.FeeSats
+		preview.EstimatedVsize += built.EstimatedVsize
+	}
+
+	// A PSBT holds one transaction; chunked consolidations are signed online only.
+	if s.keyOrigins != nil && len(chunks) == 1 {
+		var packet *psbt.Packet
+		prevTxs, err := s.fetchPSBTPrevTxs(ctx, built.UTXOs)
+		if err == nil {
+			packet, err = NewBTCConsolidationPSBT(built, s.keyOrigins, prevTxs, s.netParams)
+		}
+		if err == nil {
+			preview.PSBT, err = packet.B64Encode()
+		}
+		if err != nil {
+			// The online flow does not need the PSBT: an unplugged wallet disk or an
+			// xpub of another address type must not fail the preview.
+			slog.Warn("BTC preview without PSBT", "error", err)
+		}
 	}
 
 	slog.Info("BTC consolidation preview complete",
+		"txCount", len(preview.Chunks),
 		"inputCount", preview.InputCount,
 		"totalInputSats", preview.TotalInputSats,
 		"outputSats", preview.OutputSats,
@@ -352,53 +539,71 @@ func (s *BTCConsolidationService) Preview(ctx context.Context, addresses []model
 	return preview, nil
 }
 
-// Execute performs the full consolidation: fetch UTXOs → validate → build → sign → broadcast → confirm → record.
+// fetchPSBTPrevTxs fetches the previous transactions a PSBT carries for the
+// P2PKH and P2SH-P2WPKH inputs among utxos, keyed by txid.
+func (s *BTCConsolidationService) fetchPSBTPrevTxs(ctx context.Context, utxos []models.UTXO) (map[chainhash.Hash]*wire.MsgTx, error) {
+	prevTxs := make(map[chainhash.Hash]*wire.MsgTx)
+	for _, u := range utxos {
+		if !needsPrevTx(u.AddressType) {
+			continue
+		}
+		hash, err := chainhash.NewHashFromStr(u.TxID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid UTXO txid %q: %w", u.TxID, err)
+		}
+		if _, ok := prevTxs[*hash]; ok {
+			continue
+		}
+		prevTx, err := s.utxoFetcher.FetchRawTx(ctx, u.TxID)
+		if err != nil {
+			return nil, fmt.Errorf("fetch previous transaction %s: %w", u.TxID, err)
+		}
+		prevTxs[*hash] = prevTx
+	}
+	return prevTxs, nil
+}
+
+// Execute performs the full consolidation: fetch UTXOs → validate → split → build → sign → broadcast → confirm → record.
+// The UTXOs sel selects are spent at the fee rate chosen as in Preview.
+// Consolidations over the chunk limits are sent as several transactions, one after
+// the other under sweepID, each with its own tx_state row; a failed transaction
+// does not stop the next ones. An error is returned only when no transaction was
+// broadcast.
 // If expectedInputCount > 0, validates that re-fetched UTXOs haven't diverged significantly from preview.
-func (s *BTCConsolidationService) Execute(ctx context.Context, addresses []models.Address, destAddr string, feeRate int64, sweepID string, expectedInputCount int, expectedTotalSats int64) (*models.SendResult, error) {
+func (s *BTCConsolidationService) Execute(ctx context.Context, addresses []models.Address, destAddr string, feeRate int64, feeTarget string, sweepID string, expectedInputCount int, expectedTotalSats int64, sel models.BTCInputSelection) (*models.SendResult, error) {
 	slog.Info("BTC consolidation execute",
 		"addressCount", len(addresses),
 		"destAddress", destAddr,
 		"requestedFeeRate", feeRate,
+		"feeTarget", feeTarget,
 		"sweepID", sweepID,
 		"expectedInputCount", expectedInputCount,
 		"expectedTotalSats", expectedTotalSats,
+		"inputSelection", sel,
 	)
 	start := time.Now()
 
-	// Create tx_state row for this consolidated TX.
-	txStateID := GenerateTxStateID()
-	txState := db.TxStateRow{
-		ID:           txStateID,
-		SweepID:      sweepID,
-		Chain:        string(models.ChainBTC),
-		Token:        string(models.TokenNative),
-		AddressIndex: 0, // BTC consolidation is multi-input, no single index
-		FromAddress:  "consolidated",
-		ToAddress:    destAddr,
-		Amount:       "0", // Updated after building
-		Status:       config.TxStatePending,
-	}
-	if err := s.database.CreateTxState(txState); err != nil {
-		slog.Error("failed to create BTC tx_state", "error", err)
-		// Non-blocking: continue even if tx_state write fails
-	}
-
-	// 1. Fetch UTXOs.
-	utxos, err := s.utxoFetcher.FetchAllUTXOs(ctx, addresses)
+	// 1. Fetch the selected UTXOs.
+	utxos, err := s.fetchSelectedUTXOs(ctx, addresses, sel)
 	if err != nil {
-		s.updateTxState(txStateID, config.TxStateFailed, "", fmt.Sprintf("fetch UTXOs: %s", err))
+		s.recordFailedConsolidation(sweepID, destAddr, addresses, fmt.Sprintf("fetch UTXOs: %s", err))
 		return nil, fmt.Errorf("fetch UTXOs: %w", err)
 	}
 
 	if len(utxos) == 0 {
-		s.updateTxState(txStateID, config.TxStateFailed, "", "no confirmed UTXOs found")
-		return nil, fmt.Errorf("%w: no confirmed UTXOs found", config.ErrInsufficientUTXO)
+		s.recordFailedConsolidation(sweepID, destAddr, addresses, "no spendable UTXOs match the input selection")
+		return nil, fmt.Errorf("%w: no spendable UTXOs match the input selection", config.ErrInsufficientUTXO)
+	}
+
+	if err := ClassifyUTXOs(utxos, s.netParams); err != nil {
+		s.recordFailedConsolidation(sweepID, destAddr, addresses, err.Error())
+		return nil, err
 	}
 
 	// 1b. Validate UTXOs against preview expectations (if provided).
 	if expectedInputCount > 0 {
 		if err := ValidateUTXOsAgainstPreview(utxos, expectedInputCount, expectedTotalSats); err != nil {
-			s.updateTxState(txStateID, config.TxStateFailed, "", fmt.Sprintf("UTXO validation: %s", err))
+			s.recordFailedConsolidation(sweepID, destAddr, addresses, fmt.Sprintf("UTXO validation: %s", err))
 			return nil, err
 		}
 	}
@@ -407,50 +612,75 @@ func (s *BTCConsolidationService) Execute(ctx context.Context, addresses []model
 	if feeRate <= 0 {
 		estimate, err := s.feeEstimator.EstimateFee(ctx)
 		if err != nil {
-			s.updateTxState(txStateID, config.TxStateFailed, "", fmt.Sprintf("estimate fee: %s", err))
+			s.recordFailedConsolidation(sweepID, destAddr, addresses, fmt.Sprintf("estimate fee: %s", err))
 			return nil, fmt.Errorf("estimate fee: %w", err)
 		}
-		feeRate = DefaultFeeRate(estimate)
+		feeRate, _, err = FeeRateForTarget(estimate, feeTarget)
+		if err != nil {
+			s.recordFailedConsolidation(sweepID, destAddr, addresses, err.Error())
+			return nil, err
+		}
 	}
 
-	// 3. Build unsigned transaction.
-	built, err := BuildBTCConsolidationTx(BTCBuildParams{
-		UTXOs:       utxos,
-		DestAddress: destAddr,
-		FeeRate:     feeRate,
-		NetParams:   s.netParams,
-	})
+	// 3. Split into standard-size transactions.
+	chunks, err := s.chunkUTXOs(utxos, destAddr)
 	if err != nil {
-		s.updateTxState(txStateID, config.TxStateFailed, "", fmt.Sprintf("build TX: %s", err))
-		return nil, fmt.Errorf("build TX: %w", err)
+		s.recordFailedConsolidation(sweepID, destAddr, addresses, fmt.Sprintf("split TX: %s", err))
+		return nil, fmt.Errorf("split TX: %w", err)
 	}
-
-	// Update tx_state with actual amount.
-	s.updateTxState(txStateID, config.TxStatePending, "", "")
-	if err := s.database.UpdateTxStatus(txStateID, config.TxStatePending, "", ""); err != nil {
-		slog.Error("failed to update BTC tx_state amount", "error", err)
+	if len(chunks) > 1 {
+		slog.Info("BTC consolidation split into several transactions",
+			"inputCount", len(utxos),
+			"txCount", len(chunks),
+			"maxInputs", s.chunkMaxInputs,
+			"maxVsize", s.chunkMaxVsize,
+			"sweepID", sweepID,
+		)
 	}
 
-	// 4. Derive keys and prepare signing UTXOs.
-	signingUTXOs, err := s.prepareSigningUTXOs(ctx, built.UTXOs)
-	if err != nil {
-		// Zero any keys already derived on error.
-		for _, su := range signingUTXOs {
-			if su.PrivKey != nil {
-				su.PrivKey.Zero()
+	// 4–11. Build, sign, broadcast, record and poll each transaction in turn.
+	result := &models.SendResult{
+		Chain:  models.ChainBTC,
+		Chunks: make([]models.BTCChunkResult, 0, len(chunks)),
+	}
+	var firstErr error
+	for i, chunk := range chunks {
+		chunkResult, err := s.executeChunk(ctx, sweepID, destAddr, feeRate, chunk, i+1, len(chunks), start)
+		result.Chunks = append(result.Chunks, chunkResult)
+		if err != nil {
+			slog.Error("BTC consolidation transaction failed",
+				"chunk", i+1,
+				"txCount", len(chunks),
+				"inputCount", len(chunk),
+				"sweepID", sweepID,
+				"error", err,
+			)
+			if firstErr == nil {
+				firstErr = err
 			}
+			continue
+		}
+		if result.TxHash == "" {
+			result.TxHash = chunkResult.TxHash
 		}
-		s.updateTxState(txStateID, config.TxStateFailed, "", fmt.Sprintf("prepare signing: %s", err))
-		return nil, fmt.Errorf("prepare signing UTXOs: %w", err)
+		result.OutputSats += chunkResult.OutputSats
 	}
 
-	// 5. Sign the transaction.
-	if err := SignBTCTx(built.Tx, signingUTXOs); err != nil {
-		s.updateTxState(txStateID, config.TxStateFailed, "", fmt.Sprintf("sign TX: %s", err))
-		return nil, fmt.Errorf("sign TX: %w", err)
+	if result.TxHash == "" {
+		if len(chunks) == 1 {
+			return nil, firstErr
+		}
+		return nil, fmt.Errorf("all %d consolidation transactions failed: %w", len(chunks), firstErr)
 	}
+	return result, nil
+}
 
-	// 6. Serialize to hex.
+// broadcastSigned serializes a signed consolidation, broadcasts it, records it in
+// the transactions table and polls for its confirmation in the background, moving
+// its tx_state row along the way. current and total number the transaction within
+// its consolidation in the SSE progress event.
+func (s *BTCConsolidationService) broadcastSigned(ctx context.Context, txStateID string, built *BTCBuiltTx, destAddr string, start time.Time, current, total int) (*models.SendResult, error) {
+	// Serialize to hex.
 	rawHex, err := SerializeBTCTx(built.Tx)
 	if err != nil {
 		s.updateTxState(txStateID, config.TxStateFailed, "", fmt.Sprintf("serialize TX: %s", err))
@@ -465,10 +695,10 @@ func (s *BTCConsolidationService) Execute(ctx context.Context, addresses []model
 		"txStateID", txStateID,
 	)
 
-	// 7. Update to broadcasting.
+	// Update to broadcasting.
 	s.updateTxState(txStateID, config.TxStateBroadcasting, "", "")
 
-	// 8. Broadcast.
+	// Broadcast.
 	txHash, err := s.broadcaster.Broadcast(ctx, rawHex)
 	if err != nil {
 		s.updateTxState(txStateID, config.TxStateFailed, "", fmt.Sprintf("broadcast: %s", err))
@@ -480,7 +710,7 @@ func (s *BTCConsolidationService) Execute(ctx context.Context, addresses []model
 		"duration", time.Since(start).Round(time.Millisecond),
 	)
 
-	// Broadcast per-TX progress via SSE (BTC is a single multi-input TX).
+	// Broadcast per-TX progress via SSE (one event per multi-input consolidation TX).
 	if s.txHub != nil {
 		s.txHub.Broadcast(TxEvent{
 			Type: "tx_status",
@@ -492,16 +722,16 @@ func (s *BTCConsolidationService) Execute(ctx context.Context, addresses []model
 				TxHash:       txHash,
 				Status:       "success",
 				Amount:       strconv.FormatInt(built.OutputSats, 10),
-				Current:      1,
-				Total:        1,
+				Current:      current,
+				Total:        total,
 			},
 		})
 	}
 
-	// 9. Update to confirming with txHash.
+	// Update to confirming with txHash.
 	s.updateTxState(txStateID, config.TxStateConfirming, txHash, "")
 
-	// 10. Record in transactions table.
+	// Record in transactions table.
 	if err := s.recordTransaction(ctx, txHash, built, destAddr); err != nil {
 		slog.Error("failed to record BTC transaction in DB",
 			"txHash", txHash,
@@ -509,7 +739,7 @@ func (s *BTCConsolidationService) Execute(ctx context.Context, addresses []model
 		)
 	}
 
-	// 11. Poll for confirmation in background (best-effort).
+	// Poll for confirmation in background (best-effort).
 	// Uses a fresh context since the HTTP request context will be cancelled when the response is sent.
 	go func() {
 		bgCtx, cancel := context.WithTimeout(context.Background(), config.BTCConfirmationTimeout)
@@ -536,6 +766,136 @@ func (s *BTCConsolidationService) Execute(ctx context.Context, addresses []model
 	}, nil
 }
 
+// BroadcastPSBT finalizes a PSBT signed offline (see hdpay sign-psbt) and
+// broadcasts it, recording tx_state and transactions rows like Execute. Only a
+// consolidation is accepted: every input must spend a stored address of this
+// account and there must be a single output. Nothing is written when the PSBT is
+// rejected.
+func (s *BTCConsolidationService) BroadcastPSBT(ctx context.Context, packet *psbt.Packet, sweepID string) (*models.PSBTBroadcastResult, error) {
+	start := time.Now()
+
+	if err := FinalizePSBT(packet); err != nil {
+		return nil, fmt.Errorf("finalize PSBT: %w", err)
+	}
+	signedTx, err := ExtractPSBTTx(packet)
+	if err != nil {
+		return nil, fmt.Errorf("extract PSBT transaction: %w", err)
+	}
+
+	built, destAddr, err := s.psbtConsolidation(packet, signedTx)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("BTC PSBT finalized, broadcasting",
+		"inputCount", len(built.UTXOs),
+		"totalInputSats", built.TotalInputSats,
+		"outputSats", built.OutputSats,
+		"feeSats", built.FeeSats,
+		"destAddress", destAddr,
+		"sweepID", sweepID,
+	)
+
+	txStateID := GenerateTxStateID()
+	if err := s.database.CreateTxState(db.TxStateRow{
+		ID:           txStateID,
+		SweepID:      sweepID,
+		Chain:        string(models.ChainBTC),
+		Token:        string(models.TokenNative),
+		AddressIndex: 0, // BTC consolidation is multi-input, no single index
+		FromAddress:  "consolidated",
+		ToAddress:    destAddr,
+		Amount:       strconv.FormatInt(built.OutputSats, 10),
+		Status:       config.TxStatePending,
+	}); err != nil {
+		slog.Error("failed to create BTC tx_state", "error", err)
+		// Non-blocking: continue even if tx_state write fails
+	}
+
+	result, err := s.broadcastSigned(ctx, txStateID, built, destAddr, start, 1, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PSBTBroadcastResult{
+		SweepID:    sweepID,
+		TxHash:     result.TxHash,
+		Chain:      models.ChainBTC,
+		InputCount: len(built.UTXOs),
+		OutputSats: built.OutputSats,
+		FeeSats:    built.FeeSats,
+	}, nil
+}
+
+// psbtConsolidation describes a signed PSBT transaction as a BTCBuiltTx, resolving
+// the stored address and index of every input.
+func (s *BTCConsolidationService) psbtConsolidation(packet *psbt.Packet, signedTx *wire.MsgTx) (*BTCBuiltTx, string, error) {
+	if len(signedTx.TxOut) != 1 {
+		return nil, "", fmt.Errorf("%w: a consolidation has one output, PSBT has %d", config.ErrInvalidPSBT, len(signedTx.TxOut))
+	}
+	destAddr, err := scriptAddress(signedTx.TxOut[0].PkScript, s.netParams)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: output: %s", config.ErrInvalidPSBT, err)
+	}
+
+	prevOuts, err := psbtPrevOutFetcher(packet)
+	if err != nil {
+		return nil, "", err
+	}
+
+	built := &BTCBuiltTx{
+		Tx:         signedTx,
+		UTXOs:      make([]models.UTXO, len(signedTx.TxIn)),
+		OutputSats: signedTx.TxOut[0].Value,
+	}
+	for i, txIn := range signedTx.TxIn {
+		prevOut := prevOuts.FetchPrevOutput(txIn.PreviousOutPoint)
+		address, err := scriptAddress(prevOut.PkScript, s.netParams)
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: input %d: %s", config.ErrPSBTForeignInput, i, err)
+		}
+		stored, err := s.database.LookupAddress(models.ChainBTC, address)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, "", fmt.Errorf("%w: input %d spends %s", config.ErrPSBTForeignInput, i, address)
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("look up input %d address: %w", i, err)
+		}
+
+		addrType, err := hd.BTCAddressTypeOf(address, s.netParams)
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: input %d: %s", config.ErrPSBTForeignInput, i, err)
+		}
+
+		built.UTXOs[i] = models.UTXO{
+			TxID:         txIn.PreviousOutPoint.Hash.String(),
+			Vout:         txIn.PreviousOutPoint.Index,
+			Value:        prevOut.Value,
+			Confirmed:    true,
+			Address:      address,
+			AddressIndex: stored.AddressIndex,
+			AddressType:  addrType,
+		}
+		built.TotalInputSats += prevOut.Value
+	}
+
+	built.FeeSats = built.TotalInputSats - built.OutputSats
+	if built.FeeSats <= 0 {
+		return nil, "", fmt.Errorf("%w: output %d sats is not below inputs %d sats", config.ErrInvalidPSBT, built.OutputSats, built.TotalInputSats)
+	}
+	built.EstimatedVsize = (signedTx.SerializeSizeStripped()*3 + signedTx.SerializeSize() + 3) / 4
+	return built, destAddr, nil
+}
+
+// scriptAddress returns the address a standard single-key output script pays to.
+func scriptAddress(pkScript []byte, netParams *chaincfg.Params) (string, error) {
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(pkScript, netParams)
+	if err != nil || len(addrs) != 1 {
+		return "", fmt.Errorf("script %x has no single address", pkScript)
+	}
+	return addrs[0].EncodeAddress(), nil
+}
+
 // updateTxState is a non-blocking helper that logs errors but doesn't propagate them.
 // For terminal states (confirmed, failed), also updates the transactions table.
 func (s *BTCConsolidationService) updateTxState(id, status, txHash, txError string) {
@@ -560,6 +920,7 @@ func (s *BTCConsolidationService) updateTxState(id, status, txHash, txError stri
 }
 
 // prepareSigningUTXOs derives private keys and reconstructs pkScripts for each UTXO.
+// Change UTXOs are signed with the internal-chain key at their change index.
 func (s *BTCConsolidationService) prepareSigningUTXOs(ctx context.Context, utxos []models.UTXO) ([]SigningUTXO, error) {
 	signingUTXOs := make([]SigningUTXO, 0, len(utxos))
 
@@ -573,9 +934,27 @@ func (s *BTCConsolidationService) prepareSigningUTXOs(ctx context.Context, utxos
 			return signingUTXOs, fmt.Errorf("pkScript for address %s (index %d): %w", u.Address, u.AddressIndex, err)
 		}
 
-		privKey, err := s.keyService.DeriveBTCPrivateKey(ctx, uint32(u.AddressIndex))
+		addrType := u.AddressType
+		if addrType == "" {
+			addrType = models.BTCAddressP2WPKH
+		}
+
+		derive, branch := s.keyService.DeriveBTCTypedPrivateKey, 0
+		if u.Change {
+			derive, branch = s.keyService.DeriveBTCChangePrivateKey, 1
+		}
+		privKey, err := derive(ctx, addrType, uint32(u.AddressIndex))
 		if err != nil {
-			return signingUTXOs, fmt.Errorf("derive key for index %d: %w", u.AddressIndex, err)
+			return signingUTXOs, fmt.Errorf("derive key for index %d/%d: %w", branch, u.AddressIndex, err)
+		}
+
+		// Refuse to sign if the derived key does not control the UTXO's address
+		// (e.g. the address set was generated for another type or account).
+		derived, err := hd.BTCAddressFromPubKey(privKey.PubKey(), addrType, s.netParams)
+		if err != nil || derived.EncodeAddress() != u.Address {
+			privKey.Zero()
+			return signingUTXOs, fmt.Errorf("%w: %s key at index %d/%d does not match address %s",
+				config.ErrKeyDerivation, addrType, branch, u.AddressIndex, u.Address)
 		}
 
 		signingUTXOs = append(signingUTXOs, SigningUTXO{
@@ -590,8 +969,12 @@ func (s *BTCConsolidationService) prepareSigningUTXOs(ctx context.Context, utxos
 
 // recordTransaction stores the consolidation TX in the transactions table.
 func (s *BTCConsolidationService) recordTransaction(_ context.Context, txHash string, built *BTCBuiltTx, destAddr string) error {
-	// Record one transaction entry per input address for history tracking.
+	// Record one transaction entry per input address for history tracking. Change
+	// inputs (a CPFP child of a payout) belong to no stored address index.
 	for _, u := range built.UTXOs {
+		if u.Change {
+			continue
+		}
 		txRecord := models.Transaction{
 			Chain:        models.ChainBTC,
 			AddressIndex: u.AddressIndex,
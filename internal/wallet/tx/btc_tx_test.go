@@ -9,7 +9,9 @@ import (
 
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
 
 	"github.com/Fantasim/hdpay/internal/shared/config"
 	"github.com/Fantasim/hdpay/internal/shared/models"
@@ -426,3 +428,134 @@ func TestBuildBTCConsolidationTx_MaxInputsExceeded(t *testing.T) {
 		t.Fatal("expected error for too many inputs")
 	}
 }
+
+func TestEstimateBTCTxWeight_MixedTypes(t *testing.T) {
+	p2wpkhOut := make([]byte, 22)
+	p2trOut := make([]byte, 34)
+
+	tests := []struct {
+		name    string
+		types   []models.BTCAddressType
+		outputs [][]byte
+		want    int
+	}{
+		// Must agree with EstimateBTCVsize for the P2WPKH-only case: 42 + 272 + 124.
+		{"p2wpkh (untyped)", []models.BTCAddressType{""}, [][]byte{p2wpkhOut}, 438},
+		// 42 + 230 + (36 + 34*4)
+		{"p2tr to p2tr", []models.BTCAddressType{models.BTCAddressP2TR}, [][]byte{p2trOut}, 444},
+		// 42 + 364 + 124
+		{"nested segwit", []models.BTCAddressType{models.BTCAddressP2SHP2WPKH}, [][]byte{p2wpkhOut}, 530},
+		// 42 + 593 + 124
+		{"legacy", []models.BTCAddressType{models.BTCAddressP2PKH}, [][]byte{p2wpkhOut}, 759},
+		// 42 + 272 + 230 + 364 + 593 + 124
+		{"all four", models.AllBTCAddressTypes, [][]byte{p2wpkhOut}, 1625},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			utxos := make([]models.UTXO, len(tt.types))
+			for i, typ := range tt.types {
+				utxos[i] = models.UTXO{AddressType: typ}
+			}
+			got, err := EstimateBTCTxWeight(utxos, tt.outputs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("EstimateBTCTxWeight() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := EstimateBTCTxWeight([]models.UTXO{{AddressType: "p2wsh"}}, nil); !errors.Is(err, hd.ErrUnsupportedAddressType) {
+		t.Errorf("EstimateBTCTxWeight(p2wsh) error = %v, want ErrUnsupportedAddressType", err)
+	}
+}
+
+func TestSignBTCTx_MixedAddressTypes(t *testing.T) {
+	// One input of every supported address type in a single consolidation; each
+	// signed input is then executed by the script engine against its prevout.
+	net := &chaincfg.MainNetParams
+	seed, _ := hd.MnemonicToSeed(testMnemonic24)
+	masterKey, _ := hd.DeriveMasterKey(seed, net)
+	ks := NewKeyService(writeTempMnemonic(t, testMnemonic24), "mainnet")
+
+	var utxos []models.UTXO
+	for i, addrType := range models.AllBTCAddressTypes {
+		parent, err := hd.DeriveBTCAccountParentKey(masterKey, addrType, 0, net)
+		if err != nil {
+			t.Fatal(err)
+		}
+		addr, err := hd.DeriveBTCTypedAddressFromParent(parent, addrType, uint32(i), net)
+		if err != nil {
+			t.Fatal(err)
+		}
+		utxos = append(utxos, models.UTXO{
+			TxID:         chainhash.HashH([]byte(addrType)).String(),
+			Vout:         uint32(i),
+			Value:        40000,
+			Address:      addr,
+			AddressIndex: i,
+		})
+	}
+
+	if err := ClassifyUTXOs(utxos, net); err != nil {
+		t.Fatalf("ClassifyUTXOs() error = %v", err)
+	}
+	for i, u := range utxos {
+		if u.AddressType != models.AllBTCAddressTypes[i] {
+			t.Errorf("utxo %d classified as %q, want %q", i, u.AddressType, models.AllBTCAddressTypes[i])
+		}
+	}
+
+	built, err := BuildBTCConsolidationTx(BTCBuildParams{
+		UTXOs:       utxos,
+		DestAddress: utxos[1].Address, // Taproot destination.
+		FeeRate:     5,
+		NetParams:   net,
+	})
+	if err != nil {
+		t.Fatalf("BuildBTCConsolidationTx() error = %v", err)
+	}
+
+	signingUTXOs := make([]SigningUTXO, len(utxos))
+	prevOuts := txscript.NewMultiPrevOutFetcher(nil)
+	for i, u := range utxos {
+		pkScript, err := PKScriptFromAddress(u.Address, net)
+		if err != nil {
+			t.Fatal(err)
+		}
+		privKey, err := ks.DeriveBTCTypedPrivateKey(context.Background(), u.AddressType, uint32(u.AddressIndex))
+		if err != nil {
+			t.Fatal(err)
+		}
+		signingUTXOs[i] = SigningUTXO{UTXO: u, PKScript: pkScript, PrivKey: privKey}
+		prevOuts.AddPrevOut(built.Tx.TxIn[i].PreviousOutPoint, wire.NewTxOut(u.Value, pkScript))
+	}
+
+	if err := SignBTCTx(built.Tx, signingUTXOs); err != nil {
+		t.Fatalf("SignBTCTx() error = %v", err)
+	}
+
+	sigHashes := txscript.NewTxSigHashes(built.Tx, prevOuts)
+	for i, su := range signingUTXOs {
+		vm, err := txscript.NewEngine(su.PKScript, built.Tx, i, txscript.StandardVerifyFlags,
+			nil, sigHashes, su.Value, prevOuts)
+		if err != nil {
+			t.Fatalf("input %d (%s): NewEngine() error = %v", i, su.AddressType, err)
+		}
+		if err := vm.Execute(); err != nil {
+			t.Errorf("input %d (%s): script execution failed: %v", i, su.AddressType, err)
+		}
+	}
+
+	// The estimate must cover the real signed size (signatures may be shorter).
+	if vsize := (blockchainWeight(built.Tx) + 3) / 4; vsize > built.EstimatedVsize {
+		t.Errorf("signed vsize %d exceeds estimate %d", vsize, built.EstimatedVsize)
+	}
+}
+
+// blockchainWeight returns the BIP-141 weight of a transaction.
+func blockchainWeight(msgTx *wire.MsgTx) int {
+	return msgTx.SerializeSizeStripped()*3 + msgTx.SerializeSize()
+}
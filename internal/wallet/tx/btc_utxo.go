@@ -1,13 +1,19 @@
 package tx
 
 import (
+	"bytes"
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"strings"
 	"sync/atomic"
 
+	"github.com/btcsuite/btcd/wire"
+
 	"github.com/Fantasim/hdpay/internal/shared/config"
 	"github.com/Fantasim/hdpay/internal/shared/models"
 	"github.com/Fantasim/hdpay/internal/shared/scanner"
@@ -48,6 +54,19 @@ func NewBTCUTXOFetcher(client *http.Client, providerURLs []string, rateLimiters
 
 // FetchUTXOs fetches confirmed UTXOs for a single address.
 func (f *BTCUTXOFetcher) FetchUTXOs(ctx context.Context, address string, addressIndex int) ([]models.UTXO, error) {
+	return f.fetchUTXOs(ctx, address, addressIndex, true)
+}
+
+// FetchUnconfirmedUTXOs fetches the UTXOs of a single address that are still in
+// the mempool. Consolidations never spend them; a CPFP child selects them
+// explicitly (see BumpFeeCPFP).
+func (f *BTCUTXOFetcher) FetchUnconfirmedUTXOs(ctx context.Context, address string, addressIndex int) ([]models.UTXO, error) {
+	return f.fetchUTXOs(ctx, address, addressIndex, false)
+}
+
+// fetchUTXOs fetches the UTXOs of a single address whose confirmation status
+// matches confirmed.
+func (f *BTCUTXOFetcher) fetchUTXOs(ctx context.Context, address string, addressIndex int, confirmed bool) ([]models.UTXO, error) {
 	idx := int(f.nextProvider.Add(1)-1) % len(f.providerURLs)
 	baseURL := f.providerURLs[idx]
 	rl := f.rateLimiters[idx]
@@ -98,15 +117,16 @@ func (f *BTCUTXOFetcher) FetchUTXOs(ctx context.Context, address string, address
 		return nil, fmt.Errorf("decode UTXO response: %w", err)
 	}
 
-	// Filter to confirmed UTXOs only.
+	// Keep only UTXOs with the requested confirmation status.
 	var utxos []models.UTXO
 	for _, u := range raw {
-		if !u.Status.Confirmed {
-			slog.Debug("skipping unconfirmed UTXO",
+		if u.Status.Confirmed != confirmed {
+			slog.Debug("skipping UTXO by confirmation status",
 				"txid", u.TxID,
 				"vout", u.Vout,
 				"value", u.Value,
 				"address", address,
+				"confirmed", u.Status.Confirmed,
 			)
 			continue
 		}
@@ -114,7 +134,7 @@ func (f *BTCUTXOFetcher) FetchUTXOs(ctx context.Context, address string, address
 			TxID:         u.TxID,
 			Vout:         u.Vout,
 			Value:        u.Value,
-			Confirmed:    true,
+			Confirmed:    u.Status.Confirmed,
 			BlockHeight:  u.Status.BlockHeight,
 			Address:      address,
 			AddressIndex: addressIndex,
@@ -125,13 +145,89 @@ func (f *BTCUTXOFetcher) FetchUTXOs(ctx context.Context, address string, address
 		"address", address,
 		"index", addressIndex,
 		"total", len(raw),
-		"confirmed", len(utxos),
+		"selected", len(utxos),
+		"confirmed", confirmed,
 		"provider", rl.Name(),
 	)
 
 	return utxos, nil
 }
 
+// FetchRawTx fetches a full transaction by txid. PSBTs carry it for the inputs
+// whose signature does not commit to the amount spent (see NewBTCConsolidationPSBT).
+// The transaction is checked to hash to txid.
+func (f *BTCUTXOFetcher) FetchRawTx(ctx context.Context, txid string) (*wire.MsgTx, error) {
+	idx := int(f.nextProvider.Add(1)-1) % len(f.providerURLs)
+	baseURL := f.providerURLs[idx]
+	rl := f.rateLimiters[idx]
+
+	if err := rl.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limiter wait for raw TX fetch: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/tx/%s/hex", baseURL, txid)
+
+	slog.Debug("fetching raw TX",
+		"txid", txid,
+		"provider", rl.Name(),
+		"url", url,
+	)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create raw TX request: %w", err)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", config.ErrUTXOFetchFailed, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		slog.Warn("raw TX fetch rate limited",
+			"txid", txid,
+			"provider", rl.Name(),
+		)
+		return nil, config.ErrProviderRateLimit
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		slog.Warn("raw TX fetch non-200 response",
+			"txid", txid,
+			"provider", rl.Name(),
+			"status", resp.StatusCode,
+		)
+		return nil, fmt.Errorf("%w: HTTP %d from %s", config.ErrUTXOFetchFailed, resp.StatusCode, rl.Name())
+	}
+
+	// A transaction is at most the block weight limit; its hex is twice that.
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 2*config.MaxPSBTBytes))
+	if err != nil {
+		return nil, fmt.Errorf("read raw TX response: %w", err)
+	}
+	raw, err := hex.DecodeString(strings.TrimSpace(string(body)))
+	if err != nil {
+		return nil, fmt.Errorf("decode raw TX hex: %w", err)
+	}
+
+	msgTx := wire.NewMsgTx(wire.TxVersion)
+	if err := msgTx.Deserialize(bytes.NewReader(raw)); err != nil {
+		return nil, fmt.Errorf("deserialize raw TX: %w", err)
+	}
+	if got := msgTx.TxHash().String(); got != txid {
+		return nil, fmt.Errorf("%w: %s returned transaction %s for %s", config.ErrUTXOFetchFailed, rl.Name(), got, txid)
+	}
+
+	slog.Debug("raw TX fetched",
+		"txid", txid,
+		"size", len(raw),
+		"provider", rl.Name(),
+	)
+
+	return msgTx, nil
+}
+
 // FetchAllUTXOs fetches confirmed UTXOs for multiple addresses with round-robin provider rotation.
 // Returns all UTXOs concatenated. Addresses with no UTXOs are silently skipped.
 func (f *BTCUTXOFetcher) FetchAllUTXOs(ctx context.Context, addresses []models.Address) ([]models.UTXO, error) {
@@ -1,12 +1,20 @@
 package tx
 
 import (
+	"bytes"
 	"context"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
 	"github.com/Fantasim/hdpay/internal/shared/models"
 	"github.com/Fantasim/hdpay/internal/shared/scanner"
 )
@@ -106,6 +114,14 @@ func TestBTCUTXOFetcher_FiltersUnconfirmed(t *testing.T) {
 	if utxos[0].Value != 50000 {
 		t.Errorf("expected confirmed UTXO value 50000, got %d", utxos[0].Value)
 	}
+
+	unconfirmed, err := fetcher.FetchUnconfirmedUTXOs(context.Background(), "bc1qtest", 3)
+	if err != nil {
+		t.Fatalf("FetchUnconfirmedUTXOs() error = %v", err)
+	}
+	if len(unconfirmed) != 1 || unconfirmed[0].Value != 10000 || unconfirmed[0].Confirmed || unconfirmed[0].AddressIndex != 3 {
+		t.Errorf("FetchUnconfirmedUTXOs() = %+v, want the 10000 sat mempool UTXO", unconfirmed)
+	}
 }
 
 func TestBTCUTXOFetcher_EmptyResponse(t *testing.T) {
@@ -283,3 +299,41 @@ func TestBTCUTXOFetcher_RoundRobin(t *testing.T) {
 		t.Errorf("expected 2 calls each, got provider1=%d provider2=%d", provider1Calls, provider2Calls)
 	}
 }
+
+func TestBTCUTXOFetcher_FetchRawTx(t *testing.T) {
+	prevTx := wire.NewMsgTx(2)
+	prevTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 0), nil, nil))
+	prevTx.AddTxOut(wire.NewTxOut(50000, []byte{0x00, 0x14}))
+	var buf bytes.Buffer
+	if err := prevTx.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	txid := prevTx.TxHash().String()
+
+	// Every txid is answered with prevTx.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/tx/") || !strings.HasSuffix(r.URL.Path, "/hex") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(hex.EncodeToString(buf.Bytes()) + "\n"))
+	}))
+	defer server.Close()
+
+	rl := scanner.NewRateLimiter("test", 100, 0)
+	fetcher := NewBTCUTXOFetcher(server.Client(), []string{server.URL}, []*scanner.RateLimiter{rl})
+
+	got, err := fetcher.FetchRawTx(context.Background(), txid)
+	if err != nil {
+		t.Fatalf("FetchRawTx() error = %v", err)
+	}
+	if got.TxHash().String() != txid || got.TxOut[0].Value != 50000 {
+		t.Errorf("FetchRawTx() = %s paying %d, want %s paying 50000", got.TxHash(), got.TxOut[0].Value, txid)
+	}
+
+	// A provider answering with another transaction is not trusted.
+	other := chainhash.HashH([]byte("other")).String()
+	if _, err := fetcher.FetchRawTx(context.Background(), other); !errors.Is(err, config.ErrUTXOFetchFailed) {
+		t.Errorf("FetchRawTx(mismatched txid) error = %v, want ErrUTXOFetchFailed", err)
+	}
+}
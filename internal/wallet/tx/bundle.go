@@ -0,0 +1,157 @@
+package tx
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/wallet/db"
+	"github.com/Fantasim/hdpay/internal/wallet/hd"
+)
+
+// SweepBundleSummary totals a sweep bundle for display before signing.
+type SweepBundleSummary struct {
+	TxCount     int
+	TotalAmount string // wei, lamports or token base units
+	TotalFee    string // wei or lamports
+}
+
+// newSweepBundle returns an empty bundle header for an export.
+func newSweepBundle(chain models.Chain, token models.Token, network string, account uint32, dest, contract string) *models.SweepBundle {
+	return &models.SweepBundle{
+		Version:     config.SweepBundleVersion,
+		Chain:       chain,
+		Token:       token,
+		Network:     network,
+		Account:     account,
+		Destination: dest,
+		Contract:    contract,
+		CreatedAt:   time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// DecodeSweepBundle parses a JSON sweep bundle and checks its header.
+func DecodeSweepBundle(raw []byte) (*models.SweepBundle, error) {
+	var bundle models.SweepBundle
+	if err := json.Unmarshal(raw, &bundle); err != nil {
+		return nil, fmt.Errorf("%w: %s", config.ErrInvalidBundle, err)
+	}
+	if err := CheckSweepBundle(&bundle); err != nil {
+		return nil, err
+	}
+	return &bundle, nil
+}
+
+// CheckSweepBundle validates the fields every bundle must carry, whatever its
+// chain. The transactions themselves are checked when signing and broadcasting.
+func CheckSweepBundle(bundle *models.SweepBundle) error {
+	if bundle == nil {
+		return fmt.Errorf("%w: missing bundle", config.ErrInvalidBundle)
+	}
+	if bundle.Version != config.SweepBundleVersion {
+		return fmt.Errorf("%w: unsupported version %d", config.ErrInvalidBundle, bundle.Version)
+	}
+	if bundle.Chain != models.ChainBSC && bundle.Chain != models.ChainSOL {
+		return fmt.Errorf("%w: unsupported chain %q", config.ErrInvalidBundle, bundle.Chain)
+	}
+	if bundle.Network != string(models.NetworkMainnet) && bundle.Network != string(models.NetworkTestnet) {
+		return fmt.Errorf("%w: unknown network %q", config.ErrInvalidBundle, bundle.Network)
+	}
+	// Token bundles must name their contract; the handler checks it against the
+	// token registry.
+	switch bundle.Token {
+	case models.TokenNative:
+	case "":
+		return fmt.Errorf("%w: missing token", config.ErrInvalidBundle)
+	default:
+		if bundle.Contract == "" {
+			return fmt.Errorf("%w: %s bundle has no token contract", config.ErrInvalidBundle, bundle.Token)
+		}
+	}
+	if bundle.Destination == "" {
+		return fmt.Errorf("%w: missing destination", config.ErrInvalidBundle)
+	}
+	if len(bundle.Transactions) == 0 {
+		return fmt.Errorf("%w: no transactions", config.ErrInvalidBundle)
+	}
+	return nil
+}
+
+// SummarizeSweepBundle checks that every transaction of the bundle is a plain
+// transfer to its destination and totals the amounts and fees.
+func SummarizeSweepBundle(bundle *models.SweepBundle) (*SweepBundleSummary, error) {
+	totalAmount := new(big.Int)
+	totalFee := new(big.Int)
+
+	for i, entry := range bundle.Transactions {
+		switch bundle.Chain {
+		case models.ChainBSC:
+			unsigned, err := bscBundleTx(bundle, entry)
+			if err != nil {
+				return nil, fmt.Errorf("transaction %d: %w", i, err)
+			}
+			totalFee.Add(totalFee, new(big.Int).Mul(unsigned.GasPrice(), new(big.Int).SetUint64(unsigned.Gas())))
+		case models.ChainSOL:
+			msg, _, err := solBundleMessage(bundle, entry)
+			if err != nil {
+				return nil, fmt.Errorf("transaction %d: %w", i, err)
+			}
+			totalFee.Add(totalFee, big.NewInt(int64(msg.Header.NumRequiredSignatures)*config.SOLBaseTransactionFee))
+		default:
+			return nil, fmt.Errorf("%w: unsupported chain %q", config.ErrInvalidBundle, bundle.Chain)
+		}
+
+		amount, _ := new(big.Int).SetString(entry.Amount, 10)
+		totalAmount.Add(totalAmount, amount)
+	}
+
+	return &SweepBundleSummary{
+		TxCount:     len(bundle.Transactions),
+		TotalAmount: totalAmount.String(),
+		TotalFee:    totalFee.String(),
+	}, nil
+}
+
+// SignSweepBundle signs every transaction of an exported bundle with keys derived
+// from seed on the bundle's account, filling in SignedTx. It needs no network
+// access. A transaction whose sender does not derive from the seed is an error.
+func SignSweepBundle(bundle *models.SweepBundle, seed []byte) (int, error) {
+	if err := CheckSweepBundle(bundle); err != nil {
+		return 0, err
+	}
+
+	switch bundle.Chain {
+	case models.ChainBSC:
+		masterKey, err := hd.DeriveMasterKey(seed, hd.NetworkParams(bundle.Network))
+		if err != nil {
+			return 0, fmt.Errorf("derive master key: %w", err)
+		}
+		defer masterKey.Zero()
+		return signBSCBundle(bundle, masterKey)
+	case models.ChainSOL:
+		return signSOLBundle(bundle, seed)
+	default:
+		return 0, fmt.Errorf("%w: unsupported chain %q", config.ErrInvalidBundle, bundle.Chain)
+	}
+}
+
+// checkBundleAddress verifies that a bundle transaction spends from the stored
+// address at its index.
+func checkBundleAddress(database *db.DB, chain models.Chain, entry models.SweepBundleTx) error {
+	if database == nil {
+		return nil
+	}
+	stored, err := database.GetAddressByIndex(chain, entry.AddressIndex)
+	if err != nil {
+		return fmt.Errorf("%w: %s index %d: %s", config.ErrBundleForeignAddress, chain, entry.AddressIndex, err)
+	}
+	if !strings.EqualFold(stored.Address, entry.FromAddress) {
+		return fmt.Errorf("%w: %s index %d is %s, bundle spends from %s",
+			config.ErrBundleForeignAddress, chain, entry.AddressIndex, stored.Address, entry.FromAddress)
+	}
+	return nil
+}
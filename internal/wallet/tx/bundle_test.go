@@ -0,0 +1,326 @@
+package tx
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strconv"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/wallet/hd"
+)
+
+const testBundleBSCDest = "0x5555555555555555555555555555555555555555"
+
+// roundTripBundle marshals and decodes a bundle, as a file carried to and from
+// the signing machine would be.
+func roundTripBundle(t *testing.T, bundle *models.SweepBundle) *models.SweepBundle {
+	t.Helper()
+	raw, err := json.Marshal(bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeSweepBundle(raw)
+	if err != nil {
+		t.Fatalf("DecodeSweepBundle() error = %v", err)
+	}
+	return decoded
+}
+
+func newBSCBundleTestService(t *testing.T) (*BSCConsolidationService, *mockEthClient, models.Address) {
+	t.Helper()
+	seed := testSeed(t, testMnemonic24)
+	masterKey, err := hd.DeriveMasterKey(seed, hd.NetworkParams("testnet"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	address, err := hd.DeriveBSCAddress(masterKey, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock := &mockEthClient{
+		pendingNonce: 7,
+		gasPrice:     big.NewInt(3_000_000_000),
+		balance:      big.NewInt(1_000_000_000_000_000_000),
+		receipt: &types.Receipt{
+			Status:      types.ReceiptStatusSuccessful,
+			BlockNumber: big.NewInt(100),
+		},
+	}
+	ks := NewKeyService(writeTempMnemonic(t, testMnemonic24), "testnet")
+	svc := NewBSCConsolidationService(ks, mock, setupGasTestDB(t), big.NewInt(config.BSCTestnetChainID), nil)
+	return svc, mock, models.Address{Chain: models.ChainBSC, AddressIndex: 0, Address: address}
+}
+
+func TestBSCBundle_ExportSignBroadcast(t *testing.T) {
+	svc, mock, stored := newBSCBundleTestService(t)
+	addresses := []models.AddressWithBalance{{
+		Chain:         models.ChainBSC,
+		AddressIndex:  stored.AddressIndex,
+		Address:       stored.Address,
+		NativeBalance: "1000000000000000000",
+	}}
+
+	bundle, err := svc.ExportNativeBundle(context.Background(), addresses, testBundleBSCDest)
+	if err != nil {
+		t.Fatalf("ExportNativeBundle() error = %v", err)
+	}
+	if len(bundle.Transactions) != 1 || bundle.Transactions[0].Nonce != 7 || bundle.Transactions[0].SignedTx != "" {
+		t.Fatalf("exported transactions = %+v, want one unsigned tx at nonce 7", bundle.Transactions)
+	}
+	if len(mock.sentTxs) != 0 {
+		t.Fatal("export must not broadcast")
+	}
+
+	bundle = roundTripBundle(t, bundle)
+	if err := svc.ValidateBundle(bundle); !errors.Is(err, config.ErrBundleUnsigned) {
+		t.Fatalf("ValidateBundle() of unsigned bundle error = %v, want ErrBundleUnsigned", err)
+	}
+
+	signed, err := SignSweepBundle(bundle, testSeed(t, testMnemonic24))
+	if err != nil || signed != 1 {
+		t.Fatalf("SignSweepBundle() = %d, %v; want 1, nil", signed, err)
+	}
+	bundle = roundTripBundle(t, bundle)
+
+	// Senders must be stored addresses: nothing is stored yet.
+	if err := svc.ValidateBundle(bundle); !errors.Is(err, config.ErrBundleForeignAddress) {
+		t.Fatalf("ValidateBundle() before storing addresses error = %v, want ErrBundleForeignAddress", err)
+	}
+	if err := svc.database.InsertAddressBatch(models.ChainBSC, []models.Address{stored}); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.ValidateBundle(bundle); err != nil {
+		t.Fatalf("ValidateBundle() error = %v", err)
+	}
+
+	result, err := svc.BroadcastBundle(context.Background(), bundle, "sweep-bundle")
+	if err != nil {
+		t.Fatalf("BroadcastBundle() error = %v", err)
+	}
+	if result.SuccessCount != 1 || result.TotalSwept != bundle.Transactions[0].Amount {
+		t.Errorf("BroadcastBundle() = %+v, want 1 success sweeping %s", result, bundle.Transactions[0].Amount)
+	}
+	if len(mock.sentTxs) != 1 || mock.sentTxs[0].Nonce() != 7 {
+		t.Fatalf("broadcast %d transactions, want 1 at nonce 7", len(mock.sentTxs))
+	}
+
+	rows, err := svc.database.GetTxStatesBySweepID("sweep-bundle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 {
+		t.Errorf("tx_state rows = %d, want 1", len(rows))
+	}
+}
+
+func TestBSCBundle_TamperedRejected(t *testing.T) {
+	svc, _, stored := newBSCBundleTestService(t)
+	if err := svc.database.InsertAddressBatch(models.ChainBSC, []models.Address{stored}); err != nil {
+		t.Fatal(err)
+	}
+	addresses := []models.AddressWithBalance{{Chain: models.ChainBSC, AddressIndex: 0, Address: stored.Address}}
+
+	export := func() *models.SweepBundle {
+		bundle, err := svc.ExportNativeBundle(context.Background(), addresses, testBundleBSCDest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return bundle
+	}
+
+	// Editing the destination after export invalidates the exported transaction.
+	bundle := export()
+	bundle.Destination = "0x6666666666666666666666666666666666666666"
+	if _, err := SignSweepBundle(bundle, testSeed(t, testMnemonic24)); !errors.Is(err, config.ErrInvalidBundle) {
+		t.Errorf("SignSweepBundle() of redirected bundle error = %v, want ErrInvalidBundle", err)
+	}
+
+	// A signed transaction for a different amount is not the exported one.
+	bundle = export()
+	honest := export()
+	bundle.Transactions[0].Amount = "1"
+	bundle.Transactions[0].Value = "1"
+	if _, err := SignSweepBundle(bundle, testSeed(t, testMnemonic24)); err != nil {
+		t.Fatal(err)
+	}
+	honest.Transactions[0].SignedTx = bundle.Transactions[0].SignedTx
+	if err := svc.ValidateBundle(honest); !errors.Is(err, config.ErrInvalidBundle) {
+		t.Errorf("ValidateBundle() with a swapped signed tx error = %v, want ErrInvalidBundle", err)
+	}
+
+	// The seed must own every sender.
+	bundle = export()
+	if _, err := SignSweepBundle(bundle, testSeed(t, testMnemonic12)); !errors.Is(err, config.ErrKeyMismatch) {
+		t.Errorf("SignSweepBundle() with another seed error = %v, want ErrKeyMismatch", err)
+	}
+}
+
+func TestBSCBundle_ExportNothingToSweep(t *testing.T) {
+	svc, mock, stored := newBSCBundleTestService(t)
+	mock.balance = big.NewInt(1000)
+
+	addresses := []models.AddressWithBalance{{Chain: models.ChainBSC, AddressIndex: 0, Address: stored.Address}}
+	if _, err := svc.ExportNativeBundle(context.Background(), addresses, testBundleBSCDest); !errors.Is(err, config.ErrBundleEmpty) {
+		t.Errorf("ExportNativeBundle() error = %v, want ErrBundleEmpty", err)
+	}
+}
+
+// --- SOL ---
+
+const (
+	testBundleSOLDest         = "5frqxtii9LeGq2bz3dSNokvZcEooF483MzeU24JrhcTA"
+	testBundleSOLNonceAccount = "9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM"
+)
+
+func newSOLBundleTestService(t *testing.T) (*SOLConsolidationService, *mockSOLRPCClient, []models.Address) {
+	t.Helper()
+	seed := testSeed(t, testMnemonic24)
+	stored := make([]models.Address, 2)
+	for i := range stored {
+		address, err := hd.DeriveSOLAddress(seed, uint32(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		stored[i] = models.Address{Chain: models.ChainSOL, AddressIndex: i, Address: address}
+	}
+
+	// The nonce authority is the wallet's address at index 1.
+	authority, err := SolPublicKeyFromBase58(stored[1].Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock := &mockSOLRPCClient{
+		getNonceAccountFn: func(ctx context.Context, addr string) ([32]byte, SolPublicKey, error) {
+			return [32]byte{0x4e}, authority, nil
+		},
+	}
+
+	database := setupGasTestDB(t)
+	if err := database.InsertAddressBatch(models.ChainSOL, stored); err != nil {
+		t.Fatal(err)
+	}
+	ks := NewKeyService(writeTempMnemonic(t, testMnemonic24), "testnet")
+	svc := NewSOLConsolidationService(ks, mock, database, "testnet", nil)
+	svc.SetNonceAccounts([]string{testBundleSOLNonceAccount})
+	return svc, mock, stored
+}
+
+func TestSOLBundle_ExportSignBroadcast(t *testing.T) {
+	svc, mock, stored := newSOLBundleTestService(t)
+	var sent []string
+	mock.sendTransactionFn = func(ctx context.Context, txBase64 string) (string, error) {
+		sent = append(sent, txBase64)
+		return "", nil
+	}
+
+	addresses := []models.AddressWithBalance{{
+		Chain:         models.ChainSOL,
+		AddressIndex:  stored[0].AddressIndex,
+		Address:       stored[0].Address,
+		NativeBalance: "1000000000",
+	}}
+	bundle, err := svc.ExportNativeBundle(context.Background(), addresses, testBundleSOLDest)
+	if err != nil {
+		t.Fatalf("ExportNativeBundle() error = %v", err)
+	}
+	if len(bundle.Transactions) != 1 {
+		t.Fatalf("exported %d transactions, want 1", len(bundle.Transactions))
+	}
+	entry := bundle.Transactions[0]
+
+	// Sender and nonce authority both sign, so the fee is two signatures.
+	wantAmount := strconv.FormatUint(1_000_000_000-2*config.SOLBaseTransactionFee, 10)
+	if entry.Amount != wantAmount || entry.NonceAccount != testBundleSOLNonceAccount {
+		t.Errorf("entry = %+v, want amount %s on %s", entry, wantAmount, testBundleSOLNonceAccount)
+	}
+	if len(entry.SignerIndexes) != 2 || entry.SignerIndexes[0] != 0 || entry.SignerIndexes[1] != 1 {
+		t.Errorf("signer indexes = %v, want [0 1]", entry.SignerIndexes)
+	}
+	msgBytes, _ := base64.StdEncoding.DecodeString(entry.Message)
+	msg, err := DeserializeMessage(msgBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.RecentBlockhash != [32]byte{0x4e} {
+		t.Errorf("message blockhash = %x, want the durable nonce", msg.RecentBlockhash)
+	}
+
+	bundle = roundTripBundle(t, bundle)
+	if err := svc.ValidateBundle(bundle); !errors.Is(err, config.ErrBundleUnsigned) {
+		t.Fatalf("ValidateBundle() of unsigned bundle error = %v, want ErrBundleUnsigned", err)
+	}
+
+	summary, err := SummarizeSweepBundle(bundle)
+	if err != nil {
+		t.Fatalf("SummarizeSweepBundle() error = %v", err)
+	}
+	if summary.TotalAmount != wantAmount || summary.TotalFee != strconv.Itoa(2*config.SOLBaseTransactionFee) {
+		t.Errorf("summary = %+v", summary)
+	}
+
+	signed, err := SignSweepBundle(bundle, testSeed(t, testMnemonic24))
+	if err != nil || signed != 1 {
+		t.Fatalf("SignSweepBundle() = %d, %v; want 1, nil", signed, err)
+	}
+	bundle = roundTripBundle(t, bundle)
+	if err := svc.ValidateBundle(bundle); err != nil {
+		t.Fatalf("ValidateBundle() error = %v", err)
+	}
+
+	result, err := svc.BroadcastBundle(context.Background(), bundle, "sweep-sol-bundle")
+	if err != nil {
+		t.Fatalf("BroadcastBundle() error = %v", err)
+	}
+	if result.SuccessCount != 1 || len(sent) != 1 || sent[0] != bundle.Transactions[0].SignedTx {
+		t.Fatalf("BroadcastBundle() = %+v with %d sends, want the signed tx sent once", result, len(sent))
+	}
+	if result.TxResults[0].TxSignature == "" {
+		t.Error("result has no transaction signature")
+	}
+}
+
+func TestSOLBundle_TamperedSignature(t *testing.T) {
+	svc, _, stored := newSOLBundleTestService(t)
+	addresses := []models.AddressWithBalance{{Chain: models.ChainSOL, AddressIndex: 0, Address: stored[0].Address}}
+
+	bundle, err := svc.ExportNativeBundle(context.Background(), addresses, testBundleSOLDest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SignSweepBundle(bundle, testSeed(t, testMnemonic24)); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, _ := base64.StdEncoding.DecodeString(bundle.Transactions[0].SignedTx)
+	raw[1] ^= 0xff // first byte of the first signature
+	bundle.Transactions[0].SignedTx = base64.StdEncoding.EncodeToString(raw)
+	if err := svc.ValidateBundle(bundle); !errors.Is(err, config.ErrBundleUnsigned) {
+		t.Errorf("ValidateBundle() with a corrupted signature error = %v, want ErrBundleUnsigned", err)
+	}
+}
+
+func TestSOLBundle_NonceAccountsShort(t *testing.T) {
+	svc, _, stored := newSOLBundleTestService(t)
+	addresses := make([]models.AddressWithBalance, len(stored))
+	for i, a := range stored {
+		addresses[i] = models.AddressWithBalance{Chain: models.ChainSOL, AddressIndex: a.AddressIndex, Address: a.Address}
+	}
+
+	// Two transactions, one configured nonce account.
+	if _, err := svc.ExportNativeBundle(context.Background(), addresses, testBundleSOLDest); !errors.Is(err, config.ErrSOLNonceAccountsShort) {
+		t.Errorf("ExportNativeBundle() error = %v, want ErrSOLNonceAccountsShort", err)
+	}
+
+	svc.SetNonceAccounts(nil)
+	if _, err := svc.ExportNativeBundle(context.Background(), addresses[:1], testBundleSOLDest); !errors.Is(err, config.ErrSOLNonceAccountsShort) {
+		t.Errorf("ExportNativeBundle() without nonce accounts error = %v, want ErrSOLNonceAccountsShort", err)
+	}
+}
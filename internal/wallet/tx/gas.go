@@ -38,6 +38,9 @@ type GasPreSeedService struct {
 	ethClient  EthClientWrapper
 	database   *db.DB
 	chainID    *big.Int
+
+	// dynamicFees builds type-2 transactions; see SetDynamicFees.
+	dynamicFees bool
 }
 
 // NewGasPreSeedService creates a gas pre-seeding service.
@@ -80,14 +83,13 @@ func (s *GasPreSeedService) Preview(
 	}
 
 	// Get gas price for cost estimation.
-	gasPrice, err := s.ethClient.SuggestGasPrice(ctx)
+	fee, err := EstimateBSCFee(ctx, s.ethClient, s.dynamicFees)
 	if err != nil {
-		return nil, fmt.Errorf("suggest gas price: %w", err)
+		return nil, err
 	}
-	gasPrice = BufferedGasPrice(gasPrice)
 
 	targetCount := int64(len(targetAddresses))
-	gasCostPerSend := new(big.Int).Mul(gasPrice, big.NewInt(int64(config.BSCGasLimitTransfer)))
+	gasCostPerSend := fee.GasCost(config.BSCGasLimitTransfer)
 	totalAmountToSend := new(big.Int).Mul(gasPreSeedAmountWei, big.NewInt(targetCount))
 	totalGasCost := new(big.Int).Mul(gasCostPerSend, big.NewInt(targetCount))
 	totalNeeded := new(big.Int).Add(totalAmountToSend, totalGasCost)
@@ -102,6 +104,11 @@ func (s *GasPreSeedService) Preview(
 		AmountPerTarget: gasPreSeedAmountWei.String(),
 		TotalNeeded:     totalNeeded.String(),
 		Sufficient:      sufficient,
+		GasPrice:        fee.GasPrice.String(),
+	}
+	if fee.Dynamic() {
+		preview.BaseFee = fee.BaseFee.String()
+		preview.PriorityFee = fee.TipCap.String()
 	}
 
 	slog.Info("gas pre-seed preview complete",
@@ -154,11 +161,10 @@ func (s *GasPreSeedService) Execute(
 	}
 
 	// Get gas price.
-	gasPrice, err := s.ethClient.SuggestGasPrice(ctx)
+	fee, err := EstimateBSCFee(ctx, s.ethClient, s.dynamicFees)
 	if err != nil {
-		return nil, fmt.Errorf("suggest gas price: %w", err)
+		return nil, err
 	}
-	gasPrice = BufferedGasPrice(gasPrice)
 
 	// Filter out targets that already have confirmed gas pre-seeds in this sweep.
 	var pendingTargets []string
@@ -206,7 +212,7 @@ func (s *GasPreSeedService) Execute(
 		}, nil
 	}
 
-	gasCostPerSend := new(big.Int).Mul(gasPrice, big.NewInt(int64(config.BSCGasLimitTransfer)))
+	gasCostPerSend := fee.GasCost(config.BSCGasLimitTransfer)
 	totalAmountToSend := new(big.Int).Mul(gasPreSeedAmountWei, big.NewInt(targetCount))
 	totalGasCost := new(big.Int).Mul(gasCostPerSend, big.NewInt(targetCount))
 	totalNeeded := new(big.Int).Add(totalAmountToSend, totalGasCost)
@@ -227,7 +233,8 @@ func (s *GasPreSeedService) Execute(
 		"sourceBalance", sourceBalance,
 		"targetCount", len(pendingTargets),
 		"amountPerTarget", gasPreSeedAmountWei,
-		"gasPrice", gasPrice,
+		"gasPrice", fee.GasPrice,
+		"dynamic", fee.Dynamic(),
 		"startNonce", nonce,
 		"sweepID", gpSweepID,
 	)
@@ -242,7 +249,7 @@ func (s *GasPreSeedService) Execute(
 			break
 		}
 
-		txResult := s.sendGasPreSeed(ctx, privKey, sourceAddr, targetAddr, nonce, gasPrice, i, gpSweepID)
+		txResult := s.sendGasPreSeed(ctx, privKey, sourceAddr, targetAddr, nonce, fee, i, gpSweepID)
 
 		// Handle nonce-too-low: re-fetch nonce and retry once.
 		if txResult.Status == "failed" && isNonceTooLowError(txResult.Error) {
@@ -267,7 +274,7 @@ func (s *GasPreSeedService) Execute(
 					"target", targetAddr,
 					"freshNonce", nonce,
 				)
-				txResult = s.sendGasPreSeed(ctx, privKey, sourceAddr, targetAddr, nonce, gasPrice, i, gpSweepID)
+				txResult = s.sendGasPreSeed(ctx, privKey, sourceAddr, targetAddr, nonce, fee, i, gpSweepID)
 			}
 		}
 
@@ -320,7 +327,7 @@ func (s *GasPreSeedService) sendGasPreSeed(
 	sourceAddr common.Address,
 	targetAddr string,
 	nonce uint64,
-	gasPrice *big.Int,
+	fee BSCFee,
 	targetIndex int,
 	sweepID string,
 ) models.BSCTxResult {
@@ -351,7 +358,7 @@ func (s *GasPreSeedService) sendGasPreSeed(
 	}
 
 	// Build native transfer.
-	unsignedTx := BuildBSCNativeTransfer(nonce, target, gasPreSeedAmountWei, gasPrice)
+	unsignedTx := fee.buildNativeTransfer(s.chainID, nonce, target, gasPreSeedAmountWei)
 
 	signedTx, err := SignBSCTx(unsignedTx, s.chainID, privKey)
 	if err != nil {
@@ -224,6 +224,17 @@ func (m *mockEthClientDynamic) SuggestGasPrice(_ context.Context) (*big.Int, err
 	return new(big.Int).Set(m.gasPrice), nil
 }
 
+func (m *mockEthClientDynamic) SuggestGasTipCap(_ context.Context) (*big.Int, error) {
+	if m.gasPriceErr != nil {
+		return nil, m.gasPriceErr
+	}
+	return new(big.Int).Set(m.gasPrice), nil
+}
+
+func (m *mockEthClientDynamic) FeeHistory(_ context.Context, _ uint64, _ *big.Int, _ []float64) (*ethereum.FeeHistory, error) {
+	return &ethereum.FeeHistory{BaseFee: []*big.Int{big.NewInt(0), big.NewInt(0)}}, nil
+}
+
 func (m *mockEthClientDynamic) SendTransaction(_ context.Context, tx *types.Transaction) error {
 	m.sentTxs = append(m.sentTxs, tx)
 	idx := int(m.sendCallNum.Add(1)) - 1
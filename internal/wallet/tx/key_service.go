@@ -15,14 +15,23 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 
 	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
 	"github.com/Fantasim/hdpay/internal/wallet/hd"
 )
 
 // KeyService derives private keys on demand from the mnemonic file.
 // The mnemonic is read fresh each time to minimize time secrets spend in memory.
+// When the wallet uses an encrypted keystore, the mnemonic is unlocked once at
+// startup and held mlocked instead (see SetUnlockedMnemonic). With SLIP-39 share
+// files the seed is recombined from the shares on each use (see SetShareFiles).
 type KeyService struct {
 	mnemonicFilePath string
+	mnemonic         []byte   // unlocked keystore mnemonic, mlocked; nil = read mnemonicFilePath
+	shareFilePaths   []string // SLIP-39 share files; when set, the seed comes from the shares
 	network          string
+	account          uint32 // BIP-44 account every key is derived under (m/purpose'/coin'/account')
+	btcAddressType   models.BTCAddressType
+	passphrase       []byte // optional BIP-39 passphrase, mlocked; nil = empty passphrase
 }
 
 // NewKeyService creates a key derivation service.
@@ -35,18 +44,250 @@ func NewKeyService(mnemonicFilePath string, network string) *KeyService {
 	return &KeyService{
 		mnemonicFilePath: mnemonicFilePath,
 		network:          network,
+		btcAddressType:   models.BTCAddressP2WPKH,
 	}
 }
 
-// DeriveBTCPrivateKey derives a BTC private key at the given address index.
-// Path: m/84'/0'/0'/0/N (mainnet) or m/84'/1'/0'/0/N (testnet).
-// The caller MUST zero the returned private key after use.
+// SetAccount selects the BIP-44 account all keys and addresses are derived under.
+// Must be called before the service is shared; the default is account 0.
+func (ks *KeyService) SetAccount(account uint32) {
+	ks.account = account
+	slog.Info("key service account configured", "account", account)
+}
+
+// Account returns the BIP-44 account keys are derived under.
+func (ks *KeyService) Account() uint32 {
+	return ks.account
+}
+
+// SetBTCAddressType selects the BTC address type used by DeriveAddress and
+// DeriveBTCPrivateKey. Must be called before the service is shared; the default is P2WPKH.
+func (ks *KeyService) SetBTCAddressType(addrType models.BTCAddressType) {
+	ks.btcAddressType = addrType
+	slog.Info("key service BTC address type configured", "addressType", addrType)
+}
+
+// BTCAddressType returns the configured BTC address type.
+func (ks *KeyService) BTCAddressType() models.BTCAddressType {
+	return ks.btcAddressType
+}
+
+// SetPassphrase installs the BIP-39 passphrase used for every seed derivation.
+// Unlike the mnemonic it cannot be re-read on demand (it may come from a prompt),
+// so a private copy is kept mlocked until Close. The caller may zero its own slice.
+func (ks *KeyService) SetPassphrase(passphrase []byte) {
+	ks.wipePassphrase()
+	if len(passphrase) == 0 {
+		return
+	}
+
+	ks.passphrase = make([]byte, len(passphrase))
+	copy(ks.passphrase, passphrase)
+	hd.MlockBytes(ks.passphrase)
+
+	slog.Info("key service BIP-39 passphrase configured")
+}
+
+// SetUnlockedMnemonic installs a mnemonic decrypted from the keystore. It is used
+// for every derivation instead of reading the mnemonic file, and a private copy is
+// kept mlocked until Close. The caller may zero its own slice.
+func (ks *KeyService) SetUnlockedMnemonic(mnemonic []byte) {
+	ks.wipeMnemonic()
+	if len(mnemonic) == 0 {
+		return
+	}
+
+	ks.mnemonic = make([]byte, len(mnemonic))
+	copy(ks.mnemonic, mnemonic)
+	hd.MlockBytes(ks.mnemonic)
+
+	slog.Info("key service using unlocked keystore mnemonic")
+}
+
+// SetShareFiles makes the service recover its seed from SLIP-39 share files instead
+// of a BIP-39 mnemonic. The configured passphrase is then the SLIP-39 passphrase.
+// Must be called before the service is shared.
+func (ks *KeyService) SetShareFiles(paths []string) {
+	ks.shareFilePaths = append([]string(nil), paths...)
+	slog.Info("key service using SLIP-39 share files", "files", len(paths))
+}
+
+// Close zeroes and unlocks any secret material held in memory.
+func (ks *KeyService) Close() {
+	ks.wipeMnemonic()
+	ks.wipePassphrase()
+	slog.Info("key service closed")
+}
+
+func (ks *KeyService) wipeMnemonic() {
+	if ks.mnemonic == nil {
+		return
+	}
+	hd.MunlockBytes(ks.mnemonic)
+	hd.ZeroBytes(ks.mnemonic)
+	ks.mnemonic = nil
+}
+
+// hasMnemonic reports whether a seed source (unlocked keystore, mnemonic file or
+// SLIP-39 shares) is configured.
+func (ks *KeyService) hasMnemonic() bool {
+	return ks.mnemonic != nil || ks.mnemonicFilePath != "" || len(ks.shareFilePaths) > 0
+}
+
+func (ks *KeyService) wipePassphrase() {
+	if ks.passphrase == nil {
+		return
+	}
+	hd.MunlockBytes(ks.passphrase)
+	hd.ZeroBytes(ks.passphrase)
+	ks.passphrase = nil
+}
+
+// DeriveAddress derives the public address at index for chain from the mnemonic and
+// passphrase. Used by the startup key consistency check (db.AddressDeriver).
+func (ks *KeyService) DeriveAddress(chain models.Chain, index int) (string, error) {
+	if !ks.hasMnemonic() {
+		return "", config.ErrMnemonicFileNotSet
+	}
+
+	seed, release, err := ks.readSeed()
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	addr, err := hd.DeriveAccountAddressFromSeed(seed, chain, ks.btcAddressType, ks.account, uint32(index), hd.NetworkParams(ks.network))
+	if err != nil {
+		return "", fmt.Errorf("%w: %s index %d: %s", config.ErrKeyDerivation, chain, index, err)
+	}
+	return addr, nil
+}
+
+// WithIndexDeriver passes fn an hd.IndexDeriver for chain over the configured account
+// (and BTC address type), derived once from the seed. The seed is released before fn
+// runs; the deriver holds only the account-level key and must not be used after fn
+// returns.
+func (ks *KeyService) WithIndexDeriver(chain models.Chain, fn func(derive hd.IndexDeriver) error) error {
+	if !ks.hasMnemonic() {
+		return config.ErrMnemonicFileNotSet
+	}
+
+	seed, release, err := ks.readSeed()
+	if err != nil {
+		return err
+	}
+	derive, err := hd.NewSeedIndexDeriver(seed, chain, ks.btcAddressType, ks.account, hd.NetworkParams(ks.network))
+	release()
+	if err != nil {
+		return fmt.Errorf("%w: %s account %d: %s", config.ErrKeyDerivation, chain, ks.account, err)
+	}
+
+	return fn(derive)
+}
+
+// DeriveBTCChangeAddress derives the address at index on the internal (change)
+// chain of the configured BTC account and address type, m/purpose'/coin'/account'/1/N.
+// Payouts send their change there.
+func (ks *KeyService) DeriveBTCChangeAddress(index uint32) (string, error) {
+	if !ks.hasMnemonic() {
+		return "", config.ErrMnemonicFileNotSet
+	}
+
+	seed, release, err := ks.readSeed()
+	if err != nil {
+		return "", err
+	}
+	derive, err := hd.NewSeedChangeIndexDeriver(seed, ks.btcAddressType, ks.account, hd.NetworkParams(ks.network))
+	release()
+	if err != nil {
+		return "", fmt.Errorf("%w: BTC change account %d: %s", config.ErrKeyDerivation, ks.account, err)
+	}
+
+	addr, err := derive(index)
+	if err != nil {
+		return "", fmt.Errorf("%w: BTC change index %d: %s", config.ErrKeyDerivation, index, err)
+	}
+	return addr, nil
+}
+
+// BTCDescriptor returns the BIP-380 output descriptor, with master fingerprint and
+// key origin, of the external chain of the configured BTC account and address type.
+// Only the account xpub is exposed; it is what Bitcoin Core needs to watch the wallet.
+func (ks *KeyService) BTCDescriptor() (string, error) {
+	if !ks.hasMnemonic() {
+		return "", config.ErrMnemonicFileNotSet
+	}
+
+	masterKey, err := ks.deriveMasterKey()
+	if err != nil {
+		return "", fmt.Errorf("derive master key for BTC descriptor: %w", err)
+	}
+
+	fingerprint, err := hd.MasterFingerprint(masterKey)
+	if err != nil {
+		return "", fmt.Errorf("%w: master fingerprint: %s", config.ErrKeyDerivation, err)
+	}
+
+	net := hd.NetworkParams(ks.network)
+	accountKey, err := hd.DeriveBTCAccountKey(masterKey, ks.btcAddressType, ks.account, net)
+	if err != nil {
+		return "", fmt.Errorf("%w: BTC account %d: %s", config.ErrKeyDerivation, ks.account, err)
+	}
+
+	return hd.BTCAccountDescriptor(accountKey, ks.btcAddressType, fingerprint, ks.account, net)
+}
+
+// BTCKeyOrigin returns the master fingerprint and public account key of the BTC
+// account for addrType, from which PSBT inputs are described (BTCKeyOriginSource).
+// No private key leaves this method.
+func (ks *KeyService) BTCKeyOrigin(addrType models.BTCAddressType) (*BTCKeyOrigin, error) {
+	if !ks.hasMnemonic() {
+		return nil, config.ErrMnemonicFileNotSet
+	}
+
+	masterKey, err := ks.deriveMasterKey()
+	if err != nil {
+		return nil, fmt.Errorf("derive master key for BTC key origin: %w", err)
+	}
+
+	fingerprint, err := hd.MasterFingerprint(masterKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: master fingerprint: %s", config.ErrKeyDerivation, err)
+	}
+
+	accountKey, err := hd.DeriveBTCAccountKey(masterKey, addrType, ks.account, hd.NetworkParams(ks.network))
+	if err != nil {
+		return nil, fmt.Errorf("%w: BTC %s account %d: %s", config.ErrKeyDerivation, addrType, ks.account, err)
+	}
+	accountPub, err := accountKey.Neuter()
+	if err != nil {
+		return nil, fmt.Errorf("%w: neuter BTC account key: %s", config.ErrKeyDerivation, err)
+	}
+
+	return &BTCKeyOrigin{Fingerprint: fingerprint, Account: ks.account, AccountKey: accountPub}, nil
+}
+
+// DeriveBTCPrivateKey derives a BTC private key at the given address index for the
+// configured address type. Path: m/purpose'/coin'/account'/0/N, e.g. m/84'/0'/account'/0/N
+// for P2WPKH on mainnet. The caller MUST zero the returned private key after use.
 func (ks *KeyService) DeriveBTCPrivateKey(ctx context.Context, index uint32) (*btcec.PrivateKey, error) {
-	if ks.mnemonicFilePath == "" {
+	return ks.DeriveBTCTypedPrivateKey(ctx, ks.btcAddressType, index)
+}
+
+// DeriveBTCTypedPrivateKey derives the BTC private key at index under the purpose of
+// addrType (84 P2WPKH, 86 P2TR, 49 P2SH-P2WPKH, 44 P2PKH). Used when a consolidation
+// spends inputs of several address types. The caller MUST zero the returned key.
+func (ks *KeyService) DeriveBTCTypedPrivateKey(ctx context.Context, addrType models.BTCAddressType, index uint32) (*btcec.PrivateKey, error) {
+	if !ks.hasMnemonic() {
 		return nil, config.ErrMnemonicFileNotSet
 	}
 
-	slog.Debug("deriving BTC private key", "index", index, "network", ks.network)
+	slog.Debug("deriving BTC private key",
+		"account", ks.account,
+		"addressType", addrType,
+		"index", index,
+		"network", ks.network,
+	)
 
 	// Check context before potentially slow file I/O.
 	if err := ctx.Err(); err != nil {
@@ -59,12 +300,46 @@ func (ks *KeyService) DeriveBTCPrivateKey(ctx context.Context, index uint32) (*b
 	}
 
 	net := hd.NetworkParams(ks.network)
-	privKey, err := deriveBTCPrivKeyAtIndex(masterKey, index, net)
+	privKey, err := deriveBTCPrivKeyAtIndex(masterKey, addrType, ks.account, index, net)
 	if err != nil {
-		return nil, fmt.Errorf("%w: BTC index %d: %s", config.ErrKeyDerivation, index, err)
+		return nil, fmt.Errorf("%w: BTC %s index %d: %s", config.ErrKeyDerivation, addrType, index, err)
 	}
 
-	slog.Debug("BTC private key derived", "index", index)
+	slog.Debug("BTC private key derived", "addressType", addrType, "index", index)
+	return privKey, nil
+}
+
+// DeriveBTCChangePrivateKey derives the BTC private key at index on the internal
+// (change) chain m/purpose'/coin'/account'/1/N of addrType, so that payouts can
+// spend their earlier change. The caller MUST zero the returned key.
+func (ks *KeyService) DeriveBTCChangePrivateKey(ctx context.Context, addrType models.BTCAddressType, index uint32) (*btcec.PrivateKey, error) {
+	if !ks.hasMnemonic() {
+		return nil, config.ErrMnemonicFileNotSet
+	}
+
+	slog.Debug("deriving BTC change private key",
+		"account", ks.account,
+		"addressType", addrType,
+		"index", index,
+		"network", ks.network,
+	)
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("context cancelled before key derivation: %w", err)
+	}
+
+	masterKey, err := ks.deriveMasterKey()
+	if err != nil {
+		return nil, fmt.Errorf("derive master key for BTC change key at index %d: %w", index, err)
+	}
+
+	net := hd.NetworkParams(ks.network)
+	privKey, err := deriveBTCChangePrivKeyAtIndex(masterKey, addrType, ks.account, index, net)
+	if err != nil {
+		return nil, fmt.Errorf("%w: BTC %s change index %d: %s", config.ErrKeyDerivation, addrType, index, err)
+	}
+
+	slog.Debug("BTC change private key derived", "addressType", addrType, "index", index)
 	return privKey, nil
 }
 
@@ -89,7 +364,21 @@ func ZeroEd25519Key(key ed25519.PrivateKey) {
 // CheckMnemonicAvailable checks whether the mnemonic file is accessible (exists and is readable).
 // This is a fast pre-flight check (os.Stat only, no read) for the external-disk workflow
 // where the mnemonic lives on removable media that may not be plugged in.
+// An unlocked keystore mnemonic is always available.
 func (ks *KeyService) CheckMnemonicAvailable() error {
+	if ks.mnemonic != nil {
+		return nil
+	}
+	if len(ks.shareFilePaths) > 0 {
+		for _, path := range ks.shareFilePaths {
+			if _, err := os.Stat(path); err != nil {
+				slog.Warn("share file not accessible", "path", path, "error", err)
+				return fmt.Errorf("%w: %s", config.ErrMnemonicFileUnavailable, err)
+			}
+		}
+		slog.Debug("share files accessible", "files", len(ks.shareFilePaths))
+		return nil
+	}
 	if ks.mnemonicFilePath == "" {
 		return config.ErrMnemonicFileNotSet
 	}
@@ -107,15 +396,15 @@ func (ks *KeyService) CheckMnemonicAvailable() error {
 }
 
 // DeriveBSCPrivateKey derives a BSC (EVM) ECDSA private key at the given address index.
-// Path: m/44'/60'/0'/0/N (same coin type for mainnet and testnet).
+// Path: m/44'/60'/account'/0/N (same coin type for mainnet and testnet).
 // Returns the private key and the corresponding address.
 // The caller MUST call ZeroECDSAKey(privKey) via defer after use.
 func (ks *KeyService) DeriveBSCPrivateKey(ctx context.Context, index uint32) (*ecdsa.PrivateKey, common.Address, error) {
-	if ks.mnemonicFilePath == "" {
+	if !ks.hasMnemonic() {
 		return nil, common.Address{}, config.ErrMnemonicFileNotSet
 	}
 
-	slog.Debug("deriving BSC private key", "index", index, "network", ks.network)
+	slog.Debug("deriving BSC private key", "account", ks.account, "index", index, "network", ks.network)
 
 	if err := ctx.Err(); err != nil {
 		return nil, common.Address{}, fmt.Errorf("context cancelled before BSC key derivation: %w", err)
@@ -126,7 +415,7 @@ func (ks *KeyService) DeriveBSCPrivateKey(ctx context.Context, index uint32) (*e
 		return nil, common.Address{}, fmt.Errorf("derive master key for BSC key at index %d: %w", index, err)
 	}
 
-	ecdsaKey, addr, err := deriveBSCPrivKeyAtIndex(masterKey, index)
+	ecdsaKey, addr, err := deriveBSCPrivKeyAtIndex(masterKey, ks.account, index)
 	if err != nil {
 		return nil, common.Address{}, fmt.Errorf("%w: BSC index %d: %s", config.ErrKeyDerivation, index, err)
 	}
@@ -135,8 +424,8 @@ func (ks *KeyService) DeriveBSCPrivateKey(ctx context.Context, index uint32) (*e
 	return ecdsaKey, addr, nil
 }
 
-// deriveBSCPrivKeyAtIndex walks the BIP-44 path m/44'/60'/0'/0/N and returns the ECDSA private key + address.
-func deriveBSCPrivKeyAtIndex(masterKey *hdkeychain.ExtendedKey, index uint32) (*ecdsa.PrivateKey, common.Address, error) {
+// deriveBSCPrivKeyAtIndex walks the BIP-44 path m/44'/60'/account'/0/N and returns the ECDSA private key + address.
+func deriveBSCPrivKeyAtIndex(masterKey *hdkeychain.ExtendedKey, account, index uint32) (*ecdsa.PrivateKey, common.Address, error) {
 	// m/44'
 	purpose, err := masterKey.Derive(hdkeychain.HardenedKeyStart + uint32(config.BIP44Purpose))
 	if err != nil {
@@ -149,19 +438,19 @@ func deriveBSCPrivKeyAtIndex(masterKey *hdkeychain.ExtendedKey, index uint32) (*
 		return nil, common.Address{}, fmt.Errorf("derive coin key: %w", err)
 	}
 
-	// m/44'/60'/0'
-	account, err := coin.Derive(hdkeychain.HardenedKeyStart + 0)
+	// m/44'/60'/account'
+	accountKey, err := coin.Derive(hdkeychain.HardenedKeyStart + account)
 	if err != nil {
 		return nil, common.Address{}, fmt.Errorf("derive account key: %w", err)
 	}
 
-	// m/44'/60'/0'/0
-	change, err := account.Derive(0)
+	// m/44'/60'/account'/0
+	change, err := accountKey.Derive(0)
 	if err != nil {
 		return nil, common.Address{}, fmt.Errorf("derive change key: %w", err)
 	}
 
-	// m/44'/60'/0'/0/N
+	// m/44'/60'/account'/0/N
 	child, err := change.Derive(index)
 	if err != nil {
 		return nil, common.Address{}, fmt.Errorf("derive child key at index %d: %w", index, err)
@@ -183,28 +472,59 @@ func deriveBSCPrivKeyAtIndex(masterKey *hdkeychain.ExtendedKey, index uint32) (*
 	return ecdsaKey, addr, nil
 }
 
-// deriveMasterKey reads the mnemonic file, converts to seed, and derives the BIP-32 master key.
-// Sensitive data (mnemonic bytes, seed) is mlocked to prevent swapping and zeroed after use.
-func (ks *KeyService) deriveMasterKey() (*hdkeychain.ExtendedKey, error) {
-	mnemonicBytes, err := hd.ReadMnemonicBytesFromFile(ks.mnemonicFilePath)
+// readSeed derives the BIP-39 seed from the unlocked keystore mnemonic, or reads the
+// mnemonic file, with the configured passphrase. With share files configured, the
+// SLIP-39 master secret is recovered instead. The seed is mlocked; the caller MUST
+// call release (via defer) to unlock and zero it. Mnemonic bytes read from the file
+// are zeroed before returning.
+func (ks *KeyService) readSeed() ([]byte, func(), error) {
+	if len(ks.shareFilePaths) > 0 {
+		seed, err := hd.SLIP39SeedFromFiles(ks.shareFilePaths, ks.passphrase)
+		if err != nil {
+			return nil, nil, fmt.Errorf("recover seed from shares: %w", err)
+		}
+		hd.MlockBytes(seed)
+		return seed, func() {
+			hd.MunlockBytes(seed)
+			hd.ZeroBytes(seed)
+		}, nil
+	}
+
+	mnemonicBytes := ks.mnemonic
+	if mnemonicBytes == nil {
+		fromFile, err := hd.ReadMnemonicBytesFromFile(ks.mnemonicFilePath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("read mnemonic: %w", err)
+		}
+		hd.MlockBytes(fromFile)
+		defer func() {
+			hd.MunlockBytes(fromFile)
+			hd.ZeroBytes(fromFile)
+		}()
+		mnemonicBytes = fromFile
+	}
+
+	seed, err := hd.MnemonicBytesToSeedWithPassphrase(mnemonicBytes, ks.passphrase)
 	if err != nil {
-		return nil, fmt.Errorf("read mnemonic: %w", err)
-	}
-	hd.MlockBytes(mnemonicBytes)
-	defer func() {
-		hd.MunlockBytes(mnemonicBytes)
-		hd.ZeroBytes(mnemonicBytes)
-	}()
-
-	seed, err := hd.MnemonicBytesToSeed(mnemonicBytes)
-	if err != nil {
-		return nil, fmt.Errorf("mnemonic to seed: %w", err)
+		return nil, nil, fmt.Errorf("mnemonic to seed: %w", err)
 	}
 	hd.MlockBytes(seed)
-	defer func() {
+
+	release := func() {
 		hd.MunlockBytes(seed)
 		hd.ZeroBytes(seed)
-	}()
+	}
+	return seed, release, nil
+}
+
+// deriveMasterKey reads the mnemonic file, converts to seed, and derives the BIP-32 master key.
+// Sensitive data (mnemonic bytes, seed) is mlocked to prevent swapping and zeroed after use.
+func (ks *KeyService) deriveMasterKey() (*hdkeychain.ExtendedKey, error) {
+	seed, release, err := ks.readSeed()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 
 	net := hd.NetworkParams(ks.network)
 	masterKey, err := hd.DeriveMasterKey(seed, net)
@@ -216,41 +536,27 @@ func (ks *KeyService) deriveMasterKey() (*hdkeychain.ExtendedKey, error) {
 }
 
 // DeriveSOLPrivateKey derives a SOL ed25519 private key at the given address index.
-// Path: m/44'/501'/N'/0' (SLIP-10 hardened, all levels).
+// Path: m/44'/501'/N'/account' (SLIP-10 hardened, all levels).
 // Unlike BTC/BSC, SOL uses SLIP-10 from the raw BIP-39 seed, not BIP-32 extended keys.
 // The caller MUST discard the returned private key after use.
 func (ks *KeyService) DeriveSOLPrivateKey(ctx context.Context, index uint32) (ed25519.PrivateKey, error) {
-	if ks.mnemonicFilePath == "" {
+	if !ks.hasMnemonic() {
 		return nil, config.ErrMnemonicFileNotSet
 	}
 
-	slog.Debug("deriving SOL private key", "index", index, "network", ks.network)
+	slog.Debug("deriving SOL private key", "account", ks.account, "index", index, "network", ks.network)
 
 	if err := ctx.Err(); err != nil {
 		return nil, fmt.Errorf("context cancelled before SOL key derivation: %w", err)
 	}
 
-	mnemonicBytes, err := hd.ReadMnemonicBytesFromFile(ks.mnemonicFilePath)
-	if err != nil {
-		return nil, fmt.Errorf("read mnemonic for SOL key at index %d: %w", index, err)
-	}
-	hd.MlockBytes(mnemonicBytes)
-	defer func() {
-		hd.MunlockBytes(mnemonicBytes)
-		hd.ZeroBytes(mnemonicBytes)
-	}()
-
-	seed, err := hd.MnemonicBytesToSeed(mnemonicBytes)
+	seed, release, err := ks.readSeed()
 	if err != nil {
-		return nil, fmt.Errorf("mnemonic to seed for SOL key at index %d: %w", index, err)
+		return nil, fmt.Errorf("seed for SOL key at index %d: %w", index, err)
 	}
-	hd.MlockBytes(seed)
-	defer func() {
-		hd.MunlockBytes(seed)
-		hd.ZeroBytes(seed)
-	}()
+	defer release()
 
-	privKey, err := hd.DeriveSOLPrivateKey(seed, index)
+	privKey, err := hd.DeriveSOLAccountPrivateKey(seed, ks.account, index)
 	if err != nil {
 		return nil, fmt.Errorf("%w: SOL index %d: %s", config.ErrKeyDerivation, index, err)
 	}
@@ -259,46 +565,50 @@ func (ks *KeyService) DeriveSOLPrivateKey(ctx context.Context, index uint32) (ed
 	return privKey, nil
 }
 
-// deriveBTCPrivKeyAtIndex walks the BIP-84 path m/84'/coin'/0'/0/N and returns the private key.
-func deriveBTCPrivKeyAtIndex(masterKey *hdkeychain.ExtendedKey, index uint32, net *chaincfg.Params) (*btcec.PrivateKey, error) {
-	coinType := uint32(config.BTCCoinType)
-	if net == &chaincfg.TestNet3Params {
-		coinType = uint32(config.BTCTestCoinType)
+// deriveBTCPrivKeyAtIndex walks m/purpose'/coin'/account'/0/N for addrType and returns the private key.
+func deriveBTCPrivKeyAtIndex(masterKey *hdkeychain.ExtendedKey, addrType models.BTCAddressType, account, index uint32, net *chaincfg.Params) (*btcec.PrivateKey, error) {
+	// m/purpose'/coin'/account'/0
+	change, err := hd.DeriveBTCAccountParentKey(masterKey, addrType, account, net)
+	if err != nil {
+		return nil, err
 	}
 
-	// m/84'
-	purpose, err := masterKey.Derive(hdkeychain.HardenedKeyStart + uint32(config.BIP84Purpose))
+	// m/purpose'/coin'/account'/0/N
+	child, err := change.Derive(index)
 	if err != nil {
-		return nil, fmt.Errorf("derive purpose key: %w", err)
+		return nil, fmt.Errorf("derive child key at index %d: %w", index, err)
 	}
 
-	// m/84'/coin'
-	coin, err := purpose.Derive(hdkeychain.HardenedKeyStart + coinType)
+	privKey, err := child.ECPrivKey()
 	if err != nil {
-		return nil, fmt.Errorf("derive coin key: %w", err)
+		return nil, fmt.Errorf("extract private key at index %d: %w", index, err)
 	}
 
-	// m/84'/coin'/0'
-	account, err := coin.Derive(hdkeychain.HardenedKeyStart + 0)
+	return privKey, nil
+}
+
+// deriveBTCChangePrivKeyAtIndex walks m/purpose'/coin'/account'/1/N for addrType and returns the private key.
+func deriveBTCChangePrivKeyAtIndex(masterKey *hdkeychain.ExtendedKey, addrType models.BTCAddressType, account, index uint32, net *chaincfg.Params) (*btcec.PrivateKey, error) {
+	accountKey, err := hd.DeriveBTCAccountKey(masterKey, addrType, account, net)
 	if err != nil {
-		return nil, fmt.Errorf("derive account key: %w", err)
+		return nil, err
 	}
 
-	// m/84'/coin'/0'/0
-	change, err := account.Derive(0)
+	// m/purpose'/coin'/account'/1
+	internal, err := hd.DeriveInternalParentFromAccount(accountKey)
 	if err != nil {
-		return nil, fmt.Errorf("derive change key: %w", err)
+		return nil, fmt.Errorf("derive BTC internal chain key: %w", err)
 	}
 
-	// m/84'/coin'/0'/0/N
-	child, err := change.Derive(index)
+	// m/purpose'/coin'/account'/1/N
+	child, err := internal.Derive(index)
 	if err != nil {
-		return nil, fmt.Errorf("derive child key at index %d: %w", index, err)
+		return nil, fmt.Errorf("derive change key at index %d: %w", index, err)
 	}
 
 	privKey, err := child.ECPrivKey()
 	if err != nil {
-		return nil, fmt.Errorf("extract private key at index %d: %w", index, err)
+		return nil, fmt.Errorf("extract private key at change index %d: %w", index, err)
 	}
 
 	return privKey, nil
@@ -2,6 +2,7 @@ package tx
 
 import (
 	"context"
+	"crypto/ed25519"
 	"os"
 	"path/filepath"
 	"testing"
@@ -10,6 +11,7 @@ import (
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/mr-tron/base58"
 
+	"github.com/Fantasim/hdpay/internal/shared/models"
 	"github.com/Fantasim/hdpay/internal/wallet/hd"
 )
 
@@ -352,3 +354,372 @@ func itoa(n int) string {
 	}
 	return s
 }
+
+func TestKeyService_Passphrase_ChangesAllChains(t *testing.T) {
+	path := writeTempMnemonic(t, testMnemonic24)
+	plain := NewKeyService(path, "mainnet")
+	protected := NewKeyService(path, "mainnet")
+	protected.SetPassphrase([]byte("hunter2"))
+	defer protected.Close()
+
+	seed, err := hd.MnemonicToSeedWithPassphrase(testMnemonic24, "hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, chain := range models.AllChains {
+		want, err := hd.DeriveAddressFromSeed(seed, chain, 3, &chaincfg.MainNetParams)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := protected.DeriveAddress(chain, 3)
+		if err != nil {
+			t.Fatalf("%s: DeriveAddress() error = %v", chain, err)
+		}
+		if got != want {
+			t.Errorf("%s: DeriveAddress() = %s, want %s", chain, got, want)
+		}
+
+		plainAddr, err := plain.DeriveAddress(chain, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if plainAddr == got {
+			t.Errorf("%s: passphrase had no effect on derived address", chain)
+		}
+	}
+
+	// Private key paths must honour the passphrase too.
+	_, bscAddr, err := protected.DeriveBSCPrivateKey(context.Background(), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantBSC, _ := hd.DeriveAddressFromSeed(seed, models.ChainBSC, 3, &chaincfg.MainNetParams)
+	if bscAddr.Hex() != wantBSC {
+		t.Errorf("DeriveBSCPrivateKey() address = %s, want %s", bscAddr.Hex(), wantBSC)
+	}
+
+	solKey, err := protected.DeriveSOLPrivateKey(context.Background(), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ZeroEd25519Key(solKey)
+	wantSOL, _ := hd.DeriveAddressFromSeed(seed, models.ChainSOL, 3, &chaincfg.MainNetParams)
+	if got := base58.Encode(solKey.Public().(ed25519.PublicKey)); got != wantSOL {
+		t.Errorf("DeriveSOLPrivateKey() address = %s, want %s", got, wantSOL)
+	}
+}
+
+func TestKeyService_Close_ZeroesPassphrase(t *testing.T) {
+	ks := NewKeyService("", "mainnet")
+	ks.SetPassphrase([]byte("hunter2"))
+
+	held := ks.passphrase
+	ks.Close()
+
+	if ks.passphrase != nil {
+		t.Error("passphrase not cleared after Close()")
+	}
+	for i, b := range held {
+		if b != 0 {
+			t.Fatalf("passphrase byte %d not zeroed", i)
+		}
+	}
+}
+
+func TestKeyService_UnlockedMnemonic_MatchesMnemonicFile(t *testing.T) {
+	fromFile := NewKeyService(writeTempMnemonic(t, testMnemonic24), "mainnet")
+	unlocked := NewKeyService("", "mainnet")
+	unlocked.SetUnlockedMnemonic([]byte(testMnemonic24))
+	defer unlocked.Close()
+
+	if err := unlocked.CheckMnemonicAvailable(); err != nil {
+		t.Fatalf("CheckMnemonicAvailable() error = %v", err)
+	}
+
+	for _, chain := range models.AllChains {
+		want, err := fromFile.DeriveAddress(chain, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := unlocked.DeriveAddress(chain, 2)
+		if err != nil {
+			t.Fatalf("%s: DeriveAddress() error = %v", chain, err)
+		}
+		if got != want {
+			t.Errorf("%s: unlocked mnemonic address = %s, mnemonic file = %s", chain, got, want)
+		}
+	}
+
+	privKey, err := unlocked.DeriveBTCPrivateKey(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("DeriveBTCPrivateKey() error = %v", err)
+	}
+	privKey.Zero()
+}
+
+func TestKeyService_Close_ZeroesUnlockedMnemonic(t *testing.T) {
+	ks := NewKeyService("", "mainnet")
+	ks.SetUnlockedMnemonic([]byte(testMnemonic24))
+
+	held := ks.mnemonic
+	ks.Close()
+
+	if ks.mnemonic != nil {
+		t.Error("mnemonic not cleared after Close()")
+	}
+	for i, b := range held {
+		if b != 0 {
+			t.Fatalf("mnemonic byte %d not zeroed", i)
+		}
+	}
+	if _, err := ks.DeriveAddress(models.ChainBTC, 0); err == nil {
+		t.Error("DeriveAddress() after Close() should fail without a mnemonic source")
+	}
+}
+
+func TestKeyService_ShareFiles_MatchMnemonicFile(t *testing.T) {
+	seed, err := hd.MnemonicToSeed(testMnemonic24)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shares, err := hd.SplitSLIP39(seed, []byte("share pass"), 1, []hd.SLIP39Group{{Threshold: 2, Count: 3}}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	var paths []string
+	for i, share := range shares[0][1:] {
+		path := filepath.Join(dir, "share"+itoa(i)+".txt")
+		if err := os.WriteFile(path, []byte(share+"\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	fromFile := NewKeyService(writeTempMnemonic(t, testMnemonic24), "mainnet")
+	fromShares := NewKeyService("", "mainnet")
+	fromShares.SetShareFiles(paths)
+	fromShares.SetPassphrase([]byte("share pass"))
+	defer fromShares.Close()
+
+	if err := fromShares.CheckMnemonicAvailable(); err != nil {
+		t.Fatalf("CheckMnemonicAvailable() error = %v", err)
+	}
+	for _, chain := range models.AllChains {
+		want, _ := fromFile.DeriveAddress(chain, 1)
+		got, err := fromShares.DeriveAddress(chain, 1)
+		if err != nil {
+			t.Fatalf("%s: DeriveAddress() error = %v", chain, err)
+		}
+		if got != want {
+			t.Errorf("%s: shares address = %s, mnemonic file = %s", chain, got, want)
+		}
+	}
+
+	os.Remove(paths[0])
+	if err := fromShares.CheckMnemonicAvailable(); err == nil {
+		t.Error("CheckMnemonicAvailable() should fail with a missing share file")
+	}
+}
+
+func TestKeyService_SetAccount_DerivesAccountKeys(t *testing.T) {
+	path := writeTempMnemonic(t, testMnemonic24)
+	account0 := NewKeyService(path, "mainnet")
+	account1 := NewKeyService(path, "mainnet")
+	account1.SetAccount(1)
+
+	if account1.Account() != 1 {
+		t.Fatalf("Account() = %d, want 1", account1.Account())
+	}
+
+	net := &chaincfg.MainNetParams
+	seed, err := hd.MnemonicToSeed(testMnemonic24)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, chain := range models.AllChains {
+		want, err := hd.DeriveAccountAddressFromSeed(seed, chain, models.BTCAddressP2WPKH, 1, 2, net)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := account1.DeriveAddress(chain, 2)
+		if err != nil {
+			t.Fatalf("%s: DeriveAddress() error = %v", chain, err)
+		}
+		if got != want {
+			t.Errorf("%s: DeriveAddress() = %s, want %s", chain, got, want)
+		}
+
+		other, err := account0.DeriveAddress(chain, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if other == got {
+			t.Errorf("%s: account 1 address equals account 0 address %s", chain, got)
+		}
+	}
+
+	// Signing keys must belong to the account's addresses.
+	btcKey, err := account1.DeriveBTCPrivateKey(context.Background(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer btcKey.Zero()
+	btcAddr, err := btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(btcKey.PubKey().SerializeCompressed()), net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantBTC, _ := hd.DeriveAccountAddressFromSeed(seed, models.ChainBTC, models.BTCAddressP2WPKH, 1, 2, net)
+	if btcAddr.EncodeAddress() != wantBTC {
+		t.Errorf("DeriveBTCPrivateKey() address = %s, want %s", btcAddr.EncodeAddress(), wantBTC)
+	}
+
+	bscKey, bscAddr, err := account1.DeriveBSCPrivateKey(context.Background(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ZeroECDSAKey(bscKey)
+	wantBSC, _ := hd.DeriveAccountAddressFromSeed(seed, models.ChainBSC, models.BTCAddressP2WPKH, 1, 2, net)
+	if bscAddr.Hex() != wantBSC {
+		t.Errorf("DeriveBSCPrivateKey() address = %s, want %s", bscAddr.Hex(), wantBSC)
+	}
+
+	solKey, err := account1.DeriveSOLPrivateKey(context.Background(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ZeroEd25519Key(solKey)
+	wantSOL, _ := hd.DeriveAccountAddressFromSeed(seed, models.ChainSOL, models.BTCAddressP2WPKH, 1, 2, net)
+	if got := base58.Encode(solKey.Public().(ed25519.PublicKey)); got != wantSOL {
+		t.Errorf("DeriveSOLPrivateKey() address = %s, want %s", got, wantSOL)
+	}
+}
+
+func TestKeyService_DeriveBTCTypedPrivateKey_MatchesAddressTypes(t *testing.T) {
+	path := writeTempMnemonic(t, testMnemonic24)
+	net := &chaincfg.MainNetParams
+	seed, _ := hd.MnemonicToSeed(testMnemonic24)
+
+	for _, addrType := range models.AllBTCAddressTypes {
+		t.Run(string(addrType), func(t *testing.T) {
+			ks := NewKeyService(path, "mainnet")
+			ks.SetBTCAddressType(addrType)
+
+			want, err := hd.DeriveAccountAddressFromSeed(seed, models.ChainBTC, addrType, 0, 3, net)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := ks.DeriveAddress(models.ChainBTC, 3)
+			if err != nil {
+				t.Fatalf("DeriveAddress() error = %v", err)
+			}
+			if got != want {
+				t.Errorf("DeriveAddress() = %s, want %s", got, want)
+			}
+
+			privKey, err := ks.DeriveBTCTypedPrivateKey(context.Background(), addrType, 3)
+			if err != nil {
+				t.Fatalf("DeriveBTCTypedPrivateKey() error = %v", err)
+			}
+			defer privKey.Zero()
+
+			addr, err := hd.BTCAddressFromPubKey(privKey.PubKey(), addrType, net)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if addr.EncodeAddress() != want {
+				t.Errorf("key-derived %s address = %s, want %s", addrType, addr.EncodeAddress(), want)
+			}
+		})
+	}
+}
+
+func TestKeyService_BTCDescriptor(t *testing.T) {
+	ks := NewKeyService(writeTempMnemonic(t, testMnemonic24), "mainnet")
+
+	desc, err := ks.BTCDescriptor()
+	if err != nil {
+		t.Fatalf("BTCDescriptor() error = %v", err)
+	}
+	want := "wpkh([5436d724/84'/0'/0']xpub6Bner3L3tdQW367NmmMsWKtMfP7hbu4JxdtbSGdWWjSzLkSUEnT7G9h5GFWUXtifeRhHiUXJuek1qeaTJqnXkveWpiHp8rmt53E8HTMshg9/0/*)#tk4vnxy8"
+	if desc != want {
+		t.Errorf("BTCDescriptor() = %s, want %s", desc, want)
+	}
+
+	ks.SetAccount(2)
+	ks.SetBTCAddressType(models.BTCAddressP2TR)
+	desc, err = ks.BTCDescriptor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := desc[:len("tr([5436d724/86'/0'/2']")]; got != "tr([5436d724/86'/0'/2']" {
+		t.Errorf("Taproot account 2 descriptor = %s", desc)
+	}
+
+	if _, err := NewKeyService("", "mainnet").BTCDescriptor(); err == nil {
+		t.Error("BTCDescriptor() without a seed source should fail")
+	}
+}
+
+func TestKeyService_WithIndexDeriver(t *testing.T) {
+	ks := NewKeyService(writeTempMnemonic(t, testMnemonic24), "mainnet")
+
+	err := ks.WithIndexDeriver(models.ChainBTC, func(derive hd.IndexDeriver) error {
+		addr, err := derive(0)
+		if err != nil {
+			return err
+		}
+		if addr != "bc1qzmtrqsfuaf6l6kkcsseumq26ukaphfj9skkug6" {
+			t.Errorf("BTC index 0 = %s, want m/84'/0'/0'/0/0 of the 24-word test mnemonic", addr)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithIndexDeriver(BTC) error = %v", err)
+	}
+
+	ks.SetAccount(1)
+	err = ks.WithIndexDeriver(models.ChainSOL, func(derive hd.IndexDeriver) error {
+		got, err := derive(3)
+		if err != nil {
+			return err
+		}
+		want, err := ks.DeriveAddress(models.ChainSOL, 3)
+		if err != nil {
+			return err
+		}
+		if got != want {
+			t.Errorf("SOL account 1 index 3 = %s, want %s", got, want)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithIndexDeriver(SOL) error = %v", err)
+	}
+
+	if err := NewKeyService("", "mainnet").WithIndexDeriver(models.ChainBTC, func(hd.IndexDeriver) error { return nil }); err == nil {
+		t.Error("WithIndexDeriver() without a seed source should fail")
+	}
+}
+
+func TestKeyService_DeriveBTCChangeAddress(t *testing.T) {
+	ks := NewKeyService(writeTempMnemonic(t, testMnemonic24), "mainnet")
+
+	// First change address m/84'/0'/0'/1/0 of the 24-word test mnemonic.
+	addr, err := ks.DeriveBTCChangeAddress(0)
+	if err != nil {
+		t.Fatalf("DeriveBTCChangeAddress() error = %v", err)
+	}
+	if addr != "bc1qes80p0st92xjem9aw48v57fk35f44kjzm2064m" {
+		t.Errorf("change index 0 = %s, want m/84'/0'/0'/1/0 of the 24-word test mnemonic", addr)
+	}
+
+	if _, err := NewKeyService("", "mainnet").DeriveBTCChangeAddress(0); err == nil {
+		t.Error("DeriveBTCChangeAddress() without a seed source should fail")
+	}
+}
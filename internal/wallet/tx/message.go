@@ -0,0 +1,384 @@
+package tx
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/mr-tron/base58"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/wallet/hd"
+)
+
+// SignMessage signs message with the key of the address at index, proving control
+// of that address: a BIP-322 simple signature for BTC (P2WPKH and P2TR only), an
+// EIP-191 personal_sign signature for BSC and a Solana off-chain message signature
+// for SOL. The derived key is zeroed before returning.
+func (ks *KeyService) SignMessage(ctx context.Context, chain models.Chain, index uint32, message []byte) (*models.SignedMessage, error) {
+	if err := checkMessage(message); err != nil {
+		return nil, err
+	}
+
+	signed := &models.SignedMessage{
+		Chain:        chain,
+		AddressIndex: int(index),
+		Message:      string(message),
+	}
+
+	switch chain {
+	case models.ChainBTC:
+		addrType := ks.btcAddressType
+		if addrType != models.BTCAddressP2WPKH && addrType != models.BTCAddressP2TR {
+			return nil, fmt.Errorf("%w: BTC %s (BIP-322 simple needs P2WPKH or P2TR)", config.ErrMessageSigningUnsupported, addrType)
+		}
+		privKey, err := ks.DeriveBTCPrivateKey(ctx, index)
+		if err != nil {
+			return nil, err
+		}
+		defer privKey.Zero()
+
+		addr, err := hd.BTCAddressFromPubKey(privKey.PubKey(), addrType, hd.NetworkParams(ks.network))
+		if err != nil {
+			return nil, fmt.Errorf("BTC address at index %d: %w", index, err)
+		}
+		pkScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			return nil, fmt.Errorf("BTC script at index %d: %w", index, err)
+		}
+		signed.Address = addr.EncodeAddress()
+		signed.Scheme = models.MessageSchemeBIP322Simple
+		signed.Signature, err = signBIP322Simple(privKey, pkScript, message)
+		if err != nil {
+			return nil, err
+		}
+
+	case models.ChainBSC:
+		privKey, addr, err := ks.DeriveBSCPrivateKey(ctx, index)
+		if err != nil {
+			return nil, err
+		}
+		defer ZeroECDSAKey(privKey)
+
+		signed.Address = addr.Hex()
+		signed.Scheme = models.MessageSchemeEIP191
+		signed.Signature, err = signEIP191(privKey, message)
+		if err != nil {
+			return nil, err
+		}
+
+	case models.ChainSOL:
+		privKey, err := ks.DeriveSOLPrivateKey(ctx, index)
+		if err != nil {
+			return nil, err
+		}
+		defer ZeroEd25519Key(privKey)
+
+		offchain, err := solOffchainMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		signed.Address = base58.Encode(privKey.Public().(ed25519.PublicKey))
+		signed.Scheme = models.MessageSchemeSOLOffchain
+		signed.Signature = base58.Encode(ed25519.Sign(privKey, offchain))
+
+	default:
+		return nil, fmt.Errorf("unsupported chain: %s", chain)
+	}
+
+	slog.Info("message signed",
+		"chain", chain,
+		"index", index,
+		"address", signed.Address,
+		"scheme", signed.Scheme,
+		"messageBytes", len(message),
+	)
+	return signed, nil
+}
+
+// VerifyMessage checks a proof-of-ownership signature made by SignMessage (or any
+// wallet implementing the same scheme) for address. It returns the scheme used and
+// an error wrapping ErrInvalidMessageSignature when the signature does not prove
+// control of the address.
+func VerifyMessage(chain models.Chain, address string, message []byte, signature string, net *chaincfg.Params) (models.MessageScheme, error) {
+	if err := checkMessage(message); err != nil {
+		return "", err
+	}
+
+	switch chain {
+	case models.ChainBTC:
+		return models.MessageSchemeBIP322Simple, verifyBIP322Simple(address, message, signature, net)
+	case models.ChainBSC:
+		return models.MessageSchemeEIP191, verifyEIP191(address, message, signature)
+	case models.ChainSOL:
+		return models.MessageSchemeSOLOffchain, verifySOLOffchainMessage(address, message, signature)
+	default:
+		return "", fmt.Errorf("unsupported chain: %s", chain)
+	}
+}
+
+// checkMessage rejects empty and oversized messages.
+func checkMessage(message []byte) error {
+	if len(message) == 0 {
+		return fmt.Errorf("%w: empty message", config.ErrInvalidMessage)
+	}
+	if len(message) > config.MaxSignMessageBytes {
+		return fmt.Errorf("%w: %d bytes (max %d)", config.ErrInvalidMessage, len(message), config.MaxSignMessageBytes)
+	}
+	return nil
+}
+
+// --- BIP-322 (BTC) ---
+
+// bip322Transactions builds the virtual to_spend and to_sign transactions of a
+// BIP-322 signature of message by pkScript.
+func bip322Transactions(pkScript, message []byte) (*wire.MsgTx, *wire.MsgTx, error) {
+	msgHash := chainhash.TaggedHash([]byte(config.BIP322Tag), message)
+
+	scriptSig, err := txscript.NewScriptBuilder().AddOp(txscript.OP_0).AddData(msgHash[:]).Script()
+	if err != nil {
+		return nil, nil, fmt.Errorf("build to_spend scriptSig: %w", err)
+	}
+	toSpend := wire.NewMsgTx(0)
+	spendIn := wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{}, 0xFFFFFFFF), scriptSig, nil)
+	spendIn.Sequence = 0
+	toSpend.AddTxIn(spendIn)
+	toSpend.AddTxOut(wire.NewTxOut(0, pkScript))
+
+	toSpendHash := toSpend.TxHash()
+	toSign := wire.NewMsgTx(0)
+	signIn := wire.NewTxIn(wire.NewOutPoint(&toSpendHash, 0), nil, nil)
+	signIn.Sequence = 0
+	toSign.AddTxIn(signIn)
+	toSign.AddTxOut(wire.NewTxOut(0, []byte{txscript.OP_RETURN}))
+
+	return toSpend, toSign, nil
+}
+
+// signBIP322Simple signs message for a P2WPKH or P2TR pkScript and returns the
+// base64 witness stack of the to_sign input.
+func signBIP322Simple(privKey *btcec.PrivateKey, pkScript, message []byte) (string, error) {
+	_, toSign, err := bip322Transactions(pkScript, message)
+	if err != nil {
+		return "", err
+	}
+	fetcher := txscript.NewCannedPrevOutputFetcher(pkScript, 0)
+	sigHashes := txscript.NewTxSigHashes(toSign, fetcher)
+
+	var witness wire.TxWitness
+	switch class := txscript.GetScriptClass(pkScript); class {
+	case txscript.WitnessV0PubKeyHashTy:
+		witness, err = txscript.WitnessSignature(toSign, sigHashes, 0, 0, pkScript, txscript.SigHashAll, privKey, true)
+	case txscript.WitnessV1TaprootTy:
+		witness, err = txscript.TaprootWitnessSignature(toSign, sigHashes, 0, 0, pkScript, txscript.SigHashDefault, privKey)
+	default:
+		return "", fmt.Errorf("%w: BTC script class %s", config.ErrMessageSigningUnsupported, class)
+	}
+	if err != nil {
+		return "", fmt.Errorf("BIP-322 sign: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := wire.WriteVarInt(&buf, 0, uint64(len(witness))); err != nil {
+		return "", err
+	}
+	for _, item := range witness {
+		if err := wire.WriteVarBytes(&buf, 0, item); err != nil {
+			return "", err
+		}
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// verifyBIP322Simple runs the script engine on the to_sign transaction carrying
+// the witness of a BIP-322 simple signature.
+func verifyBIP322Simple(address string, message []byte, signature string, net *chaincfg.Params) error {
+	addr, err := btcutil.DecodeAddress(address, net)
+	if err != nil || !addr.IsForNet(net) {
+		return fmt.Errorf("%w: invalid BTC address %q", config.ErrInvalidMessage, address)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return fmt.Errorf("%w: %s", config.ErrInvalidMessage, err)
+	}
+	if class := txscript.GetScriptClass(pkScript); class != txscript.WitnessV0PubKeyHashTy && class != txscript.WitnessV1TaprootTy {
+		return fmt.Errorf("%w: BTC script class %s (BIP-322 simple needs P2WPKH or P2TR)", config.ErrMessageSigningUnsupported, class)
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("%w: decode base64: %s", config.ErrInvalidMessageSignature, err)
+	}
+	witness, err := decodeWitness(raw)
+	if err != nil {
+		return fmt.Errorf("%w: %s", config.ErrInvalidMessageSignature, err)
+	}
+
+	_, toSign, err := bip322Transactions(pkScript, message)
+	if err != nil {
+		return err
+	}
+	toSign.TxIn[0].Witness = witness
+
+	fetcher := txscript.NewCannedPrevOutputFetcher(pkScript, 0)
+	sigHashes := txscript.NewTxSigHashes(toSign, fetcher)
+	engine, err := txscript.NewEngine(pkScript, toSign, 0, txscript.StandardVerifyFlags, nil, sigHashes, 0, fetcher)
+	if err != nil {
+		return fmt.Errorf("%w: %s", config.ErrInvalidMessageSignature, err)
+	}
+	if err := engine.Execute(); err != nil {
+		return fmt.Errorf("%w: %s", config.ErrInvalidMessageSignature, err)
+	}
+	return nil
+}
+
+// decodeWitness parses a consensus-encoded witness stack with no trailing bytes.
+func decodeWitness(raw []byte) (wire.TxWitness, error) {
+	r := bytes.NewReader(raw)
+	count, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, fmt.Errorf("witness item count: %w", err)
+	}
+	if count == 0 || count > uint64(len(raw)) {
+		return nil, fmt.Errorf("invalid witness item count %d", count)
+	}
+	witness := make(wire.TxWitness, count)
+	for i := range witness {
+		witness[i], err = wire.ReadVarBytes(r, 0, uint32(len(raw)), "witness item")
+		if err != nil {
+			return nil, fmt.Errorf("witness item %d: %w", i, err)
+		}
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("%d trailing bytes after witness", r.Len())
+	}
+	return witness, nil
+}
+
+// --- EIP-191 (BSC) ---
+
+// eip191Hash is keccak256("\x19Ethereum Signed Message:\n" + len(message) + message).
+func eip191Hash(message []byte) []byte {
+	return crypto.Keccak256([]byte(config.EIP191Prefix+strconv.Itoa(len(message))), message)
+}
+
+// signEIP191 returns the 65-byte personal_sign signature (r || s || v, v = 27/28)
+// as 0x-hex.
+func signEIP191(privKey *ecdsa.PrivateKey, message []byte) (string, error) {
+	sig, err := crypto.Sign(eip191Hash(message), privKey)
+	if err != nil {
+		return "", fmt.Errorf("EIP-191 sign: %w", err)
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	return hexutil.Encode(sig), nil
+}
+
+// verifyEIP191 recovers the signer of a personal_sign signature and compares it
+// with address. Recovery IDs 0/1 and 27/28 are both accepted.
+func verifyEIP191(address string, message []byte, signature string) error {
+	if !common.IsHexAddress(address) {
+		return fmt.Errorf("%w: invalid BSC address %q", config.ErrInvalidMessage, address)
+	}
+	sig, err := hexutil.Decode(signature)
+	if err != nil {
+		return fmt.Errorf("%w: decode hex: %s", config.ErrInvalidMessageSignature, err)
+	}
+	if len(sig) != crypto.SignatureLength {
+		return fmt.Errorf("%w: %d bytes, expected %d", config.ErrInvalidMessageSignature, len(sig), crypto.SignatureLength)
+	}
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	if sig[crypto.RecoveryIDOffset] > 1 {
+		return fmt.Errorf("%w: invalid recovery ID", config.ErrInvalidMessageSignature)
+	}
+
+	pubKey, err := crypto.SigToPub(eip191Hash(message), sig)
+	if err != nil {
+		return fmt.Errorf("%w: recover signer: %s", config.ErrInvalidMessageSignature, err)
+	}
+	if signer := crypto.PubkeyToAddress(*pubKey); signer != common.HexToAddress(address) {
+		return fmt.Errorf("%w: signed by %s", config.ErrInvalidMessageSignature, signer.Hex())
+	}
+	return nil
+}
+
+// --- Solana off-chain messages (SOL) ---
+
+// solOffchainMessage wraps message in the version 0 off-chain message header:
+// signing domain, version, format and u16 LE length. The format is the most
+// restrictive that fits: 0 printable ASCII and 1 UTF-8 (both up to the Ledger
+// limit), 2 longer UTF-8. Other payloads are rejected.
+func solOffchainMessage(message []byte) ([]byte, error) {
+	if !utf8.Valid(message) {
+		return nil, fmt.Errorf("%w: SOL off-chain messages must be UTF-8", config.ErrInvalidMessage)
+	}
+	var format byte
+	switch {
+	case len(message) <= config.SOLOffchainLedgerMaxLen && isPrintableASCII(message):
+		format = 0
+	case len(message) <= config.SOLOffchainLedgerMaxLen:
+		format = 1
+	case len(message) <= config.SOLOffchainMaxLen:
+		format = 2
+	default:
+		return nil, fmt.Errorf("%w: %d bytes (max %d)", config.ErrInvalidMessage, len(message), config.SOLOffchainMaxLen)
+	}
+
+	buf := make([]byte, 0, len(config.SOLOffchainSigningDomain)+4+len(message))
+	buf = append(buf, config.SOLOffchainSigningDomain...)
+	buf = append(buf, 0, format) // header version 0
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(len(message)))
+	return append(buf, message...), nil
+}
+
+// isPrintableASCII reports whether every byte is in 0x20..0x7e.
+func isPrintableASCII(b []byte) bool {
+	for _, c := range b {
+		if c < 0x20 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// verifySOLOffchainMessage checks a base58 ed25519 signature of the off-chain
+// message by the address's key.
+func verifySOLOffchainMessage(address string, message []byte, signature string) error {
+	pubKey, err := SolPublicKeyFromBase58(address)
+	if err != nil {
+		return fmt.Errorf("%w: invalid SOL address %q", config.ErrInvalidMessage, address)
+	}
+	offchain, err := solOffchainMessage(message)
+	if err != nil {
+		return err
+	}
+	sig, err := base58.Decode(signature)
+	if err != nil {
+		return fmt.Errorf("%w: decode base58: %s", config.ErrInvalidMessageSignature, err)
+	}
+	if len(sig) != ed25519.SignatureSize {
+		return fmt.Errorf("%w: %d bytes, expected %d", config.ErrInvalidMessageSignature, len(sig), ed25519.SignatureSize)
+	}
+	if !ed25519.Verify(pubKey[:], offchain, sig) {
+		return fmt.Errorf("%w: signature does not match %s", config.ErrInvalidMessageSignature, address)
+	}
+	return nil
+}
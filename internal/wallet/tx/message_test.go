@@ -0,0 +1,156 @@
+package tx
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+)
+
+func TestSignMessage_RoundTrip(t *testing.T) {
+	message := []byte("I control this address — hdpay 2026-10-16")
+
+	tests := []struct {
+		name     string
+		chain    models.Chain
+		addrType models.BTCAddressType
+		scheme   models.MessageScheme
+		prefix   string
+	}{
+		{"BTC P2WPKH", models.ChainBTC, models.BTCAddressP2WPKH, models.MessageSchemeBIP322Simple, "tb1q"},
+		{"BTC P2TR", models.ChainBTC, models.BTCAddressP2TR, models.MessageSchemeBIP322Simple, "tb1p"},
+		{"BSC", models.ChainBSC, "", models.MessageSchemeEIP191, "0x"},
+		{"SOL", models.ChainSOL, "", models.MessageSchemeSOLOffchain, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ks := NewKeyService(writeTempMnemonic(t, testMnemonic24), "testnet")
+			if tt.addrType != "" {
+				ks.SetBTCAddressType(tt.addrType)
+			}
+
+			signed, err := ks.SignMessage(context.Background(), tt.chain, 3, message)
+			if err != nil {
+				t.Fatalf("SignMessage() error = %v", err)
+			}
+			if signed.Scheme != tt.scheme || signed.AddressIndex != 3 || !strings.HasPrefix(signed.Address, tt.prefix) {
+				t.Fatalf("signed = %+v, want scheme %s at index 3 with prefix %q", signed, tt.scheme, tt.prefix)
+			}
+
+			scheme, err := VerifyMessage(tt.chain, signed.Address, message, signed.Signature, &chaincfg.TestNet3Params)
+			if err != nil {
+				t.Fatalf("VerifyMessage() error = %v", err)
+			}
+			if scheme != tt.scheme {
+				t.Errorf("scheme = %s, want %s", scheme, tt.scheme)
+			}
+
+			_, err = VerifyMessage(tt.chain, signed.Address, []byte("another message"), signed.Signature, &chaincfg.TestNet3Params)
+			if !errors.Is(err, config.ErrInvalidMessageSignature) {
+				t.Errorf("tampered message error = %v, want ErrInvalidMessageSignature", err)
+			}
+
+			other, err := ks.SignMessage(context.Background(), tt.chain, 4, message)
+			if err != nil {
+				t.Fatalf("SignMessage(index 4) error = %v", err)
+			}
+			_, err = VerifyMessage(tt.chain, other.Address, message, signed.Signature, &chaincfg.TestNet3Params)
+			if !errors.Is(err, config.ErrInvalidMessageSignature) {
+				t.Errorf("wrong address error = %v, want ErrInvalidMessageSignature", err)
+			}
+		})
+	}
+}
+
+func TestVerifyMessage_BIP322Vector(t *testing.T) {
+	// "Hello World" signed by bc1q9vza2e8x573nczrlzms0wvx3gsqjx7vavgkx0l (BIP-322 test vector).
+	const (
+		address   = "bc1q9vza2e8x573nczrlzms0wvx3gsqjx7vavgkx0l"
+		signature = "AkcwRAIgZRfIY3p7/DoVTty6YZbWS71bc5Vct9p9Fia83eRmw2QCICK/ENGfwLtptFluMGs2KsqoNSk89pO7F29zJLUx9a/sASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI="
+	)
+
+	if _, err := VerifyMessage(models.ChainBTC, address, []byte("Hello World"), signature, &chaincfg.MainNetParams); err != nil {
+		t.Errorf("VerifyMessage() error = %v, want valid", err)
+	}
+	_, err := VerifyMessage(models.ChainBTC, address, []byte("Hello World!"), signature, &chaincfg.MainNetParams)
+	if !errors.Is(err, config.ErrInvalidMessageSignature) {
+		t.Errorf("tampered message error = %v, want ErrInvalidMessageSignature", err)
+	}
+}
+
+func TestVerifyMessage_EIP191RecoveryID(t *testing.T) {
+	ks := NewKeyService(writeTempMnemonic(t, testMnemonic24), "testnet")
+	message := []byte("hello")
+	signed, err := ks.SignMessage(context.Background(), models.ChainBSC, 0, message)
+	if err != nil {
+		t.Fatalf("SignMessage() error = %v", err)
+	}
+
+	// Some wallets emit v as 0/1 instead of 27/28.
+	last := signed.Signature[len(signed.Signature)-2:]
+	raw := map[string]string{"1b": "00", "1c": "01"}[last]
+	if raw == "" {
+		t.Fatalf("signature v = %s, want 1b or 1c", last)
+	}
+	if _, err := VerifyMessage(models.ChainBSC, strings.ToLower(signed.Address), message, signed.Signature[:len(signed.Signature)-2]+raw, nil); err != nil {
+		t.Errorf("VerifyMessage(v=%s) error = %v", raw, err)
+	}
+}
+
+func TestSOLOffchainMessage_Format(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		format  byte
+	}{
+		{"ascii", "hello", 0},
+		{"utf8", "héllo", 1},
+		{"long", strings.Repeat("a", config.SOLOffchainLedgerMaxLen+1), 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := solOffchainMessage([]byte(tt.message))
+			if err != nil {
+				t.Fatalf("solOffchainMessage() error = %v", err)
+			}
+			header := len(config.SOLOffchainSigningDomain)
+			if string(got[:header]) != config.SOLOffchainSigningDomain || got[header] != 0 || got[header+1] != tt.format {
+				t.Errorf("header = %x, want domain, version 0, format %d", got[:header+2], tt.format)
+			}
+			if n := int(got[header+2]) | int(got[header+3])<<8; n != len(tt.message) {
+				t.Errorf("length = %d, want %d", n, len(tt.message))
+			}
+		})
+	}
+
+	if _, err := solOffchainMessage([]byte{0xff, 0xfe}); !errors.Is(err, config.ErrInvalidMessage) {
+		t.Errorf("invalid UTF-8 error = %v, want ErrInvalidMessage", err)
+	}
+}
+
+func TestSignMessage_Rejected(t *testing.T) {
+	ks := NewKeyService(writeTempMnemonic(t, testMnemonic24), "testnet")
+
+	if _, err := ks.SignMessage(context.Background(), models.ChainBSC, 0, nil); !errors.Is(err, config.ErrInvalidMessage) {
+		t.Errorf("empty message error = %v, want ErrInvalidMessage", err)
+	}
+	big := make([]byte, config.MaxSignMessageBytes+1)
+	if _, err := ks.SignMessage(context.Background(), models.ChainBSC, 0, big); !errors.Is(err, config.ErrInvalidMessage) {
+		t.Errorf("oversized message error = %v, want ErrInvalidMessage", err)
+	}
+
+	ks.SetBTCAddressType(models.BTCAddressP2PKH)
+	if _, err := ks.SignMessage(context.Background(), models.ChainBTC, 0, []byte("hello")); !errors.Is(err, config.ErrMessageSigningUnsupported) {
+		t.Errorf("P2PKH error = %v, want ErrMessageSigningUnsupported", err)
+	}
+	_, err := VerifyMessage(models.ChainBTC, "mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn", []byte("hello"), "AA==", &chaincfg.TestNet3Params)
+	if !errors.Is(err, config.ErrMessageSigningUnsupported) {
+		t.Errorf("P2PKH verify error = %v, want ErrMessageSigningUnsupported", err)
+	}
+}
@@ -0,0 +1,553 @@
+package tx
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/wallet/hd"
+)
+
+// psbtMagic is the "psbt" magic followed by the 0xff separator.
+var psbtMagic = []byte{0x70, 0x73, 0x62, 0x74, 0xff}
+
+// ParseFingerprint parses an 8 hex character BIP-32 key fingerprint into the
+// little-endian integer PSBT key origins carry.
+func ParseFingerprint(s string) (uint32, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != 4 {
+		return 0, fmt.Errorf("master fingerprint must be 8 hex characters, got %q", s)
+	}
+	return binary.LittleEndian.Uint32(b), nil
+}
+
+// psbtInputFinalized reports whether the input carries its final scriptSig or witness.
+func psbtInputFinalized(in *psbt.PInput) bool {
+	return len(in.FinalScriptSig) > 0 || len(in.FinalScriptWitness) > 0
+}
+
+// needsPrevTx reports whether a PSBT input of addrType must carry its full
+// previous transaction. The P2PKH sighash does not commit to the amount spent,
+// and a P2SH script does not tell a signer whether it wraps a witness program.
+func needsPrevTx(addrType models.BTCAddressType) bool {
+	return addrType == models.BTCAddressP2PKH || addrType == models.BTCAddressP2SHP2WPKH
+}
+
+// --- Key origins ---
+
+// BTCKeyOrigin describes the BTC account of one address type the way PSBT key
+// origins need it: the master key fingerprint (hex, as hd.MasterFingerprint
+// returns it) and the public account key m/purpose'/coin'/account'.
+type BTCKeyOrigin struct {
+	Fingerprint string
+	Account     uint32
+	AccountKey  *hdkeychain.ExtendedKey
+}
+
+// BTCKeyOriginSource resolves the key origin of the BTC account for an address type.
+// Implemented by KeyService (from the seed) and XPubKeyOrigin (from configuration).
+type BTCKeyOriginSource interface {
+	BTCKeyOrigin(addrType models.BTCAddressType) (*BTCKeyOrigin, error)
+}
+
+// XPubKeyOrigin is a BTCKeyOriginSource built from an account xpub and master
+// fingerprint, so PSBTs can be exported without the seed on the machine. It only
+// describes inputs of the address type the xpub was exported for.
+type XPubKeyOrigin struct {
+	addrType models.BTCAddressType
+	origin   BTCKeyOrigin
+}
+
+// NewXPubKeyOrigin parses the account xpub of addrType and checks that it belongs
+// to account.
+func NewXPubKeyOrigin(xpub, fingerprint string, addrType models.BTCAddressType, account uint32, net *chaincfg.Params) (*XPubKeyOrigin, error) {
+	if _, err := ParseFingerprint(fingerprint); err != nil {
+		return nil, err
+	}
+	accountKey, err := hd.ParseBTCAccountXPub(xpub, addrType, net)
+	if err != nil {
+		return nil, err
+	}
+	if got := hd.XPubAccount(accountKey); got != account {
+		return nil, fmt.Errorf("%w: BTC xpub is for account %d, configured account is %d", hd.ErrInvalidXPub, got, account)
+	}
+
+	slog.Info("BTC PSBT key origin configured from xpub",
+		"addressType", addrType,
+		"account", account,
+		"fingerprint", fingerprint,
+	)
+	return &XPubKeyOrigin{
+		addrType: addrType,
+		origin:   BTCKeyOrigin{Fingerprint: fingerprint, Account: account, AccountKey: accountKey},
+	}, nil
+}
+
+// BTCKeyOrigin implements BTCKeyOriginSource.
+func (x *XPubKeyOrigin) BTCKeyOrigin(addrType models.BTCAddressType) (*BTCKeyOrigin, error) {
+	if addrType != x.addrType {
+		return nil, fmt.Errorf("%w: the configured xpub is for %s, input is %s", config.ErrPSBTKeyOriginUnavailable, x.addrType, addrType)
+	}
+	return &x.origin, nil
+}
+
+// --- Creator ---
+
+// NewBTCConsolidationPSBT wraps an unsigned consolidation transaction in a PSBT.
+// Every input gets its BIP-32 key origin, plus the redeem script for nested
+// SegWit and the internal key for Taproot. SegWit inputs carry their witness
+// UTXO; P2PKH and P2SH-P2WPKH inputs carry their full previous transaction from
+// prevTxs (keyed by txid), so a signer can check the amount it signs for.
+func NewBTCConsolidationPSBT(built *BTCBuiltTx, origins BTCKeyOriginSource, prevTxs map[chainhash.Hash]*wire.MsgTx, net *chaincfg.Params) (*psbt.Packet, error) {
+	if len(built.Tx.TxIn) != len(built.UTXOs) {
+		return nil, fmt.Errorf("input count mismatch: tx has %d inputs, got %d UTXOs", len(built.Tx.TxIn), len(built.UTXOs))
+	}
+
+	p, err := psbt.NewFromUnsignedTx(built.Tx.Copy())
+	if err != nil {
+		return nil, fmt.Errorf("create PSBT: %w", err)
+	}
+
+	byType := make(map[models.BTCAddressType]*BTCKeyOrigin)
+	for i, u := range built.UTXOs {
+		addrType := u.AddressType
+		if addrType == "" {
+			addrType = models.BTCAddressP2WPKH
+		}
+
+		origin, ok := byType[addrType]
+		if !ok {
+			if origin, err = origins.BTCKeyOrigin(addrType); err != nil {
+				return nil, fmt.Errorf("key origin for %s inputs: %w", addrType, err)
+			}
+			byType[addrType] = origin
+		}
+
+		var prevTx *wire.MsgTx
+		if needsPrevTx(addrType) {
+			op := built.Tx.TxIn[i].PreviousOutPoint
+			if prevTx = prevTxs[op.Hash]; prevTx == nil {
+				return nil, fmt.Errorf("PSBT input %d (address %s): previous transaction %s of a %s input is missing",
+					i, u.Address, op.Hash, addrType)
+			}
+		}
+
+		in, err := psbtInputFor(u, addrType, origin, prevTx, net)
+		if err != nil {
+			return nil, fmt.Errorf("PSBT input %d (address %s, index %d): %w", i, u.Address, u.AddressIndex, err)
+		}
+		p.Inputs[i] = *in
+	}
+
+	slog.Info("BTC consolidation PSBT created",
+		"inputCount", len(p.Inputs),
+		"outputSats", built.OutputSats,
+		"feeSats", built.FeeSats,
+	)
+	return p, nil
+}
+
+// psbtInputFor builds the input map of one UTXO, refusing if the public key at its
+// index does not control its address or prevTx does not pay it.
+func psbtInputFor(u models.UTXO, addrType models.BTCAddressType, origin *BTCKeyOrigin, prevTx *wire.MsgTx, net *chaincfg.Params) (*psbt.PInput, error) {
+	pkScript, err := PKScriptFromAddress(u.Address, net)
+	if err != nil {
+		return nil, err
+	}
+	fingerprint, err := ParseFingerprint(origin.Fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	path, err := hd.BTCKeyPath(addrType, origin.Account, uint32(u.AddressIndex), net)
+	if err != nil {
+		return nil, err
+	}
+
+	external, err := hd.DeriveExternalParentFromAccount(origin.AccountKey)
+	if err != nil {
+		return nil, fmt.Errorf("derive external chain key: %w", err)
+	}
+	child, err := external.Derive(uint32(u.AddressIndex))
+	if err != nil {
+		return nil, fmt.Errorf("derive child key: %w", err)
+	}
+	pubKey, err := child.ECPubKey()
+	if err != nil {
+		return nil, fmt.Errorf("extract public key: %w", err)
+	}
+
+	derived, err := hd.BTCAddressFromPubKey(pubKey, addrType, net)
+	if err != nil || derived.EncodeAddress() != u.Address {
+		return nil, fmt.Errorf("%w: %s key at index %d does not match address %s",
+			config.ErrKeyDerivation, addrType, u.AddressIndex, u.Address)
+	}
+
+	witnessUTXO := wire.NewTxOut(u.Value, pkScript)
+	if prevTx != nil {
+		if prevTx.TxHash().String() != u.TxID || int(u.Vout) >= len(prevTx.TxOut) ||
+			!psbtTxOutsEqual(prevTx.TxOut[u.Vout], witnessUTXO) {
+			return nil, fmt.Errorf("previous transaction %s does not pay %d sats to %s at output %d",
+				prevTx.TxHash(), u.Value, u.Address, u.Vout)
+		}
+	}
+
+	in := &psbt.PInput{}
+	switch addrType {
+	case models.BTCAddressP2TR:
+		xOnly := schnorr.SerializePubKey(pubKey)
+		in.WitnessUtxo = witnessUTXO
+		in.TaprootInternalKey = xOnly
+		in.TaprootBip32Derivation = []*psbt.TaprootBip32Derivation{{
+			XOnlyPubKey:          xOnly,
+			MasterKeyFingerprint: fingerprint,
+			Bip32Path:            path,
+		}}
+	case models.BTCAddressP2SHP2WPKH:
+		compressed := pubKey.SerializeCompressed()
+		in.WitnessUtxo = witnessUTXO
+		in.NonWitnessUtxo = prevTx
+		in.RedeemScript = hd.P2SHP2WPKHRedeemScript(btcutil.Hash160(compressed))
+		in.Bip32Derivation = []*psbt.Bip32Derivation{{
+			PubKey:               compressed,
+			MasterKeyFingerprint: fingerprint,
+			Bip32Path:            path,
+		}}
+	case models.BTCAddressP2PKH:
+		// No witness UTXO: BIP-174 reserves it for SegWit inputs, and the
+		// finalizer would treat the input as one.
+		in.NonWitnessUtxo = prevTx
+		in.Bip32Derivation = []*psbt.Bip32Derivation{{
+			PubKey:               pubKey.SerializeCompressed(),
+			MasterKeyFingerprint: fingerprint,
+			Bip32Path:            path,
+		}}
+	default:
+		in.WitnessUtxo = witnessUTXO
+		in.Bip32Derivation = []*psbt.Bip32Derivation{{
+			PubKey:               pubKey.SerializeCompressed(),
+			MasterKeyFingerprint: fingerprint,
+			Bip32Path:            path,
+		}}
+	}
+	return in, nil
+}
+
+// psbtTxOutsEqual reports whether two outputs pay the same amount to the same script.
+func psbtTxOutsEqual(a, b *wire.TxOut) bool {
+	return a.Value == b.Value && bytes.Equal(a.PkScript, b.PkScript)
+}
+
+// --- Signer ---
+
+// SignPSBT adds a signature to every unsigned input whose key origin descends from
+// masterKey: an ECDSA partial signature (SIGHASH_ALL) for P2WPKH, P2SH-P2WPKH and
+// P2PKH, or a BIP-86 key-path Schnorr signature (SIGHASH_DEFAULT) for P2TR. P2PKH
+// and P2SH inputs are only signed when they carry the previous transaction, whose
+// output is the amount signed for. Each derived key is checked against the
+// input's script before signing and zeroed afterwards. Inputs of other wallets
+// are left alone. Returns the number of inputs signed.
+func SignPSBT(p *psbt.Packet, masterKey *hdkeychain.ExtendedKey) (int, error) {
+	encoded, err := hd.MasterFingerprint(masterKey)
+	if err != nil {
+		return 0, fmt.Errorf("%w: master fingerprint: %s", config.ErrKeyDerivation, err)
+	}
+	fingerprint, err := ParseFingerprint(encoded)
+	if err != nil {
+		return 0, fmt.Errorf("%w: master fingerprint: %s", config.ErrKeyDerivation, err)
+	}
+
+	prevOuts, err := psbtPrevOutFetcher(p)
+	if err != nil {
+		return 0, err
+	}
+	sigHashes := txscript.NewTxSigHashes(p.UnsignedTx, prevOuts)
+
+	signed := 0
+	for i := range p.Inputs {
+		in := &p.Inputs[i]
+		if psbtInputFinalized(in) {
+			continue
+		}
+
+		pubKey, path, ok := psbtDerivationFor(in, fingerprint)
+		if !ok {
+			slog.Debug("PSBT input has no key of this wallet, skipping", "inputIndex", i)
+			continue
+		}
+
+		prevOut := prevOuts.FetchPrevOutput(p.UnsignedTx.TxIn[i].PreviousOutPoint)
+		if err := signPSBTInput(p.UnsignedTx, sigHashes, i, in, prevOut, masterKey, pubKey, path); err != nil {
+			return signed, fmt.Errorf("sign PSBT input %d: %w", i, err)
+		}
+		signed++
+
+		slog.Debug("PSBT input signed",
+			"inputIndex", i,
+			"scriptClass", txscript.GetScriptClass(prevOut.PkScript).String(),
+			"value", prevOut.Value,
+		)
+	}
+
+	slog.Info("PSBT signed", "inputCount", len(p.Inputs), "signed", signed)
+	return signed, nil
+}
+
+// psbtDerivationFor returns the public key and path of the input's key origin
+// under the master key fingerprint.
+func psbtDerivationFor(in *psbt.PInput, fingerprint uint32) ([]byte, []uint32, bool) {
+	for _, d := range in.TaprootBip32Derivation {
+		if d.MasterKeyFingerprint == fingerprint {
+			return d.XOnlyPubKey, d.Bip32Path, true
+		}
+	}
+	for _, d := range in.Bip32Derivation {
+		if d.MasterKeyFingerprint == fingerprint {
+			return d.PubKey, d.Bip32Path, true
+		}
+	}
+	return nil, nil, false
+}
+
+// signPSBTInput derives the key of one input and records its signature.
+func signPSBTInput(msgTx *wire.MsgTx, sigHashes *txscript.TxSigHashes, i int, in *psbt.PInput, prevOut *wire.TxOut, masterKey *hdkeychain.ExtendedKey, derivedPubKey []byte, path []uint32) error {
+	key := masterKey
+	for _, child := range path {
+		var err error
+		if key, err = key.Derive(child); err != nil {
+			return fmt.Errorf("%w: derive %v: %s", config.ErrKeyDerivation, path, err)
+		}
+	}
+	privKey, err := key.ECPrivKey()
+	if err != nil {
+		return fmt.Errorf("%w: extract private key: %s", config.ErrKeyDerivation, err)
+	}
+	defer privKey.Zero()
+
+	pubKey := privKey.PubKey()
+	compressed := pubKey.SerializeCompressed()
+	if !bytes.Equal(derivedPubKey, compressed) && !bytes.Equal(derivedPubKey, schnorr.SerializePubKey(pubKey)) {
+		return fmt.Errorf("%w: key at %v does not match the PSBT public key", config.ErrKeyDerivation, path)
+	}
+
+	pkScript := prevOut.PkScript
+	switch class := txscript.GetScriptClass(pkScript); class {
+	case txscript.WitnessV0PubKeyHashTy, txscript.PubKeyHashTy, txscript.ScriptHashTy:
+		if in.SighashType != 0 && in.SighashType != txscript.SigHashAll {
+			return fmt.Errorf("%w: sighash type %v requested, only SIGHASH_ALL is signed", config.ErrInvalidPSBT, in.SighashType)
+		}
+		// psbtPrevOutFetcher took prevOut from the previous transaction once its
+		// hash matched the outpoint; without it the amount is only a claim.
+		if class != txscript.WitnessV0PubKeyHashTy && in.NonWitnessUtxo == nil {
+			return fmt.Errorf("%w: %s input without its previous transaction", config.ErrInvalidPSBT, class)
+		}
+
+		var sig []byte
+		switch class {
+		case txscript.WitnessV0PubKeyHashTy:
+			if !bytes.Equal(btcutil.Hash160(compressed), pkScript[2:22]) {
+				return fmt.Errorf("%w: key does not control the P2WPKH output", config.ErrKeyDerivation)
+			}
+			sig, err = txscript.RawTxInWitnessSignature(msgTx, sigHashes, i, prevOut.Value, pkScript, txscript.SigHashAll, privKey)
+		case txscript.ScriptHashTy:
+			redeemScript := hd.P2SHP2WPKHRedeemScript(btcutil.Hash160(compressed))
+			if !bytes.Equal(in.RedeemScript, redeemScript) || !bytes.Equal(btcutil.Hash160(redeemScript), pkScript[2:22]) {
+				return fmt.Errorf("%w: P2SH script does not commit to a P2WPKH redeem script for this key", config.ErrKeyDerivation)
+			}
+			sig, err = txscript.RawTxInWitnessSignature(msgTx, sigHashes, i, prevOut.Value, redeemScript, txscript.SigHashAll, privKey)
+		default:
+			if !bytes.Equal(btcutil.Hash160(compressed), pkScript[3:23]) {
+				return fmt.Errorf("%w: key does not control the P2PKH output", config.ErrKeyDerivation)
+			}
+			sig, err = txscript.RawTxInSignature(msgTx, i, pkScript, txscript.SigHashAll, privKey)
+		}
+		if err != nil {
+			return err
+		}
+		in.PartialSigs = append(in.PartialSigs, &psbt.PartialSig{PubKey: compressed, Signature: sig})
+
+	case txscript.WitnessV1TaprootTy:
+		if in.SighashType != 0 && in.SighashType != txscript.SigHashDefault {
+			return fmt.Errorf("%w: sighash type %v requested, only SIGHASH_DEFAULT is signed", config.ErrInvalidPSBT, in.SighashType)
+		}
+		// BIP-86: the output key is the internal key tweaked with an empty script tree.
+		outputKey := txscript.ComputeTaprootKeyNoScript(pubKey)
+		if !bytes.Equal(schnorr.SerializePubKey(outputKey), pkScript[2:34]) {
+			return fmt.Errorf("%w: key does not control the P2TR output", config.ErrKeyDerivation)
+		}
+		sig, err := txscript.RawTxInTaprootSignature(msgTx, sigHashes, i, prevOut.Value, pkScript, nil, txscript.SigHashDefault, privKey)
+		if err != nil {
+			return err
+		}
+		in.TaprootKeySpendSig = sig
+
+	default:
+		return fmt.Errorf("%w: script class %s", hd.ErrUnsupportedAddressType, class)
+	}
+	return nil
+}
+
+// --- Finalizer / extractor ---
+
+// FinalizePSBT turns the signatures of every input into its final scriptSig and
+// witness; the signing data is stripped as BIP-174 requires. Inputs that are
+// already final are kept. Fails with config.ErrPSBTIncomplete when an input has
+// no usable signature.
+func FinalizePSBT(p *psbt.Packet) error {
+	for i := range p.Inputs {
+		if _, err := psbt.MaybeFinalize(p, i); err != nil {
+			if errors.Is(err, psbt.ErrNotFinalizable) {
+				return fmt.Errorf("%w: input %d has no usable signature", config.ErrPSBTIncomplete, i)
+			}
+			return fmt.Errorf("%w: finalize input %d: %s", config.ErrInvalidPSBT, i, err)
+		}
+	}
+	return nil
+}
+
+// ExtractPSBTTx returns the network-serializable transaction of a finalized PSBT.
+// Every input script is executed against its prevout before the transaction is
+// returned, so a PSBT with a bad or missing signature never reaches a broadcaster.
+func ExtractPSBTTx(p *psbt.Packet) (*wire.MsgTx, error) {
+	for i := range p.Inputs {
+		if !psbtInputFinalized(&p.Inputs[i]) {
+			return nil, fmt.Errorf("%w: input %d is not finalized", config.ErrPSBTIncomplete, i)
+		}
+	}
+
+	prevOuts, err := psbtPrevOutFetcher(p)
+	if err != nil {
+		return nil, err
+	}
+	msgTx, err := psbt.Extract(p)
+	if err != nil {
+		return nil, fmt.Errorf("%w: extract: %s", config.ErrInvalidPSBT, err)
+	}
+
+	sigHashes := txscript.NewTxSigHashes(msgTx, prevOuts)
+	for i, txIn := range msgTx.TxIn {
+		prevOut := prevOuts.FetchPrevOutput(txIn.PreviousOutPoint)
+		vm, err := txscript.NewEngine(prevOut.PkScript, msgTx, i, txscript.StandardVerifyFlags,
+			nil, sigHashes, prevOut.Value, prevOuts)
+		if err != nil {
+			return nil, fmt.Errorf("%w: input %d: %s", config.ErrInvalidPSBT, i, err)
+		}
+		if err := vm.Execute(); err != nil {
+			return nil, fmt.Errorf("%w: input %d signature does not verify: %s", config.ErrInvalidPSBT, i, err)
+		}
+	}
+
+	slog.Info("transaction extracted from PSBT", "txHash", msgTx.TxHash().String(), "inputCount", len(msgTx.TxIn))
+	return msgTx, nil
+}
+
+// psbtPrevOutFetcher maps every input's outpoint to its previous output. The full
+// previous transaction wins when present: its hash must match the outpoint, and a
+// witness UTXO next to it must be the same output.
+func psbtPrevOutFetcher(p *psbt.Packet) (*txscript.MultiPrevOutFetcher, error) {
+	fetcher := txscript.NewMultiPrevOutFetcher(nil)
+	for i, txIn := range p.UnsignedTx.TxIn {
+		in := p.Inputs[i]
+		op := txIn.PreviousOutPoint
+		switch {
+		case in.NonWitnessUtxo != nil:
+			if in.NonWitnessUtxo.TxHash() != op.Hash || int(op.Index) >= len(in.NonWitnessUtxo.TxOut) {
+				return nil, fmt.Errorf("%w: input %d: previous transaction does not match outpoint %s", config.ErrInvalidPSBT, i, op)
+			}
+			prevOut := in.NonWitnessUtxo.TxOut[op.Index]
+			if in.WitnessUtxo != nil && !psbtTxOutsEqual(in.WitnessUtxo, prevOut) {
+				return nil, fmt.Errorf("%w: input %d: witness UTXO contradicts the previous transaction", config.ErrInvalidPSBT, i)
+			}
+			fetcher.AddPrevOut(op, prevOut)
+		case in.WitnessUtxo != nil:
+			fetcher.AddPrevOut(op, in.WitnessUtxo)
+		default:
+			return nil, fmt.Errorf("%w: input %d has no UTXO information", config.ErrInvalidPSBT, i)
+		}
+	}
+	return fetcher, nil
+}
+
+// --- Serialization ---
+
+// DecodePSBT parses a PSBT given either in binary or as base64 text (surrounding
+// whitespace is ignored).
+func DecodePSBT(data []byte) (*psbt.Packet, error) {
+	if bytes.HasPrefix(data, psbtMagic) {
+		return ParsePSBT(data)
+	}
+	raw, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: neither binary nor base64: %s", config.ErrInvalidPSBT, err)
+	}
+	return ParsePSBT(raw)
+}
+
+// ParsePSBT parses the binary BIP-174 encoding of a PSBT, refusing trailing bytes.
+func ParsePSBT(raw []byte) (*psbt.Packet, error) {
+	r := bytes.NewReader(raw)
+	p, err := psbt.NewFromRawBytes(r, false)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", config.ErrInvalidPSBT, err)
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("%w: %d trailing bytes", config.ErrInvalidPSBT, r.Len())
+	}
+	return p, nil
+}
+
+// --- Summary ---
+
+// PSBTSummary is what a signer should show before signing: the amounts moved and
+// where they go.
+type PSBTSummary struct {
+	InputCount     int
+	TotalInputSats int64
+	Outputs        []PSBTSummaryOutput
+	FeeSats        int64
+}
+
+// PSBTSummaryOutput is one output of a PSBTSummary.
+type PSBTSummaryOutput struct {
+	Address string // "" when the script has no standard address
+	Value   int64
+}
+
+// SummarizePSBT totals the inputs and decodes the outputs of a PSBT.
+func SummarizePSBT(p *psbt.Packet, net *chaincfg.Params) (*PSBTSummary, error) {
+	prevOuts, err := psbtPrevOutFetcher(p)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &PSBTSummary{InputCount: len(p.Inputs)}
+	for _, txIn := range p.UnsignedTx.TxIn {
+		s.TotalInputSats += prevOuts.FetchPrevOutput(txIn.PreviousOutPoint).Value
+	}
+
+	var totalOut int64
+	for _, out := range p.UnsignedTx.TxOut {
+		address, _ := scriptAddress(out.PkScript, net)
+		s.Outputs = append(s.Outputs, PSBTSummaryOutput{Address: address, Value: out.Value})
+		totalOut += out.Value
+	}
+	s.FeeSats = s.TotalInputSats - totalOut
+	if s.FeeSats < 0 {
+		return nil, fmt.Errorf("%w: outputs (%d sats) exceed inputs (%d sats)", config.ErrInvalidPSBT, totalOut, s.TotalInputSats)
+	}
+	return s, nil
+}
@@ -0,0 +1,481 @@
+package tx
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/wallet/hd"
+)
+
+// psbtTestFundingTx returns a deterministic transaction whose output vout pays
+// value to address, standing in for the previous transaction of a test UTXO.
+func psbtTestFundingTx(t *testing.T, address string, vout uint32, value int64) *wire.MsgTx {
+	t.Helper()
+	pkScript, err := PKScriptFromAddress(address, &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgTx := wire.NewMsgTx(2)
+	msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, vout), nil, nil))
+	for i := uint32(0); i <= vout; i++ {
+		msgTx.AddTxOut(wire.NewTxOut(value, pkScript))
+	}
+	return msgTx
+}
+
+// psbtTestPrevTxs returns the previous transactions of every input of a
+// psbtTestConsolidation, keyed by txid.
+func psbtTestPrevTxs(t *testing.T, built *BTCBuiltTx) map[chainhash.Hash]*wire.MsgTx {
+	t.Helper()
+	prevTxs := make(map[chainhash.Hash]*wire.MsgTx)
+	for _, u := range built.UTXOs {
+		prevTx := psbtTestFundingTx(t, u.Address, u.Vout, u.Value)
+		prevTxs[prevTx.TxHash()] = prevTx
+	}
+	return prevTxs
+}
+
+// psbtTestConsolidation builds an unsigned testnet consolidation with one input of
+// every supported address type, all from testMnemonic24 account 0.
+func psbtTestConsolidation(t *testing.T) *BTCBuiltTx {
+	t.Helper()
+	net := &chaincfg.TestNet3Params
+	seed, err := hd.MnemonicToSeed(testMnemonic24)
+	if err != nil {
+		t.Fatal(err)
+	}
+	masterKey, err := hd.DeriveMasterKey(seed, net)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var utxos []models.UTXO
+	for i, addrType := range models.AllBTCAddressTypes {
+		parent, err := hd.DeriveBTCAccountParentKey(masterKey, addrType, 0, net)
+		if err != nil {
+			t.Fatal(err)
+		}
+		addr, err := hd.DeriveBTCTypedAddressFromParent(parent, addrType, uint32(i), net)
+		if err != nil {
+			t.Fatal(err)
+		}
+		utxos = append(utxos, models.UTXO{
+			TxID:         psbtTestFundingTx(t, addr, uint32(i), 40000).TxHash().String(),
+			Vout:         uint32(i),
+			Value:        40000,
+			Address:      addr,
+			AddressIndex: i,
+		})
+	}
+	if err := ClassifyUTXOs(utxos, net); err != nil {
+		t.Fatal(err)
+	}
+
+	built, err := BuildBTCConsolidationTx(BTCBuildParams{
+		UTXOs:       utxos,
+		DestAddress: utxos[0].Address,
+		FeeRate:     5,
+		NetParams:   net,
+	})
+	if err != nil {
+		t.Fatalf("BuildBTCConsolidationTx() error = %v", err)
+	}
+	return built
+}
+
+// roundTripPSBT encodes a PSBT as base64 and decodes it again.
+func roundTripPSBT(t *testing.T, p *psbt.Packet) *psbt.Packet {
+	t.Helper()
+	encoded, err := p.B64Encode()
+	if err != nil {
+		t.Fatalf("B64Encode() error = %v", err)
+	}
+	decoded, err := DecodePSBT([]byte(encoded))
+	if err != nil {
+		t.Fatalf("DecodePSBT() error = %v", err)
+	}
+	return decoded
+}
+
+// testSeed returns the BIP-39 seed of a test mnemonic.
+func testSeed(t *testing.T, mnemonic string) []byte {
+	t.Helper()
+	seed, err := hd.MnemonicToSeed(mnemonic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return seed
+}
+
+func TestPSBT_CreateSignFinalizeExtract(t *testing.T) {
+	net := &chaincfg.TestNet3Params
+	built := psbtTestConsolidation(t)
+	ks := NewKeyService(writeTempMnemonic(t, testMnemonic24), "testnet")
+
+	p, err := NewBTCConsolidationPSBT(built, ks, psbtTestPrevTxs(t, built), net)
+	if err != nil {
+		t.Fatalf("NewBTCConsolidationPSBT() error = %v", err)
+	}
+	p = roundTripPSBT(t, p)
+
+	for i, in := range p.Inputs {
+		addrType := built.UTXOs[i].AddressType
+		if wantWitness := addrType != models.BTCAddressP2PKH; (in.WitnessUtxo != nil) != wantWitness {
+			t.Errorf("%s input has witness UTXO = %v, want %v", addrType, in.WitnessUtxo != nil, wantWitness)
+		}
+		if wantPrevTx := needsPrevTx(addrType); (in.NonWitnessUtxo != nil) != wantPrevTx {
+			t.Errorf("%s input has previous transaction = %v, want %v", addrType, in.NonWitnessUtxo != nil, wantPrevTx)
+		}
+		if len(in.Bip32Derivation)+len(in.TaprootBip32Derivation) != 1 {
+			t.Errorf("input %d has %d key origins, want 1", i, len(in.Bip32Derivation)+len(in.TaprootBip32Derivation))
+		}
+	}
+
+	summary, err := SummarizePSBT(p, net)
+	if err != nil {
+		t.Fatalf("SummarizePSBT() error = %v", err)
+	}
+	if summary.FeeSats != built.FeeSats || len(summary.Outputs) != 1 || summary.Outputs[0].Address != built.UTXOs[0].Address {
+		t.Errorf("SummarizePSBT() = %+v, want fee %d to %s", summary, built.FeeSats, built.UTXOs[0].Address)
+	}
+
+	// Nothing is final before signing.
+	if err := FinalizePSBT(roundTripPSBT(t, p)); !errors.Is(err, config.ErrPSBTIncomplete) {
+		t.Errorf("FinalizePSBT(unsigned) error = %v, want ErrPSBTIncomplete", err)
+	}
+
+	masterKey, err := hd.DeriveMasterKey(testSeed(t, testMnemonic24), net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := SignPSBT(p, masterKey)
+	if err != nil {
+		t.Fatalf("SignPSBT() error = %v", err)
+	}
+	if signed != len(built.UTXOs) {
+		t.Errorf("SignPSBT() signed %d inputs, want %d", signed, len(built.UTXOs))
+	}
+	p = roundTripPSBT(t, p)
+
+	if err := FinalizePSBT(p); err != nil {
+		t.Fatalf("FinalizePSBT() error = %v", err)
+	}
+	for i := range p.Inputs {
+		if !psbtInputFinalized(&p.Inputs[i]) {
+			t.Errorf("input %d not finalized", i)
+		}
+	}
+
+	signedTx, err := ExtractPSBTTx(roundTripPSBT(t, p))
+	if err != nil {
+		t.Fatalf("ExtractPSBTTx() error = %v", err)
+	}
+	// The fixture has P2PKH and P2SH-P2WPKH inputs, whose scriptSigs are part of
+	// the txid, so the extracted transaction is compared field by field.
+	if signedTx.Version != built.Tx.Version || signedTx.LockTime != built.Tx.LockTime {
+		t.Errorf("extracted version/locktime = %d/%d, want %d/%d",
+			signedTx.Version, signedTx.LockTime, built.Tx.Version, built.Tx.LockTime)
+	}
+	if len(signedTx.TxIn) != len(built.Tx.TxIn) || len(signedTx.TxOut) != len(built.Tx.TxOut) {
+		t.Fatalf("extracted %d inputs / %d outputs, want %d / %d",
+			len(signedTx.TxIn), len(signedTx.TxOut), len(built.Tx.TxIn), len(built.Tx.TxOut))
+	}
+	for i, in := range signedTx.TxIn {
+		want := built.Tx.TxIn[i]
+		if in.PreviousOutPoint != want.PreviousOutPoint || in.Sequence != want.Sequence {
+			t.Errorf("input %d = %s seq %d, want %s seq %d", i, in.PreviousOutPoint, in.Sequence, want.PreviousOutPoint, want.Sequence)
+		}
+		if len(in.SignatureScript) == 0 && len(in.Witness) == 0 {
+			t.Errorf("input %d carries no signature", i)
+		}
+	}
+	for i, out := range signedTx.TxOut {
+		want := built.Tx.TxOut[i]
+		if out.Value != want.Value || !bytes.Equal(out.PkScript, want.PkScript) {
+			t.Errorf("output %d = %d to %x, want %d to %x", i, out.Value, out.PkScript, want.Value, want.PkScript)
+		}
+	}
+}
+
+func TestSignPSBT_OtherSeedSignsNothing(t *testing.T) {
+	net := &chaincfg.TestNet3Params
+	built := psbtTestConsolidation(t)
+	ks := NewKeyService(writeTempMnemonic(t, testMnemonic24), "testnet")
+
+	p, err := NewBTCConsolidationPSBT(built, ks, psbtTestPrevTxs(t, built), net)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherKey, err := hd.DeriveMasterKey(testSeed(t, testMnemonic12), net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := SignPSBT(p, otherKey)
+	if err != nil {
+		t.Fatalf("SignPSBT() error = %v", err)
+	}
+	if signed != 0 {
+		t.Errorf("SignPSBT() with another seed signed %d inputs, want 0", signed)
+	}
+	if _, err := ExtractPSBTTx(p); !errors.Is(err, config.ErrPSBTIncomplete) {
+		t.Errorf("ExtractPSBTTx() error = %v, want ErrPSBTIncomplete", err)
+	}
+}
+
+func TestNewBTCConsolidationPSBT_WrongIndex(t *testing.T) {
+	built := psbtTestConsolidation(t)
+	built.UTXOs[2].AddressIndex = 7
+	ks := NewKeyService(writeTempMnemonic(t, testMnemonic24), "testnet")
+
+	if _, err := NewBTCConsolidationPSBT(built, ks, psbtTestPrevTxs(t, built), &chaincfg.TestNet3Params); !errors.Is(err, config.ErrKeyDerivation) {
+		t.Errorf("NewBTCConsolidationPSBT() error = %v, want ErrKeyDerivation", err)
+	}
+}
+
+func TestNewBTCConsolidationPSBT_PrevTxs(t *testing.T) {
+	net := &chaincfg.TestNet3Params
+	built := psbtTestConsolidation(t)
+	ks := NewKeyService(writeTempMnemonic(t, testMnemonic24), "testnet")
+
+	if _, err := NewBTCConsolidationPSBT(built, ks, nil, net); err == nil {
+		t.Error("NewBTCConsolidationPSBT() without previous transactions succeeded")
+	}
+
+	// A previous transaction paying another amount is refused.
+	prevTxs := psbtTestPrevTxs(t, built)
+	for _, prevTx := range prevTxs {
+		for _, out := range prevTx.TxOut {
+			out.Value++
+		}
+	}
+	if _, err := NewBTCConsolidationPSBT(built, ks, prevTxs, net); err == nil {
+		t.Error("NewBTCConsolidationPSBT() accepted previous transactions that do not match the UTXOs")
+	}
+}
+
+func TestSignPSBT_LegacyInputNeedsPrevTx(t *testing.T) {
+	net := &chaincfg.TestNet3Params
+	built := psbtTestConsolidation(t)
+	ks := NewKeyService(writeTempMnemonic(t, testMnemonic24), "testnet")
+	masterKey, err := hd.DeriveMasterKey(testSeed(t, testMnemonic24), net)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	legacy := -1
+	for i, u := range built.UTXOs {
+		if u.AddressType == models.BTCAddressP2PKH {
+			legacy = i
+		}
+	}
+	if legacy < 0 {
+		t.Fatal("fixture has no P2PKH input")
+	}
+	newPSBT := func() *psbt.Packet {
+		p, err := NewBTCConsolidationPSBT(built, ks, psbtTestPrevTxs(t, built), net)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	// The legacy sighash does not commit to the amount: a bare, inflated witness
+	// UTXO in place of the previous transaction must not be signed.
+	p := newPSBT()
+	in := &p.Inputs[legacy]
+	prevOut := in.NonWitnessUtxo.TxOut[built.UTXOs[legacy].Vout]
+	in.WitnessUtxo = wire.NewTxOut(prevOut.Value*10, prevOut.PkScript)
+	in.NonWitnessUtxo = nil
+	if _, err := SignPSBT(p, masterKey); !errors.Is(err, config.ErrInvalidPSBT) {
+		t.Errorf("SignPSBT(P2PKH without previous transaction) error = %v, want ErrInvalidPSBT", err)
+	}
+
+	// An edited previous transaction no longer hashes to the outpoint.
+	p = newPSBT()
+	p.Inputs[legacy].NonWitnessUtxo.TxOut[built.UTXOs[legacy].Vout].Value *= 10
+	if _, err := SignPSBT(p, masterKey); !errors.Is(err, config.ErrInvalidPSBT) {
+		t.Errorf("SignPSBT(edited previous transaction) error = %v, want ErrInvalidPSBT", err)
+	}
+	if _, err := SummarizePSBT(p, net); !errors.Is(err, config.ErrInvalidPSBT) {
+		t.Errorf("SummarizePSBT(edited previous transaction) error = %v, want ErrInvalidPSBT", err)
+	}
+}
+
+func TestXPubKeyOrigin(t *testing.T) {
+	net := &chaincfg.TestNet3Params
+	ks := NewKeyService(writeTempMnemonic(t, testMnemonic24), "testnet")
+	want, err := ks.BTCKeyOrigin(models.BTCAddressP2WPKH)
+	if err != nil {
+		t.Fatalf("BTCKeyOrigin() error = %v", err)
+	}
+	fingerprint := want.Fingerprint
+
+	origin, err := NewXPubKeyOrigin(want.AccountKey.String(), fingerprint, models.BTCAddressP2WPKH, 0, net)
+	if err != nil {
+		t.Fatalf("NewXPubKeyOrigin() error = %v", err)
+	}
+	got, err := origin.BTCKeyOrigin(models.BTCAddressP2WPKH)
+	if err != nil {
+		t.Fatalf("XPubKeyOrigin.BTCKeyOrigin() error = %v", err)
+	}
+	if got.Fingerprint != want.Fingerprint || got.AccountKey.String() != want.AccountKey.String() {
+		t.Errorf("XPubKeyOrigin = %s %s, want %s %s", got.Fingerprint, got.AccountKey, want.Fingerprint, want.AccountKey)
+	}
+
+	if _, err := origin.BTCKeyOrigin(models.BTCAddressP2TR); !errors.Is(err, config.ErrPSBTKeyOriginUnavailable) {
+		t.Errorf("BTCKeyOrigin(p2tr) error = %v, want ErrPSBTKeyOriginUnavailable", err)
+	}
+	if _, err := NewXPubKeyOrigin(want.AccountKey.String(), fingerprint, models.BTCAddressP2WPKH, 1, net); !errors.Is(err, hd.ErrInvalidXPub) {
+		t.Errorf("NewXPubKeyOrigin(account 1) error = %v, want ErrInvalidXPub", err)
+	}
+	if _, err := NewXPubKeyOrigin(want.AccountKey.String(), "xyz", models.BTCAddressP2WPKH, 0, net); err == nil {
+		t.Error("NewXPubKeyOrigin() accepted a malformed fingerprint")
+	}
+}
+
+func TestDecodePSBT_Rejects(t *testing.T) {
+	built := psbtTestConsolidation(t)
+	ks := NewKeyService(writeTempMnemonic(t, testMnemonic24), "testnet")
+	p, err := NewBTCConsolidationPSBT(built, ks, psbtTestPrevTxs(t, built), &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := p.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	raw := buf.Bytes()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"not base64", []byte("not a psbt!")},
+		{"wrong magic", append([]byte("psbx\xff"), raw[5:]...)},
+		{"truncated", raw[:len(raw)-3]},
+		{"trailing bytes", append(append([]byte{}, raw...), 0x00, 0x00)},
+		{"base64 trailing bytes", []byte(base64.StdEncoding.EncodeToString(append(append([]byte{}, raw...), 0x01)))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodePSBT(tt.data); !errors.Is(err, config.ErrInvalidPSBT) {
+				t.Errorf("DecodePSBT() error = %v, want ErrInvalidPSBT", err)
+			}
+		})
+	}
+
+	// Binary and base64 forms decode to the same packet.
+	fromBinary, err := DecodePSBT(raw)
+	if err != nil {
+		t.Fatalf("DecodePSBT(binary) error = %v", err)
+	}
+	var again bytes.Buffer
+	if err := fromBinary.Serialize(&again); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again.Bytes(), raw) {
+		t.Error("binary PSBT did not round-trip byte for byte")
+	}
+}
+
+// recordingBroadcaster records raw transactions instead of sending them.
+type recordingBroadcaster struct {
+	mu   sync.Mutex
+	sent []string
+}
+
+func (b *recordingBroadcaster) Broadcast(_ context.Context, rawHex string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sent = append(b.sent, rawHex)
+	return "psbt-txhash", nil
+}
+
+func TestBTCConsolidationService_BroadcastPSBT(t *testing.T) {
+	net := &chaincfg.TestNet3Params
+	database := setupGasTestDB(t)
+	built := psbtTestConsolidation(t)
+	ks := NewKeyService(writeTempMnemonic(t, testMnemonic24), "testnet")
+
+	// The confirmation poller reports the transaction confirmed right away.
+	status := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"confirmed":true}`))
+	}))
+	defer status.Close()
+
+	broadcaster := &recordingBroadcaster{}
+	svc := NewBTCConsolidationService(ks, nil, nil, broadcaster, database, net, status.Client(), []string{status.URL}, nil)
+
+	newSignedPSBT := func() *psbt.Packet {
+		p, err := NewBTCConsolidationPSBT(built, ks, psbtTestPrevTxs(t, built), net)
+		if err != nil {
+			t.Fatal(err)
+		}
+		masterKey, err := hd.DeriveMasterKey(testSeed(t, testMnemonic24), net)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := SignPSBT(p, masterKey); err != nil {
+			t.Fatal(err)
+		}
+		return roundTripPSBT(t, p)
+	}
+
+	// Inputs must be stored addresses: nothing is stored yet.
+	if _, err := svc.BroadcastPSBT(context.Background(), newSignedPSBT(), "sweep-foreign"); !errors.Is(err, config.ErrPSBTForeignInput) {
+		t.Fatalf("BroadcastPSBT() before storing addresses error = %v, want ErrPSBTForeignInput", err)
+	}
+	if rows, _ := database.GetTxStatesBySweepID("sweep-foreign"); len(rows) != 0 {
+		t.Errorf("rejected PSBT wrote %d tx_state rows", len(rows))
+	}
+
+	stored := make([]models.Address, len(built.UTXOs))
+	for i, u := range built.UTXOs {
+		stored[i] = models.Address{Chain: models.ChainBTC, AddressIndex: u.AddressIndex, Address: u.Address}
+	}
+	if err := database.InsertAddressBatch(models.ChainBTC, stored); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := svc.BroadcastPSBT(context.Background(), newSignedPSBT(), "sweep-psbt")
+	if err != nil {
+		t.Fatalf("BroadcastPSBT() error = %v", err)
+	}
+	if result.TxHash != "psbt-txhash" || result.InputCount != len(built.UTXOs) ||
+		result.OutputSats != built.OutputSats || result.FeeSats != built.FeeSats {
+		t.Errorf("BroadcastPSBT() = %+v, want %d inputs, %d out, %d fee", result, len(built.UTXOs), built.OutputSats, built.FeeSats)
+	}
+	if len(broadcaster.sent) != 1 {
+		t.Fatalf("broadcast %d transactions, want 1", len(broadcaster.sent))
+	}
+
+	rows, err := database.GetTxStatesBySweepID("sweep-psbt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].ToAddress != built.UTXOs[0].Address {
+		t.Errorf("tx_state rows = %+v, want one row to %s", rows, built.UTXOs[0].Address)
+	}
+	recorded, err := database.GetTransactionByHash(models.ChainBTC, "psbt-txhash")
+	if err != nil {
+		t.Fatalf("GetTransactionByHash() error = %v", err)
+	}
+	if recorded.Direction != "send" {
+		t.Errorf("recorded direction = %q, want send", recorded.Direction)
+	}
+}
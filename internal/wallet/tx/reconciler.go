@@ -280,6 +280,17 @@ func (r *TxReconciler) updateBothTables(txState db.TxStateRow, status string) {
 			)
 		}
 	}
+
+	// BTC payouts keep their status in btc_payouts rather than transactions.
+	if txState.FromAddress == config.FromAddressBTCPayout && txState.TxHash != "" {
+		if err := r.database.UpdateBTCPayoutStatus(txState.TxHash, status); err != nil {
+			slog.Error("tx reconciler: failed to update btc_payouts",
+				"txHash", txState.TxHash,
+				"status", status,
+				"error", err,
+			)
+		}
+	}
 }
 
 // launchPolling starts a background confirmation poller for a still-pending transaction.
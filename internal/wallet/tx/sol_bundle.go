@@ -0,0 +1,732 @@
+package tx
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/mr-tron/base58"
+
+	"github.com/Fantasim/hdpay/internal/shared/config"
+	"github.com/Fantasim/hdpay/internal/shared/models"
+	"github.com/Fantasim/hdpay/internal/shared/scanner"
+	"github.com/Fantasim/hdpay/internal/wallet/db"
+	"github.com/Fantasim/hdpay/internal/wallet/hd"
+)
+
+// solBundleNonce is a durable nonce account ready to back one bundle transaction.
+type solBundleNonce struct {
+	account        SolPublicKey
+	nonce          [32]byte
+	authority      SolPublicKey
+	authorityIndex int
+}
+
+// solNoncePool hands out the configured nonce accounts in order, one per
+// exported transaction.
+type solNoncePool struct {
+	s      *SOLConsolidationService
+	next   int
+	peeked *solBundleNonce
+}
+
+// peek returns the next unused nonce account without consuming it.
+func (p *solNoncePool) peek(ctx context.Context) (*solBundleNonce, error) {
+	if p.peeked != nil {
+		return p.peeked, nil
+	}
+	if p.next >= len(p.s.nonceAccounts) {
+		return nil, fmt.Errorf("%w: all %d configured accounts are used by this bundle (set HDPAY_SOL_NONCE_ACCOUNTS)",
+			config.ErrSOLNonceAccountsShort, len(p.s.nonceAccounts))
+	}
+
+	address := p.s.nonceAccounts[p.next]
+	account, err := SolPublicKeyFromBase58(address)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %s", config.ErrSOLNonceAccount, address, err)
+	}
+	nonce, authority, err := p.s.rpcClient.GetNonceAccount(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	if p.s.database == nil {
+		return nil, fmt.Errorf("%w: database not configured", config.ErrSOLNonceAccount)
+	}
+	stored, err := p.s.database.LookupAddress(models.ChainSOL, authority.ToBase58())
+	if err != nil {
+		return nil, fmt.Errorf("%w: authority %s of %s is not a stored address: %s",
+			config.ErrSOLNonceAccount, authority.ToBase58(), address, err)
+	}
+
+	p.peeked = &solBundleNonce{
+		account:        account,
+		nonce:          nonce,
+		authority:      authority,
+		authorityIndex: stored.AddressIndex,
+	}
+	return p.peeked, nil
+}
+
+// take consumes the nonce account returned by the last peek.
+func (p *solNoncePool) take() {
+	p.next++
+	p.peeked = nil
+}
+
+// ExportNativeBundle builds an unsigned SOL sweep bundle: for every address whose
+// live balance covers the fee, a transfer of balance minus fee, built on its own
+// durable nonce account so the bundle stays valid while it is signed offline.
+func (s *SOLConsolidationService) ExportNativeBundle(
+	ctx context.Context,
+	addresses []models.AddressWithBalance,
+	destAddress string,
+) (*models.SweepBundle, error) {
+	slog.Info("SOL native bundle export",
+		"addressCount", len(addresses),
+		"destAddress", destAddress,
+		"nonceAccounts", len(s.nonceAccounts),
+	)
+
+	destPubKey, err := SolPublicKeyFromBase58(destAddress)
+	if err != nil {
+		return nil, fmt.Errorf("parse destination address: %w", err)
+	}
+
+	bundle := newSweepBundle(models.ChainSOL, models.TokenNative, s.network, s.keyService.Account(), destAddress, "")
+	pool := &solNoncePool{s: s}
+
+	for _, addr := range addresses {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		fromPubKey, err := SolPublicKeyFromBase58(addr.Address)
+		if err != nil {
+			return nil, fmt.Errorf("parse address %s: %w", addr.Address, err)
+		}
+		balance, err := s.rpcClient.GetBalance(ctx, addr.Address)
+		if err != nil {
+			return nil, fmt.Errorf("get balance for %s: %w", addr.Address, err)
+		}
+		if balance <= 2*config.SOLBaseTransactionFee {
+			slog.Debug("SOL bundle: skipping address with balance too low to sweep",
+				"address", addr.Address,
+				"balance", balance,
+			)
+			continue
+		}
+
+		n, err := pool.peek(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		signerIndexes := map[SolPublicKey]int{
+			fromPubKey:  addr.AddressIndex,
+			n.authority: n.authorityIndex,
+		}
+		fee := uint64(len(signerIndexes)) * config.SOLBaseTransactionFee
+		sendAmount := balance - fee
+
+		instructions := []SolInstruction{
+			BuildAdvanceNonceInstruction(n.account, n.authority),
+			BuildSystemTransferInstruction(fromPubKey, destPubKey, sendAmount),
+		}
+		entry, err := solBundleEntry(addr, sendAmount, fromPubKey, instructions, n, signerIndexes)
+		if err != nil {
+			return nil, err
+		}
+		bundle.Transactions = append(bundle.Transactions, entry)
+		pool.take()
+	}
+
+	if len(bundle.Transactions) == 0 {
+		return nil, config.ErrBundleEmpty
+	}
+
+	slog.Info("SOL native bundle exported", "txCount", len(bundle.Transactions))
+	return bundle, nil
+}
+
+// ExportTokenBundle builds an unsigned SPL token sweep bundle on durable nonces.
+// The first transaction creates the destination ATA if it does not exist yet.
+// When feePayerIndex is non-nil, that address pays every fee, as in ExecuteTokenSweep.
+func (s *SOLConsolidationService) ExportTokenBundle(
+	ctx context.Context,
+	addresses []models.AddressWithBalance,
+	destAddress string,
+	token models.Token,
+	mint string,
+	feePayerIndex *int,
+) (*models.SweepBundle, error) {
+	slog.Info("SOL token bundle export",
+		"addressCount", len(addresses),
+		"destAddress", destAddress,
+		"token", token,
+		"mint", mint,
+		"feePayerIndex", feePayerIndex,
+		"nonceAccounts", len(s.nonceAccounts),
+	)
+
+	mintPubKey, err := SolPublicKeyFromBase58(mint)
+	if err != nil {
+		return nil, fmt.Errorf("parse mint address: %w", err)
+	}
+	destPubKey, err := SolPublicKeyFromBase58(destAddress)
+	if err != nil {
+		return nil, fmt.Errorf("parse destination address: %w", err)
+	}
+	destATAStr, err := scanner.DeriveATA(destAddress, mint)
+	if err != nil {
+		return nil, fmt.Errorf("derive destination ATA: %w", err)
+	}
+	destATAPubKey, err := SolPublicKeyFromBase58(destATAStr)
+	if err != nil {
+		return nil, fmt.Errorf("parse destination ATA: %w", err)
+	}
+	destATAExists, _, err := s.rpcClient.GetAccountInfo(ctx, destATAStr)
+	if err != nil {
+		return nil, fmt.Errorf("check destination ATA: %w", err)
+	}
+
+	var feePayer *models.Address
+	if feePayerIndex != nil {
+		if s.database == nil {
+			return nil, fmt.Errorf("fee payer index %d: database not configured", *feePayerIndex)
+		}
+		feePayer, err = s.database.GetAddressByIndex(models.ChainSOL, *feePayerIndex)
+		if err != nil {
+			return nil, fmt.Errorf("fee payer index %d: %w", *feePayerIndex, err)
+		}
+	}
+
+	bundle := newSweepBundle(models.ChainSOL, token, s.network, s.keyService.Account(), destAddress, mint)
+	pool := &solNoncePool{s: s}
+	var feePayerTotal uint64
+
+	for _, addr := range addresses {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		tokenBal := findTokenBalance(addr, token)
+		if tokenBal == 0 {
+			continue
+		}
+
+		fromPubKey, err := SolPublicKeyFromBase58(addr.Address)
+		if err != nil {
+			return nil, fmt.Errorf("parse address %s: %w", addr.Address, err)
+		}
+		sourceATAStr, err := scanner.DeriveATA(addr.Address, mint)
+		if err != nil {
+			return nil, fmt.Errorf("derive source ATA for %s: %w", addr.Address, err)
+		}
+		sourceATAPubKey, err := SolPublicKeyFromBase58(sourceATAStr)
+		if err != nil {
+			return nil, fmt.Errorf("parse source ATA: %w", err)
+		}
+
+		n, err := pool.peek(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		effectiveFeePayer := fromPubKey
+		signerIndexes := map[SolPublicKey]int{
+			fromPubKey:  addr.AddressIndex,
+			n.authority: n.authorityIndex,
+		}
+		if feePayer != nil {
+			effectiveFeePayer, err = SolPublicKeyFromBase58(feePayer.Address)
+			if err != nil {
+				return nil, fmt.Errorf("parse fee payer address: %w", err)
+			}
+			signerIndexes[effectiveFeePayer] = feePayer.AddressIndex
+		}
+
+		cost := uint64(len(signerIndexes)) * config.SOLBaseTransactionFee
+		if !destATAExists {
+			cost += config.SOLATARentLamports
+		}
+
+		// Without an external fee payer, each holder pays its own fee.
+		if feePayer == nil {
+			nativeBal, err := s.rpcClient.GetBalance(ctx, addr.Address)
+			if err != nil {
+				return nil, fmt.Errorf("get balance for %s: %w", addr.Address, err)
+			}
+			if nativeBal < cost {
+				slog.Warn("SOL bundle: skipping address with insufficient SOL for fee",
+					"address", addr.Address,
+					"balance", nativeBal,
+					"required", cost,
+				)
+				continue
+			}
+		}
+
+		instructions := []SolInstruction{BuildAdvanceNonceInstruction(n.account, n.authority)}
+		if !destATAExists {
+			instructions = append(instructions, BuildCreateATAInstruction(effectiveFeePayer, destATAPubKey, destPubKey, mintPubKey))
+		}
+		instructions = append(instructions, BuildSPLTransferInstruction(sourceATAPubKey, destATAPubKey, fromPubKey, tokenBal))
+
+		entry, err := solBundleEntry(addr, tokenBal, effectiveFeePayer, instructions, n, signerIndexes)
+		if err != nil {
+			return nil, err
+		}
+		bundle.Transactions = append(bundle.Transactions, entry)
+		pool.take()
+		feePayerTotal += cost
+		destATAExists = true
+	}
+
+	if len(bundle.Transactions) == 0 {
+		return nil, config.ErrBundleEmpty
+	}
+
+	if feePayer != nil {
+		fpBalance, err := s.rpcClient.GetBalance(ctx, feePayer.Address)
+		if err != nil {
+			return nil, fmt.Errorf("get fee payer balance: %w", err)
+		}
+		if fpBalance < feePayerTotal {
+			return nil, fmt.Errorf("fee payer has insufficient SOL: have %d lamports, need %d lamports", fpBalance, feePayerTotal)
+		}
+	}
+
+	slog.Info("SOL token bundle exported",
+		"token", token,
+		"txCount", len(bundle.Transactions),
+	)
+	return bundle, nil
+}
+
+// solBundleEntry compiles instructions on a durable nonce into a bundle
+// transaction, recording the address index of every required signer.
+func solBundleEntry(
+	addr models.AddressWithBalance,
+	amount uint64,
+	feePayer SolPublicKey,
+	instructions []SolInstruction,
+	n *solBundleNonce,
+	signerIndexes map[SolPublicKey]int,
+) (models.SweepBundleTx, error) {
+	msg, err := CompileMessage(feePayer, instructions, n.nonce)
+	if err != nil {
+		return models.SweepBundleTx{}, fmt.Errorf("compile message for %s: %w", addr.Address, err)
+	}
+	msgBytes, err := SerializeMessage(msg)
+	if err != nil {
+		return models.SweepBundleTx{}, fmt.Errorf("serialize message for %s: %w", addr.Address, err)
+	}
+
+	numSigs := int(msg.Header.NumRequiredSignatures)
+	if size := 1 + numSigs*64 + len(msgBytes); size > config.SOLMaxTxSize {
+		return models.SweepBundleTx{}, fmt.Errorf("%w: %d bytes (max %d)", config.ErrSOLTxTooLarge, size, config.SOLMaxTxSize)
+	}
+
+	indexes := make([]int, numSigs)
+	for i, key := range msg.AccountKeys[:numSigs] {
+		idx, ok := signerIndexes[key]
+		if !ok {
+			return models.SweepBundleTx{}, fmt.Errorf("no address index for signer %s", key.ToBase58())
+		}
+		indexes[i] = idx
+	}
+
+	return models.SweepBundleTx{
+		AddressIndex:  addr.AddressIndex,
+		FromAddress:   addr.Address,
+		Amount:        strconv.FormatUint(amount, 10),
+		Message:       base64.StdEncoding.EncodeToString(msgBytes),
+		NonceAccount:  n.account.ToBase58(),
+		SignerIndexes: indexes,
+	}, nil
+}
+
+// solBundleMessage decodes the message of a bundle transaction and checks that
+// it advances the transaction's nonce account and then only transfers Amount
+// from the sender to the bundle destination (creating the destination ATA
+// first is allowed for token bundles). Anything else is rejected.
+func solBundleMessage(bundle *models.SweepBundle, entry models.SweepBundleTx) (SolMessage, []byte, error) {
+	invalid := func(format string, args ...interface{}) (SolMessage, []byte, error) {
+		return SolMessage{}, nil, fmt.Errorf("%w: transaction from %s: %s",
+			config.ErrInvalidBundle, entry.FromAddress, fmt.Sprintf(format, args...))
+	}
+
+	msgBytes, err := base64.StdEncoding.DecodeString(entry.Message)
+	if err != nil {
+		return invalid("decode message: %s", err)
+	}
+	msg, err := DeserializeMessage(msgBytes)
+	if err != nil {
+		return invalid("parse message: %s", err)
+	}
+	if int(msg.Header.NumRequiredSignatures) != len(entry.SignerIndexes) {
+		return invalid("%d signers, %d signer indexes", msg.Header.NumRequiredSignatures, len(entry.SignerIndexes))
+	}
+
+	from, err := SolPublicKeyFromBase58(entry.FromAddress)
+	if err != nil {
+		return invalid("sender: %s", err)
+	}
+	dest, err := SolPublicKeyFromBase58(bundle.Destination)
+	if err != nil {
+		return invalid("destination: %s", err)
+	}
+	nonceAccount, err := SolPublicKeyFromBase58(entry.NonceAccount)
+	if err != nil {
+		return invalid("nonce account: %s", err)
+	}
+	amount, err := strconv.ParseUint(entry.Amount, 10, 64)
+	if err != nil || amount == 0 {
+		return invalid("invalid amount %q", entry.Amount)
+	}
+
+	program := func(ix SolCompiledInstruction) SolPublicKey { return msg.AccountKeys[ix.ProgramIDIndex] }
+	accounts := func(ix SolCompiledInstruction) []SolPublicKey {
+		keys := make([]SolPublicKey, len(ix.AccountIndexes))
+		for i, idx := range ix.AccountIndexes {
+			keys[i] = msg.AccountKeys[idx]
+		}
+		return keys
+	}
+
+	if len(msg.Instructions) < 2 {
+		return invalid("expected a nonce advance and a transfer, got %d instructions", len(msg.Instructions))
+	}
+	advance := msg.Instructions[0]
+	advanceAccounts := accounts(advance)
+	if program(advance) != solSystemProgramID ||
+		!bytes.Equal(advance.Data, []byte{4, 0, 0, 0}) ||
+		len(advanceAccounts) != 3 ||
+		advanceAccounts[0] != nonceAccount ||
+		advanceAccounts[1] != solRecentBlockhashesSysvarID {
+		return invalid("first instruction does not advance nonce account %s", entry.NonceAccount)
+	}
+
+	rest := msg.Instructions[1:]
+	if bundle.Token == models.TokenNative {
+		if len(rest) != 1 {
+			return invalid("expected one transfer, got %d instructions", len(rest))
+		}
+		ix := rest[0]
+		acc := accounts(ix)
+		if program(ix) != solSystemProgramID || len(ix.Data) != 12 ||
+			binary.LittleEndian.Uint32(ix.Data[0:4]) != 2 ||
+			len(acc) != 2 || acc[0] != from || acc[1] != dest {
+			return invalid("not a SOL transfer to %s", bundle.Destination)
+		}
+		if got := binary.LittleEndian.Uint64(ix.Data[4:12]); got != amount {
+			return invalid("transfers %d lamports, bundle says %d", got, amount)
+		}
+		return msg, msgBytes, nil
+	}
+
+	destATAStr, err := scanner.DeriveATA(bundle.Destination, bundle.Contract)
+	if err != nil {
+		return invalid("derive destination ATA: %s", err)
+	}
+	sourceATAStr, err := scanner.DeriveATA(entry.FromAddress, bundle.Contract)
+	if err != nil {
+		return invalid("derive source ATA: %s", err)
+	}
+	destATA, _ := SolPublicKeyFromBase58(destATAStr)
+	sourceATA, _ := SolPublicKeyFromBase58(sourceATAStr)
+	mint, err := SolPublicKeyFromBase58(bundle.Contract)
+	if err != nil {
+		return invalid("mint: %s", err)
+	}
+
+	if len(rest) == 2 {
+		create := rest[0]
+		acc := accounts(create)
+		if program(create) != solAssociatedTokenProgramID || len(create.Data) != 0 ||
+			len(acc) != 7 || acc[1] != destATA || acc[2] != dest || acc[3] != mint {
+			return invalid("unexpected instruction before the token transfer")
+		}
+		rest = rest[1:]
+	}
+	if len(rest) != 1 {
+		return invalid("expected one token transfer, got %d instructions", len(rest))
+	}
+	ix := rest[0]
+	acc := accounts(ix)
+	if program(ix) != solTokenProgramID || len(ix.Data) != 9 || ix.Data[0] != 3 ||
+		len(acc) != 3 || acc[0] != sourceATA || acc[1] != destATA || acc[2] != from {
+		return invalid("not a %s transfer to %s", bundle.Token, bundle.Destination)
+	}
+	if got := binary.LittleEndian.Uint64(ix.Data[1:9]); got != amount {
+		return invalid("transfers %d token units, bundle says %d", got, amount)
+	}
+	return msg, msgBytes, nil
+}
+
+// signSOLBundle signs each transaction with the keys of its signer indexes on
+// the bundle's account.
+func signSOLBundle(bundle *models.SweepBundle, seed []byte) (int, error) {
+	signed := 0
+	for i := range bundle.Transactions {
+		entry := &bundle.Transactions[i]
+		msg, msgBytes, err := solBundleMessage(bundle, *entry)
+		if err != nil {
+			return signed, fmt.Errorf("transaction %d: %w", i, err)
+		}
+
+		signers := make(map[SolPublicKey]ed25519.PrivateKey, len(entry.SignerIndexes))
+		wipe := func() {
+			for _, key := range signers {
+				ZeroEd25519Key(key)
+			}
+		}
+		for j, idx := range entry.SignerIndexes {
+			privKey, err := hd.DeriveSOLAccountPrivateKey(seed, bundle.Account, uint32(idx))
+			if err != nil {
+				wipe()
+				return signed, fmt.Errorf("%w: SOL index %d: %s", config.ErrKeyDerivation, idx, err)
+			}
+			pubKey := privKey.Public().(ed25519.PublicKey)
+			if !bytes.Equal(pubKey, msg.AccountKeys[j][:]) {
+				ZeroEd25519Key(privKey)
+				wipe()
+				return signed, fmt.Errorf("%w: SOL index %d derives %s, transaction %d expects signer %s",
+					config.ErrKeyMismatch, idx, base58.Encode(pubKey), i, msg.AccountKeys[j].ToBase58())
+			}
+			signers[msg.AccountKeys[j]] = privKey
+		}
+
+		tx, err := SignTransaction(msg, msgBytes, signers)
+		wipe()
+		if err != nil {
+			return signed, fmt.Errorf("transaction %d: %w", i, err)
+		}
+		raw, err := SerializeTransaction(tx)
+		if err != nil {
+			return signed, fmt.Errorf("transaction %d: %w", i, err)
+		}
+		entry.SignedTx = base64.StdEncoding.EncodeToString(raw)
+		signed++
+	}
+
+	slog.Info("SOL bundle signed", "signedCount", signed, "token", bundle.Token)
+	return signed, nil
+}
+
+// solSignedBundleTx decodes a signed bundle transaction, checks that it signs the
+// exported message with valid signatures from every required signer, and
+// returns the wire bytes and the transaction signature.
+func solSignedBundleTx(bundle *models.SweepBundle, entry models.SweepBundleTx) ([]byte, string, error) {
+	if entry.SignedTx == "" {
+		return nil, "", fmt.Errorf("%w: transaction from %s has no signature", config.ErrBundleUnsigned, entry.FromAddress)
+	}
+	raw, err := base64.StdEncoding.DecodeString(entry.SignedTx)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: decode signed transaction: %s", config.ErrInvalidBundle, err)
+	}
+	if len(raw) > config.SOLMaxTxSize {
+		return nil, "", fmt.Errorf("%w: %d bytes (max %d)", config.ErrSOLTxTooLarge, len(raw), config.SOLMaxTxSize)
+	}
+
+	msg, msgBytes, err := solBundleMessage(bundle, entry)
+	if err != nil {
+		return nil, "", err
+	}
+	sigs, signedMsg, err := SplitTransaction(raw)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %s", config.ErrInvalidBundle, err)
+	}
+	if !bytes.Equal(signedMsg, msgBytes) {
+		return nil, "", fmt.Errorf("%w: signed transaction from %s differs from the exported one", config.ErrInvalidBundle, entry.FromAddress)
+	}
+	if len(sigs) != int(msg.Header.NumRequiredSignatures) {
+		return nil, "", fmt.Errorf("%w: %d signatures, %d required", config.ErrBundleUnsigned, len(sigs), msg.Header.NumRequiredSignatures)
+	}
+	for i, sig := range sigs {
+		if !ed25519.Verify(msg.AccountKeys[i][:], msgBytes, sig[:]) {
+			return nil, "", fmt.Errorf("%w: invalid signature from %s", config.ErrBundleUnsigned, msg.AccountKeys[i].ToBase58())
+		}
+	}
+
+	return raw, base58.Encode(sigs[0][:]), nil
+}
+
+// ValidateBundle checks a signed SOL bundle before it is broadcast: every
+// transaction must be the exported transfer, fully and validly signed, and
+// every sender must be a stored address.
+func (s *SOLConsolidationService) ValidateBundle(bundle *models.SweepBundle) error {
+	if bundle.Chain != models.ChainSOL {
+		return fmt.Errorf("%w: not a SOL bundle", config.ErrInvalidBundle)
+	}
+	for i, entry := range bundle.Transactions {
+		if _, _, err := solSignedBundleTx(bundle, entry); err != nil {
+			return fmt.Errorf("transaction %d: %w", i, err)
+		}
+		if err := checkBundleAddress(s.database, models.ChainSOL, entry); err != nil {
+			return fmt.Errorf("transaction %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// BroadcastBundle sends the transactions of a validated, signed SOL bundle in
+// order, with the same tx_state tracking, transaction records and SSE events as
+// ExecuteNativeSweep. Confirmations are polled in the background.
+func (s *SOLConsolidationService) BroadcastBundle(ctx context.Context, bundle *models.SweepBundle, sweepID string) (*models.SOLSendResult, error) {
+	slog.Info("SOL bundle broadcast",
+		"txCount", len(bundle.Transactions),
+		"token", bundle.Token,
+		"sweepID", sweepID,
+	)
+	start := time.Now()
+
+	result := &models.SOLSendResult{
+		Chain: models.ChainSOL,
+		Token: bundle.Token,
+	}
+	var totalSwept uint64
+
+	for i, entry := range bundle.Transactions {
+		if err := ctx.Err(); err != nil {
+			slog.Warn("SOL bundle broadcast cancelled", "error", err)
+			break
+		}
+
+		txResult := s.broadcastBundleTx(ctx, bundle, entry, sweepID)
+		result.TxResults = append(result.TxResults, txResult)
+
+		if txResult.Status == "success" {
+			result.SuccessCount++
+			amount, _ := strconv.ParseUint(txResult.Amount, 10, 64)
+			totalSwept += amount
+		} else {
+			result.FailCount++
+		}
+
+		if s.txHub != nil {
+			s.txHub.Broadcast(TxEvent{
+				Type: "tx_status",
+				Data: TxStatusData{
+					Chain:        string(models.ChainSOL),
+					Token:        string(bundle.Token),
+					AddressIndex: txResult.AddressIndex,
+					FromAddress:  txResult.FromAddress,
+					TxHash:       txResult.TxSignature,
+					Status:       txResult.Status,
+					Amount:       txResult.Amount,
+					Error:        txResult.Error,
+					Current:      i + 1,
+					Total:        len(bundle.Transactions),
+				},
+			})
+		}
+	}
+
+	result.TotalSwept = strconv.FormatUint(totalSwept, 10)
+
+	slog.Info("SOL bundle broadcast complete",
+		"successCount", result.SuccessCount,
+		"failCount", result.FailCount,
+		"totalSwept", result.TotalSwept,
+		"duration", time.Since(start).Round(time.Millisecond),
+	)
+
+	return result, nil
+}
+
+// broadcastBundleTx sends one signed bundle transaction.
+func (s *SOLConsolidationService) broadcastBundleTx(ctx context.Context, bundle *models.SweepBundle, entry models.SweepBundleTx, sweepID string) models.SOLTxResult {
+	txResult := models.SOLTxResult{
+		AddressIndex: entry.AddressIndex,
+		FromAddress:  entry.FromAddress,
+	}
+
+	txStateID := GenerateTxStateID()
+	s.createTxState(db.TxStateRow{
+		ID:           txStateID,
+		SweepID:      sweepID,
+		Chain:        string(models.ChainSOL),
+		Token:        string(bundle.Token),
+		AddressIndex: entry.AddressIndex,
+		FromAddress:  entry.FromAddress,
+		ToAddress:    bundle.Destination,
+		Amount:       entry.Amount,
+		Status:       config.TxStatePending,
+	})
+
+	raw, txSig, err := solSignedBundleTx(bundle, entry)
+	if err != nil {
+		txResult.Status = "failed"
+		txResult.Error = err.Error()
+		slog.Error("SOL bundle: invalid transaction", "address", entry.FromAddress, "error", err)
+		s.updateTxState(txStateID, config.TxStateFailed, "", txResult.Error)
+		return txResult
+	}
+
+	slog.Info("SOL bundle: broadcasting transfer",
+		"from", entry.FromAddress,
+		"to", bundle.Destination,
+		"token", bundle.Token,
+		"amount", entry.Amount,
+		"nonceAccount", entry.NonceAccount,
+		"txStateID", txStateID,
+	)
+
+	s.updateTxState(txStateID, config.TxStateBroadcasting, "", "")
+
+	signature, err := s.rpcClient.SendTransaction(ctx, base64.StdEncoding.EncodeToString(raw))
+	if err != nil {
+		txResult.Status = "failed"
+		txResult.Error = fmt.Sprintf("broadcast: %s", err)
+		slog.Error("SOL bundle: broadcast failed", "address", entry.FromAddress, "error", err)
+		s.updateTxState(txStateID, config.TxStateFailed, "", txResult.Error)
+		return txResult
+	}
+
+	if signature != "" {
+		txResult.TxSignature = signature
+	} else {
+		txResult.TxSignature = txSig
+	}
+	txResult.Amount = entry.Amount
+
+	s.updateTxState(txStateID, config.TxStateConfirming, txResult.TxSignature, "")
+
+	addr := models.AddressWithBalance{
+		Chain:        models.ChainSOL,
+		AddressIndex: entry.AddressIndex,
+		Address:      entry.FromAddress,
+	}
+	s.recordSOLTransaction(addr, txResult.TxSignature, txResult.Amount, bundle.Destination, bundle.Token, "pending")
+	txResult.Status = "success"
+
+	go func() {
+		bgCtx, cancel := context.WithTimeout(context.Background(), config.SOLConfirmationTimeout)
+		defer cancel()
+
+		slot, err := WaitForSOLConfirmation(bgCtx, s.rpcClient, txResult.TxSignature)
+		if err != nil {
+			if errors.Is(err, config.ErrSOLConfirmationUncertain) {
+				slog.Warn("SOL bundle: confirmation uncertain", "signature", txResult.TxSignature, "error", err)
+				s.updateTxState(txStateID, config.TxStateUncertain, txResult.TxSignature, fmt.Sprintf("confirmation uncertain: %s", err))
+			} else {
+				slog.Error("SOL bundle: confirmation failed", "signature", txResult.TxSignature, "error", err)
+				s.updateTxState(txStateID, config.TxStateFailed, txResult.TxSignature, fmt.Sprintf("confirmation: %s", err))
+			}
+			return
+		}
+
+		slog.Info("SOL bundle: transfer confirmed", "signature", txResult.TxSignature, "slot", slot)
+		s.updateTxState(txStateID, config.TxStateConfirmed, txResult.TxSignature, "")
+	}()
+
+	return txResult
+}
@@ -86,10 +86,11 @@ type SolTransaction struct {
 
 // Well-known Solana program IDs (parsed once at init).
 var (
-	solSystemProgramID          SolPublicKey
-	solTokenProgramID           SolPublicKey
-	solAssociatedTokenProgramID SolPublicKey
-	solRentSysvarID             SolPublicKey
+	solSystemProgramID           SolPublicKey
+	solTokenProgramID            SolPublicKey
+	solAssociatedTokenProgramID  SolPublicKey
+	solRentSysvarID              SolPublicKey
+	solRecentBlockhashesSysvarID SolPublicKey
 )
 
 func init() {
@@ -110,6 +111,10 @@ func init() {
 	if err != nil {
 		panic("invalid rent sysvar ID: " + err.Error())
 	}
+	solRecentBlockhashesSysvarID, err = SolPublicKeyFromBase58(config.SOLRecentBlockhashesSysvarID)
+	if err != nil {
+		panic("invalid recent blockhashes sysvar ID: " + err.Error())
+	}
 }
 
 // EncodeCompactU16 encodes an integer as Solana's compact-u16 variable-length format.
@@ -131,6 +136,26 @@ func EncodeCompactU16(buf *bytes.Buffer, val int) error {
 	return nil
 }
 
+// DecodeCompactU16 decodes a compact-u16 from the start of b and returns the value
+// and the number of bytes it used.
+func DecodeCompactU16(b []byte) (int, int, error) {
+	val := 0
+	for i := 0; i < 3; i++ {
+		if i >= len(b) {
+			return 0, 0, fmt.Errorf("truncated compact-u16")
+		}
+		elem := int(b[i])
+		val |= (elem & 0x7f) << (7 * i)
+		if elem&0x80 == 0 {
+			if val > 65535 {
+				return 0, 0, fmt.Errorf("compact-u16 value out of range: %d", val)
+			}
+			return val, i + 1, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("compact-u16 longer than 3 bytes")
+}
+
 // BuildSystemTransferInstruction creates a SystemProgram.Transfer instruction.
 // Data: [u32 LE: 2 (Transfer variant)] [u64 LE: lamports] = 12 bytes.
 func BuildSystemTransferInstruction(from, to SolPublicKey, lamports uint64) SolInstruction {
@@ -148,6 +173,40 @@ func BuildSystemTransferInstruction(from, to SolPublicKey, lamports uint64) SolI
 	}
 }
 
+// BuildAdvanceNonceInstruction creates a SystemProgram.AdvanceNonceAccount instruction.
+// It must be the first instruction of a transaction built on a durable nonce.
+// Data: [u32 LE: 4 (AdvanceNonceAccount variant)] = 4 bytes.
+func BuildAdvanceNonceInstruction(nonceAccount, authority SolPublicKey) SolInstruction {
+	data := make([]byte, 4)
+	binary.LittleEndian.PutUint32(data, 4) // AdvanceNonceAccount = variant index 4
+
+	return SolInstruction{
+		ProgramID: solSystemProgramID,
+		Accounts: []SolAccountMeta{
+			{PubKey: nonceAccount, IsSigner: false, IsWritable: true},
+			{PubKey: solRecentBlockhashesSysvarID, IsSigner: false, IsWritable: false},
+			{PubKey: authority, IsSigner: true, IsWritable: false},
+		},
+		Data: data,
+	}
+}
+
+// DecodeNonceAccount parses the data of an initialized durable nonce account and
+// returns the stored nonce (used as the transaction's recent blockhash) and the
+// nonce authority.
+func DecodeNonceAccount(data []byte) (nonce [32]byte, authority SolPublicKey, err error) {
+	if len(data) != config.SOLNonceAccountSize {
+		return nonce, authority, fmt.Errorf("%w: data is %d bytes, expected %d",
+			config.ErrSOLNonceAccount, len(data), config.SOLNonceAccountSize)
+	}
+	if state := binary.LittleEndian.Uint32(data[4:8]); state != config.SOLNonceStateInitialized {
+		return nonce, authority, fmt.Errorf("%w: account is not initialized (state %d)", config.ErrSOLNonceAccount, state)
+	}
+	copy(authority[:], data[8:40])
+	copy(nonce[:], data[40:72])
+	return nonce, authority, nil
+}
+
 // BuildSPLTransferInstruction creates an SPL Token.Transfer instruction.
 // Data: [u8: 3 (Transfer variant)] [u64 LE: amount] = 9 bytes.
 func BuildSPLTransferInstruction(sourceATA, destATA, owner SolPublicKey, amount uint64) SolInstruction {
@@ -387,6 +446,129 @@ func SerializeMessage(msg SolMessage) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// DeserializeMessage parses a serialized legacy message, the inverse of
+// SerializeMessage. Account and program indexes are bounds-checked.
+func DeserializeMessage(b []byte) (SolMessage, error) {
+	var msg SolMessage
+	if len(b) < 3 {
+		return msg, fmt.Errorf("message too short: %d bytes", len(b))
+	}
+	if b[0]&0x80 != 0 {
+		return msg, fmt.Errorf("versioned messages are not supported")
+	}
+	msg.Header = SolMessageHeader{
+		NumRequiredSignatures:       b[0],
+		NumReadonlySignedAccounts:   b[1],
+		NumReadonlyUnsignedAccounts: b[2],
+	}
+	pos := 3
+
+	readLen := func(what string) (int, error) {
+		n, used, err := DecodeCompactU16(b[pos:])
+		if err != nil {
+			return 0, fmt.Errorf("decode %s: %w", what, err)
+		}
+		pos += used
+		return n, nil
+	}
+	readBytes := func(n int, what string) ([]byte, error) {
+		if n > len(b)-pos {
+			return nil, fmt.Errorf("truncated %s", what)
+		}
+		out := b[pos : pos+n]
+		pos += n
+		return out, nil
+	}
+
+	keyCount, err := readLen("account key count")
+	if err != nil {
+		return msg, err
+	}
+	if keyCount < int(msg.Header.NumRequiredSignatures) {
+		return msg, fmt.Errorf("%d account keys for %d required signatures", keyCount, msg.Header.NumRequiredSignatures)
+	}
+	msg.AccountKeys = make([]SolPublicKey, keyCount)
+	for i := range msg.AccountKeys {
+		k, err := readBytes(32, "account key")
+		if err != nil {
+			return msg, err
+		}
+		copy(msg.AccountKeys[i][:], k)
+	}
+
+	blockhash, err := readBytes(32, "recent blockhash")
+	if err != nil {
+		return msg, err
+	}
+	copy(msg.RecentBlockhash[:], blockhash)
+
+	ixCount, err := readLen("instruction count")
+	if err != nil {
+		return msg, err
+	}
+	msg.Instructions = make([]SolCompiledInstruction, ixCount)
+	for i := range msg.Instructions {
+		prog, err := readBytes(1, "program index")
+		if err != nil {
+			return msg, err
+		}
+		if int(prog[0]) >= keyCount {
+			return msg, fmt.Errorf("instruction %d: program index %d out of range", i, prog[0])
+		}
+
+		accCount, err := readLen("account index count")
+		if err != nil {
+			return msg, err
+		}
+		accIdxs, err := readBytes(accCount, "account indexes")
+		if err != nil {
+			return msg, err
+		}
+		for _, idx := range accIdxs {
+			if int(idx) >= keyCount {
+				return msg, fmt.Errorf("instruction %d: account index %d out of range", i, idx)
+			}
+		}
+
+		dataLen, err := readLen("instruction data length")
+		if err != nil {
+			return msg, err
+		}
+		data, err := readBytes(dataLen, "instruction data")
+		if err != nil {
+			return msg, err
+		}
+
+		msg.Instructions[i] = SolCompiledInstruction{
+			ProgramIDIndex: prog[0],
+			AccountIndexes: append([]uint8(nil), accIdxs...),
+			Data:           append([]byte(nil), data...),
+		}
+	}
+
+	if pos != len(b) {
+		return msg, fmt.Errorf("%d trailing bytes after message", len(b)-pos)
+	}
+	return msg, nil
+}
+
+// SplitTransaction splits a serialized transaction into its signatures and the
+// serialized message they sign.
+func SplitTransaction(b []byte) ([]SolSignature, []byte, error) {
+	n, used, err := DecodeCompactU16(b)
+	if err != nil {
+		return nil, nil, fmt.Errorf("decode signature count: %w", err)
+	}
+	if n*64 > len(b)-used {
+		return nil, nil, fmt.Errorf("truncated signatures")
+	}
+	sigs := make([]SolSignature, n)
+	for i := range sigs {
+		copy(sigs[i][:], b[used+i*64:])
+	}
+	return sigs, b[used+n*64:], nil
+}
+
 // SerializeTransaction serializes a full SolTransaction into the wire format.
 func SerializeTransaction(tx SolTransaction) ([]byte, error) {
 	msgBytes, err := SerializeMessage(tx.Message)
@@ -436,3 +436,122 @@ func TestBuildAndSerializeTransaction_FullFlow(t *testing.T) {
 		t.Errorf("unexpectedly large tx: %d bytes", len(txBytes))
 	}
 }
+
+func TestDecodeCompactU16_RoundTrip(t *testing.T) {
+	for _, v := range []int{0, 1, 127, 128, 255, 16383, 16384, 65535} {
+		var buf bytes.Buffer
+		if err := EncodeCompactU16(&buf, v); err != nil {
+			t.Fatal(err)
+		}
+		enc := buf.Bytes()
+		got, used, err := DecodeCompactU16(append(enc, 0xff))
+		if err != nil {
+			t.Fatalf("DecodeCompactU16(%d) error = %v", v, err)
+		}
+		if got != v || used != len(enc) {
+			t.Errorf("DecodeCompactU16(%x) = %d, %d bytes; want %d, %d bytes", enc, got, used, v, len(enc))
+		}
+	}
+	if _, _, err := DecodeCompactU16([]byte{0x80}); err == nil {
+		t.Error("DecodeCompactU16 of a truncated value should fail")
+	}
+}
+
+func TestDeserializeMessage_RoundTrip(t *testing.T) {
+	from := SolPublicKey{1}
+	authority := SolPublicKey{2}
+	nonceAccount := SolPublicKey{3}
+	dest := SolPublicKey{4}
+
+	msg, err := CompileMessage(from, []SolInstruction{
+		BuildAdvanceNonceInstruction(nonceAccount, authority),
+		BuildSystemTransferInstruction(from, dest, 42),
+	}, [32]byte{0xee})
+	if err != nil {
+		t.Fatalf("CompileMessage error = %v", err)
+	}
+	msgBytes, err := SerializeMessage(msg)
+	if err != nil {
+		t.Fatalf("SerializeMessage error = %v", err)
+	}
+
+	decoded, err := DeserializeMessage(msgBytes)
+	if err != nil {
+		t.Fatalf("DeserializeMessage error = %v", err)
+	}
+	if decoded.Header != msg.Header || decoded.RecentBlockhash != msg.RecentBlockhash {
+		t.Errorf("decoded header/blockhash = %+v/%x, want %+v/%x", decoded.Header, decoded.RecentBlockhash, msg.Header, msg.RecentBlockhash)
+	}
+	again, err := SerializeMessage(decoded)
+	if err != nil {
+		t.Fatalf("SerializeMessage(decoded) error = %v", err)
+	}
+	if !bytes.Equal(again, msgBytes) {
+		t.Error("re-serialized message differs from the original")
+	}
+
+	if _, err := DeserializeMessage(append(msgBytes, 0)); err == nil {
+		t.Error("DeserializeMessage should reject trailing bytes")
+	}
+	if _, err := DeserializeMessage(msgBytes[:len(msgBytes)-1]); err == nil {
+		t.Error("DeserializeMessage should reject a truncated message")
+	}
+}
+
+func TestSplitTransaction(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(nil)
+	var payer SolPublicKey
+	copy(payer[:], priv.Public().(ed25519.PublicKey))
+
+	msg, err := CompileMessage(payer, []SolInstruction{BuildSystemTransferInstruction(payer, SolPublicKey{9}, 1)}, [32]byte{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgBytes, err := SerializeMessage(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := SignTransaction(msg, msgBytes, map[SolPublicKey]ed25519.PrivateKey{payer: priv})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := SerializeTransaction(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sigs, gotMsg, err := SplitTransaction(raw)
+	if err != nil {
+		t.Fatalf("SplitTransaction error = %v", err)
+	}
+	if len(sigs) != 1 || sigs[0] != signed.Signatures[0] {
+		t.Errorf("signatures = %x, want %x", sigs, signed.Signatures)
+	}
+	if !bytes.Equal(gotMsg, msgBytes) {
+		t.Error("message bytes differ from the signed message")
+	}
+}
+
+func TestDecodeNonceAccount(t *testing.T) {
+	data := make([]byte, 80)
+	binary.LittleEndian.PutUint32(data[4:8], 1)
+	authority := SolPublicKey{7, 7}
+	copy(data[8:40], authority[:])
+	data[40] = 0x42
+
+	nonce, gotAuthority, err := DecodeNonceAccount(data)
+	if err != nil {
+		t.Fatalf("DecodeNonceAccount error = %v", err)
+	}
+	if gotAuthority != authority || nonce[0] != 0x42 {
+		t.Errorf("DecodeNonceAccount = %x, %s", nonce, gotAuthority.ToBase58())
+	}
+
+	binary.LittleEndian.PutUint32(data[4:8], 0)
+	if _, _, err := DecodeNonceAccount(data); err == nil {
+		t.Error("DecodeNonceAccount should reject an uninitialized account")
+	}
+	if _, _, err := DecodeNonceAccount(data[:40]); err == nil {
+		t.Error("DecodeNonceAccount should reject short data")
+	}
+}
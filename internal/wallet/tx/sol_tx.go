@@ -39,6 +39,7 @@ type SOLRPCClient interface {
 	GetSignatureStatuses(ctx context.Context, signatures []string) ([]SOLSignatureStatus, error)
 	GetAccountInfo(ctx context.Context, address string) (exists bool, lamports uint64, err error)
 	GetBalance(ctx context.Context, address string) (uint64, error)
+	GetNonceAccount(ctx context.Context, address string) (nonce [32]byte, authority SolPublicKey, err error)
 }
 
 // --- Default SOL RPC Client (JSON-RPC over HTTP) ---
@@ -318,6 +319,52 @@ func (c *DefaultSOLRPCClient) GetBalance(ctx context.Context, address string) (u
 	return parsed.Value, nil
 }
 
+// GetNonceAccount reads a durable nonce account and returns its stored nonce and
+// its nonce authority.
+func (c *DefaultSOLRPCClient) GetNonceAccount(ctx context.Context, address string) ([32]byte, SolPublicKey, error) {
+	result, err := c.doRPC(ctx, "getAccountInfo", []interface{}{
+		address,
+		map[string]string{"encoding": "base64", "commitment": "confirmed"},
+	})
+	if err != nil {
+		return [32]byte{}, SolPublicKey{}, fmt.Errorf("getAccountInfo for nonce account %s: %w", address, err)
+	}
+
+	var parsed struct {
+		Value *struct {
+			Data  []string `json:"data"`
+			Owner string   `json:"owner"`
+		} `json:"value"`
+	}
+	if err := json.Unmarshal(result, &parsed); err != nil {
+		return [32]byte{}, SolPublicKey{}, fmt.Errorf("parse getAccountInfo: %w", err)
+	}
+
+	if parsed.Value == nil {
+		return [32]byte{}, SolPublicKey{}, fmt.Errorf("%w: %s does not exist", config.ErrSOLNonceAccount, address)
+	}
+	if parsed.Value.Owner != config.SOLSystemProgramID || len(parsed.Value.Data) == 0 {
+		return [32]byte{}, SolPublicKey{}, fmt.Errorf("%w: %s is not owned by the system program", config.ErrSOLNonceAccount, address)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(parsed.Value.Data[0])
+	if err != nil {
+		return [32]byte{}, SolPublicKey{}, fmt.Errorf("decode nonce account data: %w", err)
+	}
+
+	nonce, authority, err := DecodeNonceAccount(data)
+	if err != nil {
+		return [32]byte{}, SolPublicKey{}, fmt.Errorf("nonce account %s: %w", address, err)
+	}
+
+	slog.Debug("SOL nonce account fetched",
+		"address", address,
+		"authority", authority.ToBase58(),
+	)
+
+	return nonce, authority, nil
+}
+
 // --- Confirmation Polling ---
 
 // WaitForSOLConfirmation polls getSignatureStatuses until the transaction is confirmed or fails.
@@ -402,6 +449,9 @@ type SOLConsolidationService struct {
 	network    string
 	txHub      *TxSSEHub
 
+	// Durable nonce accounts for offline-signed bundles (see SetNonceAccounts).
+	nonceAccounts []string
+
 	// Blockhash cache — avoids fetching a new blockhash for every single TX in a sweep.
 	blockhashCache             [32]byte
 	blockhashLastValidHeight   uint64
@@ -427,6 +477,12 @@ func NewSOLConsolidationService(
 	}
 }
 
+// SetNonceAccounts configures the durable nonce accounts that exported sweep
+// bundles are built on, one per transaction.
+func (s *SOLConsolidationService) SetNonceAccounts(accounts []string) {
+	s.nonceAccounts = accounts
+}
+
 // getOrRefreshBlockhash returns a cached blockhash if still valid, or fetches a fresh one.
 // It tracks lastValidBlockHeight to detect expiry — Solana blockhashes are only valid for
 // ~150 blocks (~60 seconds). A stale blockhash will cause all subsequent TXs to fail silently.
@@ -26,6 +26,7 @@ type mockSOLRPCClient struct {
 	getSignatureStatusesFn  func(ctx context.Context, sigs []string) ([]SOLSignatureStatus, error)
 	getAccountInfoFn        func(ctx context.Context, addr string) (bool, uint64, error)
 	getBalanceFn            func(ctx context.Context, addr string) (uint64, error)
+	getNonceAccountFn       func(ctx context.Context, addr string) ([32]byte, SolPublicKey, error)
 }
 
 func (m *mockSOLRPCClient) GetLatestBlockhash(ctx context.Context) ([32]byte, uint64, error) {
@@ -67,6 +68,13 @@ func (m *mockSOLRPCClient) GetBalance(ctx context.Context, addr string) (uint64,
 	return 1_000_000_000, nil // 1 SOL
 }
 
+func (m *mockSOLRPCClient) GetNonceAccount(ctx context.Context, addr string) ([32]byte, SolPublicKey, error) {
+	if m.getNonceAccountFn != nil {
+		return m.getNonceAccountFn(ctx, addr)
+	}
+	return [32]byte{}, SolPublicKey{}, fmt.Errorf("%w: %s", config.ErrSOLNonceAccount, addr)
+}
+
 // --- Mock DB that satisfies *db.DB interface for recording ---
 // (We need a nil-safe way to handle the database calls. The consolidation service
 // calls s.database.InsertTransaction but in tests we may pass nil.
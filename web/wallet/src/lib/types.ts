@@ -14,7 +14,7 @@ export type ScanStatus = 'idle' | 'scanning' | 'paused' | 'completed' | 'failed'
 export type TransactionDirection = 'in' | 'out';
 
 // TransactionStatus represents the state of a transaction.
-export type TransactionStatus = 'pending' | 'confirmed' | 'failed';
+export type TransactionStatus = 'pending' | 'confirmed' | 'failed' | 'superseded';
 
 // Address represents a derived HD wallet address.
 export interface Address {
@@ -32,6 +32,34 @@ export interface AddressWithBalance {
 	nativeBalance: string;
 	tokenBalances: TokenBalance[];
 	lastScanned: string | null;
+	label?: string;
+	tags?: string[];
+	externalId?: string;
+	allocated?: boolean;
+	allocatedTo?: string;
+	allocatedAt?: string;
+}
+
+// MessageScheme identifies how a proof-of-ownership message is signed.
+export type MessageScheme = 'bip322-simple' | 'eip191' | 'solana-offchain';
+
+// SignedMessage is the response data from POST /api/addresses/{chain}/{index}/sign-message.
+export interface SignedMessage {
+	chain: Chain;
+	addressIndex: number;
+	address: string;
+	message: string;
+	scheme: MessageScheme;
+	signature: string;
+}
+
+// VerifyMessageResult is the response data from POST /api/addresses/{chain}/verify-message.
+export interface VerifyMessageResult {
+	chain: Chain;
+	address: string;
+	scheme: MessageScheme;
+	valid: boolean;
+	reason?: string;
 }
 
 // TokenBalance represents the balance of a specific token.
@@ -114,6 +142,7 @@ export interface Transaction {
 	status: TransactionStatus;
 	createdAt: string;
 	confirmedAt: string | null;
+	label?: string;
 }
 
 // HealthResponse represents the /api/health response.
@@ -219,6 +248,46 @@ export interface SendRequest {
 	destination: string;
 	// SOL token sweeps: index of the address that pays all transaction fees.
 	feePayerIndex?: number;
+	// BTC coin control; omitted = every confirmed, unfrozen UTXO.
+	inputSelection?: BTCInputSelection;
+}
+
+// BTCInputSelection narrows the UTXOs a BTC consolidation spends.
+export interface BTCInputSelection {
+	addressIndices?: number[];
+	minValueSats?: number;
+	includeUnconfirmed?: boolean;
+	maxInputs?: number; // keep only the largest UTXOs
+}
+
+// FrozenUTXO is a BTC outpoint never spent by consolidations
+// (GET/POST /api/utxos/frozen).
+export interface FrozenUTXO {
+	txid: string;
+	vout: number;
+	note?: string;
+	frozenAt: string;
+}
+
+// TokenInfo is a token of the registry: a BEP-20 contract or SPL mint
+// (GET/POST /api/settings/tokens). Built-in tokens cannot be removed.
+export interface TokenInfo {
+	chain: Chain;
+	symbol: string;
+	contract: string;
+	decimals: number;
+	coingeckoId?: string;
+	builtin: boolean;
+	createdAt?: string;
+}
+
+// AddTokenRequest is the body of POST /api/settings/tokens.
+export interface AddTokenRequest {
+	chain: Chain;
+	symbol: string;
+	contract: string;
+	decimals: number;
+	coingeckoId?: string;
 }
 
 // FundedAddressInfo is a row in the preview's funded address table.
@@ -227,6 +296,8 @@ export interface FundedAddressInfo {
 	address: string;
 	balance: string;
 	hasGas: boolean;
+	label?: string;
+	externalId?: string;
 }
 
 // UnifiedSendPreview is the unified preview response for all chains.
@@ -242,6 +313,169 @@ export interface UnifiedSendPreview {
 	needsGasPreSeed: boolean;
 	gasPreSeedCount: number;
 	fundedAddresses: FundedAddressInfo[];
+	psbt?: string; // BTC only: base64 unsigned PSBT for offline signing; single-transaction consolidations only
+	chunks?: BTCChunkPreview[]; // BTC only: one entry per consolidation transaction
+	feeRate?: number; // BTC only: sat/vB the preview was built at
+	feeBreakdown?: FeeEstimate; // BTC only: the estimate feeRate was taken from
+	gasPrice?: string; // BSC only: wei to send back as expectedGasPrice; the max fee per gas for dynamic fees
+	baseFee?: string; // BSC only, dynamic fees: next block's base fee (wei)
+	priorityFee?: string; // BSC only, dynamic fees: priority tip (wei)
+}
+
+// FeeEstimate is the BTC fee rate per confirmation target: the median of the
+// sources' quotes within the sanity bounds.
+export interface FeeEstimate {
+	fastestFee: number;
+	halfHourFee: number;
+	hourFee: number;
+	economyFee: number;
+	minimumFee: number;
+	targets?: FeeTargetEstimate[];
+	sources?: FeeSourceStatus[];
+	fallback?: boolean; // no source answered: configured default rates
+}
+
+export interface FeeTargetEstimate {
+	target: 'next_block' | '3_blocks' | '6_blocks' | '24_blocks' | 'economy';
+	blocks: number;
+	feeRate: number;
+	quotes: FeeQuote[];
+}
+
+export interface FeeQuote {
+	source: string;
+	feeRate: number;
+	used: boolean; // false when outside the sanity bounds
+}
+
+export interface FeeSourceStatus {
+	source: 'mempool' | 'esplora' | 'bitcoind';
+	error?: string;
+}
+
+// BTCChunkPreview is one transaction of a BTC consolidation split under the
+// per-transaction input and vsize caps.
+export interface BTCChunkPreview {
+	inputCount: number;
+	totalInputSats: number;
+	outputSats: number;
+	feeSats: number;
+	estimatedVsize: number;
+}
+
+// PSBTBroadcastResult is the response of POST /api/send/psbt/broadcast.
+export interface PSBTBroadcastResult {
+	sweepID: string;
+	txHash: string;
+	chain: Chain;
+	inputCount: number;
+	outputSats: number;
+	feeSats: number;
+}
+
+// FeeBumpRequest is the body of POST /api/send/bump (BTC replace-by-fee).
+export interface FeeBumpRequest {
+	txHash: string;
+	feeRate?: number; // sat/vB; omitted = estimate, raised to the minimum replacement rate
+}
+
+// FeeBumpResult is the response of POST /api/send/bump.
+export interface FeeBumpResult {
+	sweepID: string;
+	txHash: string;
+	replacedTxHash: string;
+	chain: Chain;
+	inputCount: number;
+	outputSats: number;
+	feeSats: number;
+	feeRate: number;
+	replacedFeeSats: number;
+}
+
+// CPFPRequest is the body of POST /api/send/cpfp (BTC child-pays-for-parent).
+export interface CPFPRequest {
+	parentTxHash: string;
+	destination: string;
+	feeRate?: number; // target package sat/vB; omitted = estimate
+}
+
+// CPFPResult is the response of POST /api/send/cpfp.
+export interface CPFPResult {
+	sweepID: string;
+	txHash: string;
+	parentTxHash: string;
+	chain: Chain;
+	inputCount: number;
+	outputSats: number;
+	feeSats: number;
+	parentFeeSats: number;
+	parentVsize: number;
+	packageFeeRate: number;
+}
+
+// PayoutRecipient is one output of a BTC payout.
+export interface PayoutRecipient {
+	address: string;
+	amountSats: number;
+}
+
+// PayoutRequest is the body of POST /api/send/payout/preview and /api/send/payout.
+export interface PayoutRequest {
+	recipients: PayoutRecipient[];
+	feeRate?: number; // sat/vB; omitted = estimate
+	inputSelection?: BTCInputSelection;
+}
+
+// PayoutResult is the response of the payout endpoints; sweepID and txHash are
+// absent in a preview, changeAddress when the change is dust.
+export interface PayoutResult {
+	sweepID?: string;
+	txHash?: string;
+	chain: Chain;
+	recipients: PayoutRecipient[];
+	inputCount: number;
+	totalInputSats: number;
+	payoutSats: number;
+	changeSats: number;
+	changeAddress?: string;
+	changeIndex?: number;
+	feeSats: number;
+	feeRate: number;
+	estimatedVsize: number;
+}
+
+// SweepBundle is a BSC or SOL sweep exported by POST /api/send/bundle/export
+// for offline signing (hdpay sign-bundle); POST /api/send/bundle/broadcast
+// takes it back as { bundle } once signed.
+export interface SweepBundle {
+	version: number;
+	chain: Chain;
+	token: Token;
+	network: string;
+	account: number;
+	destination: string;
+	contract?: string;
+	chainID?: string; // BSC only
+	createdAt: string;
+	transactions: SweepBundleTx[];
+}
+
+export interface SweepBundleTx {
+	addressIndex: number;
+	fromAddress: string;
+	amount: string;
+	// BSC
+	nonce?: number;
+	gasPrice?: string;
+	gasLimit?: number;
+	to?: string;
+	value?: string;
+	data?: string;
+	// SOL
+	message?: string; // base64 message built on a durable nonce
+	nonceAccount?: string;
+	signerIndexes?: number[];
+	signedTx?: string;
 }
 
 // TxResult is a single transaction result in a unified sweep.
@@ -287,6 +521,9 @@ export interface GasPreSeedPreview {
 	amountPerTarget: string;
 	totalNeeded: string;
 	sufficient: boolean;
+	gasPrice: string; // wei; the max fee per gas for dynamic fees
+	baseFee?: string; // dynamic fees only (wei)
+	priorityFee?: string; // dynamic fees only (wei)
 }
 
 // GasPreSeedResult contains the result of a gas pre-seeding operation.